@@ -68,12 +68,21 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 			tequilapi_endpoints.AddRouteForStop(utils.SoftKiller(di.Shutdown)),
 			tequilapi_endpoints.AddRoutesForAuthentication(di.Authenticator, di.JWTAuthenticator),
 			tequilapi_endpoints.AddRoutesForIdentities(di.IdentityManager, di.IdentitySelector, di.IdentityRegistry, di.ConsumerBalanceTracker, di.AddressProvider, di.HermesChannelRepository, di.BCHelper, di.Transactor, di.BeneficiaryProvider, di.IdentityMover, di.PayoutAddressStorage),
-			tequilapi_endpoints.AddRoutesForConnection(di.ConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry, di.EventBus, di.AddressProvider),
+			tequilapi_endpoints.AddRoutesForConnection(di.ConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry, di.EventBus, di.AddressProvider, di.LatencyProber),
+			tequilapi_endpoints.AddRoutesForConnectionSchedule(di.ConnectionScheduleStorage),
+			tequilapi_endpoints.AddRoutesForDNSFilter(di.DNSFilter),
 			tequilapi_endpoints.AddRoutesForSessions(di.SessionStorage),
 			tequilapi_endpoints.AddRoutesForConnectionLocation(di.IPResolver, di.LocationResolver, di.LocationResolver),
 			tequilapi_endpoints.AddRoutesForProposals(di.ProposalRepository, di.PricingHelper, di.LocationResolver, di.FilterPresetStorage, di.NATProber),
+			tequilapi_endpoints.AddRoutesForProposalEvents(di.ProposalRepository, di.NATProber, di.ProposalStream, di.ProposalWebhookStorage, di.HTTPClient),
+			tequilapi_endpoints.AddRoutesForLatency(di.ProposalRepository, di.LatencyProber),
+			tequilapi_endpoints.AddRoutesForSelector(di.ProposalRepository, di.ConsumerBalanceTracker),
+			tequilapi_endpoints.AddRoutesForProviderReputation(di.ProviderReputation),
 			tequilapi_endpoints.AddRoutesForService(di.ServicesManager, services.JSONParsersByType, di.ProposalRepository),
+			tequilapi_endpoints.AddRoutesForServiceSessions(di.ServicesManager, di.ServiceSessions, di.StateKeeper, di.ServiceBanList),
+			tequilapi_endpoints.AddRoutesForShaper(di.ShaperLimiter),
 			tequilapi_endpoints.AddRoutesForAccessPolicies(di.HTTPClient, config.GetString(config.FlagAccessPolicyAddress)),
+			tequilapi_endpoints.AddRoutesForLocalAccessPolicies(di.LocalPolicies),
 			tequilapi_endpoints.AddRoutesForNAT(di.StateKeeper, di.NATProber),
 			tequilapi_endpoints.AddRoutesForNode(di.NodeStatusTracker),
 			tequilapi_endpoints.AddRoutesForTransactor(di.IdentityRegistry, di.Transactor, di.HermesPromiseSettler, di.SettlementHistoryStorage, di.AddressProvider),
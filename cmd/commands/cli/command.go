@@ -55,9 +55,14 @@ const CommandName = "cli"
 const serviceHelp = `service <action> [args]
 	start	<ProviderID> <ServiceType> [options]
 	stop	<ServiceID>
+	drain	<ServiceID> [timeout]
 	status	<ServiceID>
 	list
-	sessions
+	sessions	[ServiceID]
+	kill-session	<ServiceID> <SessionID>
+	ban	<ConsumerID> [reason]
+	unban	<ConsumerID>
+	bans
 
 	example: service start 0x7d5ee3557775aed0b85d691b036769c17349db23 openvpn --openvpn.port=1194 --openvpn.proto=UDP`
 
@@ -607,6 +612,7 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []contract.P
 					readline.PcItem("noop", connectOpts...),
 					readline.PcItem("openvpn", connectOpts...),
 					readline.PcItem("wireguard", connectOpts...),
+					readline.PcItem("proxy", connectOpts...),
 				),
 			),
 		),
@@ -617,11 +623,17 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []contract.P
 				readline.PcItem("noop"),
 				readline.PcItem("openvpn"),
 				readline.PcItem("wireguard"),
+				readline.PcItem("proxy"),
 			)),
 			readline.PcItem("stop"),
+			readline.PcItem("drain"),
 			readline.PcItem("list"),
 			readline.PcItem("status"),
 			readline.PcItem("sessions"),
+			readline.PcItem("kill-session"),
+			readline.PcItem("ban"),
+			readline.PcItem("unban"),
+			readline.PcItem("bans"),
 		),
 		readline.PcItem(
 			"identities",
@@ -674,6 +686,7 @@ func parseStartFlags(serviceType string, args ...string) (services.StartOptions,
 	config.RegisterFlagsServiceOpenvpn(&flags)
 	config.RegisterFlagsServiceWireguard(&flags)
 	config.RegisterFlagsServiceNoop(&flags)
+	config.RegisterFlagsServiceProxy(&flags)
 
 	set := flag.NewFlagSet("", flag.ContinueOnError)
 	for _, f := range flags {
@@ -688,6 +701,7 @@ func parseStartFlags(serviceType string, args ...string) (services.StartOptions,
 	config.ParseFlagsServiceOpenvpn(ctx)
 	config.ParseFlagsServiceWireguard(ctx)
 	config.ParseFlagsServiceNoop(ctx)
+	config.ParseFlagsServiceProxy(ctx)
 
 	return services.GetStartOptions(serviceType)
 }
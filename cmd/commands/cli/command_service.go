@@ -19,6 +19,8 @@ package cli
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
 	"github.com/mysteriumnetwork/node/datasize"
@@ -46,6 +48,12 @@ func (c *cliApp) service(args []string) (err error) {
 			return errWrongArgumentCount
 		}
 		return c.serviceStop(args[1])
+	case "drain":
+		if len(args) < 2 {
+			fmt.Println(serviceHelp)
+			return errWrongArgumentCount
+		}
+		return c.serviceDrain(args[1], args[2:]...)
 	case "status":
 		if len(args) < 2 {
 			fmt.Println(serviceHelp)
@@ -55,7 +63,30 @@ func (c *cliApp) service(args []string) (err error) {
 	case "list":
 		return c.serviceList()
 	case "sessions":
+		if len(args) > 1 {
+			return c.serviceLiveSessions(args[1])
+		}
 		return c.serviceSessions()
+	case "kill-session":
+		if len(args) < 3 {
+			fmt.Println(serviceHelp)
+			return errWrongArgumentCount
+		}
+		return c.serviceKillSession(args[1], args[2])
+	case "ban":
+		if len(args) < 2 {
+			fmt.Println(serviceHelp)
+			return errWrongArgumentCount
+		}
+		return c.serviceBan(args[1], strings.Join(args[2:], " "))
+	case "unban":
+		if len(args) < 2 {
+			fmt.Println(serviceHelp)
+			return errWrongArgumentCount
+		}
+		return c.serviceUnban(args[1])
+	case "bans":
+		return c.serviceBans()
 	default:
 		fmt.Println(serviceHelp)
 		return errUnknownSubCommand(args[0])
@@ -68,12 +99,18 @@ func (c *cliApp) serviceStart(providerID, serviceType string, args ...string) (e
 		return fmt.Errorf("failed to parse service options: %w", err)
 	}
 
-	service, err := c.tequilapi.ServiceStart(contract.ServiceStartRequest{
+	startRequest := contract.ServiceStartRequest{
 		ProviderID:     providerID,
 		Type:           serviceType,
 		AccessPolicies: contract.ServiceAccessPolicies{IDs: serviceOpts.AccessPolicyList},
 		Options:        serviceOpts.TypeOptions,
-	})
+	}
+	// Node applies its own capacity limits unless they are given explicitly.
+	if capacity := contract.NewServiceCapacityDTO(serviceOpts.Capacity); capacity != (contract.ServiceCapacityDTO{}) {
+		startRequest.Capacity = &capacity
+	}
+
+	service, err := c.tequilapi.ServiceStart(startRequest)
 	if err != nil {
 		return fmt.Errorf("failed to start service: %w", err)
 	}
@@ -94,6 +131,24 @@ func (c *cliApp) serviceStop(id string) (err error) {
 	return nil
 }
 
+func (c *cliApp) serviceDrain(id string, args ...string) (err error) {
+	var request contract.ServiceDrainRequest
+	if len(args) > 0 {
+		timeout, err := time.ParseDuration(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid drain timeout, expected duration e.g. 10m: %w", err)
+		}
+		request.Timeout = int(timeout.Seconds())
+	}
+
+	if err := c.tequilapi.ServiceDrain(id, request); err != nil {
+		return fmt.Errorf("failed to drain service: %w", err)
+	}
+
+	clio.Status("Draining", "ID: "+id)
+	return nil
+}
+
 func (c *cliApp) serviceList() (err error) {
 	services, err := c.tequilapi.Services()
 	if err != nil {
@@ -127,6 +182,74 @@ func (c *cliApp) serviceSessions() (err error) {
 	return nil
 }
 
+func (c *cliApp) serviceLiveSessions(id string) (err error) {
+	sessions, err := c.tequilapi.ServiceSessions(id)
+	if err != nil {
+		return fmt.Errorf("failed to get a list of live sessions: %w", err)
+	}
+
+	clio.Status("Live sessions", len(sessions.Sessions))
+	for _, session := range sessions.Sessions {
+		clio.Status(
+			"ID: "+session.ID,
+			"ConsumerID: "+session.ConsumerID,
+			"Country: "+session.ConsumerCountry,
+			"Tunnel IP: "+strings.Join(session.TunnelIPs, ", "),
+			fmt.Sprintf("Data: %s/%s", datasize.FromBytes(session.BytesReceived).String(), datasize.FromBytes(session.BytesSent).String()),
+			fmt.Sprintf("Tokens: %s", money.New(session.Tokens)),
+		)
+	}
+	return nil
+}
+
+func (c *cliApp) serviceKillSession(id, sessionID string) (err error) {
+	if err := c.tequilapi.ServiceSessionTerminate(id, sessionID); err != nil {
+		return fmt.Errorf("failed to terminate session: %w", err)
+	}
+
+	clio.Status("Terminated", "ID: "+sessionID)
+	return nil
+}
+
+func (c *cliApp) serviceBan(consumerID, reason string) (err error) {
+	ban, err := c.tequilapi.ConsumerBan(contract.ConsumerBanRequest{
+		ConsumerID: consumerID,
+		Reason:     reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to ban consumer: %w", err)
+	}
+
+	clio.Status("Banned", "ConsumerID: "+ban.ConsumerID)
+	return nil
+}
+
+func (c *cliApp) serviceUnban(consumerID string) (err error) {
+	if err := c.tequilapi.ConsumerUnban(consumerID); err != nil {
+		return fmt.Errorf("failed to unban consumer: %w", err)
+	}
+
+	clio.Status("Unbanned", "ConsumerID: "+consumerID)
+	return nil
+}
+
+func (c *cliApp) serviceBans() (err error) {
+	bans, err := c.tequilapi.ConsumerBans()
+	if err != nil {
+		return fmt.Errorf("failed to get a list of banned consumers: %w", err)
+	}
+
+	clio.Status("Banned consumers", len(bans.Bans))
+	for _, ban := range bans.Bans {
+		clio.Status(
+			"ConsumerID: "+ban.ConsumerID,
+			"Reason: "+ban.Reason,
+			"Since: "+ban.CreatedAt,
+		)
+	}
+	return nil
+}
+
 func (c *cliApp) serviceGet(id string) (err error) {
 	service, err := c.tequilapi.Service(id)
 	if err != nil {
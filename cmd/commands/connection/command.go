@@ -20,6 +20,8 @@ package connection
 import (
 	"errors"
 	"fmt"
+	"math/big"
+	"net"
 	"os"
 	"strings"
 	"text/tabwriter"
@@ -32,6 +34,7 @@ import (
 	"github.com/mysteriumnetwork/node/config/remote"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/query"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/metadata"
@@ -56,15 +59,81 @@ var (
 
 	flagSortType = cli.StringFlag{
 		Name:  "sort",
-		Usage: "Proposal sorting type. One of: quality, bandwidth, latency or price",
+		Usage: "Proposal sorting type. One of: quality, bandwidth, latency, local_latency, price or reputation",
 		Value: "quality",
 	}
 
+	flagFilter = cli.StringFlag{
+		Name:  "filter",
+		Usage: "Proposal filter expression eg. 'country in (DE,NL) and quality >= 2 and price.gib < 0.1'",
+	}
+
+	flagProposalsSort = cli.StringFlag{
+		Name:  "sort",
+		Usage: "Comma separated proposal fields to sort by, prefix field with '-' for descending order eg. '-quality,price.gib'",
+	}
+
+	flagProbe = cli.IntFlag{
+		Name:  "probe",
+		Usage: "Measure latency to the given count of the best quality proposals before listing, sort by 'local_latency' to use it",
+	}
+
+	flagFastestOf = cli.IntFlag{
+		Name:  "fastest-of",
+		Usage: "Measure latency to the given count of the best proposals and connect to the fastest of them, 0 disables probing",
+	}
+
+	flagSelector = cli.StringFlag{
+		Name:  "select",
+		Usage: "Pick the best provider by weighted score within price caps instead of a provider identity eg. 'quality=3,price_gib=1,max_price_gib=0.1' or 'auto' for default weights, prices in MYST",
+	}
+
+	flagPreset = cli.IntFlag{
+		Name:  "preset",
+		Usage: "Proposal filter preset ID to choose provider by",
+	}
+
 	flagIncludeFailed = cli.BoolFlag{
 		Name:  "include-failed",
 		Usage: "Include proposals marked as test failed by monitoring agent",
 		Value: false,
 	}
+
+	flagFailover = cli.IntFlag{
+		Name:  "failover",
+		Usage: "Switch to the next best provider after the given count of failed session checks, 0 disables failover",
+		Value: 0,
+	}
+
+	flagInclude = cli.StringSliceFlag{
+		Name:  "include",
+		Usage: "Route only the given networks (CIDR) or domains through the tunnel",
+	}
+
+	flagExclude = cli.StringSliceFlag{
+		Name:  "exclude",
+		Usage: "Route the given networks (CIDR) or domains directly, bypassing the tunnel",
+	}
+
+	flagExcludeApp = cli.StringSliceFlag{
+		Name:  "exclude-app",
+		Usage: "Route traffic of applications in the given cgroup directly, bypassing the tunnel (Linux only)",
+	}
+
+	flagMaxSessionSpend = cli.Float64Flag{
+		Name:  "max-session-spend",
+		Usage: "Disconnect once the session costs more than the given amount of MYST",
+	}
+
+	flagMaxHourlySpend = cli.Float64Flag{
+		Name:  "max-hourly-spend",
+		Usage: "Disconnect once the session costs more than the given amount of MYST per hour",
+	}
+
+	flagMaxTraffic = cli.Float64Flag{
+		Name:  "max-traffic",
+		Usage: "Disconnect once the session transfers more than the given amount of GiB",
+	}
 )
 
 const serviceWireguard = "wireguard"
@@ -99,7 +168,7 @@ func NewCommand() *cli.Command {
 			{
 				Name:  "proposals",
 				Usage: "List all possible proposals to which you can connect",
-				Flags: []cli.Flag{&flagCountry, &flagLocationType},
+				Flags: []cli.Flag{&flagCountry, &flagLocationType, &flagFilter, &flagProposalsSort, &flagProbe},
 				Action: func(ctx *cli.Context) error {
 					cmd.proposals(ctx)
 					return nil
@@ -109,12 +178,21 @@ func NewCommand() *cli.Command {
 				Name:      "up",
 				ArgsUsage: "[ProviderIdentityAddress]",
 				Usage:     "Create a new connection",
-				Flags:     []cli.Flag{&config.FlagAgreedTermsConditions, &flagCountry, &flagLocationType, &flagSortType, &flagIncludeFailed},
+				Flags:     []cli.Flag{&config.FlagAgreedTermsConditions, &flagCountry, &flagLocationType, &flagSortType, &flagFastestOf, &flagSelector, &flagPreset, &flagIncludeFailed, &flagFailover, &flagInclude, &flagExclude, &flagExcludeApp, &flagMaxSessionSpend, &flagMaxHourlySpend, &flagMaxTraffic},
 				Action: func(ctx *cli.Context) error {
 					cmd.up(ctx)
 					return nil
 				},
 			},
+			{
+				Name:  "select",
+				Usage: "Show the provider picked by the selector and how far your balance goes at its price",
+				Flags: []cli.Flag{&flagCountry, &flagPreset, &flagSelector},
+				Action: func(ctx *cli.Context) error {
+					cmd.selectProvider(ctx)
+					return nil
+				},
+			},
 			{
 				Name:  "down",
 				Usage: "Disconnect from your current connection",
@@ -131,6 +209,14 @@ func NewCommand() *cli.Command {
 					return nil
 				},
 			},
+			{
+				Name:  "diagnose",
+				Usage: "Probe quality of your current connection",
+				Action: func(ctx *cli.Context) error {
+					cmd.diagnose()
+					return nil
+				},
+			},
 		},
 	}
 }
@@ -148,7 +234,38 @@ func (c *command) proposals(ctx *cli.Context) {
 		return
 	}
 
-	proposals, err := c.tequilapi.ProposalsByLocationAndService(serviceWireguard, locationType, locationCountry)
+	filter := ctx.String(flagFilter.Name)
+	if _, err := query.Parse(filter, nil); err != nil {
+		clio.Warn("Invalid proposal filter:", err)
+		return
+	}
+	sort := ctx.String(flagProposalsSort.Name)
+	if _, err := query.ParseSort(sort); err != nil {
+		clio.Warn("Invalid proposal sorting:", err)
+		return
+	}
+
+	if limit := ctx.Int(flagProbe.Name); limit > 0 {
+		id, err := c.tequilapi.CurrentIdentity("", "")
+		if err != nil {
+			clio.Warn("Unable to get current identity:", err)
+			return
+		}
+
+		latencies, err := c.tequilapi.ProposalsProbe(contract.ProposalsProbeRequest{
+			ConsumerID:  id.Address,
+			ServiceType: serviceWireguard,
+			CountryCode: locationCountry,
+			Limit:       limit,
+		})
+		if err != nil {
+			clio.Warn("Failed to probe proposals:", err)
+			return
+		}
+		clio.Info(fmt.Sprintf("Measured latency to %d providers", len(latencies)))
+	}
+
+	proposals, err := c.tequilapi.ProposalsByQuery(serviceWireguard, locationType, locationCountry, filter, sort)
 	if err != nil {
 		clio.Warn("Failed to fetch proposal list")
 		return
@@ -167,6 +284,45 @@ func (c *command) proposals(ctx *cli.Context) {
 	w.Flush()
 }
 
+func (c *command) selectProvider(ctx *cli.Context) {
+	selector, err := parseSelector(ctx.String(flagSelector.Name))
+	if err != nil {
+		clio.Warn("Invalid provider selector:", err)
+		return
+	}
+	if selector == nil {
+		selector = &contract.ProviderSelectorDTO{}
+	}
+
+	id, err := c.tequilapi.CurrentIdentity("", "")
+	if err != nil {
+		clio.Error("Failed to get your identity")
+		return
+	}
+
+	selected, err := c.tequilapi.ProposalSelect(contract.ProposalSelectRequest{
+		ConsumerID:  id.Address,
+		ServiceType: serviceWireguard,
+		CountryCode: ctx.String(flagCountry.Name),
+		PresetID:    ctx.Int(flagPreset.Name),
+		Selector:    *selector,
+	})
+	if err != nil {
+		clio.Warn("Failed to select provider:", err)
+		return
+	}
+
+	clio.Info(fmt.Sprintf("Selected provider (score %.2f):", selected.Score))
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+	fmt.Fprintln(w, proposalFormatted(&selected.Proposal))
+	w.Flush()
+
+	if e := selected.Estimates; e != nil {
+		clio.Info(fmt.Sprintf("Your balance lasts for %d minutes of video, %d minutes of music, %d minutes of browsing or %d MB of traffic",
+			e.VideoMinutes, e.MusicMinutes, e.BrowsingMinutes, e.TrafficMB))
+	}
+}
+
 func (c *command) down() {
 	status, err := c.tequilapi.ConnectionStatus()
 	if err != nil {
@@ -246,6 +402,16 @@ func (c *command) up(ctx *cli.Context) {
 		}
 	}
 
+	selector, err := parseSelector(ctx.String(flagSelector.Name))
+	if err != nil {
+		clio.Warn("Invalid provider selector:", err)
+		return
+	}
+	if selector != nil && len(providerIDs) > 0 {
+		clio.Warn("Provider selector can't be used together with provider identity")
+		return
+	}
+
 	id, err := c.tequilapi.CurrentIdentity("", "")
 	if err != nil {
 		clio.Error("Failed to get your identity")
@@ -266,8 +432,11 @@ func (c *command) up(ctx *cli.Context) {
 	clio.Status("CONNECTING", "Creating connection from:", id.Address, "to:", providers)
 
 	connectOptions := contract.ConnectOptions{
-		DNS:               connection.DNSOptionAuto,
-		DisableKillSwitch: false,
+		DNS:                 connection.DNSOptionAuto,
+		DisableKillSwitch:   false,
+		FailoverMaxFailures: ctx.Int(flagFailover.Name),
+		SplitTunnel:         splitTunnel(ctx),
+		SpendingLimits:      spendingLimits(ctx),
 	}
 	hermesID, err := c.cfg.GetHermesID()
 	if err != nil {
@@ -281,6 +450,9 @@ func (c *command) up(ctx *cli.Context) {
 		IPType:                  ctx.String(flagLocationType.Name),
 		SortBy:                  ctx.String(flagSortType.Name),
 		IncludeMonitoringFailed: ctx.Bool(flagIncludeFailed.Name),
+		PresetID:                ctx.Int(flagPreset.Name),
+		FastestOf:               ctx.Int(flagFastestOf.Name),
+		Selector:                selector,
 	}
 
 	_, err = c.tequilapi.SmartConnectionCreate(id.Address, hermesID, serviceWireguard, filter, connectOptions)
@@ -292,6 +464,45 @@ func (c *command) up(ctx *cli.Context) {
 	clio.Success("Connected")
 }
 
+func splitTunnel(ctx *cli.Context) contract.SplitTunnelDTO {
+	var st contract.SplitTunnelDTO
+	st.IncludeCIDRs, st.IncludeDomains = splitNetworksAndDomains(ctx.StringSlice(flagInclude.Name))
+	st.ExcludeCIDRs, st.ExcludeDomains = splitNetworksAndDomains(ctx.StringSlice(flagExclude.Name))
+	st.ExcludeApps = ctx.StringSlice(flagExcludeApp.Name)
+	return st
+}
+
+func spendingLimits(ctx *cli.Context) contract.SpendingLimitsDTO {
+	return contract.SpendingLimitsDTO{
+		MaxSessionSpend: mystToWei(ctx.Float64(flagMaxSessionSpend.Name)),
+		MaxHourlySpend:  mystToWei(ctx.Float64(flagMaxHourlySpend.Name)),
+		MaxTrafficBytes: uint64(ctx.Float64(flagMaxTraffic.Name) * float64(datasize.GiB.Bytes())),
+	}
+}
+
+func mystToWei(amount float64) *big.Int {
+	if amount <= 0 {
+		return nil
+	}
+	wei, _ := new(big.Float).Mul(big.NewFloat(amount), new(big.Float).SetInt(money.MystSize)).Int(nil)
+	return wei
+}
+
+func splitNetworksAndDomains(values []string) (cidrs, domains []string) {
+	for _, v := range values {
+		if _, _, err := net.ParseCIDR(v); err == nil {
+			cidrs = append(cidrs, v)
+		} else if ip := net.ParseIP(v); ip != nil && ip.To4() != nil {
+			cidrs = append(cidrs, v+"/32")
+		} else if ip != nil {
+			cidrs = append(cidrs, v+"/128")
+		} else {
+			domains = append(domains, v)
+		}
+	}
+	return cidrs, domains
+}
+
 func (c *command) info() {
 	inf := newConnInfo()
 
@@ -336,3 +547,32 @@ func (c *command) info() {
 
 	inf.printAll()
 }
+
+func (c *command) diagnose() {
+	clio.Info("Running connection diagnostics, this may take a few seconds...")
+	diagnostics, err := c.tequilapi.ConnectionDiagnostics()
+	if err != nil {
+		clio.Warn("Could not diagnose connection:", err)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+	fmt.Fprintf(w, "Session ID:\t%s\n", diagnostics.SessionID)
+	fmt.Fprintf(w, "Latency:\t%.1f ms\n", diagnostics.Latency)
+	fmt.Fprintf(w, "Jitter:\t%.1f ms\n", diagnostics.Jitter)
+	fmt.Fprintf(w, "Packet loss:\t%.0f%% (%d of %d pings lost)\n", diagnostics.PacketLoss*100, diagnostics.PingsLost, diagnostics.PingsSent)
+	dnsServers := "system"
+	if len(diagnostics.DNSServers) > 0 {
+		dnsServers = strings.Join(diagnostics.DNSServers, ",")
+	}
+	if diagnostics.DNSError != "" {
+		fmt.Fprintf(w, "DNS resolution (%s, %s):\tfailed: %s\n", diagnostics.DNSOption, dnsServers, diagnostics.DNSError)
+	} else {
+		fmt.Fprintf(w, "DNS resolution (%s, %s):\t%.1f ms\n", diagnostics.DNSOption, dnsServers, diagnostics.DNSResolveTime)
+	}
+	if diagnostics.HandshakeAge > 0 {
+		fmt.Fprintf(w, "Last handshake:\t%s ago\n", time.Duration(diagnostics.HandshakeAge)*time.Second)
+	}
+	fmt.Fprintf(w, "Observed traffic rate (down/up):\t%s/%s\n", datasize.BitSpeed(diagnostics.ObservedTrafficRateReceived), datasize.BitSpeed(diagnostics.ObservedTrafficRateSent))
+	w.Flush()
+}
@@ -20,6 +20,8 @@ package connection
 import (
 	"fmt"
 	"math/big"
+	"strconv"
+	"strings"
 
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
@@ -28,11 +30,64 @@ import (
 func proposalFormatted(p *contract.ProposalDTO) string {
 	ph := money.New(new(big.Int).SetUint64(p.Price.PerHour))
 	pg := money.New(new(big.Int).SetUint64(p.Price.PerGiB))
-	return fmt.Sprintf("| Identity: %s\t| Type: %s\t| Country: %s\t | Price: %s/hour\t%s/GiB\t|",
+	formatted := fmt.Sprintf("| Identity: %s\t| Type: %s\t| Country: %s\t | Price: %s/hour\t%s/GiB\t|",
 		p.ProviderID,
 		p.Location.IPType,
 		p.Location.Country,
 		ph.String(),
 		pg.String(),
 	)
+	if p.LocalLatency > 0 {
+		formatted += fmt.Sprintf(" Latency: %.0fms\t|", p.LocalLatency)
+	}
+	return formatted
+}
+
+// parseSelector parses provider selector spec e.g. "quality=3,price_gib=1,max_price_gib=0.1",
+// "auto" selects with default weights. Prices are given in MYST.
+func parseSelector(spec string) (*contract.ProviderSelectorDTO, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	selector := &contract.ProviderSelectorDTO{}
+	if spec == "auto" {
+		return selector, nil
+	}
+
+	weights := contract.SelectorWeightsDTO{}
+	for _, part := range strings.Split(spec, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("expected key=value, got %q", part)
+		}
+		value, err := strconv.ParseFloat(kv[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %q: %w", kv[0], err)
+		}
+
+		switch kv[0] {
+		case "quality":
+			weights.Quality = value
+		case "bandwidth":
+			weights.Bandwidth = value
+		case "latency":
+			weights.Latency = value
+		case "price_gib":
+			weights.PriceGiB = value
+		case "price_hour":
+			weights.PriceHour = value
+		case "max_price_gib":
+			selector.MaxPricePerGiB = mystToWei(value)
+		case "max_price_hour":
+			selector.MaxPricePerHour = mystToWei(value)
+		default:
+			return nil, fmt.Errorf("unknown selector key %q", kv[0])
+		}
+	}
+
+	if weights != (contract.SelectorWeightsDTO{}) {
+		selector.Weights = &weights
+	}
+	return selector, nil
 }
@@ -41,6 +41,7 @@ func NewCommand() *cli.Command {
 			config.ParseFlagsServiceOpenvpn(ctx)
 			config.ParseFlagsServiceWireguard(ctx)
 			config.ParseFlagsServiceNoop(ctx)
+			config.ParseFlagsServiceProxy(ctx)
 			config.ParseFlagsNode(ctx)
 
 			nodeOptions := node.GetOptions()
@@ -0,0 +1,262 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package presets
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/money"
+	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+// CommandName is the name of this command
+const CommandName = "presets"
+
+var (
+	flagName = cli.StringFlag{
+		Name:     "name",
+		Usage:    "Name of the preset",
+		Required: true,
+	}
+
+	flagIPType = cli.StringFlag{
+		Name:  "ip-type",
+		Usage: "Provider IP type eg.'hosting', 'residential', 'mobile' etc.",
+	}
+
+	flagCountry = cli.StringFlag{
+		Name:  "country",
+		Usage: "Two letter (ISO 3166-1 alpha-2) country code of providers",
+	}
+
+	flagPriceHourMax = cli.Float64Flag{
+		Name:  "price-hour-max",
+		Usage: "Maximum price per hour in MYST",
+	}
+
+	flagPriceGiBMax = cli.Float64Flag{
+		Name:  "price-gib-max",
+		Usage: "Maximum price per GiB in MYST",
+	}
+
+	flagQualityMin = cli.Float64Flag{
+		Name:  "quality-min",
+		Usage: "Minimum provider quality",
+	}
+
+	flagNATCompatibility = cli.StringFlag{
+		Name:  "nat-compatibility",
+		Usage: "Pick providers compatible with NAT of the given type eg. 'none', 'fullcone', 'rcone', 'prcone' or 'symmetric'",
+	}
+
+	flagAllow = cli.StringSliceFlag{
+		Name:  "allow",
+		Usage: "Choose only from the given providers",
+	}
+
+	flagDeny = cli.StringSliceFlag{
+		Name:  "deny",
+		Usage: "Never choose the given providers",
+	}
+)
+
+var presetFlags = []cli.Flag{&flagName, &flagIPType, &flagCountry, &flagPriceHourMax, &flagPriceGiBMax, &flagQualityMin, &flagNATCompatibility, &flagAllow, &flagDeny}
+
+// NewCommand function creates presets command.
+func NewCommand() *cli.Command {
+	var cmd *command
+
+	return &cli.Command{
+		Name:        CommandName,
+		Usage:       "Manage your proposal filter presets",
+		Description: "Using the presets subcommands you can manage presets used to choose providers when connecting",
+		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort},
+		Before: func(ctx *cli.Context) error {
+			tc, err := clio.NewTequilApiClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			cmd = &command{
+				tequilapi: tc,
+			}
+			return nil
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List proposal filter presets",
+				Action: func(ctx *cli.Context) error {
+					cmd.list()
+					return nil
+				},
+			},
+			{
+				Name:  "add",
+				Usage: "Add a new proposal filter preset",
+				Flags: presetFlags,
+				Action: func(ctx *cli.Context) error {
+					cmd.add(ctx)
+					return nil
+				},
+			},
+			{
+				Name:      "update",
+				ArgsUsage: "[PresetID]",
+				Usage:     "Replace proposal filter preset",
+				Flags:     presetFlags,
+				Action: func(ctx *cli.Context) error {
+					cmd.update(ctx)
+					return nil
+				},
+			},
+			{
+				Name:      "remove",
+				ArgsUsage: "[PresetID]",
+				Usage:     "Remove proposal filter preset",
+				Action: func(ctx *cli.Context) error {
+					cmd.remove(ctx)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+type command struct {
+	tequilapi *tequilapi_client.Client
+}
+
+func (c *command) list() {
+	presets, err := c.tequilapi.FilterPresets()
+	if err != nil {
+		clio.Warn("Failed to fetch proposal filter presets")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+	for _, p := range presets.Items {
+		fmt.Fprintln(w, presetFormatted(p))
+	}
+	w.Flush()
+}
+
+func (c *command) add(ctx *cli.Context) {
+	preset, err := c.tequilapi.FilterPresetCreate(presetRequest(ctx))
+	if err != nil {
+		clio.Error("Failed to add proposal filter preset", err)
+		return
+	}
+
+	clio.Success(fmt.Sprintf("Proposal filter preset %d added", preset.ID))
+}
+
+func (c *command) update(ctx *cli.Context) {
+	id, err := strconv.Atoi(ctx.Args().First())
+	if err != nil {
+		clio.Warn("Preset ID must be a number")
+		return
+	}
+
+	if _, err := c.tequilapi.FilterPresetUpdate(id, presetRequest(ctx)); err != nil {
+		clio.Error("Failed to update proposal filter preset", err)
+		return
+	}
+
+	clio.Success(fmt.Sprintf("Proposal filter preset %d updated", id))
+}
+
+func (c *command) remove(ctx *cli.Context) {
+	id, err := strconv.Atoi(ctx.Args().First())
+	if err != nil {
+		clio.Warn("Preset ID must be a number")
+		return
+	}
+
+	if err := c.tequilapi.FilterPresetDelete(id); err != nil {
+		clio.Error("Failed to remove proposal filter preset", err)
+		return
+	}
+
+	clio.Success(fmt.Sprintf("Proposal filter preset %d removed", id))
+}
+
+func presetRequest(ctx *cli.Context) contract.FilterPresetRequest {
+	return contract.FilterPresetRequest{
+		Name:             ctx.String(flagName.Name),
+		IPType:           ctx.String(flagIPType.Name),
+		Country:          strings.ToUpper(ctx.String(flagCountry.Name)),
+		PriceHourMax:     mystToWei(ctx.Float64(flagPriceHourMax.Name)),
+		PriceGiBMax:      mystToWei(ctx.Float64(flagPriceGiBMax.Name)),
+		QualityMin:       ctx.Float64(flagQualityMin.Name),
+		NATCompatibility: ctx.String(flagNATCompatibility.Name),
+		AllowedProviders: ctx.StringSlice(flagAllow.Name),
+		DeniedProviders:  ctx.StringSlice(flagDeny.Name),
+	}
+}
+
+func mystToWei(amount float64) *big.Int {
+	if amount <= 0 {
+		return nil
+	}
+	wei, _ := new(big.Float).Mul(big.NewFloat(amount), new(big.Float).SetInt(money.MystSize)).Int(nil)
+	return wei
+}
+
+func presetFormatted(p contract.FilterPreset) string {
+	var criteria []string
+	if p.IPType != "" {
+		criteria = append(criteria, "IP type: "+p.IPType)
+	}
+	if p.Country != "" {
+		criteria = append(criteria, "country: "+p.Country)
+	}
+	if p.PriceHourMax != nil {
+		criteria = append(criteria, "max per hour: "+money.New(p.PriceHourMax).String())
+	}
+	if p.PriceGiBMax != nil {
+		criteria = append(criteria, "max per GiB: "+money.New(p.PriceGiBMax).String())
+	}
+	if p.QualityMin > 0 {
+		criteria = append(criteria, fmt.Sprintf("min quality: %.1f", p.QualityMin))
+	}
+	if p.NATCompatibility != "" {
+		criteria = append(criteria, "NAT: "+p.NATCompatibility)
+	}
+	if len(p.AllowedProviders) > 0 {
+		criteria = append(criteria, "allowed: "+strings.Join(p.AllowedProviders, ","))
+	}
+	if len(p.DeniedProviders) > 0 {
+		criteria = append(criteria, "denied: "+strings.Join(p.DeniedProviders, ","))
+	}
+	if len(criteria) == 0 {
+		criteria = append(criteria, "-")
+	}
+
+	return fmt.Sprintf("| ID: %d\t| %s\t| %s\t|", p.ID, p.Name, strings.Join(criteria, ", "))
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package providers
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
+	"github.com/mysteriumnetwork/node/config"
+	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+// CommandName is the name of this command
+const CommandName = "providers"
+
+// NewCommand function creates providers command.
+func NewCommand() *cli.Command {
+	var cmd *command
+
+	update := func(name, usage, done string, request contract.ProviderReputationRequest) *cli.Command {
+		return &cli.Command{
+			Name:      name,
+			ArgsUsage: "[ProviderID]",
+			Usage:     usage,
+			Action: func(ctx *cli.Context) error {
+				cmd.update(ctx.Args().First(), request, done)
+				return nil
+			},
+		}
+	}
+	yes, no := true, false
+
+	return &cli.Command{
+		Name:        CommandName,
+		Usage:       "Manage favourite and blocked providers",
+		Description: "Using the providers subcommands you can see personal provider reputation built from your sessions and manage favourites and blocklist",
+		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort},
+		Before: func(ctx *cli.Context) error {
+			tc, err := clio.NewTequilApiClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			cmd = &command{
+				tequilapi: tc,
+			}
+			return nil
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List providers with personal reputation, favourites and best scored first",
+				Action: func(ctx *cli.Context) error {
+					cmd.list()
+					return nil
+				},
+			},
+			update("favourite", "Mark provider as favourite", "added to favourites", contract.ProviderReputationRequest{Favourite: &yes}),
+			update("unfavourite", "Remove provider from favourites", "removed from favourites", contract.ProviderReputationRequest{Favourite: &no}),
+			update("block", "Block provider, blocked providers are never chosen", "blocked", contract.ProviderReputationRequest{Blocked: &yes}),
+			update("unblock", "Unblock provider", "unblocked", contract.ProviderReputationRequest{Blocked: &no}),
+		},
+	}
+}
+
+type command struct {
+	tequilapi *tequilapi_client.Client
+}
+
+func (c *command) list() {
+	providers, err := c.tequilapi.ProviderReputations()
+	if err != nil {
+		clio.Warn("Failed to fetch provider reputations")
+		return
+	}
+	if len(providers) == 0 {
+		clio.Info("No providers known yet")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+	for _, p := range providers {
+		fmt.Fprintln(w, providerFormatted(p))
+	}
+	w.Flush()
+}
+
+func (c *command) update(providerID string, request contract.ProviderReputationRequest, done string) {
+	if providerID == "" {
+		clio.Warn("Provider ID is required")
+		return
+	}
+
+	if _, err := c.tequilapi.ProviderReputationUpdate(providerID, request); err != nil {
+		clio.Error("Failed to update provider", err)
+		return
+	}
+
+	clio.Success(fmt.Sprintf("Provider %s %s", providerID, done))
+}
+
+func providerFormatted(p contract.ProviderReputationDTO) string {
+	var marks []string
+	if p.Favourite {
+		marks = append(marks, "favourite")
+	}
+	if p.Blocked {
+		marks = append(marks, "blocked")
+	}
+	if len(marks) == 0 {
+		marks = append(marks, "-")
+	}
+
+	reasons := make([]string, 0, len(p.DisconnectReasons))
+	for reason, count := range p.DisconnectReasons {
+		reasons = append(reasons, fmt.Sprintf("%s: %d", reason, count))
+	}
+	sort.Strings(reasons)
+
+	return fmt.Sprintf(
+		"| %s\t| %s\t| score: %.2f\t| connects: %d/%d\t| connect time: %dms\t| throughput: %d B/s\t| %s\t|",
+		p.ProviderID,
+		strings.Join(marks, ", "),
+		p.Score,
+		p.ConnectSuccesses,
+		p.ConnectSuccesses+p.ConnectFailures,
+		p.ConnectTimeMs,
+		p.Throughput,
+		strings.Join(reasons, ", "),
+	)
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/money"
+	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+// CommandName is the name of this command
+const CommandName = "schedule"
+
+var (
+	flagAt = cli.StringFlag{
+		Name:  "at",
+		Usage: "Local time of day to connect at in HH:MM format, rules without it limit every connection",
+	}
+
+	flagCountry = cli.StringFlag{
+		Name:  "country",
+		Usage: "Two letter (ISO 3166-1 alpha-2) country code of provider to connect to",
+	}
+
+	flagPreset = cli.IntFlag{
+		Name:  "preset",
+		Usage: "Proposal filter preset ID to choose provider by",
+	}
+
+	flagDisconnectAfter = cli.IntFlag{
+		Name:  "disconnect-after",
+		Usage: "Disconnect after the given count of minutes",
+	}
+
+	flagMaxTraffic = cli.Float64Flag{
+		Name:  "max-traffic",
+		Usage: "Disconnect after the given amount of GiB is transferred",
+	}
+
+	flagMaxSpend = cli.Float64Flag{
+		Name:  "max-spend",
+		Usage: "Disconnect after the given amount of MYST is spent",
+	}
+
+	flagReconnect = cli.BoolFlag{
+		Name:  "reconnect-on-network-change",
+		Usage: "Reconnect when network of the node changes",
+	}
+)
+
+const serviceWireguard = "wireguard"
+
+// NewCommand function creates schedule command.
+func NewCommand() *cli.Command {
+	var cmd *command
+
+	return &cli.Command{
+		Name:        CommandName,
+		Usage:       "Manage your scheduled and conditional connections",
+		Description: "Using the schedule subcommands you can connect at the given time and disconnect after time, traffic or spending limits are exceeded",
+		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort},
+		Before: func(ctx *cli.Context) error {
+			tc, err := clio.NewTequilApiClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			cmd = &command{
+				tequilapi: tc,
+			}
+			return nil
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List connection schedule rules",
+				Action: func(ctx *cli.Context) error {
+					cmd.list()
+					return nil
+				},
+			},
+			{
+				Name:  "add",
+				Usage: "Add a new connection schedule rule",
+				Flags: []cli.Flag{&flagAt, &flagCountry, &flagPreset, &flagDisconnectAfter, &flagMaxTraffic, &flagMaxSpend, &flagReconnect},
+				Action: func(ctx *cli.Context) error {
+					cmd.add(ctx)
+					return nil
+				},
+			},
+			{
+				Name:      "remove",
+				ArgsUsage: "[RuleID]",
+				Usage:     "Remove connection schedule rule",
+				Action: func(ctx *cli.Context) error {
+					cmd.remove(ctx)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+type command struct {
+	tequilapi *tequilapi_client.Client
+}
+
+func (c *command) list() {
+	rules, err := c.tequilapi.ConnectionSchedules()
+	if err != nil {
+		clio.Warn("Failed to fetch connection schedule rules")
+		return
+	}
+
+	if len(rules) == 0 {
+		clio.Info("No connection schedule rules found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+	for _, r := range rules {
+		fmt.Fprintln(w, ruleFormatted(r))
+	}
+	w.Flush()
+}
+
+func (c *command) add(ctx *cli.Context) {
+	req := contract.ConnectionScheduleRequest{
+		CountryCode:              strings.ToUpper(ctx.String(flagCountry.Name)),
+		PresetID:                 ctx.Int(flagPreset.Name),
+		ConnectAt:                ctx.String(flagAt.Name),
+		DisconnectAfterMinutes:   ctx.Int(flagDisconnectAfter.Name),
+		MaxTrafficBytes:          uint64(ctx.Float64(flagMaxTraffic.Name) * float64(datasize.GiB.Bytes())),
+		ReconnectOnNetworkChange: ctx.Bool(flagReconnect.Name),
+	}
+	if spend := ctx.Float64(flagMaxSpend.Name); spend > 0 {
+		req.MaxSpend, _ = new(big.Float).Mul(big.NewFloat(spend), new(big.Float).SetInt(money.MystSize)).Int(nil)
+	}
+
+	if req.ConnectAt != "" {
+		id, err := c.tequilapi.CurrentIdentity("", "")
+		if err != nil {
+			clio.Error("Failed to get your identity")
+			return
+		}
+		req.ConsumerID = id.Address
+		req.ServiceType = serviceWireguard
+	}
+
+	rule, err := c.tequilapi.ConnectionScheduleCreate(req)
+	if err != nil {
+		clio.Error("Failed to add connection schedule rule", err)
+		return
+	}
+
+	clio.Success(fmt.Sprintf("Connection schedule rule %d added", rule.ID))
+}
+
+func (c *command) remove(ctx *cli.Context) {
+	id, err := strconv.Atoi(ctx.Args().First())
+	if err != nil {
+		clio.Warn("Rule ID must be a number")
+		return
+	}
+
+	if err := c.tequilapi.ConnectionScheduleDelete(id); err != nil {
+		clio.Error("Failed to remove connection schedule rule", err)
+		return
+	}
+
+	clio.Success(fmt.Sprintf("Connection schedule rule %d removed", id))
+}
+
+func ruleFormatted(r contract.ConnectionScheduleDTO) string {
+	connect := "every connection"
+	if r.ConnectAt != "" {
+		connect = "connect at " + r.ConnectAt
+		if r.CountryCode != "" {
+			connect += " to " + r.CountryCode
+		}
+		if r.PresetID != 0 {
+			connect += fmt.Sprintf(" by preset %d", r.PresetID)
+		}
+	}
+
+	var limits []string
+	if r.DisconnectAfterMinutes > 0 {
+		limits = append(limits, fmt.Sprintf("%d min", r.DisconnectAfterMinutes))
+	}
+	if r.MaxTrafficBytes > 0 {
+		limits = append(limits, datasize.FromBytes(r.MaxTrafficBytes).String())
+	}
+	if r.MaxSpend != nil && r.MaxSpend.Sign() > 0 {
+		limits = append(limits, money.New(r.MaxSpend).String())
+	}
+	if len(limits) == 0 {
+		limits = append(limits, "none")
+	}
+
+	return fmt.Sprintf("| ID: %d\t| %s\t| Disconnect after: %s\t| Reconnect on network change: %t\t|",
+		r.ID,
+		connect,
+		strings.Join(limits, ", "),
+		r.ReconnectOnNetworkChange,
+	)
+}
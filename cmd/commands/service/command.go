@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -53,6 +54,7 @@ func NewCommand(licenseCommandName string) *cli.Command {
 			config.ParseFlagsServiceOpenvpn(ctx)
 			config.ParseFlagsServiceWireguard(ctx)
 			config.ParseFlagsServiceNoop(ctx)
+			config.ParseFlagsServiceProxy(ctx)
 			config.ParseFlagsNode(ctx)
 
 			if err := hasAcceptedTOS(ctx); err != nil {
@@ -67,12 +69,19 @@ func NewCommand(licenseCommandName string) *cli.Command {
 			}
 			go func() { quit <- di.Node.Wait() }()
 
-			cmd.RegisterSignalCallback(func() { quit <- nil })
-
 			cmdService := &serviceCommand{
 				tequilapi:    client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort),
 				errorChannel: quit,
 			}
+
+			cmd.RegisterSignalCallback(func() {
+				if config.GetBool(config.FlagServiceDrainOnExit) {
+					// Second signal skips the drain.
+					cmd.RegisterSignalCallback(func() { quit <- nil })
+					cmdService.drainServices()
+				}
+				quit <- nil
+			})
 			go func() {
 				quit <- cmdService.Run(ctx)
 			}()
@@ -88,6 +97,7 @@ func NewCommand(licenseCommandName string) *cli.Command {
 	config.RegisterFlagsServiceOpenvpn(&command.Flags)
 	config.RegisterFlagsServiceWireguard(&command.Flags)
 	config.RegisterFlagsServiceNoop(&command.Flags)
+	config.RegisterFlagsServiceProxy(&command.Flags)
 
 	return command
 }
@@ -105,6 +115,9 @@ func describeQuit(err error) error {
 type serviceCommand struct {
 	tequilapi    *client.Client
 	errorChannel chan error
+
+	serviceIDsLock sync.Mutex
+	serviceIDs     []string
 }
 
 // Run runs a command
@@ -185,9 +198,49 @@ func (sc *serviceCommand) tryRememberTOS(ctx *cli.Context, errCh chan error) {
 }
 
 func (sc *serviceCommand) runService(request contract.ServiceStartRequest) {
-	_, err := sc.tequilapi.ServiceStart(request)
+	service, err := sc.tequilapi.ServiceStart(request)
 	if err != nil {
 		sc.errorChannel <- errors.Wrapf(err, "failed to run service %s", request.Type)
+		return
+	}
+
+	sc.serviceIDsLock.Lock()
+	defer sc.serviceIDsLock.Unlock()
+	sc.serviceIDs = append(sc.serviceIDs, service.ID)
+}
+
+// drainServices drains started services and blocks until they are stopped.
+func (sc *serviceCommand) drainServices() {
+	const checkRate = 5 * time.Second
+
+	sc.serviceIDsLock.Lock()
+	draining := make(map[string]bool)
+	for _, id := range sc.serviceIDs {
+		if err := sc.tequilapi.ServiceDrain(id, contract.ServiceDrainRequest{}); err != nil {
+			log.Warn().Err(err).Msgf("Failed to drain service %s", id)
+			continue
+		}
+		draining[id] = true
+	}
+	sc.serviceIDsLock.Unlock()
+
+	log.Info().Msgf("Waiting up to %s for sessions to end, interrupt again to stop immediately", config.GetDuration(config.FlagServiceDrainTimeout))
+	for len(draining) > 0 {
+		time.Sleep(checkRate)
+
+		services, err := sc.tequilapi.Services()
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to get a list of services")
+			continue
+		}
+
+		running := make(map[string]bool)
+		for _, service := range services {
+			if draining[service.ID] {
+				running[service.ID] = true
+			}
+		}
+		draining = running
 	}
 }
 
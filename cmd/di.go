@@ -35,13 +35,17 @@ import (
 	"github.com/mysteriumnetwork/node/config"
 	appconfig "github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/consumer/latency"
+	"github.com/mysteriumnetwork/node/consumer/reputation"
 	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
 	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/mysteriumnetwork/node/core/beneficiary"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/connection/schedule"
 	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/stream"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/node"
@@ -51,10 +55,13 @@ import (
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/sysmon"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/core/state"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrator"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/feedback"
 	"github.com/mysteriumnetwork/node/firewall"
@@ -76,6 +83,7 @@ import (
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/mysteriumnetwork/node/requests/resolver"
 	"github.com/mysteriumnetwork/node/router"
+	router_network "github.com/mysteriumnetwork/node/router/network"
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
 	"github.com/mysteriumnetwork/node/session/connectivity"
@@ -125,38 +133,49 @@ type Dependencies struct {
 	IdentitySelector identity_selector.Handler
 	IdentityMover    *identity.Mover
 
-	DiscoveryFactory    service.DiscoveryFactory
-	ProposalRepository  *discovery.PricedServiceProposalRepository
-	FilterPresetStorage *proposal.FilterPresetStorage
-	DiscoveryWorker     discovery.Worker
+	DiscoveryFactory       service.DiscoveryFactory
+	ProposalRepository     *discovery.PricedServiceProposalRepository
+	ProposalStream         *stream.Stream
+	ProposalWebhookStorage *stream.WebhookStorage
+	FilterPresetStorage    *proposal.FilterPresetStorage
+	DiscoveryWorker        discovery.Worker
 
 	QualityClient *quality.MysteriumMORQA
 
 	IPResolver       ip.Resolver
 	LocationResolver *location.Cache
 
-	PolicyOracle *policy.Oracle
+	PolicyOracle  *policy.Oracle
+	LocalPolicies *policy.LocalPolicies
 
 	SessionStorage                   *consumer_session.Storage
 	SessionConnectivityStatusStorage connectivity.StatusStorage
+	ProviderReputation               *reputation.Storage
 
 	EventBus eventbus.EventBus
 
-	ConnectionManager  connection.Manager
-	ConnectionRegistry *connection.Registry
+	ConnectionManager         connection.Manager
+	ConnectionRegistry        *connection.Registry
+	ConnectionScheduleStorage *schedule.Storage
+	ConnectionScheduler       *schedule.Scheduler
+	DNSFilter                 *dns.Filter
 
-	ServicesManager *service.Manager
-	ServiceRegistry *service.Registry
-	ServiceSessions *service.SessionPool
-	ServiceFirewall firewall.IncomingTrafficFirewall
+	ServicesManager  *service.Manager
+	ServiceRegistry  *service.Registry
+	ServiceSessions  *service.SessionPool
+	ServiceBanList   *service.BanList
+	ServiceResources *sysmon.Monitor
+	ServiceFirewall  firewall.IncomingTrafficFirewall
+	ShaperLimiter    *shaper.Limiter
 
 	PortPool   *port.Pool
 	PortMapper mapping.PortMapper
 
 	StateKeeper *state.Keeper
 
-	P2PDialer   p2p.Dialer
-	P2PListener p2p.Listener
+	P2PDialer     p2p.Dialer
+	P2PListener   p2p.Listener
+	LatencyProber *latency.Prober
 
 	Authenticator     *auth.Authenticator
 	JWTAuthenticator  *auth.JWTAuthenticator
@@ -261,6 +280,11 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 
 	di.PortPool = port.NewFixedRangePool(portRange)
 
+	di.ShaperLimiter = shaper.NewLimiter(di.Storage, di.EventBus)
+	if err := di.ShaperLimiter.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
 	di.bootstrapP2P()
 	di.SessionConnectivityStatusStorage = connectivity.NewStatusStorage()
 
@@ -321,6 +345,9 @@ func (di *Dependencies) bootstrapP2P() {
 
 	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.SignerFactory, identity.NewVerifierSigned(), di.IPResolver, di.EventBus)
 	di.P2PDialer = p2p.NewDialer(di.BrokerConnector, di.SignerFactory, verifierFactory, di.IPResolver, di.PortPool, di.EventBus)
+
+	di.LatencyProber = latency.NewProber(p2p.NewEchoPinger(di.SignerFactory, verifierFactory))
+	di.ProposalRepository.SetLatency(di.LatencyProber)
 }
 
 func (di *Dependencies) createTequilaListener(nodeOptions node.Options) (net.Listener, error) {
@@ -401,6 +428,10 @@ func (di *Dependencies) Shutdown() (err error) {
 		di.PolicyOracle.Stop()
 	}
 
+	if di.ServiceResources != nil {
+		di.ServiceResources.Stop()
+	}
+
 	if di.NATService != nil {
 		if err := di.NATService.Disable(); err != nil {
 			errs = append(errs, err)
@@ -421,6 +452,14 @@ func (di *Dependencies) Shutdown() (err error) {
 		di.SorterClientL2.Stop()
 	}
 
+	if di.ConnectionScheduler != nil {
+		di.ConnectionScheduler.Stop()
+	}
+
+	if di.DNSFilter != nil {
+		di.DNSFilter.Stop()
+	}
+
 	if di.DiscoveryWorker != nil {
 		di.DiscoveryWorker.Stop()
 	}
@@ -471,6 +510,11 @@ func (di *Dependencies) bootstrapStorage(path string) error {
 	di.HermesPromiseStorage = pingpong.NewHermesPromiseStorage(di.Storage)
 	di.SessionStorage = consumer_session.NewSessionStorage(di.Storage)
 	di.SettlementHistoryStorage = pingpong.NewSettlementHistoryStorage(di.Storage)
+	di.ConnectionScheduleStorage = schedule.NewStorage(di.Storage)
+	di.ProviderReputation = reputation.NewStorage(di.Storage)
+	if err := di.ProviderReputation.Subscribe(di.EventBus); err != nil {
+		return err
+	}
 	return di.SessionStorage.Subscribe(di.EventBus)
 }
 
@@ -568,10 +612,24 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 			di.IdentityManager,
 		),
 		di.P2PDialer,
+		di.bootstrapDNSFilter(),
 		di.allowTrustedDomainBypassTunnel,
 		di.disallowTrustedDomainBypassTunnel,
 	)
 
+	di.ConnectionScheduler = schedule.NewScheduler(
+		di.ConnectionScheduleStorage,
+		di.ConnectionManager,
+		di.ProposalRepository,
+		di.AddressProvider,
+		gatewayDiscoverer(),
+		schedule.DefaultCheckInterval,
+	)
+	if err := di.ConnectionScheduler.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	di.ConnectionScheduler.Start()
+
 	di.NATProber = natprobe.NewNATProber(di.ConnectionManager, di.EventBus)
 
 	di.LogCollector = logconfig.NewCollector(&logconfig.CurrentLogOptions)
@@ -922,6 +980,38 @@ func (di *Dependencies) bootstrapPilvytis(options node.Options) {
 	di.PilvytisTracker.SubscribeAsync(di.EventBus)
 }
 
+func (di *Dependencies) bootstrapDNSFilter() connection.DNSFilter {
+	di.DNSFilter = dns.NewFilter(
+		config.GetStringSlice(config.FlagDNSFilterLists),
+		config.GetDuration(config.FlagDNSFilterRefreshInterval),
+	)
+	if err := di.DNSFilter.SetOverrides(
+		config.GetStringSlice(config.FlagDNSFilterAllow),
+		config.GetStringSlice(config.FlagDNSFilterDeny),
+	); err != nil {
+		log.Warn().Err(err).Msg("Ignoring invalid DNS filter overrides")
+	}
+	di.DNSFilter.Start()
+
+	upstreams := config.GetStringSlice(config.FlagDNSFilterUpstreams)
+	for _, addr := range upstreams {
+		if _, err := dns.NewUpstream(addr); err != nil {
+			log.Warn().Err(err).Msg("Filtered DNS option is disabled")
+			return nil
+		}
+	}
+
+	address := connection.FilteredDNSAddress()
+	if ip := net.ParseIP(address); ip == nil || !ip.IsLoopback() {
+		log.Warn().Msgf("Filtered DNS option is disabled, %q is not a loopback address", address)
+		return nil
+	}
+	return dns.NewFilterProxy(address, 53, dns.ResolverOptions{
+		Upstreams: upstreams,
+		CacheSize: config.GetInt(config.FlagDNSCacheSize),
+	}, di.DNSFilter)
+}
+
 func (di *Dependencies) bootstrapFirewall(options node.OptionsFirewall) error {
 	firewall.DefaultOutgoingFirewall = firewall.NewOutgoingTrafficFirewall(config.GetBool(config.FlagOutgoingFirewall))
 	if err := firewall.DefaultOutgoingFirewall.Setup(); err != nil {
@@ -1060,6 +1150,14 @@ func getUDPListenPorts() (port.Range, error) {
 	return udpPortRange, nil
 }
 
+// gatewayDiscoverer returns the routing table used by router to detect the system default gateway.
+func gatewayDiscoverer() schedule.GatewayDiscoverer {
+	if config.GetBool(config.FlagUserMode) {
+		return &router_network.RoutingTableRemote{}
+	}
+	return &router_network.RoutingTable{}
+}
+
 func (di *Dependencies) allowTrustedDomainBypassTunnel() {
 	allow := []string{di.NetworkDefinition.MysteriumAPIAddress}
 	allow = append(allow, di.NetworkDefinition.BrokerAddresses...)
@@ -18,6 +18,8 @@
 package cmd
 
 import (
+	"net"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
@@ -25,10 +27,12 @@ import (
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/service/sysmon"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/mmn"
 	"github.com/mysteriumnetwork/node/nat"
@@ -36,6 +40,9 @@ import (
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn/service"
+	service_proxy "github.com/mysteriumnetwork/node/services/proxy"
+	proxy_connection "github.com/mysteriumnetwork/node/services/proxy/connection"
+	proxy_service "github.com/mysteriumnetwork/node/services/proxy/service"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	wireguard_connection "github.com/mysteriumnetwork/node/services/wireguard/connection"
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint"
@@ -60,6 +67,7 @@ func (di *Dependencies) bootstrapServices(nodeOptions node.Options) error {
 	di.bootstrapServiceOpenvpn(nodeOptions)
 	di.bootstrapServiceNoop(nodeOptions)
 	di.bootstrapServiceWireguard(nodeOptions)
+	di.bootstrapServiceProxy(nodeOptions)
 
 	return nil
 }
@@ -83,6 +91,8 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
 				wgOptions,
 				di.PortPool,
 				di.ServiceFirewall,
+				di.ServiceSessions,
+				di.ShaperLimiter,
 			)
 			return svc, nil
 		},
@@ -127,6 +137,15 @@ func (di *Dependencies) bootstrapServiceNoop(nodeOptions node.Options) {
 	)
 }
 
+func (di *Dependencies) bootstrapServiceProxy(nodeOptions node.Options) {
+	di.ServiceRegistry.Register(
+		service_proxy.ServiceType,
+		func(serviceOptions service.Options) (service.Service, error) {
+			return proxy_service.NewManager(di.EventBus, serviceOptions.(proxy_service.Options))
+		},
+	)
+}
+
 func (di *Dependencies) bootstrapProviderRegistrar(nodeOptions node.Options) error {
 	if nodeOptions.Consumer {
 		log.Debug().Msg("Skipping provider registrar for consumer mode")
@@ -204,6 +223,9 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 	di.ServiceRegistry = service.NewRegistry()
 
 	di.ServiceSessions = service.NewSessionPool(di.EventBus)
+	di.ServiceBanList = service.NewBanList(di.Storage)
+	di.ServiceResources = sysmon.NewMonitor(10 * time.Second)
+	go di.ServiceResources.Start()
 
 	di.PolicyOracle = policy.NewOracle(
 		di.HTTPClient,
@@ -212,6 +234,18 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 	)
 	go di.PolicyOracle.Start()
 
+	localPolicies, err := policy.NewLocalPolicies(di.Storage, config.GetStringSlice(config.FlagAccessPolicyLocalFiles))
+	if err != nil {
+		return errors.Wrap(err, "could not load local access policies")
+	}
+	di.LocalPolicies = localPolicies
+
+	// Country rules of the access policies are checked against the country of the consumer address.
+	consumerCountryResolver, err := location.NewBuiltInResolver(di.IPResolver)
+	if err != nil {
+		return errors.Wrap(err, "could not load consumer country database")
+	}
+
 	di.HermesStatusChecker = pingpong.NewHermesStatusChecker(di.BCHelper, nodeOptions.Payments.HermesStatusRecheckInterval)
 
 	newP2PSessionHandler := func(serviceInstance *service.Instance, channel p2p.Channel) *service.SessionManager {
@@ -234,18 +268,37 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 			channel,
 			service.DefaultConfig(),
 			di.PricingHelper,
+			di.ServiceBanList,
+			consumerCountryResolver,
 		)
 	}
 
+	pricingRules, err := pingpong.ParsePricingRules(
+		config.GetStringSlice(config.FlagPaymentPriceSchedule),
+		config.GetStringSlice(config.FlagPaymentPriceLoad),
+		config.GetStringSlice(config.FlagPaymentPriceDiscounts),
+	)
+	if err != nil {
+		return errors.Wrap(err, "invalid pricing policy")
+	}
+	var pricingPolicy service.PricingPolicy
+	if !pricingRules.Empty() {
+		pricingPolicy = pingpong.NewPricingPolicy(di.PricingHelper, di.ServiceSessions, pricingRules)
+	}
+
 	di.ServicesManager = service.NewManager(
 		di.ServiceRegistry,
 		di.DiscoveryFactory,
 		di.EventBus,
 		di.PolicyOracle,
+		di.LocalPolicies,
 		di.P2PListener,
 		newP2PSessionHandler,
 		di.SessionConnectivityStatusStorage,
 		di.LocationResolver,
+		pricingPolicy,
+		di.ServiceSessions,
+		di.ServiceResources,
 	)
 
 	serviceCleaner := service.Cleaner{SessionStorage: di.ServiceSessions}
@@ -260,13 +313,15 @@ func (di *Dependencies) registerConnections(nodeOptions node.Options) {
 	di.registerOpenvpnConnection(nodeOptions)
 	di.registerNoopConnection()
 	di.registerWireguardConnection(nodeOptions)
+	di.registerProxyConnection()
 }
 
 func (di *Dependencies) registerWireguardConnection(nodeOptions node.Options) {
 	wireguard.Bootstrap()
 	handshakeWaiter := wireguard_connection.NewHandshakeWaiter()
+	// Allocator is shared, so multi-hop connection endpoints don't treat each other's interfaces as abandoned.
+	resourceAllocator := resources.NewAllocator(nil, wireguard_service.DefaultOptions.Subnet)
 	endpointFactory := func() (wireguard.ConnectionEndpoint, error) {
-		resourceAllocator := resources.NewAllocator(nil, wireguard_service.DefaultOptions.Subnet)
 		return endpoint.NewConnectionEndpoint(resourceAllocator)
 	}
 	connFactory := func() (connection.Connection, error) {
@@ -279,6 +334,19 @@ func (di *Dependencies) registerWireguardConnection(nodeOptions node.Options) {
 	di.ConnectionRegistry.Register(wireguard.ServiceType, connFactory)
 }
 
+func (di *Dependencies) registerProxyConnection() {
+	service_proxy.Bootstrap()
+	connFactory := func() (connection.Connection, error) {
+		opts := proxy_connection.Options{
+			Address:  net.JoinHostPort(config.GetString(config.FlagProxyAddress), strconv.Itoa(config.GetInt(config.FlagProxyPort))),
+			Username: config.GetString(config.FlagProxyUsername),
+			Password: config.GetString(config.FlagProxyPassword),
+		}
+		return proxy_connection.NewConnection(opts)
+	}
+	di.ConnectionRegistry.Register(service_proxy.ServiceType, connFactory)
+}
+
 func (di *Dependencies) bootstrapMMN() error {
 	client := mmn.NewClient(di.HTTPClient, config.GetString(config.FlagMMNAPIAddress), di.SignerFactory)
 
@@ -25,15 +25,19 @@ import (
 	"github.com/mysteriumnetwork/node/core/discovery/apidiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/dhtdiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/mdnsdiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/stream"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/pkg/errors"
 )
 
 func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscovery) error {
 	di.FilterPresetStorage = proposal.NewFilterPresetStorage(di.Storage)
 	proposalRepository := discovery.NewRepository()
+	proposalRepository.SetCache(discovery.NewProposalCache(di.Storage))
 	proposalRegistry := discovery.NewRegistry()
 	discoveryWorker := discovery.NewWorker()
 
@@ -42,7 +46,11 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 		case node.DiscoveryTypeAPI:
 			// Broker is the way to announce node presence currently, so enabled by default no matter the users preferences.
 			proposalRegistry.AddRegistry(brokerdiscovery.NewRegistry(di.BrokerConnection))
-			proposalRepository.Add(apidiscovery.NewRepository(di.MysteriumAPI))
+			apiRepository := apidiscovery.NewRepository(di.MysteriumAPI, di.EventBus, options.FetchInterval)
+			if options.FetchEnabled {
+				discoveryWorker.AddWorker(apiRepository)
+			}
+			proposalRepository.Add(apiRepository)
 
 		case node.DiscoveryTypeBroker:
 			storage := brokerdiscovery.NewStorage(di.EventBus)
@@ -58,26 +66,62 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 			dhtNode, err := dhtdiscovery.NewNode(
 				fmt.Sprintf("/ip4/%s/%s/%d", options.DHT.Address, options.DHT.Protocol, options.DHT.Port),
 				options.DHT.BootstrapPeers,
+				func(id identity.Identity) identity.Verifier {
+					return identity.NewVerifierIdentity(id)
+				},
 			)
 			if err != nil {
 				return errors.Wrap(err, "failed to configure DHT node")
 			}
 			discoveryWorker.AddWorker(dhtNode)
 
-			proposalRegistry.AddRegistry(dhtdiscovery.NewRegistry())
-			proposalRepository.Add(dhtdiscovery.NewRepository())
+			// Proposal records survive a single missed ping.
+			proposalRegistry.AddRegistry(dhtdiscovery.NewRegistry(dhtNode, 2*options.PingInterval))
+
+			dhtRepository := dhtdiscovery.NewRepository(dhtNode, brokerdiscovery.NewStorage(di.EventBus), options.FetchInterval)
+			if options.FetchEnabled {
+				discoveryWorker.AddWorker(dhtRepository)
+			}
+			proposalRepository.Add(dhtRepository)
+
+		case node.DiscoveryTypeMDNS:
+			// Proposal records survive a single missed ping.
+			proposalRegistry.AddRegistry(mdnsdiscovery.NewRegistry(2 * options.PingInterval))
+
+			mdnsRepository := mdnsdiscovery.NewRepository(
+				brokerdiscovery.NewStorage(di.EventBus),
+				func(id identity.Identity) identity.Verifier {
+					return identity.NewVerifierIdentity(id)
+				},
+				options.FetchInterval,
+			)
+			if options.FetchEnabled {
+				discoveryWorker.AddWorker(mdnsRepository)
+			}
+			proposalRepository.Add(mdnsRepository)
 
 		default:
 			return errors.Errorf("unknown discovery adapter: %s", discoveryType)
 		}
 	}
 
+	// Proposals are stored into the cache in background, after the delegates fetched them.
+	discoveryWorker.AddWorker(proposalRepository)
+
 	di.DiscoveryWorker = discoveryWorker
 	if err := di.DiscoveryWorker.Start(); err != nil {
 		return errors.Wrap(err, "failed to start discovery")
 	}
 
 	di.ProposalRepository = discovery.NewPricedServiceProposalRepository(proposalRepository, di.PricingHelper, di.FilterPresetStorage)
+	di.ProposalRepository.SetReputation(di.ProviderReputation)
+
+	di.ProposalStream = stream.NewStream(di.ProposalRepository)
+	if err := di.ProposalStream.Subscribe(di.EventBus); err != nil {
+		return errors.Wrap(err, "failed to subscribe proposal stream")
+	}
+	di.ProposalWebhookStorage = stream.NewWebhookStorage(di.Storage)
+
 	di.DiscoveryFactory = func() service.Discovery {
 		return discovery.NewService(di.IdentityRegistry, proposalRegistry, options.PingInterval, di.SignerFactory, di.EventBus)
 	}
@@ -27,7 +27,10 @@ import (
 	"github.com/mysteriumnetwork/node/cmd/commands/connection"
 	"github.com/mysteriumnetwork/node/cmd/commands/daemon"
 	"github.com/mysteriumnetwork/node/cmd/commands/license"
+	"github.com/mysteriumnetwork/node/cmd/commands/presets"
+	"github.com/mysteriumnetwork/node/cmd/commands/providers"
 	"github.com/mysteriumnetwork/node/cmd/commands/reset"
+	"github.com/mysteriumnetwork/node/cmd/commands/schedule"
 	"github.com/mysteriumnetwork/node/cmd/commands/service"
 	"github.com/mysteriumnetwork/node/cmd/commands/version"
 	"github.com/mysteriumnetwork/node/config"
@@ -52,6 +55,9 @@ var (
 	resetCommand      = reset.NewCommand()
 	accountCommand    = account.NewCommand()
 	connectionCommand = connection.NewCommand()
+	scheduleCommand   = schedule.NewCommand()
+	presetsCommand    = presets.NewCommand()
+	providersCommand  = providers.NewCommand()
 	configCommand     = command_cfg.NewCommand()
 )
 
@@ -98,6 +104,9 @@ func NewCommand() (*cli.App, error) {
 		resetCommand,
 		accountCommand,
 		connectionCommand,
+		scheduleCommand,
+		presetsCommand,
+		providersCommand,
 		configCommand,
 	}
 
@@ -115,6 +124,9 @@ var uiCommands = map[string]struct{}{
 	command_cli.CommandName: {},
 	account.CommandName:     {},
 	connection.CommandName:  {},
+	schedule.CommandName:    {},
+	presets.CommandName:     {},
+	providers.CommandName:   {},
 	command_cfg.CommandName: {},
 	reset.CommandName:       {},
 }
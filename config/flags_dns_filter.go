@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagDNSFilterLists sets the blocklists of consumer filtering DNS.
+	FlagDNSFilterLists = cli.StringSliceFlag{
+		Name:  "dns.filter.lists",
+		Usage: "Blocklists of filtering DNS option in hosts, AdBlock or RPZ format, given as local file paths or URLs",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagDNSFilterRefreshInterval sets how often blocklists are reloaded.
+	FlagDNSFilterRefreshInterval = cli.DurationFlag{
+		Name:  "dns.filter.refresh-interval",
+		Usage: "Interval of reloading filtering DNS blocklists",
+		Value: 24 * time.Hour,
+	}
+	// FlagDNSFilterUpstreams sets the upstream resolvers of consumer filtering DNS.
+	FlagDNSFilterUpstreams = cli.StringSliceFlag{
+		Name:  "dns.filter.upstreams",
+		Usage: "Upstream resolvers of filtering DNS option, tried in the given order, e.g. https://cloudflare-dns.com/dns-query, tls://1.1.1.1, udp://8.8.8.8:53. DNS servers of the provider are used if empty",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagDNSFilterAllow sets the domains never blocked by consumer filtering DNS.
+	FlagDNSFilterAllow = cli.StringSliceFlag{
+		Name:  "dns.filter.allow",
+		Usage: "Domains which are never blocked by filtering DNS option, including their subdomains",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagDNSFilterAddress sets the loopback address consumer filtering DNS listens on.
+	FlagDNSFilterAddress = cli.StringFlag{
+		Name:  "dns.filter.address",
+		Usage: "Loopback address filtering DNS option listens on, e.g. 127.0.0.153 if port 53 of 127.0.0.1 is used by another DNS resolver",
+		Value: "127.0.0.1",
+	}
+	// FlagDNSFilterDeny sets the domains always blocked by consumer filtering DNS.
+	FlagDNSFilterDeny = cli.StringSliceFlag{
+		Name:  "dns.filter.deny",
+		Usage: "Domains which are always blocked by filtering DNS option, including their subdomains",
+		Value: cli.NewStringSlice(),
+	}
+)
+
+// RegisterFlagsDNSFilter function registers consumer DNS filtering flags to flag list.
+func RegisterFlagsDNSFilter(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagDNSFilterLists,
+		&FlagDNSFilterRefreshInterval,
+		&FlagDNSFilterUpstreams,
+		&FlagDNSFilterAllow,
+		&FlagDNSFilterDeny,
+		&FlagDNSFilterAddress,
+	)
+}
+
+// ParseFlagsDNSFilter function fills in consumer DNS filtering options from CLI context.
+func ParseFlagsDNSFilter(ctx *cli.Context) {
+	Current.ParseStringSliceFlag(ctx, FlagDNSFilterLists)
+	Current.ParseDurationFlag(ctx, FlagDNSFilterRefreshInterval)
+	Current.ParseStringSliceFlag(ctx, FlagDNSFilterUpstreams)
+	Current.ParseStringSliceFlag(ctx, FlagDNSFilterAllow)
+	Current.ParseStringSliceFlag(ctx, FlagDNSFilterDeny)
+	Current.ParseStringFlag(ctx, FlagDNSFilterAddress)
+}
@@ -36,7 +36,7 @@ var (
 	// FlagDiscoveryType proposal discovery adapter.
 	FlagDiscoveryType = cli.StringSliceFlag{
 		Name:  "discovery.type",
-		Usage: `Proposal discovery adapter(s) separated by comma. Options: { "api", "broker", "api,broker,dht" }`,
+		Usage: `Proposal discovery adapter(s) separated by comma. Options: { "api", "broker", "dht", "mdns", "api,broker,dht" }`,
 		Value: cli.NewStringSlice("api"),
 	}
 	// FlagDiscoveryPingInterval proposal ping interval in seconds.
@@ -303,6 +303,8 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 	RegisterFlagsMMN(flags)
 	RegisterFlagsPilvytis(flags)
 	RegisterFlagsChains(flags)
+	RegisterFlagsDNSFilter(flags)
+	RegisterFlagsProxy(flags)
 
 	*flags = append(*flags,
 		&FlagBindAddress,
@@ -360,6 +362,8 @@ func ParseFlagsNode(ctx *cli.Context) {
 	ParseFlagsMMN(ctx)
 	ParseFlagPilvytis(ctx)
 	ParseFlagsChains(ctx)
+	ParseFlagsDNSFilter(ctx)
+	ParseFlagsProxy(ctx)
 
 	Current.ParseStringFlag(ctx, FlagBindAddress)
 	Current.ParseStringSliceFlag(ctx, FlagDiscoveryType)
@@ -37,6 +37,11 @@ var (
 		Usage: `Proposal fetch interval { "30s", "3m", "1h20m30s" }`,
 		Value: 10 * time.Minute,
 	}
+	// FlagAccessPolicyLocalFiles files with access policies defined by the provider.
+	FlagAccessPolicyLocalFiles = cli.StringSliceFlag{
+		Name:  "access-policy.local-files",
+		Usage: "JSON files with access policies applied to every provided service, in addition to policies managed through Tequilapi",
+	}
 )
 
 // RegisterFlagsPolicy function registers Policy Oracle flags to flag list.
@@ -44,6 +49,7 @@ func RegisterFlagsPolicy(flags *[]cli.Flag) {
 	*flags = append(*flags,
 		&FlagAccessPolicyAddress,
 		&FlagAccessPolicyFetchInterval,
+		&FlagAccessPolicyLocalFiles,
 	)
 }
 
@@ -51,4 +57,5 @@ func RegisterFlagsPolicy(flags *[]cli.Flag) {
 func ParseFlagsPolicy(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagAccessPolicyAddress)
 	Current.ParseDurationFlag(ctx, FlagAccessPolicyFetchInterval)
+	Current.ParseStringSliceFlag(ctx, FlagAccessPolicyLocalFiles)
 }
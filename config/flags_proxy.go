@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagProxyAddress sets the address of consumer local proxy.
+	FlagProxyAddress = cli.StringFlag{
+		Name:  "proxy.address",
+		Usage: "Address of the local SOCKS5 and HTTP CONNECT proxy of proxy service connection",
+		Value: "127.0.0.1",
+	}
+	// FlagProxyPort sets the port of consumer local proxy.
+	FlagProxyPort = cli.IntFlag{
+		Name:  "proxy.port",
+		Usage: "Port of the local SOCKS5 and HTTP CONNECT proxy of proxy service connection",
+		Value: 1080,
+	}
+	// FlagProxyUsername sets the username required from clients of consumer local proxy.
+	FlagProxyUsername = cli.StringFlag{
+		Name:  "proxy.auth.username",
+		Usage: "Username required from the local proxy clients, authentication is disabled if empty",
+	}
+	// FlagProxyPassword sets the password required from clients of consumer local proxy.
+	FlagProxyPassword = cli.StringFlag{
+		Name:  "proxy.auth.password",
+		Usage: "Password required from the local proxy clients",
+	}
+)
+
+// RegisterFlagsProxy function registers consumer local proxy flags to flag list.
+func RegisterFlagsProxy(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagProxyAddress,
+		&FlagProxyPort,
+		&FlagProxyUsername,
+		&FlagProxyPassword,
+	)
+}
+
+// ParseFlagsProxy function fills in consumer local proxy options from CLI context.
+func ParseFlagsProxy(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagProxyAddress)
+	Current.ParseIntFlag(ctx, FlagProxyPort)
+	Current.ParseStringFlag(ctx, FlagProxyUsername)
+	Current.ParseStringFlag(ctx, FlagProxyPassword)
+}
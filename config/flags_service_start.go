@@ -18,6 +18,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/urfave/cli/v2"
 )
 
@@ -60,6 +62,85 @@ var (
 		Usage: "Sets the price/hour applied to provider service.",
 		Value: 0.00006,
 	}
+	// FlagPaymentPriceSchedule sets the time of day price factors of provided service.
+	FlagPaymentPriceSchedule = cli.StringSliceFlag{
+		Name:  "payment.price-schedule",
+		Usage: "Price factors applied during daily time windows in local time, e.g. 22:00-06:00=0.8",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagPaymentPriceLoad sets the load based price surcharges of provided service.
+	FlagPaymentPriceLoad = cli.StringSliceFlag{
+		Name:  "payment.price-load",
+		Usage: "Price factors applied once the count of active sessions is reached, e.g. 20=1.2",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagPaymentPriceDiscounts sets the consumer discounts of provided service.
+	FlagPaymentPriceDiscounts = cli.StringSliceFlag{
+		Name:  "payment.price-discounts",
+		Usage: "Price factors applied to consumer identities or consumers allowed by access policies, e.g. 0x0000000000000000000000000000000000000001=0.5, verified-traffic=0.9",
+		Value: cli.NewStringSlice(),
+	}
+
+	// FlagServiceMaxSessions limits concurrent sessions of provided service.
+	FlagServiceMaxSessions = cli.IntFlag{
+		Name:  "service.max-sessions",
+		Usage: "Maximum count of concurrent sessions served by the service, 0 means unlimited",
+		Value: 0,
+	}
+	// FlagServiceMaxConsumerSessions limits concurrent sessions single consumer may have with the provider.
+	FlagServiceMaxConsumerSessions = cli.IntFlag{
+		Name:  "service.max-consumer-sessions",
+		Usage: "Maximum count of concurrent sessions single consumer identity may have with the provider, 0 means unlimited",
+		Value: 0,
+	}
+	// FlagServiceMinFreeCPU sets the CPU headroom of provided service.
+	FlagServiceMinFreeCPU = cli.Float64Flag{
+		Name:  "service.min-free-cpu",
+		Usage: "Share of CPU time in percent which has to stay idle, new sessions are rejected otherwise",
+		Value: 0,
+	}
+	// FlagServiceMinFreeMemory sets the memory headroom of provided service.
+	FlagServiceMinFreeMemory = cli.Uint64Flag{
+		Name:  "service.min-free-memory",
+		Usage: "Memory in MiB which has to stay available, new sessions are rejected otherwise",
+		Value: 0,
+	}
+	// FlagServiceBandwidth sets the uplink bandwidth of the provider.
+	FlagServiceBandwidth = cli.Uint64Flag{
+		Name:  "service.bandwidth",
+		Usage: "Uplink bandwidth of the provider in Mbit/s, it is required to keep bandwidth headroom",
+		Value: 0,
+	}
+	// FlagServiceMinFreeBandwidth sets the bandwidth headroom of provided service.
+	FlagServiceMinFreeBandwidth = cli.Uint64Flag{
+		Name:  "service.min-free-bandwidth",
+		Usage: "Uplink bandwidth in Mbit/s which has to stay unused, new sessions are rejected otherwise",
+		Value: 0,
+	}
+	// FlagServiceDrainTimeout sets how long drained service waits for sessions to end.
+	FlagServiceDrainTimeout = cli.DurationFlag{
+		Name:  "service.drain-timeout",
+		Usage: "Time to wait for sessions to end when the service is drained, remaining sessions are closed afterwards",
+		Value: 10 * time.Minute,
+	}
+	// FlagServiceDrainOnExit drains provided services before the node exits.
+	FlagServiceDrainOnExit = cli.BoolFlag{
+		Name:  "service.drain-on-exit",
+		Usage: "Drain started services before exiting: stop accepting new sessions and wait for existing ones to end",
+	}
+
+	// FlagDNSUpstreams sets the upstream resolvers of provider DNS proxy.
+	FlagDNSUpstreams = cli.StringSliceFlag{
+		Name:  "dns.upstreams",
+		Usage: "Upstream resolvers of provider DNS, tried in the given order, e.g. https://cloudflare-dns.com/dns-query, tls://1.1.1.1, udp://8.8.8.8:53. System DNS servers are used if not set",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagDNSCacheSize sets the response cache size of provider DNS proxy.
+	FlagDNSCacheSize = cli.IntFlag{
+		Name:  "dns.cache-size",
+		Usage: "Maximum count of responses cached by provider DNS, 0 disables caching",
+		Value: 1000,
+	}
 )
 
 // RegisterFlagsServiceStart registers CLI flags used to start a service.
@@ -70,7 +151,20 @@ func RegisterFlagsServiceStart(flags *[]cli.Flag) {
 		&FlagAgreedTermsConditions,
 		&FlagPaymentPriceGiB,
 		&FlagPaymentPriceHour,
+		&FlagPaymentPriceSchedule,
+		&FlagPaymentPriceLoad,
+		&FlagPaymentPriceDiscounts,
 		&FlagAccessPolicyList,
+		&FlagServiceMaxSessions,
+		&FlagServiceMaxConsumerSessions,
+		&FlagServiceMinFreeCPU,
+		&FlagServiceMinFreeMemory,
+		&FlagServiceBandwidth,
+		&FlagServiceMinFreeBandwidth,
+		&FlagServiceDrainTimeout,
+		&FlagServiceDrainOnExit,
+		&FlagDNSUpstreams,
+		&FlagDNSCacheSize,
 	)
 }
 
@@ -81,5 +175,18 @@ func ParseFlagsServiceStart(ctx *cli.Context) {
 	Current.ParseBoolFlag(ctx, FlagAgreedTermsConditions)
 	Current.ParseFloat64Flag(ctx, FlagPaymentPriceGiB)
 	Current.ParseFloat64Flag(ctx, FlagPaymentPriceHour)
+	Current.ParseStringSliceFlag(ctx, FlagPaymentPriceSchedule)
+	Current.ParseStringSliceFlag(ctx, FlagPaymentPriceLoad)
+	Current.ParseStringSliceFlag(ctx, FlagPaymentPriceDiscounts)
 	Current.ParseStringFlag(ctx, FlagAccessPolicyList)
+	Current.ParseIntFlag(ctx, FlagServiceMaxSessions)
+	Current.ParseIntFlag(ctx, FlagServiceMaxConsumerSessions)
+	Current.ParseFloat64Flag(ctx, FlagServiceMinFreeCPU)
+	Current.ParseUInt64Flag(ctx, FlagServiceMinFreeMemory)
+	Current.ParseUInt64Flag(ctx, FlagServiceBandwidth)
+	Current.ParseUInt64Flag(ctx, FlagServiceMinFreeBandwidth)
+	Current.ParseDurationFlag(ctx, FlagServiceDrainTimeout)
+	Current.ParseBoolFlag(ctx, FlagServiceDrainOnExit)
+	Current.ParseStringSliceFlag(ctx, FlagDNSUpstreams)
+	Current.ParseIntFlag(ctx, FlagDNSCacheSize)
 }
@@ -35,6 +35,12 @@ var (
 		Usage: "Subnet to be used by the wireguard service",
 		Value: "10.182.0.0/16",
 	}
+	// FlagWireguardListenSubnet6 IPv6 subnet to be used by the wireguard service.
+	FlagWireguardListenSubnet6 = cli.StringFlag{
+		Name:  "wireguard.allowed.subnet6",
+		Usage: "IPv6 subnet (/48 or shorter) to be used by the wireguard service, IPv6 traffic is not tunnelled if empty",
+		Value: "fd00:182::/48",
+	}
 	// FlagWireguardAccessPolicies a comma-separated list of access policies that determines allowed identities to use the service.
 	FlagWireguardAccessPolicies = cli.StringFlag{
 		Name:  "wireguard.access-policies",
@@ -47,6 +53,7 @@ func RegisterFlagsServiceWireguard(flags *[]cli.Flag) {
 	*flags = append(*flags,
 		&FlagWireguardListenPorts,
 		&FlagWireguardListenSubnet,
+		&FlagWireguardListenSubnet6,
 		&FlagWireguardAccessPolicies,
 	)
 }
@@ -55,5 +62,6 @@ func RegisterFlagsServiceWireguard(flags *[]cli.Flag) {
 func ParseFlagsServiceWireguard(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagWireguardListenPorts)
 	Current.ParseStringFlag(ctx, FlagWireguardListenSubnet)
+	Current.ParseStringFlag(ctx, FlagWireguardListenSubnet6)
 	Current.ParseStringFlag(ctx, FlagWireguardAccessPolicies)
 }
@@ -17,7 +17,12 @@
 
 package entertainment
 
-import "math"
+import (
+	"math"
+	"math/big"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+)
 
 const (
 	video720pMBPerMin   = 15
@@ -49,6 +54,11 @@ func NewEstimator(pricePerGiB, pricePerMin float64) *Estimator {
 	}
 }
 
+// NewPriceEstimator creates estimator for the provider price given in wei.
+func NewPriceEstimator(pricePerGiB, pricePerHour *big.Int) *Estimator {
+	return NewEstimator(WeiToMyst(pricePerGiB), WeiToMyst(pricePerHour)/60)
+}
+
 // EstimatedEntertainment calculates average service times
 func (e *Estimator) EstimatedEntertainment(myst float64) Estimates {
 	return Estimates{
@@ -61,6 +71,14 @@ func (e *Estimator) EstimatedEntertainment(myst float64) Estimates {
 	}
 }
 
+// WeiToMyst converts token amount in wei to MYST, nil amount is zero.
+func WeiToMyst(wei *big.Int) float64 {
+	if wei == nil {
+		return 0
+	}
+	return crypto.BigMystToFloat(wei)
+}
+
 func mib2MB(mibs float64) float64 {
 	return mibs * math.Pow(2, 20) / math.Pow(10, 6)
 }
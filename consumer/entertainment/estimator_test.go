@@ -18,6 +18,7 @@
 package entertainment
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -46,3 +47,14 @@ func TestEstimator(t *testing.T) {
 	assert.Less(t, uint64(0), e.MusicMinutes)
 	assert.Less(t, uint64(0), e.BrowsingMinutes)
 }
+
+func TestPriceEstimator(t *testing.T) {
+	// given
+	estimator := NewPriceEstimator(big.NewInt(10_000_000_000_000_000), big.NewInt(6_000_000_000_000_000))
+
+	// expect
+	e := estimator.EstimatedEntertainment(5)
+	assert.Equal(t, uint64(536870), e.TrafficMB)
+	assert.InDelta(t, 0.01, e.PricePerGiB, 1e-12)
+	assert.InDelta(t, 0.0001, e.PricePerMin, 1e-12)
+}
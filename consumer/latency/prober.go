@@ -0,0 +1,180 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package latency
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+)
+
+const (
+	// DefaultCandidates is the count of proposals probed when the caller does not limit it.
+	DefaultCandidates = 10
+	// MaxCandidates bounds the count of proposals probed at once.
+	MaxCandidates = 50
+
+	probeTimeout     = 3 * time.Second
+	probeConcurrency = 8
+	resultTTL        = 10 * time.Minute
+	maxResults       = 10000
+)
+
+type echoPinger interface {
+	Echo(ctx context.Context, consumerID, providerID identity.Identity, contactDef p2p.ContactDefinition) (time.Duration, error)
+}
+
+type result struct {
+	rtt        time.Duration
+	reachable  bool
+	measuredAt time.Time
+}
+
+// Prober measures round trip time from the consumer to providers and caches it.
+// Unlike the latency reported by quality oracle, it is measured from the consumer location.
+type Prober struct {
+	pinger     echoPinger
+	timeGetter func() time.Time
+
+	mu      sync.Mutex
+	results map[string]result
+}
+
+// NewProber creates latency prober sending echo requests with the given pinger.
+func NewProber(pinger echoPinger) *Prober {
+	return &Prober{
+		pinger:     pinger,
+		timeGetter: time.Now,
+		results:    make(map[string]result),
+	}
+}
+
+// Probe concurrently pings up to limit first proposals which have no fresh measurement and returns
+// round trip times of all reachable providers among them.
+func (p *Prober) Probe(consumerID identity.Identity, proposals []proposal.PricedServiceProposal, limit int) map[string]time.Duration {
+	if limit <= 0 {
+		limit = DefaultCandidates
+	}
+	if limit > MaxCandidates {
+		limit = MaxCandidates
+	}
+	if len(proposals) > limit {
+		proposals = proposals[:limit]
+	}
+
+	var wg sync.WaitGroup
+	queue := make(chan struct{}, probeConcurrency)
+	probed := make(map[string]struct{})
+	for _, prop := range proposals {
+		providerID := normalize(prop.ProviderID)
+		if _, ok := probed[providerID]; ok {
+			continue
+		}
+		probed[providerID] = struct{}{}
+		if _, fresh := p.result(providerID); fresh {
+			continue
+		}
+
+		contact, err := p2p.ParseContact(prop.Contacts)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Skipping latency probe of provider %s", prop.ProviderID)
+			continue
+		}
+
+		wg.Add(1)
+		queue <- struct{}{}
+		go func(prop proposal.PricedServiceProposal) {
+			defer wg.Done()
+			defer func() { <-queue }()
+
+			ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+			defer cancel()
+			rtt, err := p.pinger.Echo(ctx, consumerID, identity.FromAddress(prop.ProviderID), contact)
+			if err != nil {
+				log.Debug().Err(err).Msgf("Provider %s did not reply to latency probe", prop.ProviderID)
+			}
+			p.store(normalize(prop.ProviderID), result{rtt: rtt, reachable: err == nil, measuredAt: p.timeGetter()})
+		}(prop)
+	}
+	wg.Wait()
+
+	latencies := make(map[string]time.Duration)
+	for providerID := range probed {
+		if res, fresh := p.result(providerID); fresh && res.reachable {
+			latencies[providerID] = res.rtt
+		}
+	}
+	return latencies
+}
+
+// Latency returns the cached round trip time to the provider, if it was measured recently and replied.
+func (p *Prober) Latency(providerID string) (time.Duration, bool) {
+	res, fresh := p.result(normalize(providerID))
+	return res.rtt, fresh && res.reachable
+}
+
+func (p *Prober) result(providerID string) (result, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	res, ok := p.results[providerID]
+	if ok && p.timeGetter().Sub(res.measuredAt) >= resultTTL {
+		delete(p.results, providerID)
+		return res, false
+	}
+	return res, ok
+}
+
+func (p *Prober) store(providerID string, res result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.results[providerID]; !ok && len(p.results) >= maxResults {
+		p.evict()
+	}
+	p.results[providerID] = res
+}
+
+// evict removes expired results, or the oldest one if all of them are still fresh.
+func (p *Prober) evict() {
+	now := p.timeGetter()
+	oldest := ""
+	for providerID, res := range p.results {
+		if now.Sub(res.measuredAt) >= resultTTL {
+			delete(p.results, providerID)
+			continue
+		}
+		if oldest == "" || res.measuredAt.Before(p.results[oldest].measuredAt) {
+			oldest = providerID
+		}
+	}
+	if len(p.results) >= maxResults {
+		delete(p.results, oldest)
+	}
+}
+
+func normalize(providerID string) string {
+	return strings.ToLower(providerID)
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package latency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
+)
+
+func TestProber_Probe(t *testing.T) {
+	pinger := &mockPinger{rtts: map[string]time.Duration{
+		"0x1": 80 * time.Millisecond,
+		"0x2": 20 * time.Millisecond,
+	}}
+	prober := NewProber(pinger)
+	now := time.Now()
+	prober.timeGetter = func() time.Time { return now }
+
+	proposals := []proposal.PricedServiceProposal{
+		proposalWithContact("0x1"),
+		proposalWithContact("0x2"),
+		proposalWithContact("0x3"),
+		{ServiceProposal: market.ServiceProposal{ProviderID: "0x4"}},
+		proposalWithContact("0x5"),
+	}
+
+	latencies := prober.Probe(identity.FromAddress("0xc"), proposals, 4)
+	assert.Equal(t, map[string]time.Duration{"0x1": 80 * time.Millisecond, "0x2": 20 * time.Millisecond}, latencies)
+	assert.ElementsMatch(t, []string{"0x1", "0x2", "0x3"}, pinger.pinged)
+
+	rtt, ok := prober.Latency("0x2")
+	assert.True(t, ok)
+	assert.Equal(t, 20*time.Millisecond, rtt)
+	_, ok = prober.Latency("0x3")
+	assert.False(t, ok)
+
+	// Fresh results are not probed again.
+	pinger.pinged = nil
+	prober.Probe(identity.FromAddress("0xc"), proposals, 4)
+	assert.Empty(t, pinger.pinged)
+
+	now = now.Add(resultTTL)
+	_, ok = prober.Latency("0x2")
+	assert.False(t, ok)
+	prober.Probe(identity.FromAddress("0xc"), proposals, 1)
+	assert.Equal(t, []string{"0x1"}, pinger.pinged)
+}
+
+func TestProber_EvictsResults(t *testing.T) {
+	prober := NewProber(&mockPinger{})
+	now := time.Now()
+	prober.timeGetter = func() time.Time { return now }
+
+	prober.store("0xold", result{measuredAt: now.Add(-time.Second)})
+	for i := 1; i < maxResults; i++ {
+		prober.store(fmt.Sprintf("0x%d", i), result{measuredAt: now})
+	}
+	prober.store("0xnew", result{measuredAt: now})
+	assert.Len(t, prober.results, maxResults)
+	assert.NotContains(t, prober.results, "0xold")
+
+	now = now.Add(resultTTL)
+	_, fresh := prober.result("0xnew")
+	assert.False(t, fresh)
+	assert.NotContains(t, prober.results, "0xnew")
+}
+
+func proposalWithContact(providerID string) proposal.PricedServiceProposal {
+	return proposal.PricedServiceProposal{
+		ServiceProposal: market.ServiceProposal{
+			ProviderID:  providerID,
+			ServiceType: "wireguard",
+			Contacts: market.ContactList{
+				{Type: p2p.ContactTypeV1, Definition: p2p.ContactDefinition{BrokerAddresses: []string{"nats://broker"}}},
+			},
+		},
+	}
+}
+
+type mockPinger struct {
+	mu     sync.Mutex
+	rtts   map[string]time.Duration
+	pinged []string
+}
+
+func (m *mockPinger) Echo(_ context.Context, _, providerID identity.Identity, _ p2p.ContactDefinition) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pinged = append(m.pinged, providerID.Address)
+	rtt, ok := m.rtts[providerID.Address]
+	if !ok {
+		return 0, errors.New("timeout")
+	}
+	return rtt, nil
+}
+
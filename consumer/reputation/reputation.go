@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package reputation
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+)
+
+const (
+	// connectTimeBaseline is the time to connect which gets the average speed score.
+	connectTimeBaseline = 5 * time.Second
+	// throughputBaseline is the throughput in bytes per second which gets the average throughput score.
+	throughputBaseline = 1024 * 1024
+	// smoothing is the weight of the newest measurement in the moving averages.
+	smoothing = 0.3
+)
+
+// Record is the personal reputation of a provider built from consumer's own sessions with it.
+type Record struct {
+	ProviderID string `storm:"id"`
+	Favourite  bool
+	Blocked    bool
+
+	ConnectSuccesses int
+	ConnectFailures  int
+	// ConnectTime is the moving average of the time it took to connect.
+	ConnectTime time.Duration
+	// Sessions is the count of finished sessions the throughput was measured for.
+	Sessions int
+	// Throughput is the moving average of the session throughput in bytes per second, measured while traffic flowed.
+	Throughput float64
+	// DisconnectReasons counts why connections to the provider were lost.
+	DisconnectReasons map[string]int
+
+	UpdatedAt time.Time
+}
+
+// Score returns provider score in range [0, 1] calculated from connect success rate,
+// time to connect and session throughput. Metrics which were not measured yet count as average,
+// so an unknown provider scores 0.5.
+func (r Record) Score() float64 {
+	success := float64(r.ConnectSuccesses+1) / float64(r.ConnectSuccesses+r.ConnectFailures+2)
+
+	speed := 0.5
+	if r.ConnectTime > 0 {
+		speed = 1 / (1 + r.ConnectTime.Seconds()/connectTimeBaseline.Seconds())
+	}
+
+	throughput := 0.5
+	if r.Sessions > 0 {
+		throughput = r.Throughput / (r.Throughput + throughputBaseline)
+	}
+
+	return 0.6*success + 0.2*speed + 0.2*throughput
+}
+
+// Reputation returns the reputation of the provider as attached to the proposals.
+func (r Record) Reputation() proposal.Reputation {
+	return proposal.Reputation{
+		Score:     r.Score(),
+		Favourite: r.Favourite,
+		Blocked:   r.Blocked,
+	}
+}
+
+func (r *Record) recordConnect(success bool) {
+	if success {
+		r.ConnectSuccesses++
+	} else {
+		r.ConnectFailures++
+	}
+}
+
+func (r *Record) recordConnectTime(duration time.Duration) {
+	if r.ConnectTime == 0 {
+		r.ConnectTime = duration
+		return
+	}
+	r.ConnectTime += time.Duration(smoothing * float64(duration-r.ConnectTime))
+}
+
+func (r *Record) recordThroughput(bytesPerSecond float64) {
+	if r.Sessions == 0 {
+		r.Throughput = bytesPerSecond
+	} else {
+		r.Throughput += smoothing * (bytesPerSecond - r.Throughput)
+	}
+	r.Sessions++
+}
+
+func (r *Record) recordDisconnect(reason string) {
+	if r.DisconnectReasons == nil {
+		r.DisconnectReasons = make(map[string]int)
+	}
+	r.DisconnectReasons[reason]++
+}
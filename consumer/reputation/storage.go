@@ -0,0 +1,346 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package reputation
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+const (
+	bucketName = "provider-reputation"
+	// connectTraceKey is the name of the connection manager tracer covering the whole connect.
+	connectTraceKey = "Consumer whole Connect"
+	// minActiveDuration is the shortest time of flowing traffic used to measure throughput.
+	minActiveDuration = 10 * time.Second
+	// minActiveRate is the rate in bytes per second below which the session is considered idle.
+	minActiveRate = 1024
+)
+
+type persistentStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+}
+
+// attempt tracks the current connection to the provider until it is finished.
+type attempt struct {
+	providerID string
+	connected  bool
+	reason     string
+}
+
+// traffic measures the session throughput only over the intervals while traffic flows,
+// so that idle sessions don't lower the throughput of the provider.
+type traffic struct {
+	last       connectionstate.Statistics
+	lastAt     time.Time
+	activeSize uint64
+	activeTime time.Duration
+}
+
+func (t *traffic) add(stats connectionstate.Statistics, at time.Time) {
+	if !t.lastAt.IsZero() {
+		elapsed := at.Sub(t.lastAt)
+		total, lastTotal := stats.BytesSent+stats.BytesReceived, t.last.BytesSent+t.last.BytesReceived
+		if elapsed > 0 && total > lastTotal && float64(total-lastTotal)/elapsed.Seconds() >= minActiveRate {
+			t.activeSize += total - lastTotal
+			t.activeTime += elapsed
+		}
+	}
+	t.last, t.lastAt = stats, at
+}
+
+// throughput returns the rate in bytes per second while traffic flowed.
+func (t *traffic) throughput() (float64, bool) {
+	if t.activeTime < minActiveDuration {
+		return 0, false
+	}
+	return float64(t.activeSize) / t.activeTime.Seconds(), true
+}
+
+// Storage keeps personal provider reputation records and updates them from consumer connection events.
+type Storage struct {
+	storage    persistentStorage
+	timeGetter func() time.Time
+
+	mu       sync.Mutex
+	records  map[string]*Record
+	attempt  *attempt
+	sessions map[session.ID]string
+	traffic  map[session.ID]*traffic
+}
+
+// NewStorage creates provider reputation storage.
+func NewStorage(storage persistentStorage) *Storage {
+	return &Storage{
+		storage:    storage,
+		timeGetter: time.Now,
+
+		sessions: make(map[session.ID]string),
+		traffic:  make(map[session.ID]*traffic),
+	}
+}
+
+// Subscribe subscribes to relevant events of event bus.
+func (s *Storage) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionState, s.consumeConnectionStateEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionSession, s.consumeConnectionSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionStatistics, s.consumeConnectionStatisticsEvent); err != nil {
+		return err
+	}
+	return bus.Subscribe(trace.AppTopicTraceEvent, s.consumeTraceEvent)
+}
+
+// List returns all known provider records.
+func (s *Storage) List() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	result := make([]Record, 0, len(s.records))
+	for _, record := range s.records {
+		result = append(result, *record)
+	}
+	return result, nil
+}
+
+// Get returns the record of the provider, providers without history get an empty record.
+func (s *Storage) Get(providerID string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return Record{}, err
+	}
+
+	providerID = normalize(providerID)
+	if record, ok := s.records[providerID]; ok {
+		return *record, nil
+	}
+	return Record{ProviderID: providerID}, nil
+}
+
+// Reputation returns the personal reputation of the provider attached to the proposals.
+func (s *Storage) Reputation(providerID string) proposal.Reputation {
+	record, err := s.Get(providerID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load provider reputation")
+	}
+	return record.Reputation()
+}
+
+// SetFavourite marks or unmarks the provider as favourite.
+func (s *Storage) SetFavourite(providerID string, favourite bool) (Record, error) {
+	return s.update(providerID, func(r *Record) {
+		r.Favourite = favourite
+	})
+}
+
+// SetBlocked adds or removes the provider to the blocklist.
+func (s *Storage) SetBlocked(providerID string, blocked bool) (Record, error) {
+	return s.update(providerID, func(r *Record) {
+		r.Blocked = blocked
+	})
+}
+
+func (s *Storage) update(providerID string, change func(r *Record)) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.updateLocked(providerID, change)
+}
+
+func (s *Storage) updateLocked(providerID string, change func(r *Record)) (Record, error) {
+	if err := s.load(); err != nil {
+		return Record{}, err
+	}
+
+	providerID = normalize(providerID)
+	record, ok := s.records[providerID]
+	if !ok {
+		record = &Record{ProviderID: providerID}
+	}
+
+	updated := *record
+	change(&updated)
+	updated.UpdatedAt = s.timeGetter().UTC()
+	if err := s.storage.Store(bucketName, &updated); err != nil {
+		return Record{}, err
+	}
+
+	s.records[providerID] = &updated
+	return updated, nil
+}
+
+func (s *Storage) load() error {
+	if s.records != nil {
+		return nil
+	}
+
+	var list []Record
+	if err := s.storage.GetAllFrom(bucketName, &list); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return err
+	}
+
+	s.records = make(map[string]*Record, len(list))
+	for i := range list {
+		s.records[list[i].ProviderID] = &list[i]
+	}
+	return nil
+}
+
+func (s *Storage) record(providerID string, change func(r *Record)) {
+	if providerID == "" {
+		return
+	}
+	if _, err := s.updateLocked(providerID, change); err != nil {
+		log.Error().Err(err).Msgf("Failed to update reputation of provider %s", providerID)
+	}
+}
+
+// consumeConnectionStateEvent follows connection attempt to record whether it succeeded and why it ended.
+func (s *Storage) consumeConnectionStateEvent(e connectionstate.AppEventConnectionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch e.State {
+	case connectionstate.Connecting:
+		s.finishAttempt()
+		s.attempt = &attempt{providerID: e.SessionInfo.Proposal.ProviderID}
+	case connectionstate.Connected:
+		if s.attempt != nil && !s.attempt.connected {
+			s.attempt.connected = true
+			s.record(s.attempt.providerID, func(r *Record) {
+				r.recordConnect(true)
+			})
+		}
+	case connectionstate.StateConnectionFailed,
+		connectionstate.StateIPNotChanged,
+		connectionstate.StateSpendingLimitReached,
+		connectionstate.StateOnHold,
+		connectionstate.Canceled,
+		connectionstate.Disconnecting:
+		// The first state explaining the end of the connection is its reason.
+		if s.attempt != nil && s.attempt.reason == "" {
+			s.attempt.reason = string(e.State)
+		}
+	case connectionstate.NotConnected:
+		s.finishAttempt()
+	}
+}
+
+func (s *Storage) finishAttempt() {
+	a := s.attempt
+	if a == nil {
+		return
+	}
+	s.attempt = nil
+
+	switch {
+	case a.connected:
+		reason := a.reason
+		if reason == "" {
+			reason = string(connectionstate.NotConnected)
+		}
+		s.record(a.providerID, func(r *Record) {
+			r.recordDisconnect(reason)
+		})
+	case a.reason == string(connectionstate.Canceled), a.reason == string(connectionstate.Disconnecting):
+		// Connection was canceled by the consumer, it says nothing about the provider.
+	default:
+		s.record(a.providerID, func(r *Record) {
+			r.recordConnect(false)
+		})
+	}
+}
+
+func (s *Storage) consumeConnectionSessionEvent(e connectionstate.AppEventConnectionSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionID := e.SessionInfo.SessionID
+	switch e.Status {
+	case connectionstate.SessionCreatedStatus:
+		s.sessions[sessionID] = e.SessionInfo.Proposal.ProviderID
+		s.traffic[sessionID] = &traffic{}
+	case connectionstate.SessionEndedStatus:
+		t, ok := s.traffic[sessionID]
+		delete(s.traffic, sessionID)
+		delete(s.sessions, sessionID)
+		if !ok {
+			return
+		}
+
+		throughput, ok := t.throughput()
+		if !ok {
+			return
+		}
+		s.record(e.SessionInfo.Proposal.ProviderID, func(r *Record) {
+			r.recordThroughput(throughput)
+		})
+	}
+}
+
+func (s *Storage) consumeConnectionStatisticsEvent(e connectionstate.AppEventConnectionStatistics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.traffic[e.SessionInfo.SessionID]; ok {
+		t.add(e.Stats, s.timeGetter())
+	}
+}
+
+// consumeTraceEvent records the time to connect, trace events only carry the session ID.
+func (s *Storage) consumeTraceEvent(e trace.Event) {
+	if e.Key != connectTraceKey {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	providerID, ok := s.sessions[session.ID(e.ID)]
+	if !ok || s.attempt == nil || !s.attempt.connected {
+		return
+	}
+	s.record(providerID, func(r *Record) {
+		r.recordConnectTime(e.Duration)
+	})
+}
+
+func normalize(providerID string) string {
+	return strings.ToLower(providerID)
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package reputation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+func TestRecord_Score(t *testing.T) {
+	unknown := Record{}
+	assert.Equal(t, 0.5, unknown.Score())
+
+	reliable := Record{ConnectSuccesses: 10, ConnectTime: time.Second, Sessions: 1, Throughput: 10 * throughputBaseline}
+	failing := Record{ConnectFailures: 5}
+	assert.Greater(t, reliable.Score(), unknown.Score())
+	assert.Less(t, failing.Score(), unknown.Score())
+	assert.LessOrEqual(t, reliable.Score(), 1.0)
+}
+
+func TestStorage_RecordsConnectionOutcomes(t *testing.T) {
+	storage := NewStorage(&mockStorage{})
+	now := time.Now()
+	storage.timeGetter = func() time.Time { return now }
+	provider := status("0xAA", "session1", now.Add(-time.Minute))
+	statistics := func(elapsed time.Duration, bytes uint64) {
+		now = now.Add(elapsed)
+		storage.consumeConnectionStatisticsEvent(connectionstate.AppEventConnectionStatistics{
+			Stats:       connectionstate.Statistics{BytesSent: bytes / 10, BytesReceived: bytes - bytes/10},
+			SessionInfo: provider,
+		})
+	}
+
+	// successful session
+	storage.consumeConnectionStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.Connecting, SessionInfo: provider})
+	storage.consumeConnectionSessionEvent(connectionstate.AppEventConnectionSession{Status: connectionstate.SessionCreatedStatus, SessionInfo: provider})
+	storage.consumeConnectionStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.Connected, SessionInfo: provider})
+	storage.consumeTraceEvent(trace.Event{ID: "session1", Key: connectTraceKey, Duration: 2 * time.Second})
+	statistics(0, 0)
+	statistics(10*time.Second, 10*1024*1024)
+	// idle traffic doesn't lower the throughput
+	statistics(time.Minute, 10*1024*1024+100)
+	statistics(10*time.Second, 20*1024*1024+100)
+	storage.consumeConnectionStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.Disconnecting, SessionInfo: provider})
+	storage.consumeConnectionSessionEvent(connectionstate.AppEventConnectionSession{Status: connectionstate.SessionEndedStatus, SessionInfo: provider})
+	storage.consumeConnectionStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.NotConnected, SessionInfo: provider})
+
+	record, err := storage.Get("0xaa")
+	require.NoError(t, err)
+	assert.Equal(t, 1, record.ConnectSuccesses)
+	assert.Equal(t, 0, record.ConnectFailures)
+	assert.Equal(t, 2*time.Second, record.ConnectTime)
+	assert.Equal(t, 1, record.Sessions)
+	assert.InDelta(t, 1024*1024, record.Throughput, 10*1024)
+	assert.Equal(t, map[string]int{"Disconnecting": 1}, record.DisconnectReasons)
+
+	// failed and canceled connection attempts
+	failing := status("0xBB", "", time.Now())
+	storage.consumeConnectionStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.Connecting, SessionInfo: failing})
+	storage.consumeConnectionStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.StateConnectionFailed, SessionInfo: failing})
+	storage.consumeConnectionStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.Canceled, SessionInfo: failing})
+	storage.consumeConnectionStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.NotConnected, SessionInfo: failing})
+
+	storage.consumeConnectionStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.Connecting, SessionInfo: failing})
+	storage.consumeConnectionStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.Canceled, SessionInfo: failing})
+	storage.consumeConnectionStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.NotConnected, SessionInfo: failing})
+
+	record, err = storage.Get("0xbb")
+	require.NoError(t, err)
+	assert.Equal(t, 0, record.ConnectSuccesses)
+	assert.Equal(t, 1, record.ConnectFailures)
+	assert.Less(t, record.Score(), 0.5)
+
+	records, err := storage.List()
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+}
+
+func TestStorage_FavouriteAndBlocked(t *testing.T) {
+	backend := &mockStorage{}
+	storage := NewStorage(backend)
+
+	_, err := storage.SetFavourite("0xAA", true)
+	require.NoError(t, err)
+	_, err = storage.SetBlocked("0xBB", true)
+	require.NoError(t, err)
+
+	assert.Equal(t, proposal.Reputation{Score: 0.5, Favourite: true}, storage.Reputation("0xAA"))
+	assert.Equal(t, proposal.Reputation{Score: 0.5, Blocked: true}, storage.Reputation("0xbb"))
+	assert.Equal(t, proposal.Reputation{Score: 0.5}, storage.Reputation("0xCC"))
+
+	reloaded := NewStorage(backend)
+	assert.True(t, reloaded.Reputation("0xaa").Favourite)
+
+	_, err = reloaded.SetBlocked("0xBB", false)
+	require.NoError(t, err)
+	assert.False(t, reloaded.Reputation("0xbb").Blocked)
+}
+
+func status(providerID string, sessionID session.ID, startedAt time.Time) connectionstate.Status {
+	return connectionstate.Status{
+		StartedAt: startedAt,
+		SessionID: sessionID,
+		Proposal: proposal.PricedServiceProposal{
+			ServiceProposal: market.ServiceProposal{ProviderID: providerID},
+		},
+	}
+}
+
+type mockStorage struct {
+	records []Record
+}
+
+func (m *mockStorage) Store(_ string, data interface{}) error {
+	record := *data.(*Record)
+	for i := range m.records {
+		if m.records[i].ProviderID == record.ProviderID {
+			m.records[i] = record
+			return nil
+		}
+	}
+	m.records = append(m.records, record)
+	return nil
+}
+
+func (m *mockStorage) GetAllFrom(_ string, data interface{}) error {
+	*data.(*[]Record) = append([]Record(nil), m.records...)
+	return nil
+}
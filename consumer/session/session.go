@@ -53,6 +53,10 @@ type History struct {
 	DataReceived    uint64
 	Tokens          *big.Int
 
+	// Bandwidth limits of the provided session in Kbytes per second, zero means unlimited.
+	BandwidthUp   uint64
+	BandwidthDown uint64
+
 	IPType string
 
 	Status  string
@@ -22,6 +22,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/session"
@@ -33,6 +34,15 @@ type ConnectParams struct {
 	DisableKillSwitch bool
 	// DNS servers to use
 	DNS DNSOption
+	// Hops are the ordered multi-hop entry proposals, starting from the one connected directly.
+	// Proposal passed to Connect is used as the exit hop.
+	Hops []ProposalLookup
+	// Failover policy switching to the next best proposal when session checks keep failing
+	Failover FailoverPolicy
+	// SplitTunnel rules defining which traffic bypasses the tunnel
+	SplitTunnel SplitTunnel
+	// SpendingLimits are the hard limits of each session, invoices exceeding them are refused
+	SpendingLimits connectionstate.SpendingLimits
 }
 
 // ConnectOptions represents the params we need to ensure a successful connection
@@ -46,4 +56,10 @@ type ConnectOptions struct {
 	ProviderNATConn *net.UDPConn
 	ChannelConn     *net.UDPConn
 	HermesID        common.Address
+	// EntryHop marks a multi-hop entry hop, which carries the next hops inside its tunnel.
+	EntryHop bool
+	// OuterIface is the tunnel interface of the previous hop, connection is nested into it.
+	OuterIface string
+	// NestingLevel is the count of hop tunnels the connection is nested into.
+	NestingLevel int
 }
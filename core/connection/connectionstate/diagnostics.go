@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connectionstate
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/session"
+)
+
+// AppTopicConnectionDiagnostics represents the topic of active connection probe results
+const AppTopicConnectionDiagnostics = "Diagnostics"
+
+// AppEventConnectionDiagnostics is the struct we'll emit on a AppTopicConnectionDiagnostics topic event,
+// it is emitted after each probe with the results collected so far
+type AppEventConnectionDiagnostics struct {
+	Diagnostics Diagnostics
+}
+
+// Diagnostics holds results of active probes run over the connection
+type Diagnostics struct {
+	SessionID session.ID
+	StartedAt time.Time
+	Completed bool
+
+	// PingsSent and PingsLost count keep-alive round-trips to the provider over the p2p channel
+	PingsSent int
+	PingsLost int
+	Latency   time.Duration
+	Jitter    time.Duration
+
+	// DNSOption is the DNS server selection strategy of the connection
+	DNSOption string
+	// DNSServers are the DNS servers used by the connection the resolution time was measured with,
+	// empty if the system resolver is used
+	DNSServers     []string
+	DNSResolveTime time.Duration
+	DNSError       string
+
+	// HandshakeAge is zero if the underlying connection has no handshakes
+	HandshakeAge time.Duration
+
+	// Observed traffic rate is a passive diff of connection statistics while probes are running,
+	// it is the rate of traffic passing the connection and not its measured throughput
+	ObservedBytesSentPerSecond     uint64
+	ObservedBytesReceivedPerSecond uint64
+}
+
+// PacketLoss returns the ratio of lost keep-alive pings
+func (d Diagnostics) PacketLoss() float64 {
+	if d.PingsSent == 0 {
+		return 0
+	}
+	return float64(d.PingsLost) / float64(d.PingsSent)
+}
@@ -36,8 +36,16 @@ const (
 	AppTopicConnectionStatistics = "Statistics"
 	// AppTopicConnectionSession represents the session lifetime changes
 	AppTopicConnectionSession = "Session"
+	// AppTopicConnectionFailover represents the switch of failed session to the next provider
+	AppTopicConnectionFailover = "Failover"
 )
 
+// AppEventConnectionFailover is the struct we'll emit on a AppTopicConnectionFailover topic event
+type AppEventConnectionFailover struct {
+	FailedProposal proposal.PricedServiceProposal
+	SessionInfo    Status
+}
+
 // AppEventConnectionState is the struct we'll emit on a AppEventConnectionState topic event
 type AppEventConnectionState struct {
 	State       State
@@ -68,6 +76,8 @@ const (
 	StateConnectionFailed = State("ConnectionFailed")
 	// StateOnHold means that underlying connection failed, but manager keeps it not removed to prevent traffic leaks.
 	StateOnHold = State("OnHold")
+	// StateSpendingLimitReached means that session exceeded its spending limits and connection is being closed
+	StateSpendingLimitReached = State("SpendingLimitReached")
 )
 
 // Status holds connection state, session id and proposal of the connection
@@ -79,6 +89,19 @@ type Status struct {
 	State            State
 	SessionID        session.ID
 	Proposal         proposal.PricedServiceProposal
+	// Hops are the entry hops of multi-hop connection, SessionID and Proposal above belong to the exit hop.
+	Hops []Hop
+	// SpendingLimits are the hard limits of each session of the connection
+	SpendingLimits SpendingLimits
+	// Reason describes why connection moved to the current state, empty for regular state changes.
+	// Reason of the state which ended the connection is kept by the following NotConnected state.
+	Reason string
+}
+
+// Hop holds the session of a multi-hop connection entry hop
+type Hop struct {
+	SessionID session.ID
+	Proposal  proposal.PricedServiceProposal
 }
 
 // Duration returns elapsed time from marked session start
@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connectionstate
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrSpendingLimitReached is returned when session exceeds one of its spending limits.
+var ErrSpendingLimitReached = errors.New("spending limit reached")
+
+// SpendingLimits are the hard limits of a single session enforced by consumer, nil and zero values mean no limit.
+type SpendingLimits struct {
+	// MaxSessionSpend limits tokens spent during the session
+	MaxSessionSpend *big.Int
+	// MaxHourlySpend limits tokens spent per hour of the session, the first hour is always allowed in full
+	MaxHourlySpend *big.Int
+	// MaxTraffic limits bytes transferred during the session
+	MaxTraffic uint64
+}
+
+// SpendingBudget is the remaining budget of a session, nil values mean no limit.
+type SpendingBudget struct {
+	Session *big.Int
+	Hourly  *big.Int
+	Traffic *uint64
+}
+
+// Enabled checks if any limit is defined.
+func (l SpendingLimits) Enabled() bool {
+	return isSet(l.MaxSessionSpend) || isSet(l.MaxHourlySpend) || l.MaxTraffic > 0
+}
+
+// Validate checks if limits are well formed.
+func (l SpendingLimits) Validate() error {
+	if l.MaxSessionSpend != nil && l.MaxSessionSpend.Sign() < 0 {
+		return errors.New("session spending limit can't be negative")
+	}
+	if l.MaxHourlySpend != nil && l.MaxHourlySpend.Sign() < 0 {
+		return errors.New("hourly spending limit can't be negative")
+	}
+	return nil
+}
+
+// Check returns ErrSpendingLimitReached describing the limit which the given session usage exceeds.
+func (l SpendingLimits) Check(elapsed time.Duration, spent *big.Int, traffic uint64) error {
+	if spent == nil {
+		spent = new(big.Int)
+	}
+	if isSet(l.MaxSessionSpend) && spent.Cmp(l.MaxSessionSpend) > 0 {
+		return fmt.Errorf("%w: session spending %v exceeds %v", ErrSpendingLimitReached, spent, l.MaxSessionSpend)
+	}
+	if isSet(l.MaxHourlySpend) {
+		if allowed := l.hourlyAllowance(elapsed); spent.Cmp(allowed) > 0 {
+			return fmt.Errorf("%w: spending %v exceeds hourly limit of %v", ErrSpendingLimitReached, spent, l.MaxHourlySpend)
+		}
+	}
+	if l.MaxTraffic > 0 && traffic > l.MaxTraffic {
+		return fmt.Errorf("%w: traffic of %d bytes exceeds %d bytes", ErrSpendingLimitReached, traffic, l.MaxTraffic)
+	}
+	return nil
+}
+
+// Remaining returns the budget left for the given session usage.
+func (l SpendingLimits) Remaining(elapsed time.Duration, spent *big.Int, traffic uint64) SpendingBudget {
+	if spent == nil {
+		spent = new(big.Int)
+	}
+
+	var budget SpendingBudget
+	if isSet(l.MaxSessionSpend) {
+		budget.Session = nonNegative(new(big.Int).Sub(l.MaxSessionSpend, spent))
+	}
+	if isSet(l.MaxHourlySpend) {
+		budget.Hourly = nonNegative(new(big.Int).Sub(l.hourlyAllowance(elapsed), spent))
+	}
+	if l.MaxTraffic > 0 {
+		left := uint64(0)
+		if traffic < l.MaxTraffic {
+			left = l.MaxTraffic - traffic
+		}
+		budget.Traffic = &left
+	}
+	return budget
+}
+
+// hourlyAllowance returns tokens allowed to spend during the elapsed time of session.
+func (l SpendingLimits) hourlyAllowance(elapsed time.Duration) *big.Int {
+	if elapsed < time.Hour {
+		elapsed = time.Hour
+	}
+	allowance := new(big.Int).Mul(l.MaxHourlySpend, big.NewInt(int64(elapsed)))
+	return allowance.Div(allowance, big.NewInt(int64(time.Hour)))
+}
+
+func isSet(amount *big.Int) bool {
+	return amount != nil && amount.Sign() > 0
+}
+
+func nonNegative(amount *big.Int) *big.Int {
+	if amount.Sign() < 0 {
+		return new(big.Int)
+	}
+	return amount
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connectionstate
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpendingLimits_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   SpendingLimits
+		elapsed  time.Duration
+		spent    *big.Int
+		traffic  uint64
+		exceeded bool
+	}{
+		{
+			name:    "passes without limits",
+			elapsed: time.Hour,
+			spent:   big.NewInt(1000),
+			traffic: 1000,
+		},
+		{
+			name:    "passes within session limit",
+			limits:  SpendingLimits{MaxSessionSpend: big.NewInt(100)},
+			spent:   big.NewInt(100),
+			traffic: 1000,
+		},
+		{
+			name:     "fails over session limit",
+			limits:   SpendingLimits{MaxSessionSpend: big.NewInt(100)},
+			spent:    big.NewInt(101),
+			exceeded: true,
+		},
+		{
+			name:    "allows full hourly limit during the first hour",
+			limits:  SpendingLimits{MaxHourlySpend: big.NewInt(100)},
+			elapsed: time.Minute,
+			spent:   big.NewInt(100),
+		},
+		{
+			name:     "fails over hourly limit",
+			limits:   SpendingLimits{MaxHourlySpend: big.NewInt(100)},
+			elapsed:  2 * time.Hour,
+			spent:    big.NewInt(201),
+			exceeded: true,
+		},
+		{
+			name:     "fails over traffic limit",
+			limits:   SpendingLimits{MaxTraffic: 1000},
+			spent:    big.NewInt(0),
+			traffic:  1001,
+			exceeded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Check(tt.elapsed, tt.spent, tt.traffic)
+			assert.Equal(t, tt.exceeded, errors.Is(err, ErrSpendingLimitReached))
+		})
+	}
+}
+
+func TestSpendingLimits_Remaining(t *testing.T) {
+	limits := SpendingLimits{
+		MaxSessionSpend: big.NewInt(100),
+		MaxHourlySpend:  big.NewInt(50),
+		MaxTraffic:      1000,
+	}
+
+	budget := limits.Remaining(90*time.Minute, big.NewInt(60), 1500)
+
+	assert.Equal(t, big.NewInt(40), budget.Session)
+	assert.Equal(t, big.NewInt(15), budget.Hourly)
+	assert.Equal(t, uint64(0), *budget.Traffic)
+	assert.Equal(t, SpendingBudget{}, SpendingLimits{}.Remaining(time.Hour, big.NewInt(60), 1500))
+}
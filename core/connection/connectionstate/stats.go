@@ -29,6 +29,7 @@ type Statistics struct {
 	At            time.Time
 	BytesSent     uint64
 	BytesReceived uint64
+	LastHandshake time.Time // Zero if underlying connection has no handshakes.
 }
 
 // Diff calculates the difference in bytes between the old stats and new.
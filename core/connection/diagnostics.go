@@ -0,0 +1,180 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+)
+
+// diagnosticsDNSDomain is the domain random hosts are resolved under, so that probe isn't answered from cache.
+const diagnosticsDNSDomain = "mysterium.network"
+
+// Diagnose runs active probes over the current connection, probes are stopped once the diagnostics timeout passes.
+func (m *connectionManager) Diagnose(ctx context.Context) (connectionstate.Diagnostics, error) {
+	status := m.Status()
+	if status.State != connectionstate.Connected {
+		return connectionstate.Diagnostics{}, ErrNoConnection
+	}
+
+	active := m.activeHop()
+	if active.connection == nil {
+		return connectionstate.Diagnostics{}, ErrNoConnection
+	}
+
+	cfg := m.config.Diagnostics
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	dnsOption := active.options.Params.DNS
+	result := connectionstate.Diagnostics{
+		SessionID: status.SessionID,
+		StartedAt: time.Now(),
+		DNSOption: string(dnsOption),
+	}
+
+	statsBefore, statsErr := active.connection.Statistics()
+
+	var durations []time.Duration
+	for i := 0; i < cfg.PingCount; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(cfg.PingInterval):
+			}
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, cfg.PingTimeout)
+		duration, err := pingProvider(pingCtx, active.channel, status.SessionID)
+		cancel()
+
+		result.PingsSent++
+		if err != nil {
+			log.Debug().Err(err).Msgf("Diagnostics ping failed. SessionID=%s", status.SessionID)
+			result.PingsLost++
+		} else {
+			durations = append(durations, duration)
+		}
+		result.Latency, result.Jitter = latencyAndJitter(durations)
+		m.publishDiagnostics(result)
+	}
+
+	servers := dnsServers(active.connection, dnsOption)
+	result.DNSServers = servers
+	dnsCtx, cancel := context.WithTimeout(ctx, cfg.DNSTimeout)
+	start := time.Now()
+	err := m.resolveHost(dnsCtx, servers, fmt.Sprintf("%x.%s", rand.Int63(), diagnosticsDNSDomain))
+	cancel()
+	if err != nil {
+		result.DNSError = err.Error()
+	} else {
+		result.DNSResolveTime = time.Since(start)
+	}
+	m.publishDiagnostics(result)
+
+	statsAfter, err := active.connection.Statistics()
+	if err == nil && statsErr == nil {
+		if elapsed := statsAfter.At.Sub(statsBefore.At).Seconds(); elapsed > 0 {
+			transferred := statsBefore.Diff(statsAfter)
+			result.ObservedBytesSentPerSecond = uint64(float64(transferred.BytesSent) / elapsed)
+			result.ObservedBytesReceivedPerSecond = uint64(float64(transferred.BytesReceived) / elapsed)
+		}
+		if !statsAfter.LastHandshake.IsZero() {
+			result.HandshakeAge = statsAfter.At.Sub(statsAfter.LastHandshake)
+		}
+	}
+
+	result.Completed = true
+	m.publishDiagnostics(result)
+
+	return result, nil
+}
+
+// dnsServers returns DNS servers the connection resolves with.
+func dnsServers(connection Connection, option DNSOption) []string {
+	if conn, ok := connection.(DNSConnection); ok {
+		return conn.DNSServers()
+	}
+	servers, _ := option.Exact()
+	return servers
+}
+
+func (m *connectionManager) publishDiagnostics(result connectionstate.Diagnostics) {
+	m.eventBus.Publish(connectionstate.AppTopicConnectionDiagnostics, connectionstate.AppEventConnectionDiagnostics{
+		Diagnostics: result,
+	})
+}
+
+// latencyAndJitter returns mean round-trip time and mean deviation of consecutive round-trip times.
+func latencyAndJitter(durations []time.Duration) (latency, jitter time.Duration) {
+	if len(durations) == 0 {
+		return 0, 0
+	}
+
+	var sum, deviations time.Duration
+	for i, d := range durations {
+		sum += d
+		if i > 0 {
+			deviation := d - durations[i-1]
+			if deviation < 0 {
+				deviation = -deviation
+			}
+			deviations += deviation
+		}
+	}
+
+	latency = sum / time.Duration(len(durations))
+	if len(durations) > 1 {
+		jitter = deviations / time.Duration(len(durations)-1)
+	}
+	return latency, jitter
+}
+
+// resolveHost resolves host using given DNS servers or the system resolver if none given,
+// host not found is a valid answer as well.
+func resolveHost(ctx context.Context, servers []string, host string) error {
+	resolver := net.DefaultResolver
+	if len(servers) > 0 {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, net.JoinHostPort(servers[0], "53"))
+			},
+		}
+	}
+
+	_, err := resolver.LookupHost(ctx, host)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_latencyAndJitter(t *testing.T) {
+	for name, tc := range map[string]struct {
+		durations       []time.Duration
+		expectedLatency time.Duration
+		expectedJitter  time.Duration
+	}{
+		"no successful pings": {},
+		"single ping": {
+			durations:       []time.Duration{10 * time.Millisecond},
+			expectedLatency: 10 * time.Millisecond,
+		},
+		"stable pings": {
+			durations:       []time.Duration{10 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond},
+			expectedLatency: 10 * time.Millisecond,
+		},
+		"fluctuating pings": {
+			durations:       []time.Duration{10 * time.Millisecond, 30 * time.Millisecond, 20 * time.Millisecond},
+			expectedLatency: 20 * time.Millisecond,
+			expectedJitter:  15 * time.Millisecond,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			latency, jitter := latencyAndJitter(tc.durations)
+			assert.Equal(t, tc.expectedLatency, latency)
+			assert.Equal(t, tc.expectedJitter, jitter)
+		})
+	}
+}
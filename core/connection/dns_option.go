@@ -22,6 +22,7 @@ import (
 	"net"
 	"strings"
 
+	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/utils/stringutil"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -37,13 +38,26 @@ const (
 	DNSOptionProvider = DNSOption("provider")
 	// DNSOptionSystem uses DNS servers from client's system configuration
 	DNSOptionSystem = DNSOption("system")
+	// DNSOptionFiltered uses local DNS proxy of the client which blocks domains found in the blocklists
+	DNSOptionFiltered = DNSOption("filtered")
 )
 
+// FilteredDNSIP is the default address of local DNS proxy serving DNSOptionFiltered
+const FilteredDNSIP = "127.0.0.1"
+
+// FilteredDNSAddress returns the configured address of local DNS proxy serving DNSOptionFiltered
+func FilteredDNSAddress() string {
+	if address := config.GetString(config.FlagDNSFilterAddress); address != "" {
+		return address
+	}
+	return FilteredDNSIP
+}
+
 // NewDNSOption creates and validates DNSOption
 func NewDNSOption(str string) (DNSOption, error) {
 	opt := DNSOption(str)
 	switch opt {
-	case DNSOptionAuto, DNSOptionProvider, DNSOptionSystem, "":
+	case DNSOptionAuto, DNSOptionProvider, DNSOptionSystem, DNSOptionFiltered, "":
 		return opt, nil
 	}
 	// It may also be a set of IP addresses, e.g. 1.1.1.1,8.8.8.8
@@ -73,7 +87,7 @@ func (o *DNSOption) UnmarshalJSON(data []byte) error {
 // Exact returns a slice of DNS server IPs, if they were set
 func (o DNSOption) Exact() (servers []string, ok bool) {
 	switch o {
-	case DNSOptionAuto, DNSOptionProvider, DNSOptionSystem:
+	case DNSOptionAuto, DNSOptionProvider, DNSOptionSystem, DNSOptionFiltered:
 		return nil, false
 	}
 	return stringutil.Split(string(o), ','), true
@@ -88,12 +102,14 @@ func (o *DNSOption) ResolveIPs(providerDNS string) ([]string, error) {
 	}
 	switch *o {
 	case DNSOptionProvider:
-		return selectProviderDNS(providerDNS)
+		return SelectProviderDNS(providerDNS)
 	case DNSOptionSystem:
 		return nil, nil
+	case DNSOptionFiltered:
+		return []string{FilteredDNSAddress()}, nil
 	case DNSOptionAuto:
 		log.Debug().Msg("Attempting to use provider DNS")
-		if providerDNS, err := selectProviderDNS(providerDNS); err == nil {
+		if providerDNS, err := SelectProviderDNS(providerDNS); err == nil {
 			return providerDNS, nil
 		}
 		log.Debug().Msg("Attempting to use system DNS")
@@ -103,7 +119,8 @@ func (o *DNSOption) ResolveIPs(providerDNS string) ([]string, error) {
 	return []string{"1.1.1.1", "8.8.8.8"}, nil
 }
 
-func selectProviderDNS(providerDNS string) ([]string, error) {
+// SelectProviderDNS parses DNS servers offered by the provider in the session config.
+func SelectProviderDNS(providerDNS string) ([]string, error) {
 	opt, err := NewDNSOption(providerDNS)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't parse provider DNS string")
@@ -21,6 +21,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/config"
 )
 
 func TestNewDNSOption(t *testing.T) {
@@ -33,6 +35,7 @@ func TestNewDNSOption(t *testing.T) {
 		{input: "auto", expect: DNSOptionAuto},
 		{input: "provider", expect: DNSOptionProvider},
 		{input: "system", expect: DNSOptionSystem},
+		{input: "filtered", expect: DNSOptionFiltered},
 		{input: "1.1.1.1,9.9.9.9", expect: DNSOption("1.1.1.1,9.9.9.9")},
 		{input: "1.1.1.1", expect: DNSOption("1.1.1.1")},
 		{input: "", expect: DNSOption("")},
@@ -57,6 +60,7 @@ func TestDNSOption_UnmarshalJSON(t *testing.T) {
 		{input: "\"auto\"", expectOption: DNSOptionAuto},
 		{input: "\"provider\"", expectOption: DNSOptionProvider},
 		{input: "\"system\"", expectOption: DNSOptionSystem},
+		{input: "\"filtered\"", expectOption: DNSOptionFiltered},
 		{input: "\"1.1.1.1,9.9.9.9\"", expectOption: DNSOption("1.1.1.1,9.9.9.9")},
 		{input: "\"9.9.9.9\"", expectOption: DNSOption("9.9.9.9")},
 		{input: "\"\"", expectOption: DNSOption("")},
@@ -83,6 +87,7 @@ func TestDNSOption_Exact(t *testing.T) {
 		{option: DNSOptionAuto, expectOK: false},
 		{option: DNSOptionProvider, expectOK: false},
 		{option: DNSOptionSystem, expectOK: false},
+		{option: DNSOptionFiltered, expectOK: false},
 		{option: DNSOption("1.1.1.1,9.9.9.9"), expectServers: []string{"1.1.1.1", "9.9.9.9"}, expectOK: true},
 		{option: DNSOption("9.9.9.9"), expectServers: []string{"9.9.9.9"}, expectOK: true},
 		{option: DNSOption(""), expectServers: nil, expectOK: true},
@@ -93,3 +98,36 @@ func TestDNSOption_Exact(t *testing.T) {
 		assert.Equal(tt.expectServers, servers)
 	}
 }
+
+func TestDNSOption_ResolveIPs(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		option      DNSOption
+		providerDNS string
+		expectIPs   []string
+		expectErr   bool
+	}{
+		{option: DNSOptionAuto, providerDNS: "10.8.0.1", expectIPs: []string{"10.8.0.1"}},
+		{option: DNSOptionAuto, providerDNS: "", expectIPs: nil},
+		{option: DNSOptionProvider, providerDNS: "10.8.0.1", expectIPs: []string{"10.8.0.1"}},
+		{option: DNSOptionProvider, providerDNS: "", expectErr: true},
+		{option: DNSOptionSystem, providerDNS: "10.8.0.1", expectIPs: nil},
+		{option: DNSOptionFiltered, providerDNS: "10.8.0.1", expectIPs: []string{FilteredDNSIP}},
+		{option: DNSOption("9.9.9.9"), providerDNS: "10.8.0.1", expectIPs: []string{"9.9.9.9"}},
+	}
+	for i, tt := range tests {
+		ips, err := tt.option.ResolveIPs(tt.providerDNS)
+		assert.Equal(tt.expectErr, err != nil, "%v: expected err = %v, actual err = %v", i, tt.expectErr, err)
+		assert.Equal(tt.expectIPs, ips, "%v", i)
+	}
+}
+
+func Test_DNSOption_ResolveIPs_UsesConfiguredFilterAddress(t *testing.T) {
+	config.Current.SetUser(config.FlagDNSFilterAddress.Name, "127.0.0.153")
+	defer config.Current.RemoveUser(config.FlagDNSFilterAddress.Name)
+
+	option := DNSOptionFiltered
+	ips, err := option.ResolveIPs("10.8.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.153"}, ips)
+}
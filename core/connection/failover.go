@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/firewall"
+)
+
+// FailoverPolicy defines when the connection is moved to the next best proposal of the same proposal lookup.
+type FailoverPolicy struct {
+	// MaxFailures is a count of consecutive failed session checks which triggers failover, zero disables failover.
+	MaxFailures int
+}
+
+// Enabled checks if failover should be done at all.
+func (p FailoverPolicy) Enabled() bool {
+	return p.MaxFailures > 0
+}
+
+// sessionCheckFailed records failed keep-alive check and reports if the failover policy requires switching the provider.
+func (m *connectionManager) sessionCheckFailed() bool {
+	m.failoverLock.Lock()
+	defer m.failoverLock.Unlock()
+
+	m.checkFailures++
+	policy := m.connectOptions.Params.Failover
+	return policy.Enabled() && !m.failingOver && m.checkFailures >= policy.MaxFailures
+}
+
+func (m *connectionManager) sessionCheckSucceeded() {
+	m.failoverLock.Lock()
+	defer m.failoverLock.Unlock()
+
+	m.checkFailures = 0
+}
+
+// failover tears down the current session and connects to the next best proposal returned by the same
+// proposal lookup, skipping the failed provider. Kill switch rules are held during the swap, so no traffic
+// leaks outside the tunnel.
+func (m *connectionManager) failover() error {
+	if !m.startFailover() {
+		return nil
+	}
+	defer m.finishFailover()
+
+	failed := m.Status()
+	opts := m.connectOptions
+	log.Warn().Msgf("Session %s check failed, switching provider %s to the next one", failed.SessionID, failed.Proposal.ProviderID)
+
+	if err := m.Disconnect(); err != nil {
+		return err
+	}
+
+	m.cleanupFinishedLock.Lock()
+	<-m.cleanupFinished
+	m.cleanupFinishedLock.Unlock()
+
+	err := m.connectExcluding(opts.ConsumerID, opts.HermesID, opts.ProposalLookup, opts.Params, failed.Proposal.ProviderID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to connect to the next provider")
+		return err
+	}
+
+	m.eventBus.Publish(connectionstate.AppTopicConnectionFailover, connectionstate.AppEventConnectionFailover{
+		FailedProposal: failed.Proposal,
+		SessionInfo:    m.Status(),
+	})
+	return nil
+}
+
+func (m *connectionManager) startFailover() bool {
+	m.failoverLock.Lock()
+	defer m.failoverLock.Unlock()
+
+	if m.failingOver {
+		return false
+	}
+	m.failingOver = true
+	m.checkFailures = 0
+	return true
+}
+
+func (m *connectionManager) finishFailover() {
+	m.failoverLock.Lock()
+	defer m.failoverLock.Unlock()
+
+	m.failingOver = false
+	m.checkFailures = 0
+	for _, removeRule := range m.heldTrafficBlock {
+		removeRule()
+	}
+	m.heldTrafficBlock = nil
+}
+
+// holdTrafficBlock keeps kill switch rule of the failed session until the next session takes it over.
+func (m *connectionManager) holdTrafficBlock(removeRule firewall.OutgoingRuleRemove) bool {
+	m.failoverLock.Lock()
+	defer m.failoverLock.Unlock()
+
+	if !m.failingOver {
+		return false
+	}
+	m.heldTrafficBlock = append(m.heldTrafficBlock, removeRule)
+	return true
+}
+
+// takeHeldTrafficBlock takes over kill switch rules held during failover.
+func (m *connectionManager) takeHeldTrafficBlock() []firewall.OutgoingRuleRemove {
+	m.failoverLock.Lock()
+	defer m.failoverLock.Unlock()
+
+	held := m.heldTrafficBlock
+	m.heldTrafficBlock = nil
+	return held
+}
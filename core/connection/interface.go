@@ -39,6 +39,36 @@ type Connection interface {
 	Statistics() (connectionstate.Statistics, error)
 }
 
+// TunnelConnection is a connection routing traffic through a network interface,
+// which is able to carry the next hop of multi-hop connection.
+type TunnelConnection interface {
+	Connection
+	InterfaceName() string
+}
+
+// ProxyConnection is a connection exposing a local proxy instead of routing all traffic of the system,
+// so it neither blocks the traffic outside of it nor changes the public IP of the consumer.
+type ProxyConnection interface {
+	Connection
+	ProxyAddress() string
+}
+
+// DNSConnection is a connection configuring the DNS servers of the system while it is active.
+type DNSConnection interface {
+	Connection
+	// DNSServers returns the configured DNS servers, empty if the system ones are left untouched.
+	DNSServers() []string
+	// ProviderDNSServers returns DNS servers offered by the provider, which are reachable through the tunnel.
+	ProviderDNSServers() []string
+}
+
+// DNSFilter is the local DNS proxy serving DNSOptionFiltered while connection is active
+type DNSFilter interface {
+	// Start starts the proxy resolving allowed queries via given upstreams, unless upstreams are configured explicitly.
+	Start(upstreams []string) error
+	Stop() error
+}
+
 // StateChannel is the channel we receive state change events on
 type StateChannel chan connectionstate.State
 
@@ -54,4 +84,6 @@ type Manager interface {
 	CheckChannel(context.Context) error
 	// Reconnect reconnects current session
 	Reconnect()
+	// Diagnose runs active probes over the current connection, results are also published after each probe
+	Diagnose(context.Context) (connectionstate.Diagnostics, error)
 }
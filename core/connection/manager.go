@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
 	"sync"
 	"time"
 
@@ -50,6 +51,9 @@ const (
 	p2pDialTimeout = 60 * time.Second
 )
 
+// lookupIP resolves the split tunnel included domains for the kill switch.
+var lookupIP = net.LookupIP
+
 var (
 	// ErrNoConnection error indicates that action applied to manager expects active connection (i.e. disconnect)
 	ErrNoConnection = errors.New("no connection exists")
@@ -65,6 +69,12 @@ var (
 	ErrInsufficientBalance = errors.New("insufficient balance")
 	// ErrUnlockRequired indicates that the consumer identity has not been unlocked yet
 	ErrUnlockRequired = errors.New("unlock required")
+	// ErrMultiHopUnsupported indicates that multi-hop entry proposal service type can't carry the next hops
+	ErrMultiHopUnsupported = errors.New("service type does not support multi-hop")
+	// ErrDNSFilterUnavailable indicates that filtered DNS option was requested, but DNS filter is not set up
+	ErrDNSFilterUnavailable = errors.New("DNS filtering is not available")
+	// ErrNoFailoverProposal indicates that proposal lookup has no other provider than the failed one.
+	ErrNoFailoverProposal = errors.New("no other provider to fail over to")
 )
 
 // IPCheckConfig contains common params for connection ip check.
@@ -80,10 +90,21 @@ type KeepAliveConfig struct {
 	MaxSendErrCount int
 }
 
+// DiagnosticsConfig contains active connection probe options.
+type DiagnosticsConfig struct {
+	// Timeout limits the whole diagnostics run
+	Timeout      time.Duration
+	PingCount    int
+	PingInterval time.Duration
+	PingTimeout  time.Duration
+	DNSTimeout   time.Duration
+}
+
 // Config contains common configuration options for connection manager.
 type Config struct {
-	IPCheck   IPCheckConfig
-	KeepAlive KeepAliveConfig
+	IPCheck     IPCheckConfig
+	KeepAlive   KeepAliveConfig
+	Diagnostics DiagnosticsConfig
 }
 
 // DefaultConfig returns default params.
@@ -98,6 +119,13 @@ func DefaultConfig() Config {
 			SendTimeout:     5 * time.Second,
 			MaxSendErrCount: 3,
 		},
+		Diagnostics: DiagnosticsConfig{
+			Timeout:      30 * time.Second,
+			PingCount:    10,
+			PingInterval: 500 * time.Millisecond,
+			PingTimeout:  5 * time.Second,
+			DNSTimeout:   5 * time.Second,
+		},
 	}
 }
 
@@ -127,11 +155,20 @@ type validator interface {
 type TimeGetter func() time.Time
 
 // PaymentEngineFactory creates a new payment issuer from the given params
-type PaymentEngineFactory func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal proposal.PricedServiceProposal, price market.Price) (PaymentIssuer, error)
+type PaymentEngineFactory func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal proposal.PricedServiceProposal, price market.Price, limits connectionstate.SpendingLimits) (PaymentIssuer, error)
 
 // ProposalLookup returns a service proposal based on predefined conditions.
 type ProposalLookup func() (proposal *proposal.PricedServiceProposal, err error)
 
+// hopConnection holds the state of a single hop of the connection,
+// which is either the exit hop or one of multi-hop entry hops.
+type hopConnection struct {
+	options     ConnectOptions
+	connection  Connection
+	channel     p2p.Channel
+	acknowledge func()
+}
+
 type connectionManager struct {
 	// These are passed on creation.
 	paymentEngineFactory PaymentEngineFactory
@@ -143,7 +180,9 @@ type connectionManager struct {
 	statsReportInterval  time.Duration
 	validator            validator
 	p2pDialer            p2p.Dialer
+	dnsFilter            DNSFilter
 	timeGetter           TimeGetter
+	resolveHost          func(ctx context.Context, servers []string, host string) error
 
 	// These are populated by Connect at runtime.
 	ctx                    context.Context
@@ -155,7 +194,6 @@ type connectionManager struct {
 	cleanupAfterDisconnect []func() error
 	cleanupFinished        chan struct{}
 	cleanupFinishedLock    sync.Mutex
-	acknowledge            func()
 	cancel                 func()
 	channel                p2p.Channel
 
@@ -166,6 +204,14 @@ type connectionManager struct {
 	connectOptions ConnectOptions
 
 	activeConnection Connection
+
+	// activeLock guards connectOptions, activeConnection and channel for readers outside of connect flow.
+	activeLock sync.RWMutex
+
+	failoverLock     sync.Mutex
+	checkFailures    int
+	failingOver      bool
+	heldTrafficBlock []firewall.OutgoingRuleRemove
 }
 
 // NewManager creates connection manager with given dependencies
@@ -179,6 +225,7 @@ func NewManager(
 	statsReportInterval time.Duration,
 	validator validator,
 	p2pDialer p2p.Dialer,
+	dnsFilter DNSFilter,
 	preReconnect, postReconnect func(),
 ) *connectionManager {
 	return &connectionManager{
@@ -194,7 +241,9 @@ func NewManager(
 		statsReportInterval:  statsReportInterval,
 		validator:            validator,
 		p2pDialer:            p2pDialer,
+		dnsFilter:            dnsFilter,
 		timeGetter:           time.Now,
+		resolveHost:          resolveHost,
 		preReconnect:         preReconnect,
 		postReconnect:        postReconnect,
 	}
@@ -204,13 +253,50 @@ func (m *connectionManager) chainID() int64 {
 	return config.GetInt64(config.FlagChainID)
 }
 
-func (m *connectionManager) Connect(consumerID identity.Identity, hermesID common.Address, proposalLookup ProposalLookup, params ConnectParams) (err error) {
-	var sessionID session.ID
+// maxTemporaryRejections is a count of providers rejecting the session due to their load, which are skipped before connect fails.
+const maxTemporaryRejections = 3
 
-	proposal, err := proposalLookup()
-	if err != nil {
-		return fmt.Errorf("failed to lookup proposal: %w", err)
+func (m *connectionManager) Connect(consumerID identity.Identity, hermesID common.Address, proposalLookup ProposalLookup, params ConnectParams) error {
+	return m.connectExcluding(consumerID, hermesID, proposalLookup, params, "")
+}
+
+// connectExcluding connects to the proposal returned by the lookup, skipping the proposals of the excluded provider.
+func (m *connectionManager) connectExcluding(consumerID identity.Identity, hermesID common.Address, proposalLookup ProposalLookup, params ConnectParams, excludedProviderID string) (err error) {
+	skipped := make(map[string]bool)
+	var rejections int
+	for {
+		proposal, lookupErr := proposalLookup()
+		if lookupErr != nil {
+			return fmt.Errorf("failed to lookup proposal: %w", lookupErr)
+		}
+		// Lookup returns the skipped provider again if there is no other one.
+		if skipped[proposal.ProviderID] {
+			if err == nil {
+				err = ErrNoFailoverProposal
+			}
+			return err
+		}
+		if proposal.ProviderID == excludedProviderID {
+			skipped[proposal.ProviderID] = true
+			continue
+		}
+
+		err = m.connect(consumerID, hermesID, proposal, proposalLookup, params)
+		if !session.IsTemporaryRejection(err) {
+			return err
+		}
+
+		skipped[proposal.ProviderID] = true
+		rejections++
+		if rejections >= maxTemporaryRejections {
+			return err
+		}
+		log.Warn().Err(err).Msgf("Provider %s is at capacity, connecting to the next one", proposal.ProviderID)
 	}
+}
+
+func (m *connectionManager) connect(consumerID identity.Identity, hermesID common.Address, proposal *proposal.PricedServiceProposal, proposalLookup ProposalLookup, params ConnectParams) (err error) {
+	var sessionID session.ID
 
 	tracer := trace.NewTracer("Consumer whole Connect")
 	defer func() {
@@ -240,7 +326,7 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 	m.ctx, m.cancel = context.WithCancel(context.Background())
 	m.ctxLock.Unlock()
 
-	m.statusConnecting(consumerID, hermesID, *proposal)
+	m.statusConnecting(consumerID, hermesID, *proposal, params.SpendingLimits)
 	defer func() {
 		if err != nil {
 			log.Err(err).Msg("Connect failed, disconnecting")
@@ -248,20 +334,36 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		}
 	}()
 
-	m.connectOptions = ConnectOptions{
-		ConsumerID:     consumerID,
-		HermesID:       hermesID,
-		Proposal:       *proposal,
-		ProposalLookup: proposalLookup,
-		Params:         params,
+	outerIface, err := m.connectHops(consumerID, hermesID, params, tracer)
+	if err != nil {
+		return err
+	}
+
+	exit := &hopConnection{
+		options: ConnectOptions{
+			ConsumerID:     consumerID,
+			HermesID:       hermesID,
+			Proposal:       *proposal,
+			ProposalLookup: proposalLookup,
+			Params:         params,
+			OuterIface:     outerIface,
+			NestingLevel:   len(params.Hops),
+		},
 	}
+	m.activeLock.Lock()
+	m.connectOptions = exit.options
+	m.activeLock.Unlock()
 
-	m.activeConnection, err = m.newConnection(proposal.ServiceType)
+	exit.connection, err = m.newConnection(proposal.ServiceType)
 	if err != nil {
 		return err
 	}
+	m.activeLock.Lock()
+	m.activeConnection = exit.connection
+	m.activeLock.Unlock()
 
-	sessionID, err = m.initSession(tracer, prc)
+	sessionID, err = m.initSession(exit, tracer, prc)
+	m.useExitHop(exit)
 	if err != nil {
 		return err
 	}
@@ -270,12 +372,12 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 
 	err = m.startConnection(m.currentCtx(), m.activeConnection, m.activeConnection.Start, m.connectOptions, tracer)
 	if err != nil {
-		return m.handleStartError(sessionID, err)
+		return m.handleStartError(exit, sessionID, err)
 	}
 
-	err = m.waitForConnectedState(m.activeConnection.State())
+	err = m.waitForConnectedState(exit, m.activeConnection.State())
 	if err != nil {
-		return m.handleStartError(sessionID, err)
+		return m.handleStartError(exit, sessionID, err)
 	}
 
 	statsPublisher := newStatsPublisher(m.eventBus, m.statsReportInterval)
@@ -288,13 +390,81 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 	})
 
 	go m.consumeConnectionStates(m.activeConnection.State())
-	go m.checkSessionIP(m.channel, m.connectOptions.ConsumerID, m.connectOptions.SessionID, originalPublicIP)
+	if _, ok := m.activeConnection.(ProxyConnection); ok {
+		// Public IP of the consumer doesn't change when only the proxy clients are using the connection.
+		go m.sendSessionStatus(m.channel, m.connectOptions.ConsumerID, m.connectOptions.SessionID, connectivity.StatusConnectionOk, nil)
+	} else {
+		go m.checkSessionIP(m.channel, m.connectOptions.ConsumerID, m.connectOptions.SessionID, originalPublicIP)
+	}
 
 	m.eventBus.SubscribeAsync(connectionstate.AppTopicConnectionState, m.reconnectOnHold)
 
 	return nil
 }
 
+// connectHops establishes multi-hop entry hops, each one nested into the tunnel of the previous hop.
+// It returns the tunnel interface of the last entry hop, which the exit hop has to be nested into.
+func (m *connectionManager) connectHops(consumerID identity.Identity, hermesID common.Address, params ConnectParams, tracer *trace.Tracer) (outerIface string, err error) {
+	for i, proposalLookup := range params.Hops {
+		proposal, err := proposalLookup()
+		if err != nil {
+			return "", fmt.Errorf("failed to lookup proposal of hop %d: %w", i+1, err)
+		}
+
+		prc := m.priceFromProposal(*proposal)
+		if err := m.validator.Validate(m.chainID(), consumerID, prc); err != nil {
+			return "", err
+		}
+
+		hop := &hopConnection{
+			options: ConnectOptions{
+				ConsumerID:     consumerID,
+				HermesID:       hermesID,
+				Proposal:       *proposal,
+				ProposalLookup: proposalLookup,
+				Params: ConnectParams{
+					DisableKillSwitch: params.DisableKillSwitch,
+					DNS:               DNSOptionSystem,
+					SpendingLimits:    params.SpendingLimits,
+				},
+				EntryHop:     true,
+				OuterIface:   outerIface,
+				NestingLevel: i,
+			},
+		}
+
+		hop.connection, err = m.newConnection(proposal.ServiceType)
+		if err != nil {
+			return "", err
+		}
+		tunnel, ok := hop.connection.(TunnelConnection)
+		if !ok {
+			return "", ErrMultiHopUnsupported
+		}
+
+		sessionID, err := m.initSession(hop, tracer, prc)
+		if err != nil {
+			return "", err
+		}
+
+		err = m.startConnection(m.currentCtx(), hop.connection, hop.connection.Start, hop.options, tracer)
+		if err != nil {
+			return "", m.handleStartError(hop, sessionID, err)
+		}
+
+		err = m.waitForHopConnectedState(hop, hop.connection.State())
+		if err != nil {
+			return "", m.handleStartError(hop, sessionID, err)
+		}
+		go m.consumeHopStates(m.currentCtx(), sessionID, hop.connection.State())
+
+		outerIface = tunnel.InterfaceName()
+		log.Info().Msgf("Multi-hop entry hop %d connected through %s", i+1, outerIface)
+	}
+
+	return outerIface, nil
+}
+
 func (m *connectionManager) autoReconnect() (err error) {
 	var sessionID session.ID
 
@@ -309,16 +479,21 @@ func (m *connectionManager) autoReconnect() (err error) {
 		return fmt.Errorf("failed to lookup proposal: %w", err)
 	}
 
-	m.connectOptions.Proposal = *proposal
+	exit := &hopConnection{
+		options:    m.connectOptions,
+		connection: m.activeConnection,
+	}
+	exit.options.Proposal = *proposal
 
-	sessionID, err = m.initSession(tracer, m.priceFromProposal(m.connectOptions.Proposal))
+	sessionID, err = m.initSession(exit, tracer, m.priceFromProposal(exit.options.Proposal))
+	m.useExitHop(exit)
 	if err != nil {
 		return err
 	}
 
 	err = m.startConnection(m.currentCtx(), m.activeConnection, m.activeConnection.Reconnect, m.connectOptions, tracer)
 	if err != nil {
-		return m.handleStartError(sessionID, err)
+		return m.handleStartError(exit, sessionID, err)
 	}
 
 	return nil
@@ -347,48 +522,77 @@ func (m *connectionManager) priceFromProposal(proposal proposal.PricedServicePro
 
 }
 
-func (m *connectionManager) initSession(tracer *trace.Tracer, prc market.Price) (sessionID session.ID, err error) {
-	err = m.createP2PChannel(m.connectOptions, tracer)
+// useExitHop makes the given hop the active connection of the manager.
+func (m *connectionManager) useExitHop(exit *hopConnection) {
+	m.activeLock.Lock()
+	defer m.activeLock.Unlock()
+
+	m.connectOptions = exit.options
+	m.activeConnection = exit.connection
+	m.channel = exit.channel
+}
+
+// activeHop returns a snapshot of the active connection of the manager.
+func (m *connectionManager) activeHop() hopConnection {
+	m.activeLock.RLock()
+	defer m.activeLock.RUnlock()
+
+	return hopConnection{
+		options:    m.connectOptions,
+		connection: m.activeConnection,
+		channel:    m.channel,
+	}
+}
+
+func (m *connectionManager) initSession(hop *hopConnection, tracer *trace.Tracer, prc market.Price) (sessionID session.ID, err error) {
+	err = m.createP2PChannel(hop, tracer)
 	if err != nil {
 		return sessionID, fmt.Errorf("could not create p2p channel during connect: %w", err)
 	}
 
-	m.connectOptions.ProviderNATConn = m.channel.ServiceConn()
-	m.connectOptions.ChannelConn = m.channel.Conn()
+	hop.options.ProviderNATConn = hop.channel.ServiceConn()
+	hop.options.ChannelConn = hop.channel.Conn()
 
-	paymentSession, err := m.paymentLoop(m.connectOptions, prc)
+	paymentSession, err := m.paymentLoop(hop, prc)
 	if err != nil {
 		return sessionID, err
 	}
 
-	sessionDTO, err := m.createP2PSession(m.activeConnection, m.connectOptions, tracer, prc)
+	sessionDTO, err := m.createP2PSession(hop, tracer, prc)
 	sessionID = session.ID(sessionDTO.GetID())
 	if err != nil {
-		m.sendSessionStatus(m.channel, m.connectOptions.ConsumerID, sessionID, connectivity.StatusSessionEstablishmentFailed, err)
+		m.sendSessionStatus(hop.channel, hop.options.ConsumerID, sessionID, connectivity.StatusSessionEstablishmentFailed, err)
 		return sessionID, err
 	}
 
+	hop.options.SessionID = sessionID
+	hop.options.SessionConfig = sessionDTO.GetConfig()
+
 	traceStart := tracer.StartStage("Consumer session creation (start)")
-	go m.keepAliveLoop(m.channel, sessionID)
+	go m.keepAliveLoop(hop.channel, sessionID, hop.options.EntryHop)
+	entryHop := hop.options.EntryHop
+	statusHop := connectionstate.Hop{SessionID: sessionID, Proposal: hop.options.Proposal}
 	m.setStatus(func(status *connectionstate.Status) {
-		status.SessionID = sessionID
+		if entryHop {
+			status.Hops = append(status.Hops, statusHop)
+		} else {
+			status.SessionID = sessionID
+		}
 	})
 	m.publishSessionCreate(sessionID)
 	paymentSession.SetSessionID(string(sessionID))
 	tracer.EndStage(traceStart)
 
-	m.connectOptions.SessionID = sessionID
-	m.connectOptions.SessionConfig = sessionDTO.GetConfig()
-
 	return sessionID, nil
 }
 
-func (m *connectionManager) handleStartError(sessionID session.ID, err error) error {
+func (m *connectionManager) handleStartError(hop *hopConnection, sessionID session.ID, err error) error {
 	if errors.Is(err, context.Canceled) {
 		return ErrConnectionCancelled
 	}
+	channel, consumerID := hop.channel, hop.options.ConsumerID
 	m.addCleanupAfterDisconnect(func() error {
-		return m.sendSessionStatus(m.channel, m.connectOptions.ConsumerID, sessionID, connectivity.StatusConnectionFailed, err)
+		return m.sendSessionStatus(channel, consumerID, sessionID, connectivity.StatusConnectionFailed, err)
 	})
 	m.publishStateEvent(connectionstate.StateConnectionFailed)
 
@@ -464,8 +668,9 @@ func (m *connectionManager) getPublicIP() string {
 	return currentPublicIP
 }
 
-func (m *connectionManager) paymentLoop(opts ConnectOptions, price market.Price) (PaymentIssuer, error) {
-	payments, err := m.paymentEngineFactory(m.channel, opts.ConsumerID, identity.FromAddress(opts.Proposal.ProviderID), opts.HermesID, opts.Proposal, price)
+func (m *connectionManager) paymentLoop(hop *hopConnection, price market.Price) (PaymentIssuer, error) {
+	opts := hop.options
+	payments, err := m.paymentEngineFactory(hop.channel, opts.ConsumerID, identity.FromAddress(opts.Proposal.ProviderID), opts.HermesID, opts.Proposal, price, opts.Params.SpendingLimits)
 	if err != nil {
 		return nil, err
 	}
@@ -478,7 +683,15 @@ func (m *connectionManager) paymentLoop(opts ConnectOptions, price market.Price)
 
 	go func() {
 		err := payments.Start()
-		if err != nil {
+		if errors.Is(err, connectionstate.ErrSpendingLimitReached) {
+			log.Warn().Err(err).Msg("Session spending limit reached, disconnecting")
+
+			// Disconnect overrides the state, but the reason stays in the status until the next connection.
+			m.statusSpendingLimitReached(err.Error())
+			if err := m.Disconnect(); err != nil {
+				log.Error().Err(err).Msg("Could not disconnect gracefully")
+			}
+		} else if err != nil {
 			log.Error().Err(err).Msg("Payment error")
 
 			if config.GetBool(config.FlagKeepConnectedOnFail) {
@@ -522,10 +735,11 @@ func (m *connectionManager) cleanAfterDisconnect() {
 	m.cleanupAfterDisconnect = nil
 }
 
-func (m *connectionManager) createP2PChannel(opts ConnectOptions, tracer *trace.Tracer) error {
+func (m *connectionManager) createP2PChannel(hop *hopConnection, tracer *trace.Tracer) error {
 	trace := tracer.StartStage("Consumer P2P channel creation")
 	defer tracer.EndStage(trace)
 
+	opts := hop.options
 	contactDef, err := p2p.ParseContact(opts.Proposal.Contacts)
 	if err != nil {
 		return fmt.Errorf("provider does not support p2p communication: %w", err)
@@ -546,7 +760,7 @@ func (m *connectionManager) createP2PChannel(opts ConnectOptions, tracer *trace.
 		return channel.Close()
 	})
 
-	m.channel = channel
+	hop.channel = channel
 	return nil
 }
 
@@ -562,11 +776,12 @@ func (m *connectionManager) addCleanup(fn func() error) {
 	m.cleanup = append(m.cleanup, fn)
 }
 
-func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions, tracer *trace.Tracer, requestedPrice market.Price) (*pb.SessionResponse, error) {
+func (m *connectionManager) createP2PSession(hop *hopConnection, tracer *trace.Tracer, requestedPrice market.Price) (*pb.SessionResponse, error) {
 	trace := tracer.StartStage("Consumer session creation")
 	defer tracer.EndStage(trace)
 
-	sessionCreateConfig, err := c.GetConfig()
+	opts := hop.options
+	sessionCreateConfig, err := hop.connection.GetConfig()
 	if err != nil {
 		return nil, fmt.Errorf("could not get session config: %w", err)
 	}
@@ -595,7 +810,7 @@ func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions,
 	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionCreate, sessionRequest.String())
 	ctx, cancel := context.WithTimeout(m.currentCtx(), 20*time.Second)
 	defer cancel()
-	res, err := m.channel.Send(ctx, p2p.TopicSessionCreate, p2p.ProtoMessage(sessionRequest))
+	res, err := hop.channel.Send(ctx, p2p.TopicSessionCreate, p2p.ProtoMessage(sessionRequest))
 	if err != nil {
 		return nil, fmt.Errorf("could not send p2p session create request: %w", err)
 	}
@@ -605,10 +820,14 @@ func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions,
 	if err != nil {
 		return nil, fmt.Errorf("could not unmarshal session reply to proto: %w", err)
 	}
+	if sessionResponse.GetError() != "" {
+		rejection := session.NewRejection(session.RejectionCode(sessionResponse.GetErrorCode()), sessionResponse.GetError())
+		return nil, fmt.Errorf("provider rejected the session: %w", rejection)
+	}
 	log.Info().Msgf("Provider's session config: %s", string(sessionResponse.Config))
 
-	channel := m.channel
-	m.acknowledge = func() {
+	channel := hop.channel
+	hop.acknowledge = func() {
 		pc := &pb.SessionInfo{
 			ConsumerID: opts.ConsumerID.Address,
 			SessionID:  sessionResponse.GetID(),
@@ -633,7 +852,7 @@ func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions,
 		log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionDestroy, sessionDestroy.String())
 		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 		defer cancel()
-		_, err := m.channel.Send(ctx, p2p.TopicSessionDestroy, p2p.ProtoMessage(sessionDestroy))
+		_, err := channel.Send(ctx, p2p.TopicSessionDestroy, p2p.ProtoMessage(sessionDestroy))
 		if err != nil {
 			return fmt.Errorf("could not send session destroy request: %w", err)
 		}
@@ -647,7 +866,7 @@ func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions,
 func (m *connectionManager) publishSessionCreate(sessionID session.ID) {
 	m.eventBus.Publish(connectionstate.AppTopicConnectionSession, connectionstate.AppEventConnectionSession{
 		Status:      connectionstate.SessionCreatedStatus,
-		SessionInfo: m.Status(),
+		SessionInfo: m.sessionInfo(sessionID),
 	})
 
 	m.addCleanup(func() error {
@@ -655,12 +874,25 @@ func (m *connectionManager) publishSessionCreate(sessionID session.ID) {
 		defer log.Trace().Msg("Cleaning: publishing session ended status DONE")
 		m.eventBus.Publish(connectionstate.AppTopicConnectionSession, connectionstate.AppEventConnectionSession{
 			Status:      connectionstate.SessionEndedStatus,
-			SessionInfo: m.Status(),
+			SessionInfo: m.sessionInfo(sessionID),
 		})
 		return nil
 	})
 }
 
+// sessionInfo returns connection status describing the given session,
+// which is either the exit session or one of multi-hop entry hop sessions.
+func (m *connectionManager) sessionInfo(sessionID session.ID) connectionstate.Status {
+	status := m.Status()
+	for _, hop := range status.Hops {
+		if hop.SessionID == sessionID {
+			status.SessionID = hop.SessionID
+			status.Proposal = hop.Proposal
+		}
+	}
+	return status
+}
+
 func (m *connectionManager) startConnection(ctx context.Context, conn Connection, start ConnectionStart, connectOptions ConnectOptions, tracer *trace.Tracer) (err error) {
 	trace := tracer.StartStage("Consumer start connection")
 	defer tracer.EndStage(trace)
@@ -675,7 +907,18 @@ func (m *connectionManager) startConnection(ctx context.Context, conn Connection
 		return nil
 	})
 
-	err = m.setupTrafficBlock(connectOptions.Params.DisableKillSwitch)
+	if connectOptions.Params.DNS == DNSOptionFiltered {
+		if err = m.startDNSFilter(conn); err != nil {
+			return err
+		}
+	}
+
+	// Proxy connections don't route the traffic of the system, so there is no traffic to block.
+	if _, ok := conn.(ProxyConnection); ok {
+		return nil
+	}
+
+	err = m.setupTrafficBlock(connectOptions.Params)
 	if err != nil {
 		return err
 	}
@@ -686,6 +929,27 @@ func (m *connectionManager) startConnection(ctx context.Context, conn Connection
 	return nil
 }
 
+// startDNSFilter starts the DNS filter resolving through the provider DNS servers of the connection,
+// so that filtered queries don't leave outside of the tunnel.
+func (m *connectionManager) startDNSFilter(conn Connection) error {
+	if m.dnsFilter == nil {
+		return ErrDNSFilterUnavailable
+	}
+	var upstreams []string
+	if dnsConn, ok := conn.(DNSConnection); ok {
+		upstreams = dnsConn.ProviderDNSServers()
+	}
+	if err := m.dnsFilter.Start(upstreams); err != nil {
+		return fmt.Errorf("failed to start DNS filter: %w", err)
+	}
+	m.addCleanup(func() error {
+		log.Trace().Msg("Cleaning: stopping DNS filter")
+		defer log.Trace().Msg("Cleaning: stopping DNS filter DONE")
+		return m.dnsFilter.Stop()
+	})
+	return nil
+}
+
 func (m *connectionManager) Status() connectionstate.Status {
 	m.statusLock.RLock()
 	defer m.statusLock.RUnlock()
@@ -708,7 +972,7 @@ func (m *connectionManager) setStatus(delta func(status *connectionstate.Status)
 	}
 }
 
-func (m *connectionManager) statusConnecting(consumerID identity.Identity, accountantID common.Address, proposal proposal.PricedServiceProposal) {
+func (m *connectionManager) statusConnecting(consumerID identity.Identity, accountantID common.Address, proposal proposal.PricedServiceProposal, limits connectionstate.SpendingLimits) {
 	m.setStatus(func(status *connectionstate.Status) {
 		*status = connectionstate.Status{
 			StartedAt:        m.timeGetter(),
@@ -717,6 +981,7 @@ func (m *connectionManager) statusConnecting(consumerID identity.Identity, accou
 			HermesID:         accountantID,
 			Proposal:         proposal,
 			State:            connectionstate.Connecting,
+			SpendingLimits:   limits,
 		}
 	})
 }
@@ -733,6 +998,7 @@ func (m *connectionManager) statusReconnecting() {
 	})
 }
 
+// statusNotConnected ends the connection, keeping the reason of the state which ended it.
 func (m *connectionManager) statusNotConnected() {
 	m.setStatus(func(status *connectionstate.Status) {
 		status.State = connectionstate.NotConnected
@@ -757,6 +1023,13 @@ func (m *connectionManager) statusOnHold() {
 	})
 }
 
+func (m *connectionManager) statusSpendingLimitReached(reason string) {
+	m.setStatus(func(status *connectionstate.Status) {
+		status.State = connectionstate.StateSpendingLimitReached
+		status.Reason = reason
+	})
+}
+
 func (m *connectionManager) Cancel() {
 	m.statusCanceled()
 	logDisconnectError(m.Disconnect())
@@ -775,8 +1048,12 @@ func (m *connectionManager) Disconnect() error {
 
 func (m *connectionManager) CheckChannel(ctx context.Context) error {
 	if err := m.sendKeepAlivePing(ctx, m.channel, m.Status().SessionID); err != nil {
+		if m.sessionCheckFailed() {
+			return m.failover()
+		}
 		return fmt.Errorf("keep alive ping failed: %w", err)
 	}
+	m.sessionCheckSucceeded()
 	return nil
 }
 
@@ -799,7 +1076,7 @@ func (m *connectionManager) disconnect() {
 	m.cleanAfterDisconnect()
 }
 
-func (m *connectionManager) waitForConnectedState(stateChannel <-chan connectionstate.State) error {
+func (m *connectionManager) waitForConnectedState(hop *hopConnection, stateChannel <-chan connectionstate.State) error {
 	log.Debug().Msg("waiting for connected state")
 	for {
 		select {
@@ -811,8 +1088,8 @@ func (m *connectionManager) waitForConnectedState(stateChannel <-chan connection
 			switch state {
 			case connectionstate.Connected:
 				log.Debug().Msg("Connected started event received")
-				if m.acknowledge != nil {
-					go m.acknowledge()
+				if hop.acknowledge != nil {
+					go hop.acknowledge()
 				}
 				m.onStateChanged(state)
 				return nil
@@ -825,12 +1102,48 @@ func (m *connectionManager) waitForConnectedState(stateChannel <-chan connection
 	}
 }
 
+func (m *connectionManager) waitForHopConnectedState(hop *hopConnection, stateChannel <-chan connectionstate.State) error {
+	log.Debug().Msg("waiting for multi-hop entry hop connected state")
+	for {
+		select {
+		case state, more := <-stateChannel:
+			if !more {
+				return ErrConnectionFailed
+			}
+
+			if state == connectionstate.Connected {
+				if hop.acknowledge != nil {
+					go hop.acknowledge()
+				}
+				return nil
+			}
+		case <-m.currentCtx().Done():
+			return m.currentCtx().Err()
+		}
+	}
+}
+
 func (m *connectionManager) consumeConnectionStates(stateChannel <-chan connectionstate.State) {
 	for state := range stateChannel {
 		m.onStateChanged(state)
 	}
 }
 
+// consumeHopStates follows the states of a multi-hop entry hop. Entry hops don't drive the connection status,
+// but the whole chain is torn down when one of them goes away while the connection is still up.
+func (m *connectionManager) consumeHopStates(ctx context.Context, sessionID session.ID, stateChannel <-chan connectionstate.State) {
+	for state := range stateChannel {
+		log.Debug().Msgf("Multi-hop entry hop state received: %s. SessionID=%s", state, sessionID)
+	}
+
+	select {
+	case <-ctx.Done():
+	default:
+		log.Error().Msgf("Multi-hop entry hop closed, disconnecting the chain. SessionID=%s", sessionID)
+		logDisconnectError(m.Disconnect())
+	}
+}
+
 func (m *connectionManager) onStateChanged(state connectionstate.State) {
 	log.Debug().Msgf("Connection state received: %s", state)
 
@@ -843,8 +1156,25 @@ func (m *connectionManager) onStateChanged(state connectionstate.State) {
 	}
 }
 
-func (m *connectionManager) setupTrafficBlock(disableKillSwitch bool) error {
-	if disableKillSwitch {
+func (m *connectionManager) setupTrafficBlock(params ConnectParams) error {
+	if params.DisableKillSwitch {
+		return nil
+	}
+
+	// Traffic which is not included into the split tunnel goes directly, so just the included networks are blocked.
+	// All traffic stays blocked if none of the included domains resolve.
+	var destinations []string
+	if split := params.SplitTunnel; split.IncludesOnly() {
+		destinations = split.includedNetworks(lookupIP)
+		if len(destinations) == 0 {
+			log.Warn().Msg("Split tunnel included domains did not resolve, kill switch blocks all traffic")
+		}
+	}
+
+	if held := m.takeHeldTrafficBlock(); len(held) > 0 {
+		for _, removeRule := range held {
+			m.addTrafficBlockCleanup(removeRule)
+		}
 		return nil
 	}
 
@@ -853,19 +1183,26 @@ func (m *connectionManager) setupTrafficBlock(disableKillSwitch bool) error {
 		return err
 	}
 
-	removeRule, err := firewall.BlockNonTunnelTraffic(firewall.Session, outboundIP)
+	removeRule, err := firewall.BlockNonTunnelTraffic(firewall.Session, outboundIP, destinations...)
 	if err != nil {
 		return err
 	}
+	m.addTrafficBlockCleanup(removeRule)
+	return nil
+}
+
+func (m *connectionManager) addTrafficBlockCleanup(removeRule firewall.OutgoingRuleRemove) {
 	m.addCleanup(func() error {
 		log.Trace().Msg("Cleaning: traffic block rule")
 		defer log.Trace().Msg("Cleaning: traffic block rule DONE")
 
+		if m.holdTrafficBlock(removeRule) {
+			return nil
+		}
 		removeRule()
 
 		return nil
 	})
-	return nil
 }
 
 func (m *connectionManager) reconnectOnHold(state connectionstate.AppEventConnectionState) {
@@ -873,6 +1210,14 @@ func (m *connectionManager) reconnectOnHold(state connectionstate.AppEventConnec
 		return
 	}
 
+	// Entry hops carry the tunnel of the exit hop, so the chain is reconnected as one unit.
+	if len(m.connectOptions.Params.Hops) > 0 {
+		m.preReconnect()
+		m.Reconnect()
+		m.postReconnect()
+		return
+	}
+
 	if m.channel != nil {
 		m.channel.Close()
 	}
@@ -899,7 +1244,7 @@ func (m *connectionManager) publishStateEvent(state connectionstate.State) {
 	})
 }
 
-func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session.ID) {
+func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session.ID, entryHop bool) {
 	// Register handler for handling p2p keep alive pings from provider.
 	channel.Handle(p2p.TopicKeepAlive, func(c p2p.Context) error {
 		var ping pb.P2PKeepAlivePing
@@ -922,8 +1267,26 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 			ctx, cancel := context.WithTimeout(context.Background(), m.config.KeepAlive.SendTimeout)
 			if err := m.sendKeepAlivePing(ctx, channel, sessionID); err != nil {
 				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sessionID)
+				if entryHop {
+					errCount++
+					if errCount == m.config.KeepAlive.MaxSendErrCount {
+						log.Error().Msgf("Max p2p keepalive err count of multi-hop entry hop reached. SessionID=%s", sessionID)
+						cancel()
+						m.entryHopFailed()
+						return
+					}
+					cancel()
+					continue
+				}
+				if m.sessionCheckFailed() {
+					cancel()
+					if err := m.failover(); err != nil {
+						log.Error().Err(err).Msgf("Failover failed. SessionID=%s", sessionID)
+					}
+					return
+				}
 				errCount++
-				if errCount == m.config.KeepAlive.MaxSendErrCount {
+				if errCount == m.config.KeepAlive.MaxSendErrCount && !m.connectOptions.Params.Failover.Enabled() {
 					log.Error().Msgf("Max p2p keepalive err count reached, disconnecting. SessionID=%s", sessionID)
 					if config.GetBool(config.FlagKeepConnectedOnFail) {
 						m.statusOnHold()
@@ -935,31 +1298,54 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 				}
 			} else {
 				errCount = 0
+				if !entryHop {
+					m.sessionCheckSucceeded()
+				}
 			}
 			cancel()
 		}
 	}
 }
 
-func (m *connectionManager) sendKeepAlivePing(ctx context.Context, channel p2p.Channel, sessionID session.ID) error {
-	msg := &pb.P2PKeepAlivePing{
-		SessionID: string(sessionID),
+// entryHopFailed handles a lost multi-hop entry hop. The exit hop is tunneled through it,
+// so the whole chain is either put on hold, rebuilt or torn down.
+func (m *connectionManager) entryHopFailed() {
+	switch {
+	case config.GetBool(config.FlagKeepConnectedOnFail):
+		m.statusOnHold()
+	case m.connectOptions.Params.Failover.Enabled():
+		m.Reconnect()
+	default:
+		logDisconnectError(m.Disconnect())
 	}
+}
 
-	start := time.Now()
-	_, err := channel.Send(ctx, p2p.TopicKeepAlive, p2p.ProtoMessage(msg))
+func (m *connectionManager) sendKeepAlivePing(ctx context.Context, channel p2p.Channel, sessionID session.ID) error {
+	duration, err := pingProvider(ctx, channel, sessionID)
 	if err != nil {
 		return err
 	}
 
 	m.eventBus.Publish(quality.AppTopicConsumerPingP2P, quality.PingEvent{
 		SessionID: string(sessionID),
-		Duration:  time.Now().Sub(start),
+		Duration:  duration,
 	})
 
 	return nil
 }
 
+func pingProvider(ctx context.Context, channel p2p.Channel, sessionID session.ID) (time.Duration, error) {
+	msg := &pb.P2PKeepAlivePing{
+		SessionID: string(sessionID),
+	}
+
+	start := time.Now()
+	if _, err := channel.Send(ctx, p2p.TopicKeepAlive, p2p.ProtoMessage(msg)); err != nil {
+		return 0, err
+	}
+	return time.Now().Sub(start), nil
+}
+
 func (m *connectionManager) currentCtx() context.Context {
 	m.ctxLock.RLock()
 	defer m.ctxLock.RUnlock()
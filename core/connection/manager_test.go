@@ -39,6 +39,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
@@ -62,6 +63,7 @@ type testContext struct {
 	config                Config
 	statsReportInterval   time.Duration
 	mockP2P               *mockP2PDialer
+	mockDNSFilter         *mockDNSFilter
 	mockTime              time.Time
 	sync.RWMutex
 }
@@ -140,12 +142,13 @@ func (tc *testContext) SetupTest() {
 	brokerConn := nats.StartConnectionMock()
 	brokerConn.MockResponse("fake-node-1.p2p-config-exchange", []byte("123"))
 
-	tc.mockP2P = &mockP2PDialer{&mockP2PChannel{}}
+	tc.mockP2P = &mockP2PDialer{ch: &mockP2PChannel{}}
+	tc.mockDNSFilter = &mockDNSFilter{}
 	tc.mockTime = time.Date(2000, time.January, 0, 10, 12, 3, 0, time.UTC)
 
 	tc.connManager = NewManager(
 		func(channel p2p.Channel,
-			consumer, provider identity.Identity, hermes common.Address, proposal proposal.PricedServiceProposal, price market.Price) (PaymentIssuer, error) {
+			consumer, provider identity.Identity, hermes common.Address, proposal proposal.PricedServiceProposal, price market.Price, limits connectionstate.SpendingLimits) (PaymentIssuer, error) {
 			tc.MockPaymentIssuer = &MockPaymentIssuer{
 				stopChan: make(chan struct{}),
 			}
@@ -159,6 +162,7 @@ func (tc *testContext) SetupTest() {
 		tc.statsReportInterval,
 		&mockValidator{},
 		tc.mockP2P,
+		tc.mockDNSFilter,
 		func() {}, func() {},
 	)
 	tc.connManager.timeGetter = func() time.Time {
@@ -206,6 +210,39 @@ func (tc *testContext) TestWhenManagerMadeConnectionStatusReturnsConnectedStateA
 	)
 }
 
+func (tc *testContext) TestWhenManagerMadeMultiHopConnectionStatusReturnsEntryHops() {
+	entryProposal := activeProposal
+	entryProposal.ProviderID = "fake-node-2"
+	entryProposalLookup := func() (*proposal.PricedServiceProposal, error) {
+		return &entryProposal, nil
+	}
+	entryChannel := &mockP2PChannel{sessionID: "session-200"}
+	tc.mockP2P.hopChannels = map[string]*mockP2PChannel{entryProposal.ProviderID: entryChannel}
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{Hops: []ProposalLookup{entryProposalLookup}})
+	assert.NoError(tc.T(), err)
+	assert.Equal(
+		tc.T(),
+		connectionstate.Status{
+			StartedAt:        tc.mockTime,
+			ConsumerID:       consumerID,
+			ConsumerLocation: consumerLocation,
+			HermesID:         hermesID,
+			State:            connectionstate.Connected,
+			SessionID:        establishedSessionID,
+			Proposal:         activeProposal,
+			Hops: []connectionstate.Hop{
+				{SessionID: "session-200", Proposal: entryProposal},
+			},
+		},
+		tc.connManager.Status(),
+	)
+	assert.Equal(tc.T(), "fake-iface", tc.connManager.connectOptions.OuterIface)
+	assert.Equal(tc.T(), 1, tc.connManager.connectOptions.NestingLevel)
+	assert.Equal(tc.T(), establishedSessionID, tc.connManager.connectOptions.SessionID)
+	assert.Same(tc.T(), tc.mockP2P.ch, tc.connManager.channel)
+}
+
 func (tc *testContext) TestSessionDoesFullReconnectOnWakeupEvent() {
 	tc.connManager.eventBus = eventbus.New()
 
@@ -249,6 +286,125 @@ func (tc *testContext) TestSessionDoesFullReconnectOnWakeupEvent() {
 	assert.Equal(tc.T(), <-stateCh, connectionstate.Connected)
 }
 
+func (tc *testContext) TestFailoverSwitchesToNextProposalWhenSessionChecksFail() {
+	tc.connManager.eventBus = eventbus.New()
+
+	nextProposal := activeProposal
+	nextProposal.ProviderID = "fake-node-2"
+	// Lookup keeps returning the failed provider first, as long as it is the best one.
+	var lookups int
+	proposalLookup := func() (*proposal.PricedServiceProposal, error) {
+		lookups++
+		if lookups <= 2 {
+			return &activeProposal, nil
+		}
+		return &nextProposal, nil
+	}
+
+	failoverCh := make(chan connectionstate.AppEventConnectionFailover, 1)
+	tc.connManager.eventBus.Subscribe(connectionstate.AppTopicConnectionFailover, func(e connectionstate.AppEventConnectionFailover) {
+		select {
+		case failoverCh <- e:
+		default:
+		}
+	})
+
+	err := tc.connManager.Connect(consumerID, hermesID, proposalLookup, ConnectParams{Failover: FailoverPolicy{MaxFailures: 2}})
+	assert.NoError(tc.T(), err)
+
+	select {
+	case e := <-failoverCh:
+		assert.Equal(tc.T(), activeProposal, e.FailedProposal)
+		assert.Equal(tc.T(), connectionstate.Connected, e.SessionInfo.State)
+		assert.Equal(tc.T(), nextProposal, e.SessionInfo.Proposal)
+	case <-time.After(2 * time.Second):
+		tc.Fail("failover event not published")
+	}
+	tc.connManager.Disconnect()
+}
+
+func (tc *testContext) TestFailoverDisconnectsWhenThereIsNoOtherProvider() {
+	tc.connManager.eventBus = eventbus.New()
+
+	failoverCh := make(chan connectionstate.AppEventConnectionFailover, 1)
+	tc.connManager.eventBus.Subscribe(connectionstate.AppTopicConnectionFailover, func(e connectionstate.AppEventConnectionFailover) {
+		select {
+		case failoverCh <- e:
+		default:
+		}
+	})
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{Failover: FailoverPolicy{MaxFailures: 2}})
+	assert.NoError(tc.T(), err)
+
+	assert.Eventually(tc.T(), func() bool {
+		return tc.connManager.Status().State == connectionstate.NotConnected
+	}, 2*time.Second, 10*time.Millisecond)
+	select {
+	case e := <-failoverCh:
+		tc.Failf("failover event published", "failed over to %s", e.SessionInfo.Proposal.ProviderID)
+	default:
+	}
+}
+
+func (tc *testContext) TestKillSwitchBlocksJustIncludedNetworksOfSplitTunnel() {
+	fw := &mockOutgoingFirewall{}
+	defaultFirewall := firewall.DefaultOutgoingFirewall
+	firewall.DefaultOutgoingFirewall = fw
+	defer func() {
+		firewall.DefaultOutgoingFirewall = defaultFirewall
+	}()
+
+	params := ConnectParams{SplitTunnel: SplitTunnel{IncludeCIDRs: []string{"10.0.0.0/8"}}}
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, params)
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), [][]string{{"10.0.0.0/8"}}, fw.getBlockedDestinations())
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+func (tc *testContext) TestKillSwitchBlocksResolvedDomainsOfSplitTunnel() {
+	fw := &mockOutgoingFirewall{}
+	defaultFirewall := firewall.DefaultOutgoingFirewall
+	firewall.DefaultOutgoingFirewall = fw
+	defaultLookupIP := lookupIP
+	lookupIP = func(host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("93.184.216.34"), net.ParseIP("2606:2800:220:1::1")}, nil
+	}
+	defer func() {
+		firewall.DefaultOutgoingFirewall = defaultFirewall
+		lookupIP = defaultLookupIP
+	}()
+
+	params := ConnectParams{SplitTunnel: SplitTunnel{IncludeDomains: []string{"example.com"}}}
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, params)
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), [][]string{{"93.184.216.34/32", "2606:2800:220:1::1/128"}}, fw.getBlockedDestinations())
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+func (tc *testContext) TestKillSwitchBlocksAllTrafficWhenSplitTunnelDomainsDoNotResolve() {
+	fw := &mockOutgoingFirewall{}
+	defaultFirewall := firewall.DefaultOutgoingFirewall
+	firewall.DefaultOutgoingFirewall = fw
+	defaultLookupIP := lookupIP
+	lookupIP = func(host string) ([]net.IP, error) {
+		return nil, errors.New("no such host")
+	}
+	defer func() {
+		firewall.DefaultOutgoingFirewall = defaultFirewall
+		lookupIP = defaultLookupIP
+	}()
+
+	params := ConnectParams{SplitTunnel: SplitTunnel{IncludeDomains: []string{"example.com"}}}
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, params)
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), [][]string{nil}, fw.getBlockedDestinations())
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
 func (tc *testContext) TestStatusReportsConnectingWhenConnectionIsInProgress() {
 	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{}
 
@@ -376,6 +532,24 @@ func (tc *testContext) TestTwoConnectDisconnectCyclesReturnNoError() {
 	assert.Equal(tc.T(), connectionstate.NotConnected, tc.connManager.Status().State)
 }
 
+func (tc *testContext) TestFilteredDNSIsServedWhileConnected() {
+	tc.fakeConnectionFactory.mockConnection.providerDNS = "10.182.0.1"
+
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{DNS: DNSOptionFiltered}))
+	assert.True(tc.T(), tc.mockDNSFilter.isRunning())
+	assert.Equal(tc.T(), []string{"10.182.0.1"}, tc.mockDNSFilter.getUpstreams())
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	waitABit()
+	assert.False(tc.T(), tc.mockDNSFilter.isRunning())
+}
+
+func (tc *testContext) TestConnectFailsIfDNSFilterFailsToStart() {
+	tc.mockDNSFilter.startErr = errors.New("address already in use")
+	assert.Error(tc.T(), tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{DNS: DNSOptionFiltered}))
+	assert.False(tc.T(), tc.mockDNSFilter.isRunning())
+}
+
 func (tc *testContext) TestConnectFailsIfConnectionFactoryReturnsError() {
 	tc.fakeConnectionFactory.mockError = errors.New("failed to create connection instance")
 	assert.Error(tc.T(), tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{}))
@@ -614,6 +788,139 @@ func (tc *testContext) Test_ManagerNotifiesAboutSuccessfulConnection() {
 	)
 }
 
+func (tc *testContext) Test_ManagerDisconnects_WhenSpendingLimitReached() {
+	tc.stubPublisher.Clear()
+
+	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{
+		connectedState,
+	}
+	tc.connManager.paymentEngineFactory = func(channel p2p.Channel,
+		consumer, provider identity.Identity, hermes common.Address, proposal proposal.PricedServiceProposal, price market.Price, limits connectionstate.SpendingLimits) (PaymentIssuer, error) {
+		tc.MockPaymentIssuer = &MockPaymentIssuer{
+			MockError: fmt.Errorf("invoice refused: %w", connectionstate.ErrSpendingLimitReached),
+			stopChan:  make(chan struct{}),
+		}
+		return tc.MockPaymentIssuer, nil
+	}
+
+	limits := connectionstate.SpendingLimits{MaxSessionSpend: big.NewInt(10)}
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{SpendingLimits: limits})
+	assert.NoError(tc.T(), err)
+
+	waitABit()
+
+	var limitEvent, lastEvent *connectionstate.AppEventConnectionState
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.Topic != connectionstate.AppTopicConnectionState {
+			continue
+		}
+		e := v.Event.(connectionstate.AppEventConnectionState)
+		if e.State == connectionstate.StateSpendingLimitReached {
+			limitEvent = &e
+		}
+		lastEvent = &e
+	}
+	assert.NotNil(tc.T(), limitEvent)
+	assert.Contains(tc.T(), limitEvent.SessionInfo.Reason, connectionstate.ErrSpendingLimitReached.Error())
+	assert.Equal(tc.T(), limits, limitEvent.SessionInfo.SpendingLimits)
+
+	// Final state carries the reason of disconnect.
+	assert.NotNil(tc.T(), lastEvent)
+	assert.Equal(tc.T(), connectionstate.NotConnected, lastEvent.State)
+	assert.Equal(tc.T(), limitEvent.SessionInfo.Reason, lastEvent.SessionInfo.Reason)
+	status := tc.connManager.Status()
+	assert.Equal(tc.T(), connectionstate.NotConnected, status.State)
+	assert.Equal(tc.T(), limitEvent.SessionInfo.Reason, status.Reason)
+}
+
+func (tc *testContext) Test_ManagerDiagnose_ProbesActiveConnection() {
+	_, err := tc.connManager.Diagnose(context.Background())
+	assert.Equal(tc.T(), ErrNoConnection, err)
+
+	tc.connManager.config.Diagnostics = DiagnosticsConfig{
+		PingCount:    3,
+		PingInterval: time.Millisecond,
+		PingTimeout:  time.Second,
+		DNSTimeout:   time.Second,
+	}
+	var resolvedWith []string
+	tc.connManager.resolveHost = func(_ context.Context, servers []string, host string) error {
+		resolvedWith = servers
+		return nil
+	}
+	tc.fakeConnectionFactory.mockConnection.onStartReportStats = connectionstate.Statistics{
+		At:            tc.mockTime,
+		LastHandshake: tc.mockTime.Add(-time.Minute),
+	}
+
+	err = tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{DNS: "1.1.1.1"})
+	assert.NoError(tc.T(), err)
+	tc.stubPublisher.Clear()
+
+	result, err := tc.connManager.Diagnose(context.Background())
+	assert.NoError(tc.T(), err)
+	assert.True(tc.T(), result.Completed)
+	assert.Equal(tc.T(), establishedSessionID, result.SessionID)
+	assert.Equal(tc.T(), 3, result.PingsSent)
+	assert.Equal(tc.T(), 3, result.PingsLost, "mocked channel fails keep-alive pings")
+	assert.Equal(tc.T(), float64(1), result.PacketLoss())
+	assert.Equal(tc.T(), "1.1.1.1", result.DNSOption)
+	assert.Equal(tc.T(), []string{"1.1.1.1"}, resolvedWith)
+	assert.Empty(tc.T(), result.DNSError)
+	assert.Equal(tc.T(), time.Minute, result.HandshakeAge)
+
+	var diagnosticsEvents int
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.Topic == connectionstate.AppTopicConnectionDiagnostics {
+			diagnosticsEvents++
+		}
+	}
+	assert.Equal(tc.T(), 5, diagnosticsEvents)
+}
+
+func (tc *testContext) Test_ManagerDiagnose_StopsAfterTimeout() {
+	tc.connManager.config.Diagnostics = DiagnosticsConfig{
+		Timeout:      50 * time.Millisecond,
+		PingCount:    1000,
+		PingInterval: 10 * time.Millisecond,
+		PingTimeout:  time.Second,
+		DNSTimeout:   time.Second,
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	start := time.Now()
+	result, err := tc.connManager.Diagnose(context.Background())
+	assert.Equal(tc.T(), context.DeadlineExceeded, err)
+	assert.False(tc.T(), result.Completed)
+	assert.Less(tc.T(), time.Since(start), 5*time.Second)
+}
+
+func (tc *testContext) Test_ManagerDiagnose_ResolvesWithProviderDNS() {
+	tc.connManager.config.Diagnostics = DiagnosticsConfig{
+		PingCount:    1,
+		PingInterval: time.Millisecond,
+		PingTimeout:  time.Second,
+		DNSTimeout:   time.Second,
+	}
+	var resolvedWith []string
+	tc.connManager.resolveHost = func(_ context.Context, servers []string, host string) error {
+		resolvedWith = servers
+		return nil
+	}
+	tc.fakeConnectionFactory.mockConnection.providerDNS = "10.182.0.1"
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{DNS: DNSOptionAuto})
+	assert.NoError(tc.T(), err)
+
+	result, err := tc.connManager.Diagnose(context.Background())
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), "auto", result.DNSOption)
+	assert.Equal(tc.T(), []string{"10.182.0.1"}, result.DNSServers)
+	assert.Equal(tc.T(), []string{"10.182.0.1"}, resolvedWith)
+}
+
 func TestConnectionManagerSuite(t *testing.T) {
 	suite.Run(t, new(testContext))
 }
@@ -635,9 +942,13 @@ type MockPaymentIssuer struct {
 func (mpm *MockPaymentIssuer) Start() error {
 	mpm.Lock()
 	mpm.startCalled = true
+	err := mpm.MockError
 	mpm.Unlock()
+	if err != nil {
+		return err
+	}
 	<-mpm.stopChan
-	return mpm.MockError
+	return nil
 }
 
 func (mpm *MockPaymentIssuer) StartCalled() bool {
@@ -664,15 +975,21 @@ func (mpm *MockPaymentIssuer) SetSessionID(string) {
 
 type mockP2PDialer struct {
 	ch *mockP2PChannel
+	// hopChannels are channels to other providers than the active one, keyed by provider ID.
+	hopChannels map[string]*mockP2PChannel
 }
 
 func (m mockP2PDialer) Dial(ctx context.Context, consumerID identity.Identity, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition, tracer *trace.Tracer) (p2p.Channel, error) {
+	if ch, ok := m.hopChannels[providerID.Address]; ok {
+		return ch, nil
+	}
 	return m.ch, nil
 }
 
 type mockP2PChannel struct {
-	status proto.Message
-	lock   sync.Mutex
+	sessionID session.ID
+	status    proto.Message
+	lock      sync.Mutex
 }
 
 func (m *mockP2PChannel) Conn() *net.UDPConn {
@@ -688,8 +1005,12 @@ func (m *mockP2PChannel) getSentMsg() proto.Message {
 func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
 	switch topic {
 	case p2p.TopicSessionCreate:
+		sessionID := establishedSessionID
+		if m.sessionID != "" {
+			sessionID = m.sessionID
+		}
 		res := &pb.SessionResponse{
-			ID: string(establishedSessionID),
+			ID: string(sessionID),
 		}
 		return p2p.ProtoMessage(res), nil
 	case p2p.TopicSessionStatus:
@@ -713,6 +1034,10 @@ func (m *mockP2PChannel) Tracer() *trace.Tracer {
 	return nil
 }
 
+func (m *mockP2PChannel) RemoteAddr() *net.UDPAddr {
+	return nil
+}
+
 func (m *mockP2PChannel) ServiceConn() *net.UDPConn {
 	raddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:12345")
 	conn, _ := net.DialUDP("udp", nil, raddr)
@@ -727,6 +1052,45 @@ func (m *mockP2PChannel) ID() string {
 	return fmt.Sprintf("%p", m)
 }
 
+type mockOutgoingFirewall struct {
+	blockedDestinations [][]string
+	lock                sync.Mutex
+}
+
+func (m *mockOutgoingFirewall) Setup() error {
+	return nil
+}
+
+func (m *mockOutgoingFirewall) Teardown() {
+}
+
+func (m *mockOutgoingFirewall) BlockOutgoingTraffic(scope firewall.Scope, outboundIP string, destinations ...string) (firewall.OutgoingRuleRemove, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.blockedDestinations = append(m.blockedDestinations, destinations)
+	return func() {}, nil
+}
+
+func (m *mockOutgoingFirewall) AllowIPAccess(ip string) (firewall.OutgoingRuleRemove, error) {
+	return func() {}, nil
+}
+
+func (m *mockOutgoingFirewall) AllowURLAccess(rawURLs ...string) (firewall.OutgoingRuleRemove, error) {
+	return func() {}, nil
+}
+
+func (m *mockOutgoingFirewall) AllowAppAccess(cgroup string) (firewall.OutgoingRuleRemove, error) {
+	return func() {}, nil
+}
+
+func (m *mockOutgoingFirewall) getBlockedDestinations() [][]string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.blockedDestinations
+}
+
 type mockValidator struct {
 	errorToReturn error
 }
@@ -735,6 +1099,47 @@ func (mv *mockValidator) Validate(chainID int64, consumerID identity.Identity, p
 	return mv.errorToReturn
 }
 
+type mockDNSFilter struct {
+	startErr  error
+	running   bool
+	upstreams []string
+	mu        sync.Mutex
+}
+
+func (mf *mockDNSFilter) Start(upstreams []string) error {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+
+	if mf.startErr != nil {
+		return mf.startErr
+	}
+	mf.running = true
+	mf.upstreams = upstreams
+	return nil
+}
+
+func (mf *mockDNSFilter) getUpstreams() []string {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+
+	return mf.upstreams
+}
+
+func (mf *mockDNSFilter) Stop() error {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+
+	mf.running = false
+	return nil
+}
+
+func (mf *mockDNSFilter) isRunning() bool {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+
+	return mf.running
+}
+
 type mockLocationResolver struct{}
 
 func (mlr *mockLocationResolver) GetOrigin() locationstate.Location {
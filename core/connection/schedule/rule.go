@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// connectAtLayout is the layout of the local time of day at which scheduled rule connects.
+const connectAtLayout = "15:04"
+
+// Rule describes a scheduled or conditional consumer connection.
+// Rules without a connect time guard every connection of the node.
+type Rule struct {
+	ID int `storm:"id,increment"`
+	// ConsumerID is the identity used to connect at the scheduled time
+	ConsumerID string
+	// ServiceType is the type of service to connect to
+	ServiceType string
+	// CountryCode filters proposals by provider country
+	CountryCode string
+	// PresetID filters proposals by proposal filter preset
+	PresetID int
+	// ConnectAt is the local time of day in "15:04" format to connect at, empty for guard rules
+	ConnectAt string
+	// DisconnectAfter limits the duration of connection, 0 means no limit
+	DisconnectAfter time.Duration
+	// MaxTraffic limits the bytes transferred during connection, 0 means no limit
+	MaxTraffic uint64
+	// MaxSpend limits the tokens spent during connection, nil means no limit
+	MaxSpend *big.Int
+	// ReconnectOnNetworkChange reconnects when the default gateway of the node changes
+	ReconnectOnNetworkChange bool
+}
+
+// Validate checks if the rule is well formed.
+func (r Rule) Validate() error {
+	if r.ConnectAt != "" {
+		if _, err := time.Parse(connectAtLayout, r.ConnectAt); err != nil {
+			return fmt.Errorf("invalid connect time %q, expected format is HH:MM", r.ConnectAt)
+		}
+		if r.ConsumerID == "" {
+			return errors.New("consumer is required for scheduled connection")
+		}
+		if r.ServiceType == "" {
+			return errors.New("service type is required for scheduled connection")
+		}
+	}
+	if r.DisconnectAfter < 0 {
+		return errors.New("connection duration limit can't be negative")
+	}
+	if r.MaxSpend != nil && r.MaxSpend.Sign() < 0 {
+		return errors.New("spending limit can't be negative")
+	}
+	if r.ConnectAt == "" && !r.limited() && !r.ReconnectOnNetworkChange {
+		return errors.New("rule defines neither connect time nor any condition")
+	}
+	return nil
+}
+
+// Scheduled checks if the rule connects at a given time of day.
+func (r Rule) Scheduled() bool {
+	return r.ConnectAt != ""
+}
+
+// connectsBetween returns the connect time of the rule, which is after from and not after to.
+func (r Rule) connectsBetween(from, to time.Time) (time.Time, bool) {
+	if !r.Scheduled() {
+		return time.Time{}, false
+	}
+	clock, err := time.Parse(connectAtLayout, r.ConnectAt)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// Period may span midnight, so connect times of both days are checked.
+	for _, day := range []time.Time{from, to} {
+		year, month, date := day.Date()
+		at := time.Date(year, month, date, clock.Hour(), clock.Minute(), 0, 0, day.Location())
+		if at.After(from) && !at.After(to) {
+			return at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r Rule) limited() bool {
+	return r.DisconnectAfter > 0 || r.MaxTraffic > 0 || (r.MaxSpend != nil && r.MaxSpend.Sign() > 0)
+}
+
+// exceeded returns the description of the first limit exceeded by the given usage, empty if none is.
+func (r Rule) exceeded(duration time.Duration, traffic uint64, spent *big.Int) string {
+	switch {
+	case r.DisconnectAfter > 0 && duration >= r.DisconnectAfter:
+		return fmt.Sprintf("connection lasted longer than %s", r.DisconnectAfter)
+	case r.MaxTraffic > 0 && traffic >= r.MaxTraffic:
+		return fmt.Sprintf("connection transferred more than %d bytes", r.MaxTraffic)
+	case r.MaxSpend != nil && r.MaxSpend.Sign() > 0 && spent.Cmp(r.MaxSpend) >= 0:
+		return fmt.Sprintf("connection spent more than %s tokens", r.MaxSpend)
+	}
+	return ""
+}
@@ -0,0 +1,422 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// DefaultCheckInterval is the interval at which scheduler checks rules and connection limits.
+const DefaultCheckInterval = 10 * time.Second
+
+var errNoProposals = errors.New("no providers available for the scheduled connection")
+
+type ruleStorage interface {
+	List() ([]Rule, error)
+}
+
+type connectionManager interface {
+	Connect(consumerID identity.Identity, hermesID common.Address, proposal connection.ProposalLookup, params connection.ConnectParams) error
+	Status() connectionstate.Status
+	Disconnect() error
+	Reconnect()
+}
+
+type proposalRepository interface {
+	Proposals(filter *proposal.Filter) ([]proposal.PricedServiceProposal, error)
+}
+
+type hermesProvider interface {
+	GetActiveHermes(chainID int64) (common.Address, error)
+}
+
+// GatewayDiscoverer detects the default gateway of the node.
+type GatewayDiscoverer interface {
+	DiscoverGateway() (net.IP, error)
+}
+
+// usage accumulates resources used by the current connection.
+type usage struct {
+	rules     []Rule
+	startedAt time.Time
+	traffic   map[session.ID]uint64
+	spent     map[session.ID]*big.Int
+	stopping  bool
+
+	// usage of sessions ended during reconnect is carried over to the new sessions
+	carriedTraffic uint64
+	carriedSpent   *big.Int
+}
+
+func (u *usage) totalTraffic() uint64 {
+	total := u.carriedTraffic
+	for _, t := range u.traffic {
+		total += t
+	}
+	return total
+}
+
+func (u *usage) totalSpent() *big.Int {
+	total := new(big.Int).Set(u.carriedSpent)
+	for _, s := range u.spent {
+		total.Add(total, s)
+	}
+	return total
+}
+
+func (u *usage) endSession(sessionID session.ID) {
+	u.carriedTraffic += u.traffic[sessionID]
+	if spent, ok := u.spent[sessionID]; ok {
+		u.carriedSpent.Add(u.carriedSpent, spent)
+	}
+	delete(u.traffic, sessionID)
+	delete(u.spent, sessionID)
+}
+
+// Scheduler connects at the time defined by schedule rules and
+// disconnects when connection exceeds limits defined by the rules.
+type Scheduler struct {
+	storage       ruleStorage
+	manager       connectionManager
+	proposals     proposalRepository
+	hermes        hermesProvider
+	gateway       GatewayDiscoverer
+	checkInterval time.Duration
+	timeGetter    func() time.Time
+
+	mu           sync.Mutex
+	lastCheck    time.Time
+	fired        map[int]string
+	connectRule  *Rule
+	usage        *usage
+	currentGW    net.IP
+	reconnecting bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewScheduler returns a new instance of connection scheduler.
+func NewScheduler(storage ruleStorage, manager connectionManager, proposals proposalRepository, hermes hermesProvider, gateway GatewayDiscoverer, checkInterval time.Duration) *Scheduler {
+	return &Scheduler{
+		storage:       storage,
+		manager:       manager,
+		proposals:     proposals,
+		hermes:        hermes,
+		gateway:       gateway,
+		checkInterval: checkInterval,
+		timeGetter:    time.Now,
+		fired:         make(map[int]string),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Subscribe subscribes to connection and payment events of event bus.
+func (s *Scheduler) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionSession, s.consumeSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionStatistics, s.consumeStatisticsEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(pingpong_event.AppTopicInvoicePaid, s.consumeInvoicePaidEvent)
+}
+
+// Start starts checking schedule rules periodically.
+func (s *Scheduler) Start() {
+	go func() {
+		for {
+			select {
+			case <-time.After(s.checkInterval):
+				s.check()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler.
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+func (s *Scheduler) check() {
+	s.checkLimits()
+	s.checkNetwork()
+	s.checkSchedule()
+}
+
+func (s *Scheduler) checkSchedule() {
+	rules, err := s.storage.List()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load connection schedule rules")
+		return
+	}
+
+	// Rules are checked for the whole period since the previous check, so a connect time
+	// is not skipped when a scheduled connection takes longer than the check interval.
+	now := s.timeGetter()
+	s.mu.Lock()
+	since := s.lastCheck
+	if since.IsZero() {
+		since = now.Add(-s.checkInterval)
+	}
+	s.lastCheck = now
+	s.mu.Unlock()
+
+	for i := range rules {
+		rule := rules[i]
+		at, ok := rule.connectsBetween(since, now)
+		if !ok {
+			continue
+		}
+
+		day := at.Format("2006-01-02")
+		s.mu.Lock()
+		alreadyFired := s.fired[rule.ID] == day
+		s.fired[rule.ID] = day
+		s.mu.Unlock()
+		if alreadyFired {
+			continue
+		}
+
+		if s.manager.Status().State != connectionstate.NotConnected {
+			log.Info().Msgf("Skipping scheduled connection of rule %d, connection already exists", rule.ID)
+			continue
+		}
+
+		s.connect(rule)
+	}
+}
+
+func (s *Scheduler) connect(rule Rule) {
+	hermesID, err := s.hermes.GetActiveHermes(config.GetInt64(config.FlagChainID))
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to get active hermes for scheduled connection of rule %d", rule.ID)
+		return
+	}
+
+	s.mu.Lock()
+	s.connectRule = &rule
+	s.mu.Unlock()
+
+	log.Info().Msgf("Starting scheduled connection of rule %d", rule.ID)
+	params := connection.ConnectParams{DNS: connection.DNSOptionAuto}
+	if err := s.manager.Connect(identity.FromAddress(rule.ConsumerID), hermesID, s.proposalLookup(rule), params); err != nil {
+		log.Error().Err(err).Msgf("Failed to start scheduled connection of rule %d", rule.ID)
+
+		s.mu.Lock()
+		s.connectRule = nil
+		s.mu.Unlock()
+	}
+}
+
+func (s *Scheduler) proposalLookup(rule Rule) connection.ProposalLookup {
+	filter := &proposal.Filter{
+		ServiceType:     rule.ServiceType,
+		LocationCountry: rule.CountryCode,
+		PresetID:        rule.PresetID,
+		AccessPolicy:    "all",
+	}
+
+	return func() (*proposal.PricedServiceProposal, error) {
+		proposals, err := s.proposals.Proposals(filter)
+		if err != nil {
+			return nil, err
+		}
+		if len(proposals) == 0 {
+			return nil, errNoProposals
+		}
+
+		proposals = proposal.SortByQuality(proposals)
+		return &proposals[0], nil
+	}
+}
+
+func (s *Scheduler) checkLimits() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.enforceLimits()
+}
+
+// enforceLimits disconnects when current connection exceeds a limit of any rule, must be called with s.mu held.
+func (s *Scheduler) enforceLimits() {
+	u := s.usage
+	if u == nil || u.stopping {
+		return
+	}
+
+	duration := s.timeGetter().Sub(u.startedAt)
+	traffic := u.totalTraffic()
+	spent := u.totalSpent()
+	for _, rule := range u.rules {
+		reason := rule.exceeded(duration, traffic, spent)
+		if reason == "" {
+			continue
+		}
+
+		log.Info().Msgf("Disconnecting by schedule rule %d: %s", rule.ID, reason)
+		u.stopping = true
+		go func() {
+			if err := s.manager.Disconnect(); err != nil && err != connection.ErrNoConnection {
+				log.Error().Err(err).Msg("Failed to disconnect by schedule rule")
+			}
+		}()
+		return
+	}
+}
+
+func (s *Scheduler) checkNetwork() {
+	gw, err := s.gateway.DiscoverGateway()
+	if err != nil || gw == nil || gw.Equal(net.IPv4zero) {
+		return
+	}
+
+	s.mu.Lock()
+	changed := s.currentGW != nil && !s.currentGW.Equal(gw)
+	s.currentGW = gw
+	reconnect := changed && s.usage != nil && !s.usage.stopping && s.reconnectsOnNetworkChange()
+	s.mu.Unlock()
+
+	if !reconnect || s.manager.Status().State != connectionstate.Connected {
+		return
+	}
+
+	log.Info().Msgf("Default gateway changed to %s, reconnecting by schedule rule", gw)
+	s.setReconnecting(true)
+	defer s.setReconnecting(false)
+	s.manager.Reconnect()
+}
+
+func (s *Scheduler) setReconnecting(reconnecting bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reconnecting = reconnecting
+}
+
+func (s *Scheduler) reconnectsOnNetworkChange() bool {
+	for _, rule := range s.usage.rules {
+		if rule.ReconnectOnNetworkChange {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) consumeSessionEvent(e connectionstate.AppEventConnectionSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionID := e.SessionInfo.SessionID
+	switch e.Status {
+	case connectionstate.SessionCreatedStatus:
+		if s.usage == nil {
+			s.usage = &usage{
+				rules:     s.applicableRules(),
+				startedAt: s.timeGetter(),
+				traffic:   make(map[session.ID]uint64),
+				spent:     make(map[session.ID]*big.Int),
+
+				carriedSpent: new(big.Int),
+			}
+		}
+		s.usage.traffic[sessionID] = 0
+		s.usage.spent[sessionID] = new(big.Int)
+	case connectionstate.SessionEndedStatus:
+		if s.usage == nil {
+			return
+		}
+		s.usage.endSession(sessionID)
+		if len(s.usage.traffic) == 0 && !s.reconnecting {
+			s.usage = nil
+			s.connectRule = nil
+		}
+	}
+}
+
+// applicableRules returns the guard rules and the rule which started connection, must be called with s.mu held.
+func (s *Scheduler) applicableRules() []Rule {
+	var applicable []Rule
+	if s.connectRule != nil {
+		applicable = append(applicable, *s.connectRule)
+	}
+
+	rules, err := s.storage.List()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load connection schedule rules")
+		return applicable
+	}
+	for _, rule := range rules {
+		if !rule.Scheduled() {
+			applicable = append(applicable, rule)
+		}
+	}
+	return applicable
+}
+
+func (s *Scheduler) consumeStatisticsEvent(e connectionstate.AppEventConnectionStatistics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.usage == nil {
+		return
+	}
+	if _, ok := s.usage.traffic[e.SessionInfo.SessionID]; !ok {
+		return
+	}
+
+	s.usage.traffic[e.SessionInfo.SessionID] = e.Stats.BytesSent + e.Stats.BytesReceived
+	s.enforceLimits()
+}
+
+func (s *Scheduler) consumeInvoicePaidEvent(e pingpong_event.AppEventInvoicePaid) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.usage == nil || e.Invoice.AgreementTotal == nil {
+		return
+	}
+	sessionID := session.ID(e.SessionID)
+	if _, ok := s.usage.spent[sessionID]; !ok {
+		return
+	}
+
+	s.usage.spent[sessionID] = new(big.Int).Set(e.Invoice.AgreementTotal)
+	s.enforceLimits()
+}
@@ -0,0 +1,348 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+func TestScheduler_ConnectsOncePerDayAtScheduledTime(t *testing.T) {
+	// given
+	manager := &mockConnectionManager{}
+	proposals := &mockProposalRepository{proposals: []proposal.PricedServiceProposal{
+		{ServiceProposal: market.ServiceProposal{ProviderID: "0x1"}},
+	}}
+	storage := &mockRuleStorage{rules: []Rule{
+		{ID: 1, ConsumerID: "0xc", ServiceType: "wireguard", CountryCode: "DE", ConnectAt: "08:30"},
+	}}
+	scheduler := newTestScheduler(storage, manager, proposals)
+
+	// when
+	scheduler.timeGetter = fixedTime("08:29")
+	scheduler.checkSchedule()
+
+	// then
+	assert.Equal(t, 0, manager.connects)
+
+	// when
+	scheduler.timeGetter = fixedTime("08:30")
+	scheduler.checkSchedule()
+	scheduler.checkSchedule()
+
+	// then
+	assert.Equal(t, 1, manager.connects)
+	assert.Equal(t, identity.FromAddress("0xc"), manager.consumerID)
+	assert.Equal(t, "0x1", manager.proposal.ProviderID)
+	assert.Equal(t, "DE", proposals.filter.LocationCountry)
+	assert.Equal(t, "wireguard", proposals.filter.ServiceType)
+}
+
+func TestScheduler_ConnectsWhenScheduledTimePassedDuringPreviousCheck(t *testing.T) {
+	// given
+	manager := &mockConnectionManager{}
+	proposals := &mockProposalRepository{proposals: []proposal.PricedServiceProposal{
+		{ServiceProposal: market.ServiceProposal{ProviderID: "0x1"}},
+	}}
+	storage := &mockRuleStorage{rules: []Rule{
+		{ID: 1, ConsumerID: "0xc", ServiceType: "wireguard", ConnectAt: "08:30"},
+		{ID: 2, ConsumerID: "0xc", ServiceType: "wireguard", ConnectAt: "06:00"},
+	}}
+	scheduler := newTestScheduler(storage, manager, proposals)
+
+	// when
+	scheduler.timeGetter = fixedTime("08:29")
+	scheduler.checkSchedule()
+	scheduler.timeGetter = fixedTime("08:32")
+	scheduler.checkSchedule()
+	scheduler.timeGetter = fixedTime("08:33")
+	scheduler.checkSchedule()
+
+	// then
+	assert.Equal(t, 1, manager.connects)
+}
+
+func TestScheduler_SkipsScheduledConnectionWhenConnected(t *testing.T) {
+	// given
+	manager := &mockConnectionManager{state: connectionstate.Connected}
+	storage := &mockRuleStorage{rules: []Rule{
+		{ID: 1, ConsumerID: "0xc", ServiceType: "wireguard", ConnectAt: "08:30"},
+	}}
+	scheduler := newTestScheduler(storage, manager, &mockProposalRepository{})
+	scheduler.timeGetter = fixedTime("08:30")
+
+	// when
+	scheduler.checkSchedule()
+
+	// then
+	assert.Equal(t, 0, manager.connects)
+}
+
+func TestScheduler_DisconnectsWhenSpendingLimitIsExceeded(t *testing.T) {
+	// given
+	manager := &mockConnectionManager{state: connectionstate.Connected}
+	storage := &mockRuleStorage{rules: []Rule{
+		{ID: 1, MaxSpend: big.NewInt(100)},
+	}}
+	scheduler := newTestScheduler(storage, manager, &mockProposalRepository{})
+	scheduler.consumeSessionEvent(sessionEvent(connectionstate.SessionCreatedStatus, "s1"))
+
+	// when
+	scheduler.consumeInvoicePaidEvent(invoicePaidEvent("s1", 99))
+
+	// then
+	assert.Equal(t, 0, manager.disconnectCount())
+
+	// when
+	scheduler.consumeInvoicePaidEvent(invoicePaidEvent("s1", 100))
+
+	// then
+	assert.Eventually(t, func() bool { return manager.disconnectCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	// when
+	scheduler.consumeInvoicePaidEvent(invoicePaidEvent("s1", 110))
+
+	// then
+	assert.Never(t, func() bool { return manager.disconnectCount() > 1 }, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestScheduler_DisconnectsWhenTrafficOrDurationLimitIsExceeded(t *testing.T) {
+	// given
+	manager := &mockConnectionManager{state: connectionstate.Connected}
+	storage := &mockRuleStorage{rules: []Rule{
+		{ID: 1, MaxTraffic: 1000},
+		{ID: 2, DisconnectAfter: time.Hour},
+	}}
+	scheduler := newTestScheduler(storage, manager, &mockProposalRepository{})
+	scheduler.timeGetter = fixedTime("08:00")
+	scheduler.consumeSessionEvent(sessionEvent(connectionstate.SessionCreatedStatus, "s1"))
+
+	// when
+	scheduler.consumeStatisticsEvent(connectionstate.AppEventConnectionStatistics{
+		Stats:       connectionstate.Statistics{BytesSent: 400, BytesReceived: 500},
+		SessionInfo: connectionstate.Status{SessionID: "s1"},
+	})
+	scheduler.timeGetter = fixedTime("08:59")
+	scheduler.checkLimits()
+
+	// then
+	assert.Never(t, func() bool { return manager.disconnectCount() > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+
+	// when
+	scheduler.timeGetter = fixedTime("09:00")
+	scheduler.checkLimits()
+
+	// then
+	assert.Eventually(t, func() bool { return manager.disconnectCount() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestScheduler_ScheduledRuleLimitsApplyToItsConnectionOnly(t *testing.T) {
+	// given
+	manager := &mockConnectionManager{}
+	proposals := &mockProposalRepository{proposals: []proposal.PricedServiceProposal{{}}}
+	storage := &mockRuleStorage{rules: []Rule{
+		{ID: 1, ConsumerID: "0xc", ServiceType: "wireguard", ConnectAt: "08:30", MaxTraffic: 10},
+	}}
+	scheduler := newTestScheduler(storage, manager, proposals)
+	scheduler.consumeSessionEvent(sessionEvent(connectionstate.SessionCreatedStatus, "manual"))
+	scheduler.consumeStatisticsEvent(connectionstate.AppEventConnectionStatistics{
+		Stats:       connectionstate.Statistics{BytesSent: 100},
+		SessionInfo: connectionstate.Status{SessionID: "manual"},
+	})
+	scheduler.consumeSessionEvent(sessionEvent(connectionstate.SessionEndedStatus, "manual"))
+	assert.Never(t, func() bool { return manager.disconnectCount() > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+
+	// when
+	manager.onConnect = func() {
+		scheduler.consumeSessionEvent(sessionEvent(connectionstate.SessionCreatedStatus, "scheduled"))
+	}
+	scheduler.timeGetter = fixedTime("08:30")
+	scheduler.checkSchedule()
+	scheduler.consumeStatisticsEvent(connectionstate.AppEventConnectionStatistics{
+		Stats:       connectionstate.Statistics{BytesSent: 100},
+		SessionInfo: connectionstate.Status{SessionID: "scheduled"},
+	})
+
+	// then
+	assert.Eventually(t, func() bool { return manager.disconnectCount() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestScheduler_ReconnectsOnNetworkChange(t *testing.T) {
+	// given
+	manager := &mockConnectionManager{state: connectionstate.Connected}
+	storage := &mockRuleStorage{rules: []Rule{
+		{ID: 1, ReconnectOnNetworkChange: true, MaxSpend: big.NewInt(100)},
+	}}
+	scheduler := newTestScheduler(storage, manager, &mockProposalRepository{})
+	gateway := scheduler.gateway.(*mockGateway)
+	gateway.gw = net.ParseIP("192.168.1.1")
+	scheduler.consumeSessionEvent(sessionEvent(connectionstate.SessionCreatedStatus, "s1"))
+	scheduler.consumeInvoicePaidEvent(invoicePaidEvent("s1", 60))
+	manager.onReconnect = func() {
+		scheduler.consumeSessionEvent(sessionEvent(connectionstate.SessionEndedStatus, "s1"))
+		scheduler.consumeSessionEvent(sessionEvent(connectionstate.SessionCreatedStatus, "s2"))
+	}
+
+	// when
+	scheduler.checkNetwork()
+
+	// then
+	assert.Equal(t, 0, manager.reconnects)
+
+	// when
+	gateway.gw = net.ParseIP("10.0.0.1")
+	scheduler.checkNetwork()
+
+	// then
+	assert.Equal(t, 1, manager.reconnects)
+
+	// when
+	scheduler.consumeInvoicePaidEvent(invoicePaidEvent("s2", 50))
+
+	// then
+	assert.Eventually(t, func() bool { return manager.disconnectCount() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func newTestScheduler(storage ruleStorage, manager connectionManager, proposals proposalRepository) *Scheduler {
+	return NewScheduler(storage, manager, proposals, &mockHermesProvider{}, &mockGateway{}, time.Minute)
+}
+
+func fixedTime(clock string) func() time.Time {
+	t, _ := time.ParseInLocation("2006-01-02 15:04", "2021-06-01 "+clock, time.Local)
+	return func() time.Time {
+		return t
+	}
+}
+
+func sessionEvent(status, sessionID string) connectionstate.AppEventConnectionSession {
+	return connectionstate.AppEventConnectionSession{
+		Status:      status,
+		SessionInfo: connectionstate.Status{SessionID: session.ID(sessionID)},
+	}
+}
+
+func invoicePaidEvent(sessionID string, total int64) pingpong_event.AppEventInvoicePaid {
+	return pingpong_event.AppEventInvoicePaid{
+		SessionID: sessionID,
+		Invoice:   crypto.Invoice{AgreementTotal: big.NewInt(total)},
+	}
+}
+
+type mockRuleStorage struct {
+	rules []Rule
+}
+
+func (m *mockRuleStorage) List() ([]Rule, error) {
+	return m.rules, nil
+}
+
+type mockConnectionManager struct {
+	state       connectionstate.State
+	connects    int
+	reconnects  int
+	consumerID  identity.Identity
+	proposal    *proposal.PricedServiceProposal
+	onConnect   func()
+	onReconnect func()
+
+	mu          sync.Mutex
+	disconnects int
+}
+
+func (m *mockConnectionManager) Connect(consumerID identity.Identity, _ common.Address, lookup connection.ProposalLookup, _ connection.ConnectParams) error {
+	m.connects++
+	m.consumerID = consumerID
+	p, err := lookup()
+	if err != nil {
+		return err
+	}
+	m.proposal = p
+	m.state = connectionstate.Connected
+	if m.onConnect != nil {
+		m.onConnect()
+	}
+	return nil
+}
+
+func (m *mockConnectionManager) Status() connectionstate.Status {
+	if m.state == "" {
+		return connectionstate.Status{State: connectionstate.NotConnected}
+	}
+	return connectionstate.Status{State: m.state}
+}
+
+func (m *mockConnectionManager) Disconnect() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.disconnects++
+	return nil
+}
+
+func (m *mockConnectionManager) disconnectCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.disconnects
+}
+
+func (m *mockConnectionManager) Reconnect() {
+	m.reconnects++
+	if m.onReconnect != nil {
+		m.onReconnect()
+	}
+}
+
+type mockProposalRepository struct {
+	proposals []proposal.PricedServiceProposal
+	filter    *proposal.Filter
+}
+
+func (m *mockProposalRepository) Proposals(filter *proposal.Filter) ([]proposal.PricedServiceProposal, error) {
+	m.filter = filter
+	return m.proposals, nil
+}
+
+type mockHermesProvider struct{}
+
+func (m *mockHermesProvider) GetActiveHermes(_ int64) (common.Address, error) {
+	return common.HexToAddress("0x2"), nil
+}
+
+type mockGateway struct {
+	gw net.IP
+}
+
+func (m *mockGateway) DiscoverGateway() (net.IP, error) {
+	return m.gw, nil
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/asdine/storm/v3"
+)
+
+const bucketName = "connection-schedules"
+
+// ErrRuleNotFound is returned when rule with the given ID doesn't exist.
+var ErrRuleNotFound = errors.New("schedule rule not found")
+
+type persistentStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+// Storage keeps connection schedule rules.
+type Storage struct {
+	lock    sync.Mutex
+	storage persistentStorage
+}
+
+// NewStorage returns a new instance of connection schedule rules storage.
+func NewStorage(storage persistentStorage) *Storage {
+	return &Storage{
+		storage: storage,
+	}
+}
+
+// List returns all stored rules.
+func (s *Storage) List() ([]Rule, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var rules []Rule
+	err := s.storage.GetAllFrom(bucketName, &rules)
+	if errors.Is(err, storm.ErrNotFound) {
+		return []Rule{}, nil
+	}
+	return rules, err
+}
+
+// Get returns a rule by its ID.
+func (s *Storage) Get(id int) (Rule, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var rule Rule
+	err := s.storage.GetOneByField(bucketName, "ID", id, &rule)
+	if errors.Is(err, storm.ErrNotFound) {
+		return rule, ErrRuleNotFound
+	}
+	return rule, err
+}
+
+// Save creates a new rule or updates the existing one, ID of the new rule is set on the given rule.
+func (s *Storage) Save(rule *Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.storage.Store(bucketName, rule)
+}
+
+// Delete removes a rule by its ID.
+func (s *Storage) Delete(id int) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := s.storage.Delete(bucketName, &Rule{ID: id})
+	if errors.Is(err, storm.ErrNotFound) {
+		return ErrRuleNotFound
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+)
+
+func TestStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "connectionScheduleStorageTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	storage := NewStorage(bolt)
+
+	rules, err := storage.List()
+	assert.NoError(t, err)
+	assert.Len(t, rules, 0)
+
+	scheduled := Rule{ConsumerID: "0xc", ServiceType: "wireguard", CountryCode: "DE", ConnectAt: "08:30", DisconnectAfter: time.Hour}
+	assert.NoError(t, storage.Save(&scheduled))
+	guard := Rule{MaxSpend: big.NewInt(1000)}
+	assert.NoError(t, storage.Save(&guard))
+	assert.NotEqual(t, scheduled.ID, guard.ID)
+
+	assert.Error(t, storage.Save(&Rule{ConnectAt: "25:00"}))
+	assert.Error(t, storage.Save(&Rule{}))
+
+	rules, err = storage.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []Rule{scheduled, guard}, rules)
+
+	guard.MaxTraffic = 100
+	assert.NoError(t, storage.Save(&guard))
+	got, err := storage.Get(guard.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, guard, got)
+
+	assert.NoError(t, storage.Delete(scheduled.ID))
+	_, err = storage.Get(scheduled.ID)
+	assert.Equal(t, ErrRuleNotFound, err)
+	assert.Equal(t, ErrRuleNotFound, storage.Delete(scheduled.ID))
+}
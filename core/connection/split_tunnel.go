@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SplitTunnel defines which traffic is routed through the tunnel and which one goes directly.
+type SplitTunnel struct {
+	// IncludeCIDRs are the only networks routed through the tunnel, everything is routed if includes are empty
+	IncludeCIDRs []string
+	// IncludeDomains are the domains which addresses are routed through the tunnel
+	IncludeDomains []string
+	// ExcludeCIDRs are the networks bypassing the tunnel
+	ExcludeCIDRs []string
+	// ExcludeDomains are the domains which addresses bypass the tunnel
+	ExcludeDomains []string
+	// ExcludeApps are cgroup paths of applications bypassing the tunnel, supported on Linux only
+	ExcludeApps []string
+}
+
+// Enabled checks if any split tunneling rule is defined.
+func (st SplitTunnel) Enabled() bool {
+	return st.IncludesOnly() || len(st.ExcludeCIDRs) > 0 || len(st.ExcludeDomains) > 0 || len(st.ExcludeApps) > 0
+}
+
+// IncludesOnly checks if just included traffic should be routed through the tunnel.
+func (st SplitTunnel) IncludesOnly() bool {
+	return len(st.IncludeCIDRs) > 0 || len(st.IncludeDomains) > 0
+}
+
+// Validate checks if split tunneling rules are well formed.
+func (st SplitTunnel) Validate() error {
+	for _, cidr := range append(st.IncludeCIDRs, st.ExcludeCIDRs...) {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid split tunnel CIDR %q: %w", cidr, err)
+		}
+	}
+	for _, domain := range append(st.IncludeDomains, st.ExcludeDomains...) {
+		if domain == "" || strings.ContainsAny(domain, "/: ") {
+			return fmt.Errorf("invalid split tunnel domain %q", domain)
+		}
+	}
+	for _, app := range st.ExcludeApps {
+		if app == "" {
+			return fmt.Errorf("empty split tunnel application cgroup")
+		}
+	}
+	return nil
+}
+
+// includedNetworks returns the included networks with the current addresses of the included domains.
+func (st SplitTunnel) includedNetworks(lookupIP func(host string) ([]net.IP, error)) []string {
+	networks := append([]string(nil), st.IncludeCIDRs...)
+	for _, domain := range st.IncludeDomains {
+		ips, err := lookupIP(domain)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to resolve split tunnel included domain %s", domain)
+			continue
+		}
+		for _, ip := range ips {
+			if ip4 := ip.To4(); ip4 != nil {
+				networks = append(networks, (&net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}).String())
+			} else {
+				networks = append(networks, (&net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}).String())
+			}
+		}
+	}
+	return networks
+}
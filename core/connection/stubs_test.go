@@ -79,6 +79,7 @@ func (c *connectionFactoryFake) CreateConnection(serviceType string) (Connection
 		onStartReportStats:  c.mockConnection.onStartReportStats,
 		fakeProcess:         sync.WaitGroup{},
 		stopBlock:           c.mockConnection.stopBlock,
+		providerDNS:         c.mockConnection.providerDNS,
 	}
 
 	return &copy, nil
@@ -93,6 +94,8 @@ type connectionMock struct {
 	onStartReportStats  connectionstate.Statistics
 	fakeProcess         sync.WaitGroup
 	stopBlock           chan struct{}
+	providerDNS         string
+	dnsServers          []string
 	sync.RWMutex
 }
 
@@ -108,6 +111,19 @@ func (c *connectionMock) GetConfig() (ConsumerConfig, error) {
 	return nil, nil
 }
 
+func (c *connectionMock) DNSServers() []string {
+	return c.dnsServers
+}
+
+func (c *connectionMock) ProviderDNSServers() []string {
+	servers, _ := SelectProviderDNS(c.providerDNS)
+	return servers
+}
+
+func (c *connectionMock) InterfaceName() string {
+	return "fake-iface"
+}
+
 func (c *connectionMock) Reconnect(ctx context.Context, connectionParams ConnectOptions) error {
 	return c.Start(ctx, connectionParams)
 }
@@ -120,6 +136,12 @@ func (c *connectionMock) Start(ctx context.Context, connectionParams ConnectOpti
 		return c.onStartReturnError
 	}
 
+	dnsServers, err := connectionParams.Params.DNS.ResolveIPs(c.providerDNS)
+	if err != nil {
+		return err
+	}
+	c.dnsServers = dnsServers
+
 	c.fakeProcess.Add(1)
 	for _, fakeState := range c.onStartReportStates {
 		c.reportState(fakeState)
@@ -19,19 +19,106 @@ package apidiscovery
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/market/mysterium"
 )
 
+type discoveryAPI interface {
+	QueryProposals(query mysterium.ProposalsQuery) ([]market.ServiceProposal, error)
+	QueryCountries(query mysterium.ProposalsQuery) (map[string]int, error)
+}
+
 type apiRepository struct {
-	discoveryAPI *mysterium.MysteriumAPI
+	discoveryAPI   discoveryAPI
+	eventPublisher eventbus.Publisher
+	fetchInterval  time.Duration
+
+	known    map[market.ProposalID]market.ServiceProposal
+	stopOnce sync.Once
+	stopChan chan struct{}
 }
 
 // NewRepository constructs a new proposal repository (backed by API).
-func NewRepository(api *mysterium.MysteriumAPI) *apiRepository {
-	return &apiRepository{discoveryAPI: api}
+// Once started, it polls the API and publishes proposal changes to the event bus.
+func NewRepository(api discoveryAPI, eventPublisher eventbus.Publisher, fetchInterval time.Duration) *apiRepository {
+	return &apiRepository{
+		discoveryAPI:   api,
+		eventPublisher: eventPublisher,
+		fetchInterval:  fetchInterval,
+		stopChan:       make(chan struct{}),
+	}
+}
+
+// Start begins publishing proposal changes.
+func (a *apiRepository) Start() error {
+	go a.fetchLoop()
+
+	return nil
+}
+
+// Stop ends publishing proposal changes.
+func (a *apiRepository) Stop() {
+	a.stopOnce.Do(func() {
+		close(a.stopChan)
+	})
+}
+
+func (a *apiRepository) fetchLoop() {
+	for {
+		a.fetch()
+
+		select {
+		case <-a.stopChan:
+			return
+		case <-time.After(a.fetchInterval):
+		}
+	}
+}
+
+// fetch publishes proposals which were added, changed or removed since the previous fetch.
+// The first fetch only remembers proposals, as they are not changes.
+func (a *apiRepository) fetch() {
+	proposals, err := a.discoveryAPI.QueryProposals(mysterium.ProposalsQuery{
+		AccessPolicy:            "all",
+		IncludeMonitoringFailed: true,
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to fetch proposal changes")
+		return
+	}
+
+	current := make(map[market.ProposalID]market.ServiceProposal, len(proposals))
+	for _, p := range proposals {
+		current[p.UniqueID()] = p
+	}
+	if a.known == nil {
+		a.known = current
+		return
+	}
+
+	for id, p := range current {
+		previous, ok := a.known[id]
+		switch {
+		case !ok:
+			a.eventPublisher.Publish(discovery.AppTopicProposalAdded, p)
+		case !discovery.StableFieldsEqual(previous, p):
+			a.eventPublisher.Publish(discovery.AppTopicProposalUpdated, p)
+		}
+	}
+	for id, p := range a.known {
+		if _, ok := current[id]; !ok {
+			a.eventPublisher.Publish(discovery.AppTopicProposalRemoved, p)
+		}
+	}
+	a.known = current
 }
 
 // Proposal returns proposal by ID.
@@ -0,0 +1,207 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const (
+	cacheBucketName = "proposal-cache"
+	// cacheRetention is how long proposals are kept since they were seen last time.
+	cacheRetention = 7 * 24 * time.Hour
+	// cacheWriteInterval limits how often unchanged proposal is persisted again only to update its seen time.
+	cacheWriteInterval = 10 * time.Minute
+	// cacheRefreshInterval is how often proposals of discovery are stored into the cache.
+	cacheRefreshInterval = time.Minute
+)
+
+type cacheStorage interface {
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+	DB() *storm.DB
+	Lock()
+	Unlock()
+}
+
+type cachedProposal struct {
+	Key      string `storm:"id"`
+	Proposal market.ServiceProposal
+	SeenAt   time.Time
+}
+
+// ProposalCache keeps last seen proposals in persistent storage,
+// so that they are available while discovery is unreachable, e.g. after restart.
+type ProposalCache struct {
+	storage cacheStorage
+
+	mu        sync.RWMutex
+	proposals map[market.ProposalID]cachedProposal
+	storedAt  map[market.ProposalID]time.Time
+}
+
+// NewProposalCache creates proposal cache loaded from the storage.
+func NewProposalCache(storage cacheStorage) *ProposalCache {
+	cache := &ProposalCache{
+		storage:   storage,
+		proposals: make(map[market.ProposalID]cachedProposal),
+		storedAt:  make(map[market.ProposalID]time.Time),
+	}
+
+	var entries []cachedProposal
+	if err := storage.GetAllFrom(cacheBucketName, &entries); err != nil {
+		log.Warn().Err(err).Msg("Failed to load proposal cache")
+	}
+
+	expired := time.Now().Add(-cacheRetention)
+	for i := range entries {
+		entry := entries[i]
+		if entry.SeenAt.Before(expired) {
+			if err := storage.Delete(cacheBucketName, &entry); err != nil {
+				log.Warn().Err(err).Msgf("Failed to remove expired proposal %s from cache", entry.Key)
+			}
+			continue
+		}
+
+		id := entry.Proposal.UniqueID()
+		cache.proposals[id] = entry
+		cache.storedAt[id] = entry.SeenAt
+	}
+
+	return cache
+}
+
+// Store remembers proposals as seen now. Changed proposals are persisted in a single transaction.
+func (pc *ProposalCache) Store(proposals ...market.ServiceProposal) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	now := time.Now()
+	changed := make([]cachedProposal, 0)
+	for _, p := range proposals {
+		id := p.UniqueID()
+		p.CachedAt = time.Time{}
+
+		previous, known := pc.proposals[id]
+		entry := cachedProposal{Key: cacheKey(id), Proposal: p, SeenAt: now}
+		pc.proposals[id] = entry
+
+		if known && StableFieldsEqual(previous.Proposal, p) && now.Sub(pc.storedAt[id]) < cacheWriteInterval {
+			continue
+		}
+		changed = append(changed, entry)
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	if err := pc.persist(changed); err != nil {
+		log.Warn().Err(err).Msgf("Failed to cache %d proposals", len(changed))
+		return
+	}
+	for _, entry := range changed {
+		pc.storedAt[entry.Proposal.UniqueID()] = now
+	}
+}
+
+func (pc *ProposalCache) persist(entries []cachedProposal) error {
+	pc.storage.Lock()
+	defer pc.storage.Unlock()
+
+	tx, err := pc.storage.DB().From(cacheBucketName).Begin(true)
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if err := tx.Save(&entries[i]); err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Error().Err(rollbackErr).Msg("Failed to rollback proposal cache update")
+			}
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Proposal returns a single cached proposal by its ID.
+func (pc *ProposalCache) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	entry, ok := pc.proposals[id]
+	if !ok {
+		return nil, fmt.Errorf("proposal is not cached: %v", id)
+	}
+
+	p := entry.stale()
+	return &p, nil
+}
+
+// Proposals returns cached proposals matching the filter.
+func (pc *ProposalCache) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	proposals := make([]market.ServiceProposal, 0)
+	for _, entry := range pc.proposals {
+		if filter.Matches(entry.Proposal) {
+			proposals = append(proposals, entry.stale())
+		}
+	}
+	return proposals, nil
+}
+
+// Countries returns count of cached proposals per country matching the filter.
+func (pc *ProposalCache) Countries(filter *proposal.Filter) (map[string]int, error) {
+	proposals, err := pc.Proposals(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	countries := make(map[string]int)
+	for _, p := range proposals {
+		countries[p.Location.Country]++
+	}
+	return countries, nil
+}
+
+func (cp cachedProposal) stale() market.ServiceProposal {
+	p := cp.Proposal
+	p.CachedAt = cp.SeenAt
+	return p
+}
+
+// StableFieldsEqual compares proposals ignoring the fields which change frequently, e.g. quality and capacity.
+func StableFieldsEqual(a, b market.ServiceProposal) bool {
+	a.Quality, b.Quality = market.Quality{}, market.Quality{}
+	a.Capacity, b.Capacity = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+func cacheKey(id market.ProposalID) string {
+	return id.ProviderID + "/" + id.ServiceType
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func TestProposalCache_ServesStaleProposalsWhenDelegatesFail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "proposalCacheTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	delegate := &mockRepository{proposalsToReturn: []market.ServiceProposal{mockProposal}}
+	repo := NewRepository()
+	repo.Add(delegate)
+	repo.SetCache(NewProposalCache(bolt))
+
+	proposals, err := repo.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, proposals, 1)
+	assert.True(t, proposals[0].CachedAt.IsZero())
+	repo.refreshCache()
+
+	// Cache is reloaded from the storage as after restart.
+	delegate = &mockRepository{errToReturn: errors.New("discovery is unreachable")}
+	repo = NewRepository()
+	repo.Add(delegate)
+	repo.SetCache(NewProposalCache(bolt))
+
+	proposals, err = repo.Proposals(&proposal.Filter{ServiceType: mockProposal.ServiceType})
+	assert.NoError(t, err)
+	assert.Len(t, proposals, 1)
+	assert.Equal(t, mockProposal.UniqueID(), proposals[0].UniqueID())
+	assert.False(t, proposals[0].CachedAt.IsZero())
+
+	proposals, err = repo.Proposals(&proposal.Filter{ServiceType: "other"})
+	assert.NoError(t, err)
+	assert.Len(t, proposals, 0)
+
+	cached, err := repo.Proposal(mockProposal.UniqueID())
+	assert.NoError(t, err)
+	assert.Equal(t, mockProposal.Location, cached.Location)
+	assert.False(t, cached.CachedAt.IsZero())
+
+	countries, err := repo.Countries(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{mockProposal.Location.Country: 1}, countries)
+}
+
+func TestProposalCache_ComparesStableFieldsOnly(t *testing.T) {
+	changedQuality := mockProposal
+	changedQuality.Quality = market.Quality{Quality: mockProposal.Quality.Quality + 1}
+	assert.True(t, StableFieldsEqual(mockProposal, changedQuality))
+
+	moved := mockProposal
+	moved.Location.Country = "other"
+	assert.False(t, StableFieldsEqual(mockProposal, moved))
+}
@@ -19,13 +19,46 @@ package dhtdiscovery
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	dhtopts "github.com/libp2p/go-libp2p-kad-dht/opts"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery/record"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const (
+	// protocolID keeps Mysterium DHT separate from the public IPFS DHT.
+	protocolID = protocol.ID("/mysterium/kad/1.0.0")
+
+	// shardCount is the count of DHT keys proposal providers are announced under,
+	// so that all the providers are not announced to the same closest peers.
+	shardCount = 16
+	// maxProvidersPerShard limits the count of peers records are fetched from per shard.
+	maxProvidersPerShard = 1000
+
+	// maxRecordsPerPeer limits the count of records in a single DHT value.
+	maxRecordsPerPeer = 100
+	// maxValueSize limits the size of a single DHT value.
+	maxValueSize = 1 << 20
+	// maxStoredRecords limits the count of records collected by a single fetch.
+	maxStoredRecords = 100000
+
+	pruneInterval = time.Minute
+	// tombstoneRetention is how long expired records are kept, so that unregistrations override stale replicas.
+	tombstoneRetention = 10 * time.Minute
 )
 
 // Node represents DHT server-client in P2P network.
@@ -34,14 +67,19 @@ type Node struct {
 	libP2PNode       host.Host
 	libP2PNodeCtx    context.Context
 	libP2PNodeCancel context.CancelFunc
+	dht              *dht.IpfsDHT
 
-	bootstrapPeers []*peer.AddrInfo
+	bootstrapPeers  []*peer.AddrInfo
+	verifierFactory identity.VerifierFactory
+	published       *recordStore
 }
 
 // NewNode create an instance of DHT node.
-func NewNode(listenAddress string, bootstrapPeerAddresses []string) (*Node, error) {
+func NewNode(listenAddress string, bootstrapPeerAddresses []string, verifierFactory identity.VerifierFactory) (*Node, error) {
 	node := &Node{
-		bootstrapPeers: make([]*peer.AddrInfo, len(bootstrapPeerAddresses)),
+		bootstrapPeers:  make([]*peer.AddrInfo, len(bootstrapPeerAddresses)),
+		verifierFactory: verifierFactory,
+		published:       newRecordStore(maxRecordsPerPeer),
 	}
 
 	// Parse and validate configuration
@@ -83,12 +121,21 @@ func (n *Node) Start() (err error) {
 		return fmt.Errorf("failed to start DHT node: %w", err)
 	}
 
+	n.dht, err = dht.New(
+		n.libP2PNodeCtx,
+		n.libP2PNode,
+		dhtopts.Protocols(protocolID),
+		dhtopts.NamespacedValidator(recordNamespace, newRecordValidator(n.verifierFactory)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start DHT: %w", err)
+	}
+
 	log.Info().Msgf("DHT node started on %s with ID=%s", n.libP2PNode.Addrs(), n.libP2PNode.ID())
 
 	// Start connecting to the bootstrap peer nodes early. They will tell us about the other nodes in the network.
-	for _, peerInfo := range n.bootstrapPeers {
-		go n.connectToPeer(*peerInfo)
-	}
+	go n.bootstrap()
+	go n.pruneRecords()
 
 	return nil
 }
@@ -96,6 +143,44 @@ func (n *Node) Start() (err error) {
 // Stop stops DHT node.
 func (n *Node) Stop() {
 	n.libP2PNodeCancel()
+
+	if n.dht != nil {
+		if err := n.dht.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to stop DHT")
+		}
+	}
+
+	if n.libP2PNode != nil {
+		if err := n.libP2PNode.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to stop DHT node")
+		}
+	}
+}
+
+// Addresses returns addresses which other nodes can use to bootstrap from this node.
+func (n *Node) Addresses() []string {
+	addresses := make([]string, 0)
+	for _, addr := range n.libP2PNode.Addrs() {
+		addresses = append(addresses, fmt.Sprintf("%s/p2p/%s", addr, n.libP2PNode.ID().Pretty()))
+	}
+	return addresses
+}
+
+func (n *Node) bootstrap() {
+	var wg sync.WaitGroup
+	for _, peerInfo := range n.bootstrapPeers {
+		wg.Add(1)
+		go func(peerInfo peer.AddrInfo) {
+			defer wg.Done()
+			n.connectToPeer(peerInfo)
+		}(*peerInfo)
+	}
+	wg.Wait()
+
+	// Refreshing the routing table fills it with the closest peers.
+	if err := n.dht.Bootstrap(n.libP2PNodeCtx); err != nil {
+		log.Warn().Err(err).Msg("Failed to bootstrap DHT")
+	}
 }
 
 func (n *Node) connectToPeer(peerInfo peer.AddrInfo) {
@@ -107,3 +192,121 @@ func (n *Node) connectToPeer(peerInfo peer.AddrInfo) {
 
 	log.Info().Msgf("Connection established with DHT peer: %v", peerInfo)
 }
+
+func (n *Node) pruneRecords() {
+	for {
+		select {
+		case <-n.libP2PNodeCtx.Done():
+			return
+		case <-time.After(pruneInterval):
+			n.published.prune(time.Now().Add(-tombstoneRetention))
+		}
+	}
+}
+
+// publish stores records of the node under its peer key and announces the node as the provider of the shard.
+func (n *Node) publish(ctx context.Context, r record.Record) error {
+	proposal, err := r.Verify(n.verifierFactory)
+	if err != nil {
+		return err
+	}
+	if !n.published.put(r, proposal) {
+		return errors.New("proposal record is outdated or too many proposals are published")
+	}
+
+	value, err := json.Marshal(n.published.all())
+	if err != nil {
+		return fmt.Errorf("failed to marshal proposal records: %w", err)
+	}
+
+	if n.dht.RoutingTable().Size() == 0 {
+		log.Debug().Msg("No DHT peers found, proposal record is stored locally only")
+		return nil
+	}
+
+	if err := n.dht.PutValue(ctx, peerRecordsKey(n.libP2PNode.ID()), value); err != nil {
+		return fmt.Errorf("failed to store proposal records in DHT: %w", err)
+	}
+
+	shard, err := shardCID(providerShard(proposal.ProviderID))
+	if err != nil {
+		return err
+	}
+	if err := n.dht.Provide(ctx, shard, true); err != nil {
+		return fmt.Errorf("failed to announce proposal records in DHT: %w", err)
+	}
+	return nil
+}
+
+// fetch collects proposal records of every shard provider and returns active proposals.
+func (n *Node) fetch(ctx context.Context) []market.ServiceProposal {
+	merged := newRecordStore(maxStoredRecords)
+	for _, r := range n.published.all() {
+		n.storeRecord(merged, r)
+	}
+
+	var mu sync.Mutex
+	fetched := make(map[peer.ID]bool)
+
+	var wg sync.WaitGroup
+	for shard := 0; shard < shardCount; shard++ {
+		key, err := shardCID(shard)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to fetch proposal records of shard %d", shard)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for info := range n.dht.FindProvidersAsync(ctx, key, maxProvidersPerShard) {
+				mu.Lock()
+				skip := fetched[info.ID] || info.ID == n.libP2PNode.ID()
+				fetched[info.ID] = true
+				mu.Unlock()
+				if skip {
+					continue
+				}
+
+				wg.Add(1)
+				go func(id peer.ID) {
+					defer wg.Done()
+					n.fetchPeer(ctx, id, merged)
+				}(info.ID)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return merged.proposals(time.Now())
+}
+
+// fetchPeer collects proposal records published by the peer.
+func (n *Node) fetchPeer(ctx context.Context, id peer.ID, store *recordStore) {
+	value, err := n.dht.GetValue(ctx, peerRecordsKey(id))
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to fetch proposal records of DHT peer %s", id)
+		return
+	}
+
+	var records []record.Record
+	if err := json.Unmarshal(value, &records); err != nil {
+		log.Debug().Err(err).Msgf("Failed to parse proposal records of DHT peer %s", id)
+		return
+	}
+	for _, r := range records {
+		if _, err := n.storeRecord(store, r); err != nil {
+			log.Debug().Err(err).Msgf("Ignoring proposal record of DHT peer %s", id)
+		}
+	}
+}
+
+func (n *Node) storeRecord(store *recordStore, r record.Record) (market.ServiceProposal, error) {
+	proposal, err := r.Verify(n.verifierFactory)
+	if err != nil {
+		return proposal, err
+	}
+
+	store.put(r, proposal)
+	return proposal, nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dhtdiscovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+func startTestNodes(t *testing.T, count int) []*Node {
+	nodes := make([]*Node, count)
+	var bootstrapPeers []string
+	for i := range nodes {
+		node, err := NewNode("/ip4/127.0.0.1/tcp/0", bootstrapPeers, fakeVerifier)
+		assert.NoError(t, err)
+		assert.NoError(t, node.Start())
+		t.Cleanup(node.Stop)
+
+		if i == 0 {
+			bootstrapPeers = node.Addresses()
+		}
+		nodes[i] = node
+	}
+	return nodes
+}
+
+func Test_Node_PublishesProposalsToConsumers(t *testing.T) {
+	nodes := startTestNodes(t, 4)
+	provider, consumer := nodes[3], nodes[2]
+
+	registry := NewRegistry(provider, time.Minute)
+	repository := NewRepository(consumer, brokerdiscovery.NewStorage(eventbus.New()), 10*time.Millisecond)
+	assert.NoError(t, repository.Start())
+	defer repository.Stop()
+
+	assert.Eventually(t, func() bool {
+		return consumer.dht.RoutingTable().Size() == len(nodes)-1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, registry.RegisterProposal(testProposal, &identity.SignerFake{}))
+	assert.Eventually(t, func() bool {
+		proposals, _ := repository.Proposals(&proposal.Filter{ServiceType: "wireguard"})
+		return len(proposals) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	countries, err := repository.Countries(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"LT": 1}, countries)
+
+	// Proposal stays available while the provider is gone.
+	provider.Stop()
+	found, err := repository.Proposal(testProposal.UniqueID())
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", found.ProviderID)
+}
+
+func Test_Node_UnregistersProposal(t *testing.T) {
+	nodes := startTestNodes(t, 3)
+	provider, consumer := nodes[1], nodes[2]
+	registry := NewRegistry(provider, time.Minute)
+
+	assert.Eventually(t, func() bool {
+		return provider.dht.RoutingTable().Size() == len(nodes)-1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, registry.RegisterProposal(testProposal, &identity.SignerFake{}))
+	assert.Len(t, consumer.fetch(consumer.libP2PNodeCtx), 1)
+
+	assert.NoError(t, registry.UnregisterProposal(testProposal, &identity.SignerFake{}))
+	assert.Len(t, consumer.fetch(consumer.libP2PNodeCtx), 0)
+}
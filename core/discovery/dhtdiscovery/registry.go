@@ -18,29 +18,54 @@
 package dhtdiscovery
 
 import (
+	"context"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/record"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 )
 
+// publishTimeout limits a single proposal announcement into the DHT.
+const publishTimeout = 30 * time.Second
+
 type registryDHT struct {
+	node *Node
+	ttl  time.Duration
 }
 
-// NewRegistry create an instance of Broker registryBroker.
-func NewRegistry() *registryDHT {
-	return &registryDHT{}
+// NewRegistry create an instance of DHT registryDHT.
+// Proposal records expire after the given TTL unless they are refreshed by pings.
+func NewRegistry(node *Node, ttl time.Duration) *registryDHT {
+	return &registryDHT{
+		node: node,
+		ttl:  ttl,
+	}
 }
 
 // RegisterProposal registers service proposal to discovery service.
 func (rd *registryDHT) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	return nil
+	return rd.publish(proposal, rd.ttl, signer)
 }
 
 // UnregisterProposal unregisters a service proposal when client disconnects.
 func (rd *registryDHT) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	return nil
+	return rd.publish(proposal, 0, signer)
 }
 
 // PingProposal pings service proposal as being alive.
 func (rd *registryDHT) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	return nil
+	return rd.publish(proposal, rd.ttl, signer)
+}
+
+func (rd *registryDHT) publish(proposal market.ServiceProposal, ttl time.Duration, signer identity.Signer) error {
+	r, err := record.New(proposal, ttl, signer)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	return rd.node.publish(ctx, r)
 }
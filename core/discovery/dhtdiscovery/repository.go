@@ -18,42 +18,54 @@
 package dhtdiscovery
 
 import (
+	"context"
 	"sync"
+	"time"
 
+	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/market"
 )
 
 // Repository provides proposals from the DHT.
 type Repository struct {
+	node          *Node
+	storage       *brokerdiscovery.ProposalStorage
+	fetchInterval time.Duration
+
 	stopOnce sync.Once
 	stopChan chan struct{}
 }
 
 // NewRepository constructs a new proposal repository (backed by the DHT).
-func NewRepository() *Repository {
+func NewRepository(node *Node, storage *brokerdiscovery.ProposalStorage, fetchInterval time.Duration) *Repository {
 	return &Repository{
-		stopChan: make(chan struct{}),
+		node:          node,
+		storage:       storage,
+		fetchInterval: fetchInterval,
+		stopChan:      make(chan struct{}),
 	}
 }
 
 // Proposal returns a single proposal by its ID.
 func (r *Repository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
-	return nil, nil
+	return r.storage.GetProposal(id)
 }
 
 // Proposals returns proposals matching the filter.
 func (r *Repository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
-	return []market.ServiceProposal{}, nil
+	return r.storage.FindProposals(filter)
 }
 
 // Countries returns proposals per country matching the filter.
 func (r *Repository) Countries(filter *proposal.Filter) (map[string]int, error) {
-	return nil, nil
+	return r.storage.Countries(filter)
 }
 
 // Start begins proposals synchronization to storage.
 func (r *Repository) Start() error {
+	go r.fetchLoop()
+
 	return nil
 }
 
@@ -63,3 +75,35 @@ func (r *Repository) Stop() {
 		close(r.stopChan)
 	})
 }
+
+func (r *Repository) fetchLoop() {
+	for {
+		r.fetch()
+
+		select {
+		case <-r.stopChan:
+			return
+		case <-time.After(r.fetchInterval):
+		}
+	}
+}
+
+func (r *Repository) fetch() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	proposals := make([]market.ServiceProposal, 0)
+	for _, p := range r.node.fetch(ctx) {
+		if p.IsSupported() {
+			proposals = append(proposals, p)
+		}
+	}
+	r.storage.Set(proposals)
+}
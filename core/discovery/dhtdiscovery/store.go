@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dhtdiscovery
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/multiformats/go-multihash"
+
+	"github.com/mysteriumnetwork/node/core/discovery/record"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// recordNamespace is the DHT key namespace proposal records are stored under.
+const recordNamespace = "myst"
+
+// peerRecordsKey returns the DHT key the proposal records published by the given peer are stored under.
+func peerRecordsKey(id peer.ID) string {
+	return fmt.Sprintf("/%s/%s", recordNamespace, id.Pretty())
+}
+
+// shardCID returns the DHT key the providers of proposal records of the given shard are announced under.
+func shardCID(shard int) (cid.Cid, error) {
+	hash, err := multihash.Sum([]byte(fmt.Sprintf("/mysterium/proposals/%d", shard)), multihash.SHA2_256, -1)
+	if err != nil {
+		return cid.Undef, fmt.Errorf("failed to hash proposal shard key: %w", err)
+	}
+	return cid.NewCidV1(cid.Raw, hash), nil
+}
+
+// providerShard returns the shard keeping proposal records of the given provider.
+func providerShard(providerID string) int {
+	sum := sha256.Sum256([]byte(providerID))
+	return int(sum[0]) % shardCount
+}
+
+type storedRecord struct {
+	record   record.Record
+	proposal market.ServiceProposal
+}
+
+// recordStore keeps the newest record of each proposal.
+type recordStore struct {
+	mu       sync.Mutex
+	records  map[market.ProposalID]storedRecord
+	capacity int
+}
+
+func newRecordStore(capacity int) *recordStore {
+	return &recordStore{
+		records:  make(map[market.ProposalID]storedRecord),
+		capacity: capacity,
+	}
+}
+
+// put stores the record unless newer record of the same proposal is already stored or the store is full.
+func (s *recordStore) put(r record.Record, proposal market.ServiceProposal) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := proposal.UniqueID()
+	existing, ok := s.records[id]
+	if ok && existing.record.IssuedAt >= r.IssuedAt {
+		return false
+	}
+	if !ok && len(s.records) >= s.capacity {
+		return false
+	}
+
+	s.records[id] = storedRecord{record: r, proposal: proposal}
+	return true
+}
+
+// all returns all stored records including expired ones, so that unregistrations are propagated.
+func (s *recordStore) all() []record.Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]record.Record, 0, len(s.records))
+	for _, stored := range s.records {
+		records = append(records, stored.record)
+	}
+	return records
+}
+
+// proposals returns proposals of the records which are not expired.
+func (s *recordStore) proposals(now time.Time) []market.ServiceProposal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proposals := make([]market.ServiceProposal, 0, len(s.records))
+	for _, stored := range s.records {
+		if !stored.record.Expired(now) {
+			proposals = append(proposals, stored.proposal)
+		}
+	}
+	return proposals
+}
+
+// prune removes records which expired before the given time.
+func (s *recordStore) prune(before time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, stored := range s.records {
+		if stored.record.Expired(before) {
+			delete(s.records, id)
+		}
+	}
+}
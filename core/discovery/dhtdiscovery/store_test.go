@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dhtdiscovery
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/record"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+var testProposal = market.ServiceProposal{
+	ProviderID:  "0x1",
+	ServiceType: "wireguard",
+	Location:    market.Location{Country: "LT"},
+	Contacts:    market.ContactList{{Type: "test_contact"}},
+}
+
+type testContact struct{}
+
+func init() {
+	// Repository only serves proposals of the known services and contacts.
+	market.RegisterServiceType("wireguard")
+	market.RegisterContactUnserializer("test_contact", func(*json.RawMessage) (market.ContactDefinition, error) {
+		return testContact{}, nil
+	})
+}
+
+func fakeVerifier(_ identity.Identity) identity.Verifier {
+	return &identity.VerifierFake{}
+}
+
+func Test_RecordStore_KeepsNewestRecord(t *testing.T) {
+	store := newRecordStore(10)
+
+	registered, err := record.New(testProposal, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+	unregistered, err := record.New(testProposal, 0, &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	assert.True(t, store.put(registered, testProposal))
+	assert.Len(t, store.proposals(time.Now()), 1)
+
+	assert.True(t, store.put(unregistered, testProposal))
+	assert.False(t, store.put(registered, testProposal))
+	assert.Len(t, store.proposals(time.Now()), 0)
+	assert.Len(t, store.all(), 1)
+
+	store.prune(time.Now())
+	assert.Len(t, store.all(), 0)
+}
+
+func Test_RecordStore_LimitsCapacity(t *testing.T) {
+	store := newRecordStore(1)
+
+	r, err := record.New(testProposal, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+	assert.True(t, store.put(r, testProposal))
+
+	other := testProposal
+	other.ProviderID = "0x2"
+	r, err = record.New(other, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+	assert.False(t, store.put(r, other))
+}
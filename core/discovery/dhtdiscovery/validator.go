@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dhtdiscovery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	p2precord "github.com/libp2p/go-libp2p-record"
+
+	"github.com/mysteriumnetwork/node/core/discovery/record"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// recordValidator accepts DHT values holding proposal records signed by their providers,
+// so that peers don't store or serve forged proposals.
+type recordValidator struct {
+	verifierFactory identity.VerifierFactory
+}
+
+var _ p2precord.Validator = (*recordValidator)(nil)
+
+func newRecordValidator(verifierFactory identity.VerifierFactory) *recordValidator {
+	return &recordValidator{verifierFactory: verifierFactory}
+}
+
+// Validate checks the value is a list of valid proposal records stored under the peer key.
+func (v *recordValidator) Validate(key string, value []byte) error {
+	namespace, path, err := p2precord.SplitKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != recordNamespace {
+		return fmt.Errorf("unexpected proposal records namespace: %s", namespace)
+	}
+	if _, err := peer.IDB58Decode(path); err != nil {
+		return fmt.Errorf("invalid proposal records peer: %w", err)
+	}
+
+	_, err = v.records(value)
+	return err
+}
+
+// Select picks the value with the most recently issued proposal record.
+func (v *recordValidator) Select(_ string, values [][]byte) (int, error) {
+	best := -1
+	var bestIssuedAt int64
+	for i, value := range values {
+		records, err := v.records(value)
+		if err != nil {
+			continue
+		}
+
+		var issuedAt int64
+		for _, r := range records {
+			if r.IssuedAt > issuedAt {
+				issuedAt = r.IssuedAt
+			}
+		}
+		if best == -1 || issuedAt > bestIssuedAt {
+			best, bestIssuedAt = i, issuedAt
+		}
+	}
+
+	if best == -1 {
+		return 0, errors.New("no valid proposal records")
+	}
+	return best, nil
+}
+
+func (v *recordValidator) records(value []byte) ([]record.Record, error) {
+	if len(value) > maxValueSize {
+		return nil, fmt.Errorf("proposal records are too large: %d bytes", len(value))
+	}
+
+	var records []record.Record
+	if err := json.Unmarshal(value, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse proposal records: %w", err)
+	}
+	if len(records) > maxRecordsPerPeer {
+		return nil, fmt.Errorf("too many proposal records: %d", len(records))
+	}
+	for _, r := range records {
+		if _, err := r.Verify(v.verifierFactory); err != nil {
+			return nil, fmt.Errorf("invalid proposal record: %w", err)
+		}
+	}
+	return records, nil
+}
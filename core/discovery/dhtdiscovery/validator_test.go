@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dhtdiscovery
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/record"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+var testPeerID, _ = peer.IDB58Decode("QmYyQSo1c1Ym7orWxLYvCrM2EmxFTANf8wXmmE7DWjhx5N")
+
+func marshalRecords(t *testing.T, records ...record.Record) []byte {
+	value, err := json.Marshal(records)
+	assert.NoError(t, err)
+	return value
+}
+
+func Test_RecordValidator_AcceptsSignedRecords(t *testing.T) {
+	validator := newRecordValidator(fakeVerifier)
+
+	valid, err := record.New(testProposal, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, validator.Validate(peerRecordsKey(testPeerID), marshalRecords(t, valid)))
+	assert.Error(t, validator.Validate("/pk/"+testPeerID.Pretty(), marshalRecords(t, valid)))
+	assert.Error(t, validator.Validate("/myst/not-a-peer", marshalRecords(t, valid)))
+}
+
+func Test_RecordValidator_RejectsForgedRecords(t *testing.T) {
+	validator := newRecordValidator(fakeVerifier)
+
+	forged, err := record.New(market.ServiceProposal{ProviderID: "0x2", ServiceType: "wireguard"}, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+	forged.Signature = "forged"
+	valid, err := record.New(testProposal, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	assert.Error(t, validator.Validate(peerRecordsKey(testPeerID), marshalRecords(t, forged, valid)))
+}
+
+func Test_RecordValidator_RejectsTooManyRecords(t *testing.T) {
+	validator := newRecordValidator(fakeVerifier)
+
+	valid, err := record.New(testProposal, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+	records := make([]record.Record, maxRecordsPerPeer+1)
+	for i := range records {
+		records[i] = valid
+	}
+
+	assert.Error(t, validator.Validate(peerRecordsKey(testPeerID), marshalRecords(t, records...)))
+}
+
+func Test_RecordValidator_SelectsNewestRecords(t *testing.T) {
+	validator := newRecordValidator(fakeVerifier)
+
+	registered, err := record.New(testProposal, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	unregistered, err := record.New(testProposal, 0, &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	best, err := validator.Select(peerRecordsKey(testPeerID), [][]byte{
+		marshalRecords(t, registered),
+		[]byte("invalid"),
+		marshalRecords(t, unregistered),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, best)
+
+	_, err = validator.Select(peerRecordsKey(testPeerID), [][]byte{[]byte("invalid")})
+	assert.Error(t, err)
+}
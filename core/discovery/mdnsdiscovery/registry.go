@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mdnsdiscovery
+
+import (
+	"sync"
+	"time"
+
+	"github.com/oleksandr/bonjour"
+
+	"github.com/mysteriumnetwork/node/core/discovery/record"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// announcePort is put into the SRV record which requires a port, proposals themselves are carried in TXT records.
+const announcePort = 5353
+
+// announcer answers mDNS queries for a single proposal.
+type announcer interface {
+	SetText(text []string)
+	Shutdown()
+}
+
+type announceFunc func(instance string, text []string) (announcer, error)
+
+func bonjourAnnounce(instance string, text []string) (announcer, error) {
+	server, err := bonjour.Register(instance, serviceName, "", announcePort, text, nil)
+	if err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+type registryMDNS struct {
+	ttl      time.Duration
+	announce announceFunc
+
+	mu         sync.Mutex
+	announcers map[market.ProposalID]announcer
+}
+
+// NewRegistry create an instance of mDNS registryMDNS.
+// Proposal records expire after the given TTL unless they are refreshed by pings.
+func NewRegistry(ttl time.Duration) *registryMDNS {
+	return &registryMDNS{
+		ttl:        ttl,
+		announce:   bonjourAnnounce,
+		announcers: make(map[market.ProposalID]announcer),
+	}
+}
+
+// RegisterProposal registers service proposal to discovery service.
+func (rm *registryMDNS) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return rm.publish(proposal, signer)
+}
+
+// UnregisterProposal unregisters a service proposal when client disconnects.
+func (rm *registryMDNS) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if a, ok := rm.announcers[proposal.UniqueID()]; ok {
+		a.Shutdown()
+		delete(rm.announcers, proposal.UniqueID())
+	}
+	return nil
+}
+
+// PingProposal pings service proposal as being alive.
+func (rm *registryMDNS) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return rm.publish(proposal, signer)
+}
+
+func (rm *registryMDNS) publish(proposal market.ServiceProposal, signer identity.Signer) error {
+	r, err := record.New(proposal, rm.ttl, signer)
+	if err != nil {
+		return err
+	}
+	text, err := recordText(r)
+	if err != nil {
+		return err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if a, ok := rm.announcers[proposal.UniqueID()]; ok {
+		a.SetText(text)
+		return nil
+	}
+
+	a, err := rm.announce(instanceName(proposal), text)
+	if err != nil {
+		return err
+	}
+	rm.announcers[proposal.UniqueID()] = a
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mdnsdiscovery
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/record"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// fakeNetwork keeps the proposals announced by registries and answers repository browsing.
+type fakeNetwork struct {
+	mu    sync.Mutex
+	texts map[string][]string
+}
+
+func newFakeNetwork() *fakeNetwork {
+	return &fakeNetwork{texts: make(map[string][]string)}
+}
+
+func (fn *fakeNetwork) announce(instance string, text []string) (announcer, error) {
+	a := &fakeAnnouncer{network: fn, instance: instance}
+	a.SetText(text)
+	return a, nil
+}
+
+func (fn *fakeNetwork) browse(_ time.Duration, _ <-chan struct{}) ([][]string, error) {
+	fn.mu.Lock()
+	defer fn.mu.Unlock()
+
+	texts := make([][]string, 0, len(fn.texts))
+	for _, text := range fn.texts {
+		texts = append(texts, text)
+	}
+	return texts, nil
+}
+
+type fakeAnnouncer struct {
+	network  *fakeNetwork
+	instance string
+}
+
+func (fa *fakeAnnouncer) SetText(text []string) {
+	fa.network.mu.Lock()
+	defer fa.network.mu.Unlock()
+	fa.network.texts[fa.instance] = text
+}
+
+func (fa *fakeAnnouncer) Shutdown() {
+	fa.network.mu.Lock()
+	defer fa.network.mu.Unlock()
+	delete(fa.network.texts, fa.instance)
+}
+
+func Test_Registry_AnnouncesProposalsToRepository(t *testing.T) {
+	network := newFakeNetwork()
+
+	registry := NewRegistry(time.Minute)
+	registry.announce = network.announce
+
+	repository := NewRepository(brokerdiscovery.NewStorage(eventbus.New()), fakeVerifier, time.Minute)
+	repository.browse = network.browse
+
+	assert.NoError(t, registry.RegisterProposal(testProposal, &identity.SignerFake{}))
+	assert.NoError(t, registry.PingProposal(testProposal, &identity.SignerFake{}))
+	assert.Len(t, network.texts, 1)
+
+	repository.fetch()
+	proposals, err := repository.Proposals(&proposal.Filter{ServiceType: "wireguard"})
+	assert.NoError(t, err)
+	assert.Len(t, proposals, 1)
+
+	countries, err := repository.Countries(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"LT": 1}, countries)
+
+	assert.NoError(t, registry.UnregisterProposal(testProposal, &identity.SignerFake{}))
+	repository.fetch()
+	proposals, err = repository.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, proposals, 0)
+}
+
+func Test_Repository_IgnoresInvalidRecords(t *testing.T) {
+	network := newFakeNetwork()
+
+	expired, err := record.New(testProposal, 0, &identity.SignerFake{})
+	assert.NoError(t, err)
+	forged, err := record.New(testProposal, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+	forged.Signature = "forged"
+	for name, r := range map[string]record.Record{"expired": expired, "forged": forged} {
+		text, err := recordText(r)
+		assert.NoError(t, err)
+		network.texts[name] = text
+	}
+	network.texts["malformed"] = []string{"0=garbage"}
+
+	repository := NewRepository(brokerdiscovery.NewStorage(eventbus.New()), fakeVerifier, time.Minute)
+	repository.browse = network.browse
+
+	repository.fetch()
+	proposals, err := repository.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, proposals, 0)
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mdnsdiscovery
+
+import (
+	"sync"
+	"time"
+
+	"github.com/oleksandr/bonjour"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/record"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// browseTimeout limits how long answers to a single mDNS query are collected.
+const browseTimeout = 3 * time.Second
+
+// browseFunc collects TXT records of the announced proposals until timeout or stop.
+type browseFunc func(timeout time.Duration, stop <-chan struct{}) ([][]string, error)
+
+func bonjourBrowse(timeout time.Duration, stop <-chan struct{}) ([][]string, error) {
+	resolver, err := bonjour.NewResolver(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(chan *bonjour.ServiceEntry)
+	defer func() {
+		// Resolver blocks on delivering entries, so they are drained until it exits.
+		done := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case <-entries:
+				case <-done:
+					return
+				}
+			}
+		}()
+		resolver.Exit <- true
+		close(done)
+	}()
+
+	if err := resolver.Browse(serviceName, "", entries); err != nil {
+		return nil, err
+	}
+
+	var texts [][]string
+	timeoutChan := time.After(timeout)
+	for {
+		select {
+		case entry := <-entries:
+			texts = append(texts, entry.Text)
+		case <-timeoutChan:
+			return texts, nil
+		case <-stop:
+			return texts, nil
+		}
+	}
+}
+
+// Repository provides proposals announced over mDNS on the local network.
+type Repository struct {
+	storage         *brokerdiscovery.ProposalStorage
+	verifierFactory identity.VerifierFactory
+	fetchInterval   time.Duration
+	browse          browseFunc
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+// NewRepository constructs a new proposal repository (backed by mDNS).
+func NewRepository(storage *brokerdiscovery.ProposalStorage, verifierFactory identity.VerifierFactory, fetchInterval time.Duration) *Repository {
+	return &Repository{
+		storage:         storage,
+		verifierFactory: verifierFactory,
+		fetchInterval:   fetchInterval,
+		browse:          bonjourBrowse,
+		stopChan:        make(chan struct{}),
+	}
+}
+
+// Proposal returns a single proposal by its ID.
+func (r *Repository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	return r.storage.GetProposal(id)
+}
+
+// Proposals returns proposals matching the filter.
+func (r *Repository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	return r.storage.FindProposals(filter)
+}
+
+// Countries returns proposals per country matching the filter.
+func (r *Repository) Countries(filter *proposal.Filter) (map[string]int, error) {
+	return r.storage.Countries(filter)
+}
+
+// Start begins proposals synchronization to storage.
+func (r *Repository) Start() error {
+	go r.fetchLoop()
+
+	return nil
+}
+
+// Stop ends proposals synchronization to storage.
+func (r *Repository) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+	})
+}
+
+func (r *Repository) fetchLoop() {
+	for {
+		r.fetch()
+
+		select {
+		case <-r.stopChan:
+			return
+		case <-time.After(r.fetchInterval):
+		}
+	}
+}
+
+func (r *Repository) fetch() {
+	texts, err := r.browse(browseTimeout, r.stopChan)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to browse proposals on the local network")
+		return
+	}
+
+	now := time.Now()
+	newest := make(map[market.ProposalID]record.Record)
+	proposals := make(map[market.ProposalID]market.ServiceProposal)
+	for _, text := range texts {
+		rec, err := parseText(text)
+		if err != nil {
+			log.Debug().Err(err).Msg("Ignoring malformed proposal record from the local network")
+			continue
+		}
+		p, err := rec.Verify(r.verifierFactory)
+		if err != nil {
+			log.Debug().Err(err).Msg("Ignoring proposal record from the local network")
+			continue
+		}
+		if rec.Expired(now) || !p.IsSupported() {
+			continue
+		}
+
+		id := p.UniqueID()
+		if existing, ok := newest[id]; ok && existing.IssuedAt >= rec.IssuedAt {
+			continue
+		}
+		newest[id] = rec
+		proposals[id] = p
+	}
+
+	result := make([]market.ServiceProposal, 0, len(proposals))
+	for _, p := range proposals {
+		result = append(result, p)
+	}
+	r.storage.Set(result)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mdnsdiscovery
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/discovery/record"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// serviceName is the DNS-SD service type proposals are announced under.
+const serviceName = "_mysterium-proposal._udp"
+
+// textChunkSize keeps TXT strings within the 255 bytes limit of a single string.
+const textChunkSize = 200
+
+// recordText encodes the record into TXT strings "<index>=<chunk>", as the record does not fit into a single one.
+func recordText(r record.Record) ([]string, error) {
+	recordJSON, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal proposal record: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(recordJSON)
+	text := make([]string, 0, len(encoded)/textChunkSize+1)
+	for i := 0; len(encoded) > 0; i++ {
+		size := textChunkSize
+		if len(encoded) < size {
+			size = len(encoded)
+		}
+		text = append(text, fmt.Sprintf("%d=%s", i, encoded[:size]))
+		encoded = encoded[size:]
+	}
+	return text, nil
+}
+
+// parseText decodes the record from TXT strings, which may come in any order.
+func parseText(text []string) (record.Record, error) {
+	chunks := make(map[int]string, len(text))
+	indexes := make([]int, 0, len(text))
+	for _, entry := range text {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		index, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+		if _, ok := chunks[index]; !ok {
+			indexes = append(indexes, index)
+		}
+		chunks[index] = parts[1]
+	}
+	sort.Ints(indexes)
+
+	var encoded strings.Builder
+	for i, index := range indexes {
+		if i != index {
+			return record.Record{}, fmt.Errorf("proposal record chunk %d is missing", i)
+		}
+		encoded.WriteString(chunks[index])
+	}
+
+	recordJSON, err := base64.RawURLEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return record.Record{}, fmt.Errorf("failed to decode proposal record: %w", err)
+	}
+
+	var r record.Record
+	if err := json.Unmarshal(recordJSON, &r); err != nil {
+		return record.Record{}, fmt.Errorf("failed to unmarshal proposal record: %w", err)
+	}
+	return r, nil
+}
+
+// instanceName returns the DNS-SD instance name of the proposal, it is unique on the network.
+func instanceName(proposal market.ServiceProposal) string {
+	return proposal.ProviderID + "-" + proposal.ServiceType
+}
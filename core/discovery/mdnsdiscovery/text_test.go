@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mdnsdiscovery
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/record"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+var testProposal = market.ServiceProposal{
+	ProviderID:  "0x1",
+	ServiceType: "wireguard",
+	Location:    market.Location{Country: "LT", City: "Vilnius"},
+	Contacts:    market.ContactList{{Type: "test_contact"}},
+}
+
+type testContact struct{}
+
+func init() {
+	// Repository only serves proposals of the known services and contacts.
+	market.RegisterServiceType("wireguard")
+	market.RegisterContactUnserializer("test_contact", func(*json.RawMessage) (market.ContactDefinition, error) {
+		return testContact{}, nil
+	})
+}
+
+func fakeVerifier(_ identity.Identity) identity.Verifier {
+	return &identity.VerifierFake{}
+}
+
+func Test_RecordText_RoundTrip(t *testing.T) {
+	r, err := record.New(testProposal, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	text, err := recordText(r)
+	assert.NoError(t, err)
+	assert.True(t, len(text) > 1)
+	for _, entry := range text {
+		assert.True(t, len(entry) <= 255)
+	}
+
+	// Chunks are reassembled even if they arrive out of order.
+	text[0], text[1] = text[1], text[0]
+	parsed, err := parseText(text)
+	assert.NoError(t, err)
+	assert.Equal(t, r, parsed)
+
+	proposal, err := parsed.Verify(fakeVerifier)
+	assert.NoError(t, err)
+	assert.Equal(t, testProposal.UniqueID(), proposal.UniqueID())
+
+	_, err = parseText(text[1:])
+	assert.Error(t, err)
+}
@@ -18,6 +18,8 @@
 package discovery
 
 import (
+	"time"
+
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
@@ -29,6 +31,18 @@ type PricedServiceProposalRepository struct {
 	baseRepo      proposal.Repository
 	pip           PriceInfoProvider
 	filterPresets proposal.FilterPresetRepository
+	reputation    ReputationProvider
+	latency       LatencyProvider
+}
+
+// ReputationProvider returns the personal consumer reputation of the provider.
+type ReputationProvider interface {
+	Reputation(providerID string) proposal.Reputation
+}
+
+// LatencyProvider returns the round trip time to the provider measured by the consumer.
+type LatencyProvider interface {
+	Latency(providerID string) (time.Duration, bool)
 }
 
 // PriceInfoProvider allows to fetch the current pricing for services.
@@ -45,6 +59,16 @@ func NewPricedServiceProposalRepository(baseRepo proposal.Repository, pip PriceI
 	}
 }
 
+// SetReputation sets the provider reputation source used to rank proposals and hide blocked providers.
+func (pspr *PricedServiceProposalRepository) SetReputation(reputation ReputationProvider) {
+	pspr.reputation = reputation
+}
+
+// SetLatency sets the source of provider latency measured by the consumer.
+func (pspr *PricedServiceProposalRepository) SetLatency(latency LatencyProvider) {
+	pspr.latency = latency
+}
+
 // Proposal fetches the proposal from base repository and enriches it with pricing data.
 func (pspr *PricedServiceProposalRepository) Proposal(id market.ProposalID) (*proposal.PricedServiceProposal, error) {
 	prop, err := pspr.baseRepo.Proposal(id)
@@ -63,21 +87,80 @@ func (pspr *PricedServiceProposalRepository) Proposal(id market.ProposalID) (*pr
 
 // Proposals fetches proposals from base repository and enriches them with pricing data.
 func (pspr *PricedServiceProposalRepository) Proposals(filter *proposal.Filter) ([]proposal.PricedServiceProposal, error) {
+	var preset *proposal.FilterPreset
+	if filter != nil && filter.PresetID != 0 {
+		var err error
+		preset, err = pspr.filterPresets.Get(filter.PresetID)
+		if err != nil {
+			return nil, err
+		}
+		// NAT compatibility can only be resolved by discovery, so preset narrows down the query itself.
+		if filter.NATCompatibility == "" {
+			filter = filter.Copy()
+			filter.NATCompatibility = preset.NATCompatibility
+		}
+	}
+
 	proposals, err := pspr.baseRepo.Proposals(filter)
 	if err != nil {
 		return nil, err
 	}
 	priced := pspr.toPricedProposals(proposals)
 
-	if filter != nil && filter.PresetID != 0 {
+	if preset != nil {
+		priced = preset.Filter(priced)
+	}
+
+	// Blocked providers are only listed when asked for explicitly.
+	if filter == nil || (filter.ProviderID == "" && len(filter.ProviderIDs) == 0) {
+		priced = withoutBlocked(priced)
+	}
+
+	if filter != nil && filter.LocalLatencyMax > 0 {
+		priced = withinLatency(priced, filter.LocalLatencyMax)
+	}
+
+	return priced, nil
+}
+
+// Matches checks if the proposal satisfies the filter the same way Proposals would select it.
+// NAT compatibility is resolved by discovery only, so it is not checked.
+func (pspr *PricedServiceProposalRepository) Matches(filter *proposal.Filter, p proposal.PricedServiceProposal) (bool, error) {
+	if filter == nil {
+		return !p.Reputation.Blocked, nil
+	}
+
+	if !filter.Matches(p.ServiceProposal) {
+		return false, nil
+	}
+	if filter.CompatibilityMin > 0 && p.Compatibility < filter.CompatibilityMin {
+		return false, nil
+	}
+	if filter.CompatibilityMax > 0 && p.Compatibility > filter.CompatibilityMax {
+		return false, nil
+	}
+	if p.Quality.Quality < float64(filter.QualityMin) {
+		return false, nil
+	}
+
+	if filter.PresetID != 0 {
 		preset, err := pspr.filterPresets.Get(filter.PresetID)
 		if err != nil {
-			return nil, err
+			return false, err
+		}
+		if len(preset.Filter([]proposal.PricedServiceProposal{p})) == 0 {
+			return false, nil
 		}
-		priced = preset.Filter(priced)
 	}
 
-	return priced, nil
+	if filter.ProviderID == "" && len(filter.ProviderIDs) == 0 && p.Reputation.Blocked {
+		return false, nil
+	}
+	if filter.LocalLatencyMax > 0 && len(withinLatency([]proposal.PricedServiceProposal{p}, filter.LocalLatencyMax)) == 0 {
+		return false, nil
+	}
+
+	return true, nil
 }
 
 // Countries fetches number of proposals per country from base repository.
@@ -105,13 +188,58 @@ func (pspr *PricedServiceProposalRepository) toPricedProposals(in []market.Servi
 }
 
 func (pspr *PricedServiceProposalRepository) toPricedProposal(in market.ServiceProposal) (proposal.PricedServiceProposal, error) {
-	price, err := pspr.pip.GetCurrentPrice(in.Location.IPType, in.Location.Country)
+	price, err := pspr.ProposalPrice(in)
 	if err != nil {
 		return proposal.PricedServiceProposal{}, err
 	}
 
-	return proposal.PricedServiceProposal{
+	priced := proposal.PricedServiceProposal{
 		ServiceProposal: in,
 		Price:           price,
-	}, nil
+	}
+	if pspr.reputation != nil {
+		priced.Reputation = pspr.reputation.Reputation(in.ProviderID)
+	}
+	if pspr.latency != nil {
+		if rtt, ok := pspr.latency.Latency(in.ProviderID); ok {
+			priced.LocalLatency = rtt
+		}
+	}
+	return priced, nil
+}
+
+// ProposalPrice returns the price advertised by provider, or the discovery price if provider does not advertise one.
+// Advertised price is not trusted above the discovery price, provider would not accept such price anyway.
+func (pspr *PricedServiceProposalRepository) ProposalPrice(in market.ServiceProposal) (market.Price, error) {
+	price, err := pspr.pip.GetCurrentPrice(in.Location.IPType, in.Location.Country)
+	if err != nil {
+		return market.Price{}, err
+	}
+
+	if p := in.Price; p != nil && p.PricePerHour != nil && p.PricePerGiB != nil &&
+		p.PricePerHour.Cmp(price.PricePerHour) <= 0 && p.PricePerGiB.Cmp(price.PricePerGiB) <= 0 {
+		return *p, nil
+	}
+
+	return price, nil
+}
+
+func withoutBlocked(in []proposal.PricedServiceProposal) []proposal.PricedServiceProposal {
+	res := make([]proposal.PricedServiceProposal, 0, len(in))
+	for _, p := range in {
+		if !p.Reputation.Blocked {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+func withinLatency(in []proposal.PricedServiceProposal, max time.Duration) []proposal.PricedServiceProposal {
+	res := make([]proposal.PricedServiceProposal, 0, len(in))
+	for _, p := range in {
+		if p.LocalLatency > 0 && p.LocalLatency <= max {
+			res = append(res, p)
+		}
+	}
+	return res
 }
@@ -21,11 +21,14 @@ import (
 	"errors"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/query"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat"
 )
 
 var mockProposal = market.ServiceProposal{
@@ -76,6 +79,40 @@ func TestGetProposal(t *testing.T) {
 		assert.EqualValues(t, mockProposal, result.ServiceProposal)
 		assert.EqualValues(t, mockPrice, result.Price)
 	})
+	t.Run("prefers price advertised by provider", func(t *testing.T) {
+		advertised := mockProposal
+		advertised.Price = &market.Price{PricePerHour: big.NewInt(1), PricePerGiB: big.NewInt(2)}
+		mp := &mockPriceInfoProvider{
+			priceToReturn: market.Price{
+				PricePerHour: big.NewInt(3),
+				PricePerGiB:  big.NewInt(4),
+			},
+		}
+		repo := NewPricedServiceProposalRepository(&mockRepository{
+			proposalToReturn: &advertised,
+		}, mp, presetRepository)
+
+		result, err := repo.Proposal(market.ProposalID{})
+		assert.NoError(t, err)
+		assert.EqualValues(t, market.Price{PricePerHour: big.NewInt(1), PricePerGiB: big.NewInt(2)}, result.Price)
+	})
+	t.Run("ignores advertised price above discovery price", func(t *testing.T) {
+		advertised := mockProposal
+		advertised.Price = &market.Price{PricePerHour: big.NewInt(1), PricePerGiB: big.NewInt(5)}
+		mp := &mockPriceInfoProvider{
+			priceToReturn: market.Price{
+				PricePerHour: big.NewInt(3),
+				PricePerGiB:  big.NewInt(4),
+			},
+		}
+		repo := NewPricedServiceProposalRepository(&mockRepository{
+			proposalToReturn: &advertised,
+		}, mp, presetRepository)
+
+		result, err := repo.Proposal(market.ProposalID{})
+		assert.NoError(t, err)
+		assert.EqualValues(t, mp.priceToReturn, result.Price)
+	})
 	t.Run("bubbles repo errors", func(t *testing.T) {
 		mockError := errors.New("boom")
 		mr := &mockRepository{
@@ -151,10 +188,163 @@ func TestGetProposals(t *testing.T) {
 	})
 }
 
+func TestGetProposalsByUserPreset(t *testing.T) {
+	otherProposal := mockProposal
+	otherProposal.ProviderID = "0x1"
+
+	mr := &mockRepository{
+		proposalsToReturn: []market.ServiceProposal{mockProposal, otherProposal},
+	}
+	mp := &mockPriceInfoProvider{
+		priceToReturn: market.Price{PricePerHour: big.NewInt(1), PricePerGiB: big.NewInt(2)},
+	}
+	presets := &mockFilterPresetRepository{
+		presets: proposal.FilterPresets{Entries: []proposal.FilterPreset{
+			{
+				ID:               100,
+				Name:             "Cheap residential",
+				IPType:           proposal.Residential,
+				PriceGiBMax:      big.NewInt(2),
+				NATCompatibility: nat.NATTypeSymmetric,
+				DeniedProviders:  []string{"0x1"},
+			},
+		}},
+	}
+	repo := NewPricedServiceProposalRepository(mr, mp, presets)
+
+	filter := &proposal.Filter{PresetID: 100}
+	result, err := repo.Proposals(filter)
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "0x0", result[0].ProviderID)
+	assert.Equal(t, nat.NATTypeSymmetric, mr.recordedFilter.NATCompatibility)
+	assert.Empty(t, filter.NATCompatibility, "caller filter is not changed")
+
+	presets.presets.Entries[0].PriceGiBMax = big.NewInt(1)
+	result, err = repo.Proposals(&proposal.Filter{PresetID: 100})
+	assert.NoError(t, err)
+	assert.Len(t, result, 0)
+}
+
+func TestGetProposalsWithReputation(t *testing.T) {
+	blockedProposal := mockProposal
+	blockedProposal.ProviderID = "0x1"
+
+	mr := &mockRepository{
+		proposalsToReturn: []market.ServiceProposal{mockProposal, blockedProposal},
+	}
+	repo := NewPricedServiceProposalRepository(mr, &mockPriceInfoProvider{}, presetRepository)
+	repo.SetReputation(mockReputationProvider{
+		"0x0": {Score: 0.8, Favourite: true},
+		"0x1": {Score: 0.1, Blocked: true},
+	})
+
+	result, err := repo.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, proposal.Reputation{Score: 0.8, Favourite: true}, result[0].Reputation)
+
+	result, err = repo.Proposals(&proposal.Filter{ProviderIDs: []string{"0x0", "0x1"}})
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.True(t, result[1].Reputation.Blocked)
+}
+
+func TestGetProposalsWithinLocalLatency(t *testing.T) {
+	slowProposal := mockProposal
+	slowProposal.ProviderID = "0x1"
+	unmeasuredProposal := mockProposal
+	unmeasuredProposal.ProviderID = "0x2"
+
+	mr := &mockRepository{
+		proposalsToReturn: []market.ServiceProposal{mockProposal, slowProposal, unmeasuredProposal},
+	}
+	repo := NewPricedServiceProposalRepository(mr, &mockPriceInfoProvider{}, presetRepository)
+	repo.SetLatency(mockLatencyProvider{
+		"0x0": 30 * time.Millisecond,
+		"0x1": 300 * time.Millisecond,
+	})
+
+	result, err := repo.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+	assert.Equal(t, 30*time.Millisecond, result[0].LocalLatency)
+	assert.Zero(t, result[2].LocalLatency)
+
+	result, err = repo.Proposals(&proposal.Filter{LocalLatencyMax: 100 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "0x0", result[0].ProviderID)
+}
+
+func TestMatches(t *testing.T) {
+	presets := &mockFilterPresetRepository{
+		presets: proposal.FilterPresets{Entries: []proposal.FilterPreset{
+			{ID: 100, Name: "Datacenter", IPType: proposal.Hosting},
+		}},
+	}
+	repo := NewPricedServiceProposalRepository(&mockRepository{}, &mockPriceInfoProvider{}, presets)
+	p := proposal.PricedServiceProposal{ServiceProposal: mockProposal}
+	blocked := p
+	blocked.Reputation.Blocked = true
+
+	tests := []struct {
+		name     string
+		filter   *proposal.Filter
+		proposal proposal.PricedServiceProposal
+		matches  bool
+	}{
+		{"no filter", nil, p, true},
+		{"no filter hides blocked", nil, blocked, false},
+		{"country", &proposal.Filter{LocationCountry: "yes"}, p, true},
+		{"other country", &proposal.Filter{LocationCountry: "no"}, p, false},
+		{"compatibility too low", &proposal.Filter{CompatibilityMin: 2}, p, false},
+		{"quality too low", &proposal.Filter{QualityMin: 1}, p, false},
+		{"preset", &proposal.Filter{PresetID: 100}, p, false},
+		{"blocked provider asked explicitly", &proposal.Filter{ProviderID: "0x0"}, blocked, true},
+		{"latency not measured", &proposal.Filter{LocalLatencyMax: time.Second}, p, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := repo.Matches(tt.filter, tt.proposal)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.matches, matches)
+		})
+	}
+}
+
+func TestQueryPriceUsesAdvertisedPrice(t *testing.T) {
+	discoveryPrice := market.Price{PricePerHour: big.NewInt(1e18), PricePerGiB: big.NewInt(1e18)}
+	repo := NewPricedServiceProposalRepository(&mockRepository{}, &mockPriceInfoProvider{priceToReturn: discoveryPrice}, presetRepository)
+
+	match, err := query.Parse("price.gib < 0.1", repo)
+	assert.NoError(t, err)
+
+	advertised := mockProposal
+	advertised.Price = &market.Price{PricePerHour: big.NewInt(1e16), PricePerGiB: big.NewInt(5e16)}
+	assert.True(t, match(advertised))
+	assert.False(t, match(mockProposal))
+}
+
+type mockLatencyProvider map[string]time.Duration
+
+func (m mockLatencyProvider) Latency(providerID string) (time.Duration, bool) {
+	rtt, ok := m[providerID]
+	return rtt, ok
+}
+
+type mockReputationProvider map[string]proposal.Reputation
+
+func (m mockReputationProvider) Reputation(providerID string) proposal.Reputation {
+	return m[providerID]
+}
+
 type mockRepository struct {
 	proposalsToReturn []market.ServiceProposal
 	errToReturn       error
 	proposalToReturn  *market.ServiceProposal
+	recordedFilter    *proposal.Filter
 }
 
 func (mr *mockRepository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
@@ -162,11 +352,12 @@ func (mr *mockRepository) Proposal(id market.ProposalID) (*market.ServiceProposa
 }
 
 func (mr *mockRepository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	mr.recordedFilter = filter
 	return mr.proposalsToReturn, mr.errToReturn
 }
 
 func (mr *mockRepository) Countries(filter *proposal.Filter) (map[string]int, error) {
-	return nil, nil
+	return nil, mr.errToReturn
 }
 
 type mockPriceInfoProvider struct {
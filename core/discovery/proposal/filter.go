@@ -19,6 +19,7 @@ package proposal
 
 import (
 	"sync"
+	"time"
 
 	"github.com/mysteriumnetwork/node/core/discovery/reducer"
 	"github.com/mysteriumnetwork/node/market"
@@ -39,7 +40,11 @@ type Filter struct {
 	QualityMin                         float32
 	ExcludeUnsupported                 bool
 	IncludeMonitoringFailed            bool
+	IPv6                               bool
+	ExcludeFull                        bool
 	NATCompatibility                   nat.NATType
+	Query                              reducer.AndCondition
+	LocalLatencyMax                    time.Duration
 	condition                          reducer.AndCondition
 	buildOnce                          sync.Once
 }
@@ -70,11 +75,20 @@ func (filter *Filter) Build() {
 		if filter.LocationCountry != "" {
 			conditions = append(conditions, reducer.Equal(reducer.LocationCountry, filter.LocationCountry))
 		}
+		if filter.IPv6 {
+			conditions = append(conditions, reducer.Equal(reducer.IPv6, true))
+		}
+		if filter.ExcludeFull {
+			conditions = append(conditions, reducer.NotFull())
+		}
 		if filter.AccessPolicy != "all" {
 			if filter.AccessPolicy != "" || filter.AccessPolicySource != "" {
 				conditions = append(conditions, reducer.AccessPolicy(filter.AccessPolicy, filter.AccessPolicySource))
 			}
 		}
+		if filter.Query != nil {
+			conditions = append(conditions, filter.Query)
+		}
 		filter.condition = reducer.And(conditions...)
 	})
 }
@@ -86,6 +100,30 @@ func (filter *Filter) Matches(proposal market.ServiceProposal) bool {
 	return filter.condition(proposal)
 }
 
+// Copy returns a copy of the filter criteria, conditions of the copy are built anew.
+func (filter *Filter) Copy() *Filter {
+	return &Filter{
+		PresetID:                filter.PresetID,
+		ProviderID:              filter.ProviderID,
+		ProviderIDs:             filter.ProviderIDs,
+		ServiceType:             filter.ServiceType,
+		LocationCountry:         filter.LocationCountry,
+		IPType:                  filter.IPType,
+		AccessPolicy:            filter.AccessPolicy,
+		AccessPolicySource:      filter.AccessPolicySource,
+		CompatibilityMin:        filter.CompatibilityMin,
+		CompatibilityMax:        filter.CompatibilityMax,
+		QualityMin:              filter.QualityMin,
+		ExcludeUnsupported:      filter.ExcludeUnsupported,
+		IncludeMonitoringFailed: filter.IncludeMonitoringFailed,
+		IPv6:                    filter.IPv6,
+		ExcludeFull:             filter.ExcludeFull,
+		NATCompatibility:        filter.NATCompatibility,
+		Query:                   filter.Query,
+		LocalLatencyMax:         filter.LocalLatencyMax,
+	}
+}
+
 // ToAPIQuery serialises filter to query of Mysterium API
 func (filter *Filter) ToAPIQuery() mysterium.ProposalsQuery {
 	query := mysterium.ProposalsQuery{
@@ -101,6 +139,7 @@ func (filter *Filter) ToAPIQuery() mysterium.ProposalsQuery {
 		QualityMin:              filter.QualityMin,
 		IncludeMonitoringFailed: filter.IncludeMonitoringFailed,
 		NATCompatibility:        filter.NATCompatibility,
+		IPv6:                    filter.IPv6,
 	}
 
 	return query
@@ -19,13 +19,20 @@ package proposal
 
 import (
 	"fmt"
+	"math/big"
+	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
+
+	"github.com/mysteriumnetwork/node/nat"
 )
 
 var errMsgBoltNotFound = "not found"
 
+// ErrFilterPresetNotFound indicates that filter preset with the requested id does not exist.
+var ErrFilterPresetNotFound = errors.New("filter preset not found")
+
 type persistentStorage interface {
 	Store(bucket string, data interface{}) error
 	GetAllFrom(bucket string, data interface{}) error
@@ -100,7 +107,7 @@ func (fps *FilterPresetStorage) Get(id int) (*FilterPreset, error) {
 	}
 	byId, ok := filterPresets(entries).prependSystemPresets().byId(id)
 	if !ok {
-		return nil, fmt.Errorf("filter preset id: %d not found", id)
+		return nil, fmt.Errorf("%w: %d", ErrFilterPresetNotFound, id)
 	}
 	return &byId, nil
 }
@@ -113,12 +120,15 @@ func (fps *FilterPresetStorage) ls() ([]FilterPreset, error) {
 
 // Save created or updates existing
 // to update existing: preset.ID > startingID
-func (fps *FilterPresetStorage) Save(preset FilterPreset) error {
+func (fps *FilterPresetStorage) Save(preset *FilterPreset) error {
 	fps.lock.Lock()
 	defer fps.lock.Unlock()
 
 	if preset.ID != 0 {
-		return fps.storage.Store(bucketName, &preset)
+		if IsSystemFilterPreset(preset.ID) {
+			return errors.New("modifying system presets is not allowed")
+		}
+		return fps.storage.Store(bucketName, preset)
 	}
 
 	nextID, err := fps.nextID()
@@ -127,8 +137,9 @@ func (fps *FilterPresetStorage) Save(preset FilterPreset) error {
 	}
 
 	preset.ID = nextID
-	err = fps.storage.Store(bucketName, &preset)
+	err = fps.storage.Store(bucketName, preset)
 	if err != nil {
+		preset.ID = 0
 		return err
 	}
 
@@ -140,7 +151,7 @@ func (fps *FilterPresetStorage) Delete(id int) error {
 	fps.lock.Lock()
 	defer fps.lock.Unlock()
 
-	if id < 100 {
+	if IsSystemFilterPreset(id) {
 		return errors.New("deleting system presets is not allowed")
 	}
 
@@ -148,6 +159,11 @@ func (fps *FilterPresetStorage) Delete(id int) error {
 	return fps.storage.Delete(bucketName, &toRemove)
 }
 
+// IsSystemFilterPreset returns true if the preset with the given id is predefined and read only.
+func IsSystemFilterPreset(id int) bool {
+	return id < startingID
+}
+
 func (fps *FilterPresetStorage) nextID() (int, error) {
 	var last FilterPreset
 	err := fps.storage.GetLast(bucketName, &last)
@@ -183,12 +199,65 @@ type FilterPreset struct {
 	ID     int
 	Name   string
 	IPType IPType
-	filter func(proposals []PricedServiceProposal) []PricedServiceProposal
+	// Country is a two letter country code of providers to select.
+	Country string
+	// PriceHourMax and PriceGiBMax are price caps in wei, nil means no cap.
+	PriceHourMax *big.Int
+	PriceGiBMax  *big.Int
+	QualityMin   float64
+	// NATCompatibility selects providers compatible with NAT of the given type.
+	NATCompatibility nat.NATType
+	// AllowedProviders limits selection to the given providers, empty allows any provider.
+	AllowedProviders []string
+	DeniedProviders  []string
+	filter           func(proposals []PricedServiceProposal) []PricedServiceProposal
 }
 
 // Filter filters proposals according to preset
 func (fps *FilterPreset) Filter(proposals []PricedServiceProposal) []PricedServiceProposal {
-	return fps.filter(proposals) // because of storage, fps.filter can't be exported as a struct property
+	if fps.filter != nil {
+		return fps.filter(proposals) // because of storage, fps.filter can't be exported as a struct property
+	}
+
+	var filtered []PricedServiceProposal
+	for _, p := range proposals {
+		if fps.matches(p) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+func (fps *FilterPreset) matches(p PricedServiceProposal) bool {
+	if fps.IPType != "" && !strings.EqualFold(p.Location.IPType, string(fps.IPType)) {
+		return false
+	}
+	if fps.Country != "" && !strings.EqualFold(p.Location.Country, fps.Country) {
+		return false
+	}
+	if exceeds(p.Price.PricePerHour, fps.PriceHourMax) || exceeds(p.Price.PricePerGiB, fps.PriceGiBMax) {
+		return false
+	}
+	if p.Quality.Quality < fps.QualityMin {
+		return false
+	}
+	if len(fps.AllowedProviders) > 0 && !containsProvider(fps.AllowedProviders, p.ProviderID) {
+		return false
+	}
+	return !containsProvider(fps.DeniedProviders, p.ProviderID)
+}
+
+func exceeds(price, max *big.Int) bool {
+	return max != nil && price != nil && price.Cmp(max) > 0
+}
+
+func containsProvider(providers []string, providerID string) bool {
+	for _, id := range providers {
+		if strings.EqualFold(id, providerID) {
+			return true
+		}
+	}
+	return false
 }
 
 func filterPresets(entries []FilterPreset) *FilterPresets {
@@ -18,7 +18,11 @@
 package proposal
 
 import (
+	"math/big"
+
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat"
 	"github.com/stretchr/testify/assert"
 
 	"io/ioutil"
@@ -47,9 +51,9 @@ func Test_ProposalFilterPreset(t *testing.T) {
 
 	t.Run("save", func(t *testing.T) {
 		// given
-		err := presetStorage.Save(FilterPreset{Name: "Boink"})
+		err := presetStorage.Save(&FilterPreset{Name: "Boink"})
 		assert.NoError(t, err)
-		err = presetStorage.Save(FilterPreset{Name: "Boink 2"})
+		err = presetStorage.Save(&FilterPreset{Name: "Boink 2"})
 		assert.NoError(t, err)
 
 		// when
@@ -67,7 +71,7 @@ func Test_ProposalFilterPreset(t *testing.T) {
 
 		// and
 		p2.Name = "Boink 3"
-		err = presetStorage.Save(p2)
+		err = presetStorage.Save(&p2)
 		assert.NoError(t, err)
 
 		ls, err = presetStorage.List()
@@ -82,7 +86,7 @@ func Test_ProposalFilterPreset(t *testing.T) {
 
 	t.Run("delete", func(t *testing.T) {
 		// given
-		err := presetStorage.Save(FilterPreset{Name: "Delete Me"})
+		err := presetStorage.Save(&FilterPreset{Name: "Delete Me"})
 		assert.NoError(t, err)
 
 		ls, err := presetStorage.List()
@@ -102,6 +106,72 @@ func Test_ProposalFilterPreset(t *testing.T) {
 		_, ok = ls.byName("Delete Me")
 		assert.False(t, ok)
 	})
+
+	t.Run("system presets are read only", func(t *testing.T) {
+		err := presetStorage.Save(&FilterPreset{ID: 1, Name: "Media"})
+		assert.Error(t, err)
+
+		err = presetStorage.Delete(1)
+		assert.Error(t, err)
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		_, err := presetStorage.Get(999)
+		assert.ErrorIs(t, err, ErrFilterPresetNotFound)
+	})
+
+	t.Run("user preset criteria survive storage", func(t *testing.T) {
+		preset := FilterPreset{
+			Name:             "Cheap",
+			Country:          "DE",
+			PriceGiBMax:      big.NewInt(500),
+			QualityMin:       1.5,
+			NATCompatibility: nat.NATTypeFullCone,
+			AllowedProviders: []string{"0x1", "0x2"},
+			DeniedProviders:  []string{"0x2"},
+		}
+		err := presetStorage.Save(&preset)
+		assert.NoError(t, err)
+
+		stored, err := presetStorage.Get(preset.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, preset, *stored)
+	})
+}
+
+func Test_UserFilterPreset_Filter(t *testing.T) {
+	preset := FilterPreset{
+		IPType:           Residential,
+		Country:          "de",
+		PriceHourMax:     big.NewInt(10),
+		PriceGiBMax:      big.NewInt(100),
+		QualityMin:       1,
+		AllowedProviders: []string{"0x1", "0x2", "0x3", "0x4"},
+		DeniedProviders:  []string{"0x4"},
+	}
+	newProposal := func(providerID, ipType, country string, perHour, perGiB int64, quality float64) PricedServiceProposal {
+		return PricedServiceProposal{
+			ServiceProposal: market.ServiceProposal{
+				ProviderID: providerID,
+				Location:   market.Location{IPType: ipType, Country: country},
+				Quality:    market.Quality{Quality: quality},
+			},
+			Price: market.Price{PricePerHour: big.NewInt(perHour), PricePerGiB: big.NewInt(perGiB)},
+		}
+	}
+
+	filtered := preset.Filter([]PricedServiceProposal{
+		newProposal("0x1", "residential", "DE", 10, 100, 1),
+		newProposal("0x2", "hosting", "DE", 1, 1, 2),
+		newProposal("0x3", "residential", "DE", 1, 101, 2),
+		newProposal("0x3", "residential", "NL", 1, 1, 2),
+		newProposal("0x3", "residential", "DE", 1, 1, 0.5),
+		newProposal("0x4", "residential", "DE", 1, 1, 2),
+		newProposal("0x5", "residential", "DE", 1, 1, 2),
+	})
+
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "0x1", filtered[0].ProviderID)
 }
 
 func (w *FilterPresets) byName(name string) (FilterPreset, bool) {
@@ -20,6 +20,7 @@ package proposal
 import (
 	"testing"
 
+	"github.com/mysteriumnetwork/node/core/discovery/reducer"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/stretchr/testify/assert"
 )
@@ -127,6 +128,40 @@ func Test_ProposalFilter_FiltersByLocationType(t *testing.T) {
 	assert.True(t, filter.Matches(proposalProvider2Streaming))
 }
 
+func Test_ProposalFilter_FiltersByIPv6(t *testing.T) {
+	proposalDualStack := market.NewProposal(provider2, serviceTypeStreaming, market.NewProposalOpts{IPv6: true})
+
+	filter := &Filter{
+		IPv6: true,
+	}
+	assert.False(t, filter.Matches(proposalEmpty))
+	assert.False(t, filter.Matches(proposalProvider1Streaming))
+	assert.True(t, filter.Matches(proposalDualStack))
+}
+
+func Test_ProposalFilter_FiltersFull(t *testing.T) {
+	proposalFull := market.NewProposal(provider2, serviceTypeStreaming, market.NewProposalOpts{})
+	proposalFull.Capacity = &market.Capacity{Sessions: 10, MaxSessions: 10, Load: 1, Full: true}
+	proposalAvailable := market.NewProposal(provider2, serviceTypeStreaming, market.NewProposalOpts{})
+	proposalAvailable.Capacity = &market.Capacity{Sessions: 5, MaxSessions: 10, Load: 0.5}
+
+	filter := &Filter{
+		ExcludeFull: true,
+	}
+	assert.True(t, filter.Matches(proposalEmpty))
+	assert.False(t, filter.Matches(proposalFull))
+	assert.True(t, filter.Matches(proposalAvailable))
+}
+
+func Test_ProposalFilter_FiltersByQuery(t *testing.T) {
+	filter := &Filter{
+		Query: reducer.Equal(reducer.ProviderID, provider1),
+	}
+	assert.False(t, filter.Matches(proposalEmpty))
+	assert.True(t, filter.Matches(proposalProvider1Streaming))
+	assert.False(t, filter.Matches(proposalProvider2Streaming))
+}
+
 func Test_ProposalFilter_FiltersByAccessID(t *testing.T) {
 	filter := &Filter{
 		AccessPolicy: "whitelist",
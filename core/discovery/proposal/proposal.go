@@ -18,6 +18,8 @@
 package proposal
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/market"
 )
 
@@ -33,5 +35,16 @@ type Repository interface {
 // PricedServiceProposal enriches proposals with price data.
 type PricedServiceProposal struct {
 	market.ServiceProposal
-	Price market.Price `json:"price,omitempty"`
+	Price      market.Price `json:"price,omitempty"`
+	Reputation Reputation   `json:"reputation"`
+	// LocalLatency is the round trip time to the provider measured by the consumer, zero if not measured.
+	LocalLatency time.Duration `json:"local_latency,omitempty"`
+}
+
+// Reputation is the personal consumer view of the provider built from own sessions with it.
+type Reputation struct {
+	// Score is in range [0, 1], providers without history score 0.5.
+	Score     float64 `json:"score"`
+	Favourite bool    `json:"favourite,omitempty"`
+	Blocked   bool    `json:"blocked,omitempty"`
 }
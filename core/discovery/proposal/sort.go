@@ -28,6 +28,10 @@ const (
 	SortTypeLatency   = "latency"
 	SortTypePrice     = "price"
 	SortTypeQuality   = "quality"
+	// SortTypeReputation ranks favourite providers first and the rest by personal reputation score.
+	SortTypeReputation = "reputation"
+	// SortTypeLocalLatency ranks providers by the round trip time measured by the consumer, unmeasured ones go last.
+	SortTypeLocalLatency = "local_latency"
 )
 
 // ErrUnsupportedSortType indicates unsupported proposals sorting type error.
@@ -45,6 +49,10 @@ func Sort(proposals []PricedServiceProposal, sortType string) ([]PricedServicePr
 		return SortByPrice(proposals), nil
 	case SortTypeQuality:
 		return SortByQuality(proposals), nil
+	case SortTypeReputation:
+		return SortByReputation(proposals), nil
+	case SortTypeLocalLatency:
+		return SortByLocalLatency(proposals), nil
 	case "": // Assuming zero value to be no sorting.
 		return proposals, nil
 	default:
@@ -99,3 +107,34 @@ func SortByPrice(proposals []PricedServiceProposal) []PricedServiceProposal {
 
 	return tmp
 }
+
+// SortByReputation sorts proposals list putting favourite providers first and the rest by personal reputation score.
+func SortByReputation(proposals []PricedServiceProposal) []PricedServiceProposal {
+	tmp := make([]PricedServiceProposal, len(proposals))
+	copy(tmp, proposals)
+
+	sort.SliceStable(tmp, func(i, j int) bool {
+		if tmp[i].Reputation.Favourite != tmp[j].Reputation.Favourite {
+			return tmp[i].Reputation.Favourite
+		}
+		return tmp[i].Reputation.Score > tmp[j].Reputation.Score
+	})
+
+	return tmp
+}
+
+// SortByLocalLatency sorts proposals list based on the round trip time measured by the consumer.
+// Proposals without measurement keep their order after the measured ones.
+func SortByLocalLatency(proposals []PricedServiceProposal) []PricedServiceProposal {
+	tmp := make([]PricedServiceProposal, len(proposals))
+	copy(tmp, proposals)
+
+	sort.SliceStable(tmp, func(i, j int) bool {
+		if tmp[i].LocalLatency == 0 || tmp[j].LocalLatency == 0 {
+			return tmp[j].LocalLatency == 0 && tmp[i].LocalLatency != 0
+		}
+		return tmp[i].LocalLatency < tmp[j].LocalLatency
+	})
+
+	return tmp
+}
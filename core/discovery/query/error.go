@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import "fmt"
+
+// Error describes invalid filter expression, pointing at the offending token.
+type Error struct {
+	// Pos is a 1-based character offset of the offending token.
+	Pos int
+	// Token is the offending token text, empty at the end of expression.
+	Token   string
+	Message string
+}
+
+// Error returns human readable error description.
+func (e *Error) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%s at end of expression", e.Message)
+	}
+	return fmt.Sprintf("%s at position %d near %q", e.Message, e.Pos, e.Token)
+}
+
+func errorAt(t token, format string, args ...interface{}) *Error {
+	return &Error{Pos: t.pos, Token: t.text, Message: fmt.Sprintf(format, args...)}
+}
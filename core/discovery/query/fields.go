@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindNumber
+	kindBool
+)
+
+func (k fieldKind) String() string {
+	switch k {
+	case kindNumber:
+		return "number"
+	case kindBool:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// Pricer resolves the price consumer pays for the proposal, it's used by price fields.
+type Pricer interface {
+	ProposalPrice(proposal market.ServiceProposal) (market.Price, error)
+}
+
+// field describes queryable proposal attribute. String values are selected lowercased,
+// number values are selected as float64.
+type field struct {
+	kind  fieldKind
+	value func(proposal market.ServiceProposal, price priceFunc) interface{}
+}
+
+type priceFunc func(proposal market.ServiceProposal) (market.Price, bool)
+
+var fields = map[string]field{
+	"provider":      stringField(func(p market.ServiceProposal) string { return p.ProviderID }),
+	"service_type":  stringField(func(p market.ServiceProposal) string { return p.ServiceType }),
+	"country":       stringField(func(p market.ServiceProposal) string { return p.Location.Country }),
+	"city":          stringField(func(p market.ServiceProposal) string { return p.Location.City }),
+	"continent":     stringField(func(p market.ServiceProposal) string { return p.Location.Continent }),
+	"ip_type":       stringField(func(p market.ServiceProposal) string { return p.Location.IPType }),
+	"isp":           stringField(func(p market.ServiceProposal) string { return p.Location.ISP }),
+	"asn":           numberField(func(p market.ServiceProposal) float64 { return float64(p.Location.ASN) }),
+	"compatibility": numberField(func(p market.ServiceProposal) float64 { return float64(p.Compatibility) }),
+	"quality":       numberField(func(p market.ServiceProposal) float64 { return p.Quality.Quality }),
+	"latency":       numberField(func(p market.ServiceProposal) float64 { return p.Quality.Latency }),
+	"bandwidth":     numberField(func(p market.ServiceProposal) float64 { return p.Quality.Bandwidth }),
+	"ipv6": {
+		kind: kindBool,
+		value: func(p market.ServiceProposal, _ priceFunc) interface{} {
+			return p.IPv6
+		},
+	},
+	"sessions": numberField(func(p market.ServiceProposal) float64 {
+		if p.Capacity == nil {
+			return 0
+		}
+		return float64(p.Capacity.Sessions)
+	}),
+	"load": numberField(func(p market.ServiceProposal) float64 {
+		if p.Capacity == nil {
+			return 0
+		}
+		return p.Capacity.Load
+	}),
+	"price.hour": priceField(func(price market.Price) *big.Int { return price.PricePerHour }),
+	"price.gib":  priceField(func(price market.Price) *big.Int { return price.PricePerGiB }),
+}
+
+func stringField(selector func(market.ServiceProposal) string) field {
+	return field{
+		kind: kindString,
+		value: func(p market.ServiceProposal, _ priceFunc) interface{} {
+			return strings.ToLower(selector(p))
+		},
+	}
+}
+
+func numberField(selector func(market.ServiceProposal) float64) field {
+	return field{
+		kind: kindNumber,
+		value: func(p market.ServiceProposal, _ priceFunc) interface{} {
+			return selector(p)
+		},
+	}
+}
+
+// priceField selects price amount in MYST, nil is selected if price is unknown.
+func priceField(selector func(market.Price) *big.Int) field {
+	return field{
+		kind: kindNumber,
+		value: func(p market.ServiceProposal, price priceFunc) interface{} {
+			if price == nil {
+				return nil
+			}
+			current, ok := price(p)
+			if !ok {
+				return nil
+			}
+			amount := selector(current)
+			if amount == nil {
+				return float64(0)
+			}
+			return crypto.BigMystToFloat(amount)
+		},
+	}
+}
+
+// Fields returns names of all queryable proposal fields.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
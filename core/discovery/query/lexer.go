@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenIn
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value string
+	pos   int
+}
+
+var keywords = map[string]tokenKind{
+	"and": tokenAnd,
+	"or":  tokenOr,
+	"not": tokenNot,
+	"in":  tokenIn,
+}
+
+// tokenize splits filter expression into tokens, positions are 1-based character offsets.
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+
+	input := []rune(expression)
+	for i := 0; i < len(input); {
+		r := input[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", pos: pos})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: pos})
+			i++
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if i+1 < len(input) && input[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, &Error{Pos: pos, Token: op, Message: "unexpected character"}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+			i += len(op)
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(input) && input[end] != r {
+				end++
+			}
+			if end == len(input) {
+				return nil, &Error{Pos: pos, Token: string(input[i:]), Message: "unterminated string"}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(input[i : end+1]), value: string(input[i+1 : end]), pos: pos})
+			i = end + 1
+		default:
+			end := i
+			for end < len(input) && !isDelimiter(input[end]) {
+				end++
+			}
+			text := string(input[i:end])
+			tokens = append(tokens, newWordToken(text, pos))
+			i = end
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(input) + 1}), nil
+}
+
+func newWordToken(text string, pos int) token {
+	if kind, ok := keywords[strings.ToLower(text)]; ok {
+		return token{kind: kind, text: text, value: strings.ToLower(text), pos: pos}
+	}
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		return token{kind: tokenNumber, text: text, value: text, pos: pos}
+	}
+	return token{kind: tokenWord, text: text, value: text, pos: pos}
+}
+
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("(),=!<>'\"", r)
+}
@@ -0,0 +1,293 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package query compiles proposal filter expressions into discovery reducer conditions.
+//
+// Expression grammar:
+//   expression = term { "or" term }
+//   term       = factor { "and" factor }
+//   factor     = "not" factor | "(" expression ")" | comparison
+//   comparison = field ( operator value | [ "not" ] "in" "(" value { "," value } ")" )
+//   operator   = "=" | "==" | "!=" | "<" | "<=" | ">" | ">="
+//
+// Example: country in (DE,NL) and quality >= 2 and price.gib < 0.1 and not provider in (0x1, 0x2)
+package query
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/discovery/reducer"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type condition = func(market.ServiceProposal) bool
+
+// Parse compiles filter expression into proposal condition.
+// Pricer is used to evaluate price fields, proposals with unknown price never match price comparisons.
+func Parse(expression string, pricer Pricer) (func(market.ServiceProposal) bool, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if pricer != nil {
+		p.price = func(proposal market.ServiceProposal) (market.Price, bool) {
+			price, err := pricer.ProposalPrice(proposal)
+			return price, err == nil
+		}
+	}
+
+	if p.peek().kind == tokenEOF {
+		return reducer.True, nil
+	}
+
+	cond, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, errorAt(t, "unexpected token")
+	}
+	return cond, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	price  priceFunc
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, errorAt(t, "expected %s", what)
+	}
+	return t, nil
+}
+
+func (p *parser) parseExpression() (condition, error) {
+	first, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []reducer.OrCondition{first}
+	for p.peek().kind == tokenOr {
+		p.next()
+		cond, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, cond)
+	}
+
+	if len(conditions) == 1 {
+		return first, nil
+	}
+	return reducer.Or(conditions...), nil
+}
+
+func (p *parser) parseTerm() (condition, error) {
+	first, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []reducer.AndCondition{first}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		cond, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, cond)
+	}
+
+	if len(conditions) == 1 {
+		return first, nil
+	}
+	return reducer.And(conditions...), nil
+}
+
+func (p *parser) parseFactor() (condition, error) {
+	switch p.peek().kind {
+	case tokenNot:
+		p.next()
+		cond, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return reducer.Not(cond), nil
+	case tokenLeftParen:
+		p.next()
+		cond, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRightParen, "closing parenthesis"); err != nil {
+			return nil, err
+		}
+		return cond, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *parser) parseComparison() (condition, error) {
+	name, err := p.expect(tokenWord, "field name")
+	if err != nil {
+		return nil, err
+	}
+	f, ok := fields[strings.ToLower(name.text)]
+	if !ok {
+		return nil, errorAt(name, "unknown field, expected one of: %s", strings.Join(Fields(), ", "))
+	}
+	selector := func(proposal market.ServiceProposal) interface{} {
+		return f.value(proposal, p.price)
+	}
+
+	switch t := p.peek(); t.kind {
+	case tokenIn:
+		p.next()
+		return p.parseIn(f, selector)
+	case tokenNot:
+		p.next()
+		if _, err := p.expect(tokenIn, "in"); err != nil {
+			return nil, err
+		}
+		cond, err := p.parseIn(f, selector)
+		if err != nil {
+			return nil, err
+		}
+		return reducer.Not(cond), nil
+	case tokenOperator:
+		p.next()
+		value, err := p.parseValue(f)
+		if err != nil {
+			return nil, err
+		}
+		return compare(f, selector, t, value)
+	default:
+		if f.kind == kindBool {
+			return reducer.Equal(selector, true), nil
+		}
+		return nil, errorAt(t, "expected operator")
+	}
+}
+
+func (p *parser) parseIn(f field, selector reducer.FieldSelector) (condition, error) {
+	if _, err := p.expect(tokenLeftParen, "opening parenthesis"); err != nil {
+		return nil, err
+	}
+
+	var values []interface{}
+	for {
+		value, err := p.parseValue(f)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		t := p.next()
+		if t.kind == tokenRightParen {
+			break
+		}
+		if t.kind != tokenComma {
+			return nil, errorAt(t, "expected comma or closing parenthesis")
+		}
+	}
+
+	return reducer.In(selector, values...), nil
+}
+
+func (p *parser) parseValue(f field) (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenWord, tokenNumber, tokenString:
+	default:
+		return nil, errorAt(t, "expected value")
+	}
+
+	switch f.kind {
+	case kindNumber:
+		if t.kind != tokenNumber {
+			return nil, errorAt(t, "expected number")
+		}
+		value, _ := strconv.ParseFloat(t.value, 64)
+		return value, nil
+	case kindBool:
+		value, err := strconv.ParseBool(t.value)
+		if err != nil {
+			return nil, errorAt(t, "expected boolean")
+		}
+		return value, nil
+	default:
+		return strings.ToLower(t.value), nil
+	}
+}
+
+func compare(f field, selector reducer.FieldSelector, op token, expected interface{}) (condition, error) {
+	if f.kind != kindNumber {
+		switch op.text {
+		case "=", "==":
+			return reducer.Equal(selector, expected), nil
+		case "!=":
+			return reducer.Not(reducer.Equal(selector, expected)), nil
+		default:
+			return nil, errorAt(op, "operator is not supported for %s field", f.kind)
+		}
+	}
+
+	// Number fields select nil when value is unknown, such values never match.
+	bound := expected.(float64)
+	var matches func(value float64) bool
+	switch op.text {
+	case "=", "==":
+		matches = func(value float64) bool { return value == bound }
+	case "!=":
+		matches = func(value float64) bool { return value != bound }
+	case "<":
+		matches = func(value float64) bool { return value < bound }
+	case "<=":
+		matches = func(value float64) bool { return value <= bound }
+	case ">":
+		matches = func(value float64) bool { return value > bound }
+	case ">=":
+		matches = func(value float64) bool { return value >= bound }
+	default:
+		return nil, errorAt(op, "unknown operator")
+	}
+
+	return reducer.Field(selector, func(value interface{}) bool {
+		number, ok := value.(float64)
+		return ok && matches(number)
+	}), nil
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+var (
+	proposalDE = market.ServiceProposal{
+		ProviderID:  "0x1",
+		ServiceType: "wireguard",
+		Location:    market.Location{Country: "DE", IPType: "residential"},
+		Quality:     market.Quality{Quality: 2.5, Latency: 30},
+		IPv6:        true,
+	}
+	proposalNL = market.ServiceProposal{
+		ProviderID:  "0x2",
+		ServiceType: "wireguard",
+		Location:    market.Location{Country: "NL", IPType: "hosting"},
+		Quality:     market.Quality{Quality: 1.5, Latency: 10},
+	}
+	proposalUS = market.ServiceProposal{
+		ProviderID:  "0x3",
+		ServiceType: "openvpn",
+		Location:    market.Location{Country: "US", IPType: "residential"},
+		Quality:     market.Quality{Quality: 3, Latency: 20},
+	}
+)
+
+type mockPricer struct {
+	prices map[string]market.Price
+}
+
+func (mp *mockPricer) ProposalPrice(proposal market.ServiceProposal) (market.Price, error) {
+	price, ok := mp.prices[proposal.Location.Country]
+	if !ok {
+		return market.Price{}, errors.New("no price")
+	}
+	return price, nil
+}
+
+func myst(amount float64) *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(amount), big.NewFloat(1e18)).Int(nil)
+	return wei
+}
+
+func Test_Parse_MatchesProposals(t *testing.T) {
+	pricer := &mockPricer{prices: map[string]market.Price{
+		"DE": {PricePerHour: myst(0.01), PricePerGiB: myst(0.05)},
+		"NL": {PricePerHour: myst(0.02), PricePerGiB: myst(0.2)},
+	}}
+
+	for _, tc := range []struct {
+		expression string
+		matches    []bool
+	}{
+		{"", []bool{true, true, true}},
+		{"country = de", []bool{true, false, false}},
+		{"country in (DE,NL)", []bool{true, true, false}},
+		{"country not in (DE, NL)", []bool{false, false, true}},
+		{"quality >= 2", []bool{true, false, true}},
+		{"latency < 20 or latency > 25", []bool{true, true, false}},
+		{"price.gib < 0.1", []bool{true, false, false}},
+		{"price.hour != 0.01", []bool{false, true, false}},
+		{"ipv6", []bool{true, false, false}},
+		{"ipv6 = false", []bool{false, true, true}},
+		{"not provider in (0x1, '0x3')", []bool{false, true, false}},
+		{"service_type = wireguard and (country = nl or quality > 2)", []bool{true, true, false}},
+		{"country in (DE,NL) and quality >= 2 and price.gib < 0.1 and not provider in (0x2)", []bool{true, false, false}},
+		{"NOT ip_type == \"hosting\" AND service_type != openvpn", []bool{true, false, false}},
+	} {
+		t.Run(tc.expression, func(t *testing.T) {
+			match, err := Parse(tc.expression, pricer)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.matches[0], match(proposalDE))
+			assert.Equal(t, tc.matches[1], match(proposalNL))
+			assert.Equal(t, tc.matches[2], match(proposalUS))
+		})
+	}
+}
+
+func Test_Parse_ReturnsErrorAtOffendingToken(t *testing.T) {
+	for _, tc := range []struct {
+		expression string
+		expected   Error
+	}{
+		{"countri = DE", Error{Pos: 1, Token: "countri"}},
+		{"country < DE", Error{Pos: 9, Token: "<"}},
+		{"quality >= high", Error{Pos: 12, Token: "high"}},
+		{"country in (DE NL)", Error{Pos: 16, Token: "NL"}},
+		{"country = DE and", Error{Pos: 17, Token: ""}},
+		{"(country = DE", Error{Pos: 14, Token: ""}},
+		{"country = DE)", Error{Pos: 13, Token: ")"}},
+		{"country = 'DE", Error{Pos: 11, Token: "'DE"}},
+		{"ipv6 = maybe", Error{Pos: 8, Token: "maybe"}},
+		{"country ! DE", Error{Pos: 9, Token: "!"}},
+	} {
+		t.Run(tc.expression, func(t *testing.T) {
+			_, err := Parse(tc.expression, nil)
+
+			var queryErr *Error
+			require.True(t, errors.As(err, &queryErr), "unexpected error: %v", err)
+			assert.Equal(t, tc.expected.Pos, queryErr.Pos)
+			assert.Equal(t, tc.expected.Token, queryErr.Token)
+		})
+	}
+}
+
+func Test_Sort_ByMultipleKeys(t *testing.T) {
+	proposals := []proposal.PricedServiceProposal{
+		{ServiceProposal: proposalNL, Price: market.Price{PricePerGiB: myst(0.2)}},
+		{ServiceProposal: proposalUS, Price: market.Price{PricePerGiB: myst(0.1)}},
+		{ServiceProposal: proposalDE, Price: market.Price{PricePerGiB: myst(0.1)}},
+	}
+
+	keys, err := ParseSort("price.gib, -quality")
+	require.NoError(t, err)
+	assert.Equal(t, []SortKey{{Field: "price.gib"}, {Field: "quality", Descending: true}}, keys)
+
+	sorted := Sort(proposals, keys)
+	assert.Equal(t, "0x3", sorted[0].ProviderID)
+	assert.Equal(t, "0x1", sorted[1].ProviderID)
+	assert.Equal(t, "0x2", sorted[2].ProviderID)
+	assert.Equal(t, "0x2", proposals[0].ProviderID)
+}
+
+func Test_Sort_ByReputation(t *testing.T) {
+	proposals := []proposal.PricedServiceProposal{
+		{ServiceProposal: proposalNL, Reputation: proposal.Reputation{Score: 0.5}},
+		{ServiceProposal: proposalUS, Reputation: proposal.Reputation{Score: 0.9}},
+		{ServiceProposal: proposalDE, Reputation: proposal.Reputation{Score: 0.2, Favourite: true}},
+	}
+
+	keys, err := ParseSort("-favourite,-score")
+	require.NoError(t, err)
+
+	sorted := Sort(proposals, keys)
+	assert.Equal(t, "0x1", sorted[0].ProviderID)
+	assert.Equal(t, "0x3", sorted[1].ProviderID)
+	assert.Equal(t, "0x2", sorted[2].ProviderID)
+
+	_, err = Parse("score > 0.5", nil)
+	assert.Error(t, err)
+}
+
+func Test_Sort_ByLocalLatency(t *testing.T) {
+	proposals := []proposal.PricedServiceProposal{
+		{ServiceProposal: proposalNL},
+		{ServiceProposal: proposalUS, LocalLatency: 90 * time.Millisecond},
+		{ServiceProposal: proposalDE, LocalLatency: 30 * time.Millisecond},
+	}
+
+	keys, err := ParseSort("local_latency")
+	require.NoError(t, err)
+
+	sorted := Sort(proposals, keys)
+	assert.Equal(t, "0x1", sorted[0].ProviderID)
+	assert.Equal(t, "0x3", sorted[1].ProviderID)
+	assert.Equal(t, "0x2", sorted[2].ProviderID)
+}
+
+func Test_ParseSort_ReturnsErrorAtOffendingKey(t *testing.T) {
+	keys, err := ParseSort("")
+	assert.NoError(t, err)
+	assert.Nil(t, keys)
+
+	_, err = ParseSort("quality,-speed")
+	var queryErr *Error
+	require.True(t, errors.As(err, &queryErr))
+	assert.Equal(t, 9, queryErr.Pos)
+	assert.Equal(t, "-speed", queryErr.Token)
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// SortKey defines single proposal sorting key.
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// sortOnlyFields are known to the consumer only, so proposals can be sorted by them, but not filtered.
+var sortOnlyFields = map[string]func(p proposal.PricedServiceProposal) interface{}{
+	"favourite": func(p proposal.PricedServiceProposal) interface{} { return p.Reputation.Favourite },
+	"score":     func(p proposal.PricedServiceProposal) interface{} { return p.Reputation.Score },
+	"local_latency": func(p proposal.PricedServiceProposal) interface{} {
+		if p.LocalLatency == 0 {
+			return nil
+		}
+		return float64(p.LocalLatency) / float64(time.Millisecond)
+	},
+}
+
+// SortFields returns names of all fields proposals can be sorted by.
+func SortFields() []string {
+	names := Fields()
+	for name := range sortOnlyFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseSort parses comma separated list of sort keys, e.g. "-quality,price.gib".
+// Keys prefixed with "-" sort proposals in descending order, e.g. "-score" ranks by personal provider reputation.
+func ParseSort(expression string) ([]SortKey, error) {
+	var keys []SortKey
+
+	pos := 1
+	for _, part := range strings.Split(expression, ",") {
+		text := strings.TrimSpace(part)
+		keyPos := pos + strings.Index(part, text)
+		pos += len([]rune(part)) + 1
+
+		if text == "" {
+			if strings.TrimSpace(expression) == "" {
+				return nil, nil
+			}
+			return nil, &Error{Pos: keyPos, Token: ",", Message: "empty sort key"}
+		}
+
+		key := SortKey{Field: strings.ToLower(text)}
+		if strings.HasPrefix(key.Field, "-") {
+			key.Field = strings.TrimPrefix(key.Field, "-")
+			key.Descending = true
+		}
+		_, known := fields[key.Field]
+		if _, sortOnly := sortOnlyFields[key.Field]; !known && !sortOnly {
+			return nil, &Error{Pos: keyPos, Token: text, Message: "unknown sort field, expected one of: " + strings.Join(SortFields(), ", ")}
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Sort sorts proposals by given keys, proposals having equal keys keep their order.
+// Proposals with unknown key values are placed last.
+func Sort(proposals []proposal.PricedServiceProposal, keys []SortKey) []proposal.PricedServiceProposal {
+	type sortable struct {
+		proposal proposal.PricedServiceProposal
+		values   []interface{}
+	}
+
+	items := make([]sortable, len(proposals))
+	for i, p := range proposals {
+		price := p.Price
+		priced := func(market.ServiceProposal) (market.Price, bool) {
+			return price, true
+		}
+
+		items[i].proposal = p
+		items[i].values = make([]interface{}, len(keys))
+		for k, key := range keys {
+			if value, ok := sortOnlyFields[key.Field]; ok {
+				items[i].values[k] = value(p)
+				continue
+			}
+			items[i].values[k] = fields[key.Field].value(p.ServiceProposal, priced)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		for k, key := range keys {
+			a, b := items[i].values[k], items[j].values[k]
+			if a == nil || b == nil {
+				if (a == nil) != (b == nil) {
+					return b == nil
+				}
+				continue
+			}
+
+			cmp := compareValues(a, b)
+			if cmp == 0 {
+				continue
+			}
+			if key.Descending {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+
+	sorted := make([]proposal.PricedServiceProposal, len(items))
+	for i := range items {
+		sorted[i] = items[i].proposal
+	}
+	return sorted
+}
+
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case float64:
+		b := b.(float64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case string:
+		return strings.Compare(a, b.(string))
+	case bool:
+		if a != b.(bool) {
+			if a {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
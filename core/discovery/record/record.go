@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package record
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// ErrInvalidSignature is returned when record is not signed by the provider of its proposal.
+var ErrInvalidSignature = errors.New("record is not signed by the proposal provider")
+
+// Record is a proposal signed by its provider, it is how proposals are exchanged by the peer to peer
+// discovery backends. Record which is already expired on creation announces that the proposal is unregistered.
+type Record struct {
+	Proposal  json.RawMessage `json:"proposal"`
+	IssuedAt  int64           `json:"issued_at"`
+	ExpiresAt int64           `json:"expires_at"`
+	Signature string          `json:"signature"`
+}
+
+// New creates the record of the proposal valid for the given ttl and signs it.
+func New(proposal market.ServiceProposal, ttl time.Duration, signer identity.Signer) (Record, error) {
+	proposalJSON, err := json.Marshal(proposal)
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to marshal proposal: %w", err)
+	}
+
+	now := time.Now()
+	r := Record{
+		Proposal:  proposalJSON,
+		IssuedAt:  now.UnixNano(),
+		ExpiresAt: now.Add(ttl).UnixNano(),
+	}
+
+	signature, err := signer.Sign(r.message())
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to sign proposal record: %w", err)
+	}
+	r.Signature = signature.Base64()
+
+	return r, nil
+}
+
+func (r Record) message() []byte {
+	return []byte(fmt.Sprintf("%s|%d|%d", r.Proposal, r.IssuedAt, r.ExpiresAt))
+}
+
+// Expired checks whether the record is expired at the given time.
+func (r Record) Expired(now time.Time) bool {
+	return now.UnixNano() >= r.ExpiresAt
+}
+
+// Verify checks the record is signed by the provider of the proposal and returns the proposal.
+func (r Record) Verify(verifierFactory identity.VerifierFactory) (market.ServiceProposal, error) {
+	var proposal market.ServiceProposal
+	if err := json.Unmarshal(r.Proposal, &proposal); err != nil {
+		return proposal, fmt.Errorf("failed to unmarshal proposal: %w", err)
+	}
+	if proposal.ProviderID == "" || proposal.ServiceType == "" {
+		return proposal, errors.New("proposal is missing provider or service type")
+	}
+
+	verifier := verifierFactory(identity.FromAddress(proposal.ProviderID))
+	if ok, _ := verifier.Verify(r.message(), identity.SignatureBase64(r.Signature)); !ok {
+		return proposal, ErrInvalidSignature
+	}
+
+	return proposal, nil
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package record
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+var testProposal = market.ServiceProposal{
+	ProviderID:  "0x1",
+	ServiceType: "wireguard",
+	Location:    market.Location{Country: "LT"},
+}
+
+func fakeVerifier(_ identity.Identity) identity.Verifier {
+	return &identity.VerifierFake{}
+}
+
+func Test_Record_Verify(t *testing.T) {
+	r, err := New(testProposal, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	proposal, err := r.Verify(fakeVerifier)
+	assert.NoError(t, err)
+	assert.Equal(t, testProposal.UniqueID(), proposal.UniqueID())
+	assert.False(t, r.Expired(time.Now()))
+
+	r.ExpiresAt = time.Now().Add(time.Hour).UnixNano()
+	_, err = r.Verify(fakeVerifier)
+	assert.Equal(t, ErrInvalidSignature, err)
+}
+
+func Test_Record_Expired(t *testing.T) {
+	r, err := New(testProposal, 0, &identity.SignerFake{})
+	assert.NoError(t, err)
+	assert.True(t, r.Expired(time.Now()))
+
+	_, err = r.Verify(fakeVerifier)
+	assert.NoError(t, err)
+}
+
+func Test_Record_VerifyRejectsIncompleteProposal(t *testing.T) {
+	r, err := New(market.ServiceProposal{ProviderID: "0x1"}, time.Minute, &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	_, err = r.Verify(fakeVerifier)
+	assert.Error(t, err)
+}
@@ -46,6 +46,11 @@ func LocationType(proposal market.ServiceProposal) interface{} {
 	return proposal.Location.IPType
 }
 
+// IPv6 selects IPv6 egress support from proposal
+func IPv6(proposal market.ServiceProposal) interface{} {
+	return proposal.IPv6
+}
+
 // AccessPolicy returns a matcher for checking if proposal allows given access policy
 func AccessPolicy(id, source string) func(market.ServiceProposal) bool {
 	return func(proposal market.ServiceProposal) bool {
@@ -72,3 +77,10 @@ func Unsupported() func(market.ServiceProposal) bool {
 		return proposal.IsSupported()
 	}
 }
+
+// NotFull filters out proposals of services which reject new sessions due to their load
+func NotFull() func(market.ServiceProposal) bool {
+	return func(proposal market.ServiceProposal) bool {
+		return proposal.Capacity == nil || !proposal.Capacity.Full
+	}
+}
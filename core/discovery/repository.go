@@ -19,6 +19,7 @@ package discovery
 
 import (
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
@@ -30,11 +31,17 @@ import (
 // repository provides proposals from multiple other repositories.
 type repository struct {
 	delegates []proposal.Repository
+	cache     *ProposalCache
+
+	stopOnce sync.Once
+	stopChan chan struct{}
 }
 
 // NewRepository constructs a new composite repository.
 func NewRepository() *repository {
-	return &repository{}
+	return &repository{
+		stopChan: make(chan struct{}),
+	}
 }
 
 // Add adds a delegate repositories from which proposals can be acquired.
@@ -42,6 +49,47 @@ func (c *repository) Add(repository proposal.Repository) {
 	c.delegates = append(c.delegates, repository)
 }
 
+// SetCache sets the cache which remembers proposals of delegates and serves them once delegates fail.
+func (c *repository) SetCache(cache *ProposalCache) {
+	c.cache = cache
+}
+
+// Start begins refreshing the cache with proposals of delegates.
+func (c *repository) Start() error {
+	if c.cache != nil {
+		go c.cacheLoop(cacheRefreshInterval)
+	}
+
+	return nil
+}
+
+// Stop ends refreshing the cache.
+func (c *repository) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+	})
+}
+
+func (c *repository) cacheLoop(interval time.Duration) {
+	for {
+		c.refreshCache()
+
+		select {
+		case <-c.stopChan:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (c *repository) refreshCache() {
+	proposals, err := c.proposals(&proposal.Filter{})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to fetch some proposals for cache")
+	}
+	c.cache.Store(proposals...)
+}
+
 // Proposal returns a single proposal by its ID.
 func (c *repository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
 	allErrors := utils.ErrorCollection{}
@@ -54,6 +102,13 @@ func (c *repository) Proposal(id market.ProposalID) (*market.ServiceProposal, er
 		allErrors.Add(err)
 	}
 
+	if c.cache != nil {
+		if serviceProposal, err := c.cache.Proposal(id); err == nil {
+			log.Warn().Err(allErrors.Error()).Msgf("Returning stale proposal %v from cache", id)
+			return serviceProposal, nil
+		}
+	}
+
 	return nil, allErrors.Error()
 }
 
@@ -62,6 +117,22 @@ func (c *repository) Proposals(filter *proposal.Filter) ([]market.ServiceProposa
 	log.Debug().
 		Interface("filter", filter).
 		Msgf("Retrieving proposals from %d repositories", len(c.delegates))
+	result, err := c.proposals(filter)
+
+	if c.cache != nil && len(result) == 0 && err != nil {
+		cached, cacheErr := c.cache.Proposals(filter)
+		if cacheErr == nil {
+			log.Warn().Err(err).Msgf("Returning %d stale proposals from cache", len(cached))
+			return cached, nil
+		}
+	}
+
+	log.Err(err).Msgf("Returning %d unique proposals", len(result))
+	return result, err
+}
+
+// proposals collects unique proposals of all delegates matching the filter.
+func (c *repository) proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
 	proposals := make([][]market.ServiceProposal, len(c.delegates))
 	errors := make([]error, len(c.delegates))
 
@@ -90,8 +161,6 @@ func (c *repository) Proposals(filter *proposal.Filter) ([]market.ServiceProposa
 
 	allErrors := utils.ErrorCollection{}
 	allErrors.Add(errors...)
-
-	log.Err(allErrors.Error()).Msgf("Returning %d unique proposals", len(result))
 	return result, allErrors.Error()
 }
 
@@ -102,6 +171,10 @@ func (c *repository) Countries(filter *proposal.Filter) (map[string]int, error)
 	for _, repo := range c.delegates {
 		repoCountries, err := repo.Countries(filter)
 		if err != nil {
+			if c.cache != nil {
+				log.Warn().Err(err).Msg("Returning proposal countries from cache")
+				return c.cache.Countries(filter)
+			}
 			return nil, err
 		}
 		for k, v := range repoCountries {
@@ -0,0 +1,208 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package selector
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+)
+
+// ErrNoCandidates indicates that no proposal fits the price caps.
+var ErrNoCandidates = errors.New("no providers within the price caps")
+
+// Weights defines the importance of proposal properties when picking a provider.
+// Only the ratio between weights matters.
+type Weights struct {
+	Quality   float64
+	Bandwidth float64
+	Latency   float64
+	PriceGiB  float64
+	PriceHour float64
+}
+
+// DefaultWeights prefers quality and speed while keeping the price in mind.
+var DefaultWeights = Weights{
+	Quality:   3,
+	Bandwidth: 2,
+	Latency:   2,
+	PriceGiB:  2,
+	PriceHour: 1,
+}
+
+func (w Weights) total() float64 {
+	return w.Quality + w.Bandwidth + w.Latency + w.PriceGiB + w.PriceHour
+}
+
+// Spec describes how to pick the best provider.
+type Spec struct {
+	// Weights are DefaultWeights when all zero.
+	Weights Weights
+	// MaxPricePerGiB excludes providers charging more per GiB, nil means no cap.
+	MaxPricePerGiB *big.Int
+	// MaxPricePerHour excludes providers charging more per hour, nil means no cap.
+	MaxPricePerHour *big.Int
+}
+
+// Validate checks if the spec is usable.
+func (s Spec) Validate() error {
+	w := s.Weights
+	if w.Quality < 0 || w.Bandwidth < 0 || w.Latency < 0 || w.PriceGiB < 0 || w.PriceHour < 0 {
+		return errors.New("weights can't be negative")
+	}
+	if s.MaxPricePerGiB != nil && s.MaxPricePerGiB.Sign() < 0 {
+		return errors.New("max price per GiB can't be negative")
+	}
+	if s.MaxPricePerHour != nil && s.MaxPricePerHour.Sign() < 0 {
+		return errors.New("max price per hour can't be negative")
+	}
+	return nil
+}
+
+// Candidate is a proposal scored by the selector.
+type Candidate struct {
+	Proposal proposal.PricedServiceProposal
+	// Score is in range [0, 1], higher is better.
+	Score float64
+}
+
+// Rank scores proposals within the price caps, best first.
+// Every property is scaled between the worst and the best value among the candidates.
+func (s Spec) Rank(proposals []proposal.PricedServiceProposal) []Candidate {
+	weights := s.Weights
+	if weights.total() == 0 {
+		weights = DefaultWeights
+	}
+
+	var within []proposal.PricedServiceProposal
+	for _, p := range proposals {
+		if s.withinCaps(p) {
+			within = append(within, p)
+		}
+	}
+
+	quality := newScale(within, func(p proposal.PricedServiceProposal) (float64, bool) {
+		return p.Quality.Quality, true
+	}, true)
+	bandwidth := newScale(within, func(p proposal.PricedServiceProposal) (float64, bool) {
+		return p.Quality.Bandwidth, p.Quality.Bandwidth > 0
+	}, true)
+	latency := newScale(within, latencyOf, false)
+	priceGiB := newScale(within, func(p proposal.PricedServiceProposal) (float64, bool) {
+		return toFloat(p.Price.PricePerGiB), true
+	}, false)
+	priceHour := newScale(within, func(p proposal.PricedServiceProposal) (float64, bool) {
+		return toFloat(p.Price.PricePerHour), true
+	}, false)
+
+	candidates := make([]Candidate, len(within))
+	for i, p := range within {
+		score := weights.Quality*quality.of(p) +
+			weights.Bandwidth*bandwidth.of(p) +
+			weights.Latency*latency.of(p) +
+			weights.PriceGiB*priceGiB.of(p) +
+			weights.PriceHour*priceHour.of(p)
+
+		candidates[i] = Candidate{Proposal: p, Score: score / weights.total()}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+// Select returns the best proposal within the price caps.
+func (s Spec) Select(proposals []proposal.PricedServiceProposal) (Candidate, error) {
+	candidates := s.Rank(proposals)
+	if len(candidates) == 0 {
+		return Candidate{}, ErrNoCandidates
+	}
+	return candidates[0], nil
+}
+
+func (s Spec) withinCaps(p proposal.PricedServiceProposal) bool {
+	if s.MaxPricePerGiB != nil && p.Price.PricePerGiB != nil && p.Price.PricePerGiB.Cmp(s.MaxPricePerGiB) > 0 {
+		return false
+	}
+	if s.MaxPricePerHour != nil && p.Price.PricePerHour != nil && p.Price.PricePerHour.Cmp(s.MaxPricePerHour) > 0 {
+		return false
+	}
+	return true
+}
+
+// latencyOf prefers round trip time measured by the consumer over the one reported by quality oracle.
+func latencyOf(p proposal.PricedServiceProposal) (float64, bool) {
+	if p.LocalLatency > 0 {
+		return float64(p.LocalLatency.Milliseconds()), true
+	}
+	return p.Quality.Latency, p.Quality.Latency > 0
+}
+
+func toFloat(v *big.Int) float64 {
+	if v == nil {
+		return 0
+	}
+	f, _ := new(big.Float).SetInt(v).Float64()
+	return f
+}
+
+// scale maps property value to range [0, 1] where 1 is the best among candidates and unknown values score 0.
+type scale struct {
+	value          func(p proposal.PricedServiceProposal) (float64, bool)
+	higherIsBetter bool
+	min, max       float64
+}
+
+func newScale(proposals []proposal.PricedServiceProposal, value func(p proposal.PricedServiceProposal) (float64, bool), higherIsBetter bool) scale {
+	s := scale{value: value, higherIsBetter: higherIsBetter}
+
+	known := false
+	for _, p := range proposals {
+		v, ok := value(p)
+		if !ok {
+			continue
+		}
+		if !known || v < s.min {
+			s.min = v
+		}
+		if !known || v > s.max {
+			s.max = v
+		}
+		known = true
+	}
+	return s
+}
+
+func (s scale) of(p proposal.PricedServiceProposal) float64 {
+	v, ok := s.value(p)
+	if !ok {
+		return 0
+	}
+	if s.max == s.min {
+		return 1
+	}
+
+	normalized := (v - s.min) / (s.max - s.min)
+	if !s.higherIsBetter {
+		normalized = 1 - normalized
+	}
+	return normalized
+}
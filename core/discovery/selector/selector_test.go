@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package selector
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func testProposal(providerID string, quality, bandwidth, latency float64, priceGiB, priceHour int64) proposal.PricedServiceProposal {
+	return proposal.PricedServiceProposal{
+		ServiceProposal: market.ServiceProposal{
+			ProviderID: providerID,
+			Quality:    market.Quality{Quality: quality, Bandwidth: bandwidth, Latency: latency},
+		},
+		Price: market.Price{PricePerGiB: big.NewInt(priceGiB), PricePerHour: big.NewInt(priceHour)},
+	}
+}
+
+func providerIDs(candidates []Candidate) []string {
+	var ids []string
+	for _, c := range candidates {
+		ids = append(ids, c.Proposal.ProviderID)
+	}
+	return ids
+}
+
+func Test_Spec_Rank(t *testing.T) {
+	proposals := []proposal.PricedServiceProposal{
+		testProposal("0x1", 1, 10, 200, 10, 10),
+		testProposal("0x2", 3, 100, 50, 50, 10),
+		testProposal("0x3", 2, 50, 100, 20, 10),
+	}
+
+	tests := []struct {
+		name     string
+		spec     Spec
+		expected []string
+	}{
+		{
+			name:     "default weights",
+			spec:     Spec{},
+			expected: []string{"0x2", "0x3", "0x1"},
+		},
+		{
+			name:     "price only",
+			spec:     Spec{Weights: Weights{PriceGiB: 1}},
+			expected: []string{"0x1", "0x3", "0x2"},
+		},
+		{
+			name:     "price cap",
+			spec:     Spec{MaxPricePerGiB: big.NewInt(20)},
+			expected: []string{"0x3", "0x1"},
+		},
+		{
+			name:     "nothing within caps",
+			spec:     Spec{MaxPricePerHour: big.NewInt(5)},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, providerIDs(tt.spec.Rank(proposals)))
+		})
+	}
+}
+
+func Test_Spec_RankPrefersLocalLatencyAndPenalizesUnknown(t *testing.T) {
+	measured := testProposal("0x1", 2, 0, 300, 10, 10)
+	measured.LocalLatency = 20 * time.Millisecond
+	unknown := testProposal("0x2", 2, 0, 0, 10, 10)
+	average := testProposal("0x3", 2, 0, 60, 10, 10)
+	slow := testProposal("0x4", 2, 0, 100, 10, 10)
+
+	candidates := Spec{Weights: Weights{Latency: 1}}.Rank([]proposal.PricedServiceProposal{unknown, slow, average, measured})
+
+	assert.Equal(t, []string{"0x1", "0x3", "0x2", "0x4"}, providerIDs(candidates))
+	assert.Equal(t, []float64{1, 0.5, 0, 0}, []float64{candidates[0].Score, candidates[1].Score, candidates[2].Score, candidates[3].Score})
+}
+
+func Test_Spec_Select(t *testing.T) {
+	_, err := Spec{}.Select(nil)
+	assert.Equal(t, ErrNoCandidates, err)
+
+	best, err := Spec{}.Select([]proposal.PricedServiceProposal{testProposal("0x1", 1, 1, 1, 1, 1)})
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", best.Proposal.ProviderID)
+	assert.Equal(t, 1.0, best.Score)
+}
+
+func Test_Spec_Validate(t *testing.T) {
+	assert.NoError(t, Spec{}.Validate())
+	assert.Error(t, Spec{Weights: Weights{Quality: -1}}.Validate())
+	assert.Error(t, Spec{MaxPricePerGiB: big.NewInt(-1)}.Validate())
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stream
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// subscriberBufferSize is the count of events kept for a slow subscriber before they are dropped.
+const subscriberBufferSize = 100
+
+// EventType represents the kind of proposal change.
+type EventType string
+
+const (
+	// EventAdded is sent for a newly announced proposal.
+	EventAdded EventType = "added"
+	// EventUpdated is sent for a re-announced proposal.
+	EventUpdated EventType = "updated"
+	// EventRemoved is sent for a de-announced proposal.
+	EventRemoved EventType = "removed"
+)
+
+// Event represents a change of a proposal matching the subscriber filter.
+type Event struct {
+	Type     EventType
+	Proposal proposal.PricedServiceProposal
+}
+
+type proposalRepository interface {
+	EnrichProposalWithPrice(in market.ServiceProposal) (proposal.PricedServiceProposal, error)
+	Matches(filter *proposal.Filter, p proposal.PricedServiceProposal) (bool, error)
+}
+
+type subscriber struct {
+	filter *proposal.Filter
+	events chan Event
+}
+
+// Stream fans discovery proposal changes out to the subscribers, each getting only the ones matching its filter.
+type Stream struct {
+	repository proposalRepository
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+// NewStream creates proposal change stream.
+func NewStream(repository proposalRepository) *Stream {
+	return &Stream{
+		repository:  repository,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Subscribe subscribes to proposal events of event bus.
+func (s *Stream) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(discovery.AppTopicProposalAdded, s.consumeProposalAdded); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(discovery.AppTopicProposalUpdated, s.consumeProposalUpdated); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(discovery.AppTopicProposalRemoved, s.consumeProposalRemoved)
+}
+
+// Watch returns the channel of proposal changes matching the filter and the function to stop watching.
+// Events are dropped while the channel is full.
+func (s *Stream) Watch(filter *proposal.Filter) (<-chan Event, func()) {
+	sub := &subscriber{
+		filter: filter,
+		events: make(chan Event, subscriberBufferSize),
+	}
+
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return sub.events, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			delete(s.subscribers, sub)
+			close(sub.events)
+		})
+	}
+}
+
+func (s *Stream) consumeProposalAdded(p market.ServiceProposal) {
+	s.publish(EventAdded, p)
+}
+
+func (s *Stream) consumeProposalUpdated(p market.ServiceProposal) {
+	s.publish(EventUpdated, p)
+}
+
+func (s *Stream) consumeProposalRemoved(p market.ServiceProposal) {
+	s.publish(EventRemoved, p)
+}
+
+func (s *Stream) publish(eventType EventType, p market.ServiceProposal) {
+	s.mu.Lock()
+	subscribers := make([]*subscriber, 0, len(s.subscribers))
+	for sub := range s.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	s.mu.Unlock()
+
+	if len(subscribers) == 0 {
+		return
+	}
+
+	priced, err := s.repository.EnrichProposalWithPrice(p)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not add pricing info to proposal %v(%v)", p.ProviderID, p.ServiceType)
+		return
+	}
+
+	event := Event{Type: eventType, Proposal: priced}
+	for _, sub := range subscribers {
+		matches, err := s.repository.Matches(sub.filter, priced)
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not match proposal to the stream filter")
+			continue
+		}
+		if matches {
+			s.send(sub, event)
+		}
+	}
+}
+
+func (s *Stream) send(sub *subscriber, event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Subscriber could have stopped watching while the proposal was matched.
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+
+	select {
+	case sub.events <- event:
+	default:
+		log.Warn().Msgf("Proposal stream subscriber is too slow, dropping %s event of %s", event.Type, event.Proposal.ProviderID)
+	}
+}
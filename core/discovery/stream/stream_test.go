@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stream
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/apidiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/market/mysterium"
+)
+
+type mockRepository struct{}
+
+func (m *mockRepository) EnrichProposalWithPrice(in market.ServiceProposal) (proposal.PricedServiceProposal, error) {
+	return proposal.PricedServiceProposal{ServiceProposal: in}, nil
+}
+
+func (m *mockRepository) Matches(filter *proposal.Filter, p proposal.PricedServiceProposal) (bool, error) {
+	return filter.Matches(p.ServiceProposal), nil
+}
+
+func Test_Stream_DeliversMatchingEvents(t *testing.T) {
+	stream := NewStream(&mockRepository{})
+	events, stop := stream.Watch(&proposal.Filter{LocationCountry: "JP"})
+
+	stream.consumeProposalAdded(market.ServiceProposal{ProviderID: "0x1", Location: market.Location{Country: "DE"}})
+	stream.consumeProposalAdded(market.ServiceProposal{ProviderID: "0x2", Location: market.Location{Country: "JP"}})
+	stream.consumeProposalRemoved(market.ServiceProposal{ProviderID: "0x2", Location: market.Location{Country: "JP"}})
+
+	event := <-events
+	assert.Equal(t, EventAdded, event.Type)
+	assert.Equal(t, "0x2", event.Proposal.ProviderID)
+	event = <-events
+	assert.Equal(t, EventRemoved, event.Type)
+
+	stop()
+	_, open := <-events
+	assert.False(t, open)
+
+	// Stopped subscriber does not get events anymore.
+	stream.consumeProposalUpdated(market.ServiceProposal{ProviderID: "0x2", Location: market.Location{Country: "JP"}})
+	stop()
+}
+
+func Test_Stream_DropsEventsOfSlowSubscriber(t *testing.T) {
+	stream := NewStream(&mockRepository{})
+	events, stop := stream.Watch(&proposal.Filter{})
+	defer stop()
+
+	for i := 0; i < subscriberBufferSize+10; i++ {
+		stream.consumeProposalUpdated(market.ServiceProposal{ProviderID: "0x1"})
+	}
+
+	assert.Len(t, events, subscriberBufferSize)
+}
+
+func Test_Stream_DeliversChangesOfAPIDiscovery(t *testing.T) {
+	bus := eventbus.New()
+	stream := NewStream(&mockRepository{})
+	assert.NoError(t, stream.Subscribe(bus))
+	events, stop := stream.Watch(&proposal.Filter{})
+	defer stop()
+
+	api := &mockDiscoveryAPI{proposals: []market.ServiceProposal{{ProviderID: "0x1", ServiceType: "wireguard"}}}
+	repository := apidiscovery.NewRepository(api, bus, 10*time.Millisecond)
+	assert.NoError(t, repository.Start())
+	defer repository.Stop()
+
+	// Proposals known on the first fetch are not changes.
+	assert.Eventually(t, func() bool { return api.queryCount() > 0 }, time.Second, time.Millisecond)
+	api.setProposals([]market.ServiceProposal{{ProviderID: "0x2", ServiceType: "wireguard"}})
+
+	received := make(map[EventType]string)
+	for len(received) < 2 {
+		select {
+		case event := <-events:
+			received[event.Type] = event.Proposal.ProviderID
+		case <-time.After(2 * time.Second):
+			t.Fatalf("proposal events were not received, got: %v", received)
+		}
+	}
+	assert.Equal(t, map[EventType]string{EventAdded: "0x2", EventRemoved: "0x1"}, received)
+}
+
+type mockDiscoveryAPI struct {
+	mu        sync.Mutex
+	proposals []market.ServiceProposal
+	queries   int
+}
+
+func (m *mockDiscoveryAPI) queryCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.queries
+}
+
+func (m *mockDiscoveryAPI) setProposals(proposals []market.ServiceProposal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.proposals = proposals
+}
+
+func (m *mockDiscoveryAPI) QueryProposals(_ mysterium.ProposalsQuery) ([]market.ServiceProposal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queries++
+	return m.proposals, nil
+}
+
+func (m *mockDiscoveryAPI) QueryCountries(_ mysterium.ProposalsQuery) (map[string]int, error) {
+	return nil, nil
+}
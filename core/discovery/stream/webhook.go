@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stream
+
+import (
+	"errors"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/gofrs/uuid"
+)
+
+const webhookBucketName = "proposal-webhooks"
+
+// ErrWebhookNotFound indicates that webhook with the requested id does not exist.
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+type persistentStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+// Webhook is the URL proposal changes matching the filter are delivered to.
+type Webhook struct {
+	ID  string `storm:"id"`
+	URL string
+	// Filter keeps URL encoded query parameters of proposal listing to match proposals by.
+	Filter    string
+	CreatedAt time.Time
+}
+
+// WebhookStorage keeps registered proposal webhooks.
+type WebhookStorage struct {
+	storage persistentStorage
+}
+
+// NewWebhookStorage creates proposal webhook storage.
+func NewWebhookStorage(storage persistentStorage) *WebhookStorage {
+	return &WebhookStorage{storage: storage}
+}
+
+// List returns all registered webhooks.
+func (ws *WebhookStorage) List() ([]Webhook, error) {
+	var webhooks []Webhook
+	if err := ws.storage.GetAllFrom(webhookBucketName, &webhooks); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// Add registers a new webhook.
+func (ws *WebhookStorage) Add(url, filter string) (Webhook, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	webhook := Webhook{
+		ID:        id.String(),
+		URL:       url,
+		Filter:    filter,
+		CreatedAt: time.Now().UTC(),
+	}
+	return webhook, ws.storage.Store(webhookBucketName, &webhook)
+}
+
+// Delete removes the webhook.
+func (ws *WebhookStorage) Delete(id string) error {
+	err := ws.storage.Delete(webhookBucketName, &Webhook{ID: id})
+	if errors.Is(err, storm.ErrNotFound) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
@@ -55,20 +55,29 @@ func (r *DBResolver) DetectLocation() (loc locationstate.Location, err error) {
 	}
 
 	ip := net.ParseIP(ipAddress)
+	country, err := r.ResolveCountry(ip)
+	if err != nil {
+		return loc, err
+	}
+
+	loc.IP = ip.String()
+	loc.Country = country
+	return loc, nil
+}
+
+// ResolveCountry resolves country of the given IP-address.
+func (r *DBResolver) ResolveCountry(ip net.IP) (string, error) {
 	countryRecord, err := r.dbReader.Country(ip)
 	if err != nil {
-		return loc, errors.Wrap(err, "failed to get a country")
+		return "", errors.Wrap(err, "failed to get a country")
 	}
 
 	country := countryRecord.Country.IsoCode
 	if country == "" {
 		country = countryRecord.RegisteredCountry.IsoCode
 		if country == "" {
-			return loc, errors.New("failed to resolve country")
+			return "", errors.New("failed to resolve country")
 		}
 	}
-
-	loc.IP = ip.String()
-	loc.Country = country
-	return loc, nil
+	return country, nil
 }
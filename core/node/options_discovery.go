@@ -29,6 +29,8 @@ const (
 	DiscoveryTypeBroker = DiscoveryType("broker")
 	// DiscoveryTypeDHT defines type which discovers proposals through DHT (Distributed Hash Table).
 	DiscoveryTypeDHT = DiscoveryType("dht")
+	// DiscoveryTypeMDNS defines type which discovers proposals on the local network through mDNS/DNS-SD.
+	DiscoveryTypeMDNS = DiscoveryType("mdns")
 )
 
 // OptionsDiscovery describes possible parameters of discovery configuration.
@@ -0,0 +1,243 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/asdine/storm/v3"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const (
+	localPolicyBucket = "local-access-policies"
+	localPolicySource = "local"
+)
+
+var (
+	// ErrLocalPolicyNotFound is returned when local policy with the given ID doesn't exist.
+	ErrLocalPolicyNotFound = errors.New("local access policy not found")
+	// ErrLocalPolicyReadOnly is returned when trying to modify policy loaded from file.
+	ErrLocalPolicyReadOnly = errors.New("local access policy is loaded from file and can't be modified")
+)
+
+type persistentStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+type localPolicy struct {
+	ID    string `storm:"id"`
+	Rules market.AccessPolicyRuleSet
+}
+
+// LocalPolicies keeps access policies defined by the provider, they are applied to every provided service.
+type LocalPolicies struct {
+	lock        sync.Mutex
+	storage     persistentStorage
+	files       []market.AccessPolicyRuleSet
+	subscribers []*Repository
+}
+
+// NewLocalPolicies creates local policies from the given files and policies persisted in the storage.
+func NewLocalPolicies(storage persistentStorage, files []string) (*LocalPolicies, error) {
+	lp := &LocalPolicies{
+		storage: storage,
+	}
+
+	for _, file := range files {
+		rules, err := readPolicyFile(file)
+		if err != nil {
+			return nil, err
+		}
+		lp.files = append(lp.files, rules)
+	}
+
+	return lp, nil
+}
+
+func readPolicyFile(file string) (market.AccessPolicyRuleSet, error) {
+	var rules market.AccessPolicyRuleSet
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return rules, fmt.Errorf("could not read access policy file %s: %w", file, err)
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("could not parse access policy file %s: %w", file, err)
+	}
+	if err := ValidateRuleSet(rules); err != nil {
+		return rules, fmt.Errorf("invalid access policy file %s: %w", file, err)
+	}
+
+	return rules, nil
+}
+
+// ValidateRuleSet checks whether the policy is identified and all of its rules are valid.
+func ValidateRuleSet(rules market.AccessPolicyRuleSet) error {
+	if rules.ID == "" {
+		return errors.New("policy ID is required")
+	}
+	if len(rules.Allow) == 0 && len(rules.Deny) == 0 {
+		return errors.New("policy has no rules")
+	}
+	for _, rule := range rules.Allow {
+		if err := ValidateRule(rule); err != nil {
+			return err
+		}
+	}
+	for _, rule := range rules.Deny {
+		if err := ValidateRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns all local policies.
+func (lp *LocalPolicies) List() ([]market.AccessPolicyRuleSet, error) {
+	lp.lock.Lock()
+	defer lp.lock.Unlock()
+
+	return lp.list()
+}
+
+func (lp *LocalPolicies) list() ([]market.AccessPolicyRuleSet, error) {
+	var stored []localPolicy
+	if err := lp.storage.GetAllFrom(localPolicyBucket, &stored); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return nil, err
+	}
+
+	policies := make([]market.AccessPolicyRuleSet, 0, len(lp.files)+len(stored))
+	policies = append(policies, lp.files...)
+	for _, p := range stored {
+		policies = append(policies, p.Rules)
+	}
+	return policies, nil
+}
+
+// Get returns local policy by its ID.
+func (lp *LocalPolicies) Get(id string) (market.AccessPolicyRuleSet, error) {
+	policies, err := lp.List()
+	if err != nil {
+		return market.AccessPolicyRuleSet{}, err
+	}
+
+	for _, rules := range policies {
+		if rules.ID == id {
+			return rules, nil
+		}
+	}
+	return market.AccessPolicyRuleSet{}, ErrLocalPolicyNotFound
+}
+
+// Save creates a new local policy or replaces the existing one, services apply the change to new sessions.
+func (lp *LocalPolicies) Save(rules market.AccessPolicyRuleSet) error {
+	if err := ValidateRuleSet(rules); err != nil {
+		return err
+	}
+
+	lp.lock.Lock()
+	defer lp.lock.Unlock()
+
+	if lp.isFromFile(rules.ID) {
+		return ErrLocalPolicyReadOnly
+	}
+	if err := lp.storage.Store(localPolicyBucket, &localPolicy{ID: rules.ID, Rules: rules}); err != nil {
+		return err
+	}
+
+	for _, subscriber := range lp.subscribers {
+		subscriber.SetPolicyRules(LocalPolicy(rules.ID), rules)
+	}
+	return nil
+}
+
+// Delete removes local policy by its ID.
+func (lp *LocalPolicies) Delete(id string) error {
+	lp.lock.Lock()
+	defer lp.lock.Unlock()
+
+	if lp.isFromFile(id) {
+		return ErrLocalPolicyReadOnly
+	}
+	err := lp.storage.Delete(localPolicyBucket, &localPolicy{ID: id})
+	if errors.Is(err, storm.ErrNotFound) {
+		return ErrLocalPolicyNotFound
+	} else if err != nil {
+		return err
+	}
+
+	for _, subscriber := range lp.subscribers {
+		subscriber.RemovePolicy(LocalPolicy(id))
+	}
+	return nil
+}
+
+// SubscribePolicies adds all local policies to repository and keeps them in sync until unsubscribed.
+func (lp *LocalPolicies) SubscribePolicies(repository *Repository) error {
+	lp.lock.Lock()
+	defer lp.lock.Unlock()
+
+	policies, err := lp.list()
+	if err != nil {
+		return err
+	}
+
+	for _, rules := range policies {
+		repository.SetPolicyRules(LocalPolicy(rules.ID), rules)
+	}
+	lp.subscribers = append(lp.subscribers, repository)
+	return nil
+}
+
+// UnsubscribePolicies stops syncing local policies to repository.
+func (lp *LocalPolicies) UnsubscribePolicies(repository *Repository) {
+	lp.lock.Lock()
+	defer lp.lock.Unlock()
+
+	for i, subscriber := range lp.subscribers {
+		if subscriber == repository {
+			lp.subscribers = append(lp.subscribers[:i], lp.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (lp *LocalPolicies) isFromFile(id string) bool {
+	for _, rules := range lp.files {
+		if rules.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalPolicy converts given ID of local policy to policy.
+func LocalPolicy(id string) market.AccessPolicy {
+	return market.AccessPolicy{
+		ID:     id,
+		Source: localPolicySource,
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func Test_LocalPolicies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localPoliciesTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "isp.json")
+	err = ioutil.WriteFile(file, []byte(`{"id": "isp", "deny": [{"type": "protocol", "value": "smtp"}]}`), 0600)
+	assert.NoError(t, err)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	local, err := NewLocalPolicies(bolt, []string{file})
+	assert.NoError(t, err)
+
+	repo := NewRepository()
+	assert.NoError(t, local.SubscribePolicies(repo))
+	assert.Equal(t, []market.AccessPolicy{LocalPolicy("isp")}, repo.Policies())
+
+	countries := market.AccessPolicyRuleSet{
+		ID:    "countries",
+		Allow: []market.AccessRule{{Type: market.AccessPolicyTypeConsumerCountry, Value: "DE"}},
+	}
+	assert.NoError(t, local.Save(countries))
+	assert.False(t, repo.IsCountryAllowed("US"))
+
+	assert.Error(t, local.Save(market.AccessPolicyRuleSet{ID: "empty"}))
+	assert.Equal(t, ErrLocalPolicyReadOnly, local.Save(market.AccessPolicyRuleSet{
+		ID:    "isp",
+		Allow: []market.AccessRule{{Type: market.AccessPolicyTypeConsumerCountry, Value: "DE"}},
+	}))
+	assert.Equal(t, ErrLocalPolicyReadOnly, local.Delete("isp"))
+
+	policies, err := local.List()
+	assert.NoError(t, err)
+	assert.Len(t, policies, 2)
+
+	got, err := local.Get("countries")
+	assert.NoError(t, err)
+	assert.Equal(t, countries, got)
+
+	assert.NoError(t, local.Delete("countries"))
+	assert.True(t, repo.IsCountryAllowed("US"))
+	assert.Equal(t, ErrLocalPolicyNotFound, local.Delete("countries"))
+	_, err = local.Get("countries")
+	assert.Equal(t, ErrLocalPolicyNotFound, err)
+
+	local.UnsubscribePolicies(repo)
+	assert.NoError(t, local.Save(countries))
+	assert.True(t, repo.IsCountryAllowed("US"))
+
+	_, err = NewLocalPolicies(bolt, []string{filepath.Join(dir, "missing.json")})
+	assert.Error(t, err)
+}
@@ -19,9 +19,13 @@ package policy
 
 import (
 	"fmt"
+	"net"
 	"strings"
 	"sync"
 
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 )
@@ -60,6 +64,19 @@ func (r *Repository) SetPolicyRules(policy market.AccessPolicy, policyRules mark
 	}
 }
 
+// RemovePolicy removes policy and it's items from repository
+func (r *Repository) RemovePolicy(policy market.AccessPolicy) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, item := range r.items {
+		if item.policy == policy {
+			r.items = append(r.items[:i], r.items[i+1:]...)
+			return
+		}
+	}
+}
+
 // Policies list policies in repository
 func (r *Repository) Policies() []market.AccessPolicy {
 	r.lock.RLock()
@@ -121,6 +138,10 @@ func (r *Repository) IsIdentityAllowed(identity identity.Identity) bool {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
+	if r.isDenied(market.AccessPolicyTypeIdentity, identity.Address) {
+		return false
+	}
+
 	isAllowedByDefault := true
 	for _, item := range r.items {
 		for _, rule := range item.rules.Allow {
@@ -136,6 +157,24 @@ func (r *Repository) IsIdentityAllowed(identity identity.Identity) bool {
 	return isAllowedByDefault
 }
 
+// IdentityPolicies returns IDs of policies which allow given identity explicitly
+func (r *Repository) IdentityPolicies(identity identity.Identity) []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	policyIDs := make([]string, 0)
+	for _, item := range r.items {
+		for _, rule := range item.rules.Allow {
+			if rule.Type == market.AccessPolicyTypeIdentity && identity.Address == rule.Value {
+				policyIDs = append(policyIDs, item.rules.ID)
+				break
+			}
+		}
+	}
+
+	return policyIDs
+}
+
 // HasDNSRules returns flag if any DNS rules are applied
 func (r *Repository) HasDNSRules() bool {
 	r.lock.RLock()
@@ -155,11 +194,53 @@ func (r *Repository) HasDNSRules() bool {
 	return false
 }
 
+// HasDNSDenyRules returns flag if any DNS rules deny hosts
+func (r *Repository) HasDNSDenyRules() bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, item := range r.items {
+		for _, rule := range item.rules.Deny {
+			if rule.Type == market.AccessPolicyTypeDNSZone || rule.Type == market.AccessPolicyTypeDNSHostname {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsHostDenied returns flag if given FQDN host is explicitly denied by rules
+func (r *Repository) IsHostDenied(host string) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.isHostDenied(host)
+}
+
+func (r *Repository) isHostDenied(host string) bool {
+	for _, item := range r.items {
+		for _, rule := range item.rules.Deny {
+			if rule.Type == market.AccessPolicyTypeDNSZone && strings.HasSuffix(host, rule.Value) {
+				return true
+			}
+			if rule.Type == market.AccessPolicyTypeDNSHostname && host == rule.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // IsHostAllowed returns flag if given FQDN host should be allowed by rules
 func (r *Repository) IsHostAllowed(host string) bool {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
+	if r.isHostDenied(host) {
+		return false
+	}
+
 	isAllowedByDefault := true
 	for _, item := range r.items {
 		for _, rule := range item.rules.Allow {
@@ -181,6 +262,92 @@ func (r *Repository) IsHostAllowed(host string) bool {
 	return isAllowedByDefault
 }
 
+// IsCountryAllowed returns flag if consumer from the given country should be allowed by rules
+func (r *Repository) IsCountryAllowed(country string) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	country = strings.ToUpper(country)
+	if r.isDenied(market.AccessPolicyTypeConsumerCountry, country) {
+		return false
+	}
+
+	isAllowedByDefault := true
+	for _, item := range r.items {
+		for _, rule := range item.rules.Allow {
+			if rule.Type == market.AccessPolicyTypeConsumerCountry {
+				isAllowedByDefault = false
+				if strings.ToUpper(rule.Value) == country {
+					return true
+				}
+			}
+		}
+	}
+
+	return isAllowedByDefault
+}
+
+// DestinationRules returns firewall rules restricting destinations of consumer traffic
+func (r *Repository) DestinationRules() (allow, deny []firewall.DestinationRule) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, item := range r.items {
+		allow = append(allow, destinationRules(item.rules.ID, item.rules.Allow)...)
+		deny = append(deny, destinationRules(item.rules.ID, item.rules.Deny)...)
+	}
+
+	return allow, deny
+}
+
+// IsDestinationAllowed returns flag if consumer traffic to the given destination should be allowed by rules
+func (r *Repository) IsDestinationAllowed(ip net.IP, protocol string, port int) bool {
+	allow, deny := r.DestinationRules()
+	for _, rule := range deny {
+		if rule.Matches(ip, protocol, port) {
+			return false
+		}
+	}
+
+	if len(allow) == 0 {
+		return true
+	}
+	for _, rule := range allow {
+		if rule.Matches(ip, protocol, port) {
+			return true
+		}
+	}
+	return false
+}
+
+func destinationRules(policyID string, rules []market.AccessRule) []firewall.DestinationRule {
+	var res []firewall.DestinationRule
+	for _, rule := range rules {
+		if !isDestinationRule(rule) {
+			continue
+		}
+
+		destinations, err := ParseDestinationRule(rule)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Skipping invalid rule of access policy %s", policyID)
+			continue
+		}
+		res = append(res, destinations...)
+	}
+	return res
+}
+
+func (r *Repository) isDenied(ruleType, value string) bool {
+	for _, item := range r.items {
+		for _, rule := range item.rules.Deny {
+			if rule.Type == ruleType && strings.EqualFold(rule.Value, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (r *Repository) findItemFor(policy market.AccessPolicy) (*listItem, error) {
 	for i, item := range r.items {
 		if item.policy == policy {
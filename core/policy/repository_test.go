@@ -18,8 +18,10 @@
 package policy
 
 import (
+	"net"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/stretchr/testify/assert"
@@ -133,6 +135,127 @@ func Test_Repository_Rules(t *testing.T) {
 	assert.Equal(t, []market.AccessPolicyRuleSet{policyOneRules, policyTwoRules}, repo.Rules())
 }
 
+func Test_Repository_IdentityPolicies(t *testing.T) {
+	repo := createEmptyRepo()
+	assert.Equal(t, []string{}, repo.IdentityPolicies(identity.FromAddress("0x1")))
+
+	repo = createFullRepo()
+	assert.Equal(t, []string{"1"}, repo.IdentityPolicies(identity.FromAddress("0x1")))
+	assert.Equal(t, []string{}, repo.IdentityPolicies(identity.FromAddress("0x2")))
+}
+
+func Test_Repository_RemovePolicy(t *testing.T) {
+	repo := createFullRepo()
+	repo.RemovePolicy(policyOne)
+	assert.Equal(t, []market.AccessPolicy{policyTwo}, repo.Policies())
+
+	repo.RemovePolicy(policyThree)
+	assert.Equal(t, []market.AccessPolicy{policyTwo}, repo.Policies())
+}
+
+func Test_Repository_DenyRules(t *testing.T) {
+	repo := createFullRepo()
+	repo.SetPolicyRules(
+		LocalPolicy("deny"),
+		market.AccessPolicyRuleSet{
+			ID: "deny",
+			Deny: []market.AccessRule{
+				{Type: market.AccessPolicyTypeIdentity, Value: "0x1"},
+				{Type: market.AccessPolicyTypeDNSZone, Value: "torrent.com"},
+				{Type: market.AccessPolicyTypeConsumerCountry, Value: "cn"},
+			},
+		},
+	)
+
+	assert.False(t, repo.IsIdentityAllowed(identity.FromAddress("0x1")))
+	assert.True(t, repo.HasDNSDenyRules())
+	assert.True(t, repo.IsHostDenied("tracker.torrent.com"))
+	assert.False(t, repo.IsHostAllowed("tracker.torrent.com"))
+	assert.False(t, repo.IsHostDenied("ipinfo.io"))
+	assert.True(t, repo.IsHostAllowed("ipinfo.io"))
+	assert.False(t, repo.IsCountryAllowed("CN"))
+	assert.True(t, repo.IsCountryAllowed("DE"))
+}
+
+func Test_Repository_IsCountryAllowed(t *testing.T) {
+	repo := createEmptyRepo()
+	assert.True(t, repo.IsCountryAllowed("DE"))
+	assert.False(t, repo.HasDNSDenyRules())
+
+	repo.SetPolicyRules(
+		LocalPolicy("countries"),
+		market.AccessPolicyRuleSet{
+			ID: "countries",
+			Allow: []market.AccessRule{
+				{Type: market.AccessPolicyTypeConsumerCountry, Value: "DE"},
+				{Type: market.AccessPolicyTypeConsumerCountry, Value: "LT"},
+			},
+		},
+	)
+	assert.True(t, repo.IsCountryAllowed("de"))
+	assert.True(t, repo.IsCountryAllowed("LT"))
+	assert.False(t, repo.IsCountryAllowed("US"))
+	assert.False(t, repo.IsCountryAllowed(""))
+}
+
+func Test_Repository_DestinationRules(t *testing.T) {
+	repo := createFullRepo()
+	allow, deny := repo.DestinationRules()
+	assert.Len(t, allow, 0)
+	assert.Len(t, deny, 0)
+
+	repo.SetPolicyRules(
+		LocalPolicy("isp"),
+		market.AccessPolicyRuleSet{
+			ID: "isp",
+			Allow: []market.AccessRule{
+				{Type: market.AccessPolicyTypeDestinationCIDR, Value: "192.168.0.0/16"},
+			},
+			Deny: []market.AccessRule{
+				{Type: market.AccessPolicyTypeProtocol, Value: "smtp"},
+				{Type: market.AccessPolicyTypeDestinationPort, Value: "udp/53"},
+				{Type: market.AccessPolicyTypeDestinationPort, Value: "invalid"},
+			},
+		},
+	)
+
+	_, network, _ := net.ParseCIDR("192.168.0.0/16")
+	allow, deny = repo.DestinationRules()
+	assert.Equal(t, []firewall.DestinationRule{{Network: network}}, allow)
+	assert.Equal(
+		t,
+		[]firewall.DestinationRule{
+			{Protocol: "tcp", PortFrom: 25, PortTo: 25},
+			{Protocol: "tcp", PortFrom: 465, PortTo: 465},
+			{Protocol: "tcp", PortFrom: 587, PortTo: 587},
+			{Protocol: "udp", PortFrom: 53, PortTo: 53},
+		},
+		deny,
+	)
+}
+
+func Test_Repository_IsDestinationAllowed(t *testing.T) {
+	repo := createFullRepo()
+	assert.True(t, repo.IsDestinationAllowed(net.ParseIP("1.1.1.1"), "tcp", 25))
+
+	repo.SetPolicyRules(
+		LocalPolicy("isp"),
+		market.AccessPolicyRuleSet{
+			ID: "isp",
+			Allow: []market.AccessRule{
+				{Type: market.AccessPolicyTypeDestinationCIDR, Value: "192.168.0.0/16"},
+			},
+			Deny: []market.AccessRule{
+				{Type: market.AccessPolicyTypeProtocol, Value: "smtp"},
+			},
+		},
+	)
+
+	assert.True(t, repo.IsDestinationAllowed(net.ParseIP("192.168.1.1"), "tcp", 80))
+	assert.False(t, repo.IsDestinationAllowed(net.ParseIP("192.168.1.1"), "tcp", 25))
+	assert.False(t, repo.IsDestinationAllowed(net.ParseIP("1.1.1.1"), "tcp", 80))
+}
+
 func createEmptyRepo() *Repository {
 	return NewRepository()
 }
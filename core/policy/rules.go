@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// protocols maps well known protocol names to the destinations they use.
+var protocols = map[string][]firewall.DestinationRule{
+	"smtp": {
+		{Protocol: "tcp", PortFrom: 25, PortTo: 25},
+		{Protocol: "tcp", PortFrom: 465, PortTo: 465},
+		{Protocol: "tcp", PortFrom: 587, PortTo: 587},
+	},
+	"bittorrent": {
+		{PortFrom: 6881, PortTo: 6999},
+	},
+}
+
+// ValidateRule checks whether the rule has a known type and a value valid for it.
+func ValidateRule(rule market.AccessRule) error {
+	switch rule.Type {
+	case market.AccessPolicyTypeIdentity, market.AccessPolicyTypeDNSHostname, market.AccessPolicyTypeDNSZone:
+		if rule.Value == "" {
+			return fmt.Errorf("empty %s rule", rule.Type)
+		}
+		return nil
+	case market.AccessPolicyTypeConsumerCountry:
+		if len(rule.Value) != 2 {
+			return fmt.Errorf("invalid country code %q, expected ISO 3166-1 alpha-2 code", rule.Value)
+		}
+		return nil
+	case market.AccessPolicyTypeDestinationCIDR, market.AccessPolicyTypeDestinationPort, market.AccessPolicyTypeProtocol:
+		_, err := ParseDestinationRule(rule)
+		return err
+	default:
+		return fmt.Errorf("unknown rule type %q", rule.Type)
+	}
+}
+
+// ParseDestinationRule converts destination CIDR, port and protocol rules to firewall rules.
+func ParseDestinationRule(rule market.AccessRule) ([]firewall.DestinationRule, error) {
+	switch rule.Type {
+	case market.AccessPolicyTypeDestinationCIDR:
+		_, network, err := net.ParseCIDR(rule.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid destination CIDR %q: %w", rule.Value, err)
+		}
+		return []firewall.DestinationRule{{Network: network}}, nil
+	case market.AccessPolicyTypeDestinationPort:
+		destination, err := parsePortRule(rule.Value)
+		if err != nil {
+			return nil, err
+		}
+		return []firewall.DestinationRule{destination}, nil
+	case market.AccessPolicyTypeProtocol:
+		destinations, ok := protocols[strings.ToLower(rule.Value)]
+		if !ok {
+			return nil, fmt.Errorf("unknown protocol %q", rule.Value)
+		}
+		return destinations, nil
+	default:
+		return nil, fmt.Errorf("rule type %q is not a destination rule", rule.Type)
+	}
+}
+
+func isDestinationRule(rule market.AccessRule) bool {
+	return rule.Type == market.AccessPolicyTypeDestinationCIDR ||
+		rule.Type == market.AccessPolicyTypeDestinationPort ||
+		rule.Type == market.AccessPolicyTypeProtocol
+}
+
+// parsePortRule parses port rules formatted as "25", "6881-6999" or "tcp/25".
+func parsePortRule(value string) (firewall.DestinationRule, error) {
+	var rule firewall.DestinationRule
+
+	ports := value
+	if i := strings.Index(value, "/"); i >= 0 {
+		rule.Protocol = strings.ToLower(value[:i])
+		ports = value[i+1:]
+		if rule.Protocol != "tcp" && rule.Protocol != "udp" {
+			return rule, fmt.Errorf("invalid protocol in port rule %q, expected tcp or udp", value)
+		}
+	}
+
+	from, to := ports, ports
+	if i := strings.Index(ports, "-"); i >= 0 {
+		from, to = ports[:i], ports[i+1:]
+	}
+
+	var err error
+	if rule.PortFrom, err = parsePort(from); err != nil {
+		return rule, fmt.Errorf("invalid port rule %q: %w", value, err)
+	}
+	if rule.PortTo, err = parsePort(to); err != nil {
+		return rule, fmt.Errorf("invalid port rule %q: %w", value, err)
+	}
+	if rule.PortFrom > rule.PortTo {
+		return rule, fmt.Errorf("invalid port rule %q: range start is after its end", value)
+	}
+
+	return rule, nil
+}
+
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("port %d out of range", port)
+	}
+	return port, nil
+}
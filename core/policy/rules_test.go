@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func Test_ValidateRule(t *testing.T) {
+	tests := []struct {
+		rule  market.AccessRule
+		valid bool
+	}{
+		{market.AccessRule{Type: market.AccessPolicyTypeIdentity, Value: "0x1"}, true},
+		{market.AccessRule{Type: market.AccessPolicyTypeDNSZone, Value: ""}, false},
+		{market.AccessRule{Type: market.AccessPolicyTypeConsumerCountry, Value: "DE"}, true},
+		{market.AccessRule{Type: market.AccessPolicyTypeConsumerCountry, Value: "Germany"}, false},
+		{market.AccessRule{Type: market.AccessPolicyTypeDestinationCIDR, Value: "10.0.0.0/8"}, true},
+		{market.AccessRule{Type: market.AccessPolicyTypeDestinationCIDR, Value: "10.0.0.0"}, false},
+		{market.AccessRule{Type: market.AccessPolicyTypeDestinationPort, Value: "25"}, true},
+		{market.AccessRule{Type: market.AccessPolicyTypeDestinationPort, Value: "tcp/6881-6999"}, true},
+		{market.AccessRule{Type: market.AccessPolicyTypeDestinationPort, Value: "icmp/1"}, false},
+		{market.AccessRule{Type: market.AccessPolicyTypeDestinationPort, Value: "70000"}, false},
+		{market.AccessRule{Type: market.AccessPolicyTypeDestinationPort, Value: "20-10"}, false},
+		{market.AccessRule{Type: market.AccessPolicyTypeProtocol, Value: "BitTorrent"}, true},
+		{market.AccessRule{Type: market.AccessPolicyTypeProtocol, Value: "gopher"}, false},
+		{market.AccessRule{Type: "unknown", Value: "1"}, false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateRule(tt.rule)
+		assert.Equal(t, tt.valid, err == nil, "%+v: %v", tt.rule, err)
+	}
+}
+
+func Test_ParseDestinationRule(t *testing.T) {
+	rules, err := ParseDestinationRule(market.AccessRule{Type: market.AccessPolicyTypeDestinationPort, Value: "UDP/6881-6999"})
+	assert.NoError(t, err)
+	assert.Equal(t, []firewall.DestinationRule{{Protocol: "udp", PortFrom: 6881, PortTo: 6999}}, rules)
+
+	rules, err = ParseDestinationRule(market.AccessRule{Type: market.AccessPolicyTypeProtocol, Value: "bittorrent"})
+	assert.NoError(t, err)
+	assert.Equal(t, []firewall.DestinationRule{{PortFrom: 6881, PortTo: 6999}}, rules)
+
+	_, err = ParseDestinationRule(market.AccessRule{Type: market.AccessPolicyTypeIdentity, Value: "0x1"})
+	assert.Error(t, err)
+}
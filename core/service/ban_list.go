@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+const banListBucketName = "consumer-bans"
+
+// ErrBanNotFound is returned when the consumer is not in the ban list.
+var ErrBanNotFound = errors.New("consumer ban not found")
+
+type banStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+// Ban is a consumer identity which is not allowed to start sessions with the provider.
+type Ban struct {
+	ConsumerID string `storm:"id"`
+	Reason     string
+	CreatedAt  time.Time
+}
+
+// BanList keeps consumer identities banned by the provider.
+type BanList struct {
+	lock    sync.Mutex
+	storage banStorage
+}
+
+// NewBanList returns a new instance of persisted consumer ban list.
+func NewBanList(storage banStorage) *BanList {
+	return &BanList{
+		storage: storage,
+	}
+}
+
+// List returns all banned consumers.
+func (bl *BanList) List() ([]Ban, error) {
+	bl.lock.Lock()
+	defer bl.lock.Unlock()
+
+	var bans []Ban
+	err := bl.storage.GetAllFrom(banListBucketName, &bans)
+	if errors.Is(err, storm.ErrNotFound) {
+		return []Ban{}, nil
+	}
+	return bans, err
+}
+
+// Ban adds the consumer to the ban list, banning already banned consumer updates the reason.
+func (bl *BanList) Ban(consumerID identity.Identity, reason string) (Ban, error) {
+	bl.lock.Lock()
+	defer bl.lock.Unlock()
+
+	ban := Ban{
+		ConsumerID: banKey(consumerID),
+		Reason:     reason,
+		CreatedAt:  time.Now().UTC(),
+	}
+	return ban, bl.storage.Store(banListBucketName, &ban)
+}
+
+// Unban removes the consumer from the ban list.
+func (bl *BanList) Unban(consumerID identity.Identity) error {
+	bl.lock.Lock()
+	defer bl.lock.Unlock()
+
+	err := bl.storage.Delete(banListBucketName, &Ban{ConsumerID: banKey(consumerID)})
+	if errors.Is(err, storm.ErrNotFound) {
+		return ErrBanNotFound
+	}
+	return err
+}
+
+// IsBanned tells whether the consumer is in the ban list.
+func (bl *BanList) IsBanned(consumerID identity.Identity) (bool, error) {
+	bl.lock.Lock()
+	defer bl.lock.Unlock()
+
+	var ban Ban
+	err := bl.storage.GetOneByField(banListBucketName, "ConsumerID", banKey(consumerID), &ban)
+	if errors.Is(err, storm.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func banKey(consumerID identity.Identity) string {
+	return strings.ToLower(consumerID.Address)
+}
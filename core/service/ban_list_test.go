@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+func TestBanList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "consumerBanListTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	banList := NewBanList(bolt)
+
+	bans, err := banList.List()
+	assert.NoError(t, err)
+	assert.Len(t, bans, 0)
+
+	consumer := identity.FromAddress("0x000000000000000000000000000000000000000A")
+	banned, err := banList.IsBanned(consumer)
+	assert.NoError(t, err)
+	assert.False(t, banned)
+
+	ban, err := banList.Ban(consumer, "abuse")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x000000000000000000000000000000000000000a", ban.ConsumerID)
+
+	banned, err = banList.IsBanned(identity.FromAddress("0x000000000000000000000000000000000000000a"))
+	assert.NoError(t, err)
+	assert.True(t, banned)
+
+	bans, err = banList.List()
+	assert.NoError(t, err)
+	assert.Len(t, bans, 1)
+	assert.Equal(t, "abuse", bans[0].Reason)
+
+	assert.NoError(t, banList.Unban(consumer))
+	banned, err = banList.IsBanned(consumer)
+	assert.NoError(t, err)
+	assert.False(t, banned)
+	assert.Equal(t, ErrBanNotFound, banList.Unban(consumer))
+}
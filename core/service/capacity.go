@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+const mib = 1024 * 1024
+
+var (
+	// ErrorCapacityExceeded returned when service already serves max concurrent sessions
+	ErrorCapacityExceeded = session.NewRejection(session.RejectionCapacityExceeded, "service reached max concurrent sessions")
+	// ErrorConsumerLimitExceeded returned when consumer already has max concurrent sessions with the provider
+	ErrorConsumerLimitExceeded = session.NewRejection(session.RejectionConsumerLimitExceeded, "consumer reached max concurrent sessions with the provider")
+	// ErrorResourcesExhausted returned when provider has less free resources than configured headroom
+	ErrorResourcesExhausted = session.NewRejection(session.RejectionResourcesExhausted, "provider is low on resources")
+)
+
+// Capacity limits the load accepted by the service, zero value of the field disables the limit.
+type Capacity struct {
+	// MaxSessions is a count of concurrent sessions served by the service.
+	MaxSessions int `json:"max_sessions,omitempty"`
+	// MaxConsumerSessions is a count of concurrent sessions single consumer may have with the provider.
+	MaxConsumerSessions int `json:"max_consumer_sessions,omitempty"`
+	// MinFreeCPU is a share of CPU time in percent which has to stay idle.
+	MinFreeCPU float64 `json:"min_free_cpu,omitempty"`
+	// MinFreeMemory is an amount of memory in MiB which has to stay available.
+	MinFreeMemory uint64 `json:"min_free_memory,omitempty"`
+	// Bandwidth is uplink bandwidth of the provider in Mbit/s, it is required to check the bandwidth headroom.
+	Bandwidth uint64 `json:"bandwidth,omitempty"`
+	// MinFreeBandwidth is an uplink bandwidth in Mbit/s which has to stay unused.
+	MinFreeBandwidth uint64 `json:"min_free_bandwidth,omitempty"`
+}
+
+// ResourceUsage represents utilization of the provider host.
+type ResourceUsage struct {
+	// CPU is a share of used CPU time in percent.
+	CPU float64
+	// MemoryAvailable is an amount of memory in bytes available for new processes.
+	MemoryAvailable uint64
+	// Throughput is the traffic of the busiest network interface in bits per second.
+	Throughput uint64
+}
+
+// ResourceMonitor measures utilization of the provider host.
+type ResourceMonitor interface {
+	// Usage returns the last measured utilization, false is returned until the first measurement is done.
+	Usage() (ResourceUsage, bool)
+}
+
+// checkCapacity tells whether the service can serve one more session of the consumer.
+// Empty consumer identity checks the limits of the service only.
+func (i *Instance) checkCapacity(consumerID identity.Identity) error {
+	serviceSessions, consumerSessions := i.countSessions(consumerID)
+	if i.capacity.MaxSessions > 0 && serviceSessions >= i.capacity.MaxSessions {
+		return ErrorCapacityExceeded
+	}
+	if consumerID.Address != "" && i.capacity.MaxConsumerSessions > 0 && consumerSessions >= i.capacity.MaxConsumerSessions {
+		return ErrorConsumerLimitExceeded
+	}
+
+	return i.checkResources()
+}
+
+// countSessions returns a count of sessions served by the service and a count of consumer sessions with the provider.
+// Session of the consumer with the same service type is not counted as it is replaced by the new one, see clearStaleSession.
+func (i *Instance) countSessions(consumerID identity.Identity) (serviceSessions, consumerSessions int) {
+	if i.sessions == nil {
+		return 0, 0
+	}
+
+	for _, s := range i.sessions.GetAll() {
+		if s.ConsumerID == consumerID && s.Proposal.ServiceType == i.Type {
+			continue
+		}
+		if s.ServiceID == string(i.ID) {
+			serviceSessions++
+		}
+		if s.ConsumerID == consumerID {
+			consumerSessions++
+		}
+	}
+	return serviceSessions, consumerSessions
+}
+
+func (i *Instance) checkResources() error {
+	if i.resources == nil {
+		return nil
+	}
+	usage, ok := i.resources.Usage()
+	if !ok {
+		return nil
+	}
+
+	if free := 100 - usage.CPU; i.capacity.MinFreeCPU > 0 && free < i.capacity.MinFreeCPU {
+		return fmt.Errorf("%w: %.0f%% of CPU is idle", ErrorResourcesExhausted, free)
+	}
+	if i.capacity.MinFreeMemory > 0 && usage.MemoryAvailable < i.capacity.MinFreeMemory*mib {
+		return fmt.Errorf("%w: %d MiB of memory is available", ErrorResourcesExhausted, usage.MemoryAvailable/mib)
+	}
+	if i.capacity.Bandwidth > 0 && i.capacity.MinFreeBandwidth > 0 {
+		used := usage.Throughput / 1e6
+		if used >= i.capacity.Bandwidth || i.capacity.Bandwidth-used < i.capacity.MinFreeBandwidth {
+			return fmt.Errorf("%w: %d Mbit/s of bandwidth is used", ErrorResourcesExhausted, used)
+		}
+	}
+	return nil
+}
+
+// currentCapacity returns utilization of the service to advertise.
+func (i *Instance) currentCapacity() *market.Capacity {
+	sessions, _ := i.countSessions(identity.Identity{})
+	capacity := &market.Capacity{
+		Sessions:    sessions,
+		MaxSessions: i.capacity.MaxSessions,
+		Full:        i.checkCapacity(identity.Identity{}) != nil,
+	}
+	if i.capacity.MaxSessions > 0 {
+		capacity.Load = float64(sessions) / float64(i.capacity.MaxSessions)
+	}
+	if i.resources != nil {
+		if usage, ok := i.resources.Usage(); ok {
+			capacity.Load = math.Max(capacity.Load, usage.CPU/100)
+			if i.capacity.Bandwidth > 0 {
+				capacity.Load = math.Max(capacity.Load, float64(usage.Throughput)/float64(i.capacity.Bandwidth*1e6))
+			}
+		}
+	}
+	capacity.Load = math.Min(capacity.Load, 1)
+	return capacity
+}
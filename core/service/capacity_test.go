@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+type mockResourceMonitor struct {
+	usage ResourceUsage
+}
+
+func (m *mockResourceMonitor) Usage() (ResourceUsage, bool) {
+	return m.usage, true
+}
+
+func newCapacityInstance(capacity Capacity, resources ResourceMonitor) *Instance {
+	return &Instance{
+		ID:        "service1",
+		Type:      "wireguard",
+		capacity:  capacity,
+		sessions:  NewSessionPool(&mockPublisher{}),
+		resources: resources,
+	}
+}
+
+func addSession(instance *Instance, id string, serviceID ID, serviceType string, consumerID identity.Identity) {
+	instance.sessions.Add(&Session{
+		ID:         session.ID(id),
+		ConsumerID: consumerID,
+		ServiceID:  string(serviceID),
+		Proposal:   market.ServiceProposal{ServiceType: serviceType},
+	})
+}
+
+func TestInstance_CheckCapacity_Sessions(t *testing.T) {
+	consumer1 := identity.FromAddress("0x1")
+	consumer2 := identity.FromAddress("0x2")
+	consumer3 := identity.FromAddress("0x3")
+
+	// given
+	instance := newCapacityInstance(Capacity{MaxSessions: 2, MaxConsumerSessions: 1}, nil)
+	addSession(instance, "s1", "service1", "wireguard", consumer1)
+	addSession(instance, "s2", "service2", "openvpn", consumer2)
+
+	// then
+	assert.NoError(t, instance.checkCapacity(consumer1), "stale session of the same service is replaced")
+	assert.True(t, errors.Is(instance.checkCapacity(consumer2), ErrorConsumerLimitExceeded))
+	assert.NoError(t, instance.checkCapacity(consumer3))
+
+	// when
+	addSession(instance, "s3", "service1", "wireguard", consumer3)
+
+	// then
+	assert.True(t, errors.Is(instance.checkCapacity(identity.FromAddress("0x4")), ErrorCapacityExceeded))
+	assert.NoError(t, instance.checkCapacity(consumer1), "stale session of the same service is replaced")
+	assert.Equal(t, &market.Capacity{Sessions: 2, MaxSessions: 2, Load: 1, Full: true}, instance.currentCapacity())
+}
+
+func TestInstance_CheckCapacity_Resources(t *testing.T) {
+	consumer := identity.FromAddress("0x1")
+	resources := &mockResourceMonitor{}
+	instance := newCapacityInstance(Capacity{MinFreeCPU: 20, MinFreeMemory: 256, Bandwidth: 100, MinFreeBandwidth: 10}, resources)
+
+	tests := []struct {
+		usage   ResourceUsage
+		wantErr bool
+	}{
+		{usage: ResourceUsage{CPU: 50, MemoryAvailable: 1024 * mib, Throughput: 50e6}, wantErr: false},
+		{usage: ResourceUsage{CPU: 85, MemoryAvailable: 1024 * mib, Throughput: 50e6}, wantErr: true},
+		{usage: ResourceUsage{CPU: 50, MemoryAvailable: 128 * mib, Throughput: 50e6}, wantErr: true},
+		{usage: ResourceUsage{CPU: 50, MemoryAvailable: 1024 * mib, Throughput: 95e6}, wantErr: true},
+		{usage: ResourceUsage{CPU: 50, MemoryAvailable: 1024 * mib, Throughput: 120e6}, wantErr: true},
+	}
+	for _, tt := range tests {
+		resources.usage = tt.usage
+
+		err := instance.checkCapacity(consumer)
+
+		if tt.wantErr {
+			assert.True(t, errors.Is(err, ErrorResourcesExhausted), "%+v", tt.usage)
+			assert.True(t, session.IsTemporaryRejection(err))
+		} else {
+			assert.NoError(t, err, "%+v", tt.usage)
+		}
+	}
+}
+
+func TestInstance_CurrentCapacity_Load(t *testing.T) {
+	// given
+	resources := &mockResourceMonitor{usage: ResourceUsage{CPU: 30, Throughput: 60e6}}
+	instance := newCapacityInstance(Capacity{MaxSessions: 10, Bandwidth: 100}, resources)
+	addSession(instance, "s1", "service1", "wireguard", identity.FromAddress("0x1"))
+
+	// then
+	assert.Equal(t, &market.Capacity{Sessions: 1, MaxSessions: 10, Load: 0.6}, instance.currentCapacity())
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/session"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+var (
+	// ErrorServiceDraining returned when consumer requests a session from the service which is being drained
+	ErrorServiceDraining = session.NewRejection(session.RejectionServiceDraining, "service is being drained before it stops")
+	// ErrAlreadyDraining represents the error when we're draining an instance which is already being drained
+	ErrAlreadyDraining = errors.New("service is already draining")
+	// ErrNotRunning represents the error when we're draining an instance which is not running yet
+	ErrNotRunning = errors.New("service is not running")
+)
+
+// drainCheckInterval is how often sessions of the draining service are counted.
+var drainCheckInterval = time.Second
+
+// Drain stops the service gracefully. Service proposal is unregistered and new sessions are refused,
+// the service is stopped once its sessions end or the timeout expires, then earned promises are settled.
+// It returns once draining has begun, the service is stopped in the background.
+func (manager *Manager) Drain(id ID, timeout time.Duration) error {
+	instance := manager.servicePool.Instance(id)
+	if instance == nil {
+		return ErrNoSuchInstance
+	}
+
+	if err := instance.startDrain(); err != nil {
+		return err
+	}
+	log.Info().Msgf("Draining service %s, waiting up to %s for sessions to end", id, timeout)
+
+	// Sessions are not added while draining, so these are all the sessions to wait for.
+	go manager.finishDrain(instance, instance.serviceSessions(), timeout)
+	return nil
+}
+
+// finishDrain stops the service and requests settlement with hermeses of the sessions which ended while draining.
+// Sessions still open on timeout are force closed without settlement, their last promises might be missing,
+// so these are left to the regular threshold settlement.
+func (manager *Manager) finishDrain(instance *Instance, sessions []*Session, timeout time.Duration) {
+	hermesIDs, remaining := instance.waitSessions(sessions, timeout)
+	if len(remaining) > 0 {
+		log.Warn().Msgf("Service %s drain timed out, closing %d remaining sessions without settlement", instance.ID, len(remaining))
+		for _, s := range remaining {
+			s.Close()
+		}
+	}
+
+	if err := manager.Stop(instance.ID); err != nil && !errors.Is(err, ErrNoSuchInstance) {
+		log.Error().Err(err).Msgf("Failed to stop drained service %s", instance.ID)
+	}
+
+	chainID := config.GetInt64(config.FlagChainID)
+	for hermesID := range hermesIDs {
+		manager.eventPublisher.Publish(pingpong_event.AppTopicSettlementRequest, pingpong_event.AppEventSettlementRequest{
+			ChainID:    chainID,
+			HermesID:   hermesID,
+			ProviderID: instance.ProviderID,
+		})
+	}
+	log.Info().Msgf("Service %s drained", instance.ID)
+}
+
+// startDrain switches the running service to draining and unregisters its proposal.
+func (i *Instance) startDrain() error {
+	// Sessions are validated and added under the capacity lock, so none is added once the state is changed.
+	i.capacityLock.Lock()
+	defer i.capacityLock.Unlock()
+
+	switch i.State() {
+	case servicestate.Running:
+	case servicestate.Draining:
+		return ErrAlreadyDraining
+	default:
+		return ErrNotRunning
+	}
+
+	i.setState(servicestate.Draining)
+	i.stopDiscovery()
+	return nil
+}
+
+// waitSessions blocks until the given sessions end or the service is stopped. It returns hermes IDs of the sessions
+// which ended meanwhile and the sessions which are still open when the timeout expires.
+func (i *Instance) waitSessions(sessions []*Session, timeout time.Duration) (map[common.Address]struct{}, []*Session) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	hermesIDs := make(map[common.Address]struct{})
+	open := make(map[session.ID]*Session)
+	for _, s := range sessions {
+		open[s.ID] = s
+	}
+	for {
+		current := make(map[session.ID]*Session)
+		for _, s := range i.serviceSessions() {
+			current[s.ID] = s
+		}
+		for id, s := range open {
+			if _, ok := current[id]; !ok {
+				hermesIDs[s.HermesID] = struct{}{}
+			}
+		}
+		open = current
+
+		if i.State() == servicestate.NotRunning || len(open) == 0 {
+			return hermesIDs, nil
+		}
+
+		select {
+		case <-deadline.C:
+			remaining := make([]*Session, 0, len(open))
+			for _, s := range open {
+				remaining = append(remaining, s)
+			}
+			return hermesIDs, remaining
+		case <-ticker.C:
+		}
+	}
+}
+
+// serviceSessions returns sessions served by the service.
+func (i *Instance) serviceSessions() []*Session {
+	if i.sessions == nil {
+		return nil
+	}
+
+	var sessions []*Session
+	for _, s := range i.sessions.GetAll() {
+		if s.ServiceID == string(i.ID) {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions
+}
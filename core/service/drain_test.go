@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+func init() {
+	drainCheckInterval = time.Millisecond
+}
+
+func newDrainManager(publisher *mockPublisher) (*Manager, *Instance, *mockDiscovery) {
+	discovery := &mockDiscovery{}
+	discovery.Start(identity.Identity{}, nil)
+
+	instance := &Instance{
+		ID:             "service1",
+		ProviderID:     identity.FromAddress("0x1"),
+		Type:           "wireguard",
+		state:          servicestate.Running,
+		service:        &mockService{},
+		discovery:      discovery,
+		eventPublisher: publisher,
+		sessions:       NewSessionPool(publisher),
+	}
+	manager := &Manager{
+		servicePool:    NewPool(publisher),
+		eventPublisher: publisher,
+	}
+	manager.servicePool.Add(instance)
+
+	return manager, instance, discovery
+}
+
+func addDrainSession(instance *Instance, id string, hermesID common.Address) *Session {
+	s := &Session{
+		ID:         session.ID(id),
+		ConsumerID: identity.FromAddress("0x2"),
+		HermesID:   hermesID,
+		ServiceID:  string(instance.ID),
+		Proposal:   market.ServiceProposal{ServiceType: instance.Type},
+		done:       make(chan struct{}),
+	}
+	instance.sessions.Add(s)
+	return s
+}
+
+func settlementRequests(publisher *mockPublisher) (requests []pingpong_event.AppEventSettlementRequest) {
+	publisher.lock.Lock()
+	defer publisher.lock.Unlock()
+
+	for _, data := range publisher.publishedData {
+		if request, ok := data.(pingpong_event.AppEventSettlementRequest); ok {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+func TestManager_Drain_StopsServiceOnceSessionsEnd(t *testing.T) {
+	// given
+	publisher := &mockPublisher{}
+	manager, instance, discovery := newDrainManager(publisher)
+	hermesID := common.HexToAddress("0x3")
+	s := addDrainSession(instance, "1", hermesID)
+
+	// when
+	err := manager.Drain(instance.ID, time.Minute)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, servicestate.Draining, instance.State())
+	discovery.Wait()
+	assert.Equal(t, ErrAlreadyDraining, manager.Drain(instance.ID, time.Minute))
+	assert.NotNil(t, manager.Service(instance.ID), "service is kept while session lasts")
+
+	// when
+	instance.sessions.Remove(s.ID)
+
+	// then
+	assert.Eventually(t, func() bool {
+		return len(settlementRequests(publisher)) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Nil(t, manager.Service(instance.ID))
+	assert.Equal(t, servicestate.NotRunning, instance.State())
+	assert.Equal(t, []pingpong_event.AppEventSettlementRequest{
+		{HermesID: hermesID, ProviderID: instance.ProviderID},
+	}, settlementRequests(publisher))
+}
+
+func TestManager_Drain_ClosesSessionsOnTimeout(t *testing.T) {
+	// given
+	publisher := &mockPublisher{}
+	manager, instance, _ := newDrainManager(publisher)
+	s := addDrainSession(instance, "1", common.HexToAddress("0x3"))
+
+	// when
+	err := manager.Drain(instance.ID, 10*time.Millisecond)
+
+	// then
+	assert.NoError(t, err)
+	select {
+	case <-s.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("session was not closed")
+	}
+	assert.Eventually(t, func() bool {
+		return manager.Service(instance.ID) == nil
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestManager_Drain_SettlesOnlySessionsEndedBeforeTimeout(t *testing.T) {
+	// given
+	publisher := &mockPublisher{}
+	manager, instance, _ := newDrainManager(publisher)
+	endedHermesID := common.HexToAddress("0x3")
+	ended := addDrainSession(instance, "1", endedHermesID)
+	closed := addDrainSession(instance, "2", common.HexToAddress("0x4"))
+
+	// when
+	err := manager.Drain(instance.ID, 200*time.Millisecond)
+	instance.sessions.Remove(ended.ID)
+
+	// then
+	assert.NoError(t, err)
+	select {
+	case <-closed.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("session was not closed")
+	}
+	assert.Eventually(t, func() bool {
+		return manager.Service(instance.ID) == nil
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []pingpong_event.AppEventSettlementRequest{
+		{HermesID: endedHermesID, ProviderID: instance.ProviderID},
+	}, settlementRequests(publisher))
+}
+
+func TestManager_Drain_RejectsUnknownService(t *testing.T) {
+	manager, _, _ := newDrainManager(&mockPublisher{})
+
+	assert.Equal(t, ErrNoSuchInstance, manager.Drain("unknown", time.Minute))
+}
+
+func TestManager_Drain_RejectsServiceNotRunning(t *testing.T) {
+	manager, instance, _ := newDrainManager(&mockPublisher{})
+	instance.state = servicestate.Starting
+
+	assert.Equal(t, ErrNotRunning, manager.Drain(instance.ID, time.Minute))
+}
@@ -49,6 +49,14 @@ const (
 	channelIdleTimeout = 1 * time.Minute
 )
 
+// hasIPv6Egress checks if provider is able to route IPv6 traffic to the internet.
+var hasIPv6Egress = netutil.HasIPv6Egress
+
+// ipv6Options represents service options of dual-stack capable services.
+type ipv6Options interface {
+	IPv6Enabled() bool
+}
+
 // Service interface represents pluggable Mysterium service
 type Service interface {
 	Serve(instance *Instance) error
@@ -80,10 +88,14 @@ func NewManager(
 	discoveryFactory DiscoveryFactory,
 	eventPublisher Publisher,
 	policyOracle *policy.Oracle,
+	localPolicies *policy.LocalPolicies,
 	p2pListener p2p.Listener,
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager,
 	statusStorage connectivity.StatusStorage,
 	location locationResolver,
+	pricing PricingPolicy,
+	sessions *SessionPool,
+	resources ResourceMonitor,
 ) *Manager {
 	return &Manager{
 		serviceRegistry:  serviceRegistry,
@@ -91,10 +103,14 @@ func NewManager(
 		discoveryFactory: discoveryFactory,
 		eventPublisher:   eventPublisher,
 		policyOracle:     policyOracle,
+		localPolicies:    localPolicies,
 		p2pListener:      p2pListener,
 		sessionManager:   sessionManager,
 		statusStorage:    statusStorage,
 		location:         location,
+		pricing:          pricing,
+		sessions:         sessions,
+		resources:        resources,
 	}
 }
 
@@ -106,21 +122,27 @@ type Manager struct {
 	discoveryFactory DiscoveryFactory
 	eventPublisher   Publisher
 	policyOracle     *policy.Oracle
+	localPolicies    *policy.LocalPolicies
 
 	p2pListener    p2p.Listener
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager
 	statusStorage  connectivity.StatusStorage
 	location       locationResolver
+	pricing        PricingPolicy
+	sessions       *SessionPool
+	resources      ResourceMonitor
 }
 
 // Start starts an instance of the given service type if knows one in service registry.
 // It passes the options to the start method of the service.
+// Sessions exceeding the capacity are rejected.
 // If an error occurs in the underlying service, the error is then returned.
-func (manager *Manager) Start(providerID identity.Identity, serviceType string, policyIDs []string, options Options) (id ID, err error) {
+func (manager *Manager) Start(providerID identity.Identity, serviceType string, policyIDs []string, capacity Capacity, options Options) (id ID, err error) {
 	log.Debug().Fields(map[string]interface{}{
 		"providerID":  providerID.Address,
 		"serviceType": serviceType,
 		"policyIDs":   policyIDs,
+		"capacity":    capacity,
 		"options":     options,
 	}).Msg("Starting service")
 	service, err := manager.serviceRegistry.Create(serviceType, options)
@@ -137,6 +159,12 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 			return id, ErrUnsupportedAccessPolicy
 		}
 	}
+	// Local policies restrict the service only, they are not advertised in the proposal.
+	if manager.localPolicies != nil {
+		if err = manager.localPolicies.SubscribePolicies(policyRules); err != nil {
+			return id, fmt.Errorf("could not load local access policies: %w", err)
+		}
+	}
 
 	location, err := manager.location.DetectLocation()
 	if err != nil {
@@ -147,6 +175,7 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		Location:       market.NewLocation(location),
 		AccessPolicies: accessPolicies,
 		Contacts:       []market.Contact{manager.p2pListener.GetContact()},
+		IPv6:           supportsIPv6(options),
 	})
 
 	discovery := manager.discoveryFactory()
@@ -168,7 +197,13 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		discovery:      discovery,
 		eventPublisher: manager.eventPublisher,
 		location:       manager.location,
+		pricing:        manager.pricing,
+		capacity:       capacity,
+		sessions:       manager.sessions,
+		resources:      manager.resources,
 	}
+	instance.Proposal.Price = instance.currentPrice(instance.Proposal.Location)
+	instance.Proposal.Capacity = instance.currentCapacity()
 
 	discovery.Start(providerID, instance.proposalWithCurrentLocation)
 
@@ -203,6 +238,9 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		}
 
 		stopP2PListener()
+		if manager.localPolicies != nil {
+			manager.localPolicies.UnsubscribePolicies(policyRules)
+		}
 
 		stopErr := manager.servicePool.Stop(id)
 		if stopErr != nil {
@@ -249,3 +287,8 @@ func (manager *Manager) Stop(id ID) error {
 func (manager *Manager) Service(id ID) *Instance {
 	return manager.servicePool.Instance(id)
 }
+
+func supportsIPv6(options Options) bool {
+	opts, ok := options.(ipv6Options)
+	return ok && opts.IPv6Enabled() && hasIPv6Egress()
+}
@@ -58,9 +58,10 @@ func TestManager_StartRemovesServiceFromPoolIfServiceCrashes(t *testing.T) {
 		discoveryFactory,
 		mocks.NewEventBus(),
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil, mockLocationResolver{},
+		nil,
+		&mockP2PListener{}, nil, nil, mockLocationResolver{}, nil, nil, nil,
 	)
-	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
+	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, Capacity{}, struct{}{})
 	assert.Nil(t, err)
 
 	discovery.Wait()
@@ -82,10 +83,11 @@ func TestManager_StartDoesNotCrashIfStoppedByUser(t *testing.T) {
 		discoveryFactory,
 		mocks.NewEventBus(),
 		mockPolicyOracle,
+		nil,
 		&mockP2PListener{}, nil, nil,
-		mockLocationResolver{},
+		mockLocationResolver{}, nil, nil, nil,
 	)
-	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, Capacity{}, struct{}{})
 	assert.Nil(t, err)
 	err = manager.Stop(id)
 	assert.Nil(t, err)
@@ -109,11 +111,12 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 		discoveryFactory,
 		eventBus,
 		mockPolicyOracle,
+		nil,
 		&mockP2PListener{}, nil, nil,
-		mockLocationResolver{},
+		mockLocationResolver{}, nil, nil, nil,
 	)
 
-	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, Capacity{}, struct{}{})
 	assert.NoError(t, err)
 
 	services := manager.servicePool.List()
@@ -165,3 +168,30 @@ type mockLocationResolver struct {
 func (m mockLocationResolver) DetectLocation() (locationstate.Location, error) {
 	return locationstate.Location{}, nil
 }
+
+type dualStackOptions bool
+
+func (o dualStackOptions) IPv6Enabled() bool {
+	return bool(o)
+}
+
+func Test_supportsIPv6(t *testing.T) {
+	defer func(original func() bool) { hasIPv6Egress = original }(hasIPv6Egress)
+
+	for name, tc := range map[string]struct {
+		options Options
+		egress  bool
+		want    bool
+	}{
+		"options without IPv6 support": {options: struct{}{}, egress: true, want: false},
+		"IPv6 disabled":                {options: dualStackOptions(false), egress: true, want: false},
+		"no IPv6 egress":               {options: dualStackOptions(true), egress: false, want: false},
+		"IPv6 enabled with egress":     {options: dualStackOptions(true), egress: true, want: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			egress := tc.egress
+			hasIPv6Egress = func() bool { return egress }
+			assert.Equal(t, tc.want, supportsIPv6(tc.options))
+		})
+	}
+}
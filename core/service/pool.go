@@ -151,12 +151,19 @@ type Instance struct {
 	Options         Options
 	service         Service
 	Proposal        market.ServiceProposal
+	proposalLock    sync.RWMutex
 	policies        *policy.Repository
 	discovery       Discovery
+	discoveryOnce   sync.Once
 	eventPublisher  Publisher
 	p2pChannelsLock sync.Mutex
 	p2pChannels     []p2p.Channel
 	location        locationResolver
+	pricing         PricingPolicy
+	capacity        Capacity
+	capacityLock    sync.Mutex
+	sessions        *SessionPool
+	resources       ResourceMonitor
 }
 
 // Service returns the running service implementation.
@@ -164,6 +171,11 @@ func (i *Instance) Service() Service {
 	return i.service
 }
 
+// Capacity returns capacity limits of the running service instance.
+func (i *Instance) Capacity() Capacity {
+	return i.capacity
+}
+
 // Policies returns service policies of the running service instance.
 func (i *Instance) Policies() *policy.Repository {
 	return i.policies
@@ -176,16 +188,41 @@ func (i *Instance) State() servicestate.State {
 	return i.state
 }
 
+// CopyProposal returns a copy of the proposal advertised by the running service instance.
+func (i *Instance) CopyProposal() market.ServiceProposal {
+	i.proposalLock.RLock()
+	defer i.proposalLock.RUnlock()
+	return i.Proposal
+}
+
 func (i *Instance) proposalWithCurrentLocation() market.ServiceProposal {
+	proposal := i.CopyProposal()
+
 	location, err := i.location.DetectLocation()
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to get current location for proposal, using last known location")
-		return i.Proposal
+	} else {
+		proposal.Location = *market.NewLocation(location)
 	}
 
-	i.Proposal.Location = *market.NewLocation(location)
+	proposal.Price = i.currentPrice(proposal.Location)
+	proposal.Capacity = i.currentCapacity()
 
-	return i.Proposal
+	i.proposalLock.Lock()
+	defer i.proposalLock.Unlock()
+	i.Proposal = proposal
+
+	return proposal
+}
+
+// currentPrice returns the price to advertise, it is not set unless provider has a pricing policy.
+func (i *Instance) currentPrice(location market.Location) *market.Price {
+	if i.pricing == nil {
+		return nil
+	}
+
+	price := i.pricing.Price(location.IPType, location.Country)
+	return &price
 }
 
 func (i *Instance) setState(newState servicestate.State) {
@@ -205,9 +242,7 @@ func (i *Instance) addP2PChannel(ch p2p.Channel) {
 
 func (i *Instance) stop() error {
 	errStop := utils.ErrorCollection{}
-	if i.discovery != nil {
-		i.discovery.Stop()
-	}
+	i.stopDiscovery()
 	if i.service != nil {
 		errStop.Add(i.service.Stop())
 	}
@@ -222,6 +257,14 @@ func (i *Instance) stop() error {
 	return errStop.Errorf("ErrorCollection(%s)", ", ")
 }
 
+// stopDiscovery unregisters the service proposal, it is stopped once as the service may be drained before it stops.
+func (i *Instance) stopDiscovery() {
+	if i.discovery == nil {
+		return
+	}
+	i.discoveryOnce.Do(i.discovery.Stop)
+}
+
 // toEvent returns an event representation of the instance
 func (i *Instance) toEvent() servicestate.AppEventServiceStatus {
 	return servicestate.AppEventServiceStatus{
@@ -111,3 +111,26 @@ func Test_Pool_StopAllDoesNotStopOneInstance(t *testing.T) {
 	err := pool.StopAll()
 	assert.EqualError(t, err, "Some instances did not stop: ErrorCollection(I dont want to stop)")
 }
+
+func Test_Instance_ProposalRefreshedConcurrently(t *testing.T) {
+	instance := newCapacityInstance(Capacity{MaxSessions: 10}, nil)
+	instance.location = mockLocationResolver{}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			instance.proposalWithCurrentLocation()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			instance.CopyProposal()
+		}
+	}()
+	wg.Wait()
+
+	assert.Equal(t, 10, instance.CopyProposal().Capacity.MaxSessions)
+}
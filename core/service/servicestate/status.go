@@ -40,4 +40,6 @@ const (
 	Starting = State("Starting")
 	// Running means that fully established service exists
 	Running = State("Running")
+	// Draining means that service refuses new sessions and waits for existing ones to end before it stops
+	Draining = State("Draining")
 )
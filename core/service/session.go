@@ -18,6 +18,7 @@
 package service
 
 import (
+	"net"
 	"sync"
 	"time"
 
@@ -48,6 +49,8 @@ type Session struct {
 	cleanup          []func() error
 	tracer           *trace.Tracer
 	once             sync.Once
+	tunnelLock       sync.RWMutex
+	tunnelIPs        []net.IP
 }
 
 // Close ends session.
@@ -74,6 +77,21 @@ func (s *Session) Done() <-chan struct{} {
 	return s.done
 }
 
+// TunnelIPs returns IP addresses assigned to the consumer inside the service tunnel.
+func (s *Session) TunnelIPs() []net.IP {
+	s.tunnelLock.RLock()
+	defer s.tunnelLock.RUnlock()
+
+	return s.tunnelIPs
+}
+
+func (s *Session) setTunnelIPs(ips []net.IP) {
+	s.tunnelLock.Lock()
+	defer s.tunnelLock.Unlock()
+
+	s.tunnelIPs = ips
+}
+
 func (s *Session) addCleanup(fn func() error) {
 	s.cleanupLock.Lock()
 	defer s.cleanupLock.Unlock()
@@ -125,7 +143,7 @@ func NewSession(service *Instance, request *pb.SessionRequest, tracer *trace.Tra
 		ConsumerID:       identity.FromAddress(request.GetConsumer().GetId()),
 		ConsumerLocation: consumerLocation,
 		HermesID:         common.HexToAddress(request.GetConsumer().GetHermesID()),
-		Proposal:         service.Proposal,
+		Proposal:         service.CopyProposal(),
 		ServiceID:        string(service.ID),
 		CreatedAt:        time.Now().UTC(),
 		request:          request,
@@ -31,6 +31,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat/event"
@@ -49,6 +50,8 @@ var (
 	ErrorSessionNotExists = errors.New("session does not exists")
 	// ErrorWrongSessionOwner returned when consumer tries to destroy session that does not belongs to him
 	ErrorWrongSessionOwner = errors.New("wrong session owner")
+	// ErrorConsumerBanned returned when consumer identity is in the provider's ban list
+	ErrorConsumerBanned = session.NewRejection(session.RejectionConsumerBanned, "consumer identity is banned by the provider")
 )
 
 // IDGenerator defines method for session id generation
@@ -58,6 +61,8 @@ type IDGenerator func() (session.ID, error)
 type ConfigParams struct {
 	SessionServiceConfig   ServiceConfiguration
 	SessionDestroyCallback DestroyCallback
+	// SessionTunnelIPs are the addresses assigned to the consumer inside the tunnel, if the service knows them.
+	SessionTunnelIPs []net.IP
 }
 
 // ServiceConfiguration defines service configuration from underlying transport mechanism to be passed to remote party
@@ -115,6 +120,22 @@ type PriceValidator interface {
 	IsPriceValid(in market.Price, nodeType string, country string) bool
 }
 
+// PricingPolicy determines the price provider charges for the service.
+type PricingPolicy interface {
+	Price(nodeType string, country string) market.Price
+	PriceFor(consumerID identity.Identity, policyIDs []string, nodeType string, country string) market.Price
+}
+
+// BanChecker tells whether the consumer is banned by the provider.
+type BanChecker interface {
+	IsBanned(consumerID identity.Identity) (bool, error)
+}
+
+// CountryResolver resolves the country of an IP address.
+type CountryResolver interface {
+	ResolveCountry(ip net.IP) (string, error)
+}
+
 // PaymentEngine is responsible for interacting with the consumer in regard to payments.
 type PaymentEngine interface {
 	Start() error
@@ -136,6 +157,8 @@ func NewSessionManager(
 	channel p2p.Channel,
 	config Config,
 	priceValidator PriceValidator,
+	banChecker BanChecker,
+	countryResolver CountryResolver,
 ) *SessionManager {
 	return &SessionManager{
 		service:              service,
@@ -146,6 +169,8 @@ func NewSessionManager(
 		channel:              channel,
 		config:               config,
 		priceValidator:       priceValidator,
+		banChecker:           banChecker,
+		countryResolver:      countryResolver,
 	}
 }
 
@@ -159,6 +184,8 @@ type SessionManager struct {
 	channel              p2p.Channel
 	config               Config
 	priceValidator       PriceValidator
+	banChecker           BanChecker
+	countryResolver      CountryResolver
 }
 
 // Start starts a session on the provider side for the given consumer.
@@ -199,23 +226,87 @@ func (manager *SessionManager) Start(request *pb.SessionRequest) (_ pb.SessionRe
 	prices := manager.remapPricing(request.Consumer.Pricing)
 
 	if err = manager.startSession(session, prices); err != nil {
+		if code, ok := rejectionCode(err); ok {
+			return pb.SessionResponse{
+				ID:        string(session.ID),
+				Error:     err.Error(),
+				ErrorCode: code,
+			}, err
+		}
 		return pb.SessionResponse{}, err
 	}
-	if err = manager.paymentLoop(session, prices); err != nil {
+	if err = manager.paymentLoop(session, manager.chargedPrice(session.ConsumerID, prices)); err != nil {
 		return pb.SessionResponse{}, err
 	}
 
 	return manager.providerService(session, manager.channel)
 }
 
-func (manager *SessionManager) validatePrice(in market.Price, nodeType, country string) error {
-	if !manager.priceValidator.IsPriceValid(in, nodeType, country) {
+// rejectionCode tells the consumer why the session was refused, so it could try another provider.
+func rejectionCode(err error) (string, bool) {
+	var rejection *session.RejectionError
+	if !errors.As(err, &rejection) {
+		return "", false
+	}
+
+	return string(rejection.Code), true
+}
+
+func (manager *SessionManager) validatePrice(consumerID identity.Identity, in market.Price) error {
+	proposal := manager.service.CopyProposal()
+	location := proposal.Location
+	if !manager.priceValidator.IsPriceValid(in, location.IPType, location.Country) {
 		return errors.New("consumer asking for invalid price")
 	}
+	if manager.service.pricing == nil {
+		return nil
+	}
+
+	// Consumer may have picked up the proposal before the price changed, so the last advertised price is honoured too.
+	if advertised := proposal.Price; advertised != nil && priceEqual(*advertised, in) {
+		return nil
+	}
+	if !priceAtLeast(in, manager.consumerPrice(consumerID)) {
+		return errors.New("consumer asking for price below the pricing policy")
+	}
 
 	return nil
 }
 
+// chargedPrice returns the price consumer is invoiced for, it never exceeds the price consumer agreed to.
+func (manager *SessionManager) chargedPrice(consumerID identity.Identity, agreed market.Price) market.Price {
+	if manager.service.pricing == nil {
+		return agreed
+	}
+
+	policy := manager.consumerPrice(consumerID)
+	return market.Price{
+		PricePerHour: minBigInt(agreed.PricePerHour, policy.PricePerHour),
+		PricePerGiB:  minBigInt(agreed.PricePerGiB, policy.PricePerGiB),
+	}
+}
+
+func (manager *SessionManager) consumerPrice(consumerID identity.Identity) market.Price {
+	location := manager.service.CopyProposal().Location
+	policyIDs := manager.service.Policies().IdentityPolicies(consumerID)
+	return manager.service.pricing.PriceFor(consumerID, policyIDs, location.IPType, location.Country)
+}
+
+func priceEqual(a, b market.Price) bool {
+	return a.PricePerHour.Cmp(b.PricePerHour) == 0 && a.PricePerGiB.Cmp(b.PricePerGiB) == 0
+}
+
+func priceAtLeast(in, min market.Price) bool {
+	return in.PricePerHour.Cmp(min.PricePerHour) >= 0 && in.PricePerGiB.Cmp(min.PricePerGiB) >= 0
+}
+
+func minBigInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
 func (manager *SessionManager) remapPricing(in *pb.Pricing) market.Price {
 	// This prevents panics in case of malicious consumers.
 	if in == nil || in.PerGib == nil || in.PerHour == nil {
@@ -249,6 +340,10 @@ func (manager *SessionManager) startSession(session *Session, prices market.Pric
 	trace := session.tracer.StartStage("Provider session create (start)")
 	defer session.tracer.EndStage(trace)
 
+	// Capacity is checked and the session is added at once, so concurrent requests do not exceed the limits.
+	manager.service.capacityLock.Lock()
+	defer manager.service.capacityLock.Unlock()
+
 	if err := manager.validateSession(session, prices); err != nil {
 		return err
 	}
@@ -267,11 +362,45 @@ func (manager *SessionManager) startSession(session *Session, prices market.Pric
 }
 
 func (manager *SessionManager) validateSession(session *Session, prices market.Price) error {
+	if manager.service.State() == servicestate.Draining {
+		return ErrorServiceDraining
+	}
+
+	banned, err := manager.banChecker.IsBanned(session.ConsumerID)
+	if err != nil {
+		return fmt.Errorf("cannot check consumer ban list: %w", err)
+	}
+	if banned {
+		return ErrorConsumerBanned
+	}
+
 	if !manager.service.Policies().IsIdentityAllowed(session.ConsumerID) {
 		return fmt.Errorf("consumer identity is not allowed: %s", session.ConsumerID.Address)
 	}
+	// Country reported by the consumer can not be trusted, so it is resolved from the address of the peer.
+	if country := manager.consumerCountry(); !manager.service.Policies().IsCountryAllowed(country) {
+		return fmt.Errorf("consumer country is not allowed: %s", country)
+	}
+	if err := manager.service.checkCapacity(session.ConsumerID); err != nil {
+		return err
+	}
 
-	return manager.validatePrice(prices, manager.service.Proposal.Location.IPType, manager.service.Proposal.Location.Country)
+	return manager.validatePrice(session.ConsumerID, prices)
+}
+
+// consumerCountry resolves the country of the consumer, it is empty if the country is unknown.
+func (manager *SessionManager) consumerCountry() string {
+	addr := manager.channel.RemoteAddr()
+	if addr == nil {
+		return ""
+	}
+
+	country, err := manager.countryResolver.ResolveCountry(addr.IP)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to resolve country of consumer address %s", addr.IP)
+		return ""
+	}
+	return country
 }
 
 func (manager *SessionManager) clearStaleSession(consumerID identity.Identity, serviceType string) {
@@ -346,6 +475,7 @@ func (manager *SessionManager) providerService(session *Session, channel p2p.Cha
 		return pb.SessionResponse{}, fmt.Errorf("cannot get provider config for session %s: %w", string(session.ID), err)
 	}
 
+	session.setTunnelIPs(config.SessionTunnelIPs)
 	if config.SessionDestroyCallback != nil {
 		session.addCleanup(func() error {
 			config.SessionDestroyCallback()
@@ -36,6 +36,7 @@ import (
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/trace"
 	"github.com/mysteriumnetwork/node/utils/reftracker"
@@ -76,7 +77,8 @@ func (m mockBalanceTracker) WaitFirstInvoice(time.Duration) error {
 }
 
 type mockP2PChannel struct {
-	tracer *trace.Tracer
+	tracer     *trace.Tracer
+	remoteAddr *net.UDPAddr
 }
 
 func (m *mockP2PChannel) Send(_ context.Context, _ string, _ *p2p.Message) (*p2p.Message, error) {
@@ -94,6 +96,8 @@ func (m *mockP2PChannel) ServiceConn() *net.UDPConn { return nil }
 
 func (m *mockP2PChannel) Conn() *net.UDPConn { return nil }
 
+func (m *mockP2PChannel) RemoteAddr() *net.UDPAddr { return m.remoteAddr }
+
 func (m *mockP2PChannel) Close() error { return nil }
 
 func (m *mockP2PChannel) ID() string { return fmt.Sprintf("%p", m) }
@@ -320,6 +324,8 @@ func newManager(service *Instance, sessions *SessionPool, publisher publisher, p
 		&mockPriceValidator{
 			toReturn: isPriceValid,
 		},
+		&mockBanChecker{},
+		&mockCountryResolver{},
 	)
 	reftracker.Singleton().Put("channel:"+ch.ID(), 10*time.Second, func() { ch.Close() })
 	return m
@@ -352,3 +358,250 @@ type mockPriceValidator struct {
 func (mpv *mockPriceValidator) IsPriceValid(in market.Price, nodeType, country string) bool {
 	return mpv.toReturn
 }
+
+type mockBanChecker struct {
+	banned []identity.Identity
+}
+
+func (mbc *mockBanChecker) IsBanned(consumerID identity.Identity) (bool, error) {
+	for _, id := range mbc.banned {
+		if id == consumerID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type mockCountryResolver struct {
+	country string
+}
+
+func (mcr *mockCountryResolver) ResolveCountry(ip net.IP) (string, error) {
+	return mcr.country, nil
+}
+
+func TestManager_Start_RejectsConsumerFromDeniedCountry(t *testing.T) {
+	policies := policy.NewRepository()
+	policies.SetPolicyRules(
+		market.AccessPolicy{ID: "countries", Source: "local"},
+		market.AccessPolicyRuleSet{
+			ID:    "countries",
+			Allow: []market.AccessRule{{Type: market.AccessPolicyTypeConsumerCountry, Value: "LT"}},
+		},
+	)
+	service := NewInstance(
+		identity.FromAddress(currentProposal.ProviderID),
+		currentProposal.ServiceType,
+		struct{}{},
+		currentProposal,
+		servicestate.Running,
+		&mockService{},
+		policies,
+		&mockDiscovery{},
+	)
+
+	publisher := mocks.NewEventBus()
+	manager := newManager(service, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, true)
+	manager.channel.(*mockP2PChannel).remoteAddr = &net.UDPAddr{IP: net.ParseIP("1.2.3.4")}
+	manager.countryResolver = &mockCountryResolver{country: "RU"}
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Pricing: &pb.Pricing{
+				PerGib:  big.NewInt(1).Bytes(),
+				PerHour: big.NewInt(1).Bytes(),
+			},
+			Location: &pb.LocationInfo{Country: "LT"},
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.EqualError(t, err, "consumer country is not allowed: RU")
+}
+
+func TestManager_Start_RejectsBannedConsumer(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	manager := newManager(currentService, sessionStore, publisher, &mockBalanceTracker{}, true)
+	manager.banChecker = &mockBanChecker{banned: []identity.Identity{consumerID}}
+
+	response, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Pricing: &pb.Pricing{
+				PerGib:  big.NewInt(1).Bytes(),
+				PerHour: big.NewInt(1).Bytes(),
+			},
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.ErrorIs(t, err, ErrorConsumerBanned)
+	assert.Equal(t, ErrorConsumerBanned.Error(), response.Error)
+	assert.Equal(t, string(session.RejectionConsumerBanned), response.ErrorCode)
+	assert.Len(t, sessionStore.GetAll(), 0)
+}
+
+func TestManager_Start_RejectsWhenCapacityExceeded(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	service := pricedService(nil)
+	service.capacity = Capacity{MaxSessions: 1}
+	service.sessions = sessionStore
+	sessionStore.Add(&Session{
+		ID:         "existing",
+		ConsumerID: identity.FromAddress("0x2"),
+		ServiceID:  string(service.ID),
+		Proposal:   currentProposal,
+	})
+	manager := newManager(service, sessionStore, publisher, &mockBalanceTracker{}, true)
+
+	response, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Pricing: &pb.Pricing{
+				PerGib:  big.NewInt(1).Bytes(),
+				PerHour: big.NewInt(1).Bytes(),
+			},
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.ErrorIs(t, err, ErrorCapacityExceeded)
+	assert.Equal(t, string(session.RejectionCapacityExceeded), response.ErrorCode)
+	assert.Len(t, sessionStore.GetAll(), 1)
+}
+
+func TestManager_Start_RejectsWhenServiceDraining(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	service := pricedService(nil)
+	service.state = servicestate.Draining
+	manager := newManager(service, sessionStore, publisher, &mockBalanceTracker{}, true)
+
+	response, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Pricing: &pb.Pricing{
+				PerGib:  big.NewInt(1).Bytes(),
+				PerHour: big.NewInt(1).Bytes(),
+			},
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.ErrorIs(t, err, ErrorServiceDraining)
+	assert.Equal(t, string(session.RejectionServiceDraining), response.ErrorCode)
+	assert.Len(t, sessionStore.GetAll(), 0)
+}
+
+func TestManager_Start_RejectsPriceBelowPricingPolicy(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	service := pricedService(&mockPricingPolicy{
+		price:         *market.NewPrice(10, 10),
+		consumerPrice: *market.NewPrice(10, 10),
+	})
+	manager := newManager(service, sessionStore, publisher, &mockBalanceTracker{}, true)
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Pricing: &pb.Pricing{
+				PerGib:  big.NewInt(5).Bytes(),
+				PerHour: big.NewInt(10).Bytes(),
+			},
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "consumer asking for price below the pricing policy", err.Error())
+}
+
+func TestManager_Start_ChargesPricingPolicyPrice(t *testing.T) {
+	tests := map[string]struct {
+		offered  market.Price
+		expected market.Price
+	}{
+		"consumer discount is applied": {
+			offered:  *market.NewPrice(10, 10),
+			expected: *market.NewPrice(8, 8),
+		},
+		"last advertised price is honoured": {
+			offered:  *market.NewPrice(6, 6),
+			expected: *market.NewPrice(6, 6),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			publisher := mocks.NewEventBus()
+			service := pricedService(&mockPricingPolicy{
+				price:         *market.NewPrice(10, 10),
+				consumerPrice: *market.NewPrice(8, 8),
+			})
+			service.Proposal.Price = market.NewPrice(6, 6)
+
+			var charged market.Price
+			ch := &mockP2PChannel{tracer: trace.NewTracer("Provider connect")}
+			manager := NewSessionManager(
+				service,
+				NewSessionPool(publisher),
+				func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage, price market.Price) (PaymentEngine, error) {
+					charged = price
+					return &mockBalanceTracker{}, nil
+				},
+				publisher,
+				ch,
+				DefaultConfig(),
+				&mockPriceValidator{toReturn: true},
+				&mockBanChecker{},
+				&mockCountryResolver{},
+			)
+			reftracker.Singleton().Put("channel:"+ch.ID(), 10*time.Second, func() { ch.Close() })
+
+			_, err := manager.Start(&pb.SessionRequest{
+				Consumer: &pb.ConsumerInfo{
+					Id:       consumerID.Address,
+					HermesID: hermesID.String(),
+					Pricing: &pb.Pricing{
+						PerGib:  tc.offered.PricePerGiB.Bytes(),
+						PerHour: tc.offered.PricePerHour.Bytes(),
+					},
+				},
+				ProposalID: int64(currentProposalID),
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, charged)
+		})
+	}
+}
+
+func pricedService(pricing PricingPolicy) *Instance {
+	instance := NewInstance(
+		identity.FromAddress(currentProposal.ProviderID),
+		currentProposal.ServiceType,
+		struct{}{},
+		currentProposal,
+		servicestate.Running,
+		&mockService{},
+		policy.NewRepository(),
+		&mockDiscovery{},
+	)
+	instance.pricing = pricing
+	return instance
+}
+
+type mockPricingPolicy struct {
+	price         market.Price
+	consumerPrice market.Price
+}
+
+func (mpp *mockPricingPolicy) Price(nodeType, country string) market.Price {
+	return mpp.price
+}
+
+func (mpp *mockPricingPolicy) PriceFor(consumerID identity.Identity, policyIDs []string, nodeType, country string) market.Price {
+	return mpp.consumerPrice
+}
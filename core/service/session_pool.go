@@ -69,6 +69,14 @@ func (sp *SessionPool) GetAll() []*Session {
 	return sessions
 }
 
+// Count returns the count of sessions in storage.
+func (sp *SessionPool) Count() int {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	return len(sp.sessions)
+}
+
 // Find returns underlying session instance
 func (sp *SessionPool) Find(id session.ID) (*Session, bool) {
 	sp.lock.Lock()
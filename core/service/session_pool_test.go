@@ -103,6 +103,14 @@ func TestSessionPool_GetAll(t *testing.T) {
 	assert.Contains(t, sessions, sessionSecond)
 }
 
+func TestSessionPool_Count(t *testing.T) {
+	pool := mockPool(mocks.NewEventBus(), sessionExisting)
+	assert.Equal(t, 1, pool.Count())
+
+	pool.Remove(sessionExisting.ID)
+	assert.Equal(t, 0, pool.Count())
+}
+
 func TestSessionPool_Remove(t *testing.T) {
 	pool := mockPool(mocks.NewEventBus(), sessionExisting)
 
@@ -46,6 +46,10 @@ func subscribeSessionCreate(mng *SessionManager, ch p2p.ChannelHandler) {
 		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionCreate, request.String())
 
 		response, err := mng.Start(&request)
+		if response.Error != "" {
+			// Consumer is told why the session was rejected instead of getting a generic internal error.
+			return c.OkWithReply(p2p.ProtoMessage(&response))
+		}
 		if err != nil {
 			return fmt.Errorf("cannot start session: %s: %w", response.ID, err)
 		}
@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sysmon
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/mem"
+	"github.com/shirou/gopsutil/net"
+
+	"github.com/mysteriumnetwork/node/core/service"
+)
+
+// Monitor measures utilization of the host periodically, so services could keep the configured headroom.
+type Monitor struct {
+	interval time.Duration
+
+	cpuPercent    func() (float64, error)
+	memAvailable  func() (uint64, error)
+	ifaceCounters func() ([]net.IOCountersStat, error)
+
+	lock         sync.RWMutex
+	usage        service.ResourceUsage
+	measured     bool
+	lastCounters map[string]net.IOCountersStat
+	lastSampled  time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMonitor creates resource monitor which measures utilization every interval.
+func NewMonitor(interval time.Duration) *Monitor {
+	return &Monitor{
+		interval:      interval,
+		cpuPercent:    cpuPercent,
+		memAvailable:  memAvailable,
+		ifaceCounters: ifaceCounters,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start measures utilization until the monitor is stopped.
+func (m *Monitor) Start() {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.sample(time.Now())
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.sample(now)
+		}
+	}
+}
+
+// Stop stops measuring utilization.
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// Usage returns the last measured utilization, false is returned until the first measurement is done.
+func (m *Monitor) Usage() (service.ResourceUsage, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.usage, m.measured
+}
+
+func (m *Monitor) sample(now time.Time) {
+	cpuUsed, err := m.cpuPercent()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to measure CPU usage")
+		return
+	}
+	memAvailable, err := m.memAvailable()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to measure available memory")
+		return
+	}
+	counters, err := m.ifaceCounters()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to measure network traffic")
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.usage = service.ResourceUsage{
+		CPU:             cpuUsed,
+		MemoryAvailable: memAvailable,
+		Throughput:      m.throughput(counters, now),
+	}
+	m.measured = true
+}
+
+// throughput returns the traffic of the busiest interface since the last sample.
+// Tunnelled traffic passes the uplink interface too, so the busiest one is taken instead of the sum.
+// Loopback interfaces are skipped.
+func (m *Monitor) throughput(counters []net.IOCountersStat, now time.Time) uint64 {
+	elapsed := now.Sub(m.lastSampled).Seconds()
+
+	var busiest uint64
+	current := make(map[string]net.IOCountersStat, len(counters))
+	for _, c := range counters {
+		if strings.HasPrefix(c.Name, "lo") {
+			continue
+		}
+		current[c.Name] = c
+
+		last, ok := m.lastCounters[c.Name]
+		if !ok || elapsed <= 0 || c.BytesSent < last.BytesSent || c.BytesRecv < last.BytesRecv {
+			continue
+		}
+		bytes := c.BytesSent - last.BytesSent
+		if recv := c.BytesRecv - last.BytesRecv; recv > bytes {
+			bytes = recv
+		}
+		if bps := uint64(float64(bytes*8) / elapsed); bps > busiest {
+			busiest = bps
+		}
+	}
+
+	m.lastCounters = current
+	m.lastSampled = now
+	return busiest
+}
+
+func cpuPercent() (float64, error) {
+	percent, err := cpu.Percent(0, false)
+	if err != nil || len(percent) == 0 {
+		return 0, err
+	}
+	return percent[0], nil
+}
+
+func memAvailable() (uint64, error) {
+	stat, err := mem.VirtualMemory()
+	if err != nil {
+		return 0, err
+	}
+	return stat.Available, nil
+}
+
+func ifaceCounters() ([]net.IOCountersStat, error) {
+	return net.IOCounters(true)
+}
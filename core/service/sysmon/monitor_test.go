@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sysmon
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/net"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/service"
+)
+
+func TestMonitor_Usage(t *testing.T) {
+	// given
+	counters := []net.IOCountersStat{
+		{Name: "lo", BytesSent: 0, BytesRecv: 0},
+		{Name: "eth0", BytesSent: 1000, BytesRecv: 5000},
+		{Name: "wg0", BytesSent: 0, BytesRecv: 0},
+	}
+	monitor := NewMonitor(time.Second)
+	monitor.cpuPercent = func() (float64, error) { return 42.5, nil }
+	monitor.memAvailable = func() (uint64, error) { return 512 * 1024 * 1024, nil }
+	monitor.ifaceCounters = func() ([]net.IOCountersStat, error) { return counters, nil }
+
+	_, measured := monitor.Usage()
+	assert.False(t, measured)
+
+	// when
+	start := time.Now()
+	monitor.sample(start)
+	counters = []net.IOCountersStat{
+		{Name: "lo", BytesSent: 100000000, BytesRecv: 100000000},
+		{Name: "eth0", BytesSent: 1000 + 2000000, BytesRecv: 5000 + 500000},
+		{Name: "wg0", BytesSent: 250000, BytesRecv: 1000000},
+	}
+	monitor.sample(start.Add(2 * time.Second))
+
+	// then
+	usage, measured := monitor.Usage()
+	assert.True(t, measured)
+	assert.Equal(t, service.ResourceUsage{
+		CPU:             42.5,
+		MemoryAvailable: 512 * 1024 * 1024,
+		Throughput:      8000000,
+	}, usage)
+}
+
+func TestMonitor_KeepsLastUsageOnFailure(t *testing.T) {
+	// given
+	monitor := NewMonitor(time.Second)
+	monitor.cpuPercent = func() (float64, error) { return 10, nil }
+	monitor.memAvailable = func() (uint64, error) { return 1024, nil }
+	monitor.ifaceCounters = func() ([]net.IOCountersStat, error) { return nil, nil }
+	monitor.sample(time.Now())
+
+	// when
+	monitor.cpuPercent = func() (float64, error) { return 0, errors.New("boom") }
+	monitor.sample(time.Now())
+
+	// then
+	usage, measured := monitor.Usage()
+	assert.True(t, measured)
+	assert.Equal(t, 10.0, usage.CPU)
+}
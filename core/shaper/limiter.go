@@ -0,0 +1,275 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+const (
+	storageBucket = "shaper"
+	storageKey    = "rules"
+
+	// AppTopicSessionShaped represents session bandwidth limits change topic.
+	AppTopicSessionShaped = "Session shaped"
+)
+
+// maxClassID is the highest tc class minor number, priorities of filters are derived from it.
+const maxClassID = 0x7fff
+
+// ErrClassesExhausted is returned when there are no free tc classes left for a new session.
+var ErrClassesExhausted = errors.New("no free traffic shaping classes")
+
+// Limits are bandwidth limits of a session in Kbytes per second, zero means unlimited.
+type Limits struct {
+	Up   uint64 `json:"up"`   // Traffic sent by consumer.
+	Down uint64 `json:"down"` // Traffic received by consumer.
+}
+
+// Rules define session limits. Identity overrides take precedence over access policy ones,
+// which take precedence over the default limits.
+type Rules struct {
+	Default    *Limits           `json:"default,omitempty"` // Nil falls back to shaper flags.
+	Identities map[string]Limits `json:"identities"`        // Keyed by consumer identity address.
+	Policies   map[string]Limits `json:"policies"`          // Keyed by ID of access policy allowing the consumer.
+}
+
+// AppEventSessionShaped represents bandwidth limits applied to the session.
+type AppEventSessionShaped struct {
+	SessionID string
+	Limits    Limits
+}
+
+// Session describes consumer tunnel of the shaped session.
+type Session struct {
+	ID         string
+	ConsumerID identity.Identity
+	Interface  string
+	IPs        []net.IP
+	Policies   policyMatcher
+}
+
+type policyMatcher interface {
+	IdentityPolicies(identity identity.Identity) []string
+}
+
+type persistentStorage interface {
+	GetValue(bucket string, key interface{}, to interface{}) error
+	SetValue(bucket string, key interface{}, to interface{}) error
+}
+
+type publisher interface {
+	Publish(topic string, data interface{})
+}
+
+type subscriber interface {
+	SubscribeAsync(topic string, fn interface{}) error
+}
+
+// trafficControl enforces limits of a single consumer tunnel.
+type trafficControl interface {
+	Limit(iface string, ips []net.IP, classID uint16, limits Limits) error
+	Clear(iface string, ips []net.IP, classID uint16)
+}
+
+type limitedSession struct {
+	session Session
+	classID uint16
+	limits  Limits
+}
+
+// Limiter enforces bandwidth limits of active sessions and applies rule changes to them at runtime.
+type Limiter struct {
+	storage   persistentStorage
+	publisher publisher
+	tc        trafficControl
+
+	mu       sync.Mutex
+	rules    Rules
+	sessions map[string]*limitedSession
+	classes  map[uint16]struct{}
+}
+
+// NewLimiter creates session limiter with rules loaded from the storage.
+func NewLimiter(storage persistentStorage, publisher publisher) *Limiter {
+	var rules Rules
+	if err := storage.GetValue(storageBucket, storageKey, &rules); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		log.Error().Err(err).Msg("Failed to load traffic shaping rules")
+	}
+
+	return &Limiter{
+		storage:   storage,
+		publisher: publisher,
+		tc:        newTrafficControl(),
+		rules:     rules,
+		sessions:  make(map[string]*limitedSession),
+		classes:   make(map[uint16]struct{}),
+	}
+}
+
+// Subscribe re-applies limits of active sessions once shaper flags change.
+func (l *Limiter) Subscribe(bus subscriber) error {
+	for _, flag := range []string{config.FlagShaperEnabled.Name, config.FlagShaperBandwidth.Name} {
+		if err := bus.SubscribeAsync(config.AppTopicConfig(flag), l.reapply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start applies limits resolved by the rules to the new session.
+// Session is tracked even if limits fail to apply, so it has to be stopped anyway.
+func (l *Limiter) Start(session Session) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	classID, err := l.allocateClass()
+	if err != nil {
+		return err
+	}
+
+	limited := &limitedSession{session: session, classID: classID}
+	l.sessions[session.ID] = limited
+
+	return l.apply(limited)
+}
+
+// Stop removes limits of the session.
+func (l *Limiter) Stop(sessionID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limited, ok := l.sessions[sessionID]
+	if !ok {
+		return
+	}
+
+	l.tc.Clear(limited.session.Interface, limited.session.IPs, limited.classID)
+	delete(l.classes, limited.classID)
+	delete(l.sessions, sessionID)
+}
+
+// Rules returns the current limit rules.
+func (l *Limiter) Rules() Rules {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rules
+}
+
+// SetRules stores the limit rules and applies them to active sessions.
+func (l *Limiter) SetRules(rules Rules) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.storage.SetValue(storageBucket, storageKey, rules); err != nil {
+		return err
+	}
+	l.rules = rules
+
+	l.applyAll()
+	return nil
+}
+
+// SessionLimits returns limits currently applied to the active sessions.
+func (l *Limiter) SessionLimits() map[string]Limits {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limits := make(map[string]Limits, len(l.sessions))
+	for id, limited := range l.sessions {
+		limits[id] = limited.limits
+	}
+	return limits
+}
+
+func (l *Limiter) reapply(_ interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.applyAll()
+}
+
+func (l *Limiter) applyAll() {
+	for _, limited := range l.sessions {
+		if err := l.apply(limited); err != nil {
+			log.Error().Err(err).Msgf("Failed to shape traffic of session %s", limited.session.ID)
+		}
+	}
+}
+
+func (l *Limiter) apply(limited *limitedSession) error {
+	session := limited.session
+	limits := l.limitsFor(session)
+
+	l.tc.Clear(session.Interface, session.IPs, limited.classID)
+	if limits != (Limits{}) {
+		if err := l.tc.Limit(session.Interface, session.IPs, limited.classID, limits); err != nil {
+			return err
+		}
+	}
+
+	limited.limits = limits
+	l.publisher.Publish(AppTopicSessionShaped, AppEventSessionShaped{
+		SessionID: session.ID,
+		Limits:    limits,
+	})
+	return nil
+}
+
+func (l *Limiter) limitsFor(session Session) Limits {
+	if limits, ok := l.rules.Identities[session.ConsumerID.Address]; ok {
+		return limits
+	}
+
+	if session.Policies != nil {
+		for _, policyID := range session.Policies.IdentityPolicies(session.ConsumerID) {
+			if limits, ok := l.rules.Policies[policyID]; ok {
+				return limits
+			}
+		}
+	}
+
+	if l.rules.Default != nil {
+		return *l.rules.Default
+	}
+	if !config.GetBool(config.FlagShaperEnabled) {
+		return Limits{}
+	}
+	bandwidth := config.GetUInt64(config.FlagShaperBandwidth)
+	return Limits{Up: bandwidth, Down: bandwidth}
+}
+
+func (l *Limiter) allocateClass() (uint16, error) {
+	// Class 1 is left for the default traffic of the interface.
+	for id := uint16(2); id <= maxClassID; id++ {
+		if _, ok := l.classes[id]; !ok {
+			l.classes[id] = struct{}{}
+			return id, nil
+		}
+	}
+	return 0, ErrClassesExhausted
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"net"
+	"testing"
+
+	"github.com/asdine/storm/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type mockStorage struct {
+	rules *Rules
+}
+
+func (ms *mockStorage) GetValue(_ string, _ interface{}, to interface{}) error {
+	if ms.rules == nil {
+		return storm.ErrNotFound
+	}
+	*to.(*Rules) = *ms.rules
+	return nil
+}
+
+func (ms *mockStorage) SetValue(_ string, _ interface{}, value interface{}) error {
+	rules := value.(Rules)
+	ms.rules = &rules
+	return nil
+}
+
+type mockPublisher struct {
+	events []AppEventSessionShaped
+}
+
+func (mp *mockPublisher) Publish(_ string, data interface{}) {
+	mp.events = append(mp.events, data.(AppEventSessionShaped))
+}
+
+type mockTC struct {
+	limits map[uint16]Limits
+}
+
+func (mt *mockTC) Limit(_ string, _ []net.IP, classID uint16, limits Limits) error {
+	mt.limits[classID] = limits
+	return nil
+}
+
+func (mt *mockTC) Clear(_ string, _ []net.IP, classID uint16) {
+	delete(mt.limits, classID)
+}
+
+type mockPolicies map[string][]string
+
+func (mp mockPolicies) IdentityPolicies(identity identity.Identity) []string {
+	return mp[identity.Address]
+}
+
+func newTestLimiter(rules *Rules) (*Limiter, *mockTC, *mockPublisher) {
+	tc := &mockTC{limits: map[uint16]Limits{}}
+	publisher := &mockPublisher{}
+	limiter := NewLimiter(&mockStorage{rules: rules}, publisher)
+	limiter.tc = tc
+	return limiter, tc, publisher
+}
+
+func Test_Limiter_ResolvesSessionLimits(t *testing.T) {
+	limiter, tc, publisher := newTestLimiter(&Rules{
+		Default:    &Limits{Up: 100, Down: 200},
+		Identities: map[string]Limits{"0x1": {Up: 1, Down: 2}},
+		Policies:   map[string]Limits{"friends": {Up: 10, Down: 20}},
+	})
+	policies := mockPolicies{"0x1": {"friends"}, "0x2": {"strangers", "friends"}}
+
+	assert.NoError(t, limiter.Start(Session{ID: "s1", ConsumerID: identity.FromAddress("0x1"), Policies: policies}))
+	assert.NoError(t, limiter.Start(Session{ID: "s2", ConsumerID: identity.FromAddress("0x2"), Policies: policies}))
+	assert.NoError(t, limiter.Start(Session{ID: "s3", ConsumerID: identity.FromAddress("0x3"), Policies: policies}))
+
+	assert.Equal(t, map[string]Limits{
+		"s1": {Up: 1, Down: 2},
+		"s2": {Up: 10, Down: 20},
+		"s3": {Up: 100, Down: 200},
+	}, limiter.SessionLimits())
+	assert.Len(t, tc.limits, 3)
+	assert.Equal(t, AppEventSessionShaped{SessionID: "s3", Limits: Limits{Up: 100, Down: 200}}, publisher.events[2])
+
+	limiter.Stop("s1")
+	assert.Len(t, tc.limits, 2)
+	assert.NotContains(t, limiter.SessionLimits(), "s1")
+}
+
+func Test_Limiter_SetRulesAppliesToActiveSessions(t *testing.T) {
+	limiter, tc, publisher := newTestLimiter(nil)
+
+	assert.NoError(t, limiter.Start(Session{ID: "s1", ConsumerID: identity.FromAddress("0x1")}))
+	assert.Empty(t, tc.limits, "sessions are not limited by default")
+
+	rules := Rules{Identities: map[string]Limits{"0x1": {Up: 5, Down: 50}}}
+	assert.NoError(t, limiter.SetRules(rules))
+	assert.Equal(t, rules, limiter.Rules())
+	assert.Equal(t, map[uint16]Limits{2: {Up: 5, Down: 50}}, tc.limits)
+	assert.Equal(t, AppEventSessionShaped{SessionID: "s1", Limits: Limits{Up: 5, Down: 50}}, publisher.events[1])
+
+	reloaded, _, _ := newTestLimiter(limiter.storage.(*mockStorage).rules)
+	assert.Equal(t, rules, reloaded.Rules())
+
+	assert.NoError(t, limiter.SetRules(Rules{}))
+	assert.Empty(t, tc.limits)
+	assert.Equal(t, map[string]Limits{"s1": {}}, limiter.SessionLimits())
+}
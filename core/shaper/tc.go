@@ -0,0 +1,43 @@
+// +build !linux
+
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"net"
+
+	"github.com/rs/zerolog/log"
+)
+
+// noopTC does no shaping
+type noopTC struct{}
+
+func newTrafficControl() trafficControl {
+	return &noopTC{}
+}
+
+// Limit noop
+func (noopTC) Limit(_ string, _ []net.IP, _ uint16, _ Limits) error {
+	log.Warn().Msg("Session traffic shaping is only supported under linux")
+	return nil
+}
+
+// Clear noop
+func (noopTC) Clear(_ string, _ []net.IP, _ uint16) {
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/utils/cmdutil"
+)
+
+// policeBurst is the burst size of consumer upload policing.
+const policeBurst = "64k"
+
+// tc shapes consumer download with HTB classes and polices consumer upload on ingress,
+// both are keyed on the consumer tunnel IPs.
+type tc struct{}
+
+func newTrafficControl() trafficControl {
+	return &tc{}
+}
+
+// Limit applies limits to the traffic of the given IPs.
+// IPv4 and IPv6 filters use separate priorities, as tc filters of the same priority must share the protocol.
+func (t *tc) Limit(iface string, ips []net.IP, classID uint16, limits Limits) error {
+	if limits.Down > 0 {
+		if err := ensureQdisc(iface, "root", "handle", "1:", "htb"); err != nil {
+			return err
+		}
+		if err := cmdutil.SudoExec("tc", "class", "replace", "dev", iface, "parent", "1:", "classid", fmt.Sprintf("1:%x", classID),
+			"htb", "rate", rate(limits.Down)); err != nil {
+			return err
+		}
+		for _, ip := range ips {
+			args := []string{"tc", "filter", "add", "dev", iface, "parent", "1:"}
+			args = append(args, ipMatch(ip, classID, "dst")...)
+			args = append(args, "flowid", fmt.Sprintf("1:%x", classID))
+			if err := cmdutil.SudoExec(args...); err != nil {
+				return err
+			}
+		}
+	}
+
+	if limits.Up > 0 {
+		if err := ensureQdisc(iface, "handle", "ffff:", "ingress"); err != nil {
+			return err
+		}
+		for _, ip := range ips {
+			args := []string{"tc", "filter", "add", "dev", iface, "parent", "ffff:"}
+			args = append(args, ipMatch(ip, classID, "src")...)
+			args = append(args, "police", "rate", rate(limits.Up), "burst", policeBurst, "drop", "flowid", ":1")
+			if err := cmdutil.SudoExec(args...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Clear removes limits of the given IPs, errors are ignored as there might be nothing to remove.
+func (t *tc) Clear(iface string, ips []net.IP, classID uint16) {
+	for _, parent := range []string{"1:", "ffff:"} {
+		for _, ip := range ips {
+			_ = cmdutil.SudoExec("tc", "filter", "del", "dev", iface, "parent", parent, "prio", priority(ip, classID))
+		}
+	}
+	_ = cmdutil.SudoExec("tc", "class", "del", "dev", iface, "classid", fmt.Sprintf("1:%x", classID))
+}
+
+func ensureQdisc(iface string, args ...string) error {
+	out, err := cmdutil.ExecOutput("tc", "qdisc", "show", "dev", iface)
+	if err != nil {
+		return err
+	}
+	// Qdisc is shown by its handle, e.g. "qdisc htb 1: root" or "qdisc ingress ffff: parent ffff:fff1".
+	kind, handle := args[len(args)-1], args[len(args)-2]
+	if strings.Contains(out, "qdisc "+kind+" "+handle) {
+		return nil
+	}
+
+	return cmdutil.SudoExec(append([]string{"tc", "qdisc", "replace", "dev", iface}, args...)...)
+}
+
+func ipMatch(ip net.IP, classID uint16, direction string) []string {
+	if ip.To4() != nil {
+		return []string{"protocol", "ip", "prio", priority(ip, classID), "u32", "match", "ip", direction, ip.String() + "/32"}
+	}
+	return []string{"protocol", "ipv6", "prio", priority(ip, classID), "u32", "match", "ip6", direction, ip.String() + "/128"}
+}
+
+func priority(ip net.IP, classID uint16) string {
+	prio := uint32(classID) * 2
+	if ip.To4() == nil {
+		prio++
+	}
+	return fmt.Sprint(prio)
+}
+
+func rate(kbytes uint64) string {
+	return fmt.Sprintf("%dkbps", kbytes)
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/core/state/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -189,6 +190,9 @@ func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.SubscribeAsync(sevent.AppTopicTokensEarned, k.consumeServiceSessionEarningsEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(shaper.AppTopicSessionShaped, k.updateSessionBandwidth); err != nil {
+		return err
+	}
 	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionState, k.consumeConnectionStateEvent); err != nil {
 		return err
 	}
@@ -248,6 +252,7 @@ func (k *Keeper) updateServices() {
 			ProviderID:           v.ProviderID.Address,
 			Type:                 v.Type,
 			Options:              v.Options,
+			Capacity:             contract.NewServiceCapacityDTO(v.Capacity()),
 			Status:               string(v.State()),
 			Proposal:             contract.NewProposalDTO(priced),
 			ConnectionStatistics: match.ConnectionStatistics,
@@ -347,6 +352,27 @@ func (k *Keeper) updateSessionStats(e interface{}) {
 	go k.announceStateChanges(nil)
 }
 
+// updates bandwidth limits applied to the session.
+func (k *Keeper) updateSessionBandwidth(evt shaper.AppEventSessionShaped) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	var session *session.History
+	for i := range k.state.Sessions {
+		if string(k.state.Sessions[i].SessionID) == evt.SessionID {
+			session = &k.state.Sessions[i]
+		}
+	}
+	if session == nil {
+		log.Warn().Msgf("Couldn't find a matching session for bandwidth change: %s", evt.SessionID)
+		return
+	}
+
+	session.BandwidthUp = evt.Limits.Up
+	session.BandwidthDown = evt.Limits.Down
+	go k.announceStateChanges(nil)
+}
+
 // updates total tokens earned during the session.
 func (k *Keeper) updateSessionEarnings(e interface{}) {
 	k.lock.Lock()
@@ -29,6 +29,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
@@ -273,6 +274,39 @@ func Test_consumeServiceSessionStatisticsEvent(t *testing.T) {
 	)
 }
 
+func Test_consumeSessionShapedEvent(t *testing.T) {
+	// given
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		Publisher:        eventBus,
+		IdentityProvider: &mocks.IdentityProvider{},
+		EarningsProvider: &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	keeper.Subscribe(eventBus)
+	keeper.state.Sessions = []session.History{
+		{SessionID: nodeSession.ID("1")},
+	}
+
+	// when
+	eventBus.Publish(shaper.AppTopicSessionShaped, shaper.AppEventSessionShaped{
+		SessionID: "1",
+		Limits:    shaper.Limits{Up: 512, Down: 1024},
+	})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return keeper.GetState().Sessions[0].BandwidthDown != 0
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(
+		t,
+		[]session.History{
+			{SessionID: "1", BandwidthUp: 512, BandwidthDown: 1024},
+		},
+		keeper.GetState().Sessions,
+	)
+}
+
 func Test_ConsumesServiceEvents(t *testing.T) {
 	mpr := mockProposalRepository{
 		priceToAdd: market.Price{
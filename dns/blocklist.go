@@ -0,0 +1,193 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// hostsLocalNames are entries of hosts files which are not meant to be blocked.
+var hostsLocalNames = map[string]struct{}{
+	"localhost":             {},
+	"localhost.localdomain": {},
+	"local":                 {},
+	"broadcasthost":         {},
+	"ip6-localhost":         {},
+	"ip6-loopback":          {},
+	"0.0.0.0":               {},
+}
+
+// Blocklist is a set of blocked domains.
+type Blocklist struct {
+	exact      map[string]struct{}
+	wildcard   map[string]struct{} // Domains blocked together with their subdomains.
+	subdomains map[string]struct{} // Domains whose subdomains are blocked only.
+}
+
+// NewBlocklist creates an empty blocklist.
+func NewBlocklist() *Blocklist {
+	return &Blocklist{
+		exact:      make(map[string]struct{}),
+		wildcard:   make(map[string]struct{}),
+		subdomains: make(map[string]struct{}),
+	}
+}
+
+// ParseBlocklist reads blocklist in hosts, AdBlock or RPZ format, formats may be mixed line by line.
+// Unrecognized lines, comments and AdBlock exception rules are skipped.
+func ParseBlocklist(r io.Reader) (*Blocklist, error) {
+	list := NewBlocklist()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		list.parseLine(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (b *Blocklist) parseLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	switch line[0] {
+	case '#', '!', ';', '[', '$', '@':
+		return
+	}
+
+	// AdBlock: ||example.com^
+	if strings.HasPrefix(line, "||") {
+		rule := strings.TrimPrefix(line, "||")
+		if end := strings.IndexAny(rule, "^$/"); end >= 0 {
+			if rule[end] == '$' || (rule[end] == '^' && strings.TrimSpace(rule[end+1:]) != "") {
+				// Rules with modifiers apply to some requests only.
+				return
+			}
+			rule = rule[:end]
+		}
+		b.addWildcard(rule)
+		return
+	}
+
+	if i := strings.IndexAny(line, "#;"); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+
+	// RPZ: example.com CNAME . or *.example.com CNAME .
+	for i := 1; i+1 < len(fields); i++ {
+		if strings.EqualFold(fields[i], "CNAME") && fields[i+1] == "." {
+			if name := strings.TrimPrefix(fields[0], "*."); name != fields[0] {
+				b.addSubdomains(name)
+			} else {
+				b.addExact(name)
+			}
+			return
+		}
+	}
+
+	switch {
+	case len(fields) >= 2 && net.ParseIP(fields[0]) != nil:
+		// Hosts: 0.0.0.0 example.com
+		for _, name := range fields[1:] {
+			if _, ok := hostsLocalNames[strings.ToLower(name)]; !ok {
+				b.addExact(name)
+			}
+		}
+	case len(fields) == 1:
+		// Plain domain list: example.com
+		b.addExact(fields[0])
+	}
+}
+
+func (b *Blocklist) addExact(name string) {
+	if name, ok := normalizeDomain(name); ok {
+		b.exact[name] = struct{}{}
+	}
+}
+
+func (b *Blocklist) addWildcard(name string) {
+	if name, ok := normalizeDomain(name); ok {
+		b.wildcard[name] = struct{}{}
+	}
+}
+
+func (b *Blocklist) addSubdomains(name string) {
+	if name, ok := normalizeDomain(name); ok {
+		b.subdomains[name] = struct{}{}
+	}
+}
+
+// Contains checks if the domain is blocked.
+func (b *Blocklist) Contains(name string) bool {
+	name, ok := normalizeDomain(name)
+	if !ok {
+		return false
+	}
+	if _, ok := b.exact[name]; ok {
+		return true
+	}
+	if matchesDomainOrParent(b.wildcard, name) {
+		return true
+	}
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		return matchesDomainOrParent(b.subdomains, name[i+1:])
+	}
+	return false
+}
+
+// Len returns the count of blocked domains.
+func (b *Blocklist) Len() int {
+	return len(b.exact) + len(b.wildcard) + len(b.subdomains)
+}
+
+// normalizeDomain converts domain to the lower case form without the trailing dot.
+// Single label names are rejected, they are never blocked.
+func normalizeDomain(name string) (string, bool) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	if !strings.Contains(name, ".") || strings.ContainsAny(name, "*/") || net.ParseIP(name) != nil {
+		return "", false
+	}
+	if _, ok := dns.IsDomainName(name); !ok {
+		return "", false
+	}
+	return name, true
+}
+
+// matchesDomainOrParent checks if the domain or any of its parent domains is in the set.
+func matchesDomainOrParent(set map[string]struct{}, name string) bool {
+	for {
+		if _, ok := set[name]; ok {
+			return true
+		}
+		i := strings.IndexByte(name, '.')
+		if i < 0 {
+			return false
+		}
+		name = name[i+1:]
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseBlocklist(t *testing.T) {
+	list, err := ParseBlocklist(strings.NewReader(`
+# hosts
+127.0.0.1 localhost
+0.0.0.0 ads.example.com tracker.example.com # inline comment
+::1 ip6-localhost
+
+! AdBlock
+[Adblock Plus 2.0]
+||doubleclick.net^
+||third-party.example.org^$third-party
+@@||allowed.doubleclick.net^
+
+; RPZ
+$TTL 300
+@ IN SOA localhost. root.localhost. 1 3600 600 86400 300
+malware.example.net CNAME .
+*.phishing.example.net CNAME .
+redirected.example.net CNAME walled-garden.example.net.
+
+plain.example.io
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, list.Len())
+
+	tests := []struct {
+		name    string
+		blocked bool
+	}{
+		{name: "ads.example.com.", blocked: true},
+		{name: "TRACKER.example.com", blocked: true},
+		{name: "sub.ads.example.com", blocked: false},
+		{name: "localhost", blocked: false},
+		{name: "doubleclick.net", blocked: true},
+		{name: "ad.g.doubleclick.net.", blocked: true},
+		{name: "third-party.example.org", blocked: false},
+		{name: "malware.example.net", blocked: true},
+		{name: "sub.malware.example.net", blocked: false},
+		{name: "phishing.example.net", blocked: false},
+		{name: "login.phishing.example.net", blocked: true},
+		{name: "redirected.example.net", blocked: false},
+		{name: "plain.example.io", blocked: true},
+		{name: "example.com", blocked: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.blocked, list.Contains(tt.name))
+		})
+	}
+}
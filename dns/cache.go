@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// maxCacheTTL limits how long responses are cached regardless of their TTL.
+const maxCacheTTL = time.Hour
+
+// Cache keeps successful and name error DNS responses until their TTL expires.
+// Nil cache is valid and caches nothing.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[cacheKey]cacheEntry
+	now     func() time.Time
+}
+
+type cacheKey struct {
+	name   string
+	qtype  uint16
+	qclass uint16
+}
+
+type cacheEntry struct {
+	msg      *dns.Msg
+	storedAt time.Time
+	expireAt time.Time
+}
+
+// NewCache creates response cache holding up to size responses.
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:    size,
+		entries: make(map[cacheKey]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// Get returns cached response to the request with TTLs decreased by the time spent in cache.
+func (c *Cache) Get(req *dns.Msg) *dns.Msg {
+	key, ok := newCacheKey(req)
+	if c == nil || !ok {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	now := c.now()
+	if !now.Before(entry.expireAt) {
+		delete(c.entries, key)
+		return nil
+	}
+
+	resp := entry.msg.Copy()
+	resp.Id = req.Id
+	resp.Question = req.Question
+	elapsed := uint32(now.Sub(entry.storedAt).Seconds())
+	for _, rr := range records(resp) {
+		if rr.Header().Ttl > elapsed {
+			rr.Header().Ttl -= elapsed
+		} else {
+			rr.Header().Ttl = 0
+		}
+	}
+	return resp
+}
+
+// Put caches the response to the request.
+func (c *Cache) Put(req *dns.Msg, resp *dns.Msg) {
+	key, ok := newCacheKey(req)
+	if c == nil || !ok || resp == nil || resp.Truncated {
+		return
+	}
+	if resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError {
+		return
+	}
+
+	ttl, ok := responseTTL(resp)
+	if !ok || ttl == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	c.entries[key] = cacheEntry{
+		msg:      resp.Copy(),
+		storedAt: now,
+		expireAt: now.Add(ttl),
+	}
+}
+
+// evict removes expired responses, or a random one if none have expired.
+func (c *Cache) evict(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.expireAt) {
+			delete(c.entries, key)
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries) < c.size {
+			return
+		}
+		delete(c.entries, key)
+	}
+}
+
+func newCacheKey(req *dns.Msg) (cacheKey, bool) {
+	if req == nil || len(req.Question) != 1 {
+		return cacheKey{}, false
+	}
+	q := req.Question[0]
+	return cacheKey{name: strings.ToLower(q.Name), qtype: q.Qtype, qclass: q.Qclass}, true
+}
+
+// responseTTL returns the lowest TTL of response records, SOA minimum TTL is respected for negative responses.
+func responseTTL(resp *dns.Msg) (ttl time.Duration, ok bool) {
+	lowest := uint32(maxCacheTTL.Seconds())
+	for _, rr := range records(resp) {
+		ok = true
+		if rr.Header().Ttl < lowest {
+			lowest = rr.Header().Ttl
+		}
+		if soa, isSOA := rr.(*dns.SOA); isSOA && soa.Minttl < lowest {
+			lowest = soa.Minttl
+		}
+	}
+	return time.Duration(lowest) * time.Second, ok
+}
+
+// records returns all response records except OPT pseudo-record, which carries no TTL.
+func records(resp *dns.Msg) (rrs []dns.RR) {
+	for _, section := range [][]dns.RR{resp.Answer, resp.Ns, resp.Extra} {
+		for _, rr := range section {
+			if rr.Header().Rrtype == dns.TypeOPT {
+				continue
+			}
+			rrs = append(rrs, rr)
+		}
+	}
+	return rrs
+}
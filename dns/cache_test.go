@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestQuery(name string) *dns.Msg {
+	req := &dns.Msg{}
+	req.SetQuestion(name, dns.TypeA)
+	return req
+}
+
+func newTestResponse(req *dns.Msg, ttl uint32) *dns.Msg {
+	resp := &dns.Msg{}
+	resp.SetReply(req)
+	resp.Answer = []dns.RR{
+		&dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+			A:   net.ParseIP("0.0.0.1"),
+		},
+	}
+	return resp
+}
+
+func Test_Cache_ReturnsResponseWithDecreasedTTL(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(10)
+	cache.now = func() time.Time { return now }
+
+	req := newTestQuery("example.com.")
+	cache.Put(req, newTestResponse(req, 60))
+
+	now = now.Add(20 * time.Second)
+	followingReq := newTestQuery("EXAMPLE.com.")
+	resp := cache.Get(followingReq)
+	assert.NotNil(t, resp)
+	assert.Equal(t, followingReq.Id, resp.Id)
+	assert.Equal(t, followingReq.Question, resp.Question)
+	assert.Equal(t, uint32(40), resp.Answer[0].Header().Ttl)
+
+	now = now.Add(40 * time.Second)
+	assert.Nil(t, cache.Get(req))
+}
+
+func Test_Cache_RespectsSOAMinimumOfNegativeResponses(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(10)
+	cache.now = func() time.Time { return now }
+
+	req := newTestQuery("missing.example.com.")
+	resp := &dns.Msg{}
+	resp.SetRcode(req, dns.RcodeNameError)
+	resp.Ns = []dns.RR{
+		&dns.SOA{
+			Hdr:    dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 3600},
+			Minttl: 30,
+		},
+	}
+	cache.Put(req, resp)
+
+	now = now.Add(29 * time.Second)
+	assert.NotNil(t, cache.Get(req))
+	now = now.Add(time.Second)
+	assert.Nil(t, cache.Get(req))
+}
+
+func Test_Cache_SkipsUncacheableResponses(t *testing.T) {
+	cache := NewCache(10)
+
+	req := newTestQuery("example.com.")
+	failed := &dns.Msg{}
+	failed.SetRcode(req, dns.RcodeServerFailure)
+	cache.Put(req, failed)
+	assert.Nil(t, cache.Get(req))
+
+	cache.Put(req, newTestResponse(req, 0))
+	assert.Nil(t, cache.Get(req))
+
+	truncated := newTestResponse(req, 60)
+	truncated.Truncated = true
+	cache.Put(req, truncated)
+	assert.Nil(t, cache.Get(req))
+}
+
+func Test_Cache_EvictsWhenFull(t *testing.T) {
+	cache := NewCache(2)
+	for _, name := range []string{"a.com.", "b.com.", "c.com."} {
+		req := newTestQuery(name)
+		cache.Put(req, newTestResponse(req, 60))
+	}
+
+	assert.Len(t, cache.entries, 2)
+	assert.NotNil(t, cache.Get(newTestQuery("c.com.")))
+}
+
+func Test_Cache_NilCacheCachesNothing(t *testing.T) {
+	var cache *Cache
+
+	req := newTestQuery("example.com.")
+	cache.Put(req, newTestResponse(req, 60))
+	assert.Nil(t, cache.Get(req))
+}
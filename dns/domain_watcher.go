@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+var errResolveFailed = errors.New("DNS query failed")
+
+const (
+	minRefreshInterval = 30 * time.Second
+	maxRefreshInterval = 10 * time.Minute
+)
+
+// DomainWatcher resolves domain names and keeps track of their addresses.
+// Changes of resolved addresses are reported to the given callback.
+type DomainWatcher struct {
+	resolver dns.Handler
+	domains  []string
+	onChange func(added, removed []net.IP)
+
+	mu      sync.Mutex
+	current map[string]net.IP
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewDomainWatcher returns domain watcher resolving addresses with the given DNS handler.
+func NewDomainWatcher(resolver dns.Handler, domains []string, onChange func(added, removed []net.IP)) *DomainWatcher {
+	return &DomainWatcher{
+		resolver: resolver,
+		domains:  domains,
+		onChange: onChange,
+		current:  make(map[string]net.IP),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start resolves domains for the first time and keeps refreshing them in the background.
+func (w *DomainWatcher) Start() {
+	interval := w.refresh()
+
+	go func() {
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-time.After(interval):
+				interval = w.refresh()
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing domain addresses.
+func (w *DomainWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+// IPs returns currently resolved addresses.
+func (w *DomainWatcher) IPs() []net.IP {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ips := make([]net.IP, 0, len(w.current))
+	for _, ip := range w.current {
+		ips = append(ips, ip)
+	}
+	return ips
+}
+
+func (w *DomainWatcher) refresh() time.Duration {
+	resolved := make(map[string]net.IP)
+	interval := maxRefreshInterval
+	for _, domain := range w.domains {
+		for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+			ips, ttl, err := w.resolve(domain, qtype)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Failed to resolve %s, keeping previous addresses", domain)
+				return minRefreshInterval
+			}
+			for _, ip := range ips {
+				resolved[ip.String()] = ip
+			}
+			if len(ips) > 0 && ttl < interval {
+				interval = ttl
+			}
+		}
+	}
+	if interval < minRefreshInterval {
+		interval = minRefreshInterval
+	}
+
+	w.mu.Lock()
+	var added, removed []net.IP
+	for key, ip := range resolved {
+		if _, ok := w.current[key]; !ok {
+			added = append(added, ip)
+		}
+	}
+	for key, ip := range w.current {
+		if _, ok := resolved[key]; !ok {
+			removed = append(removed, ip)
+		}
+	}
+	w.current = resolved
+	w.mu.Unlock()
+
+	if len(added) > 0 || len(removed) > 0 {
+		w.onChange(added, removed)
+	}
+	return interval
+}
+
+func (w *DomainWatcher) resolve(domain string, qtype uint16) ([]net.IP, time.Duration, error) {
+	req := &dns.Msg{}
+	req.SetQuestion(dns.Fqdn(domain), qtype)
+
+	writer := &recordingWriter{}
+	w.resolver.ServeDNS(writer, req)
+	resp := writer.responseMsg
+	if resp == nil || (resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError) {
+		return nil, 0, errResolveFailed
+	}
+
+	var ips []net.IP
+	ttl := maxRefreshInterval
+	for _, record := range resp.Answer {
+		switch recordValue := record.(type) {
+		case *dns.A:
+			ips = append(ips, recordValue.A)
+		case *dns.AAAA:
+			ips = append(ips, recordValue.AAAA)
+		default:
+			continue
+		}
+		if recordTTL := time.Duration(record.Header().Ttl) * time.Second; recordTTL < ttl {
+			ttl = recordTTL
+		}
+	}
+	return ips, ttl, nil
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DomainWatcher_ReportsAddressChanges(t *testing.T) {
+	answers := map[string][]string{"corp.example.": {"10.0.0.1", "10.0.0.2"}}
+	resolver := dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+		resp := &dns.Msg{}
+		resp.SetReply(req)
+		if req.Question[0].Qtype == dns.TypeA {
+			for _, ip := range answers[req.Question[0].Name] {
+				resp.Answer = append(resp.Answer, &dns.A{
+					Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+					A:   net.ParseIP(ip),
+				})
+			}
+		}
+		writer.WriteMsg(resp)
+	})
+
+	var added, removed []net.IP
+	watcher := NewDomainWatcher(resolver, []string{"corp.example"}, func(a, r []net.IP) {
+		added, removed = a, r
+	})
+
+	interval := watcher.refresh()
+	assert.Equal(t, 60*time.Second, interval)
+	assert.ElementsMatch(t, []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")}, added)
+	assert.Empty(t, removed)
+
+	answers["corp.example."] = []string{"10.0.0.2", "10.0.0.3"}
+	watcher.refresh()
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.3")}, added)
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.1")}, removed)
+	assert.Len(t, watcher.IPs(), 2)
+}
+
+func Test_DomainWatcher_KeepsAddressesOnFailure(t *testing.T) {
+	fail := false
+	resolver := dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+		resp := &dns.Msg{}
+		resp.SetReply(req)
+		if fail {
+			resp.SetRcode(req, dns.RcodeServerFailure)
+		} else if req.Question[0].Qtype == dns.TypeA {
+			resp.Answer = append(resp.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 1},
+				A:   net.ParseIP("10.0.0.1"),
+			})
+		}
+		writer.WriteMsg(resp)
+	})
+
+	calls := 0
+	watcher := NewDomainWatcher(resolver, []string{"corp.example"}, func(a, r []net.IP) {
+		calls++
+	})
+
+	assert.Equal(t, minRefreshInterval, watcher.refresh())
+	fail = true
+	assert.Equal(t, minRefreshInterval, watcher.refresh())
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.1")}, watcher.IPs())
+}
@@ -0,0 +1,263 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const filterFetchTimeout = time.Minute
+
+// FilterListStats describes a single blocklist of the filter.
+type FilterListStats struct {
+	Source    string
+	Domains   int
+	Hits      uint64
+	UpdatedAt time.Time // Zero if the list was never loaded.
+	Error     string    // Last loading error, previously loaded domains are kept.
+}
+
+// FilterStats describes blocklists and overrides of the filter.
+type FilterStats struct {
+	Lists    []FilterListStats
+	DenyHits uint64
+}
+
+type filterList struct {
+	hits      uint64 // Accessed atomically, kept first for 64-bit alignment.
+	source    string
+	blocklist *Blocklist
+	updatedAt time.Time
+	err       error
+}
+
+// Filter blocks domains found in the blocklists, unless they are allowed explicitly.
+// Blocklists are loaded from local files or URLs and reloaded periodically.
+type Filter struct {
+	denyHits uint64 // Accessed atomically, kept first for 64-bit alignment.
+
+	interval time.Duration
+	client   *http.Client
+
+	mu    sync.RWMutex
+	lists []*filterList
+	allow map[string]struct{}
+	deny  map[string]struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewFilter creates filter of the given blocklist sources, reloaded every interval.
+func NewFilter(sources []string, interval time.Duration) *Filter {
+	lists := make([]*filterList, len(sources))
+	for i, source := range sources {
+		lists[i] = &filterList{source: source, blocklist: NewBlocklist()}
+	}
+
+	return &Filter{
+		interval: interval,
+		client:   &http.Client{Timeout: filterFetchTimeout},
+		lists:    lists,
+		allow:    make(map[string]struct{}),
+		deny:     make(map[string]struct{}),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start loads blocklists in the background and keeps reloading them.
+func (f *Filter) Start() {
+	if len(f.lists) == 0 {
+		return
+	}
+
+	go func() {
+		for {
+			f.Reload()
+
+			select {
+			case <-f.stop:
+				return
+			case <-time.After(f.interval):
+			}
+		}
+	}()
+}
+
+// Stop stops reloading blocklists.
+func (f *Filter) Stop() {
+	f.stopOnce.Do(func() {
+		close(f.stop)
+	})
+}
+
+// Reload loads all blocklists from their sources.
+func (f *Filter) Reload() {
+	f.mu.RLock()
+	lists := f.lists
+	f.mu.RUnlock()
+
+	for _, list := range lists {
+		blocklist, err := f.load(list.source)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to load DNS blocklist %s", list.source)
+		} else {
+			log.Info().Msgf("Loaded DNS blocklist %s with %d domains", list.source, blocklist.Len())
+		}
+
+		f.mu.Lock()
+		list.err = err
+		if err == nil {
+			list.blocklist = blocklist
+			list.updatedAt = time.Now()
+		}
+		f.mu.Unlock()
+	}
+}
+
+func (f *Filter) load(source string) (*Blocklist, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		return ParseBlocklist(file)
+	}
+
+	resp, err := f.client.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, errors.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	return ParseBlocklist(resp.Body)
+}
+
+// Blocked checks if the domain is blocked and counts the hit of the matching list.
+func (f *Filter) Blocked(name string) bool {
+	name, ok := normalizeDomain(name)
+	if !ok {
+		return false
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if matchesDomainOrParent(f.allow, name) {
+		return false
+	}
+	if matchesDomainOrParent(f.deny, name) {
+		atomic.AddUint64(&f.denyHits, 1)
+		return true
+	}
+	for _, list := range f.lists {
+		if list.blocklist.Contains(name) {
+			atomic.AddUint64(&list.hits, 1)
+			return true
+		}
+	}
+	return false
+}
+
+// Overrides returns domains which are allowed and denied regardless of the blocklists.
+func (f *Filter) Overrides() (allow, deny []string) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return sortedDomains(f.allow), sortedDomains(f.deny)
+}
+
+// SetOverrides replaces domains which are allowed and denied regardless of the blocklists.
+// Overrides apply to subdomains too, allowed domains take precedence over denied ones.
+func (f *Filter) SetOverrides(allow, deny []string) error {
+	allowSet, err := domainSet(allow)
+	if err != nil {
+		return err
+	}
+	denySet, err := domainSet(deny)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.allow, f.deny = allowSet, denySet
+	return nil
+}
+
+// Stats returns hit counters and loading state of the blocklists.
+func (f *Filter) Stats() FilterStats {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	stats := FilterStats{
+		Lists:    make([]FilterListStats, len(f.lists)),
+		DenyHits: atomic.LoadUint64(&f.denyHits),
+	}
+	for i, list := range f.lists {
+		stats.Lists[i] = FilterListStats{
+			Source:    list.source,
+			Domains:   list.blocklist.Len(),
+			Hits:      atomic.LoadUint64(&list.hits),
+			UpdatedAt: list.updatedAt,
+		}
+		if list.err != nil {
+			stats.Lists[i].Error = list.err.Error()
+		}
+	}
+	return stats
+}
+
+func domainSet(domains []string) (map[string]struct{}, error) {
+	set := make(map[string]struct{}, len(domains))
+	for _, domain := range domains {
+		name, ok := normalizeDomain(domain)
+		if !ok {
+			return nil, errors.New("invalid domain: " + domain)
+		}
+		set[name] = struct{}{}
+	}
+	return set, nil
+}
+
+func sortedDomains(set map[string]struct{}) []string {
+	domains := make([]string, 0, len(set))
+	for domain := range set {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Filter_LoadsListsFromFilesAndURLs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	assert.NoError(t, os.WriteFile(path, []byte("0.0.0.0 ads.example.com\n"), 0600))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "||tracker.example.org^")
+	}))
+	defer server.Close()
+
+	filter := NewFilter([]string{path, server.URL, filepath.Join(t.TempDir(), "missing")}, time.Hour)
+	filter.Reload()
+
+	assert.True(t, filter.Blocked("ads.example.com."))
+	assert.True(t, filter.Blocked("cdn.tracker.example.org."))
+	assert.True(t, filter.Blocked("cdn.tracker.example.org."))
+	assert.False(t, filter.Blocked("example.com."))
+
+	stats := filter.Stats()
+	assert.Len(t, stats.Lists, 3)
+	assert.Equal(t, 1, stats.Lists[0].Domains)
+	assert.Equal(t, uint64(1), stats.Lists[0].Hits)
+	assert.Equal(t, uint64(2), stats.Lists[1].Hits)
+	assert.False(t, stats.Lists[1].UpdatedAt.IsZero())
+	assert.Empty(t, stats.Lists[1].Error)
+	assert.NotEmpty(t, stats.Lists[2].Error)
+	assert.True(t, stats.Lists[2].UpdatedAt.IsZero())
+}
+
+func Test_Filter_AppliesOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adblock")
+	assert.NoError(t, os.WriteFile(path, []byte("||example.com^\n"), 0600))
+
+	filter := NewFilter([]string{path}, time.Hour)
+	filter.Reload()
+
+	assert.Error(t, filter.SetOverrides([]string{"*.example.com"}, nil))
+	assert.NoError(t, filter.SetOverrides([]string{"Docs.example.com."}, []string{"example.org"}))
+
+	allow, deny := filter.Overrides()
+	assert.Equal(t, []string{"docs.example.com"}, allow)
+	assert.Equal(t, []string{"example.org"}, deny)
+
+	assert.True(t, filter.Blocked("www.example.com"))
+	assert.False(t, filter.Blocked("api.docs.example.com"))
+	assert.True(t, filter.Blocked("www.example.org"))
+	assert.Equal(t, uint64(1), filter.Stats().DenyHits)
+}
+
+func Test_FilterQueries_RespondsNameErrorToBlockedQueries(t *testing.T) {
+	filter := NewFilter(nil, time.Hour)
+	assert.NoError(t, filter.SetOverrides(nil, []string{"ads.example.com"}))
+
+	upstream := &mockUpstream{resp: func(req *dns.Msg) *dns.Msg { return newTestResponse(req, 60) }}
+	handler := FilterQueries(ResolveViaUpstreams([]Upstream{upstream}, nil), filter)
+
+	writer := &mockResponseWriter{}
+	handler.ServeDNS(writer, newTestQuery("ads.example.com."))
+	assert.Equal(t, dns.RcodeNameError, writer.msg.Rcode)
+	assert.Equal(t, 0, upstream.calls)
+
+	writer = &mockResponseWriter{}
+	handler.ServeDNS(writer, newTestQuery("example.com."))
+	assert.Equal(t, dns.RcodeSuccess, writer.msg.Rcode)
+	assert.Equal(t, 1, upstream.calls)
+}
+
+func Test_FilterProxy_RefusesToStartWithoutUpstreams(t *testing.T) {
+	proxy := NewFilterProxy("127.0.0.1", 0, ResolverOptions{}, NewFilter(nil, time.Hour))
+
+	assert.Equal(t, ErrNoFilterUpstreams, proxy.Start(nil))
+	assert.NoError(t, proxy.Stop())
+}
+
+func Test_FilterProxy_ReportsUnavailableAddress(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+
+	proxy := NewFilterProxy("127.0.0.1", port, ResolverOptions{Upstreams: []string{"udp://127.0.0.1:53"}}, NewFilter(nil, time.Hour))
+
+	err = proxy.Start(nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("could not listen on 127.0.0.1:%d", port))
+	assert.NoError(t, proxy.Stop())
+}
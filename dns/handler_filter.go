@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoFilterUpstreams is returned when the filtering proxy has no upstreams to resolve allowed queries.
+var ErrNoFilterUpstreams = errors.New("no upstreams for filtered DNS")
+
+// FilterQueries creates a DNS handler that refuses to resolve domains blocked by the filter.
+// Blocked queries are answered with NXDOMAIN.
+func FilterQueries(resolver dns.Handler, filter *Filter) dns.Handler {
+	return &filterHandler{
+		resolver: resolver,
+		filter:   filter,
+	}
+}
+
+type filterHandler struct {
+	resolver dns.Handler
+	filter   *Filter
+}
+
+func (fh *filterHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	for _, question := range req.Question {
+		if fh.filter.Blocked(question.Name) {
+			log.Debug().Msgf("Blocked DNS query: %s", question.Name)
+
+			resp := &dns.Msg{}
+			resp.SetRcode(req, dns.RcodeNameError)
+			writer.WriteMsg(resp)
+			return
+		}
+	}
+
+	fh.resolver.ServeDNS(writer, req)
+}
+
+// FilterProxy runs the filtering DNS proxy on demand, e.g. while consumer connection is active.
+type FilterProxy struct {
+	lhost  string
+	lport  int
+	opts   ResolverOptions
+	filter *Filter
+
+	mu    sync.Mutex
+	proxy *Proxy
+}
+
+// NewFilterProxy returns filtering DNS proxy resolving allowed queries via upstreams of given options.
+// If options have no upstreams, the ones given on start are used.
+func NewFilterProxy(lhost string, lport int, opts ResolverOptions, filter *Filter) *FilterProxy {
+	return &FilterProxy{
+		lhost:  lhost,
+		lport:  lport,
+		opts:   opts,
+		filter: filter,
+	}
+}
+
+// Start starts the proxy, it does nothing if the proxy is already running.
+// Allowed queries are resolved via the configured upstreams, or via given ones (e.g. provider DNS servers
+// reachable through the tunnel). System DNS servers are never used, as they are likely outside of the tunnel.
+func (fp *FilterProxy) Start(upstreams []string) error {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	if fp.proxy != nil {
+		return nil
+	}
+
+	opts := fp.opts
+	if len(opts.Upstreams) == 0 {
+		opts.Upstreams = upstreams
+	}
+	if len(opts.Upstreams) == 0 {
+		return ErrNoFilterUpstreams
+	}
+	resolver, err := NewResolver(opts)
+	if err != nil {
+		return err
+	}
+
+	proxy := NewProxy(fp.lhost, fp.lport, FilterQueries(resolver, fp.filter))
+	if err := proxy.Run(); err != nil {
+		addr := net.JoinHostPort(fp.lhost, strconv.Itoa(fp.lport))
+		return errors.Wrapf(err, "could not listen on %s, it might be used by another DNS resolver", addr)
+	}
+	fp.proxy = proxy
+	return nil
+}
+
+// Stop stops the proxy if it is running.
+func (fp *FilterProxy) Stop() error {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	if fp.proxy == nil {
+		return nil
+	}
+
+	err := fp.proxy.Stop()
+	fp.proxy = nil
+	return err
+}
@@ -22,55 +22,28 @@ import (
 
 	"github.com/miekg/dns"
 	"github.com/pkg/errors"
-	"github.com/rs/zerolog/log"
 )
 
 // ResolveViaSystem creates proxying DNS handler.
 func ResolveViaSystem() (dns.Handler, error) {
-	handler := &proxyHandler{
-		client: &dns.Client{
-			DialTimeout:  dnsTimeout,
-			ReadTimeout:  dnsTimeout,
-			WriteTimeout: dnsTimeout,
-		},
-	}
-	if err := handler.configure(); err != nil {
+	upstreams, err := SystemUpstreams()
+	if err != nil {
 		return nil, errors.Wrap(err, "failed to find system DNS configuration")
 	}
 
-	return handler, nil
+	return ResolveViaUpstreams(upstreams, nil), nil
 }
 
-type proxyHandler struct {
-	proxyAddrs []string
-	client     *dns.Client
-}
-
-// configure configures proxy to use system DNS servers.
-func (ph *proxyHandler) configure() (err error) {
+// SystemUpstreams returns plain DNS upstreams of the system DNS configuration.
+func SystemUpstreams() ([]Upstream, error) {
 	cfg, err := configuration()
 	if err != nil {
-		return err
-	}
-	for _, server := range cfg.Servers {
-		ph.proxyAddrs = append(ph.proxyAddrs, net.JoinHostPort(server, cfg.Port))
+		return nil, err
 	}
-	return nil
-}
-
-func (ph *proxyHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
-	for _, addr := range ph.proxyAddrs {
-		resp, _, err := ph.client.Exchange(req, addr)
-		if err != nil {
-			log.Error().Err(err).Msg("Error proxying DNS query to " + addr)
-			continue
-		}
 
-		writer.WriteMsg(resp)
-		return
+	upstreams := make([]Upstream, 0, len(cfg.Servers))
+	for _, server := range cfg.Servers {
+		upstreams = append(upstreams, newPlainUpstream("udp", net.JoinHostPort(server, cfg.Port)))
 	}
-
-	resp := &dns.Msg{}
-	resp.SetRcode(req, dns.RcodeServerFailure)
-	writer.WriteMsg(resp)
+	return upstreams, nil
 }
@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"context"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ResolverOptions describes upstreams and caching of the DNS resolver.
+type ResolverOptions struct {
+	// Upstreams are tried in the given order, system DNS servers are used if empty.
+	Upstreams []string
+	// CacheSize is the maximum count of cached responses, caching is disabled if zero.
+	CacheSize int
+}
+
+// NewResolver creates DNS handler resolving queries via upstreams of given options.
+func NewResolver(opts ResolverOptions) (dns.Handler, error) {
+	var upstreams []Upstream
+	for _, addr := range opts.Upstreams {
+		upstream, err := NewUpstream(addr)
+		if err != nil {
+			return nil, err
+		}
+		upstreams = append(upstreams, upstream)
+	}
+
+	if len(upstreams) == 0 {
+		var err error
+		if upstreams, err = SystemUpstreams(); err != nil {
+			return nil, errors.Wrap(err, "failed to find system DNS configuration")
+		}
+	}
+
+	var cache *Cache
+	if opts.CacheSize > 0 {
+		cache = NewCache(opts.CacheSize)
+	}
+
+	return ResolveViaUpstreams(upstreams, cache), nil
+}
+
+// ResolveViaUpstreams creates DNS handler proxying queries to the first upstream which responds.
+// Responses are cached if cache is given.
+func ResolveViaUpstreams(upstreams []Upstream, cache *Cache) dns.Handler {
+	return &upstreamHandler{
+		upstreams: upstreams,
+		cache:     cache,
+	}
+}
+
+type upstreamHandler struct {
+	upstreams []Upstream
+	cache     *Cache
+}
+
+func (uh *upstreamHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	if resp := uh.cache.Get(req); resp != nil {
+		writer.WriteMsg(resp)
+		return
+	}
+
+	for _, upstream := range uh.upstreams {
+		resp, err := uh.exchange(upstream, req)
+		if err != nil {
+			log.Error().Err(err).Msg("Error proxying DNS query to " + upstream.String())
+			continue
+		}
+
+		uh.cache.Put(req, resp)
+		writer.WriteMsg(resp)
+		return
+	}
+
+	resp := &dns.Msg{}
+	resp.SetRcode(req, dns.RcodeServerFailure)
+	writer.WriteMsg(resp)
+}
+
+func (uh *upstreamHandler) exchange(upstream Upstream, req *dns.Msg) (*dns.Msg, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	return upstream.Exchange(ctx, req)
+}
@@ -18,6 +18,7 @@
 package dns
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/miekg/dns"
@@ -27,6 +28,7 @@ import (
 )
 
 // WhitelistAnswers creates a DNS handler that whitelist resolved queries to firewall.
+// Queries for hosts denied by policies are answered with NXDOMAIN.
 func WhitelistAnswers(
 	resolver dns.Handler,
 	trafficBlocker firewall.IncomingTrafficFirewall,
@@ -46,6 +48,17 @@ type whitelistHandler struct {
 }
 
 func (wh *whitelistHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	for _, question := range req.Question {
+		if host := strings.TrimRight(question.Name, "."); wh.policies.IsHostDenied(host) {
+			log.Debug().Msgf("DNS query of host %s denied by policy", host)
+
+			resp := &dns.Msg{}
+			resp.SetRcode(req, dns.RcodeNameError)
+			writer.WriteMsg(resp)
+			return
+		}
+	}
+
 	resolverWriter := &recordingWriter{writer: writer}
 	wh.resolver.ServeDNS(resolverWriter, req)
 	resp := resolverWriter.responseMsg
@@ -78,6 +91,9 @@ func (wh *whitelistHandler) whitelistByARecord(record *dns.A) error {
 	host := strings.TrimRight(record.Hdr.Name, ".")
 	ip := record.A
 
+	if wh.policies.IsHostDenied(host) {
+		return fmt.Errorf("host %s is denied by policy", host)
+	}
 	if wh.policies.IsHostAllowed(host) {
 		_, err := wh.trafficBlocker.AllowIPAccess(ip)
 		return err
@@ -164,6 +164,38 @@ func Test_WhitelistAnswers(t *testing.T) {
 	}
 }
 
+func Test_WhitelistAnswers_DeniedHost(t *testing.T) {
+	policies := createPolicies()
+	policies.SetPolicyRules(
+		market.AccessPolicy{ID: "deny", Source: "local"},
+		market.AccessPolicyRuleSet{
+			ID:   "deny",
+			Deny: []market.AccessRule{{Type: market.AccessPolicyTypeDNSZone, Value: "torrent.com"}},
+		},
+	)
+
+	resolved := false
+	mockedBlocker := &trafficBlockerMock{
+		allowIPCalls: map[string]int{},
+	}
+	writer := &recordingWriter{}
+	handler := WhitelistAnswers(
+		dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+			resolved = true
+			writer.WriteMsg(&dns.Msg{})
+		}),
+		mockedBlocker,
+		policies,
+	)
+
+	req := &dns.Msg{}
+	req.SetQuestion("tracker.torrent.com.", dns.TypeA)
+	handler.ServeDNS(writer, req)
+	assert.False(t, resolved)
+	assert.Equal(t, dns.RcodeNameError, writer.responseMsg.Rcode)
+	assert.Equal(t, map[string]int{}, mockedBlocker.allowIPCalls)
+}
+
 func createPolicies() *policy.Repository {
 	repo := policy.NewRepository()
 	repo.SetPolicyRules(policyDNSZone, policyDNSZoneRules)
@@ -187,6 +219,10 @@ func (tbn *trafficBlockerMock) AllowURLAccess(rawURLs ...string) (firewall.Incom
 	return nil, nil
 }
 
+func (tbn *trafficBlockerMock) RestrictDestinations(net.IPNet, []firewall.DestinationRule, []firewall.DestinationRule) (firewall.IncomingRuleRemove, error) {
+	return nil, nil
+}
+
 func (tbn *trafficBlockerMock) AllowIPAccess(ip net.IP) (firewall.IncomingRuleRemove, error) {
 	ipString := ip.String()
 	if _, called := tbn.allowIPCalls[ipString]; !called {
@@ -28,30 +28,47 @@ import (
 
 // Proxy defines DNS server with all handler attached to it.
 type Proxy struct {
-	server *dns.Server
+	servers []*dns.Server
 }
 
-// NewProxy returns new instance of API server.
+// NewProxy returns new instance of API server listening on both UDP and TCP.
 func NewProxy(lhost string, lport int, handler dns.Handler) *Proxy {
-	return &Proxy{
-		server: &dns.Server{
-			Addr:         net.JoinHostPort(lhost, strconv.Itoa(lport)),
-			Net:          "udp",
+	addr := net.JoinHostPort(lhost, strconv.Itoa(lport))
+
+	var servers []*dns.Server
+	for _, network := range []string{"udp", "tcp"} {
+		servers = append(servers, &dns.Server{
+			Addr:         addr,
+			Net:          network,
 			ReadTimeout:  dnsTimeout,
 			WriteTimeout: dnsTimeout,
 			ReusePort:    true,
 			Handler:      handler,
-		},
+		})
 	}
+
+	return &Proxy{servers: servers}
 }
 
-// Run starts DNS proxy server and waits for the startup to complete.
+// Run starts DNS proxy servers and waits for the startup to complete.
 func (p *Proxy) Run() (err error) {
+	for i, server := range p.servers {
+		if err := runServer(server); err != nil {
+			for _, started := range p.servers[:i] {
+				started.Shutdown()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func runServer(server *dns.Server) error {
 	dnsProxyCh := make(chan error)
-	p.server.NotifyStartedFunc = func() { dnsProxyCh <- nil }
+	server.NotifyStartedFunc = func() { dnsProxyCh <- nil }
 	go func() {
-		log.Info().Msg("Starting DNS proxy on: " + p.server.Addr)
-		if err := p.server.ListenAndServe(); err != nil {
+		log.Info().Msgf("Starting DNS proxy on: %s/%s", server.Addr, server.Net)
+		if err := server.ListenAndServe(); err != nil {
 			dnsProxyCh <- errors.Wrap(err, "failed to start DNS proxy")
 		}
 	}()
@@ -59,7 +76,12 @@ func (p *Proxy) Run() (err error) {
 	return <-dnsProxyCh
 }
 
-// Stop shutdowns DNS proxy server.
-func (p *Proxy) Stop() error {
-	return p.server.Shutdown()
+// Stop shutdowns DNS proxy servers.
+func (p *Proxy) Stop() (err error) {
+	for _, server := range p.servers {
+		if shutdownErr := server.Shutdown(); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+	return err
 }
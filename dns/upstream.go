@@ -0,0 +1,198 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// dohMediaType is the media type of DNS wire format messages, see RFC 8484.
+const dohMediaType = "application/dns-message"
+
+// Upstream resolves DNS queries on behalf of the proxy.
+type Upstream interface {
+	Exchange(ctx context.Context, req *dns.Msg) (*dns.Msg, error)
+	String() string
+}
+
+// NewUpstream creates upstream from its address. Plain DNS over UDP is used for addresses
+// like 1.1.1.1 or udp://1.1.1.1:53, it is retried over TCP if the response is truncated.
+// Plain DNS over TCP is used for tcp://1.1.1.1:53, DNS over TLS (RFC 7858) for tls://1.1.1.1:853
+// or tls://dns.quad9.net and DNS over HTTPS (RFC 8484) for https://cloudflare-dns.com/dns-query.
+func NewUpstream(addr string) (Upstream, error) {
+	if ip := net.ParseIP(addr); ip != nil {
+		return newPlainUpstream("udp", net.JoinHostPort(addr, "53")), nil
+	}
+	if !strings.Contains(addr, "://") {
+		addr = "udp://" + addr
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid DNS upstream address")
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("DNS upstream host is missing in %q", addr)
+	}
+
+	switch u.Scheme {
+	case "udp", "tcp":
+		return newPlainUpstream(u.Scheme, withDefaultPort(u.Host, "53")), nil
+	case "tls":
+		return newTLSUpstream(withDefaultPort(u.Host, "853")), nil
+	case "https":
+		return newHTTPSUpstream(u.String()), nil
+	}
+	return nil, fmt.Errorf("unsupported DNS upstream scheme %q", u.Scheme)
+}
+
+func withDefaultPort(host, port string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), port)
+}
+
+type plainUpstream struct {
+	network string
+	addr    string
+	client  *dns.Client
+}
+
+func newPlainUpstream(network, addr string) *plainUpstream {
+	return &plainUpstream{
+		network: network,
+		addr:    addr,
+		client:  newClient(network),
+	}
+}
+
+func (pu *plainUpstream) Exchange(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+	resp, _, err := pu.client.ExchangeContext(ctx, req, pu.addr)
+	if err == nil && resp.Truncated && pu.network == "udp" {
+		resp, _, err = newClient("tcp").ExchangeContext(ctx, req, pu.addr)
+	}
+	return resp, err
+}
+
+func (pu *plainUpstream) String() string {
+	return pu.network + "://" + pu.addr
+}
+
+type tlsUpstream struct {
+	addr   string
+	client *dns.Client
+}
+
+func newTLSUpstream(addr string) *tlsUpstream {
+	host, _, _ := net.SplitHostPort(addr)
+
+	client := newClient("tcp-tls")
+	client.TLSConfig = &tls.Config{
+		ServerName: host,
+		MinVersion: tls.VersionTLS12,
+	}
+	return &tlsUpstream{
+		addr:   addr,
+		client: client,
+	}
+}
+
+func (tu *tlsUpstream) Exchange(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+	resp, _, err := tu.client.ExchangeContext(ctx, req, tu.addr)
+	return resp, err
+}
+
+func (tu *tlsUpstream) String() string {
+	return "tls://" + tu.addr
+}
+
+type httpsUpstream struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPSUpstream(endpoint string) *httpsUpstream {
+	return &httpsUpstream{
+		url:    endpoint,
+		client: &http.Client{Timeout: dnsTimeout},
+	}
+}
+
+func (hu *httpsUpstream) Exchange(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+	// ID is zeroed to make responses cacheable by HTTP caches, see RFC 8484 section 4.1.
+	query := req.Copy()
+	query.Id = 0
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not pack DNS query")
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, hu.url, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", dohMediaType)
+	httpReq.Header.Set("Accept", dohMediaType)
+
+	httpResp, err := hu.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected DNS over HTTPS response status: %s", httpResp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read DNS over HTTPS response")
+	}
+
+	resp := &dns.Msg{}
+	if err := resp.Unpack(body); err != nil {
+		return nil, errors.Wrap(err, "could not unpack DNS over HTTPS response")
+	}
+	resp.Id = req.Id
+	return resp, nil
+}
+
+func (hu *httpsUpstream) String() string {
+	return hu.url
+}
+
+func newClient(network string) *dns.Client {
+	return &dns.Client{
+		Net:          network,
+		DialTimeout:  dnsTimeout,
+		ReadTimeout:  dnsTimeout,
+		WriteTimeout: dnsTimeout,
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewUpstream(t *testing.T) {
+	tests := []struct {
+		addr     string
+		expected string
+		err      bool
+	}{
+		{addr: "1.1.1.1", expected: "udp://1.1.1.1:53"},
+		{addr: "2606:4700:4700::1111", expected: "udp://[2606:4700:4700::1111]:53"},
+		{addr: "1.1.1.1:5353", expected: "udp://1.1.1.1:5353"},
+		{addr: "udp://8.8.8.8", expected: "udp://8.8.8.8:53"},
+		{addr: "tcp://8.8.8.8:53", expected: "tcp://8.8.8.8:53"},
+		{addr: "tls://dns.quad9.net", expected: "tls://dns.quad9.net:853"},
+		{addr: "tls://[2606:4700:4700::1111]", expected: "tls://[2606:4700:4700::1111]:853"},
+		{addr: "https://cloudflare-dns.com/dns-query", expected: "https://cloudflare-dns.com/dns-query"},
+		{addr: "quic://1.1.1.1", err: true},
+		{addr: "tls://", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			upstream, err := NewUpstream(tt.addr)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, upstream.String())
+		})
+	}
+}
+
+func Test_HTTPSUpstream_Exchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, dohMediaType, r.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		req := &dns.Msg{}
+		assert.NoError(t, req.Unpack(body))
+		assert.Equal(t, uint16(0), req.Id)
+
+		packed, err := newTestResponse(req, 60).Pack()
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", dohMediaType)
+		w.Write(packed)
+	}))
+	defer server.Close()
+
+	req := newTestQuery("example.com.")
+	resp, err := newHTTPSUpstream(server.URL).Exchange(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, req.Id, resp.Id)
+	assert.Len(t, resp.Answer, 1)
+}
+
+func Test_HTTPSUpstream_ExchangeFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := newHTTPSUpstream(server.URL).Exchange(context.Background(), newTestQuery("example.com."))
+	assert.Error(t, err)
+}
+
+type mockUpstream struct {
+	resp  func(req *dns.Msg) *dns.Msg
+	err   error
+	calls int
+}
+
+func (mu *mockUpstream) Exchange(_ context.Context, req *dns.Msg) (*dns.Msg, error) {
+	mu.calls++
+	if mu.err != nil {
+		return nil, mu.err
+	}
+	return mu.resp(req), nil
+}
+
+func (mu *mockUpstream) String() string {
+	return "mock://"
+}
+
+type mockResponseWriter struct {
+	dns.ResponseWriter
+	msg *dns.Msg
+}
+
+func (mw *mockResponseWriter) WriteMsg(m *dns.Msg) error {
+	mw.msg = m
+	return nil
+}
+
+func Test_ResolveViaUpstreams_FailsOverToNextUpstream(t *testing.T) {
+	failing := &mockUpstream{err: errors.New("connection refused")}
+	working := &mockUpstream{resp: func(req *dns.Msg) *dns.Msg { return newTestResponse(req, 60) }}
+	handler := ResolveViaUpstreams([]Upstream{failing, working}, NewCache(10))
+
+	writer := &mockResponseWriter{}
+	handler.ServeDNS(writer, newTestQuery("example.com."))
+	assert.Equal(t, dns.RcodeSuccess, writer.msg.Rcode)
+	assert.Len(t, writer.msg.Answer, 1)
+
+	writer = &mockResponseWriter{}
+	handler.ServeDNS(writer, newTestQuery("example.com."))
+	assert.Len(t, writer.msg.Answer, 1)
+	assert.Equal(t, 1, failing.calls)
+	assert.Equal(t, 1, working.calls, "second query should be answered from cache")
+}
+
+func Test_ResolveViaUpstreams_RespondsServerFailureWhenAllUpstreamsFail(t *testing.T) {
+	handler := ResolveViaUpstreams([]Upstream{&mockUpstream{err: errors.New("timeout")}}, nil)
+
+	writer := &mockResponseWriter{}
+	handler.ServeDNS(writer, newTestQuery("example.com."))
+	assert.Equal(t, dns.RcodeServerFailure, writer.msg.Rcode)
+}
@@ -28,6 +28,35 @@ type IncomingTrafficFirewall interface {
 	BlockIncomingTraffic(network net.IPNet) (IncomingRuleRemove, error)
 	AllowURLAccess(rawURLs ...string) (IncomingRuleRemove, error)
 	AllowIPAccess(ip net.IP) (IncomingRuleRemove, error)
+	RestrictDestinations(network net.IPNet, allow, deny []DestinationRule) (IncomingRuleRemove, error)
+}
+
+// DestinationRule matches traffic by destination network, protocol and port range.
+type DestinationRule struct {
+	// Network is a destination network, nil matches any destination.
+	Network *net.IPNet
+	// Protocol is either "tcp" or "udp", empty protocol matches both.
+	Protocol string
+	// PortFrom and PortTo is an inclusive destination port range, zero matches any port.
+	PortFrom int
+	PortTo   int
+}
+
+// Matches checks if the traffic to the given destination matches the rule.
+func (r DestinationRule) Matches(ip net.IP, protocol string, port int) bool {
+	if r.Network != nil && !r.Network.Contains(ip) {
+		return false
+	}
+	if r.Protocol != "" && r.Protocol != protocol {
+		return false
+	}
+	if r.PortFrom == 0 {
+		return true
+	}
+	if r.PortTo == 0 {
+		return port == r.PortFrom
+	}
+	return port >= r.PortFrom && port <= r.PortTo
 }
 
 // IncomingRuleRemove type defines function for removal of created rule.
@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestinationRule_Matches(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+
+	tests := []struct {
+		name     string
+		rule     DestinationRule
+		ip       string
+		protocol string
+		port     int
+		want     bool
+	}{
+		{name: "Empty rule matches anything", rule: DestinationRule{}, ip: "1.1.1.1", protocol: "udp", port: 53, want: true},
+		{name: "Network matches", rule: DestinationRule{Network: network}, ip: "10.1.2.3", protocol: "tcp", port: 80, want: true},
+		{name: "Network does not match", rule: DestinationRule{Network: network}, ip: "11.1.2.3", protocol: "tcp", port: 80, want: false},
+		{name: "Protocol does not match", rule: DestinationRule{Protocol: "tcp"}, ip: "1.1.1.1", protocol: "udp", port: 53, want: false},
+		{name: "Single port matches", rule: DestinationRule{PortFrom: 25}, ip: "1.1.1.1", protocol: "tcp", port: 25, want: true},
+		{name: "Single port does not match", rule: DestinationRule{PortFrom: 25}, ip: "1.1.1.1", protocol: "tcp", port: 26, want: false},
+		{name: "Port range matches", rule: DestinationRule{Protocol: "tcp", PortFrom: 6881, PortTo: 6889}, ip: "1.1.1.1", protocol: "tcp", port: 6885, want: true},
+		{name: "Port range does not match", rule: DestinationRule{PortFrom: 6881, PortTo: 6889}, ip: "1.1.1.1", protocol: "tcp", port: 6890, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rule.Matches(net.ParseIP(tt.ip), tt.protocol, tt.port))
+		})
+	}
+}
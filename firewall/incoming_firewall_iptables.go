@@ -20,6 +20,7 @@ package firewall
 import (
 	"net"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -115,6 +116,89 @@ func (ibi *incomingFirewallIptables) AllowIPAccess(ip net.IP) (IncomingRuleRemov
 	}, nil
 }
 
+// RestrictDestinations rejects traffic of the network to denied destinations.
+// If there are allowed destinations, traffic to any other destination is rejected too.
+// Rules of IPv6 network are installed with ip6tables and only destinations of the same address family apply to it.
+func (ibi *incomingFirewallIptables) RestrictDestinations(network net.IPNet, allow, deny []DestinationRule) (IncomingRuleRemove, error) {
+	var ruleRemovers []func()
+	removeAll := func() error {
+		for _, ruleRemover := range ruleRemovers {
+			ruleRemover()
+		}
+		return nil
+	}
+	ipv6 := network.IP.To4() == nil
+	insert := func(target string, rules []DestinationRule) error {
+		for _, rule := range rules {
+			if rule.Network != nil && (rule.Network.IP.To4() == nil) != ipv6 {
+				continue
+			}
+			for _, spec := range destinationRuleSpecs(network, rule) {
+				iptablesRule := iptables.InsertAt("FORWARD", 1).RuleSpec(append(spec, "-j", target)...)
+				if ipv6 {
+					iptablesRule = iptablesRule.IPv6()
+				}
+				remover, err := iptables.AddRuleWithRemoval(iptablesRule)
+				if err != nil {
+					return err
+				}
+				ruleRemovers = append(ruleRemovers, remover)
+			}
+		}
+		return nil
+	}
+
+	// Every rule is inserted at the top of the chain, so the rules evaluated last are inserted first.
+	if len(allow) > 0 {
+		if err := insert("REJECT", []DestinationRule{{}}); err != nil {
+			removeAll()
+			return nil, err
+		}
+	}
+	if err := insert("ACCEPT", allow); err != nil {
+		removeAll()
+		return nil, err
+	}
+	if err := insert("REJECT", deny); err != nil {
+		removeAll()
+		return nil, err
+	}
+
+	return removeAll, nil
+}
+
+func destinationRuleSpecs(network net.IPNet, rule DestinationRule) [][]string {
+	spec := []string{"-s", network.String()}
+	if rule.Network != nil {
+		spec = append(spec, "-d", rule.Network.String())
+	}
+	if rule.PortFrom == 0 && rule.Protocol == "" {
+		return [][]string{spec}
+	}
+
+	protocols := []string{"tcp", "udp"}
+	if rule.Protocol != "" {
+		protocols = []string{rule.Protocol}
+	}
+
+	specs := make([][]string, 0, len(protocols))
+	for _, protocol := range protocols {
+		protocolSpec := append(append([]string{}, spec...), "-p", protocol)
+		if rule.PortFrom != 0 {
+			protocolSpec = append(protocolSpec, "--dport", portRange(rule.PortFrom, rule.PortTo))
+		}
+		specs = append(specs, protocolSpec)
+	}
+	return specs
+}
+
+func portRange(from, to int) string {
+	if to == 0 || to == from {
+		return strconv.Itoa(from)
+	}
+	return strconv.Itoa(from) + ":" + strconv.Itoa(to)
+}
+
 func (ibi *incomingFirewallIptables) checkIpsetVersion() error {
 	output, err := ipset.Exec(ipset.OpVersion())
 	if err != nil {
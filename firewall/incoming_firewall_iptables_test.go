@@ -134,6 +134,64 @@ func Test_incomingFirewallIptables_BlockIncomingTraffic(t *testing.T) {
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-D FORWARD -s 10.8.0.0/24 -j MYST_PROVIDER_FIREWALL"))
 }
 
+func Test_incomingFirewallIptables_RestrictDestinations(t *testing.T) {
+	mockedIptables := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedIptables.Exec
+
+	fw := &incomingFirewallIptables{}
+
+	_, network, _ := net.ParseCIDR("10.8.0.1/24")
+	_, allowed, _ := net.ParseCIDR("192.168.0.0/16")
+	removeRule, err := fw.RestrictDestinations(
+		*network,
+		[]DestinationRule{{Network: allowed}},
+		[]DestinationRule{{Protocol: "tcp", PortFrom: 25}, {PortFrom: 6881, PortTo: 6999}},
+	)
+	assert.NoError(t, err)
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-I FORWARD 1 -s 10.8.0.0/24 -j REJECT"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-I FORWARD 1 -s 10.8.0.0/24 -d 192.168.0.0/16 -j ACCEPT"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-I FORWARD 1 -s 10.8.0.0/24 -p tcp --dport 25 -j REJECT"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-I FORWARD 1 -s 10.8.0.0/24 -p tcp --dport 6881:6999 -j REJECT"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-I FORWARD 1 -s 10.8.0.0/24 -p udp --dport 6881:6999 -j REJECT"))
+
+	assert.NoError(t, removeRule())
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-D FORWARD -s 10.8.0.0/24 -j REJECT"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-D FORWARD -s 10.8.0.0/24 -p udp --dport 6881:6999 -j REJECT"))
+}
+
+func Test_incomingFirewallIptables_RestrictDestinationsIPv6(t *testing.T) {
+	mockedIptables := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedIptables.Exec
+	mockedIptables6 := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec6 = mockedIptables6.Exec
+
+	fw := &incomingFirewallIptables{}
+
+	_, network, _ := net.ParseCIDR("fd00::/64")
+	_, allowed, _ := net.ParseCIDR("192.168.0.0/16")
+	_, denied, _ := net.ParseCIDR("2001:db8::/32")
+	removeRule, err := fw.RestrictDestinations(
+		*network,
+		[]DestinationRule{{Network: allowed}},
+		[]DestinationRule{{Network: denied}, {Protocol: "tcp", PortFrom: 25}},
+	)
+	assert.NoError(t, err)
+	assert.Empty(t, mockedIptables.mocks)
+	assert.True(t, mockedIptables6.VerifyCalledWithArgs("-I FORWARD 1 -s fd00::/64 -j REJECT"))
+	assert.True(t, mockedIptables6.VerifyCalledWithArgs("-I FORWARD 1 -s fd00::/64 -d 2001:db8::/32 -j REJECT"))
+	assert.True(t, mockedIptables6.VerifyCalledWithArgs("-I FORWARD 1 -s fd00::/64 -p tcp --dport 25 -j REJECT"))
+	assert.False(t, mockedIptables6.VerifyCalledWithArgs("-I FORWARD 1 -s fd00::/64 -d 192.168.0.0/16 -j ACCEPT"))
+
+	assert.NoError(t, removeRule())
+	assert.True(t, mockedIptables6.VerifyCalledWithArgs("-D FORWARD -s fd00::/64 -j REJECT"))
+}
+
 func Test_incomingFirewallIptables_AllowIPAccess(t *testing.T) {
 	mockedIpset := ipsetExecMock{
 		mocks: map[string]ipsetExecResult{},
@@ -69,4 +69,13 @@ func (ifn *incomingFirewallNoop) AllowIPAccess(ip net.IP) (IncomingRuleRemove, e
 	}, nil
 }
 
+// RestrictDestinations logs network for which destinations restriction was requested.
+func (ifn *incomingFirewallNoop) RestrictDestinations(network net.IPNet, allow, deny []DestinationRule) (IncomingRuleRemove, error) {
+	log.Info().Msgf("Restrict %s destinations, allowed: %d, denied: %d", network.String(), len(allow), len(deny))
+	return func() error {
+		log.Info().Msgf("Destinations restriction for %s removed", network.String())
+		return nil
+	}, nil
+}
+
 var _ IncomingTrafficFirewall = &incomingFirewallNoop{}
@@ -35,9 +35,10 @@ var DefaultOutgoingFirewall OutgoingTrafficFirewall = &outgoingFirewallNoop{}
 type OutgoingTrafficFirewall interface {
 	Setup() error
 	Teardown()
-	BlockOutgoingTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error)
+	BlockOutgoingTraffic(scope Scope, outboundIP string, destinations ...string) (OutgoingRuleRemove, error)
 	AllowIPAccess(ip string) (OutgoingRuleRemove, error)
 	AllowURLAccess(rawURLs ...string) (OutgoingRuleRemove, error)
+	AllowAppAccess(cgroup string) (OutgoingRuleRemove, error)
 }
 
 // Scope type represents scope of blocking consumer traffic.
@@ -47,8 +48,9 @@ type Scope string
 type OutgoingRuleRemove func()
 
 // BlockNonTunnelTraffic effectively disallows any outgoing traffic from consumer node with specified scope.
-func BlockNonTunnelTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error) {
-	return DefaultOutgoingFirewall.BlockOutgoingTraffic(scope, outboundIP)
+// If destination networks are given, only the traffic to them is blocked.
+func BlockNonTunnelTraffic(scope Scope, outboundIP string, destinations ...string) (OutgoingRuleRemove, error) {
+	return DefaultOutgoingFirewall.BlockOutgoingTraffic(scope, outboundIP, destinations...)
 }
 
 // AllowURLAccess adds exception to blocked traffic for specified URL (host part is usually taken).
@@ -61,6 +63,11 @@ func AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
 	return DefaultOutgoingFirewall.AllowIPAccess(ip)
 }
 
+// AllowAppAccess adds exception for applications of the given cgroup.
+func AllowAppAccess(cgroup string) (OutgoingRuleRemove, error) {
+	return DefaultOutgoingFirewall.AllowAppAccess(cgroup)
+}
+
 // Reset firewall state - usually called when cleanup is needed (during shutdown).
 func Reset() {
 	DefaultOutgoingFirewall.Teardown()
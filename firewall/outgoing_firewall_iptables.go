@@ -18,6 +18,7 @@
 package firewall
 
 import (
+	"net"
 	"net/url"
 	"strings"
 	"sync"
@@ -26,7 +27,11 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-const killswitchChain = "MYST_CONSUMER_KILL_SWITCH"
+const (
+	killswitchChain = "MYST_CONSUMER_KILL_SWITCH"
+	// tunnelIfaces matches consumer tunnel interfaces, IPv6 traffic leaving through any other interface is blocked.
+	tunnelIfaces = "myst+"
+)
 
 type refCount struct {
 	count int
@@ -37,6 +42,7 @@ type outgoingFirewallIptables struct {
 	lock             sync.Mutex
 	trafficLockScope Scope
 	referenceTracker map[string]refCount
+	ipv6             bool
 }
 
 // Setup tries to setup all changes made by setup and leave system in the state before setup.
@@ -44,40 +50,111 @@ func (obi *outgoingFirewallIptables) Setup() error {
 	if err := obi.checkIptablesVersion(); err != nil {
 		return err
 	}
-	if err := obi.cleanupStaleRules(); err != nil {
+	if err := obi.cleanupStaleRules(iptables.Exec); err != nil {
+		return err
+	}
+	if err := obi.setupKillSwitchChain(iptables.Exec); err != nil {
 		return err
 	}
-	return obi.setupKillSwitchChain()
+
+	obi.ipv6 = obi.setupIPv6()
+	return nil
 }
 
 // Teardown tries to cleanup all changes made by setup and leave system in the state before setup.
 func (obi *outgoingFirewallIptables) Teardown() {
-	if err := obi.cleanupStaleRules(); err != nil {
+	if err := obi.cleanupStaleRules(iptables.Exec); err != nil {
 		log.Warn().Err(err).Msg("Error cleaning up iptables rules, you might want to do it yourself")
 	}
+	if obi.ipv6 {
+		if err := obi.cleanupStaleRules(iptables.Exec6); err != nil {
+			log.Warn().Err(err).Msg("Error cleaning up ip6tables rules, you might want to do it yourself")
+		}
+	}
+}
+
+// setupIPv6 sets up IPv6 kill switch chain, IPv6 traffic is left untouched if ip6tables is not available.
+func (obi *outgoingFirewallIptables) setupIPv6() bool {
+	if _, err := iptables.Exec6("--version"); err != nil {
+		log.Warn().Err(err).Msg("ip6tables is not available, IPv6 traffic will not be blocked by kill switch")
+		return false
+	}
+	if err := obi.cleanupStaleRules(iptables.Exec6); err != nil {
+		log.Warn().Err(err).Msg("Failed to clean up stale ip6tables rules")
+		return false
+	}
+	if err := obi.setupKillSwitchChain(iptables.Exec6); err != nil {
+		log.Warn().Err(err).Msg("Failed to set up IPv6 kill switch chain")
+		return false
+	}
+	// Loopback and link-local traffic never goes through tunnel
+	for _, spec := range [][]string{{"-o", "lo"}, {"-d", "fe80::/10"}} {
+		args := append([]string{"-I", killswitchChain, "1"}, append(spec, "-j", "ACCEPT")...)
+		if _, err := iptables.Exec6(args...); err != nil {
+			log.Warn().Err(err).Msg("Failed to set up IPv6 kill switch chain")
+			return false
+		}
+	}
+	return true
 }
 
 // BlockOutgoingTraffic effectively disallows any outgoing traffic from consumer node with specified scope.
-func (obi *outgoingFirewallIptables) BlockOutgoingTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error) {
+// If destination networks are given, only the traffic to them is blocked.
+func (obi *outgoingFirewallIptables) BlockOutgoingTraffic(scope Scope, outboundIP string, destinations ...string) (OutgoingRuleRemove, error) {
 	if obi.trafficLockScope == Global {
 		// nothing can override global lock
 		return func() {}, nil
 	}
 	obi.trafficLockScope = scope
 	return obi.trackingReferenceCall("block-traffic", func() (OutgoingRuleRemove, error) {
-		// Take custom chain into effect for packets in OUTPUT
-		return iptables.AddRuleWithRemoval(
-			iptables.AppendTo("OUTPUT").RuleSpec("-s", outboundIP, "-j", killswitchChain),
-		)
+		if len(destinations) == 0 {
+			return addRulesWithRemoval(obi.blockRules(outboundIP, "")...)
+		}
+
+		var rules []iptables.Rule
+		for _, destination := range destinations {
+			rules = append(rules, obi.blockRules(outboundIP, destination)...)
+		}
+		return addRulesWithRemoval(rules...)
 	})
 }
 
+// blockRules returns rules taking custom chain into effect for packets in OUTPUT to the given destination network,
+// all packets are taken if the destination is empty.
+func (obi *outgoingFirewallIptables) blockRules(outboundIP, destination string) []iptables.Rule {
+	var spec []string
+	ipv4, ipv6 := true, obi.ipv6
+	if destination != "" {
+		spec = []string{"-d", destination}
+		if ip, _, err := net.ParseCIDR(destination); err == nil && ip.To4() == nil {
+			ipv4 = false
+		} else {
+			ipv6 = false
+		}
+	}
+
+	var rules []iptables.Rule
+	if ipv4 {
+		rules = append(rules, iptables.AppendTo("OUTPUT").RuleSpec(append(append([]string{"-s", outboundIP}, spec...), "-j", killswitchChain)...))
+	}
+	if ipv6 {
+		// Outbound IPv6 address is not known, so IPv6 packets not leaving through tunnel are taken instead.
+		rules = append(rules, iptables.AppendTo("OUTPUT").RuleSpec(append(append([]string{"!", "-o", tunnelIfaces}, spec...), "-j", killswitchChain)...).IPv6())
+	}
+	return rules
+}
+
 // AllowIPAccess adds exception to blocked traffic for specified URL (host part is usually taken).
 func (obi *outgoingFirewallIptables) AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
-	return obi.trackingReferenceCall("allow:"+ip, func() (rule OutgoingRuleRemove, e error) {
-		return iptables.AddRuleWithRemoval(
-			iptables.InsertAt(killswitchChain, 1).RuleSpec("-d", ip, "-j", "ACCEPT"),
-		)
+	return obi.trackingReferenceCall("allow:"+ip, func() (OutgoingRuleRemove, error) {
+		rule := iptables.InsertAt(killswitchChain, 1).RuleSpec("-d", ip, "-j", "ACCEPT")
+		if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+			if !obi.ipv6 {
+				return func() {}, nil
+			}
+			rule = rule.IPv6()
+		}
+		return iptables.AddRuleWithRemoval(rule)
 	})
 }
 
@@ -106,6 +183,19 @@ func (obi *outgoingFirewallIptables) AllowURLAccess(rawURLs ...string) (Outgoing
 	return removeAll, nil
 }
 
+// AllowAppAccess adds exception to blocked traffic for applications of the given cgroup (v2 path).
+func (obi *outgoingFirewallIptables) AllowAppAccess(cgroup string) (OutgoingRuleRemove, error) {
+	return obi.trackingReferenceCall("allow-app:"+cgroup, func() (rule OutgoingRuleRemove, e error) {
+		rules := []iptables.Rule{
+			iptables.InsertAt(killswitchChain, 1).RuleSpec("-m", "cgroup", "--path", cgroup, "-j", "ACCEPT"),
+		}
+		if obi.ipv6 {
+			rules = append(rules, rules[0].IPv6())
+		}
+		return addRulesWithRemoval(rules...)
+	})
+}
+
 func (obi *outgoingFirewallIptables) checkIptablesVersion() error {
 	output, err := iptables.Exec("--version")
 	if err != nil {
@@ -117,31 +207,31 @@ func (obi *outgoingFirewallIptables) checkIptablesVersion() error {
 	return nil
 }
 
-func (obi *outgoingFirewallIptables) setupKillSwitchChain() error {
+func (obi *outgoingFirewallIptables) setupKillSwitchChain(exec iptablesExec) error {
 	// Add chain
-	if _, err := iptables.Exec("-N", killswitchChain); err != nil {
+	if _, err := exec("-N", killswitchChain); err != nil {
 		return err
 	}
 	// Append rule - by default all packets going to kill switch chain are rejected
-	if _, err := iptables.Exec("-A", killswitchChain, "-m", "conntrack", "--ctstate", "NEW", "-j", "REJECT"); err != nil {
+	if _, err := exec("-A", killswitchChain, "-m", "conntrack", "--ctstate", "NEW", "-j", "REJECT"); err != nil {
 		return err
 	}
 
 	// Insert rule - TODO for now always allow outgoing DNS traffic, BUT it should be exposed as separate firewall call
-	if _, err := iptables.Exec("-I", killswitchChain, "1", "-p", "udp", "--dport", "53", "-j", "ACCEPT"); err != nil {
+	if _, err := exec("-I", killswitchChain, "1", "-p", "udp", "--dport", "53", "-j", "ACCEPT"); err != nil {
 		return err
 	}
 	// Insert rule - TCP DNS is not so popular - but for the sake of humanity, lets allow it too
-	if _, err := iptables.Exec("-I", killswitchChain, "1", "-p", "tcp", "--dport", "53", "-j", "ACCEPT"); err != nil {
+	if _, err := exec("-I", killswitchChain, "1", "-p", "tcp", "--dport", "53", "-j", "ACCEPT"); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (obi *outgoingFirewallIptables) cleanupStaleRules() error {
+func (obi *outgoingFirewallIptables) cleanupStaleRules(exec iptablesExec) error {
 	// List rules
-	rules, err := iptables.Exec("-S", "OUTPUT")
+	rules, err := exec("-S", "OUTPUT")
 	if err != nil {
 		return err
 	}
@@ -150,26 +240,26 @@ func (obi *outgoingFirewallIptables) cleanupStaleRules() error {
 		if strings.HasSuffix(rule, killswitchChain) {
 			deleteRule := strings.Replace(rule, "-A", "-D", 1)
 			deleteRuleArgs := strings.Split(deleteRule, " ")
-			if _, err := iptables.Exec(deleteRuleArgs...); err != nil {
+			if _, err := exec(deleteRuleArgs...); err != nil {
 				return err
 			}
 		}
 	}
 
 	// List chain rules
-	if _, err := iptables.Exec("-L", killswitchChain); err != nil {
+	if _, err := exec("-L", killswitchChain); err != nil {
 		// error means no such chain - log error just in case and bail out
 		log.Info().Err(err).Msg("[setup] Got error while listing kill switch chain rules. Probably nothing to worry about")
 		return nil
 	}
 
 	// Remove chain rules
-	if _, err := iptables.Exec("-F", killswitchChain); err != nil {
+	if _, err := exec("-F", killswitchChain); err != nil {
 		return err
 	}
 
 	// Remove chain
-	_, err = iptables.Exec("-X", killswitchChain)
+	_, err = exec("-X", killswitchChain)
 	return err
 }
 
@@ -207,4 +297,24 @@ func (obi *outgoingFirewallIptables) decreaseRefCall(ref string) OutgoingRuleRem
 	}
 }
 
+type iptablesExec func(args ...string) ([]string, error)
+
+func addRulesWithRemoval(rules ...iptables.Rule) (OutgoingRuleRemove, error) {
+	var removers []func()
+	removeAll := func() {
+		for _, remove := range removers {
+			remove()
+		}
+	}
+	for _, rule := range rules {
+		remove, err := iptables.AddRuleWithRemoval(rule)
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+		removers = append(removers, remove)
+	}
+	return removeAll, nil
+}
+
 var _ OutgoingTrafficFirewall = &outgoingFirewallIptables{}
@@ -42,6 +42,66 @@ func Test_outgoingFirewallIptables_BlocksAllOutgoingTraffic(t *testing.T) {
 	assert.True(t, mockedExec.VerifyCalledWithArgs("-D", "OUTPUT", "-s", "1.1.1.1", "-j", killswitchChain))
 }
 
+func Test_outgoingFirewallIptables_BlocksIPv6TrafficNotLeavingThroughTunnel(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	mockedExec6 := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedExec.Exec
+	iptables.Exec6 = mockedExec6.Exec
+
+	fw := &outgoingFirewallIptables{
+		referenceTracker: make(map[string]refCount),
+	}
+	assert.NoError(t, fw.Setup())
+	assert.True(t, fw.ipv6)
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-N", killswitchChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-I", killswitchChain, "1", "-o", "lo", "-j", "ACCEPT"))
+
+	removeRuleFunc, err := fw.BlockOutgoingTraffic("test-scope", "1.1.1.1")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-A", "OUTPUT", "-s", "1.1.1.1", "-j", killswitchChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-A", "OUTPUT", "!", "-o", tunnelIfaces, "-j", killswitchChain))
+
+	removeAllowFunc, err := fw.AllowIPAccess("2001:db8::1")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-I", killswitchChain, "1", "-d", "2001:db8::1", "-j", "ACCEPT"))
+	assert.False(t, mockedExec.VerifyCalledWithArgs("-I", killswitchChain, "1", "-d", "2001:db8::1", "-j", "ACCEPT"))
+
+	removeAllowFunc()
+	removeRuleFunc()
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-D", "OUTPUT", "!", "-o", tunnelIfaces, "-j", killswitchChain))
+}
+
+func Test_outgoingFirewallIptables_BlocksOutgoingTrafficToDestinations(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	mockedExec6 := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedExec.Exec
+	iptables.Exec6 = mockedExec6.Exec
+
+	fw := &outgoingFirewallIptables{
+		referenceTracker: make(map[string]refCount),
+		ipv6:             true,
+	}
+
+	removeRuleFunc, err := fw.BlockOutgoingTraffic("test-scope", "1.1.1.1", "10.0.0.0/8", "2001:db8::/32")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-A", "OUTPUT", "-s", "1.1.1.1", "-d", "10.0.0.0/8", "-j", killswitchChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-A", "OUTPUT", "!", "-o", tunnelIfaces, "-d", "2001:db8::/32", "-j", killswitchChain))
+	assert.False(t, mockedExec.VerifyCalledWithArgs("-A", "OUTPUT", "-s", "1.1.1.1", "-j", killswitchChain))
+	assert.False(t, mockedExec6.VerifyCalledWithArgs("-A", "OUTPUT", "!", "-o", tunnelIfaces, "-j", killswitchChain))
+
+	removeRuleFunc()
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-D", "OUTPUT", "-s", "1.1.1.1", "-d", "10.0.0.0/8", "-j", killswitchChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-D", "OUTPUT", "!", "-o", tunnelIfaces, "-d", "2001:db8::/32", "-j", killswitchChain))
+}
+
 func Test_outgoingFirewallIptables_SessionTrafficBlockIsNoopWhenGlobalBlockWasCalled(t *testing.T) {
 	mockedExec := iptablesExecMock{
 		mocks: map[string]iptablesExecResult{},
@@ -78,6 +138,17 @@ func Test_outgoingFirewallIptables_AllowIPAccessIsAddedAndRemoved(t *testing.T)
 	assert.Equal(t, 0, fw.referenceTracker["allow:test-ip"].count)
 }
 
+func Test_outgoingFirewallIptables_AllowAppAccessIsAddedAndRemoved(t *testing.T) {
+	fw := &outgoingFirewallIptables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	removeRule, _ := fw.AllowAppAccess("user.slice/browser.scope")
+	assert.Equal(t, 1, fw.referenceTracker["allow-app:user.slice/browser.scope"].count)
+	removeRule()
+	assert.Equal(t, 0, fw.referenceTracker["allow-app:user.slice/browser.scope"].count)
+}
+
 func Test_outgoingFirewallIptables_HostsFromMultipleURLsAreAllowed(t *testing.T) {
 	fw := &outgoingFirewallIptables{
 		referenceTracker: make(map[string]refCount),
@@ -123,6 +194,7 @@ func Test_outgoingFirewallIptables_SetupIsSuccessful(t *testing.T) {
 		},
 	}
 	iptables.Exec = mockedExec.Exec
+	iptables.Exec6 = mockedExec.Exec
 
 	fw := &outgoingFirewallIptables{
 		referenceTracker: make(map[string]refCount),
@@ -156,6 +228,7 @@ func Test_outgoingFirewallIptables_SetupIsSucessfulIfPreviousCleanupFailed(t *te
 		},
 	}
 	iptables.Exec = mockedExec.Exec
+	iptables.Exec6 = mockedExec.Exec
 
 	fw := &outgoingFirewallIptables{
 		referenceTracker: make(map[string]refCount),
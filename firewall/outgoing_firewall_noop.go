@@ -36,7 +36,7 @@ func (ofn *outgoingFirewallNoop) Teardown() {
 }
 
 // BlockOutgoingTraffic just logs the call.
-func (ofn *outgoingFirewallNoop) BlockOutgoingTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error) {
+func (ofn *outgoingFirewallNoop) BlockOutgoingTraffic(scope Scope, outboundIP string, destinations ...string) (OutgoingRuleRemove, error) {
 	log.Info().Msg("Outgoing traffic block requested")
 	return func() {
 		log.Info().Msg("Outgoing traffic block removed")
@@ -63,4 +63,12 @@ func (ofn *outgoingFirewallNoop) AllowURLAccess(rawURLs ...string) (OutgoingRule
 	}, nil
 }
 
+// AllowAppAccess logs cgroup for which access was requested.
+func (ofn *outgoingFirewallNoop) AllowAppAccess(cgroup string) (OutgoingRuleRemove, error) {
+	log.Info().Msgf("Allow cgroup %s access", cgroup)
+	return func() {
+		log.Info().Msgf("Rule for cgroup: %s removed", cgroup)
+	}, nil
+}
+
 var _ OutgoingTrafficFirewall = &outgoingFirewallNoop{}
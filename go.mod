@@ -25,15 +25,20 @@ require (
 	github.com/golang/protobuf v1.5.2
 	github.com/google/go-github/v35 v35.2.0
 	github.com/huin/goupnp v1.0.2
+	github.com/ipfs/go-cid v0.0.5
 	github.com/jackpal/gateway v1.0.6
 	github.com/julienschmidt/httprouter v1.2.0
 	github.com/koron/go-ssdp v0.0.2
 	github.com/libp2p/go-libp2p v0.5.2
 	github.com/libp2p/go-libp2p-core v0.3.0
+	github.com/libp2p/go-libp2p-kad-dht v0.5.0
+	github.com/libp2p/go-libp2p-record v0.1.2
+	github.com/libp2p/go-yamux v1.2.3
 	github.com/magefile/mage v1.11.0
 	github.com/mholt/archiver v3.1.1+incompatible
 	github.com/miekg/dns v1.1.29
 	github.com/multiformats/go-multiaddr v0.2.0
+	github.com/multiformats/go-multihash v0.0.13
 	github.com/mysteriumnetwork/feedback v1.1.1
 	github.com/mysteriumnetwork/go-ci v0.0.0-20211124142828-37ca8ff3ef34
 	github.com/mysteriumnetwork/go-dvpn-web v1.2.0
@@ -48,6 +53,7 @@ require (
 	github.com/pion/stun v0.3.5
 	github.com/pkg/errors v0.9.1
 	github.com/rs/zerolog v1.17.2
+	github.com/shirou/gopsutil v3.21.4-0.20210419000835-c7a38de76ee5+incompatible
 	github.com/shurcooL/vfsgen v0.0.0-20200627165143-92b8a710ab6c
 	github.com/songgao/water v0.0.0-20190112225332-f6122f5b2fbd
 	github.com/spf13/cast v1.3.1
@@ -108,7 +114,6 @@ require (
 	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/hashicorp/golang-lru v0.5.5-0.20210104140557-80c98217689d // indirect
 	github.com/imdario/mergo v0.3.12 // indirect
-	github.com/ipfs/go-cid v0.0.5 // indirect
 	github.com/ipfs/go-ipfs-util v0.0.1 // indirect
 	github.com/ipfs/go-log v0.0.1 // indirect
 	github.com/jackpal/go-nat-pmp v1.0.2 // indirect
@@ -153,7 +158,6 @@ require (
 	github.com/libp2p/go-stream-muxer-multistream v0.2.0 // indirect
 	github.com/libp2p/go-tcp-transport v0.1.1 // indirect
 	github.com/libp2p/go-ws-transport v0.2.0 // indirect
-	github.com/libp2p/go-yamux v1.2.3 // indirect
 	github.com/mattn/go-colorable v0.1.8 // indirect
 	github.com/mattn/go-isatty v0.0.12 // indirect
 	github.com/mattn/go-pointer v0.0.1 // indirect
@@ -172,7 +176,6 @@ require (
 	github.com/multiformats/go-multiaddr-fmt v0.1.0 // indirect
 	github.com/multiformats/go-multiaddr-net v0.1.2 // indirect
 	github.com/multiformats/go-multibase v0.0.1 // indirect
-	github.com/multiformats/go-multihash v0.0.13 // indirect
 	github.com/multiformats/go-multistream v0.1.1 // indirect
 	github.com/multiformats/go-varint v0.0.5 // indirect
 	github.com/nats-io/nats-server/v2 v2.3.2 // indirect
@@ -187,7 +190,6 @@ require (
 	github.com/robfig/cron v1.2.0 // indirect
 	github.com/russross/blackfriday/v2 v2.0.1 // indirect
 	github.com/sergi/go-diff v1.1.0 // indirect
-	github.com/shirou/gopsutil v3.21.4-0.20210419000835-c7a38de76ee5+incompatible // indirect
 	github.com/shopspring/decimal v1.2.0 // indirect
 	github.com/shurcooL/httpfs v0.0.0-20190707220628-8d4bc4ba7749 // indirect
 	github.com/shurcooL/sanitized_anchor_name v1.0.0 // indirect
@@ -24,6 +24,14 @@ const (
 	AccessPolicyTypeDNSHostname = "dns_hostname"
 	// AccessPolicyTypeDNSZone Explicitly allow just specific DNS zone ("example.com" matches "example.com" and all of its subdomains)
 	AccessPolicyTypeDNSZone = "dns_zone"
+	// AccessPolicyTypeDestinationCIDR Destination network of consumer traffic ("10.0.0.0/8")
+	AccessPolicyTypeDestinationCIDR = "destination_cidr"
+	// AccessPolicyTypeDestinationPort Destination port or port range of consumer traffic, optionally limited to protocol ("25", "tcp/6881-6889")
+	AccessPolicyTypeDestinationPort = "destination_port"
+	// AccessPolicyTypeProtocol Well known protocol of consumer traffic ("smtp", "bittorrent")
+	AccessPolicyTypeProtocol = "protocol"
+	// AccessPolicyTypeConsumerCountry Country of the consumer ("DE")
+	AccessPolicyTypeConsumerCountry = "consumer_country"
 )
 
 // AccessPolicy represents the access controls for proposal
@@ -38,6 +46,7 @@ type AccessPolicyRuleSet struct {
 	Title       string       `json:"title"`
 	Description string       `json:"description"`
 	Allow       []AccessRule `json:"allow"`
+	Deny        []AccessRule `json:"deny,omitempty"`
 }
 
 // AccessRule represents rule specifying whether connection should be allowed
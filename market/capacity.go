@@ -0,0 +1,30 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+// Capacity represents current utilization of the service.
+type Capacity struct {
+	// Sessions is a count of sessions served at the moment.
+	Sessions int `json:"sessions"`
+	// MaxSessions is a limit of concurrent sessions, zero means there is no limit.
+	MaxSessions int `json:"max_sessions,omitempty"`
+	// Load is the highest utilization of session slots, CPU and bandwidth, from 0 to 1.
+	Load float64 `json:"load"`
+	// Full tells that the service rejects new sessions.
+	Full bool `json:"full,omitempty"`
+}
@@ -39,6 +39,7 @@ type ProposalsQuery struct {
 	NATCompatibility        nat.NATType
 	QualityMin              float32
 	IncludeMonitoringFailed bool
+	IPv6                    bool
 }
 
 // ToURLValues converts the query to url.Values.
@@ -78,5 +79,8 @@ func (q ProposalsQuery) ToURLValues() url.Values {
 	if q.IncludeMonitoringFailed {
 		values.Set("include_monitoring_failed", fmt.Sprint(q.IncludeMonitoringFailed))
 	}
+	if q.IPv6 {
+		values.Set("ipv6", fmt.Sprint(q.IPv6))
+	}
 	return values
 }
@@ -19,6 +19,7 @@ package market
 
 import (
 	"encoding/json"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/mysteriumnetwork/node/p2p/compat"
@@ -56,6 +57,18 @@ type ServiceProposal struct {
 
 	// Quality represents the service quality.
 	Quality Quality `json:"quality"`
+
+	// IPv6 tells whether the service provides IPv6 egress.
+	IPv6 bool `json:"ipv6,omitempty"`
+
+	// Price is the price advertised by provider, consumers fall back to discovery pricing when it is not set.
+	Price *Price `json:"price,omitempty"`
+
+	// Capacity is current utilization of the service, it is not set by older providers.
+	Capacity *Capacity `json:"capacity,omitempty"`
+
+	// CachedAt is the time stale proposal was last seen by discovery, it is set only for proposals served from local cache.
+	CachedAt time.Time `json:"-"`
 }
 
 // NewProposalOpts optional params for the new proposal creation.
@@ -64,6 +77,8 @@ type NewProposalOpts struct {
 	AccessPolicies []AccessPolicy
 	Contacts       []Contact
 	Quality        *Quality
+	IPv6           bool
+	Price          *Price
 }
 
 // NewProposal creates a new proposal.
@@ -76,6 +91,8 @@ func NewProposal(providerID, serviceType string, opts NewProposalOpts) ServicePr
 		Location:       Location{},
 		Contacts:       nil,
 		AccessPolicies: nil,
+		IPv6:           opts.IPv6,
+		Price:          opts.Price,
 	}
 	if loc := opts.Location; loc != nil {
 		p.Location = *loc
@@ -123,6 +140,9 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 		Contacts       *json.RawMessage `json:"contacts"`
 		AccessPolicies *[]AccessPolicy  `json:"access_policies,omitempty"`
 		Quality        Quality          `json:"quality"`
+		IPv6           bool             `json:"ipv6,omitempty"`
+		Price          *Price           `json:"price,omitempty"`
+		Capacity       *Capacity        `json:"capacity,omitempty"`
 	}
 	if err := json.Unmarshal(data, &jsonData); err != nil {
 		return err
@@ -139,6 +159,9 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 	proposal.Contacts = unserializeContacts(jsonData.Contacts)
 	proposal.AccessPolicies = jsonData.AccessPolicies
 	proposal.Quality = jsonData.Quality
+	proposal.IPv6 = jsonData.IPv6
+	proposal.Price = jsonData.Price
+	proposal.Capacity = jsonData.Capacity
 
 	return nil
 }
@@ -148,3 +148,49 @@ func Test_ServiceProposal_UnserializeAccessPolicy(t *testing.T) {
 	assert.Equal(t, expected, actual)
 	assert.True(t, actual.IsSupported())
 }
+
+func Test_ServiceProposal_UnserializePrice(t *testing.T) {
+	RegisterServiceType("mock_service")
+	jsonData := []byte(`{
+		"id": 1,
+		"format": "service-proposal/v3",
+		"service_type": "mock_service",
+		"provider_id": "node",
+		"contacts": [
+			{ "type" : "mock_contact" , "definition" : {}}
+		],
+		"price": {
+			"price_per_hour": 60000000000000,
+			"price_per_gib": 100000000000000000
+		}
+	}`)
+
+	var actual ServiceProposal
+	err := json.Unmarshal(jsonData, &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, NewPrice(60000000000000, 100000000000000000), actual.Price)
+}
+
+func Test_ServiceProposal_UnserializeCapacity(t *testing.T) {
+	RegisterServiceType("mock_service")
+	jsonData := []byte(`{
+		"id": 1,
+		"format": "service-proposal/v3",
+		"service_type": "mock_service",
+		"provider_id": "node",
+		"contacts": [
+			{ "type" : "mock_contact" , "definition" : {}}
+		],
+		"capacity": {
+			"sessions": 10,
+			"max_sessions": 10,
+			"load": 1,
+			"full": true
+		}
+	}`)
+
+	var actual ServiceProposal
+	err := json.Unmarshal(jsonData, &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, &Capacity{Sessions: 10, MaxSessions: 10, Load: 1, Full: true}, actual.Capacity)
+}
@@ -19,14 +19,26 @@ package mysterium
 
 import (
 	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/nat"
 )
 
 // ProposalFilterPreset represents proposal filter preset
 type ProposalFilterPreset struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID               int      `json:"id"`
+	Name             string   `json:"name"`
+	IPType           string   `json:"ip_type,omitempty"`
+	Country          string   `json:"country,omitempty"`
+	PriceHourMax     *big.Int `json:"price_hour_max,omitempty"`
+	PriceGiBMax      *big.Int `json:"price_gib_max,omitempty"`
+	QualityMin       float64  `json:"quality_min,omitempty"`
+	NATCompatibility string   `json:"nat_compatibility,omitempty"`
+	AllowedProviders []string `json:"allowed_providers,omitempty"`
+	DeniedProviders  []string `json:"denied_providers,omitempty"`
 }
 
 // ListProposalFilterPresets lists system and user created filter presets for proposals
@@ -45,9 +57,51 @@ func (mb *MobileNode) ListProposalFilterPresets() ([]byte, error) {
 	return json.Marshal(&result)
 }
 
+// SaveProposalFilterPreset creates or updates user filter preset, preset without ID is created.
+// Accepts and returns ProposalFilterPreset encoded as JSON.
+func (mb *MobileNode) SaveProposalFilterPreset(data []byte) ([]byte, error) {
+	var req ProposalFilterPreset
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, errors.New("filter preset name is required")
+	}
+
+	entry := proposal.FilterPreset{
+		ID:               req.ID,
+		Name:             strings.TrimSpace(req.Name),
+		IPType:           proposal.IPType(req.IPType),
+		Country:          strings.ToUpper(req.Country),
+		PriceHourMax:     req.PriceHourMax,
+		PriceGiBMax:      req.PriceGiBMax,
+		QualityMin:       req.QualityMin,
+		NATCompatibility: nat.NATType(req.NATCompatibility),
+		AllowedProviders: req.AllowedProviders,
+		DeniedProviders:  req.DeniedProviders,
+	}
+	if err := mb.filterPresetStorage.Save(&entry); err != nil {
+		return nil, err
+	}
+	return json.Marshal(preset(entry))
+}
+
+// DeleteProposalFilterPreset removes user created filter preset.
+func (mb *MobileNode) DeleteProposalFilterPreset(id int) error {
+	return mb.filterPresetStorage.Delete(id)
+}
+
 func preset(entry proposal.FilterPreset) ProposalFilterPreset {
 	return ProposalFilterPreset{
-		ID:   entry.ID,
-		Name: entry.Name,
+		ID:               entry.ID,
+		Name:             entry.Name,
+		IPType:           string(entry.IPType),
+		Country:          entry.Country,
+		PriceHourMax:     entry.PriceHourMax,
+		PriceGiBMax:      entry.PriceGiBMax,
+		QualityMin:       entry.QualityMin,
+		NATCompatibility: string(entry.NATCompatibility),
+		AllowedProviders: entry.AllowedProviders,
+		DeniedProviders:  entry.DeniedProviders,
 	}
 }
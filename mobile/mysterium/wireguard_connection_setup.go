@@ -110,6 +110,7 @@ func (c *wireguardConnection) Statistics() (connectionstate.Statistics, error) {
 		At:            time.Now(),
 		BytesSent:     stats.BytesSent,
 		BytesReceived: stats.BytesReceived,
+		LastHandshake: stats.LastHandshake,
 	}, nil
 }
 
@@ -272,6 +273,9 @@ func (w *wireguardDeviceImpl) applyConfig(devApi *device.Device, privateKey stri
 		},
 		ReplacePeers: true,
 	}
+	if config.Consumer.IPAddress6.IP != nil {
+		deviceConfig.Peer.AllowedIPs = append(deviceConfig.Peer.AllowedIPs, "::/1", "8000::/1")
+	}
 
 	if err := devApi.IpcSetOperation(bufio.NewReader(strings.NewReader(deviceConfig.Encode()))); err != nil {
 		return fmt.Errorf("could not complete ipc operation: %w", err)
@@ -285,6 +289,10 @@ func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, co
 	wgTunnSetup.NewTunnel()
 	wgTunnSetup.SetSessionName("wg-tun-session")
 	wgTunnSetup.AddTunnelAddress(consumerIP.IP.String(), prefixLen)
+	if consumerIP6 := config.Consumer.IPAddress6; consumerIP6.IP != nil {
+		prefixLen6, _ := consumerIP6.Mask.Size()
+		wgTunnSetup.AddTunnelAddress(consumerIP6.IP.String(), prefixLen6)
+	}
 	wgTunnSetup.SetMTU(androidTunMtu)
 	wgTunnSetup.SetBlocking(true)
 
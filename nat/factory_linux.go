@@ -30,5 +30,13 @@ func NewService() NATService {
 			CommandDisable: []string{"sudo", "/sbin/sysctl", "-w", "net.ipv4.ip_forward=0"},
 			CommandRead:    []string{"/sbin/sysctl", "-n", "net.ipv4.ip_forward"},
 		},
+		ipForward6: serviceIPForward{
+			CommandFactory: func(name string, arg ...string) Command {
+				return exec.Command(name, arg...)
+			},
+			CommandEnable:  []string{"sudo", "/sbin/sysctl", "-w", "net.ipv6.conf.all.forwarding=1"},
+			CommandDisable: []string{"sudo", "/sbin/sysctl", "-w", "net.ipv6.conf.all.forwarding=0"},
+			CommandRead:    []string{"/sbin/sysctl", "-n", "net.ipv6.conf.all.forwarding"},
+		},
 	}
 }
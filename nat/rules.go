@@ -40,3 +40,16 @@ func protectedNetworks() (nets []*net.IPNet) {
 	}
 	return nets
 }
+
+// privateIPv6Networks are never reachable via VPN, IPv6 networks of the protected networks setting are added to them.
+var privateIPv6Networks = []string{"fc00::/7", "fe80::/10"}
+
+func protectedIPv6Networks() (nets []string) {
+	nets = append(nets, privateIPv6Networks...)
+	for _, ipNet := range protectedNetworks() {
+		if ipNet.IP.To4() == nil {
+			nets = append(nets, ipNet.String())
+		}
+	}
+	return nets
+}
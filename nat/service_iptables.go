@@ -19,6 +19,7 @@ package nat
 
 import (
 	"fmt"
+	"net"
 	"strconv"
 	"sync"
 
@@ -32,9 +33,10 @@ import (
 )
 
 type serviceIPTables struct {
-	mu        sync.Mutex
-	rules     []iptables.Rule
-	ipForward serviceIPForward
+	mu         sync.Mutex
+	rules      []iptables.Rule
+	ipForward  serviceIPForward
+	ipForward6 serviceIPForward
 }
 
 const (
@@ -109,6 +111,10 @@ func (svc *serviceIPTables) Enable() error {
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to enable IP forwarding")
 	}
+
+	if err := svc.ipForward6.Enable(); err != nil {
+		log.Warn().Err(err).Msg("Failed to enable IPv6 forwarding, IPv6 traffic will not be routed")
+	}
 	return err
 }
 
@@ -120,6 +126,7 @@ func (svc *serviceIPTables) Disable() error {
 	}
 
 	svc.ipForward.Disable()
+	svc.ipForward6.Disable()
 	err := svc.Del(untypedIptRules(svc.rules))
 	if err != nil {
 		return fmt.Errorf("failed to cleanup iptables rules")
@@ -134,7 +141,7 @@ func (svc *serviceIPTables) Disable() error {
 }
 
 func (svc *serviceIPTables) applyRule(rule iptables.Rule) error {
-	if err := iptablesExec(rule.ApplyArgs()...); err != nil {
+	if err := execRule(rule, rule.ApplyArgs()); err != nil {
 		return err
 	}
 	svc.rules = append(svc.rules, rule)
@@ -142,7 +149,7 @@ func (svc *serviceIPTables) applyRule(rule iptables.Rule) error {
 }
 
 func (svc *serviceIPTables) removeRule(rule iptables.Rule) error {
-	if err := iptablesExec(rule.RemoveArgs()...); err != nil {
+	if err := execRule(rule, rule.RemoveArgs()); err != nil {
 		return err
 	}
 	for i := range svc.rules {
@@ -161,6 +168,11 @@ func (svc *serviceIPTables) prepare() error {
 	}
 
 	for _, ipNet := range protectedNetworks() {
+		if ipNet.IP.To4() == nil {
+			// IPv6 networks are protected by forwarding rules of each session
+			continue
+		}
+
 		// Protect private networks rule
 		err = svc.applyRule(iptables.AppendTo(chainMyst).RuleSpec(
 			"--destination", ipNet.String(), "--jump", "DNAT", "--to-destination", "240.0.0.1", "--table", "nat"))
@@ -223,9 +235,41 @@ func makeIPTablesRules(opts Options) (rules []iptables.Rule) {
 	rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--jump", "ACCEPT"))
 	rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--destination", vpnNetwork, "--jump", "ACCEPT"))
 
+	if opts.VPNNetwork6.IP != nil {
+		rules = append(rules, makeIP6TablesRules(opts.VPNNetwork6)...)
+	}
+
 	return rules
 }
 
+func makeIP6TablesRules(vpnNetwork6 net.IPNet) (rules []iptables.Rule) {
+	vpnNetwork := vpnNetwork6.String()
+
+	// Protect private networks rules
+	for _, ipNet := range protectedIPv6Networks() {
+		rules = append(rules, iptables.AppendTo(chainForward).RuleSpec(
+			"--source", vpnNetwork, "--destination", ipNet, "--jump", "REJECT").IPv6())
+	}
+
+	// NAT forwarding rule, masquerade is used since provider might have many or changing IPv6 addresses
+	rules = append(rules, iptables.AppendTo(chainPostRouting).RuleSpec("--source", vpnNetwork, "!", "--destination", vpnNetwork,
+		"--jump", "MASQUERADE",
+		"--table", "nat").IPv6())
+
+	// ACCEPT forwarding rules
+	rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--jump", "ACCEPT").IPv6())
+	rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--destination", vpnNetwork, "--jump", "ACCEPT").IPv6())
+
+	return rules
+}
+
+func execRule(rule iptables.Rule, args []string) error {
+	if rule.IsIPv6() {
+		return ip6tablesExec(args...)
+	}
+	return iptablesExec(args...)
+}
+
 func iptablesExec(args ...string) error {
 	args = append([]string{"/usr/sbin/iptables"}, args...)
 	if err := cmdutil.SudoExec(args...); err != nil {
@@ -234,6 +278,14 @@ func iptablesExec(args ...string) error {
 	return nil
 }
 
+func ip6tablesExec(args ...string) error {
+	args = append([]string{"/usr/sbin/ip6tables"}, args...)
+	if err := cmdutil.SudoExec(args...); err != nil {
+		return errors.Wrap(err, "error calling IP6Tables")
+	}
+	return nil
+}
+
 func untypedIptRules(rules []iptables.Rule) []interface{} {
 	res := make([]interface{}, len(rules))
 	for i := range rules {
@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_makeIPTablesRules_AddsIPv6RulesForDualStackNetwork(t *testing.T) {
+	_, vpnNetwork, _ := net.ParseCIDR("10.182.1.0/24")
+	_, vpnNetwork6, _ := net.ParseCIDR("fd00:182:0:1::/64")
+
+	rules := makeIPTablesRules(Options{
+		VPNNetwork:    *vpnNetwork,
+		ProviderExtIP: net.ParseIP("1.2.3.4"),
+	})
+	for _, rule := range rules {
+		assert.False(t, rule.IsIPv6())
+	}
+
+	rules6 := makeIPTablesRules(Options{
+		VPNNetwork:    *vpnNetwork,
+		VPNNetwork6:   *vpnNetwork6,
+		ProviderExtIP: net.ParseIP("1.2.3.4"),
+	})
+	var ipv6Args [][]string
+	for _, rule := range rules6[len(rules):] {
+		assert.True(t, rule.IsIPv6())
+		ipv6Args = append(ipv6Args, rule.ApplyArgs())
+	}
+	assert.Contains(t, ipv6Args, []string{"-A", "FORWARD", "--source", "fd00:182:0:1::/64", "--destination", "fc00::/7", "--jump", "REJECT"})
+	assert.Contains(t, ipv6Args, []string{"-A", "POSTROUTING", "--source", "fd00:182:0:1::/64", "!", "--destination", "fd00:182:0:1::/64", "--jump", "MASQUERADE", "--table", "nat"})
+	assert.Contains(t, ipv6Args, []string{"-A", "FORWARD", "--source", "fd00:182:0:1::/64", "--jump", "ACCEPT"})
+}
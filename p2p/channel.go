@@ -79,6 +79,9 @@ type Channel interface {
 	// Conn returns underlying channel's UDP connection.
 	Conn() *net.UDPConn
 
+	// RemoteAddr returns the address of the peer.
+	RemoteAddr() *net.UDPAddr
+
 	// Close closes p2p communication channel.
 	Close() error
 
@@ -486,6 +489,11 @@ func (c *channel) Conn() *net.UDPConn {
 	return c.tr.remoteConn
 }
 
+// RemoteAddr returns the address of the peer.
+func (c *channel) RemoteAddr() *net.UDPAddr {
+	return c.peer.addr()
+}
+
 // Send sends message to given topic. Peer listening to topic will receive message.
 func (c *channel) Send(ctx context.Context, topic string, msg *Message) (*Message, error) {
 	reply, err := c.sendRequest(ctx, topic, msg)
@@ -79,11 +79,16 @@ func packSignedMsg(signer identity.SignerFactory, signerID identity.Identity, ms
 	if err != nil {
 		return nil, err
 	}
-	signature, err := signer(signerID).Sign(protoBytes)
+	return packSignedData(signer, signerID, protoBytes)
+}
+
+// packSignedData signs given bytes and returns ready to send signed message bytes.
+func packSignedData(signer identity.SignerFactory, signerID identity.Identity, data []byte) ([]byte, error) {
+	signature, err := signer(signerID).Sign(data)
 	if err != nil {
 		return nil, err
 	}
-	signedMsg := &pb.P2PSignedMsg{Data: protoBytes, Signature: signature.Bytes()}
+	signedMsg := &pb.P2PSignedMsg{Data: data, Signature: signature.Bytes()}
 	signedMsgProtoBytes, err := proto.Marshal(signedMsg)
 	if err != nil {
 		return nil, err
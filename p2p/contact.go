@@ -36,6 +36,8 @@ const (
 // ContactDefinition represents p2p contact which contains NATS broker addresses for connection.
 type ContactDefinition struct {
 	BrokerAddresses []string `json:"broker_addresses"`
+	// EchoAddress is the public UDP address replying to consumer echo requests, it is empty if provider has no echo server.
+	EchoAddress string `json:"echo_address,omitempty"`
 }
 
 // ParseContact tries to parse p2p contact from given contacts list.
@@ -0,0 +1,271 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+const (
+	// echoPayloadSize is the size of random echo nonce, it is followed by the address of the pinged provider.
+	echoPayloadSize = 16
+	// maxEchoPacketSize bounds the size of echo request and reply packets.
+	maxEchoPacketSize = 512
+	// echoReplyPrefix separates echo replies signed by provider from any other message signed by its identity.
+	echoReplyPrefix = "mysterium-echo-reply"
+	// echoRate is the number of echo requests per second answered for a single source address.
+	echoRate = 2
+	// echoBurst is the number of echo requests answered for a single source address at once.
+	echoBurst = 5
+	// maxEchoSources bounds the number of source addresses tracked by the echo rate limiter.
+	maxEchoSources = 10000
+)
+
+// ErrNoEchoAddress is returned when provider contact has no echo address, e.g. provider runs an older version.
+var ErrNoEchoAddress = errors.New("p2p contact has no echo address")
+
+// EchoPinger measures round trip time to providers by sending signed echo requests directly over UDP
+// to the echo address of their p2p contacts.
+type EchoPinger struct {
+	signer          identity.SignerFactory
+	verifierFactory identity.VerifierFactory
+}
+
+// NewEchoPinger creates new echo pinger which is used on consumer side.
+func NewEchoPinger(signer identity.SignerFactory, verifierFactory identity.VerifierFactory) *EchoPinger {
+	return &EchoPinger{
+		signer:          signer,
+		verifierFactory: verifierFactory,
+	}
+}
+
+// Echo sends signed echo request to the provider and returns round trip time of the verified reply.
+func (e *EchoPinger) Echo(ctx context.Context, consumerID, providerID identity.Identity, contactDef ContactDefinition) (time.Duration, error) {
+	if contactDef.EchoAddress == "" {
+		return 0, ErrNoEchoAddress
+	}
+
+	payload := make([]byte, echoPayloadSize)
+	if _, err := rand.Read(payload); err != nil {
+		return 0, err
+	}
+	data := append(payload, []byte(providerID.Address)...)
+	packedMsg, err := packSignedData(e.signer, consumerID, data)
+	if err != nil {
+		return 0, fmt.Errorf("could not pack signed message: %w", err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp4", contactDef.EchoAddress)
+	if err != nil {
+		return 0, fmt.Errorf("could not dial echo address: %w", err)
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	start := time.Now()
+	if _, err := conn.Write(packedMsg); err != nil {
+		return 0, fmt.Errorf("could not send echo request: %w", err)
+	}
+	reply := make([]byte, maxEchoPacketSize)
+	n, err := conn.Read(reply)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("could not read echo reply: %w", ctx.Err())
+		}
+		return 0, fmt.Errorf("could not read echo reply: %w", err)
+	}
+	rtt := time.Since(start)
+
+	signedMsg, _, err := unpackSignedMsg(e.verifierFactory(providerID), reply[:n])
+	if err != nil {
+		return 0, fmt.Errorf("could not unpack echo reply: %w", err)
+	}
+	if !bytes.HasPrefix(signedMsg.Data, echoReplyData(payload, "")) {
+		return 0, errors.New("echo reply does not match the request")
+	}
+
+	return rtt, nil
+}
+
+// echoReplyData builds the data of echo reply signed by provider, it never signs the data chosen by consumer as is.
+func echoReplyData(nonce []byte, observedAddr string) []byte {
+	data := make([]byte, 0, len(echoReplyPrefix)+len(nonce)+len(observedAddr))
+	data = append(data, echoReplyPrefix...)
+	data = append(data, nonce...)
+	return append(data, observedAddr...)
+}
+
+// echoServer replies to consumer echo requests with the nonce of the request signed by the pinged provider.
+type echoServer struct {
+	conn     *net.UDPConn
+	signer   identity.SignerFactory
+	verifier identity.Verifier
+
+	mu        sync.Mutex
+	providers map[string]identity.Identity
+
+	limiterMu sync.Mutex
+	sources   map[string]*echoBucket
+}
+
+// echoBucket is a token bucket limiting echo requests of a single source address.
+type echoBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newEchoServer(conn *net.UDPConn, signer identity.SignerFactory, verifier identity.Verifier) *echoServer {
+	return &echoServer{
+		conn:      conn,
+		signer:    signer,
+		verifier:  verifier,
+		providers: make(map[string]identity.Identity),
+		sources:   make(map[string]*echoBucket),
+	}
+}
+
+// add starts replying to echo requests of the provider until the returned function is called.
+func (s *echoServer) add(providerID identity.Identity) func() {
+	key := strings.ToLower(providerID.Address)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.providers[key] = providerID
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.providers, key)
+	}
+}
+
+func (s *echoServer) provider(address string) (identity.Identity, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	providerID, ok := s.providers[strings.ToLower(address)]
+	return providerID, ok
+}
+
+// serve replies to echo requests until the connection is closed.
+func (s *echoServer) serve() {
+	packet := make([]byte, maxEchoPacketSize)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(packet)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Debug().Err(err).Msg("Could not read echo request")
+			continue
+		}
+
+		if !s.allow(addr.IP, time.Now()) {
+			continue
+		}
+
+		reply, err := s.reply(packet[:n], addr)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Could not reply to echo request from %s", addr)
+			continue
+		}
+		if _, err := s.conn.WriteToUDP(reply, addr); err != nil {
+			log.Debug().Err(err).Msgf("Could not send echo reply to %s", addr)
+		}
+	}
+}
+
+// allow checks whether the echo request of the source is within its rate, so that requests can not be used
+// to exhaust provider CPU with signatures or to reflect traffic.
+func (s *echoServer) allow(ip net.IP, now time.Time) bool {
+	s.limiterMu.Lock()
+	defer s.limiterMu.Unlock()
+
+	key := ip.String()
+	bucket, ok := s.sources[key]
+	if !ok {
+		if len(s.sources) >= maxEchoSources {
+			s.pruneSources(now)
+			if len(s.sources) >= maxEchoSources {
+				return false
+			}
+		}
+		bucket = &echoBucket{tokens: echoBurst, last: now}
+		s.sources[key] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.last).Seconds() * echoRate
+	if bucket.tokens > echoBurst {
+		bucket.tokens = echoBurst
+	}
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// pruneSources forgets the sources whose buckets are full again, they are in the same state as new ones.
+func (s *echoServer) pruneSources(now time.Time) {
+	for key, bucket := range s.sources {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*echoRate >= echoBurst {
+			delete(s.sources, key)
+		}
+	}
+}
+
+func (s *echoServer) reply(packet []byte, addr *net.UDPAddr) ([]byte, error) {
+	signedMsg, _, err := unpackSignedMsg(s.verifier, packet)
+	if err != nil {
+		return nil, fmt.Errorf("could not unpack signed msg: %w", err)
+	}
+	if len(signedMsg.Data) <= echoPayloadSize {
+		return nil, fmt.Errorf("echo request too short: %d bytes", len(signedMsg.Data))
+	}
+
+	address := string(signedMsg.Data[echoPayloadSize:])
+	providerID, ok := s.provider(address)
+	if !ok {
+		return nil, fmt.Errorf("echo request for unknown provider: %s", address)
+	}
+
+	return packSignedData(s.signer, providerID, echoReplyData(signedMsg.Data[:echoPayloadSize], addr.String()))
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/mapping"
+)
+
+func TestEchoPinger_Echo(t *testing.T) {
+	consumerID := identity.FromAddress("0x1")
+	signer := func(identity.Identity) identity.Signer { return &identity.SignerFake{} }
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NoError(t, err)
+	defer conn.Close()
+	server := newEchoServer(conn, signer, &mockVerifier{valid: true})
+	server.add(identity.FromAddress("0x2"))
+	remove := server.add(identity.FromAddress("0x3"))
+	go server.serve()
+	contact := ContactDefinition{EchoAddress: conn.LocalAddr().String()}
+
+	pinger := NewEchoPinger(signer, func(identity.Identity) identity.Verifier {
+		return &mockVerifier{valid: true}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	rtt, err := pinger.Echo(ctx, consumerID, identity.FromAddress("0x2"), contact)
+	assert.NoError(t, err)
+	assert.Greater(t, rtt, time.Duration(0))
+
+	_, err = pinger.Echo(ctx, consumerID, identity.FromAddress("0x2"), ContactDefinition{})
+	assert.Equal(t, ErrNoEchoAddress, err)
+
+	// Reply must be signed by the provider.
+	pinger.verifierFactory = func(identity.Identity) identity.Verifier {
+		return &mockVerifier{valid: false}
+	}
+	_, err = pinger.Echo(ctx, consumerID, identity.FromAddress("0x3"), contact)
+	assert.EqualError(t, err, "could not unpack echo reply: message signature is invalid")
+
+	// Provider which stopped listening does not reply.
+	remove()
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer shortCancel()
+	_, err = pinger.Echo(shortCtx, consumerID, identity.FromAddress("0x3"), contact)
+	assert.Error(t, err)
+}
+
+func TestEchoServer_SignsOwnReply(t *testing.T) {
+	signer := func(identity.Identity) identity.Signer { return &identity.SignerFake{} }
+	server := newEchoServer(nil, signer, &mockVerifier{valid: true})
+	server.add(identity.FromAddress("0x2"))
+
+	nonce := make([]byte, echoPayloadSize)
+	request, err := packSignedData(signer, identity.FromAddress("0x1"), append(nonce, []byte("0x2")...))
+	assert.NoError(t, err)
+
+	reply, err := server.reply(request, &net.UDPAddr{IP: net.ParseIP("1.2.3.4"), Port: 1234})
+	assert.NoError(t, err)
+	signedMsg, _, err := unpackSignedMsg(&mockVerifier{valid: true}, reply)
+	assert.NoError(t, err)
+	assert.Equal(t, echoReplyPrefix+string(nonce)+"1.2.3.4:1234", string(signedMsg.Data))
+}
+
+func TestEchoServer_LimitsRatePerSource(t *testing.T) {
+	server := newEchoServer(nil, nil, nil)
+	now := time.Now()
+	first, second := net.ParseIP("1.2.3.4"), net.ParseIP("1.2.3.5")
+
+	for i := 0; i < echoBurst; i++ {
+		assert.True(t, server.allow(first, now))
+	}
+	assert.False(t, server.allow(first, now))
+	assert.True(t, server.allow(second, now))
+
+	assert.True(t, server.allow(first, now.Add(time.Second/echoRate)))
+	assert.False(t, server.allow(first, now.Add(time.Second/echoRate)))
+}
+
+func TestListener_EchoStopsWithLastProvider(t *testing.T) {
+	m := &listener{
+		ipResolver: ip.NewResolverMock("127.0.0.1"),
+		portMapper: mapping.NewNoopPortMapper(eventbus.New()),
+		echoPort:   freeUDPPort(t),
+	}
+
+	removeFirst := m.addEcho(identity.FromAddress("0x1"))
+	removeSecond := m.addEcho(identity.FromAddress("0x2"))
+	address := m.startEcho()
+	assert.Equal(t, net.JoinHostPort("127.0.0.1", strconv.Itoa(m.echoPort)), address)
+	conn := m.echo.conn
+
+	removeFirst()
+	assert.NotNil(t, m.echo)
+
+	removeSecond()
+	assert.Nil(t, m.echo)
+	_, err := conn.Write([]byte("ping"))
+	assert.ErrorIs(t, err, net.ErrClosed)
+
+	// Restarted server keeps the advertised address.
+	defer m.addEcho(identity.FromAddress("0x1"))()
+	assert.Equal(t, address, m.startEcho())
+}
+
+func TestListener_EchoNotAdvertisedBehindNATWithoutMapping(t *testing.T) {
+	m := &listener{
+		ipResolver: ip.NewResolverMockMultiple("192.168.1.2", "1.2.3.4"),
+		portMapper: mapping.NewNoopPortMapper(eventbus.New()),
+		echoPort:   freeUDPPort(t),
+	}
+
+	assert.Empty(t, m.startEcho())
+	assert.Nil(t, m.echo)
+}
+
+func freeUDPPort(t *testing.T) int {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NoError(t, err)
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+type mockVerifier struct {
+	valid bool
+}
+
+func (m *mockVerifier) Verify(message []byte, signature identity.Signature) (bool, identity.Identity) {
+	return m.valid, identity.FromAddress("0x1")
+}
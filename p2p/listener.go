@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
 	"sync"
 	"time"
 
@@ -29,10 +30,13 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/mysteriumnetwork/node/p2p/compat"
 	"github.com/mysteriumnetwork/node/p2p/nat"
@@ -60,6 +64,7 @@ func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, veri
 		signer:         signer,
 		verifier:       verifier,
 		eventBus:       eventBus,
+		portMapper:     mapping.NewPortMapper(mapping.DefaultConfig(), eventbus.New()),
 	}
 }
 
@@ -70,11 +75,21 @@ type listener struct {
 	signer     identity.SignerFactory
 	verifier   identity.Verifier
 	ipResolver ip.Resolver
+	portMapper mapping.PortMapper
 
 	// Keys holds pendingConfigs temporary configs for provider side since it
 	// need to handle key exchange in two steps.
 	pendingConfigs   map[PublicKey]p2pConnectConfig
 	pendingConfigsMu sync.Mutex
+
+	// echo server runs while at least one provider listens, its port is kept between restarts
+	// so that the address advertised in proposals stays valid.
+	echoMu      sync.Mutex
+	echo        *echoServer
+	echoUsers   int
+	echoPort    int
+	echoAddress string
+	echoRelease func()
 }
 
 type p2pConnectConfig struct {
@@ -104,7 +119,103 @@ func (c *p2pConnectConfig) peerIP() string {
 func (m *listener) GetContact() market.Contact {
 	return market.Contact{
 		Type:       ContactTypeV1,
-		Definition: ContactDefinition{BrokerAddresses: m.brokerConn.Servers()},
+		Definition: ContactDefinition{BrokerAddresses: m.brokerConn.Servers(), EchoAddress: m.startEcho()},
+	}
+}
+
+// startEcho starts the echo server on a port of UDP listen range and returns its public address.
+// Address is empty if the server could not be started, consumers are not able to measure latency then.
+func (m *listener) startEcho() string {
+	m.echoMu.Lock()
+	defer m.echoMu.Unlock()
+
+	return m.startEchoLocked()
+}
+
+func (m *listener) startEchoLocked() string {
+	if m.echo != nil {
+		return m.echoAddress
+	}
+
+	publicIP, err := m.ipResolver.GetPublicIP()
+	if err != nil {
+		log.Warn().Err(err).Msg("Echo server is disabled, could not get public IP")
+		return ""
+	}
+	outboundIP, err := m.ipResolver.GetOutboundIP()
+	if err != nil {
+		log.Warn().Err(err).Msg("Echo server is disabled, could not get outbound IP")
+		return ""
+	}
+	if m.echoPort == 0 {
+		portRange, err := port.ParseRange(config.GetString(config.FlagUDPListenPorts))
+		if err != nil {
+			log.Warn().Err(err).Msg("Echo server is disabled, could not parse UDP listen port range")
+			return ""
+		}
+		echoPort, err := port.NewFixedRangePool(portRange).Acquire()
+		if err != nil {
+			log.Warn().Err(err).Msg("Echo server is disabled, could not acquire port")
+			return ""
+		}
+		m.echoPort = echoPort.Num()
+	}
+
+	// Behind NAT the echo port is reachable only if the router forwards it to us.
+	release := func() {}
+	if outboundIP != publicIP {
+		mappingRelease, ok := m.portMapper.Map("", "UDP", m.echoPort, "Myst node echo port mapping")
+		if !ok {
+			log.Warn().Msg("Echo server is disabled, could not map echo port behind NAT")
+			return ""
+		}
+		release = mappingRelease
+	}
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: m.echoPort})
+	if err != nil {
+		release()
+		log.Warn().Err(err).Msg("Echo server is disabled, could not listen on UDP port")
+		return ""
+	}
+
+	m.echo = newEchoServer(conn, m.signer, m.verifier)
+	m.echoAddress = net.JoinHostPort(publicIP, strconv.Itoa(m.echoPort))
+	m.echoRelease = release
+	go m.echo.serve()
+
+	return m.echoAddress
+}
+
+// addEcho starts replying to echo requests of the provider, echo server is stopped
+// once the last provider is removed.
+func (m *listener) addEcho(providerID identity.Identity) func() {
+	m.echoMu.Lock()
+	defer m.echoMu.Unlock()
+
+	if m.startEchoLocked() == "" {
+		return func() {}
+	}
+	remove := m.echo.add(providerID)
+	m.echoUsers++
+
+	return func() {
+		m.echoMu.Lock()
+		defer m.echoMu.Unlock()
+
+		remove()
+		m.echoUsers--
+		if m.echoUsers > 0 {
+			return
+		}
+
+		if err := m.echo.conn.Close(); err != nil {
+			log.Warn().Err(err).Msg("Could not close echo server connection")
+		}
+		m.echoRelease()
+		m.echo = nil
+		m.echoAddress = ""
+		m.echoRelease = nil
 	}
 }
 
@@ -223,6 +334,8 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		return func() {}, fmt.Errorf("could not get subscribe to config exchange acknowledge topic: %w", err)
 	}
 
+	removeEcho := m.addEcho(providerID)
+
 	return func() {
 		if err := configSub.Unsubscribe(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange topic")
@@ -230,6 +343,7 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		if err := ackSub.Unsubscribe(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange acknowledge topic")
 		}
+		removeEcho()
 	}, nil
 }
 
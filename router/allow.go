@@ -39,6 +39,10 @@ var (
 type Manager interface {
 	ExcludeIP(net.IP) error
 	RemoveExcludedIP(net.IP) error
+	ExcludeNet(net.IPNet) error
+	RemoveExcludedNet(net.IPNet) error
+	ExcludeApp(cgroup string) error
+	RemoveExcludedApp(cgroup string) error
 	Clean() error
 }
 
@@ -151,3 +155,31 @@ func RemoveExcludedIP(ip net.IP) error {
 
 	return nil
 }
+
+// ExcludeNet adds network based exception to route traffic directly.
+func ExcludeNet(ipNet net.IPNet) error {
+	ensureRouterStarted()
+
+	return DefaultRouter.ExcludeNet(ipNet)
+}
+
+// RemoveExcludedNet removes network based exception to route traffic directly.
+func RemoveExcludedNet(ipNet net.IPNet) error {
+	ensureRouterStarted()
+
+	return DefaultRouter.RemoveExcludedNet(ipNet)
+}
+
+// ExcludeApp adds exception to route traffic of applications in the given cgroup directly.
+func ExcludeApp(cgroup string) error {
+	ensureRouterStarted()
+
+	return DefaultRouter.ExcludeApp(cgroup)
+}
+
+// RemoveExcludedApp removes exception to route traffic of applications in the given cgroup directly.
+func RemoveExcludedApp(cgroup string) error {
+	ensureRouterStarted()
+
+	return DefaultRouter.RemoveExcludedApp(cgroup)
+}
@@ -44,3 +44,23 @@ func (t *RoutingTable) ExcludeRule(ip, gw net.IP) error {
 func (t *RoutingTable) DeleteRule(ip, gw net.IP) error {
 	return nil
 }
+
+// ExcludeNetRule adds a network to be excluded from the main tunnelled traffic.
+func (t *RoutingTable) ExcludeNetRule(ipNet net.IPNet, gw net.IP) error {
+	return nil
+}
+
+// DeleteNetRule removes excluded network routing table rule.
+func (t *RoutingTable) DeleteNetRule(ipNet net.IPNet, gw net.IP) error {
+	return nil
+}
+
+// ExcludeAppRule adds applications of the cgroup to be excluded from the main tunnelled traffic.
+func (t *RoutingTable) ExcludeAppRule(cgroup string, gw net.IP) error {
+	return nil
+}
+
+// DeleteAppRule removes excluded applications routing rule.
+func (t *RoutingTable) DeleteAppRule(cgroup string, gw net.IP) error {
+	return nil
+}
@@ -46,3 +46,26 @@ func (t *RoutingTable) ExcludeRule(ip, gw net.IP) error {
 func (t *RoutingTable) DeleteRule(ip, gw net.IP) error {
 	return cmdutil.SudoExec("route", "delete", ip.String(), gw.String())
 }
+
+// ExcludeNetRule adds a network to be excluded from the main tunnelled traffic.
+// Traffic sent to the network will be directed to the system default gateway
+// instead of tunnel.
+func (t *RoutingTable) ExcludeNetRule(ipNet net.IPNet, gw net.IP) error {
+	return cmdutil.SudoExec("route", "add", "-net", ipNet.String(), gw.String())
+}
+
+// DeleteNetRule removes excluded network routing table rule to return it back to routing
+// thought the tunnel.
+func (t *RoutingTable) DeleteNetRule(ipNet net.IPNet, gw net.IP) error {
+	return cmdutil.SudoExec("route", "delete", "-net", ipNet.String(), gw.String())
+}
+
+// ExcludeAppRule is not supported on this platform.
+func (t *RoutingTable) ExcludeAppRule(cgroup string, gw net.IP) error {
+	return errAppRuleUnsupported
+}
+
+// DeleteAppRule is not supported on this platform.
+func (t *RoutingTable) DeleteAppRule(cgroup string, gw net.IP) error {
+	return errAppRuleUnsupported
+}
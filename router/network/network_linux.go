@@ -21,12 +21,20 @@ package network
 
 import (
 	"net"
+	"strings"
 
 	"github.com/jackpal/gateway"
 
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
 )
 
+const (
+	// appBypassMark marks packets of excluded applications.
+	appBypassMark = "0x6d79"
+	// appBypassTable is a routing table directing marked packets to the system default gateway.
+	appBypassTable = "28025"
+)
+
 // RoutingTable implements a set of platform specific tool for creating, deleting
 // and observe routing tables rules for a different needs.
 type RoutingTable struct{}
@@ -48,3 +56,72 @@ func (t *RoutingTable) ExcludeRule(ip, gw net.IP) error {
 func (t *RoutingTable) DeleteRule(ip, gw net.IP) error {
 	return cmdutil.SudoExec("ip", "route", "delete", ip.String(), "via", gw.String())
 }
+
+// ExcludeNetRule adds a network to be excluded from the main tunnelled traffic.
+// Traffic sent to the network will be directed to the system default gateway
+// instead of tunnel.
+func (t *RoutingTable) ExcludeNetRule(ipNet net.IPNet, gw net.IP) error {
+	return cmdutil.SudoExec("ip", "route", "add", ipNet.String(), "via", gw.String())
+}
+
+// DeleteNetRule removes excluded network routing table rule to return it back to routing
+// thought the tunnel.
+func (t *RoutingTable) DeleteNetRule(ipNet net.IPNet, gw net.IP) error {
+	return cmdutil.SudoExec("ip", "route", "delete", ipNet.String(), "via", gw.String())
+}
+
+// ExcludeAppRule excludes applications of the given cgroup (v2 path) from the main tunnelled traffic.
+// Packets of the cgroup are marked and routed through the separate table pointing to the system default gateway.
+func (t *RoutingTable) ExcludeAppRule(cgroup string, gw net.IP) error {
+	if err := cmdutil.SudoExec("ip", "route", "replace", "default", "via", gw.String(), "table", appBypassTable); err != nil {
+		return err
+	}
+
+	if !ipRuleExists() {
+		if err := cmdutil.SudoExec("ip", "rule", "add", "fwmark", appBypassMark, "lookup", appBypassTable); err != nil {
+			return err
+		}
+	}
+
+	masquerade := []string{"POSTROUTING", "-t", "nat", "-m", "mark", "--mark", appBypassMark, "-j", "MASQUERADE"}
+	if err := cmdutil.SudoExec(append([]string{"iptables", "-C"}, masquerade...)...); err != nil {
+		if err := cmdutil.SudoExec(append([]string{"iptables", "-A"}, masquerade...)...); err != nil {
+			return err
+		}
+	}
+
+	return cmdutil.SudoExec(append([]string{"iptables", "-A"}, markRule(cgroup)...)...)
+}
+
+// DeleteAppRule removes applications of the given cgroup from excluded traffic.
+// Shared routing rules are removed together with the last excluded cgroup.
+func (t *RoutingTable) DeleteAppRule(cgroup string, gw net.IP) error {
+	if err := cmdutil.SudoExec(append([]string{"iptables", "-D"}, markRule(cgroup)...)...); err != nil {
+		return err
+	}
+
+	out, err := cmdutil.ExecOutput("sudo", "iptables", "-t", "mangle", "-S", "OUTPUT")
+	if err != nil {
+		return err
+	}
+	if strings.Contains(out, appBypassMark) {
+		return nil
+	}
+
+	if err := cmdutil.SudoExec("iptables", "-t", "nat", "-D", "POSTROUTING", "-m", "mark", "--mark", appBypassMark, "-j", "MASQUERADE"); err != nil {
+		return err
+	}
+	if err := cmdutil.SudoExec("ip", "rule", "delete", "fwmark", appBypassMark, "lookup", appBypassTable); err != nil {
+		return err
+	}
+	return cmdutil.SudoExec("ip", "route", "flush", "table", appBypassTable)
+}
+
+func markRule(cgroup string) []string {
+	return []string{"OUTPUT", "-t", "mangle", "-m", "cgroup", "--path", cgroup, "-j", "MARK", "--set-mark", appBypassMark}
+}
+
+func ipRuleExists() bool {
+	out, err := cmdutil.ExecOutput("ip", "rule", "list", "fwmark", appBypassMark)
+	return err == nil && strings.Contains(out, appBypassTable)
+}
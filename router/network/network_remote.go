@@ -60,3 +60,43 @@ func (t *RoutingTableRemote) DeleteRule(ip, gw net.IP) error {
 
 	return nil
 }
+
+// ExcludeNetRule adds a network to be excluded from the main tunnelled traffic.
+func (t *RoutingTableRemote) ExcludeNetRule(ipNet net.IPNet, gw net.IP) error {
+	_, err := client.Command("exclude-route", "-ip", ipNet.String(), "-gw", gw.String())
+	if err != nil {
+		return fmt.Errorf("failed to exclude network route via supervisor: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteNetRule removes excluded network routing table rule.
+func (t *RoutingTableRemote) DeleteNetRule(ipNet net.IPNet, gw net.IP) error {
+	_, err := client.Command("delete-route", "-ip", ipNet.String(), "-gw", gw.String())
+	if err != nil {
+		return fmt.Errorf("failed to delete network route via supervisor: %w", err)
+	}
+
+	return nil
+}
+
+// ExcludeAppRule adds applications of the cgroup to be excluded from the main tunnelled traffic.
+func (t *RoutingTableRemote) ExcludeAppRule(cgroup string, gw net.IP) error {
+	_, err := client.Command("exclude-app", "-cgroup", cgroup, "-gw", gw.String())
+	if err != nil {
+		return fmt.Errorf("failed to exclude application via supervisor: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAppRule removes excluded applications routing rule.
+func (t *RoutingTableRemote) DeleteAppRule(cgroup string, gw net.IP) error {
+	_, err := client.Command("delete-app", "-cgroup", cgroup, "-gw", gw.String())
+	if err != nil {
+		return fmt.Errorf("failed to delete excluded application via supervisor: %w", err)
+	}
+
+	return nil
+}
@@ -54,3 +54,36 @@ func (t *RoutingTable) DeleteRule(ip, gw net.IP) error {
 
 	return nil
 }
+
+// ExcludeNetRule adds a network to be excluded from the main tunnelled traffic.
+// Traffic sent to the network will be directed to the system default gateway
+// instead of tunnel.
+func (t *RoutingTable) ExcludeNetRule(ipNet net.IPNet, gw net.IP) error {
+	out, err := exec.Command("powershell", "-Command", "route add "+ipNet.String()+" "+gw.String()).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to add route: %w, %s", err, string(out))
+	}
+
+	return nil
+}
+
+// DeleteNetRule removes excluded network routing table rule to return it back to routing
+// thought the tunnel.
+func (t *RoutingTable) DeleteNetRule(ipNet net.IPNet, gw net.IP) error {
+	out, err := exec.Command("powershell", "-Command", "route delete "+ipNet.String()).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to delete route: %w, %s", err, string(out))
+	}
+
+	return nil
+}
+
+// ExcludeAppRule is not supported on this platform.
+func (t *RoutingTable) ExcludeAppRule(cgroup string, gw net.IP) error {
+	return errAppRuleUnsupported
+}
+
+// DeleteAppRule is not supported on this platform.
+func (t *RoutingTable) DeleteAppRule(cgroup string, gw net.IP) error {
+	return errAppRuleUnsupported
+}
@@ -50,13 +50,30 @@ type router interface {
 	DiscoverGateway() (net.IP, error)
 	ExcludeRule(ip, gw net.IP) error
 	DeleteRule(ip, gw net.IP) error
+	ExcludeNetRule(ipNet net.IPNet, gw net.IP) error
+	DeleteNetRule(ipNet net.IPNet, gw net.IP) error
+	ExcludeAppRule(cgroup string, gw net.IP) error
+	DeleteAppRule(cgroup string, gw net.IP) error
 }
 
+// rule excludes either a single IP, a network or an application from the tunnel.
 type rule struct {
 	ip    net.IP
+	ipNet *net.IPNet
+	app   string
 	usage int
 }
 
+func (r rule) equal(other rule) bool {
+	if r.ipNet != nil || other.ipNet != nil {
+		return r.ipNet != nil && other.ipNet != nil && r.ipNet.String() == other.ipNet.String()
+	}
+	if r.app != "" || other.app != "" {
+		return r.app == other.app
+	}
+	return r.ip.Equal(other.ip)
+}
+
 // NewManager creates a new instance of service that maintain routing table to match current state.
 func NewManager() *manager {
 	var r router = &network.RoutingTable{}
@@ -74,6 +91,30 @@ func NewManager() *manager {
 }
 
 func (m *manager) ExcludeIP(ip net.IP) error {
+	return m.exclude(rule{ip: ip})
+}
+
+func (m *manager) RemoveExcludedIP(ip net.IP) error {
+	return m.removeExcluded(rule{ip: ip})
+}
+
+func (m *manager) ExcludeNet(ipNet net.IPNet) error {
+	return m.exclude(rule{ipNet: &ipNet})
+}
+
+func (m *manager) RemoveExcludedNet(ipNet net.IPNet) error {
+	return m.removeExcluded(rule{ipNet: &ipNet})
+}
+
+func (m *manager) ExcludeApp(cgroup string) error {
+	return m.exclude(rule{app: cgroup})
+}
+
+func (m *manager) RemoveExcludedApp(cgroup string) error {
+	return m.removeExcluded(rule{app: cgroup})
+}
+
+func (m *manager) exclude(r rule) error {
 	m.ensureStarted()
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -81,7 +122,7 @@ func (m *manager) ExcludeIP(ip net.IP) error {
 	new := true
 
 	for i, rule := range m.rules {
-		if !rule.ip.Equal(ip) {
+		if !rule.equal(r) {
 			continue
 		}
 
@@ -95,24 +136,22 @@ func (m *manager) ExcludeIP(ip net.IP) error {
 		return nil
 	}
 
-	if err := m.routingTable.ExcludeRule(ip, m.currentGW); err != nil {
+	if err := m.excludeRule(r, m.currentGW); err != nil {
 		return fmt.Errorf("failed to exclude rule: %w", err)
 	}
 
-	m.rules = append(m.rules, rule{
-		ip:    ip,
-		usage: 1,
-	})
+	r.usage = 1
+	m.rules = append(m.rules, r)
 
 	return nil
 }
 
-func (m *manager) RemoveExcludedIP(ip net.IP) error {
+func (m *manager) removeExcluded(r rule) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	for i, rule := range m.rules {
-		if !rule.ip.Equal(ip) {
+		if !rule.equal(r) {
 			continue
 		}
 
@@ -121,7 +160,7 @@ func (m *manager) RemoveExcludedIP(ip net.IP) error {
 		if m.rules[i].usage == 0 {
 			m.rules = append(m.rules[:i], m.rules[i+1:]...)
 
-			if err := m.routingTable.DeleteRule(ip, m.currentGW); err != nil {
+			if err := m.deleteRule(rule, m.currentGW); err != nil {
 				return fmt.Errorf("failed to remove excluded rule: %w", err)
 			}
 		}
@@ -132,6 +171,28 @@ func (m *manager) RemoveExcludedIP(ip net.IP) error {
 	return nil
 }
 
+func (m *manager) excludeRule(r rule, gw net.IP) error {
+	switch {
+	case r.ipNet != nil:
+		return m.routingTable.ExcludeNetRule(*r.ipNet, gw)
+	case r.app != "":
+		return m.routingTable.ExcludeAppRule(r.app, gw)
+	default:
+		return m.routingTable.ExcludeRule(r.ip, gw)
+	}
+}
+
+func (m *manager) deleteRule(r rule, gw net.IP) error {
+	switch {
+	case r.ipNet != nil:
+		return m.routingTable.DeleteNetRule(*r.ipNet, gw)
+	case r.app != "":
+		return m.routingTable.DeleteAppRule(r.app, gw)
+	default:
+		return m.routingTable.DeleteRule(r.ip, gw)
+	}
+}
+
 func (m *manager) ensureStarted() {
 	m.once.Do(func() {
 		m.forceCheckGW()
@@ -176,7 +237,7 @@ func (m *manager) Clean() (lastErr error) {
 
 func (m *manager) clean() (lastErr error) {
 	for _, rule := range m.rules {
-		err := m.routingTable.DeleteRule(rule.ip, m.currentGW)
+		err := m.deleteRule(rule, m.currentGW)
 		if err != nil {
 			lastErr = err
 			log.Error().Err(err).Msgf("Failed to delete route: %+v", rule)
@@ -188,7 +249,7 @@ func (m *manager) clean() (lastErr error) {
 
 func (m *manager) apply(gw net.IP) (lastErr error) {
 	for _, rule := range m.rules {
-		err := m.routingTable.ExcludeRule(rule.ip, gw)
+		err := m.excludeRule(rule, gw)
 		if err != nil {
 			lastErr = err
 			log.Error().Err(err).Msgf("Failed to delete route: %+v", rule)
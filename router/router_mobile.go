@@ -38,6 +38,22 @@ func (m *manager) RemoveExcludedIP(ip net.IP) error {
 	return nil
 }
 
+func (m *manager) ExcludeNet(ipNet net.IPNet) error {
+	return nil
+}
+
+func (m *manager) RemoveExcludedNet(ipNet net.IPNet) error {
+	return nil
+}
+
+func (m *manager) ExcludeApp(cgroup string) error {
+	return nil
+}
+
+func (m *manager) RemoveExcludedApp(cgroup string) error {
+	return nil
+}
+
 func (m *manager) Stop() {}
 
 func (m *manager) Clean() error {
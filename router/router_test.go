@@ -157,6 +157,34 @@ func Test_router_ReplaceGW(t *testing.T) {
 	assert.Len(t, table.rules, 2)
 }
 
+func Test_router_ExcludeNetAndApp(t *testing.T) {
+	table := &mockRoutingTable{gw: net.ParseIP("1.1.1.1")}
+	r := &manager{
+		stop:         make(chan struct{}),
+		routingTable: table,
+	}
+
+	_, corporate, _ := net.ParseCIDR("10.0.0.0/8")
+	assert.NoError(t, r.ExcludeNet(*corporate))
+	assert.NoError(t, r.ExcludeNet(*corporate))
+	assert.NoError(t, r.ExcludeIP(net.ParseIP("10.0.0.0")))
+	assert.NoError(t, r.ExcludeApp("user.slice/browser.scope"))
+
+	assert.Equal(t, 1, table.rules["10.0.0.0/8:1.1.1.1"])
+	assert.Equal(t, 1, table.rules["10.0.0.0:1.1.1.1"])
+	assert.Equal(t, 1, table.rules["user.slice/browser.scope:1.1.1.1"])
+	assert.Len(t, table.rules, 3)
+
+	assert.NoError(t, r.RemoveExcludedNet(*corporate))
+	assert.Contains(t, table.rules, "10.0.0.0/8:1.1.1.1")
+
+	assert.NoError(t, r.RemoveExcludedNet(*corporate))
+	assert.NoError(t, r.RemoveExcludedApp("user.slice/browser.scope"))
+	assert.NotContains(t, table.rules, "10.0.0.0/8:1.1.1.1")
+	assert.NotContains(t, table.rules, "user.slice/browser.scope:1.1.1.1")
+	assert.Len(t, table.rules, 1)
+}
+
 type mockRoutingTable struct {
 	rules map[string]int
 	gw    net.IP
@@ -198,6 +226,50 @@ func (t *mockRoutingTable) DeleteRule(ip, gw net.IP) error {
 	return nil
 }
 
+func (t *mockRoutingTable) ExcludeNetRule(ipNet net.IPNet, gw net.IP) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.rules == nil {
+		t.rules = make(map[string]int)
+	}
+
+	t.rules[fmt.Sprintf("%s:%s", ipNet.String(), gw)]++
+
+	return nil
+}
+
+func (t *mockRoutingTable) DeleteNetRule(ipNet net.IPNet, gw net.IP) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.rules, fmt.Sprintf("%s:%s", ipNet.String(), gw))
+
+	return nil
+}
+
+func (t *mockRoutingTable) ExcludeAppRule(cgroup string, gw net.IP) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.rules == nil {
+		t.rules = make(map[string]int)
+	}
+
+	t.rules[fmt.Sprintf("%s:%s", cgroup, gw)]++
+
+	return nil
+}
+
+func (t *mockRoutingTable) DeleteAppRule(cgroup string, gw net.IP) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.rules, fmt.Sprintf("%s:%s", cgroup, gw))
+
+	return nil
+}
+
 func (t *mockRoutingTable) DiscoverGateway() (net.IP, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
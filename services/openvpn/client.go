@@ -92,10 +92,12 @@ type Client struct {
 	processFactory      processFactory
 	ipResolver          ip.Resolver
 	removeAllowedIPRule func()
+	dnsServers          []string
+	providerDNS         []string
 	stopOnce            sync.Once
 }
 
-var _ connection.Connection = &Client{}
+var _ connection.DNSConnection = &Client{}
 
 // State returns connection state channel.
 func (c *Client) State() <-chan connectionstate.State {
@@ -124,6 +126,12 @@ func (c *Client) Start(ctx context.Context, options connection.ConnectOptions) e
 		return errors.Wrap(err, "failed to unmarshal session config")
 	}
 
+	c.dnsServers, err = options.Params.DNS.ResolveIPs(sessionConfig.DNSIPs)
+	if err != nil {
+		return errors.Wrap(err, "could not resolve DNS IPs")
+	}
+	c.providerDNS, _ = connection.SelectProviderDNS(sessionConfig.DNSIPs)
+
 	c.removeAllowedIPRule, err = firewall.AllowIPAccess(sessionConfig.RemoteIP)
 	if err != nil {
 		return errors.Wrap(err, "failed to add allowed IP address")
@@ -144,6 +152,16 @@ func (c *Client) Start(ctx context.Context, options connection.ConnectOptions) e
 	return errors.Wrap(err, "failed to start client process")
 }
 
+// DNSServers returns DNS servers configured by the connection.
+func (c *Client) DNSServers() []string {
+	return c.dnsServers
+}
+
+// ProviderDNSServers returns DNS servers offered by the provider.
+func (c *Client) ProviderDNSServers() []string {
+	return c.providerDNS
+}
+
 // Stop stops the connection
 func (c *Client) Stop() {
 	c.stopOnce.Do(func() {
@@ -81,10 +81,15 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 	}
 
 	dnsPort := 11153
-	dnsHandler, err := dns.ResolveViaSystem()
+	dnsHandler, err := dns.NewResolver(dns.ResolverOptions{
+		Upstreams: config.GetStringSlice(config.FlagDNSUpstreams),
+		CacheSize: config.GetInt(config.FlagDNSCacheSize),
+	})
 	if err == nil {
-		if instance.Policies().HasDNSRules() {
+		if instance.Policies().HasDNSRules() || instance.Policies().HasDNSDenyRules() {
 			dnsHandler = dns.WhitelistAnswers(dnsHandler, m.trafficFirewall, instance.Policies())
+		}
+		if instance.Policies().HasDNSRules() {
 			removeRule, err := m.trafficFirewall.BlockIncomingTraffic(m.vpnNetwork)
 			if err != nil {
 				return fmt.Errorf("failed to enable traffic blocking: %w", err)
@@ -107,6 +112,18 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 		log.Warn().Err(err).Msg("Provider DNS will not be available")
 	}
 
+	if allow, deny := instance.Policies().DestinationRules(); len(allow) > 0 || len(deny) > 0 {
+		removeRule, err := m.trafficFirewall.RestrictDestinations(m.vpnNetwork, allow, deny)
+		if err != nil {
+			return fmt.Errorf("failed to restrict traffic destinations: %w", err)
+		}
+		defer func() {
+			if err := removeRule(); err != nil {
+				log.Warn().Err(err).Msg("failed to remove traffic destinations restriction")
+			}
+		}()
+	}
+
 	servicePort, err := m.ports.Acquire()
 	if err != nil {
 		return fmt.Errorf("failed to acquire an unused port: %w", err)
@@ -24,6 +24,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/services/noop"
 	"github.com/mysteriumnetwork/node/services/openvpn"
+	"github.com/mysteriumnetwork/node/services/proxy"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/urfave/cli/v2"
 )
@@ -45,7 +46,10 @@ func GetStartOptions(serviceType string) (opts StartOptions, err error) {
 		opts.AccessPolicyList = getPolicies(config.FlagWireguardAccessPolicies, config.FlagAccessPolicyList)
 	case noop.ServiceType:
 		opts.AccessPolicyList = getPolicies(config.FlagNoopAccessPolicies, config.FlagAccessPolicyList)
+	case proxy.ServiceType:
+		opts.AccessPolicyList = getPolicies(config.FlagProxyAccessPolicies, config.FlagAccessPolicyList)
 	}
+	opts.Capacity = getCapacity()
 	return opts, nil
 }
 
@@ -64,8 +68,20 @@ func getPolicies(flag cli.StringFlag, fallback cli.StringFlag) []string {
 	return policies
 }
 
+func getCapacity() service.Capacity {
+	return service.Capacity{
+		MaxSessions:         config.GetInt(config.FlagServiceMaxSessions),
+		MaxConsumerSessions: config.GetInt(config.FlagServiceMaxConsumerSessions),
+		MinFreeCPU:          config.GetFloat64(config.FlagServiceMinFreeCPU),
+		MinFreeMemory:       config.GetUInt64(config.FlagServiceMinFreeMemory),
+		Bandwidth:           config.GetUInt64(config.FlagServiceBandwidth),
+		MinFreeBandwidth:    config.GetUInt64(config.FlagServiceMinFreeBandwidth),
+	}
+}
+
 // StartOptions describes options shared among multiple services
 type StartOptions struct {
 	AccessPolicyList []string
+	Capacity         service.Capacity
 	TypeOptions      service.Options
 }
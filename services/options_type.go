@@ -24,6 +24,8 @@ import (
 	"github.com/mysteriumnetwork/node/services/noop"
 	"github.com/mysteriumnetwork/node/services/openvpn"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn/service"
+	"github.com/mysteriumnetwork/node/services/proxy"
+	proxy_service "github.com/mysteriumnetwork/node/services/proxy/service"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
 	"github.com/pkg/errors"
@@ -35,6 +37,7 @@ var (
 		noop.ServiceType:      noop.ParseJSONOptions,
 		openvpn.ServiceType:   openvpn_service.ParseJSONOptions,
 		wireguard.ServiceType: wireguard_service.ParseJSONOptions,
+		proxy.ServiceType:     proxy_service.ParseJSONOptions,
 	}
 )
 
@@ -43,7 +46,7 @@ type ServiceOptionsParser func(*json.RawMessage) (service.Options, error)
 
 // Types returns all possible service types.
 func Types() []string {
-	return []string{openvpn.ServiceType, wireguard.ServiceType, noop.ServiceType}
+	return []string{openvpn.ServiceType, wireguard.ServiceType, noop.ServiceType, proxy.ServiceType}
 }
 
 // TypeConfiguredOptions returns specific service options.
@@ -55,6 +58,8 @@ func TypeConfiguredOptions(serviceType string) (service.Options, error) {
 		return wireguard_service.GetOptions(), nil
 	case noop.ServiceType:
 		return noop.GetOptions(), nil
+	case proxy.ServiceType:
+		return proxy_service.GetOptions(), nil
 	default:
 		return nil, errors.Errorf("unknown service type: %q", serviceType)
 	}
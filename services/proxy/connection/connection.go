@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/services/proxy"
+	"github.com/mysteriumnetwork/node/services/proxy/tunnel"
+)
+
+// Options represents connection options.
+type Options struct {
+	// Address is the local address accepting SOCKS5 and HTTP CONNECT proxy requests.
+	Address string
+	// Username and Password are required from the local proxy clients, if set.
+	Username string
+	Password string
+}
+
+// NewConnection returns new proxy connection.
+func NewConnection(opts Options) (connection.Connection, error) {
+	return &Connection{
+		stateCh: make(chan connectionstate.State, 100),
+		opts:    opts,
+	}, nil
+}
+
+// Connection exposes a local proxy which forwards the traffic through the provider.
+type Connection struct {
+	stopOnce sync.Once
+	stateCh  chan connectionstate.State
+	opts     Options
+
+	removeAllowedIPRule firewall.OutgoingRuleRemove
+	tunnel              *tunnel.Tunnel
+	server              *server
+}
+
+var _ connection.ProxyConnection = &Connection{}
+
+// State returns connection state channel.
+func (c *Connection) State() <-chan connectionstate.State {
+	return c.stateCh
+}
+
+// Statistics returns connection statistics channel.
+func (c *Connection) Statistics() (connectionstate.Statistics, error) {
+	if c.tunnel == nil {
+		return connectionstate.Statistics{}, errors.New("proxy tunnel is not started")
+	}
+
+	sent, received := c.tunnel.Stats()
+	return connectionstate.Statistics{
+		At:            time.Now(),
+		BytesSent:     sent,
+		BytesReceived: received,
+	}, nil
+}
+
+// ProxyAddress returns the address of the local proxy.
+func (c *Connection) ProxyAddress() string {
+	if c.server == nil {
+		return ""
+	}
+	return c.server.listener.Addr().String()
+}
+
+// Start starts the tunnel to the provider and the local proxy.
+func (c *Connection) Start(ctx context.Context, options connection.ConnectOptions) (err error) {
+	var config proxy.ServiceConfig
+	if err = json.Unmarshal(options.SessionConfig, &config); err != nil {
+		return errors.Wrap(err, "failed to unmarshal connection config")
+	}
+	if options.ProviderNATConn == nil {
+		return errors.New("proxy connection requires p2p service connection")
+	}
+
+	c.stateCh <- connectionstate.Connecting
+
+	defer func() {
+		if err != nil {
+			c.Stop()
+		}
+	}()
+
+	providerAddr := options.ProviderNATConn.RemoteAddr().(*net.UDPAddr)
+	c.removeAllowedIPRule, err = firewall.AllowIPAccess(providerAddr.IP.String())
+	if err != nil {
+		return errors.Wrap(err, "failed to add firewall exception for proxy remote IP")
+	}
+
+	c.tunnel, err = tunnel.Dial(options.ProviderNATConn, config.Key)
+	if err != nil {
+		return errors.Wrap(err, "could not start proxy tunnel")
+	}
+
+	listener, err := net.Listen("tcp", c.opts.Address)
+	if err != nil {
+		return errors.Wrap(err, "could not listen for local proxy requests")
+	}
+	c.server = newServer(listener, c.tunnel, c.opts.Username, c.opts.Password)
+	go c.server.serve()
+
+	log.Info().Msgf("Proxy is accepting SOCKS5 and HTTP CONNECT requests on %s", listener.Addr())
+	c.stateCh <- connectionstate.Connected
+	return nil
+}
+
+// Reconnect restarts a connection with a new options.
+func (c *Connection) Reconnect(ctx context.Context, options connection.ConnectOptions) error {
+	return fmt.Errorf("not supported")
+}
+
+// GetConfig returns the consumer configuration for session creation
+func (c *Connection) GetConfig() (connection.ConsumerConfig, error) {
+	return nil, nil
+}
+
+// Stop stops the local proxy and closes the tunnel.
+func (c *Connection) Stop() {
+	c.stopOnce.Do(func() {
+		log.Info().Msg("Stopping proxy connection")
+		c.stateCh <- connectionstate.Disconnecting
+
+		if c.server != nil {
+			if err := c.server.close(); err != nil {
+				log.Warn().Err(err).Msg("Failed to close local proxy listener")
+			}
+		}
+
+		if c.tunnel != nil {
+			if err := c.tunnel.Close(); err != nil {
+				log.Warn().Err(err).Msg("Failed to close proxy tunnel")
+			}
+		}
+
+		if c.removeAllowedIPRule != nil {
+			c.removeAllowedIPRule()
+		}
+
+		c.stateCh <- connectionstate.NotConnected
+		close(c.stateCh)
+	})
+}
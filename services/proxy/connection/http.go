@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/services/proxy"
+)
+
+func (s *server) handleHTTP(conn *bufferedConn) {
+	req, err := http.ReadRequest(conn.reader)
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	if req.Method != http.MethodConnect {
+		writeHTTPResponse(conn, http.StatusMethodNotAllowed, nil)
+		conn.Close()
+		return
+	}
+	if !s.httpAuthorized(req) {
+		writeHTTPResponse(conn, http.StatusProxyAuthRequired, http.Header{"Proxy-Authenticate": {`Basic realm="proxy"`}})
+		conn.Close()
+		return
+	}
+	if _, _, err := net.SplitHostPort(req.Host); err != nil {
+		writeHTTPResponse(conn, http.StatusBadRequest, nil)
+		conn.Close()
+		return
+	}
+
+	stream, code := s.open(proxy.Request{Network: proxy.NetworkTCP, Address: req.Host})
+	if code != proxy.ReplySucceeded {
+		writeHTTPResponse(conn, httpStatus(code), nil)
+		conn.Close()
+		return
+	}
+	if err := writeHTTPResponse(conn, http.StatusOK, nil); err != nil {
+		stream.Close()
+		conn.Close()
+		return
+	}
+	proxy.Pipe(conn, stream)
+}
+
+func (s *server) httpAuthorized(req *http.Request) bool {
+	if s.username == "" {
+		return true
+	}
+
+	auth := req.Header.Get("Proxy-Authorization")
+	const prefix = "Basic "
+	if !strings.HasPrefix(auth, prefix) {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
+	if err != nil {
+		return false
+	}
+	credentials := strings.SplitN(string(decoded), ":", 2)
+	return len(credentials) == 2 && s.authorized(credentials[0], credentials[1])
+}
+
+func writeHTTPResponse(w io.Writer, status int, header http.Header) error {
+	response := fmt.Sprintf("HTTP/1.1 %d %s\r\n", status, http.StatusText(status))
+	for key, values := range header {
+		for _, value := range values {
+			response += fmt.Sprintf("%s: %s\r\n", key, value)
+		}
+	}
+	_, err := io.WriteString(w, response+"\r\n")
+	return err
+}
+
+func httpStatus(code byte) int {
+	if code == proxy.ReplyNotAllowed {
+		return http.StatusForbidden
+	}
+	return http.StatusBadGateway
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"net"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/services/proxy"
+	"github.com/mysteriumnetwork/node/services/proxy/mux"
+)
+
+type streamOpener interface {
+	Open() (*mux.Stream, error)
+}
+
+// server is the local SOCKS5 and HTTP CONNECT proxy forwarding connections through the tunnel.
+type server struct {
+	listener net.Listener
+	tunnel   streamOpener
+	username string
+	password string
+}
+
+func newServer(listener net.Listener, tunnel streamOpener, username, password string) *server {
+	return &server{
+		listener: listener,
+		tunnel:   tunnel,
+		username: username,
+		password: password,
+	}
+}
+
+func (s *server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *server) close() error {
+	return s.listener.Close()
+}
+
+func (s *server) handle(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	version, err := reader.Peek(1)
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	buffered := &bufferedConn{Conn: conn, reader: reader}
+	if version[0] == socks5Version {
+		s.handleSOCKS(buffered)
+	} else {
+		s.handleHTTP(buffered)
+	}
+}
+
+// open opens the tunnel stream to the requested destination.
+func (s *server) open(req proxy.Request) (*mux.Stream, byte) {
+	stream, err := s.tunnel.Open()
+	if err != nil {
+		log.Error().Err(err).Msg("Could not open proxy tunnel stream")
+		return nil, proxy.ReplyGeneralFailure
+	}
+
+	if err := proxy.WriteRequest(stream, req); err != nil {
+		stream.Close()
+		return nil, proxy.ReplyGeneralFailure
+	}
+	code, err := proxy.ReadReply(stream)
+	if err != nil {
+		stream.Close()
+		return nil, proxy.ReplyGeneralFailure
+	}
+	if code != proxy.ReplySucceeded {
+		stream.Close()
+		return nil, code
+	}
+	return stream, code
+}
+
+func (s *server) authorized(username, password string) bool {
+	if s.username == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(username), []byte(s.username)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(s.password)) == 1
+}
+
+// bufferedConn is a connection whose first bytes were already peeked into the reader.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// CloseWrite closes the write side of the connection if it supports half-close, otherwise the whole connection.
+func (c *bufferedConn) CloseWrite() error {
+	if cw, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return c.Conn.Close()
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/services/proxy"
+	"github.com/mysteriumnetwork/node/services/proxy/mux"
+)
+
+// startServer starts the local proxy with the provider side echoing everything back.
+func startServer(t *testing.T, username, password string) (addr string, requests chan proxy.Request) {
+	consumerConn, providerConn := net.Pipe()
+	consumer, err := mux.Client(consumerConn)
+	require.NoError(t, err)
+	provider, err := mux.Server(providerConn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		consumer.Close()
+		provider.Close()
+	})
+
+	requests = make(chan proxy.Request, 10)
+	go func() {
+		for {
+			stream, err := provider.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer stream.Close()
+
+				req, err := proxy.ReadRequest(stream)
+				if err != nil {
+					return
+				}
+				requests <- req
+				if req.Address == "forbidden.com:80" {
+					proxy.WriteReply(stream, proxy.ReplyNotAllowed)
+					return
+				}
+				proxy.WriteReply(stream, proxy.ReplySucceeded)
+
+				if req.Network == proxy.NetworkTCP {
+					io.Copy(stream, stream)
+					return
+				}
+				for {
+					address, data, err := proxy.ReadDatagram(stream)
+					if err != nil {
+						return
+					}
+					proxy.WriteDatagram(stream, address, data)
+				}
+			}()
+		}
+	}()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := newServer(listener, consumer, username, password)
+	go srv.serve()
+	t.Cleanup(func() {
+		srv.close()
+	})
+
+	return listener.Addr().String(), requests
+}
+
+func dialSOCKS(t *testing.T, addr string, username, password string) net.Conn {
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	require.NoError(t, err)
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if username == "" {
+		_, err = conn.Write([]byte{socks5Version, 1, authNone})
+		require.NoError(t, err)
+		assertRead(t, conn, []byte{socks5Version, authNone})
+		return conn
+	}
+
+	_, err = conn.Write([]byte{socks5Version, 1, authPassword})
+	require.NoError(t, err)
+	assertRead(t, conn, []byte{socks5Version, authPassword})
+
+	auth := append([]byte{authPasswordVersion, byte(len(username))}, username...)
+	auth = append(append(auth, byte(len(password))), password...)
+	_, err = conn.Write(auth)
+	require.NoError(t, err)
+	return conn
+}
+
+func assertRead(t *testing.T, r io.Reader, expected []byte) {
+	buf := make([]byte, len(expected))
+	_, err := io.ReadFull(r, buf)
+	require.NoError(t, err)
+	assert.Equal(t, expected, buf)
+}
+
+func TestServer_SOCKSConnect(t *testing.T) {
+	addr, requests := startServer(t, "user", "secret")
+
+	// given
+	conn := dialSOCKS(t, addr, "user", "secret")
+	defer conn.Close()
+	assertRead(t, conn, []byte{authPasswordVersion, 0x00})
+
+	// when
+	_, err := conn.Write(appendSOCKSAddress([]byte{socks5Version, cmdConnect, 0x00}, "example.com", 443))
+	require.NoError(t, err)
+
+	// then
+	assertRead(t, conn, []byte{socks5Version, proxy.ReplySucceeded, 0x00, atypIPv4, 0, 0, 0, 0, 0, 0})
+	assert.Equal(t, proxy.Request{Network: proxy.NetworkTCP, Address: "example.com:443"}, <-requests)
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	assertRead(t, conn, []byte("hello"))
+}
+
+func TestServer_SOCKSRejectsInvalidCredentials(t *testing.T) {
+	addr, _ := startServer(t, "user", "secret")
+
+	conn := dialSOCKS(t, addr, "user", "wrong")
+	defer conn.Close()
+
+	assertRead(t, conn, []byte{authPasswordVersion, 0x01})
+	_, err := conn.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestServer_SOCKSConnectNotAllowed(t *testing.T) {
+	addr, _ := startServer(t, "", "")
+
+	conn := dialSOCKS(t, addr, "", "")
+	defer conn.Close()
+
+	_, err := conn.Write(appendSOCKSAddress([]byte{socks5Version, cmdConnect, 0x00}, "forbidden.com", 80))
+	require.NoError(t, err)
+	assertRead(t, conn, []byte{socks5Version, proxy.ReplyNotAllowed, 0x00, atypIPv4, 0, 0, 0, 0, 0, 0})
+}
+
+func TestServer_SOCKSUDPAssociate(t *testing.T) {
+	addr, requests := startServer(t, "", "")
+
+	// given
+	conn := dialSOCKS(t, addr, "", "")
+	defer conn.Close()
+	_, err := conn.Write(appendSOCKSAddress([]byte{socks5Version, cmdUDPAssociate, 0x00}, "0.0.0.0", 0))
+	require.NoError(t, err)
+
+	assertRead(t, conn, []byte{socks5Version, proxy.ReplySucceeded, 0x00})
+	relayAddr, err := readSOCKSAddress(conn)
+	require.NoError(t, err)
+	assert.Equal(t, proxy.Request{Network: proxy.NetworkUDP}, <-requests)
+
+	udpAddr, err := net.ResolveUDPAddr("udp", relayAddr)
+	require.NoError(t, err)
+	udpConn, err := net.DialUDP("udp", nil, udpAddr)
+	require.NoError(t, err)
+	defer udpConn.Close()
+	udpConn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// when
+	packet, err := encodeSOCKSDatagram("1.1.1.1:53", []byte("query"))
+	require.NoError(t, err)
+	_, err = udpConn.Write(packet)
+	require.NoError(t, err)
+
+	// then
+	buf := make([]byte, maxDatagramSize)
+	n, err := udpConn.Read(buf)
+	require.NoError(t, err)
+	address, data, err := decodeSOCKSDatagram(buf[:n])
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.1.1:53", address)
+	assert.Equal(t, []byte("query"), data)
+}
+
+func TestServer_HTTPConnect(t *testing.T) {
+	addr, requests := startServer(t, "user", "secret")
+
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+
+	// when
+	_, err = io.WriteString(conn, "CONNECT example.com:443 HTTP/1.1\r\nHost: example.com:443\r\n"+
+		"Proxy-Authorization: Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret"))+"\r\n\r\n")
+	require.NoError(t, err)
+
+	// then
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, proxy.Request{Network: proxy.NetworkTCP, Address: "example.com:443"}, <-requests)
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	assertRead(t, reader, []byte("hello"))
+}
+
+func TestServer_HTTPConnectRequiresCredentials(t *testing.T) {
+	addr, _ := startServer(t, "user", "secret")
+
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	_, err = io.WriteString(conn, "CONNECT example.com:443 HTTP/1.1\r\nHost: example.com:443\r\n\r\n")
+	require.NoError(t, err)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusProxyAuthRequired, resp.StatusCode)
+	assert.Equal(t, `Basic realm="proxy"`, resp.Header.Get("Proxy-Authenticate"))
+}
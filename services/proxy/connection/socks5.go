@@ -0,0 +1,322 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/services/proxy"
+)
+
+// SOCKS5 protocol constants, see RFC 1928 and RFC 1929.
+const (
+	socks5Version       = 0x05
+	authPasswordVersion = 0x01
+
+	authNone         = 0x00
+	authPassword     = 0x02
+	authNoAcceptable = 0xff
+
+	cmdConnect      = 0x01
+	cmdUDPAssociate = 0x03
+
+	atypIPv4   = 0x01
+	atypDomain = 0x03
+	atypIPv6   = 0x04
+
+	maxDatagramSize = 64 * 1024
+)
+
+var errFragmentedDatagram = errors.New("fragmented SOCKS5 datagrams are not supported")
+
+func (s *server) handleSOCKS(conn net.Conn) {
+	if err := s.socksAuthenticate(conn); err != nil {
+		log.Debug().Err(err).Msg("SOCKS5 authentication failed")
+		conn.Close()
+		return
+	}
+
+	header := make([]byte, 3)
+	if _, err := io.ReadFull(conn, header); err != nil || header[0] != socks5Version {
+		conn.Close()
+		return
+	}
+	address, err := readSOCKSAddress(conn)
+	if err != nil {
+		writeSOCKSReply(conn, proxy.ReplyGeneralFailure, nil)
+		conn.Close()
+		return
+	}
+
+	switch header[1] {
+	case cmdConnect:
+		s.socksConnect(conn, address)
+	case cmdUDPAssociate:
+		s.socksAssociate(conn)
+	default:
+		writeSOCKSReply(conn, proxy.ReplyCommandUnsupported, nil)
+		conn.Close()
+	}
+}
+
+func (s *server) socksAuthenticate(conn net.Conn) error {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return err
+	}
+
+	method := byte(authNone)
+	if s.username != "" {
+		method = authPassword
+	}
+	if bytes.IndexByte(methods, method) < 0 {
+		conn.Write([]byte{socks5Version, authNoAcceptable})
+		return errors.New("no acceptable authentication method")
+	}
+	if _, err := conn.Write([]byte{socks5Version, method}); err != nil {
+		return err
+	}
+	if method == authNone {
+		return nil
+	}
+
+	version := make([]byte, 1)
+	if _, err := io.ReadFull(conn, version); err != nil {
+		return err
+	}
+	username, err := readSOCKSString(conn)
+	if err != nil {
+		return err
+	}
+	password, err := readSOCKSString(conn)
+	if err != nil {
+		return err
+	}
+
+	if version[0] != authPasswordVersion || !s.authorized(username, password) {
+		conn.Write([]byte{authPasswordVersion, 0x01})
+		return errors.New("invalid credentials")
+	}
+	_, err = conn.Write([]byte{authPasswordVersion, 0x00})
+	return err
+}
+
+func (s *server) socksConnect(conn net.Conn, address string) {
+	stream, code := s.open(proxy.Request{Network: proxy.NetworkTCP, Address: address})
+	if err := writeSOCKSReply(conn, code, nil); err != nil || code != proxy.ReplySucceeded {
+		if stream != nil {
+			stream.Close()
+		}
+		conn.Close()
+		return
+	}
+	proxy.Pipe(conn, stream)
+}
+
+// socksAssociate relays the datagrams of the UDP association as long as the control connection is open.
+func (s *server) socksAssociate(conn net.Conn) {
+	defer conn.Close()
+
+	localAddr, _ := conn.LocalAddr().(*net.TCPAddr)
+	clientAddr, _ := conn.RemoteAddr().(*net.TCPAddr)
+	if localAddr == nil || clientAddr == nil {
+		writeSOCKSReply(conn, proxy.ReplyGeneralFailure, nil)
+		return
+	}
+
+	udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: localAddr.IP})
+	if err != nil {
+		log.Error().Err(err).Msg("Could not create UDP association socket")
+		writeSOCKSReply(conn, proxy.ReplyGeneralFailure, nil)
+		return
+	}
+	defer udpConn.Close()
+
+	stream, code := s.open(proxy.Request{Network: proxy.NetworkUDP})
+	if code != proxy.ReplySucceeded {
+		writeSOCKSReply(conn, code, nil)
+		return
+	}
+	defer stream.Close()
+
+	if err := writeSOCKSReply(conn, proxy.ReplySucceeded, udpConn.LocalAddr().(*net.UDPAddr)); err != nil {
+		return
+	}
+
+	var client atomic.Value
+	go func() {
+		for {
+			address, data, err := proxy.ReadDatagram(stream)
+			if err != nil {
+				udpConn.Close()
+				return
+			}
+			addr, ok := client.Load().(*net.UDPAddr)
+			if !ok {
+				continue
+			}
+			packet, err := encodeSOCKSDatagram(address, data)
+			if err != nil {
+				continue
+			}
+			udpConn.WriteToUDP(packet, addr)
+		}
+	}()
+
+	go func() {
+		buf := make([]byte, maxDatagramSize)
+		for {
+			n, addr, err := udpConn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			// Only the client which requested the association may use it.
+			if !addr.IP.Equal(clientAddr.IP) {
+				continue
+			}
+			client.Store(addr)
+
+			address, data, err := decodeSOCKSDatagram(buf[:n])
+			if err != nil {
+				continue
+			}
+			if err := proxy.WriteDatagram(stream, address, data); err != nil {
+				return
+			}
+		}
+	}()
+
+	io.Copy(ioutil.Discard, conn)
+}
+
+func writeSOCKSReply(w io.Writer, code byte, bound *net.UDPAddr) error {
+	reply := []byte{socks5Version, code, 0x00}
+	if bound == nil {
+		reply = append(reply, atypIPv4, 0, 0, 0, 0, 0, 0)
+	} else {
+		reply = appendSOCKSAddress(reply, bound.IP.String(), bound.Port)
+	}
+	_, err := w.Write(reply)
+	return err
+}
+
+func readSOCKSAddress(r io.Reader) (string, error) {
+	atyp := make([]byte, 1)
+	if _, err := io.ReadFull(r, atyp); err != nil {
+		return "", err
+	}
+
+	var host string
+	switch atyp[0] {
+	case atypIPv4, atypIPv6:
+		size := net.IPv4len
+		if atyp[0] == atypIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(r, ip); err != nil {
+			return "", err
+		}
+		host = net.IP(ip).String()
+	case atypDomain:
+		domain, err := readSOCKSString(r)
+		if err != nil {
+			return "", err
+		}
+		host = domain
+	default:
+		return "", fmt.Errorf("unknown address type %d", atyp[0])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(r, port); err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+func appendSOCKSAddress(buf []byte, host string, port int) []byte {
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			buf = append(append(buf, atypIPv4), ip4...)
+		} else {
+			buf = append(append(buf, atypIPv6), ip.To16()...)
+		}
+	} else {
+		buf = append(append(buf, atypDomain, byte(len(host))), host...)
+	}
+	return append(buf, byte(port>>8), byte(port))
+}
+
+func readSOCKSString(r io.Reader) (string, error) {
+	size := make([]byte, 1)
+	if _, err := io.ReadFull(r, size); err != nil {
+		return "", err
+	}
+	buf := make([]byte, size[0])
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// decodeSOCKSDatagram parses the UDP request header of SOCKS5 datagram.
+func decodeSOCKSDatagram(packet []byte) (address string, data []byte, err error) {
+	if len(packet) < 4 {
+		return "", nil, io.ErrUnexpectedEOF
+	}
+	if packet[2] != 0 {
+		return "", nil, errFragmentedDatagram
+	}
+
+	r := bytes.NewReader(packet[3:])
+	address, err = readSOCKSAddress(r)
+	if err != nil {
+		return "", nil, err
+	}
+	return address, packet[len(packet)-r.Len():], nil
+}
+
+// encodeSOCKSDatagram prepends the UDP request header of SOCKS5 datagram.
+func encodeSOCKSDatagram(address string, data []byte) ([]byte, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, err
+	}
+
+	packet := appendSOCKSAddress([]byte{0x00, 0x00, 0x00}, host, port)
+	return append(packet, data...), nil
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mux
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+
+	"github.com/libp2p/go-yamux"
+)
+
+const (
+	// streamWindow is the number of bytes buffered for a stream which is not read fast enough.
+	streamWindow = 256 * 1024
+	// maxStreams is the maximum number of open streams of a session.
+	maxStreams = 128
+	// acceptBacklog is the number of streams opened by the remote side and waiting to be accepted,
+	// streams opened above it are refused.
+	acceptBacklog = 64
+)
+
+var (
+	// ErrSessionClosed is returned when operating on a closed session.
+	ErrSessionClosed = errors.New("mux session closed")
+	// ErrTooManyStreams is returned when opening a stream above the limit of the session.
+	ErrTooManyStreams = errors.New("too many mux streams")
+)
+
+// Session multiplexes independent streams over a single reliable connection.
+type Session struct {
+	session *yamux.Session
+}
+
+// Client creates a session which opens the streams.
+func Client(conn net.Conn) (*Session, error) {
+	session, err := yamux.Client(conn, newConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &Session{session: session}, nil
+}
+
+// Server creates a session which accepts the streams.
+func Server(conn net.Conn) (*Session, error) {
+	session, err := yamux.Server(conn, newConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &Session{session: session}, nil
+}
+
+func newConfig() *yamux.Config {
+	config := yamux.DefaultConfig()
+	config.AcceptBacklog = acceptBacklog
+	config.MaxStreamWindowSize = streamWindow
+	// Owner of the session keeps the connection alive with pings.
+	config.EnableKeepAlive = false
+	config.LogOutput = ioutil.Discard
+	return config
+}
+
+// Open opens a new stream to the remote side.
+func (s *Session) Open() (*Stream, error) {
+	if s.session.IsClosed() {
+		return nil, ErrSessionClosed
+	}
+	if s.session.NumStreams() >= maxStreams {
+		return nil, ErrTooManyStreams
+	}
+
+	stream, err := s.session.OpenStream()
+	if err != nil {
+		if s.session.IsClosed() {
+			return nil, ErrSessionClosed
+		}
+		return nil, err
+	}
+	return &Stream{stream: stream}, nil
+}
+
+// Accept waits for the next stream opened by the remote side, streams above the limit of the session are refused.
+func (s *Session) Accept() (*Stream, error) {
+	for {
+		stream, err := s.session.AcceptStream()
+		if err != nil {
+			if s.session.IsClosed() {
+				return nil, ErrSessionClosed
+			}
+			return nil, err
+		}
+
+		if s.session.NumStreams() > maxStreams {
+			stream.Reset()
+			continue
+		}
+		return &Stream{stream: stream}, nil
+	}
+}
+
+// Ping sends a ping and waits for the reply, keeping the underlying connection alive.
+func (s *Session) Ping() error {
+	_, err := s.session.Ping()
+	return err
+}
+
+// Done returns a channel which is closed once the session is closed.
+func (s *Session) Done() <-chan struct{} {
+	return s.session.CloseChan()
+}
+
+// Close closes the session, all its streams and the underlying connection.
+func (s *Session) Close() error {
+	return s.session.Close()
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mux
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSessionPair(t *testing.T) (client, server *Session) {
+	clientConn, serverConn := net.Pipe()
+	client, err := Client(clientConn)
+	require.NoError(t, err)
+	server, err = Server(serverConn)
+	require.NoError(t, err)
+	return client, server
+}
+
+func TestSession_StreamsExchangeData(t *testing.T) {
+	client, server := newSessionPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	// given
+	go func() {
+		for {
+			stream, err := server.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(stream, stream)
+				stream.Close()
+			}()
+		}
+	}()
+
+	first, err := client.Open()
+	require.NoError(t, err)
+	second, err := client.Open()
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID(), second.ID())
+
+	// when
+	large := bytes.Repeat([]byte("0123456789"), 5000)
+	go func() {
+		first.Write(large)
+	}()
+	_, err = second.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	// then
+	reply := make([]byte, 5)
+	_, err = io.ReadFull(second, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(reply))
+
+	echoed := make([]byte, len(large))
+	_, err = io.ReadFull(first, echoed)
+	require.NoError(t, err)
+	assert.Equal(t, large, echoed)
+}
+
+func TestSession_RemoteCloseWriteEndsStream(t *testing.T) {
+	client, server := newSessionPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	stream, err := client.Open()
+	require.NoError(t, err)
+	remote, err := server.Accept()
+	require.NoError(t, err)
+
+	// when
+	_, err = remote.Write([]byte("bye"))
+	require.NoError(t, err)
+	require.NoError(t, remote.CloseWrite())
+
+	// then
+	data, err := ioutil.ReadAll(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "bye", string(data))
+
+	// The other direction of the half-closed stream still works.
+	_, err = stream.Write([]byte("reply"))
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+	data, err = ioutil.ReadAll(remote)
+	assert.NoError(t, err)
+	assert.Equal(t, "reply", string(data))
+
+	_, err = remote.Write([]byte("ignored"))
+	assert.Error(t, err)
+}
+
+func TestSession_CloseEndsStreams(t *testing.T) {
+	client, server := newSessionPair(t)
+	defer server.Close()
+
+	stream, err := client.Open()
+	require.NoError(t, err)
+	_, err = server.Accept()
+	require.NoError(t, err)
+
+	// when
+	require.NoError(t, client.Close())
+
+	// then
+	_, err = stream.Read(make([]byte, 1))
+	assert.Error(t, err)
+	_, err = client.Open()
+	assert.Equal(t, ErrSessionClosed, err)
+	_, err = server.Accept()
+	assert.Equal(t, ErrSessionClosed, err)
+}
+
+func TestSession_UnreadStreamDoesNotBlockOthers(t *testing.T) {
+	client, server := newSessionPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	slow, err := client.Open()
+	require.NoError(t, err)
+	_, err = server.Accept()
+	require.NoError(t, err)
+
+	// when
+	written := make(chan int, 1)
+	go func() {
+		n, _ := slow.Write(make([]byte, 2*streamWindow))
+		written <- n
+	}()
+
+	fast, err := client.Open()
+	require.NoError(t, err)
+	remote, err := server.Accept()
+	require.NoError(t, err)
+	_, err = fast.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	// then
+	reply := make([]byte, 5)
+	_, err = io.ReadFull(remote, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(reply))
+
+	// The writer is blocked once the window of the unread stream is used up.
+	select {
+	case n := <-written:
+		t.Fatalf("write of unread stream was not blocked, %d bytes written", n)
+	case <-time.After(100 * time.Millisecond):
+	}
+	require.NoError(t, slow.Reset())
+	assert.Equal(t, streamWindow, <-written)
+}
+
+func TestSession_LimitsOpenStreams(t *testing.T) {
+	client, server := newSessionPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		for {
+			if _, err := server.Accept(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < maxStreams; i++ {
+		_, err := client.Open()
+		require.NoError(t, err)
+	}
+
+	_, err := client.Open()
+	assert.Equal(t, ErrTooManyStreams, err)
+}
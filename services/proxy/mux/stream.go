@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mux
+
+import (
+	"github.com/libp2p/go-yamux"
+)
+
+// Stream is a bidirectional byte stream of the session.
+//
+// Each side may have at most streamWindow bytes of the stream which were not read by the remote side yet,
+// the remote side grants more as it reads the data.
+type Stream struct {
+	stream *yamux.Stream
+}
+
+// ID returns the stream ID unique within the session.
+func (s *Stream) ID() uint32 {
+	return s.stream.StreamID()
+}
+
+// Read reads the data sent by the remote side, it returns io.EOF once the remote side closes the stream for writing.
+func (s *Stream) Read(b []byte) (int, error) {
+	return s.stream.Read(b)
+}
+
+// Write sends the data to the remote side, it blocks while the remote side has not read enough of the data sent before.
+func (s *Stream) Write(b []byte) (int, error) {
+	return s.stream.Write(b)
+}
+
+// CloseWrite closes the stream for writing, the remote side reads the data sent before and then io.EOF.
+// The stream can still read the data sent by the remote side.
+func (s *Stream) CloseWrite() error {
+	return s.stream.Close()
+}
+
+// Close closes the stream, the remote side still reads the data sent before. The stream is released once
+// the remote side closes it too.
+func (s *Stream) Close() error {
+	return s.stream.Close()
+}
+
+// Reset closes the stream in both directions immediately, the data which was not read yet is discarded.
+func (s *Stream) Reset() error {
+	return s.stream.Reset()
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"io"
+	"sync"
+)
+
+// Pipe copies data between both connections until both directions are done, then closes both.
+// Once one direction is done, the write side of its destination is closed, so the peer learns there is
+// no more data while it still can send the rest of its own. Connections which do not support half-close
+// are closed entirely instead.
+func Pipe(a, b io.ReadWriteCloser) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		copyAndCloseWrite(a, b)
+	}()
+	go func() {
+		defer wg.Done()
+		copyAndCloseWrite(b, a)
+	}()
+	wg.Wait()
+
+	a.Close()
+	b.Close()
+}
+
+// closeWriter is implemented by connections supporting half-close, e.g. *net.TCPConn and *mux.Stream.
+type closeWriter interface {
+	CloseWrite() error
+}
+
+func copyAndCloseWrite(dst, src io.ReadWriteCloser) {
+	_, err := io.Copy(dst, src)
+	if err == nil {
+		if cw, ok := dst.(closeWriter); ok && cw.CloseWrite() == nil {
+			return
+		}
+	}
+
+	// Copying in the other direction can't go on either.
+	dst.Close()
+	src.Close()
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tcpPair(t *testing.T) (client, server *net.TCPConn) {
+	listener, err := net.ListenTCP("tcp4", &net.TCPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	defer listener.Close()
+
+	client, err = net.DialTCP("tcp4", nil, listener.Addr().(*net.TCPAddr))
+	require.NoError(t, err)
+	server, err = listener.AcceptTCP()
+	require.NoError(t, err)
+	return client, server
+}
+
+func TestPipe_KeepsHalfClosedDirection(t *testing.T) {
+	consumer, proxyIn := tcpPair(t)
+	proxyOut, destination := tcpPair(t)
+	defer consumer.Close()
+	defer destination.Close()
+	go Pipe(proxyIn, proxyOut)
+
+	// when
+	_, err := consumer.Write([]byte("request"))
+	require.NoError(t, err)
+	require.NoError(t, consumer.CloseWrite())
+
+	// then
+	request, err := ioutil.ReadAll(destination)
+	require.NoError(t, err)
+	assert.Equal(t, "request", string(request))
+
+	_, err = destination.Write([]byte("response"))
+	require.NoError(t, err)
+	require.NoError(t, destination.Close())
+
+	response, err := ioutil.ReadAll(consumer)
+	require.NoError(t, err)
+	assert.Equal(t, "response", string(response))
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Reply codes sent by provider to a stream request, they match SOCKS5 reply codes.
+const (
+	ReplySucceeded          byte = 0x00
+	ReplyGeneralFailure     byte = 0x01
+	ReplyNotAllowed         byte = 0x02
+	ReplyNetworkUnreachable byte = 0x03
+	ReplyHostUnreachable    byte = 0x04
+	ReplyConnectionRefused  byte = 0x05
+	ReplyCommandUnsupported byte = 0x07
+)
+
+const (
+	// NetworkTCP requests a connection to the TCP destination.
+	NetworkTCP = "tcp"
+	// NetworkUDP requests an association relaying UDP datagrams.
+	NetworkUDP = "udp"
+)
+
+const maxAddressSize = 255
+
+// ErrAddressTooLong is returned when address does not fit into a request.
+var ErrAddressTooLong = errors.New("address is too long")
+
+// Request is the first message of every tunnel stream telling the provider what to connect to.
+type Request struct {
+	Network string
+	// Address is a "host:port" destination, it is empty for UDP associations.
+	Address string
+}
+
+// WriteRequest writes the stream request.
+func WriteRequest(w io.Writer, req Request) error {
+	if len(req.Network) > maxAddressSize || len(req.Address) > maxAddressSize {
+		return ErrAddressTooLong
+	}
+
+	buf := make([]byte, 0, 2+len(req.Network)+len(req.Address))
+	buf = append(buf, byte(len(req.Network)))
+	buf = append(buf, req.Network...)
+	buf = append(buf, byte(len(req.Address)))
+	buf = append(buf, req.Address...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadRequest reads the stream request.
+func ReadRequest(r io.Reader) (Request, error) {
+	network, err := readString(r)
+	if err != nil {
+		return Request{}, fmt.Errorf("could not read request network: %w", err)
+	}
+	address, err := readString(r)
+	if err != nil {
+		return Request{}, fmt.Errorf("could not read request address: %w", err)
+	}
+	return Request{Network: network, Address: address}, nil
+}
+
+// WriteReply writes the reply code to the stream request.
+func WriteReply(w io.Writer, code byte) error {
+	_, err := w.Write([]byte{code})
+	return err
+}
+
+// ReadReply reads the reply code to the stream request.
+func ReadReply(r io.Reader) (byte, error) {
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+// WriteDatagram writes the UDP datagram relayed over UDP association stream.
+// The address is a destination of the consumer datagram or a source of the provider one.
+func WriteDatagram(w io.Writer, address string, data []byte) error {
+	if len(address) > maxAddressSize {
+		return ErrAddressTooLong
+	}
+	if len(data) > 0xffff {
+		return fmt.Errorf("datagram of %d bytes is too large", len(data))
+	}
+
+	buf := make([]byte, 0, 3+len(address)+len(data))
+	buf = append(buf, byte(len(address)))
+	buf = append(buf, address...)
+	buf = append(buf, 0, 0)
+	binary.BigEndian.PutUint16(buf[len(buf)-2:], uint16(len(data)))
+	buf = append(buf, data...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadDatagram reads the UDP datagram relayed over UDP association stream.
+func ReadDatagram(r io.Reader) (address string, data []byte, err error) {
+	address, err = readString(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	size := make([]byte, 2)
+	if _, err := io.ReadFull(r, size); err != nil {
+		return "", nil, err
+	}
+	data = make([]byte, binary.BigEndian.Uint16(size))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", nil, err
+	}
+	return address, data, nil
+}
+
+func readString(r io.Reader) (string, error) {
+	size := make([]byte, 1)
+	if _, err := io.ReadFull(r, size); err != nil {
+		return "", err
+	}
+	buf := make([]byte, size[0])
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequest_Serialization(t *testing.T) {
+	var buf bytes.Buffer
+
+	// when
+	err := WriteRequest(&buf, Request{Network: NetworkTCP, Address: "example.com:443"})
+	assert.NoError(t, err)
+	err = WriteReply(&buf, ReplyNotAllowed)
+	assert.NoError(t, err)
+
+	// then
+	req, err := ReadRequest(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, Request{Network: NetworkTCP, Address: "example.com:443"}, req)
+
+	code, err := ReadReply(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ReplyNotAllowed, code)
+}
+
+func TestRequest_AddressTooLong(t *testing.T) {
+	err := WriteRequest(&bytes.Buffer{}, Request{Network: NetworkTCP, Address: strings.Repeat("a", 256)})
+	assert.Equal(t, ErrAddressTooLong, err)
+}
+
+func TestDatagram_Serialization(t *testing.T) {
+	var buf bytes.Buffer
+
+	// when
+	assert.NoError(t, WriteDatagram(&buf, "1.1.1.1:53", []byte("query")))
+	assert.NoError(t, WriteDatagram(&buf, "8.8.8.8:53", nil))
+
+	// then
+	address, data, err := ReadDatagram(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1.1.1:53", address)
+	assert.Equal(t, []byte("query"), data)
+
+	address, data, err = ReadDatagram(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "8.8.8.8:53", address)
+	assert.Empty(t, data)
+}
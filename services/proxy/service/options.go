@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/service"
+)
+
+// Options describes options which are required to start Proxy service.
+type Options struct {
+	// ProtectedNetworks are the provider's networks which are never reachable via the proxy.
+	ProtectedNetworks []string `json:"protected_networks"`
+}
+
+// GetOptions returns effective Proxy service options from application configuration.
+func GetOptions() Options {
+	var networks []string
+	if cfg := config.GetString(config.FlagFirewallProtectedNetworks); cfg != "" {
+		networks = strings.Split(cfg, ",")
+	}
+
+	return Options{
+		ProtectedNetworks: networks,
+	}
+}
+
+// ParseJSONOptions function fills in Proxy options from JSON request
+func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
+	opts := GetOptions()
+	if request == nil {
+		return opts, nil
+	}
+
+	if err := json.Unmarshal(*request, &opts); err != nil {
+		return nil, err
+	}
+	if _, err := opts.networks(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+func (o Options) networks() ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(o.ProtectedNetworks))
+	for _, s := range o.ProtectedNetworks {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid protected network %q: %w", s, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
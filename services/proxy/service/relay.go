@@ -0,0 +1,266 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/services/proxy"
+	"github.com/mysteriumnetwork/node/services/proxy/mux"
+)
+
+const (
+	maxDatagramSize = 64 * 1024
+	// maxSessionDials is the maximum number of destinations a session may be connecting to at once.
+	maxSessionDials = 32
+	// maxSessionUDPSockets is the maximum number of UDP associations a session may have open.
+	maxSessionUDPSockets = 16
+)
+
+type lookupIPFunc func(ctx context.Context, host string) ([]net.IPAddr, error)
+
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// sessionLimits bounds the provider resources a single session can hold, the number of its streams
+// is bounded by the mux session itself.
+type sessionLimits struct {
+	dials      chan struct{}
+	udpSockets chan struct{}
+}
+
+func newSessionLimits() *sessionLimits {
+	return &sessionLimits{
+		dials:      make(chan struct{}, maxSessionDials),
+		udpSockets: make(chan struct{}, maxSessionUDPSockets),
+	}
+}
+
+// acquire takes a slot of the limit, it returns false if all of the slots are taken.
+func acquire(limit chan struct{}) bool {
+	select {
+	case limit <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func release(limit chan struct{}) {
+	<-limit
+}
+
+// serve handles the streams opened by consumer until the tunnel is closed.
+func (m *Manager) serve(session *mux.Session) {
+	limits := newSessionLimits()
+	for {
+		stream, err := session.Accept()
+		if err != nil {
+			return
+		}
+		go m.handleStream(stream, limits)
+	}
+}
+
+func (m *Manager) handleStream(stream *mux.Stream, limits *sessionLimits) {
+	req, err := proxy.ReadRequest(stream)
+	if err != nil {
+		log.Debug().Err(err).Msg("Could not read proxy request")
+		stream.Close()
+		return
+	}
+
+	switch req.Network {
+	case proxy.NetworkTCP:
+		m.relayTCP(stream, req.Address, limits)
+	case proxy.NetworkUDP:
+		m.relayUDP(stream, limits)
+	default:
+		proxy.WriteReply(stream, proxy.ReplyCommandUnsupported)
+		stream.Close()
+	}
+}
+
+func (m *Manager) relayTCP(stream *mux.Stream, address string, limits *sessionLimits) {
+	if !acquire(limits.dials) {
+		log.Debug().Msgf("Too many connections in progress, refusing proxy destination %s", address)
+		proxy.WriteReply(stream, proxy.ReplyGeneralFailure)
+		stream.Close()
+		return
+	}
+	conn, code := m.connect(address)
+	release(limits.dials)
+	if code != proxy.ReplySucceeded {
+		proxy.WriteReply(stream, code)
+		stream.Close()
+		return
+	}
+
+	if err := proxy.WriteReply(stream, proxy.ReplySucceeded); err != nil {
+		conn.Close()
+		stream.Close()
+		return
+	}
+	proxy.Pipe(stream, conn)
+}
+
+// connect resolves and dials the TCP destination of the consumer request.
+func (m *Manager) connect(address string) (net.Conn, byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	ip, port, code := m.destination(ctx, proxy.NetworkTCP, address)
+	if code != proxy.ReplySucceeded {
+		return nil, code
+	}
+
+	conn, err := m.dial(ctx, "tcp", net.JoinHostPort(ip.String(), strconv.Itoa(port)))
+	if err != nil {
+		log.Debug().Err(err).Msgf("Could not connect to proxy destination %s", address)
+		return nil, dialErrorReply(err)
+	}
+	return conn, proxy.ReplySucceeded
+}
+
+func (m *Manager) relayUDP(stream *mux.Stream, limits *sessionLimits) {
+	defer stream.Close()
+
+	if !acquire(limits.udpSockets) {
+		log.Debug().Msg("Too many UDP associations, refusing proxy request")
+		proxy.WriteReply(stream, proxy.ReplyGeneralFailure)
+		return
+	}
+	defer release(limits.udpSockets)
+
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		log.Error().Err(err).Msg("Could not create UDP association socket")
+		proxy.WriteReply(stream, proxy.ReplyGeneralFailure)
+		return
+	}
+	defer conn.Close()
+
+	if err := proxy.WriteReply(stream, proxy.ReplySucceeded); err != nil {
+		return
+	}
+
+	go func() {
+		defer stream.Close()
+
+		buf := make([]byte, maxDatagramSize)
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			if err := proxy.WriteDatagram(stream, addr.String(), buf[:n]); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		address, data, err := proxy.ReadDatagram(stream)
+		if err != nil {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+		ip, port, code := m.destination(ctx, proxy.NetworkUDP, address)
+		cancel()
+		if code != proxy.ReplySucceeded {
+			log.Debug().Msgf("Dropping datagram to proxy destination %s, reply code %d", address, code)
+			continue
+		}
+
+		if _, err := conn.WriteToUDP(data, &net.UDPAddr{IP: ip, Port: port}); err != nil {
+			log.Debug().Err(err).Msgf("Could not send datagram to proxy destination %s", address)
+		}
+	}
+}
+
+// destination resolves the "host:port" address of the consumer request and checks if it's allowed.
+func (m *Manager) destination(ctx context.Context, protocol, address string) (net.IP, int, byte) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, 0, proxy.ReplyGeneralFailure
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 0xffff {
+		return nil, 0, proxy.ReplyGeneralFailure
+	}
+
+	policies := m.policies()
+	ip := net.ParseIP(host)
+	if ip == nil {
+		if policies != nil && !policies.IsHostAllowed(host) {
+			return nil, 0, proxy.ReplyNotAllowed
+		}
+
+		addrs, err := m.lookupIP(ctx, host)
+		if err != nil || len(addrs) == 0 {
+			return nil, 0, proxy.ReplyHostUnreachable
+		}
+		ip = addrs[0].IP
+	} else if policies != nil && policies.HasDNSRules() {
+		// Whitelisted hosts can only be reached by name.
+		return nil, 0, proxy.ReplyNotAllowed
+	}
+
+	if !m.isAllowed(ip, protocol, port) {
+		return nil, 0, proxy.ReplyNotAllowed
+	}
+	return ip, port, proxy.ReplySucceeded
+}
+
+func (m *Manager) isAllowed(ip net.IP, protocol string, port int) bool {
+	if ip.IsUnspecified() || ip.IsLoopback() || ip.IsMulticast() || ip.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, network := range m.protectedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	if policies := m.policies(); policies != nil {
+		return policies.IsDestinationAllowed(ip, protocol, port)
+	}
+	return true
+}
+
+func dialErrorReply(err error) byte {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return proxy.ReplyConnectionRefused
+	case errors.Is(err, syscall.ENETUNREACH):
+		return proxy.ReplyNetworkUnreachable
+	case errors.Is(err, syscall.EHOSTUNREACH):
+		return proxy.ReplyHostUnreachable
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return proxy.ReplyHostUnreachable
+	default:
+		return proxy.ReplyGeneralFailure
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/services/proxy"
+	"github.com/mysteriumnetwork/node/services/proxy/tunnel"
+)
+
+const dialTimeout = 10 * time.Second
+
+// NewManager creates new instance of Proxy service, it refuses to start without the protected networks.
+func NewManager(eventBus eventbus.Publisher, options Options) (*Manager, error) {
+	networks, err := options.networks()
+	if err != nil {
+		return nil, fmt.Errorf("could not parse protected networks of proxy service: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	return &Manager{
+		done:              make(chan struct{}),
+		eventBus:          eventBus,
+		protectedNetworks: networks,
+		lookupIP:          net.DefaultResolver.LookupIPAddr,
+		dial:              dialer.DialContext,
+		sessionCleanup:    map[string]func(){},
+	}, nil
+}
+
+// Manager represents an instance of Proxy service
+type Manager struct {
+	done chan struct{}
+
+	eventBus          eventbus.Publisher
+	protectedNetworks []*net.IPNet
+	lookupIP          lookupIPFunc
+	dial              dialFunc
+
+	serviceInstance  *service.Instance
+	sessionCleanup   map[string]func()
+	sessionCleanupMu sync.Mutex
+}
+
+// ProvideConfig provides the config for consumer and starts serving the proxy tunnel of the session.
+func (m *Manager) ProvideConfig(sessionID string, _ json.RawMessage, remoteConn *net.UDPConn) (*service.ConfigParams, error) {
+	log.Info().Msg("Accepting new proxy connection")
+	if remoteConn == nil {
+		return nil, errors.New("proxy service requires p2p service connection")
+	}
+
+	key, err := tunnel.NewKey()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := tunnel.Listen(remoteConn, key)
+	if err != nil {
+		return nil, fmt.Errorf("could not start proxy tunnel: %w", err)
+	}
+
+	statsPublisher := newStatsPublisher(m.eventBus, time.Second)
+	go statsPublisher.start(sessionID, t)
+	go m.serve(t.Session)
+
+	destroy := func() {
+		m.sessionCleanupMu.Lock()
+		_, ok := m.sessionCleanup[sessionID]
+		delete(m.sessionCleanup, sessionID)
+		m.sessionCleanupMu.Unlock()
+		if !ok {
+			log.Info().Msgf("Session '%s' was already cleaned up, returning without changes", sessionID)
+			return
+		}
+
+		log.Info().Msgf("Cleaning up session %s", sessionID)
+		statsPublisher.stop()
+		if err := t.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close proxy tunnel")
+		}
+	}
+
+	m.sessionCleanupMu.Lock()
+	m.sessionCleanup[sessionID] = destroy
+	m.sessionCleanupMu.Unlock()
+
+	return &service.ConfigParams{
+		SessionServiceConfig:   proxy.ServiceConfig{Key: key},
+		SessionDestroyCallback: destroy,
+	}, nil
+}
+
+// Serve starts service - does block
+func (m *Manager) Serve(instance *service.Instance) error {
+	m.serviceInstance = instance
+	log.Info().Msg("Proxy service started successfully")
+	<-m.done
+	return nil
+}
+
+// Stop stops service.
+func (m *Manager) Stop() error {
+	m.sessionCleanupMu.Lock()
+	cleanups := make([]func(), 0, len(m.sessionCleanup))
+	for _, cleanup := range m.sessionCleanup {
+		cleanups = append(cleanups, cleanup)
+	}
+	m.sessionCleanupMu.Unlock()
+
+	for _, cleanup := range cleanups {
+		cleanup()
+	}
+
+	close(m.done)
+	log.Info().Msg("Proxy service stopped")
+	return nil
+}
+
+func (m *Manager) policies() *policy.Repository {
+	if m.serviceInstance == nil {
+		return nil
+	}
+	return m.serviceInstance.Policies()
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/services/proxy"
+	"github.com/mysteriumnetwork/node/services/proxy/mux"
+)
+
+func newTestManager(t *testing.T, policies *policy.Repository) *Manager {
+	m, err := NewManager(mocks.NewEventBus(), Options{ProtectedNetworks: []string{"10.0.0.0/8"}})
+	require.NoError(t, err)
+	m.lookupIP = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		switch host {
+		case "example.com":
+			return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+		case "intranet.local":
+			return []net.IPAddr{{IP: net.ParseIP("10.1.1.1")}}, nil
+		default:
+			return nil, errors.New("no such host")
+		}
+	}
+	if policies != nil {
+		m.serviceInstance = service.NewInstance(identity.FromAddress("0x1"), "proxy", nil, market.ServiceProposal{}, servicestate.Running, nil, policies, nil)
+	}
+	return m
+}
+
+func TestNewManager_RefusesInvalidProtectedNetworks(t *testing.T) {
+	_, err := NewManager(mocks.NewEventBus(), Options{ProtectedNetworks: []string{"10.0.0.0/33"}})
+	assert.Error(t, err)
+}
+
+func TestManager_Destination(t *testing.T) {
+	m := newTestManager(t, nil)
+
+	tests := []struct {
+		address string
+		ip      string
+		code    byte
+	}{
+		{address: "example.com:443", ip: "93.184.216.34", code: proxy.ReplySucceeded},
+		{address: "1.1.1.1:53", ip: "1.1.1.1", code: proxy.ReplySucceeded},
+		{address: "intranet.local:80", code: proxy.ReplyNotAllowed},
+		{address: "10.0.0.1:80", code: proxy.ReplyNotAllowed},
+		{address: "127.0.0.1:4050", code: proxy.ReplyNotAllowed},
+		{address: "[::1]:4050", code: proxy.ReplyNotAllowed},
+		{address: "unknown.host:80", code: proxy.ReplyHostUnreachable},
+		{address: "example.com", code: proxy.ReplyGeneralFailure},
+		{address: "example.com:0", code: proxy.ReplyGeneralFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			ip, _, code := m.destination(context.Background(), proxy.NetworkTCP, tt.address)
+			assert.Equal(t, tt.code, code)
+			if tt.ip != "" {
+				assert.Equal(t, tt.ip, ip.String())
+			}
+		})
+	}
+}
+
+func TestManager_Destination_AppliesPolicies(t *testing.T) {
+	repo := policy.NewRepository()
+	repo.SetPolicyRules(
+		policy.LocalPolicy("web"),
+		market.AccessPolicyRuleSet{
+			ID: "web",
+			Deny: []market.AccessRule{
+				{Type: market.AccessPolicyTypeProtocol, Value: "smtp"},
+				{Type: market.AccessPolicyTypeDNSHostname, Value: "blocked.com"},
+			},
+		},
+	)
+	m := newTestManager(t, repo)
+
+	_, _, code := m.destination(context.Background(), proxy.NetworkTCP, "example.com:443")
+	assert.Equal(t, proxy.ReplySucceeded, code)
+	_, _, code = m.destination(context.Background(), proxy.NetworkTCP, "example.com:25")
+	assert.Equal(t, proxy.ReplyNotAllowed, code)
+	_, _, code = m.destination(context.Background(), proxy.NetworkTCP, "blocked.com:443")
+	assert.Equal(t, proxy.ReplyNotAllowed, code)
+}
+
+func TestManager_RelaysTCPStream(t *testing.T) {
+	m := newTestManager(t, nil)
+	destination, remote := net.Pipe()
+	var dialed string
+	m.dial = func(_ context.Context, network, address string) (net.Conn, error) {
+		dialed = address
+		return remote, nil
+	}
+
+	consumerConn, providerConn := net.Pipe()
+	consumer, err := mux.Client(consumerConn)
+	require.NoError(t, err)
+	provider, err := mux.Server(providerConn)
+	require.NoError(t, err)
+	defer consumer.Close()
+	defer provider.Close()
+	go m.serve(provider)
+
+	// when
+	stream, err := consumer.Open()
+	require.NoError(t, err)
+	require.NoError(t, proxy.WriteRequest(stream, proxy.Request{Network: proxy.NetworkTCP, Address: "example.com:80"}))
+
+	// then
+	code, err := proxy.ReadReply(stream)
+	require.NoError(t, err)
+	assert.Equal(t, proxy.ReplySucceeded, code)
+	assert.Equal(t, "93.184.216.34:80", dialed)
+
+	go stream.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(destination, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+
+	go destination.Write([]byte("pong"))
+	_, err = io.ReadFull(stream, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(buf))
+}
+
+func TestManager_RejectsForbiddenStream(t *testing.T) {
+	m := newTestManager(t, nil)
+
+	consumerConn, providerConn := net.Pipe()
+	consumer, err := mux.Client(consumerConn)
+	require.NoError(t, err)
+	provider, err := mux.Server(providerConn)
+	require.NoError(t, err)
+	defer consumer.Close()
+	defer provider.Close()
+	go m.serve(provider)
+
+	// when
+	stream, err := consumer.Open()
+	require.NoError(t, err)
+	require.NoError(t, proxy.WriteRequest(stream, proxy.Request{Network: proxy.NetworkTCP, Address: "10.0.0.1:22"}))
+
+	// then
+	code, err := proxy.ReadReply(stream)
+	require.NoError(t, err)
+	assert.Equal(t, proxy.ReplyNotAllowed, code)
+	_, err = stream.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+}
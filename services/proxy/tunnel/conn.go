@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tunnel
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// packetConn adapts the connected p2p service connection to the packet connection used by KCP,
+// seals every packet with AEAD and counts the bytes transferred over it.
+type packetConn struct {
+	conn     *net.UDPConn
+	aead     cipher.AEAD
+	sent     uint64
+	received uint64
+}
+
+// ReadFrom reads the next packet which is authentic, the other packets are dropped.
+func (c *packetConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	packet := make([]byte, c.aead.NonceSize()+len(b)+c.aead.Overhead())
+	for {
+		n, err := c.conn.Read(packet)
+		atomic.AddUint64(&c.received, uint64(n))
+		if err != nil {
+			return 0, c.conn.RemoteAddr(), err
+		}
+		if n < c.aead.NonceSize() {
+			continue
+		}
+
+		nonce, sealed := packet[:c.aead.NonceSize()], packet[c.aead.NonceSize():n]
+		plain, err := c.aead.Open(b[:0], nonce, sealed, nil)
+		if err != nil {
+			continue
+		}
+		return len(plain), c.conn.RemoteAddr(), nil
+	}
+}
+
+func (c *packetConn) WriteTo(b []byte, _ net.Addr) (int, error) {
+	packet := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(b)+c.aead.Overhead())
+	if _, err := rand.Read(packet); err != nil {
+		return 0, err
+	}
+	packet = c.aead.Seal(packet, packet, b, nil)
+
+	n, err := c.conn.Write(packet)
+	atomic.AddUint64(&c.sent, uint64(n))
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *packetConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *packetConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *packetConn) SetDeadline(t time.Time) error {
+	return c.conn.SetDeadline(t)
+}
+
+func (c *packetConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *packetConn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+func (c *packetConn) stats() (sent, received uint64) {
+	return atomic.LoadUint64(&c.sent), atomic.LoadUint64(&c.received)
+}
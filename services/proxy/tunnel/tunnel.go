@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tunnel
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	kcp "github.com/xtaci/kcp-go/v5"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/mysteriumnetwork/node/services/proxy/mux"
+)
+
+const (
+	keySize           = chacha20poly1305.KeySize
+	kcpMTUSize        = 1280
+	kcpWindowSize     = 1024
+	keepAliveInterval = 10 * time.Second
+)
+
+// Tunnel is an encrypted stream multiplexer running over the p2p service connection of the session.
+type Tunnel struct {
+	*mux.Session
+
+	conn    *packetConn
+	session *kcp.UDPSession
+}
+
+// NewKey generates a random tunnel encryption key.
+func NewKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("could not generate tunnel key: %w", err)
+	}
+	return key, nil
+}
+
+// Dial creates the consumer side of the tunnel, which opens the streams.
+func Dial(conn *net.UDPConn, key []byte) (*Tunnel, error) {
+	return newTunnel(conn, key, mux.Client)
+}
+
+// Listen creates the provider side of the tunnel, which accepts the streams.
+func Listen(conn *net.UDPConn, key []byte) (*Tunnel, error) {
+	return newTunnel(conn, key, mux.Server)
+}
+
+func newTunnel(conn *net.UDPConn, key []byte, newSession func(conn net.Conn) (*mux.Session, error)) (*Tunnel, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid tunnel key size %d", len(key))
+	}
+
+	// Packets are sealed with AEAD below KCP, so forged or modified packets never reach KCP and the mux.
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not create XChaCha20-Poly1305 cipher: %w", err)
+	}
+
+	pc := &packetConn{conn: conn, aead: aead}
+	session, err := kcp.NewConn3(1, conn.RemoteAddr(), nil, 0, 0, pc)
+	if err != nil {
+		return nil, fmt.Errorf("could not create KCP session: %w", err)
+	}
+	session.SetMtu(kcpMTUSize)
+	session.SetStreamMode(true)
+	session.SetNoDelay(1, 20, 2, 1)
+	session.SetWindowSize(kcpWindowSize, kcpWindowSize)
+
+	muxSession, err := newSession(session)
+	if err != nil {
+		session.Close()
+		return nil, fmt.Errorf("could not create mux session: %w", err)
+	}
+
+	t := &Tunnel{
+		Session: muxSession,
+		conn:    pc,
+		session: session,
+	}
+	go t.keepAlive()
+	return t, nil
+}
+
+// Stats returns the number of bytes sent and received over the service connection.
+func (t *Tunnel) Stats() (sent, received uint64) {
+	return t.conn.stats()
+}
+
+// Close closes the tunnel streams and the service connection.
+func (t *Tunnel) Close() error {
+	err := t.Session.Close()
+	if err := t.conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return err
+}
+
+// keepAlive keeps NAT mapping of the service connection open while there is no traffic.
+func (t *Tunnel) keepAlive() {
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.Ping(); err != nil {
+				return
+			}
+		case <-t.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tunnel
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func udpPair(t *testing.T) (a, b *net.UDPConn) {
+	listenA, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	listenB, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	addrA, addrB := listenA.LocalAddr().(*net.UDPAddr), listenB.LocalAddr().(*net.UDPAddr)
+	listenA.Close()
+	listenB.Close()
+
+	a, err = net.DialUDP("udp4", addrA, addrB)
+	require.NoError(t, err)
+	b, err = net.DialUDP("udp4", addrB, addrA)
+	require.NoError(t, err)
+	return a, b
+}
+
+func TestTunnel_ExchangesData(t *testing.T) {
+	consumerConn, providerConn := udpPair(t)
+	key, err := NewKey()
+	require.NoError(t, err)
+
+	consumer, err := Dial(consumerConn, key)
+	require.NoError(t, err)
+	defer consumer.Close()
+	provider, err := Listen(providerConn, key)
+	require.NoError(t, err)
+	defer provider.Close()
+
+	// when
+	stream, err := consumer.Open()
+	require.NoError(t, err)
+	_, err = stream.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	// then
+	remote, err := provider.Accept()
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(remote, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+
+	sent, _ := consumer.Stats()
+	assert.Greater(t, sent, uint64(0))
+}
+
+func TestTunnel_DropsForgedPackets(t *testing.T) {
+	consumerConn, providerConn := udpPair(t)
+	key, err := NewKey()
+	require.NoError(t, err)
+	otherKey, err := NewKey()
+	require.NoError(t, err)
+
+	consumer, err := Dial(consumerConn, otherKey)
+	require.NoError(t, err)
+	defer consumer.Close()
+	provider, err := Listen(providerConn, key)
+	require.NoError(t, err)
+	defer provider.Close()
+
+	// when
+	stream, err := consumer.Open()
+	require.NoError(t, err)
+	_, err = stream.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	// then
+	accepted := make(chan struct{})
+	go func() {
+		if _, err := provider.Accept(); err == nil {
+			close(accepted)
+		}
+	}()
+	select {
+	case <-accepted:
+		t.Fatal("stream opened with a wrong key was accepted")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
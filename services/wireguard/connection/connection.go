@@ -74,12 +74,16 @@ type Connection struct {
 	ipResolver          ip.Resolver
 	connectionEndpoint  wg.ConnectionEndpoint
 	removeAllowedIPRule func()
+	splitTunnel         *splitTunnel
+	dnsServers          []string
+	providerDNS         []string
 	opts                Options
 	connEndpointFactory wg.EndpointFactory
 	handshakeWaiter     HandshakeWaiter
 }
 
-var _ connection.Connection = &Connection{}
+var _ connection.TunnelConnection = &Connection{}
+var _ connection.DNSConnection = &Connection{}
 
 // State returns connection state channel.
 func (c *Connection) State() <-chan connectionstate.State {
@@ -96,9 +100,28 @@ func (c *Connection) Statistics() (connectionstate.Statistics, error) {
 		At:            time.Now(),
 		BytesSent:     stats.BytesSent,
 		BytesReceived: stats.BytesReceived,
+		LastHandshake: stats.LastHandshake,
 	}, nil
 }
 
+// DNSServers returns DNS servers configured by the connection.
+func (c *Connection) DNSServers() []string {
+	return c.dnsServers
+}
+
+// ProviderDNSServers returns DNS servers offered by the provider.
+func (c *Connection) ProviderDNSServers() []string {
+	return c.providerDNS
+}
+
+// InterfaceName returns the name of wireguard tunnel network interface.
+func (c *Connection) InterfaceName() string {
+	if c.connectionEndpoint == nil {
+		return ""
+	}
+	return c.connectionEndpoint.InterfaceName()
+}
+
 // Start establish wireguard connection to the service provider.
 func (c *Connection) Start(ctx context.Context, options connection.ConnectOptions) error {
 	return c.start(ctx, c.startConn, options)
@@ -141,29 +164,65 @@ func (c *Connection) start(ctx context.Context, start startConn, options connect
 	if err != nil {
 		return errors.Wrap(err, "could not resolve DNS IPs")
 	}
+	c.dnsServers = dnsIPs
+	c.providerDNS, _ = connection.SelectProviderDNS(config.Consumer.DNSIPs)
+
+	if c.splitTunnel != nil {
+		c.splitTunnel.stop()
+	}
+	c.splitTunnel = newSplitTunnel(options.Params.SplitTunnel)
+	defer func(st *splitTunnel) {
+		if err != nil {
+			st.stop()
+		}
+	}(c.splitTunnel)
+	if err = c.splitTunnel.start(); err != nil {
+		return errors.Wrap(err, "could not apply split tunneling")
+	}
+
+	// Consumer subnet carries traffic to the provider DNS when only included traffic is tunnelled.
+	subnet := config.Consumer.IPAddress
+	consumerSubnet := (&net.IPNet{IP: subnet.IP.Mask(subnet.Mask), Mask: subnet.Mask}).String()
+	allowedIPs := c.splitTunnel.allowedIPs()
+	if options.Params.SplitTunnel.IncludesOnly() {
+		allowedIPs = append(allowedIPs, consumerSubnet)
+	}
 
 	log.Info().Msg("Starting new connection")
-	var conn wg.ConnectionEndpoint
-	conn, err = start(wgcfg.DeviceConfig{
+	deviceConfig := wgcfg.DeviceConfig{
 		IfaceName:    "", // Interface name will be generated by connection endpoint.
 		Subnet:       config.Consumer.IPAddress,
+		Subnet6:      config.Consumer.IPAddress6,
 		PrivateKey:   c.privateKey,
 		ListenPort:   config.LocalPort,
 		DNS:          dnsIPs,
 		DNSScriptDir: c.opts.DNSScriptDir,
+		OuterIface:   options.OuterIface,
+		NestingLevel: options.NestingLevel,
 		Peer: wgcfg.Peer{
 			Endpoint:               &config.Provider.Endpoint,
 			PublicKey:              config.Provider.PublicKey,
-			AllowedIPs:             []string{"0.0.0.0/0", "::/0"},
+			AllowedIPs:             allowedIPs,
 			KeepAlivePeriodSeconds: 18,
 		},
 		ReplacePeers: true,
-	})
+	}
+	var conn wg.ConnectionEndpoint
+	conn, err = start(deviceConfig)
 	if err != nil {
 		return errors.Wrap(err, "could not start new connection")
 	}
 	c.connectionEndpoint = conn
 
+	if options.Params.SplitTunnel.IncludesOnly() {
+		c.splitTunnel.notifyIncludeChange(func(included []string) {
+			deviceConfig.Peer.AllowedIPs = append(included, consumerSubnet)
+			if err := conn.ReconfigureConsumerMode(deviceConfig); err != nil {
+				log.Error().Err(err).Msg("Failed to route newly resolved included domain addresses")
+			}
+		})
+	}
+
 	log.Info().Msgf("Adding connection peer %s", config.Provider.Endpoint.String())
 
 	log.Info().Msg("Waiting for initial handshake")
@@ -223,6 +282,10 @@ func (c *Connection) Stop() {
 			c.removeAllowedIPRule()
 		}
 
+		if c.splitTunnel != nil {
+			c.splitTunnel.stop()
+		}
+
 		if c.connectionEndpoint != nil {
 			if err := c.connectionEndpoint.Stop(); err != nil {
 				log.Error().Err(err).Msg("Failed to close wireguard connection")
@@ -30,6 +30,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/router"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 )
@@ -93,6 +94,49 @@ func TestConnectionStopOnceAfterHandshakeErrorAndStopCall(t *testing.T) {
 	assert.Equal(t, connectionstate.NotConnected, <-conn.State())
 }
 
+func TestConnectionRollsBackSplitTunnelExclusionsAfterStartError(t *testing.T) {
+	mockRouter := &mockRouter{excluded: make(map[string]bool)}
+	defaultRouter := router.DefaultRouter
+	router.DefaultRouter = mockRouter
+	defer func() {
+		router.DefaultRouter = defaultRouter
+	}()
+
+	conn := newConn(t)
+	conn.handshakeWaiter = &mockHandshakeWaiter{err: errors.New("handshake timeout")}
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+
+	err := conn.Start(context.Background(), connection.ConnectOptions{
+		Params: connection.ConnectParams{
+			SplitTunnel: connection.SplitTunnel{ExcludeCIDRs: []string{"10.0.0.0/8"}},
+		},
+		SessionConfig: sessionConfig,
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, mockRouter.excludedCount)
+	assert.Empty(t, mockRouter.excluded)
+}
+
+type mockRouter struct {
+	excluded      map[string]bool
+	excludedCount int
+}
+
+func (mr *mockRouter) ExcludeIP(net.IP) error         { return nil }
+func (mr *mockRouter) RemoveExcludedIP(net.IP) error  { return nil }
+func (mr *mockRouter) ExcludeApp(string) error        { return nil }
+func (mr *mockRouter) RemoveExcludedApp(string) error { return nil }
+func (mr *mockRouter) Clean() error                   { return nil }
+func (mr *mockRouter) ExcludeNet(ipNet net.IPNet) error {
+	mr.excluded[ipNet.String()] = true
+	mr.excludedCount++
+	return nil
+}
+func (mr *mockRouter) RemoveExcludedNet(ipNet net.IPNet) error {
+	delete(mr.excluded, ipNet.String())
+	return nil
+}
+
 func newConn(t *testing.T) *Connection {
 	endpointFactory := func() (wg.ConnectionEndpoint, error) {
 		return &mockConnectionEndpoint{}, nil
@@ -118,8 +162,9 @@ func newServiceConfig() wg.ServiceConfig {
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
-			IPAddress net.IPNet
-			DNSIPs    string
+			IPAddress  net.IPNet
+			IPAddress6 net.IPNet
+			DNSIPs     string
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -0,0 +1,252 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/dns"
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/router"
+)
+
+// splitTunnel applies split tunneling rules of the connection. Excluded networks, domains and applications
+// are routed through the system default gateway and allowed by kill switch, included domains are kept routed
+// through the tunnel while their addresses change.
+type splitTunnel struct {
+	opts connection.SplitTunnel
+
+	mu              sync.Mutex
+	stopped         bool
+	excluded        map[string]func()
+	includeWatcher  *dns.DomainWatcher
+	excludeWatcher  *dns.DomainWatcher
+	includedIPs     []string
+	onIncludeChange func(allowedIPs []string)
+}
+
+func newSplitTunnel(opts connection.SplitTunnel) *splitTunnel {
+	return &splitTunnel{
+		opts:     opts,
+		excluded: make(map[string]func()),
+	}
+}
+
+// start applies exclusions and resolves included domains.
+func (st *splitTunnel) start() error {
+	for _, cidr := range st.opts.ExcludeCIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("could not parse excluded network %q: %w", cidr, err)
+		}
+		if err := st.excludeNet(*ipNet); err != nil {
+			return err
+		}
+	}
+
+	for _, cgroup := range st.opts.ExcludeApps {
+		if err := st.excludeApp(cgroup); err != nil {
+			return err
+		}
+	}
+
+	if len(st.opts.ExcludeDomains) == 0 && len(st.opts.IncludeDomains) == 0 {
+		return nil
+	}
+
+	resolver, err := dns.ResolveViaSystem()
+	if err != nil {
+		return fmt.Errorf("could not create split tunnel domains resolver: %w", err)
+	}
+
+	if len(st.opts.ExcludeDomains) > 0 {
+		st.excludeWatcher = dns.NewDomainWatcher(resolver, st.opts.ExcludeDomains, st.onExcludedIPsChange)
+		st.excludeWatcher.Start()
+	}
+
+	if len(st.opts.IncludeDomains) > 0 {
+		st.includeWatcher = dns.NewDomainWatcher(resolver, st.opts.IncludeDomains, st.onIncludedIPsChange)
+		st.includeWatcher.Start()
+	}
+
+	return nil
+}
+
+// allowedIPs returns networks routed through the tunnel.
+func (st *splitTunnel) allowedIPs() []string {
+	if !st.opts.IncludesOnly() {
+		return []string{"0.0.0.0/0", "::/0"}
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	return append(append([]string{}, st.opts.IncludeCIDRs...), st.includedIPs...)
+}
+
+// notifyIncludeChange sets callback called with updated allowed IPs once included domains resolve to new addresses.
+func (st *splitTunnel) notifyIncludeChange(onChange func(allowedIPs []string)) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.onIncludeChange = onChange
+}
+
+// stop removes all applied exclusions.
+func (st *splitTunnel) stop() {
+	if st.excludeWatcher != nil {
+		st.excludeWatcher.Stop()
+	}
+	if st.includeWatcher != nil {
+		st.includeWatcher.Stop()
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.stopped = true
+	for key, remove := range st.excluded {
+		remove()
+		delete(st.excluded, key)
+	}
+	st.onIncludeChange = nil
+}
+
+// onIncludedIPsChange adds newly resolved addresses of included domains.
+// Addresses which are not resolved anymore stay routed through the tunnel until the session ends.
+func (st *splitTunnel) onIncludedIPsChange(added, _ []net.IP) {
+	st.mu.Lock()
+	for _, ip := range added {
+		ipNet := hostNet(ip)
+		st.includedIPs = append(st.includedIPs, ipNet.String())
+	}
+	onChange := st.onIncludeChange
+	st.mu.Unlock()
+
+	if onChange != nil && len(added) > 0 {
+		onChange(st.allowedIPs())
+	}
+}
+
+// onExcludedIPsChange keeps addresses of excluded domains routed directly.
+// Only IPv4 addresses are excluded, as the system default gateway and kill switch rules are IPv4 only.
+func (st *splitTunnel) onExcludedIPsChange(added, removed []net.IP) {
+	for _, ip := range added {
+		if ip.To4() == nil {
+			continue
+		}
+		if err := st.excludeNet(hostNet(ip)); err != nil {
+			log.Error().Err(err).Msgf("Failed to exclude %s from the tunnel", ip)
+		}
+	}
+
+	for _, ip := range removed {
+		ipNet := hostNet(ip)
+		st.removeExcluded(ipNet.String())
+	}
+}
+
+func (st *splitTunnel) excludeNet(ipNet net.IPNet) error {
+	if st.isExcluded(ipNet.String()) {
+		return nil
+	}
+
+	removeAllowedIPRule, err := firewall.AllowIPAccess(ipNet.String())
+	if err != nil {
+		return fmt.Errorf("could not add firewall exception for excluded network %s: %w", ipNet.String(), err)
+	}
+
+	if err := router.ExcludeNet(ipNet); err != nil {
+		removeAllowedIPRule()
+		return fmt.Errorf("could not exclude network %s from the tunnel: %w", ipNet.String(), err)
+	}
+
+	st.addExcluded(ipNet.String(), func() {
+		if err := router.RemoveExcludedNet(ipNet); err != nil {
+			log.Error().Err(err).Msgf("Failed to remove excluded network %s", ipNet.String())
+		}
+		removeAllowedIPRule()
+	})
+	return nil
+}
+
+func (st *splitTunnel) excludeApp(cgroup string) error {
+	if st.isExcluded(cgroup) {
+		return nil
+	}
+
+	removeAllowedAppRule, err := firewall.AllowAppAccess(cgroup)
+	if err != nil {
+		return fmt.Errorf("could not add firewall exception for excluded application %s: %w", cgroup, err)
+	}
+
+	if err := router.ExcludeApp(cgroup); err != nil {
+		removeAllowedAppRule()
+		return fmt.Errorf("could not exclude application %s from the tunnel: %w", cgroup, err)
+	}
+
+	st.addExcluded(cgroup, func() {
+		if err := router.RemoveExcludedApp(cgroup); err != nil {
+			log.Error().Err(err).Msgf("Failed to remove excluded application %s", cgroup)
+		}
+		removeAllowedAppRule()
+	})
+	return nil
+}
+
+func (st *splitTunnel) isExcluded(key string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	_, ok := st.excluded[key]
+	return ok
+}
+
+func (st *splitTunnel) addExcluded(key string, remove func()) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.stopped {
+		remove()
+		return
+	}
+	st.excluded[key] = remove
+}
+
+func (st *splitTunnel) removeExcluded(key string) {
+	st.mu.Lock()
+	remove, ok := st.excluded[key]
+	delete(st.excluded, key)
+	st.mu.Unlock()
+
+	if ok {
+		remove()
+	}
+}
+
+func hostNet(ip net.IP) net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
@@ -104,6 +104,9 @@ func (ce *connectionEndpoint) StartProviderMode(publicIP string, config wgcfg.De
 
 	config.IfaceName = iface
 	config.Subnet.IP = netutil.FirstIP(config.Subnet)
+	if config.DualStack() {
+		config.Subnet6.IP = netutil.FirstIP(config.Subnet6)
+	}
 	ce.cfg = config
 	ce.endpoint = net.UDPAddr{IP: net.ParseIP(publicIP), Port: config.ListenPort}
 
@@ -138,9 +141,20 @@ func (ce *connectionEndpoint) Config() (wg.ServiceConfig, error) {
 	config.Provider.Endpoint = ce.endpoint
 	config.Consumer.IPAddress = ce.cfg.Subnet
 	config.Consumer.IPAddress.IP = ce.consumerIP(ce.cfg.Subnet)
+	if ce.cfg.DualStack() {
+		config.Consumer.IPAddress6 = net.IPNet{IP: consumerIP6(ce.cfg.Subnet6), Mask: ce.cfg.Subnet6.Mask}
+	}
 	return config, nil
 }
 
+// consumerIP6 returns the second address of provider IPv6 subnet.
+func consumerIP6(subnet net.IPNet) net.IP {
+	ip := make(net.IP, len(subnet.IP))
+	copy(ip, subnet.IP.Mask(subnet.Mask))
+	ip[len(ip)-1] = byte(2)
+	return ip
+}
+
 // Stop closes wireguard client and destroys wireguard network interface.
 func (ce *connectionEndpoint) Stop() error {
 	if err := ce.wgClient.Close(); err != nil {
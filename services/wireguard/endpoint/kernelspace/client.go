@@ -39,6 +39,8 @@ type client struct {
 	iface      string
 	wgClient   *wgctrl.Client
 	dnsManager dns.Manager
+	// deleteRoutes deletes routes which are not removed together with the device.
+	deleteRoutes func() error
 }
 
 // NewWireguardClient creates new wireguard kernel space client.
@@ -59,6 +61,10 @@ func (c *client) ReConfigureDevice(config wgcfg.DeviceConfig) error {
 		return err
 	}
 
+	if config.Peer.Endpoint != nil && !config.Peer.TunnelsAll() {
+		return netutil.AddRoutes(config.IfaceName, config.Peer.AllowedIPs)
+	}
+
 	return nil
 }
 
@@ -73,10 +79,13 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 	})
 
 	if config.Peer.Endpoint != nil {
-		if err := netutil.AddDefaultRoute(config.IfaceName); err != nil {
+		if err := c.addDefaultRoute(config); err != nil {
 			rollback.Run()
 			return err
 		}
+		rollback.Push(func() {
+			_ = c.cleanRoutes()
+		})
 	}
 
 	err := c.configureDevice(config)
@@ -92,6 +101,11 @@ func (c *client) configureDevice(config wgcfg.DeviceConfig) error {
 	if err := cmdutil.SudoExec("ip", "address", "replace", "dev", config.IfaceName, config.Subnet.String()); err != nil {
 		return err
 	}
+	if config.DualStack() {
+		if err := cmdutil.SudoExec("ip", "-6", "address", "replace", "dev", config.IfaceName, config.Subnet6.String()); err != nil {
+			return err
+		}
+	}
 
 	peer, err := peerConfig(config.Peer)
 	if err != nil {
@@ -203,6 +217,9 @@ func (c *client) Close() (err error) {
 	if err := c.DestroyDevice(c.iface); err != nil {
 		errs.Add(err)
 	}
+	if err := c.cleanRoutes(); err != nil {
+		errs.Add(err)
+	}
 	if err := c.wgClient.Close(); err != nil {
 		errs.Add(err)
 	}
@@ -222,3 +239,32 @@ func stringToKey(key string) (wgtypes.Key, error) {
 	}
 	return wgtypes.NewKey(k)
 }
+
+func (c *client) addDefaultRoute(config wgcfg.DeviceConfig) error {
+	if !config.Peer.TunnelsAll() {
+		return netutil.AddRoutes(config.IfaceName, config.Peer.AllowedIPs)
+	}
+
+	if config.OuterIface != "" {
+		endpoint := config.Peer.Endpoint.IP
+		if err := netutil.AddNestedDefaultRoute(config.IfaceName, config.NestingLevel, endpoint, config.OuterIface); err != nil {
+			return err
+		}
+		c.deleteRoutes = func() error {
+			return netutil.DeleteNestedDefaultRoute(endpoint, config.OuterIface)
+		}
+		return nil
+	}
+
+	return netutil.AddDefaultRoute(config.IfaceName)
+}
+
+func (c *client) cleanRoutes() error {
+	if c.deleteRoutes == nil {
+		return nil
+	}
+
+	deleteRoutes := c.deleteRoutes
+	c.deleteRoutes = nil
+	return deleteRoutes()
+}
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 	"golang.zx2c4.com/wireguard/device"
 	"golang.zx2c4.com/wireguard/tun"
 
@@ -36,6 +37,8 @@ type client struct {
 	tun        tun.Device
 	devAPI     *device.Device
 	dnsManager dns.Manager
+	// deleteRoutes deletes routes which are not removed together with the device.
+	deleteRoutes func() error
 }
 
 // NewWireguardClient creates new wireguard user space client.
@@ -50,6 +53,12 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	if c.tun, err = CreateTUN(config.IfaceName, config.Subnet); err != nil {
 		return errors.Wrap(err, "failed to create TUN device")
 	}
+	if config.DualStack() {
+		if err := netutil.AssignIPv6(config.IfaceName, config.Subnet6); err != nil {
+			c.tun.Close()
+			return fmt.Errorf("failed to assign IPv6 address: %w", err)
+		}
+	}
 
 	devAPI := device.NewDevice(c.tun, device.NewLogger(device.LogLevelDebug, "[userspace-wg]"))
 	c.devAPI = devAPI
@@ -64,7 +73,7 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	}
 
 	if config.Peer.Endpoint != nil {
-		if err := netutil.AddDefaultRoute(config.IfaceName); err != nil {
+		if err := c.addDefaultRoute(config); err != nil {
 			rollback.Run()
 			return fmt.Errorf("could not add default route for %s: %w", config.IfaceName, err)
 		}
@@ -77,8 +86,21 @@ func (c *client) ReConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	if err = netutil.AssignIP(config.IfaceName, config.Subnet); err != nil {
 		return fmt.Errorf("failed to assign IP address: %w", err)
 	}
+	if config.DualStack() {
+		if err = netutil.AssignIPv6(config.IfaceName, config.Subnet6); err != nil {
+			return fmt.Errorf("failed to assign IPv6 address: %w", err)
+		}
+	}
+
+	if err = c.configureDevice(config); err != nil {
+		return err
+	}
 
-	return c.configureDevice(config)
+	if config.Peer.Endpoint != nil && !config.Peer.TunnelsAll() {
+		return netutil.AddRoutes(config.IfaceName, config.Peer.AllowedIPs)
+	}
+
+	return nil
 }
 
 func (c *client) configureDevice(config wgcfg.DeviceConfig) (err error) {
@@ -101,6 +123,12 @@ func (c *client) configureDevice(config wgcfg.DeviceConfig) (err error) {
 
 func (c *client) Close() error {
 	c.devAPI.Close() // c.devAPI.Close() closes c.tun too
+	if c.deleteRoutes != nil {
+		if err := c.deleteRoutes(); err != nil {
+			log.Warn().Err(err).Msg("Could not delete routes")
+		}
+		c.deleteRoutes = nil
+	}
 	if err := c.dnsManager.Clean(); err != nil {
 		return fmt.Errorf("could not clean DNS: %w", err)
 	}
@@ -129,3 +157,22 @@ func (c *client) setDeviceConfig(config string) error {
 	}
 	return nil
 }
+
+func (c *client) addDefaultRoute(config wgcfg.DeviceConfig) error {
+	if !config.Peer.TunnelsAll() {
+		return netutil.AddRoutes(config.IfaceName, config.Peer.AllowedIPs)
+	}
+
+	if config.OuterIface != "" {
+		endpoint := config.Peer.Endpoint.IP
+		if err := netutil.AddNestedDefaultRoute(config.IfaceName, config.NestingLevel, endpoint, config.OuterIface); err != nil {
+			return err
+		}
+		c.deleteRoutes = func() error {
+			return netutil.DeleteNestedDefaultRoute(endpoint, config.OuterIface)
+		}
+		return nil
+	}
+
+	return netutil.AddDefaultRoute(config.IfaceName)
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package resources
+
+import "net"
+
+// IPNet6 maps the allocated IPv4 network to /64 network of the dual-stack IPv6 subnet.
+// Subnet prefix must not be longer than /48, as the last two octets of IPv4 network become IPv6 subnet ID.
+func IPNet6(subnet6 net.IPNet, ipnet net.IPNet) net.IPNet {
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, subnet6.IP.To16())
+
+	ip4 := ipnet.IP.Mask(ipnet.Mask).To4()
+	ip[6], ip[7] = ip4[2], ip4[3]
+	return net.IPNet{IP: ip.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}
+}
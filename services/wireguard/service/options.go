@@ -19,6 +19,7 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
 
 	"github.com/rs/zerolog/log"
@@ -30,6 +31,8 @@ import (
 // Options describes options which are required to start Wireguard service.
 type Options struct {
 	Subnet net.IPNet
+	// Subnet6 is IPv6 subnet of dual-stack tunnels, IPv6 traffic is not tunnelled if it is empty.
+	Subnet6 net.IPNet
 }
 
 // DefaultOptions is a wireguard service configuration that will be used if no options provided.
@@ -38,6 +41,10 @@ var DefaultOptions = Options{
 		IP:   net.ParseIP("10.182.0.0").To4(),
 		Mask: net.IPv4Mask(255, 255, 0, 0),
 	},
+	Subnet6: net.IPNet{
+		IP:   net.ParseIP("fd00:182::"),
+		Mask: net.CIDRMask(48, 128),
+	},
 }
 
 // GetOptions returns effective Wireguard service options from application configuration.
@@ -48,11 +55,23 @@ func GetOptions() Options {
 		ipnet = &DefaultOptions.Subnet
 	}
 
+	subnet6, err := parseSubnet6(config.GetString(config.FlagWireguardListenSubnet6))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to parse IPv6 subnet option, using default value")
+		subnet6 = DefaultOptions.Subnet6
+	}
+
 	return Options{
-		Subnet: *ipnet,
+		Subnet:  *ipnet,
+		Subnet6: subnet6,
 	}
 }
 
+// IPv6Enabled checks if IPv6 traffic is tunnelled.
+func (o Options) IPv6Enabled() bool {
+	return o.Subnet6.IP != nil
+}
+
 // ParseJSONOptions function fills in Wireguard options from JSON request
 func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 	requestOptions := GetOptions()
@@ -67,17 +86,25 @@ func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
 func (o Options) MarshalJSON() ([]byte, error) {
+	var subnet6 string
+	if o.IPv6Enabled() {
+		subnet6 = o.Subnet6.String()
+	}
+
 	return json.Marshal(&struct {
-		Subnet string `json:"subnet"`
+		Subnet  string `json:"subnet"`
+		Subnet6 string `json:"subnet6"`
 	}{
-		Subnet: o.Subnet.String(),
+		Subnet:  o.Subnet.String(),
+		Subnet6: subnet6,
 	})
 }
 
 // UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
 func (o *Options) UnmarshalJSON(data []byte) error {
 	var options struct {
-		Subnet string `json:"subnet"`
+		Subnet  string  `json:"subnet"`
+		Subnet6 *string `json:"subnet6"`
 	}
 
 	if err := json.Unmarshal(data, &options); err != nil {
@@ -92,5 +119,32 @@ func (o *Options) UnmarshalJSON(data []byte) error {
 		o.Subnet = *ipnet
 	}
 
+	if options.Subnet6 != nil {
+		subnet6, err := parseSubnet6(*options.Subnet6)
+		if err != nil {
+			return err
+		}
+		o.Subnet6 = subnet6
+	}
+
 	return nil
 }
+
+// parseSubnet6 parses IPv6 subnet option, empty value disables IPv6.
+func parseSubnet6(value string) (net.IPNet, error) {
+	if value == "" {
+		return net.IPNet{}, nil
+	}
+
+	ip, ipnet, err := net.ParseCIDR(value)
+	if err != nil {
+		return net.IPNet{}, err
+	}
+	if ip.To4() != nil {
+		return net.IPNet{}, fmt.Errorf("subnet %s is not IPv6", value)
+	}
+	if ones, _ := ipnet.Mask.Size(); ones > 48 {
+		return net.IPNet{}, fmt.Errorf("IPv6 subnet %s prefix must be /48 or shorter", value)
+	}
+	return *ipnet, nil
+}
@@ -57,9 +57,34 @@ func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 			IP:   net.ParseIP("10.10.0.0").To4(),
 			Mask: net.IPv4Mask(255, 255, 0, 0),
 		},
+		Subnet6: DefaultOptions.Subnet6,
 	}, options)
 }
 
+func Test_ParseJSONOptions_IPv6Subnet(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"subnet6":"fd12:3456::/32"}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, net.IPNet{
+		IP:   net.ParseIP("fd12:3456::"),
+		Mask: net.CIDRMask(32, 128),
+	}, options.(Options).Subnet6)
+	assert.True(t, options.(Options).IPv6Enabled())
+
+	request = json.RawMessage(`{"subnet6":""}`)
+	options, err = ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.False(t, options.(Options).IPv6Enabled())
+
+	request = json.RawMessage(`{"subnet6":"fd12:3456::/64"}`)
+	_, err = ParseJSONOptions(&request)
+
+	assert.Error(t, err)
+}
+
 func configureDefaults() {
 	ctx := emptyContext()
 	config.ParseFlagsServiceWireguard(ctx)
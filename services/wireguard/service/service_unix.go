@@ -29,6 +29,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
+	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
@@ -42,6 +43,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
@@ -54,6 +56,8 @@ func NewManager(
 	options Options,
 	portSupplier port.ServicePortSupplier,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	sessions sessionFinder,
+	limiter *shaper.Limiter,
 ) *Manager {
 	resourcesAllocator := resources.NewAllocator(portSupplier, options.Subnet)
 
@@ -64,15 +68,22 @@ func NewManager(
 		natService:         natService,
 		eventBus:           eventBus,
 		trafficFirewall:    trafficFirewall,
+		sessions:           sessions,
+		limiter:            limiter,
 
 		connEndpointFactory: func() (wg.ConnectionEndpoint, error) {
 			return endpoint.NewConnectionEndpoint(resourcesAllocator)
 		},
 		country:        country,
+		subnet6:        options.Subnet6,
 		sessionCleanup: map[string]func(){},
 	}
 }
 
+type sessionFinder interface {
+	Find(id session.ID) (*service.Session, bool)
+}
+
 // Manager represents an instance of Wireguard service
 type Manager struct {
 	done        chan struct{}
@@ -83,6 +94,8 @@ type Manager struct {
 	natService      nat.NATService
 	eventBus        eventbus.EventBus
 	trafficFirewall firewall.IncomingTrafficFirewall
+	sessions        sessionFinder
+	limiter         *shaper.Limiter
 
 	dnsOK    bool
 	dnsPort  int
@@ -98,6 +111,7 @@ type Manager struct {
 
 	country    string
 	outboundIP string
+	subnet6    net.IPNet
 }
 
 // ProvideConfig provides the config for consumer and handles new WireGuard connection.
@@ -145,24 +159,59 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		config.Consumer.DNSIPs = dnsIP.String()
 	}
 
+	var vpnNetwork6 net.IPNet
+	if config.Consumer.IPAddress6.IP != nil {
+		vpnNetwork6 = net.IPNet{
+			IP:   config.Consumer.IPAddress6.IP.Mask(config.Consumer.IPAddress6.Mask),
+			Mask: config.Consumer.IPAddress6.Mask,
+		}
+	}
+
+	var releaseDestinations firewall.IncomingRuleRemove
+	if allow, deny := m.serviceInstance.Policies().DestinationRules(); len(allow) > 0 || len(deny) > 0 {
+		networks := []net.IPNet{providerConfig.Subnet}
+		if vpnNetwork6.IP != nil {
+			networks = append(networks, vpnNetwork6)
+		}
+		releaseDestinations, err = m.restrictDestinations(networks, allow, deny)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to restrict traffic destinations")
+		}
+	}
+
 	natRules, err := m.natService.Setup(nat.Options{
 		VPNNetwork:        config.Consumer.IPAddress,
+		VPNNetwork6:       vpnNetwork6,
 		DNSIP:             dnsIP,
 		ProviderExtIP:     net.ParseIP(m.outboundIP),
 		EnableDNSRedirect: m.dnsOK,
 		DNSPort:           m.dnsPort,
 	})
 	if err != nil {
+		if releaseDestinations != nil {
+			if err := releaseDestinations(); err != nil {
+				log.Warn().Err(err).Msg("failed to remove traffic destinations restriction")
+			}
+		}
 		return nil, errors.Wrap(err, "failed to setup NAT/firewall rules")
 	}
 
 	statsPublisher := newStatsPublisher(m.eventBus, time.Second)
 	go statsPublisher.start(sessionID, conn)
 
-	ifaceName := conn.InterfaceName()
-	s := shaper.New(m.eventBus)
-	err = s.Start(ifaceName)
-	if err != nil {
+	shapedSession := shaper.Session{
+		ID:        sessionID,
+		Interface: conn.InterfaceName(),
+		IPs:       []net.IP{config.Consumer.IPAddress.IP},
+		Policies:  m.serviceInstance.Policies(),
+	}
+	if config.Consumer.IPAddress6.IP != nil {
+		shapedSession.IPs = append(shapedSession.IPs, config.Consumer.IPAddress6.IP)
+	}
+	if sess, ok := m.sessions.Find(session.ID(sessionID)); ok {
+		shapedSession.ConsumerID = sess.ConsumerID
+	}
+	if err := m.limiter.Start(shapedSession); err != nil {
 		log.Error().Err(err).Msg("Could not start traffic shaper")
 	}
 
@@ -179,7 +228,7 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 
 		statsPublisher.stop()
 
-		s.Clear(ifaceName)
+		m.limiter.Stop(sessionID)
 
 		if releaseTrafficFirewall != nil {
 			if err := releaseTrafficFirewall(); err != nil {
@@ -187,6 +236,12 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 			}
 		}
 
+		if releaseDestinations != nil {
+			if err := releaseDestinations(); err != nil {
+				log.Warn().Err(err).Msg("failed to remove traffic destinations restriction")
+			}
+		}
+
 		log.Trace().Msg("Deleting nat rules")
 		if err := m.natService.Del(natRules); err != nil {
 			log.Error().Err(err).Msg("Failed to delete NAT rules")
@@ -206,7 +261,35 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 	m.sessionCleanup[sessionID] = destroy
 	m.sessionCleanupMu.Unlock()
 
-	return &service.ConfigParams{SessionServiceConfig: config, SessionDestroyCallback: destroy}, nil
+	return &service.ConfigParams{
+		SessionServiceConfig:   config,
+		SessionDestroyCallback: destroy,
+		SessionTunnelIPs:       shapedSession.IPs,
+	}, nil
+}
+
+// restrictDestinations restricts traffic destinations of every consumer network, rules are released together.
+func (m *Manager) restrictDestinations(networks []net.IPNet, allow, deny []firewall.DestinationRule) (firewall.IncomingRuleRemove, error) {
+	var removers []firewall.IncomingRuleRemove
+	removeAll := func() error {
+		var lastErr error
+		for _, remove := range removers {
+			if err := remove(); err != nil {
+				lastErr = err
+			}
+		}
+		return lastErr
+	}
+
+	for _, network := range networks {
+		remove, err := m.trafficFirewall.RestrictDestinations(network, allow, deny)
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+		removers = append(removers, remove)
+	}
+	return removeAll, nil
 }
 
 func (m *Manager) createProviderConfig(listenPort int, peerPublicKey string) (wgcfg.DeviceConfig, error) {
@@ -220,9 +303,15 @@ func (m *Manager) createProviderConfig(listenPort int, peerPublicKey string) (wg
 		return wgcfg.DeviceConfig{}, fmt.Errorf("could not generate private key: %w", err)
 	}
 
+	var network6 net.IPNet
+	if m.subnet6.IP != nil {
+		network6 = resources.IPNet6(m.subnet6, network)
+	}
+
 	return wgcfg.DeviceConfig{
 		IfaceName:  "", // Interface name will be generated by connection endpoint.
 		Subnet:     network,
+		Subnet6:    network6,
 		PrivateKey: privateKey,
 		ListenPort: listenPort,
 		DNS:        nil,
@@ -264,9 +353,12 @@ func (m *Manager) Serve(instance *service.Instance) error {
 	// Start DNS proxy.
 	m.dnsPort = 11253
 	m.dnsOK = false
-	dnsHandler, err := dns.ResolveViaSystem()
+	dnsHandler, err := dns.NewResolver(dns.ResolverOptions{
+		Upstreams: config.GetStringSlice(config.FlagDNSUpstreams),
+		CacheSize: config.GetInt(config.FlagDNSCacheSize),
+	})
 	if err == nil {
-		if m.serviceInstance.Policies().HasDNSRules() {
+		if policies := m.serviceInstance.Policies(); policies.HasDNSRules() || policies.HasDNSDenyRules() {
 			dnsHandler = dns.WhitelistAnswers(dnsHandler, m.trafficFirewall, instance.Policies())
 		}
 
@@ -26,10 +26,12 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/nat"
 	natevent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/session"
 )
 
 // NATEventGetter allows us to fetch the last known NAT event
@@ -46,10 +48,16 @@ func NewManager(
 	options Options,
 	portSupplier port.ServicePortSupplier,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	sessions sessionFinder,
+	limiter *shaper.Limiter,
 ) *Manager {
 	return &Manager{}
 }
 
+type sessionFinder interface {
+	Find(id session.ID) (*service.Session, bool)
+}
+
 // Manager represents an instance of Wireguard service
 type Manager struct{}
 
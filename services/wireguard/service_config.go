@@ -38,7 +38,9 @@ type ServiceConfig struct {
 	}
 	Consumer struct {
 		IPAddress net.IPNet
-		DNSIPs    string
+		// IPAddress6 is set by dual-stack providers only.
+		IPAddress6 net.IPNet
+		DNSIPs     string
 	}
 }
 
@@ -57,8 +59,14 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 		Endpoint  string `json:"endpoint"`
 	}
 	type consumer struct {
-		IPAddress string `json:"ip_address"`
-		DNSIPs    string `json:"dns_ips"`
+		IPAddress  string `json:"ip_address"`
+		IPAddress6 string `json:"ip_address6,omitempty"`
+		DNSIPs     string `json:"dns_ips"`
+	}
+
+	var ipAddress6 string
+	if s.Consumer.IPAddress6.IP != nil {
+		ipAddress6 = s.Consumer.IPAddress6.String()
 	}
 
 	return json.Marshal(&struct {
@@ -76,8 +84,9 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 			Endpoint:  s.Provider.Endpoint.String(),
 		},
 		Consumer: consumer{
-			IPAddress: s.Consumer.IPAddress.String(),
-			DNSIPs:    s.Consumer.DNSIPs,
+			IPAddress:  s.Consumer.IPAddress.String(),
+			IPAddress6: ipAddress6,
+			DNSIPs:     s.Consumer.DNSIPs,
 		},
 	})
 }
@@ -89,8 +98,9 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 		Endpoint  string `json:"endpoint"`
 	}
 	type consumer struct {
-		IPAddress string `json:"ip_address"`
-		DNSIPs    string `json:"dns_ips"`
+		IPAddress  string `json:"ip_address"`
+		IPAddress6 string `json:"ip_address6,omitempty"`
+		DNSIPs     string `json:"dns_ips"`
 	}
 	var config struct {
 		LocalPort  int      `json:"local_port"`
@@ -122,5 +132,14 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 	s.Consumer.IPAddress = *ipnet
 	s.Consumer.IPAddress.IP = ip
 
+	if config.Consumer.IPAddress6 != "" {
+		ip6, ipnet6, err := net.ParseCIDR(config.Consumer.IPAddress6)
+		if err != nil {
+			return err
+		}
+		s.Consumer.IPAddress6 = *ipnet6
+		s.Consumer.IPAddress6.IP = ip6
+	}
+
 	return nil
 }
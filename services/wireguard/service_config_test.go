@@ -38,8 +38,9 @@ func TestServiceConfig_MarshalJSON(t *testing.T) {
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
-			IPAddress net.IPNet
-			DNSIPs    string
+			IPAddress  net.IPNet
+			IPAddress6 net.IPNet
+			DNSIPs     string
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -72,8 +73,9 @@ func TestServiceConfig_UnmarshalJSON(t *testing.T) {
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
-			IPAddress net.IPNet
-			DNSIPs    string
+			IPAddress  net.IPNet
+			IPAddress6 net.IPNet
+			DNSIPs     string
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -89,3 +91,17 @@ func TestServiceConfig_UnmarshalJSON(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expecteConfig, actualConfig)
 }
+
+func TestServiceConfig_UnmarshalJSON_DualStack(t *testing.T) {
+	configJSON := json.RawMessage(`{"provider":{"public_key":"wg1","endpoint":"127.0.0.1:51001"},"consumer":{"ip_address":"10.182.1.2/24","ip_address6":"fd00:182:0:100::2/64","dns_ips":"10.182.1.1"}}`)
+
+	var config ServiceConfig
+	err := json.Unmarshal(configJSON, &config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, net.IPNet{IP: net.ParseIP("fd00:182:0:100::2"), Mask: net.CIDRMask(64, 128)}, config.Consumer.IPAddress6)
+
+	configBytes, err := json.Marshal(config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(configBytes), `"ip_address6":"fd00:182:0:100::2/64"`)
+}
@@ -40,11 +40,16 @@ type Stats struct {
 type DeviceConfig struct {
 	IfaceName  string    `json:"iface_name"`
 	Subnet     net.IPNet `json:"subnet"`
+	Subnet6    net.IPNet `json:"subnet6"` // Set for dual-stack tunnels only.
 	PrivateKey string    `json:"private_key"`
 	ListenPort int       `json:"listen_port"`
 	DNS        []string  `json:"dns"`
 	// Used only for unix.
 	DNSScriptDir string `json:"dns_script_dir"`
+	// OuterIface is set for the nested hops of multi-hop connection, peer traffic is routed through it.
+	OuterIface string `json:"outer_iface,omitempty"`
+	// NestingLevel is the count of hop tunnels the nested hop is routed through.
+	NestingLevel int `json:"nesting_level,omitempty"`
 
 	Peer         Peer `json:"peer"`
 	ReplacePeers bool `json:"replace_peers,omitempty"`
@@ -62,10 +67,13 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 	type deviceConfig struct {
 		IfaceName    string   `json:"iface_name"`
 		Subnet       string   `json:"subnet"`
+		Subnet6      string   `json:"subnet6,omitempty"`
 		PrivateKey   string   `json:"private_key"`
 		ListenPort   int      `json:"listen_port"`
 		DNS          []string `json:"dns"`
 		DNSScriptDir string   `json:"dns_script_dir"`
+		OuterIface   string   `json:"outer_iface,omitempty"`
+		NestingLevel int      `json:"nesting_level,omitempty"`
 		Peer         peer     `json:"peer"`
 		ReplacePeers bool     `json:"replace_peers,omitempty"`
 	}
@@ -75,13 +83,21 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 		peerEndpoint = dc.Peer.Endpoint.String()
 	}
 
+	var subnet6 string
+	if dc.DualStack() {
+		subnet6 = dc.Subnet6.String()
+	}
+
 	return json.Marshal(&deviceConfig{
 		IfaceName:    dc.IfaceName,
 		Subnet:       dc.Subnet.String(),
+		Subnet6:      subnet6,
 		PrivateKey:   dc.PrivateKey,
 		ListenPort:   dc.ListenPort,
 		DNS:          dc.DNS,
 		DNSScriptDir: dc.DNSScriptDir,
+		OuterIface:   dc.OuterIface,
+		NestingLevel: dc.NestingLevel,
 		Peer: peer{
 			PublicKey:              dc.Peer.PublicKey,
 			Endpoint:               peerEndpoint,
@@ -104,10 +120,13 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	type deviceConfig struct {
 		IfaceName    string   `json:"iface_name"`
 		Subnet       string   `json:"subnet"`
+		Subnet6      string   `json:"subnet6,omitempty"`
 		PrivateKey   string   `json:"private_key"`
 		ListenPort   int      `json:"listen_port"`
 		DNS          []string `json:"dns"`
 		DNSScriptDir string   `json:"dns_script_dir"`
+		OuterIface   string   `json:"outer_iface,omitempty"`
+		NestingLevel int      `json:"nesting_level,omitempty"`
 		Peer         peer     `json:"peer"`
 		ReplacePeers bool     `json:"replace_peers,omitempty"`
 	}
@@ -123,6 +142,16 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("could not parse subnet: %w", err)
 	}
 
+	var subnet6 net.IPNet
+	if cfg.Subnet6 != "" {
+		ip6, ipnet6, err := net.ParseCIDR(cfg.Subnet6)
+		if err != nil {
+			return fmt.Errorf("could not parse IPv6 subnet: %w", err)
+		}
+		subnet6 = *ipnet6
+		subnet6.IP = ip6
+	}
+
 	var peerEndpoint *net.UDPAddr
 	if cfg.Peer.Endpoint != "" {
 		peerEndpoint, err = net.ResolveUDPAddr("udp", cfg.Peer.Endpoint)
@@ -134,10 +163,13 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	dc.IfaceName = cfg.IfaceName
 	dc.Subnet = *ipnet
 	dc.Subnet.IP = ip
+	dc.Subnet6 = subnet6
 	dc.PrivateKey = cfg.PrivateKey
 	dc.ListenPort = cfg.ListenPort
 	dc.DNS = cfg.DNS
 	dc.DNSScriptDir = cfg.DNSScriptDir
+	dc.OuterIface = cfg.OuterIface
+	dc.NestingLevel = cfg.NestingLevel
 	dc.Peer = Peer{
 		PublicKey:              cfg.Peer.PublicKey,
 		Endpoint:               peerEndpoint,
@@ -149,6 +181,11 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// DualStack checks if IPv6 traffic is tunnelled too.
+func (dc *DeviceConfig) DualStack() bool {
+	return dc.Subnet6.IP != nil
+}
+
 // Encode encodes device config into string representation which is used for
 // userspace and kernel space wireguard configuration.
 func (dc *DeviceConfig) Encode() string {
@@ -179,6 +216,16 @@ type Peer struct {
 	KeepAlivePeriodSeconds int          `json:"keep_alive_period_seconds"`
 }
 
+// TunnelsAll checks if all IPv4 traffic is allowed through the peer, otherwise only allowed IPs are routed.
+func (p *Peer) TunnelsAll() bool {
+	for _, ip := range p.AllowedIPs {
+		if ip == "0.0.0.0/0" {
+			return true
+		}
+	}
+	return false
+}
+
 // Encode encodes device peer config into string representation which is used for
 // userspace and kernel space wireguard configuration.
 func (p *Peer) Encode() string {
@@ -151,6 +151,22 @@ func TestDeviceConfig_UnmarshalJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "Test unmarshal dual-stack values",
+			config: `{"iface_name":"myst0","subnet":"10.0.182.2/24","subnet6":"fd00:182::2/64","private_key":"DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=","listen_port":53511,"peer":{"public_key":"DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=","allowed_i_ps":["0.0.0.0/0","::/0"],"keep_alive_period_seconds":20}}`,
+			expected: DeviceConfig{
+				IfaceName:  "myst0",
+				Subnet:     net.IPNet{IP: net.ParseIP("10.0.182.2"), Mask: net.IPv4Mask(255, 255, 255, 0)},
+				Subnet6:    net.IPNet{IP: net.ParseIP("fd00:182::2"), Mask: net.CIDRMask(64, 128)},
+				PrivateKey: "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+				ListenPort: 53511,
+				Peer: Peer{
+					PublicKey:              "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+					AllowedIPs:             []string{"0.0.0.0/0", "::/0"},
+					KeepAlivePeriodSeconds: 20,
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -104,6 +104,7 @@ type InvoicePayerDeps struct {
 	HermesAddress             common.Address
 	DataLeeway                datasize.BitSize
 	ChainID                   int64
+	SpendingLimits            connectionstate.SpendingLimits
 }
 
 // NewInvoicePayer returns a new instance of exchange message tracker.
@@ -149,6 +150,11 @@ func (ip *InvoicePayer) Start() error {
 				return errors.Wrap(err, "invoice not valid")
 			}
 
+			err = ip.isWithinLimits(invoice)
+			if err != nil {
+				return errors.Wrap(err, "invoice refused")
+			}
+
 			err = ip.issueExchangeMessage(invoice)
 			if err != nil {
 				return err
@@ -198,6 +204,12 @@ func (ip *InvoicePayer) isInvoiceOK(invoice crypto.Invoice) error {
 	return nil
 }
 
+// isWithinLimits checks that paying the invoice doesn't exceed the session spending limits.
+func (ip *InvoicePayer) isWithinLimits(invoice crypto.Invoice) error {
+	transferred := ip.getDataTransferred()
+	return ip.deps.SpendingLimits.Check(ip.deps.TimeTracker.Elapsed(), invoice.AgreementTotal, transferred.sum())
+}
+
 func estimateInvoiceTolerance(elapsed time.Duration, transferred DataTransferred) float64 {
 	if elapsed.Seconds() < 1 {
 		return 3
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
@@ -346,6 +347,54 @@ func TestInvoicePayer_isInvoiceOK(t *testing.T) {
 	}
 }
 
+func TestInvoicePayer_isWithinLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		limits      connectionstate.SpendingLimits
+		transferred DataTransferred
+		invoice     crypto.Invoice
+		wantErr     bool
+	}{
+		{
+			name:    "accepts invoice without limits",
+			invoice: crypto.Invoice{AgreementTotal: big.NewInt(1000)},
+			wantErr: false,
+		},
+		{
+			name:    "accepts invoice within session limit",
+			limits:  connectionstate.SpendingLimits{MaxSessionSpend: big.NewInt(1000)},
+			invoice: crypto.Invoice{AgreementTotal: big.NewInt(1000)},
+			wantErr: false,
+		},
+		{
+			name:    "refuses invoice over session limit",
+			limits:  connectionstate.SpendingLimits{MaxSessionSpend: big.NewInt(1000)},
+			invoice: crypto.Invoice{AgreementTotal: big.NewInt(1001)},
+			wantErr: true,
+		},
+		{
+			name:        "refuses invoice over traffic limit",
+			limits:      connectionstate.SpendingLimits{MaxTraffic: 1000},
+			transferred: DataTransferred{Up: 600, Down: 600},
+			invoice:     crypto.Invoice{AgreementTotal: big.NewInt(1)},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emt := &InvoicePayer{
+				deps: InvoicePayerDeps{
+					TimeTracker:    &mockTimeTracker{timeToReturn: time.Minute},
+					SpendingLimits: tt.limits,
+				},
+				dataTransferred: tt.transferred,
+			}
+			err := emt.isWithinLimits(tt.invoice)
+			assert.Equal(t, tt.wantErr, errors.Is(err, connectionstate.ErrSpendingLimitReached))
+		})
+	}
+}
+
 func TestInvoicePayer_incrementGrandTotalPromised(t *testing.T) {
 	type fields struct {
 		consumerTotalsStorage *mockConsumerTotalsStorage
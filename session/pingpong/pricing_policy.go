@@ -0,0 +1,280 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// ScheduleRule multiplies the price during a daily time window.
+// Windows ending before they start wrap around midnight.
+type ScheduleRule struct {
+	From   time.Duration
+	To     time.Duration
+	Factor float64
+}
+
+func (sr ScheduleRule) matches(t time.Time) bool {
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if sr.From <= sr.To {
+		return offset >= sr.From && offset < sr.To
+	}
+	return offset >= sr.From || offset < sr.To
+}
+
+// LoadRule multiplies the price once the count of active sessions reaches the threshold.
+type LoadRule struct {
+	Sessions int
+	Factor   float64
+}
+
+// DiscountRule multiplies the price for a consumer identity or for consumers explicitly allowed by an access policy.
+type DiscountRule struct {
+	Identity string
+	PolicyID string
+	Factor   float64
+}
+
+func (dr DiscountRule) matches(consumerID identity.Identity, policyIDs []string) bool {
+	if dr.Identity != "" {
+		return strings.EqualFold(dr.Identity, consumerID.Address)
+	}
+	for _, id := range policyIDs {
+		if id == dr.PolicyID {
+			return true
+		}
+	}
+	return false
+}
+
+// PricingRules holds the rules of provider pricing policy.
+type PricingRules struct {
+	Schedule  []ScheduleRule
+	Load      []LoadRule
+	Discounts []DiscountRule
+}
+
+// Empty tells whether there are no rules to apply.
+func (pr PricingRules) Empty() bool {
+	return len(pr.Schedule) == 0 && len(pr.Load) == 0 && len(pr.Discounts) == 0
+}
+
+// ParsePricingRules parses pricing rules given as "<key>=<factor>" pairs.
+// Schedule keys are daily windows such as "22:00-06:00", load keys are active session counts
+// and discount keys are either consumer identities or access policy IDs.
+func ParsePricingRules(schedule, load, discounts []string) (PricingRules, error) {
+	var rules PricingRules
+	for _, spec := range schedule {
+		key, factor, err := parseFactor(spec)
+		if err != nil {
+			return rules, err
+		}
+		bounds := strings.SplitN(key, "-", 2)
+		if len(bounds) != 2 {
+			return rules, fmt.Errorf("invalid schedule window %q, expected <from>-<to>", key)
+		}
+		from, err := parseDayTime(bounds[0])
+		if err != nil {
+			return rules, err
+		}
+		to, err := parseDayTime(bounds[1])
+		if err != nil {
+			return rules, err
+		}
+		rules.Schedule = append(rules.Schedule, ScheduleRule{From: from, To: to, Factor: factor})
+	}
+
+	for _, spec := range load {
+		key, factor, err := parseFactor(spec)
+		if err != nil {
+			return rules, err
+		}
+		sessions, err := strconv.Atoi(key)
+		if err != nil || sessions < 1 {
+			return rules, fmt.Errorf("invalid session count %q", key)
+		}
+		if factor < 1 {
+			return rules, fmt.Errorf("load factor %v must not be lower than 1", factor)
+		}
+		rules.Load = append(rules.Load, LoadRule{Sessions: sessions, Factor: factor})
+	}
+
+	for _, spec := range discounts {
+		key, factor, err := parseFactor(spec)
+		if err != nil {
+			return rules, err
+		}
+		if factor > 1 {
+			return rules, fmt.Errorf("discount factor %v must not be greater than 1", factor)
+		}
+		rule := DiscountRule{PolicyID: key, Factor: factor}
+		if strings.HasPrefix(key, "0x") {
+			rule = DiscountRule{Identity: key, Factor: factor}
+		}
+		rules.Discounts = append(rules.Discounts, rule)
+	}
+
+	return rules, nil
+}
+
+func parseFactor(spec string) (string, float64, error) {
+	parts := strings.SplitN(spec, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", 0, fmt.Errorf("invalid pricing rule %q, expected <key>=<factor>", spec)
+	}
+	factor, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || factor <= 0 {
+		return "", 0, fmt.Errorf("invalid price factor %q", parts[1])
+	}
+	return strings.TrimSpace(parts[0]), factor, nil
+}
+
+func parseDayTime(in string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(in))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", in)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+type priceProvider interface {
+	GetCurrentPrice(nodeType string, country string) (market.Price, error)
+	IsPriceValid(in market.Price, nodeType string, country string) bool
+}
+
+type sessionCounter interface {
+	Count() int
+}
+
+// PricingPolicy adjusts the discovery price by time of day, load and consumer,
+// keeping the result within the prices accepted by the pricer.
+type PricingPolicy struct {
+	pricer   priceProvider
+	sessions sessionCounter
+	rules    PricingRules
+	now      func() time.Time
+}
+
+// NewPricingPolicy creates a new instance of pricing policy.
+func NewPricingPolicy(pricer priceProvider, sessions sessionCounter, rules PricingRules) *PricingPolicy {
+	return &PricingPolicy{
+		pricer:   pricer,
+		sessions: sessions,
+		rules:    rules,
+		now:      time.Now,
+	}
+}
+
+// Price returns the price advertised to all consumers.
+func (pp *PricingPolicy) Price(nodeType string, country string) market.Price {
+	base := pp.basePrice(nodeType, country)
+
+	factor := 1.0
+	now := pp.now()
+	for _, rule := range pp.rules.Schedule {
+		if rule.matches(now) {
+			factor *= rule.Factor
+			break
+		}
+	}
+
+	sessions := pp.sessions.Count()
+	surcharge := LoadRule{Factor: 1}
+	for _, rule := range pp.rules.Load {
+		if sessions >= rule.Sessions && rule.Sessions > surcharge.Sessions {
+			surcharge = rule
+		}
+	}
+	factor *= surcharge.Factor
+
+	return pp.clamp(multiplyPrice(base, factor), base, nodeType, country)
+}
+
+// PriceFor returns the price charged to the given consumer, who is explicitly allowed by the given access policies.
+func (pp *PricingPolicy) PriceFor(consumerID identity.Identity, policyIDs []string, nodeType string, country string) market.Price {
+	price := pp.Price(nodeType, country)
+
+	discount := 1.0
+	for _, rule := range pp.rules.Discounts {
+		if rule.matches(consumerID, policyIDs) && rule.Factor < discount {
+			discount = rule.Factor
+		}
+	}
+	if discount == 1 {
+		return price
+	}
+
+	return pp.clamp(multiplyPrice(price, discount), pp.basePrice(nodeType, country), nodeType, country)
+}
+
+func (pp *PricingPolicy) basePrice(nodeType string, country string) market.Price {
+	price, err := pp.pricer.GetCurrentPrice(nodeType, country)
+	if err != nil || price.PricePerGiB == nil || price.PricePerHour == nil {
+		log.Warn().Err(err).Msg("Could not get current price, using default price")
+		return defaultPrice
+	}
+	return price
+}
+
+// clamp returns the highest price accepted by the pricer which does not exceed the given one.
+func (pp *PricingPolicy) clamp(in, base market.Price, nodeType string, country string) market.Price {
+	if pp.pricer.IsPriceValid(in, nodeType, country) {
+		return in
+	}
+
+	capped := market.Price{
+		PricePerHour: minBig(in.PricePerHour, defaultPrice.PricePerHour),
+		PricePerGiB:  minBig(in.PricePerGiB, defaultPrice.PricePerGiB),
+	}
+	if isPriceAtMost(base, in) && !isPriceAtMost(base, capped) && pp.pricer.IsPriceValid(base, nodeType, country) {
+		return base
+	}
+	return capped
+}
+
+func multiplyPrice(in market.Price, factor float64) market.Price {
+	return market.Price{
+		PricePerHour: multiplyBig(in.PricePerHour, factor),
+		PricePerGiB:  multiplyBig(in.PricePerGiB, factor),
+	}
+}
+
+func multiplyBig(in *big.Int, factor float64) *big.Int {
+	res, _ := new(big.Float).Mul(new(big.Float).SetInt(in), big.NewFloat(factor)).Int(nil)
+	return res
+}
+
+func minBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) <= 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
+
+func isPriceAtMost(in, max market.Price) bool {
+	return in.PricePerHour.Cmp(max.PricePerHour) <= 0 && in.PricePerGiB.Cmp(max.PricePerGiB) <= 0
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func TestParsePricingRules(t *testing.T) {
+	rules, err := ParsePricingRules(
+		[]string{"22:00-06:00=0.5"},
+		[]string{"10=1.5"},
+		[]string{"0xdead=0.8", "verified-traffic=0.9"},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, PricingRules{
+		Schedule:  []ScheduleRule{{From: 22 * time.Hour, To: 6 * time.Hour, Factor: 0.5}},
+		Load:      []LoadRule{{Sessions: 10, Factor: 1.5}},
+		Discounts: []DiscountRule{{Identity: "0xdead", Factor: 0.8}, {PolicyID: "verified-traffic", Factor: 0.9}},
+	}, rules)
+
+	for name, tc := range map[string]struct {
+		schedule, load, discounts []string
+	}{
+		"missing factor":       {schedule: []string{"22:00-06:00"}},
+		"invalid window":       {schedule: []string{"22:00=0.5"}},
+		"invalid time":         {schedule: []string{"25:00-06:00=0.5"}},
+		"invalid session load": {load: []string{"many=1.5"}},
+		"load discount":        {load: []string{"10=0.5"}},
+		"discount surcharge":   {discounts: []string{"0xdead=1.5"}},
+		"negative factor":      {discounts: []string{"0xdead=-1"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParsePricingRules(tc.schedule, tc.load, tc.discounts)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPricingPolicy_Price(t *testing.T) {
+	base := market.Price{PricePerHour: big.NewInt(1000), PricePerGiB: big.NewInt(2000)}
+	rules := PricingRules{
+		Schedule: []ScheduleRule{{From: 22 * time.Hour, To: 6 * time.Hour, Factor: 0.5}},
+		Load:     []LoadRule{{Sessions: 5, Factor: 1.2}, {Sessions: 10, Factor: 1.5}},
+	}
+
+	for name, tc := range map[string]struct {
+		now      time.Time
+		sessions int
+		expected market.Price
+	}{
+		"base price": {
+			now:      time.Date(2021, 1, 1, 12, 0, 0, 0, time.Local),
+			expected: base,
+		},
+		"night schedule wraps midnight": {
+			now:      time.Date(2021, 1, 1, 3, 0, 0, 0, time.Local),
+			expected: market.Price{PricePerHour: big.NewInt(500), PricePerGiB: big.NewInt(1000)},
+		},
+		"highest reached load applies": {
+			now:      time.Date(2021, 1, 1, 23, 0, 0, 0, time.Local),
+			sessions: 12,
+			expected: market.Price{PricePerHour: big.NewInt(750), PricePerGiB: big.NewInt(1500)},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			policy := NewPricingPolicy(&mockPriceProvider{current: base}, &mockSessionCounter{count: tc.sessions}, rules)
+			policy.now = func() time.Time { return tc.now }
+
+			assert.Equal(t, tc.expected, policy.Price("residential", "LT"))
+		})
+	}
+}
+
+func TestPricingPolicy_Price_StaysWithinValidPrices(t *testing.T) {
+	rules := PricingRules{Load: []LoadRule{{Sessions: 1, Factor: 2}}}
+
+	policy := NewPricingPolicy(&mockPriceProvider{current: market.Price{PricePerHour: big.NewInt(1000), PricePerGiB: big.NewInt(2000)}}, &mockSessionCounter{count: 1}, rules)
+	assert.Equal(t, market.Price{PricePerHour: big.NewInt(2000), PricePerGiB: big.NewInt(4000)}, policy.Price("residential", "LT"))
+
+	expensive := market.Price{
+		PricePerHour: new(big.Int).Add(defaultPrice.PricePerHour, big.NewInt(1)),
+		PricePerGiB:  new(big.Int).Add(defaultPrice.PricePerGiB, big.NewInt(1)),
+	}
+	policy = NewPricingPolicy(&mockPriceProvider{current: expensive}, &mockSessionCounter{count: 1}, rules)
+	assert.Equal(t, expensive, policy.Price("residential", "LT"))
+}
+
+func TestPricingPolicy_PriceFor(t *testing.T) {
+	base := market.Price{PricePerHour: big.NewInt(1000), PricePerGiB: big.NewInt(2000)}
+	rules := PricingRules{
+		Discounts: []DiscountRule{
+			{Identity: "0xDEAD", Factor: 0.8},
+			{PolicyID: "verified-traffic", Factor: 0.5},
+		},
+	}
+	policy := NewPricingPolicy(&mockPriceProvider{current: base}, &mockSessionCounter{}, rules)
+
+	assert.Equal(t, base, policy.PriceFor(identity.FromAddress("0xbeef"), nil, "residential", "LT"))
+	assert.Equal(
+		t,
+		market.Price{PricePerHour: big.NewInt(800), PricePerGiB: big.NewInt(1600)},
+		policy.PriceFor(identity.FromAddress("0xdead"), nil, "residential", "LT"),
+	)
+	assert.Equal(
+		t,
+		market.Price{PricePerHour: big.NewInt(500), PricePerGiB: big.NewInt(1000)},
+		policy.PriceFor(identity.FromAddress("0xdead"), []string{"verified-traffic"}, "residential", "LT"),
+	)
+}
+
+type mockPriceProvider struct {
+	current market.Price
+}
+
+func (mpp *mockPriceProvider) GetCurrentPrice(nodeType string, country string) (market.Price, error) {
+	return mpp.current, nil
+}
+
+func (mpp *mockPriceProvider) IsPriceValid(in market.Price, nodeType string, country string) bool {
+	if in.PricePerHour.Cmp(mpp.current.PricePerHour) == 0 && in.PricePerGiB.Cmp(mpp.current.PricePerGiB) == 0 {
+		return true
+	}
+	return in.PricePerHour.Cmp(defaultPrice.PricePerHour) <= 0 && in.PricePerGiB.Cmp(defaultPrice.PricePerGiB) <= 0
+}
+
+type mockSessionCounter struct {
+	count int
+}
+
+func (msc *mockSessionCounter) Count() int {
+	return msc.count
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import "errors"
+
+// RejectionCode tells consumer why provider refused to create the session.
+type RejectionCode string
+
+const (
+	// RejectionConsumerBanned is sent when consumer identity is banned by the provider.
+	RejectionConsumerBanned = RejectionCode("consumer_banned")
+	// RejectionCapacityExceeded is sent when service serves max concurrent sessions.
+	RejectionCapacityExceeded = RejectionCode("capacity_exceeded")
+	// RejectionConsumerLimitExceeded is sent when consumer has max concurrent sessions with the provider.
+	RejectionConsumerLimitExceeded = RejectionCode("consumer_limit_exceeded")
+	// RejectionResourcesExhausted is sent when provider lacks CPU, memory or bandwidth headroom.
+	RejectionResourcesExhausted = RejectionCode("resources_exhausted")
+	// RejectionServiceDraining is sent when service is being drained before it stops.
+	RejectionServiceDraining = RejectionCode("service_draining")
+)
+
+// Temporary tells if the rejection is caused by provider load or maintenance, so consumer should try another provider.
+func (c RejectionCode) Temporary() bool {
+	switch c {
+	case RejectionCapacityExceeded, RejectionConsumerLimitExceeded, RejectionResourcesExhausted, RejectionServiceDraining:
+		return true
+	default:
+		return false
+	}
+}
+
+// RejectionError represents provider refusal to create the session.
+type RejectionError struct {
+	Code    RejectionCode
+	Message string
+}
+
+// NewRejection creates rejection error with given code.
+func NewRejection(code RejectionCode, message string) *RejectionError {
+	return &RejectionError{Code: code, Message: message}
+}
+
+// Error returns rejection message.
+func (e *RejectionError) Error() string {
+	return e.Message
+}
+
+// IsTemporaryRejection checks whether the error is a rejection caused by provider load.
+func IsTemporaryRejection(err error) bool {
+	var rejection *RejectionError
+	return errors.As(err, &rejection) && rejection.Code.Temporary()
+}
@@ -29,4 +29,6 @@ const (
 	commandDiscoverGateway  = "discover-gateway"
 	commandExcludeRoute     = "exclude-route"
 	commandDeleteRoute      = "delete-route"
+	commandExcludeApp       = "exclude-app"
+	commandDeleteApp        = "delete-app"
 )
@@ -131,6 +131,20 @@ func (d *Daemon) dialog(conn io.ReadWriter) {
 			} else {
 				answer.ok()
 			}
+		case commandExcludeApp:
+			if err := d.excludeApp(cmd...); err != nil {
+				log.Err(err).Msgf("%s failed", commandExcludeApp)
+				answer.err(err)
+			} else {
+				answer.ok()
+			}
+		case commandDeleteApp:
+			if err := d.deleteApp(cmd...); err != nil {
+				log.Err(err).Msgf("%s failed", commandDeleteApp)
+				answer.err(err)
+			} else {
+				answer.ok()
+			}
 		}
 	}
 }
@@ -138,7 +152,7 @@ func (d *Daemon) dialog(conn io.ReadWriter) {
 func (d *Daemon) excludeRoute(args ...string) error {
 	flags := flag.NewFlagSet("", flag.ContinueOnError)
 
-	ip := flags.String("ip", "", "Destination IP address")
+	ip := flags.String("ip", "", "Destination IP address or network")
 	gw := flags.String("gw", "", "Gateway")
 
 	if err := flags.Parse(args[1:]); err != nil {
@@ -152,17 +166,21 @@ func (d *Daemon) excludeRoute(args ...string) error {
 		return errors.New("-gw is required")
 	}
 
-	ipAddr := net.ParseIP(*ip)
 	gwAddr := net.ParseIP(*gw)
 
 	t := &network.RoutingTable{}
+	if _, ipNet, err := net.ParseCIDR(*ip); err == nil {
+		return t.ExcludeNetRule(*ipNet, gwAddr)
+	}
+
+	ipAddr := net.ParseIP(*ip)
 	return t.ExcludeRule(ipAddr, gwAddr)
 }
 
 func (d *Daemon) deleteRoute(args ...string) error {
 	flags := flag.NewFlagSet("", flag.ContinueOnError)
 
-	ip := flags.String("ip", "", "Destination IP address")
+	ip := flags.String("ip", "", "Destination IP address or network")
 	gw := flags.String("gw", "", "Gateway")
 
 	if err := flags.Parse(args[1:]); err != nil {
@@ -176,13 +194,57 @@ func (d *Daemon) deleteRoute(args ...string) error {
 		return errors.New("-gw is required")
 	}
 
-	ipAddr := net.ParseIP(*ip)
 	gwAddr := net.ParseIP(*gw)
 
 	t := &network.RoutingTable{}
+	if _, ipNet, err := net.ParseCIDR(*ip); err == nil {
+		return t.DeleteNetRule(*ipNet, gwAddr)
+	}
+
+	ipAddr := net.ParseIP(*ip)
 	return t.DeleteRule(ipAddr, gwAddr)
 }
 
+func (d *Daemon) excludeApp(args ...string) error {
+	cgroup, gwAddr, err := parseAppArgs(args...)
+	if err != nil {
+		return err
+	}
+
+	t := &network.RoutingTable{}
+	return t.ExcludeAppRule(cgroup, gwAddr)
+}
+
+func (d *Daemon) deleteApp(args ...string) error {
+	cgroup, gwAddr, err := parseAppArgs(args...)
+	if err != nil {
+		return err
+	}
+
+	t := &network.RoutingTable{}
+	return t.DeleteAppRule(cgroup, gwAddr)
+}
+
+func parseAppArgs(args ...string) (string, net.IP, error) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+
+	cgroup := flags.String("cgroup", "", "Application cgroup path")
+	gw := flags.String("gw", "", "Gateway")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return "", nil, err
+	}
+
+	if *cgroup == "" {
+		return "", nil, errors.New("-cgroup is required")
+	}
+	if *gw == "" {
+		return "", nil, errors.New("-gw is required")
+	}
+
+	return *cgroup, net.ParseIP(*gw), nil
+}
+
 func (d *Daemon) wgUp(args ...string) (interfaceName string, err error) {
 	flags := flag.NewFlagSet("", flag.ContinueOnError)
 	deviceConfigStr := flags.String("config", "", "Device configuration JSON string")
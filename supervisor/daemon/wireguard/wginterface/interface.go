@@ -37,6 +37,8 @@ type WgInterface struct {
 	Device     *device.Device
 	uapi       net.Listener
 	dnsManager dns.Manager
+	// deleteRoutes deletes routes which are not removed together with the device.
+	deleteRoutes func() error
 }
 
 // New creates new WgInterface instance.
@@ -69,21 +71,24 @@ func New(cfg wgcfg.DeviceConfig, uid string) (*WgInterface, error) {
 
 	log.Info().Msg("Configuring network")
 	dnsManager := dns.NewManager()
-	if err := configureNetwork(cfg, dnsManager); err != nil {
+	deleteRoutes, err := configureNetwork(cfg, dnsManager)
+	if err != nil {
 		down(uapi, wgDevice, dnsManager)
 		return nil, fmt.Errorf("could not setup network: %w", err)
 	}
 
 	if err := applySocketPermissions(interfaceName, uid); err != nil {
 		down(uapi, wgDevice, dnsManager)
+		deleteNestedRoutes(deleteRoutes)
 		return nil, fmt.Errorf("could not apply socket permissions: %w", err)
 	}
 
 	wgInterface := &WgInterface{
-		Name:       interfaceName,
-		Device:     wgDevice,
-		uapi:       uapi,
-		dnsManager: dnsManager,
+		Name:         interfaceName,
+		Device:       wgDevice,
+		uapi:         uapi,
+		dnsManager:   dnsManager,
+		deleteRoutes: deleteRoutes,
 	}
 	log.Info().Msg("Accepting UAPI requests")
 	go wgInterface.accept()
@@ -103,7 +108,10 @@ func (a *WgInterface) Reconfigure(cfg wgcfg.DeviceConfig) error {
 
 	log.Info().Msg("Configuring network")
 	dnsManager := dns.NewManager()
-	if err := configureNetwork(cfg, dnsManager); err != nil {
+	deleteNestedRoutes(a.deleteRoutes)
+	deleteRoutes, err := configureNetwork(cfg, dnsManager)
+	a.deleteRoutes = deleteRoutes
+	if err != nil {
 		return fmt.Errorf("could not setup network: %w", err)
 	}
 
@@ -144,16 +152,37 @@ func down(uapi net.Listener, d *device.Device, dnsManager dns.Manager) {
 // Down closes device and user space api socket.
 func (a *WgInterface) Down() {
 	down(a.uapi, a.Device, a.dnsManager)
+	deleteNestedRoutes(a.deleteRoutes)
+	a.deleteRoutes = nil
 }
 
-func configureNetwork(cfg wgcfg.DeviceConfig, dnsManager dns.Manager) error {
+// configureNetwork returns the function deleting the route to the peer of nested tunnel, it is nil otherwise.
+func configureNetwork(cfg wgcfg.DeviceConfig, dnsManager dns.Manager) (deleteRoutes func() error, err error) {
 	if err := netutil.AssignIP(cfg.IfaceName, cfg.Subnet); err != nil {
-		return fmt.Errorf("failed to assign IP address: %w", err)
+		return nil, fmt.Errorf("failed to assign IP address: %w", err)
 	}
 
 	if cfg.Peer.Endpoint != nil {
-		if err := netutil.AddDefaultRoute(cfg.IfaceName); err != nil {
-			return fmt.Errorf("could not add default route for %s: %w", cfg.IfaceName, err)
+		addDefaultRoute := netutil.AddDefaultRoute
+		if cfg.OuterIface != "" {
+			endpoint := cfg.Peer.Endpoint.IP
+			addDefaultRoute = func(iface string) error {
+				if err := netutil.AddNestedDefaultRoute(iface, cfg.NestingLevel, endpoint, cfg.OuterIface); err != nil {
+					return err
+				}
+				deleteRoutes = func() error {
+					return netutil.DeleteNestedDefaultRoute(endpoint, cfg.OuterIface)
+				}
+				return nil
+			}
+		}
+		if !cfg.Peer.TunnelsAll() {
+			addDefaultRoute = func(iface string) error {
+				return netutil.AddRoutes(iface, cfg.Peer.AllowedIPs)
+			}
+		}
+		if err := addDefaultRoute(cfg.IfaceName); err != nil {
+			return nil, fmt.Errorf("could not add default route for %s: %w", cfg.IfaceName, err)
 		}
 	}
 
@@ -162,8 +191,18 @@ func configureNetwork(cfg wgcfg.DeviceConfig, dnsManager dns.Manager) error {
 		IfaceName: cfg.IfaceName,
 		DNS:       cfg.DNS,
 	}); err != nil {
-		return fmt.Errorf("could not set DNS: %w", err)
+		deleteNestedRoutes(deleteRoutes)
+		return nil, fmt.Errorf("could not set DNS: %w", err)
 	}
 
-	return nil
+	return deleteRoutes, nil
+}
+
+func deleteNestedRoutes(deleteRoutes func() error) {
+	if deleteRoutes == nil {
+		return
+	}
+	if err := deleteRoutes(); err != nil {
+		log.Warn().Err(err).Msg("Could not delete nested tunnel routes")
+	}
 }
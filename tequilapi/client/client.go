@@ -298,6 +298,18 @@ func (client *Client) ConnectionStatistics() (statistics contract.ConnectionStat
 	return statistics, err
 }
 
+// ConnectionDiagnostics runs active probes over the current connection and returns their results
+func (client *Client) ConnectionDiagnostics() (diagnostics contract.ConnectionDiagnosticsDTO, err error) {
+	response, err := client.http.Get("connection/diagnostics", url.Values{})
+	if err != nil {
+		return diagnostics, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &diagnostics)
+	return diagnostics, err
+}
+
 // ConnectionStatus returns connection status
 func (client *Client) ConnectionStatus() (status contract.ConnectionInfoDTO, err error) {
 	response, err := client.http.Get("connection", url.Values{})
@@ -334,6 +346,42 @@ func (client *Client) ConnectionLocation() (location contract.LocationDTO, err e
 	return location, err
 }
 
+// ConnectionSchedules returns connection schedule rules
+func (client *Client) ConnectionSchedules() (rules contract.ConnectionScheduleListResponse, err error) {
+	response, err := client.http.Get("connection/schedules", url.Values{})
+	if err != nil {
+		return rules, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &rules)
+	return rules, err
+}
+
+// ConnectionScheduleCreate creates connection schedule rule
+func (client *Client) ConnectionScheduleCreate(request contract.ConnectionScheduleRequest) (rule contract.ConnectionScheduleDTO, err error) {
+	response, err := client.http.Post("connection/schedules", request)
+	if err != nil {
+		return rule, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &rule)
+	return rule, err
+}
+
+// ConnectionScheduleDelete removes connection schedule rule by the requested id
+func (client *Client) ConnectionScheduleDelete(id int) error {
+	path := fmt.Sprintf("connection/schedules/%d", id)
+	response, err := client.http.Delete(path, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
 // Healthcheck returns a healthcheck info
 func (client *Client) Healthcheck() (healthcheck contract.HealthCheckDTO, err error) {
 	response, err := client.http.Get("healthcheck", url.Values{})
@@ -382,6 +430,17 @@ func (client *Client) ProposalsByLocationAndService(serviceType, locationType, l
 	return client.proposals(queryParams)
 }
 
+// ProposalsByQuery fetches proposals of given service and node location types matching the filter expression, sorted by given keys.
+func (client *Client) ProposalsByQuery(serviceType, locationType, locationCountry, query, sort string) ([]contract.ProposalDTO, error) {
+	queryParams := url.Values{}
+	queryParams.Add("service_type", serviceType)
+	queryParams.Add("ip_type", locationType)
+	queryParams.Add("location_country", locationCountry)
+	queryParams.Add("query", query)
+	queryParams.Add("sort", sort)
+	return client.proposals(queryParams)
+}
+
 // Proposals returns all available proposals for services
 func (client *Client) Proposals() ([]contract.ProposalDTO, error) {
 	return client.proposals(url.Values{})
@@ -394,6 +453,138 @@ func (client *Client) ProposalsNATCompatible() ([]contract.ProposalDTO, error) {
 	return client.proposals(queryParams)
 }
 
+// FilterPresets returns system and user created proposal filter presets
+func (client *Client) FilterPresets() (presets contract.ListProposalFilterPresetsResponse, err error) {
+	response, err := client.http.Get("proposals/filter-presets", url.Values{})
+	if err != nil {
+		return presets, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &presets)
+	return presets, err
+}
+
+// FilterPresetCreate creates user proposal filter preset
+func (client *Client) FilterPresetCreate(request contract.FilterPresetRequest) (preset contract.FilterPreset, err error) {
+	response, err := client.http.Post("proposals/filter-presets", request)
+	if err != nil {
+		return preset, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &preset)
+	return preset, err
+}
+
+// FilterPresetUpdate replaces user proposal filter preset by the requested id
+func (client *Client) FilterPresetUpdate(id int, request contract.FilterPresetRequest) (preset contract.FilterPreset, err error) {
+	path := fmt.Sprintf("proposals/filter-presets/%d", id)
+	response, err := client.http.Put(path, request)
+	if err != nil {
+		return preset, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &preset)
+	return preset, err
+}
+
+// FilterPresetDelete removes user proposal filter preset by the requested id
+func (client *Client) FilterPresetDelete(id int) error {
+	path := fmt.Sprintf("proposals/filter-presets/%d", id)
+	response, err := client.http.Delete(path, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// ProposalSelect previews the provider picked by the selector
+func (client *Client) ProposalSelect(request contract.ProposalSelectRequest) (selected contract.ProposalSelectResponse, err error) {
+	response, err := client.http.Post("proposals/select", request)
+	if err != nil {
+		return selected, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &selected)
+	return selected, err
+}
+
+// ProposalWebhooks returns registered proposal webhooks
+func (client *Client) ProposalWebhooks() (webhooks contract.ProposalWebhookListResponse, err error) {
+	response, err := client.http.Get("proposals/webhooks", url.Values{})
+	if err != nil {
+		return webhooks, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &webhooks)
+	return webhooks, err
+}
+
+// ProposalWebhookCreate registers URL proposal events matching the filter are posted to
+func (client *Client) ProposalWebhookCreate(request contract.ProposalWebhookRequest) (webhook contract.ProposalWebhookDTO, err error) {
+	response, err := client.http.Post("proposals/webhooks", request)
+	if err != nil {
+		return webhook, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &webhook)
+	return webhook, err
+}
+
+// ProposalWebhookDelete removes proposal webhook by the requested id
+func (client *Client) ProposalWebhookDelete(id string) error {
+	response, err := client.http.Delete("proposals/webhooks/"+id, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// ProviderReputations returns personal reputation of known providers
+func (client *Client) ProviderReputations() (providers contract.ProviderReputationListResponse, err error) {
+	response, err := client.http.Get("providers", url.Values{})
+	if err != nil {
+		return providers, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &providers)
+	return providers, err
+}
+
+// ProviderReputationUpdate marks provider as favourite or blocked
+func (client *Client) ProviderReputationUpdate(providerID string, request contract.ProviderReputationRequest) (provider contract.ProviderReputationDTO, err error) {
+	response, err := client.http.Put("providers/"+providerID, request)
+	if err != nil {
+		return provider, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &provider)
+	return provider, err
+}
+
+// ProposalsProbe measures latency to the best proposals
+func (client *Client) ProposalsProbe(request contract.ProposalsProbeRequest) (latencies contract.ProposalsProbeResponse, err error) {
+	response, err := client.http.Post("proposals/probe", request)
+	if err != nil {
+		return latencies, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &latencies)
+	return latencies, err
+}
+
 func (client *Client) proposals(query url.Values) ([]contract.ProposalDTO, error) {
 	response, err := client.http.Get("proposals", query)
 	if err != nil {
@@ -538,6 +729,79 @@ func (client *Client) ServiceStop(id string) error {
 	return nil
 }
 
+// ServiceDrain stops accepting new sessions and stops the service once existing sessions end.
+func (client *Client) ServiceDrain(id string, request contract.ServiceDrainRequest) error {
+	path := fmt.Sprintf("services/%s/drain", id)
+	response, err := client.http.Post(path, request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// ServiceSessions returns live sessions of the running service instance.
+func (client *Client) ServiceSessions(id string) (sessions contract.ServiceSessionListResponse, err error) {
+	path := fmt.Sprintf("services/%s/sessions", id)
+	response, err := client.http.Get(path, url.Values{})
+	if err != nil {
+		return sessions, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &sessions)
+	return sessions, err
+}
+
+// ServiceSessionTerminate terminates live session of the running service instance.
+func (client *Client) ServiceSessionTerminate(id, sessionID string) error {
+	path := fmt.Sprintf("services/%s/sessions/%s", id, sessionID)
+	response, err := client.http.Delete(path, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// ConsumerBans returns consumers banned by the provider.
+func (client *Client) ConsumerBans() (bans contract.ConsumerBanListResponse, err error) {
+	response, err := client.http.Get("consumer-bans", url.Values{})
+	if err != nil {
+		return bans, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &bans)
+	return bans, err
+}
+
+// ConsumerBan bans consumer and terminates its live sessions.
+func (client *Client) ConsumerBan(request contract.ConsumerBanRequest) (ban contract.ConsumerBanDTO, err error) {
+	response, err := client.http.Post("consumer-bans", request)
+	if err != nil {
+		return ban, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &ban)
+	return ban, err
+}
+
+// ConsumerUnban removes consumer from the ban list.
+func (client *Client) ConsumerUnban(consumerID string) error {
+	path := fmt.Sprintf("consumer-bans/%s", consumerID)
+	response, err := client.http.Delete(path, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
 // NATStatus returns status of NAT traversal
 func (client *Client) NATStatus() (status contract.NodeStatusResponse, err error) {
 	response, err := client.http.Get("node/monitoring-status", nil)
@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// LocalAccessPolicyListResponse represents access policies defined by the provider.
+// swagger:model LocalAccessPolicyListResponse
+type LocalAccessPolicyListResponse struct {
+	Entries []LocalAccessPolicyDTO `json:"entries"`
+}
+
+// NewLocalAccessPolicyListResponse maps local access policies to DTO.
+func NewLocalAccessPolicyListResponse(policies []market.AccessPolicyRuleSet) LocalAccessPolicyListResponse {
+	res := LocalAccessPolicyListResponse{Entries: make([]LocalAccessPolicyDTO, 0, len(policies))}
+	for _, rules := range policies {
+		res.Entries = append(res.Entries, NewLocalAccessPolicyDTO(rules))
+	}
+	return res
+}
+
+// LocalAccessPolicyDTO represents access policy defined by the provider, it is applied to every provided service.
+// swagger:model LocalAccessPolicyDTO
+type LocalAccessPolicyDTO struct {
+	// example: isp-restrictions
+	ID string `json:"id"`
+	// example: ISP restrictions
+	Title string `json:"title,omitempty"`
+	// example: Blocks outbound mail and P2P traffic
+	Description string `json:"description,omitempty"`
+	// rules of which at least one has to match, per rule type
+	Allow []AccessRuleDTO `json:"allow"`
+	// rules of which none may match
+	Deny []AccessRuleDTO `json:"deny"`
+}
+
+// AccessRuleDTO represents single rule of access policy.
+// swagger:model AccessRuleDTO
+type AccessRuleDTO struct {
+	// Possible values are "identity", "dns_hostname", "dns_zone", "destination_cidr", "destination_port", "protocol" and "consumer_country"
+	// example: destination_port
+	Type string `json:"type"`
+	// example: tcp/25
+	Value string `json:"value"`
+}
+
+// NewLocalAccessPolicyDTO maps local access policy to DTO.
+func NewLocalAccessPolicyDTO(rules market.AccessPolicyRuleSet) LocalAccessPolicyDTO {
+	return LocalAccessPolicyDTO{
+		ID:          rules.ID,
+		Title:       rules.Title,
+		Description: rules.Description,
+		Allow:       newAccessRuleDTOs(rules.Allow),
+		Deny:        newAccessRuleDTOs(rules.Deny),
+	}
+}
+
+func newAccessRuleDTOs(rules []market.AccessRule) []AccessRuleDTO {
+	res := make([]AccessRuleDTO, 0, len(rules))
+	for _, rule := range rules {
+		res = append(res, AccessRuleDTO{Type: rule.Type, Value: rule.Value})
+	}
+	return res
+}
+
+// Validate validates fields in request.
+func (r LocalAccessPolicyDTO) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if r.ID == "" {
+		errs.ForField("id").Required()
+	}
+	if len(r.Allow) == 0 && len(r.Deny) == 0 {
+		errs.ForField("allow").Invalid("Policy must have allow or deny rules")
+	}
+	for _, rule := range r.Allow {
+		if err := policy.ValidateRule(market.AccessRule{Type: rule.Type, Value: rule.Value}); err != nil {
+			errs.ForField("allow").Invalid(err.Error())
+		}
+	}
+	for _, rule := range r.Deny {
+		if err := policy.ValidateRule(market.AccessRule{Type: rule.Type, Value: rule.Value}); err != nil {
+			errs.ForField("deny").Invalid(err.Error())
+		}
+	}
+	return errs
+}
+
+// ToRuleSet converts DTO to access policy.
+func (r LocalAccessPolicyDTO) ToRuleSet() market.AccessPolicyRuleSet {
+	return market.AccessPolicyRuleSet{
+		ID:          r.ID,
+		Title:       r.Title,
+		Description: r.Description,
+		Allow:       toAccessRules(r.Allow),
+		Deny:        toAccessRules(r.Deny),
+	}
+}
+
+func toAccessRules(rules []AccessRuleDTO) []market.AccessRule {
+	res := make([]market.AccessRule, 0, len(rules))
+	for _, rule := range rules {
+		res = append(res, market.AccessRule{Type: rule.Type, Value: rule.Value})
+	}
+	return res
+}
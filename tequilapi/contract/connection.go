@@ -18,11 +18,13 @@
 package contract
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/consumer/latency"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/quality"
@@ -39,6 +41,7 @@ func NewConnectionInfoDTO(session connectionstate.Status) ConnectionInfoDTO {
 		Status:     string(session.State),
 		ConsumerID: session.ConsumerID.Address,
 		SessionID:  string(session.SessionID),
+		Reason:     session.Reason,
 	}
 	if session.HermesID != emptyAddress {
 		response.HermesID = session.HermesID.Hex()
@@ -48,6 +51,12 @@ func NewConnectionInfoDTO(session connectionstate.Status) ConnectionInfoDTO {
 		proposalRes := NewProposalDTO(session.Proposal)
 		response.Proposal = &proposalRes
 	}
+	for _, hop := range session.Hops {
+		response.Hops = append(response.Hops, ConnectionHopDTO{
+			SessionID: string(hop.SessionID),
+			Proposal:  NewProposalDTO(hop.Proposal),
+		})
+	}
 	return response
 }
 
@@ -68,6 +77,22 @@ type ConnectionInfoDTO struct {
 
 	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
 	SessionID string `json:"session_id,omitempty"`
+
+	// multi-hop entry hops ordered from the consumer, proposal and session above belong to the exit hop
+	Hops []ConnectionHopDTO `json:"hops,omitempty"`
+
+	// why the connection reached its current status, e.g. which spending limit was reached
+	// example: spending limit reached: session spending 1000 exceeds 900
+	Reason string `json:"reason,omitempty"`
+}
+
+// ConnectionHopDTO holds multi-hop connection entry hop details.
+// swagger:model ConnectionHopDTO
+type ConnectionHopDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id,omitempty"`
+
+	Proposal ProposalDTO `json:"proposal"`
 }
 
 // NewConnectionDTO maps to API connection.
@@ -95,7 +120,7 @@ func NewConnectionStatisticsDTO(session connectionstate.Status, statistics conne
 	if invoice.AgreementTotal != nil {
 		agreementTotal = invoice.AgreementTotal
 	}
-	return ConnectionStatisticsDTO{
+	response := ConnectionStatisticsDTO{
 		Duration:           int(session.Duration().Seconds()),
 		BytesSent:          statistics.BytesSent,
 		BytesReceived:      statistics.BytesReceived,
@@ -103,6 +128,16 @@ func NewConnectionStatisticsDTO(session connectionstate.Status, statistics conne
 		ThroughputReceived: datasize.BitSize(throughput.Down).Bits(),
 		TokensSpent:        agreementTotal,
 	}
+	if limits := NewSpendingLimitsDTO(session.SpendingLimits); limits != nil {
+		budget := session.SpendingLimits.Remaining(session.Duration(), agreementTotal, statistics.BytesSent+statistics.BytesReceived)
+		response.SpendingLimits = limits
+		response.SpendingBudget = &SpendingBudgetDTO{
+			Session:      budget.Session,
+			Hourly:       budget.Hourly,
+			TrafficBytes: budget.Traffic,
+		}
+	}
+	return response
 }
 
 // ConnectionStatisticsDTO holds consumer connection statistics.
@@ -128,6 +163,12 @@ type ConnectionStatisticsDTO struct {
 
 	// example: 500000
 	TokensSpent *big.Int `json:"tokens_spent"`
+
+	// spending limits of the session, omitted when not limited
+	SpendingLimits *SpendingLimitsDTO `json:"spending_limits,omitempty"`
+
+	// budget left until spending limits are reached
+	SpendingBudget *SpendingBudgetDTO `json:"spending_budget,omitempty"`
 }
 
 // ConnectionCreateRequest request used to start a connection.
@@ -145,11 +186,15 @@ type ConnectionCreateRequest struct {
 
 	Filter ConnectionCreateFilter `json:"filter"`
 
+	// multi-hop entry hops ordered from the consumer, provider and filter above select the exit hop
+	// required: false
+	Hops []ConnectionCreateFilter `json:"hops,omitempty"`
+
 	// hermes identity
 	// example: 0x0000000000000000000000000000000000000003
 	HermesID string `json:"hermes_id"`
 
-	// service type. Possible values are "openvpn", "wireguard" and "noop"
+	// service type. Possible values are "openvpn", "wireguard", "noop" and "proxy"
 	// required: false
 	// default: openvpn
 	// example: openvpn
@@ -167,7 +212,13 @@ type ConnectionCreateFilter struct {
 	CountryCode             string   `json:"country_code,omitempty"`
 	IPType                  string   `json:"ip_type,omitempty"`
 	IncludeMonitoringFailed bool     `json:"include_monitoring_failed,omitempty"`
+	IPv6                    bool     `json:"ipv6,omitempty"`
 	SortBy                  string   `json:"sort_by,omitempty"`
+	PresetID                int      `json:"preset_id,omitempty"`
+	// FastestOf probes latency of the given count of top proposals and connects to the fastest of them.
+	FastestOf int `json:"fastest_of,omitempty"`
+	// Selector picks the best provider within price caps instead of sorting proposals.
+	Selector *ProviderSelectorDTO `json:"selector,omitempty"`
 }
 
 // Validate validates fields in request.
@@ -176,6 +227,30 @@ func (cr ConnectionCreateRequest) Validate() *validation.FieldErrorMap {
 	if len(cr.ConsumerID) == 0 {
 		errs.ForField("consumer_id").Required()
 	}
+	if err := cr.ConnectOptions.SplitTunnel.ToSplitTunnel().Validate(); err != nil {
+		errs.ForField("connect_options.split_tunnel").Invalid(err.Error())
+	}
+	if err := cr.ConnectOptions.SpendingLimits.ToSpendingLimits().Validate(); err != nil {
+		errs.ForField("connect_options.spending_limits").Invalid(err.Error())
+	}
+	for _, filter := range append([]ConnectionCreateFilter{cr.Filter}, cr.Hops...) {
+		if filter.FastestOf < 0 || filter.FastestOf > latency.MaxCandidates {
+			errs.ForField("filter.fastest_of").Invalid(fmt.Sprintf("Must be between 0 and %d", latency.MaxCandidates))
+			break
+		}
+	}
+	if cr.Filter.Selector != nil && len(cr.ProviderID) > 0 {
+		errs.ForField("filter.selector").Invalid("Can't be combined with provider_id")
+	}
+	for _, filter := range append([]ConnectionCreateFilter{cr.Filter}, cr.Hops...) {
+		if filter.Selector == nil {
+			continue
+		}
+		if err := filter.Selector.ToSpec().Validate(); err != nil {
+			errs.ForField("filter.selector").Invalid(err.Error())
+			break
+		}
+	}
 	return errs
 }
 
@@ -201,6 +276,104 @@ type ConnectOptions struct {
 	// DNS to use
 	// required: false
 	// default: auto
-	// example: auto, provider, system, "1.1.1.1,8.8.8.8"
+	// example: auto, provider, system, filtered, "1.1.1.1,8.8.8.8"
 	DNS connection.DNSOption `json:"dns"`
+	// count of consecutive failed session checks after which connection is switched to the next best provider, 0 disables failover
+	// required: false
+	// example: 3
+	FailoverMaxFailures int `json:"failover_max_failures,omitempty"`
+	// split tunneling rules, all traffic goes through the tunnel if omitted
+	// required: false
+	SplitTunnel SplitTunnelDTO `json:"split_tunnel,omitempty"`
+	// session spending limits, the connection is terminated once any of them is reached
+	// required: false
+	SpendingLimits SpendingLimitsDTO `json:"spending_limits,omitempty"`
+}
+
+// SplitTunnelDTO holds split tunneling rules
+// swagger:model SplitTunnelDTO
+type SplitTunnelDTO struct {
+	// the only networks routed through the tunnel
+	// required: false
+	// example: ["10.0.0.0/8"]
+	IncludeCIDRs []string `json:"include_cidrs,omitempty"`
+	// the only domains routed through the tunnel
+	// required: false
+	// example: ["example.com"]
+	IncludeDomains []string `json:"include_domains,omitempty"`
+	// networks bypassing the tunnel
+	// required: false
+	// example: ["192.168.0.0/16"]
+	ExcludeCIDRs []string `json:"exclude_cidrs,omitempty"`
+	// domains bypassing the tunnel
+	// required: false
+	// example: ["intranet.example.com"]
+	ExcludeDomains []string `json:"exclude_domains,omitempty"`
+	// cgroup paths of applications bypassing the tunnel, Linux only
+	// required: false
+	// example: ["user.slice/user-1000.slice/app-firefox.scope"]
+	ExcludeApps []string `json:"exclude_apps,omitempty"`
+}
+
+// ToSplitTunnel converts DTO to connection split tunnel rules.
+func (dto SplitTunnelDTO) ToSplitTunnel() connection.SplitTunnel {
+	return connection.SplitTunnel{
+		IncludeCIDRs:   dto.IncludeCIDRs,
+		IncludeDomains: dto.IncludeDomains,
+		ExcludeCIDRs:   dto.ExcludeCIDRs,
+		ExcludeDomains: dto.ExcludeDomains,
+		ExcludeApps:    dto.ExcludeApps,
+	}
+}
+
+// SpendingLimitsDTO holds session spending limits
+// swagger:model SpendingLimitsDTO
+type SpendingLimitsDTO struct {
+	// maximum tokens spent during the session
+	// required: false
+	// example: 1000000000000000000
+	MaxSessionSpend *big.Int `json:"max_session_spend,omitempty"`
+	// maximum tokens spent per hour of the session
+	// required: false
+	// example: 500000000000000000
+	MaxHourlySpend *big.Int `json:"max_hourly_spend,omitempty"`
+	// maximum bytes transferred during the session
+	// required: false
+	// example: 1073741824
+	MaxTrafficBytes uint64 `json:"max_traffic_bytes,omitempty"`
+}
+
+// ToSpendingLimits converts DTO to connection spending limits.
+func (dto SpendingLimitsDTO) ToSpendingLimits() connectionstate.SpendingLimits {
+	return connectionstate.SpendingLimits{
+		MaxSessionSpend: dto.MaxSessionSpend,
+		MaxHourlySpend:  dto.MaxHourlySpend,
+		MaxTraffic:      dto.MaxTrafficBytes,
+	}
+}
+
+// NewSpendingLimitsDTO maps connection spending limits to DTO.
+func NewSpendingLimitsDTO(limits connectionstate.SpendingLimits) *SpendingLimitsDTO {
+	if !limits.Enabled() {
+		return nil
+	}
+	return &SpendingLimitsDTO{
+		MaxSessionSpend: limits.MaxSessionSpend,
+		MaxHourlySpend:  limits.MaxHourlySpend,
+		MaxTrafficBytes: limits.MaxTraffic,
+	}
+}
+
+// SpendingBudgetDTO holds the budget left until session spending limits are reached
+// swagger:model SpendingBudgetDTO
+type SpendingBudgetDTO struct {
+	// tokens left to spend during the session
+	// example: 500000000000000000
+	Session *big.Int `json:"session,omitempty"`
+	// tokens left to spend within the hourly limit
+	// example: 250000000000000000
+	Hourly *big.Int `json:"hourly,omitempty"`
+	// bytes left to transfer during the session
+	// example: 536870912
+	TrafficBytes *uint64 `json:"traffic_bytes,omitempty"`
 }
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+)
+
+// NewConnectionDiagnosticsDTO maps to API connection diagnostics.
+func NewConnectionDiagnosticsDTO(diagnostics connectionstate.Diagnostics) ConnectionDiagnosticsDTO {
+	return ConnectionDiagnosticsDTO{
+		SessionID:                   string(diagnostics.SessionID),
+		Completed:                   diagnostics.Completed,
+		PingsSent:                   diagnostics.PingsSent,
+		PingsLost:                   diagnostics.PingsLost,
+		PacketLoss:                  diagnostics.PacketLoss(),
+		Latency:                     milliseconds(diagnostics.Latency),
+		Jitter:                      milliseconds(diagnostics.Jitter),
+		DNSOption:                   diagnostics.DNSOption,
+		DNSServers:                  diagnostics.DNSServers,
+		DNSResolveTime:              milliseconds(diagnostics.DNSResolveTime),
+		DNSError:                    diagnostics.DNSError,
+		HandshakeAge:                int(diagnostics.HandshakeAge.Seconds()),
+		ObservedTrafficRateSent:     diagnostics.ObservedBytesSentPerSecond * 8,
+		ObservedTrafficRateReceived: diagnostics.ObservedBytesReceivedPerSecond * 8,
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// ConnectionDiagnosticsDTO holds results of active probes of the current connection.
+// swagger:model ConnectionDiagnosticsDTO
+type ConnectionDiagnosticsDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id"`
+
+	// false for intermediate results streamed while probes are running
+	// example: true
+	Completed bool `json:"completed"`
+
+	// keep-alive pings sent to the provider over p2p channel
+	// example: 10
+	PingsSent int `json:"pings_sent"`
+
+	// example: 1
+	PingsLost int `json:"pings_lost"`
+
+	// ratio of lost pings
+	// example: 0.1
+	PacketLoss float64 `json:"packet_loss"`
+
+	// mean round-trip time to the provider in milliseconds
+	// example: 45.5
+	Latency float64 `json:"latency"`
+
+	// mean difference of consecutive round-trip times in milliseconds
+	// example: 3.2
+	Jitter float64 `json:"jitter"`
+
+	// DNS option of the connection
+	// example: auto
+	DNSOption string `json:"dns_option"`
+
+	// DNS servers the resolution time was measured with, omitted if the system resolver is used
+	// example: ["10.182.0.1"]
+	DNSServers []string `json:"dns_servers,omitempty"`
+
+	// DNS resolution time in milliseconds
+	// example: 25.1
+	DNSResolveTime float64 `json:"dns_resolve_time"`
+
+	// example: i/o timeout
+	DNSError string `json:"dns_error,omitempty"`
+
+	// seconds since the last tunnel handshake, omitted if service has no handshakes
+	// example: 42
+	HandshakeAge int `json:"handshake_age,omitempty"`
+
+	// Rate of the traffic observed being sent through the connection while probes were running, in bits per second.
+	// It is not a measurement of the connection throughput.
+	// example: 1024
+	ObservedTrafficRateSent uint64 `json:"observed_traffic_rate_sent"`
+
+	// Rate of the traffic observed being received through the connection while probes were running, in bits per second.
+	// example: 1024
+	ObservedTrafficRateReceived uint64 `json:"observed_traffic_rate_received"`
+}
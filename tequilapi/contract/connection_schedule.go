@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection/schedule"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// ConnectionScheduleRequest request used to create or update connection schedule rule.
+// swagger:model ConnectionScheduleRequestDTO
+type ConnectionScheduleRequest struct {
+	// consumer identity used to connect at the scheduled time, required for scheduled rules
+	// required: false
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id,omitempty"`
+	// service type to connect to, required for scheduled rules
+	// required: false
+	// example: wireguard
+	ServiceType string `json:"service_type,omitempty"`
+	// provider country to connect to
+	// required: false
+	// example: DE
+	CountryCode string `json:"country_code,omitempty"`
+	// proposal filter preset to choose provider by
+	// required: false
+	// example: 1
+	PresetID int `json:"preset_id,omitempty"`
+	// local time of day to connect at, rules without it limit every connection
+	// required: false
+	// example: 08:30
+	ConnectAt string `json:"connect_at,omitempty"`
+	// disconnect after connection lasted the given count of minutes
+	// required: false
+	// example: 60
+	DisconnectAfterMinutes int `json:"disconnect_after_minutes,omitempty"`
+	// disconnect after connection transferred the given count of bytes
+	// required: false
+	// example: 1073741824
+	MaxTrafficBytes uint64 `json:"max_traffic_bytes,omitempty"`
+	// disconnect after connection spent the given amount of tokens
+	// required: false
+	// example: 1000000000000000000
+	MaxSpend *big.Int `json:"max_spend,omitempty"`
+	// reconnect when network of the node changes
+	// required: false
+	// example: true
+	ReconnectOnNetworkChange bool `json:"reconnect_on_network_change"`
+}
+
+// Validate validates fields in request.
+func (r ConnectionScheduleRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if r.DisconnectAfterMinutes < 0 {
+		errs.ForField("disconnect_after_minutes").Invalid("Must not be negative")
+	}
+	if r.MaxSpend != nil && r.MaxSpend.Sign() < 0 {
+		errs.ForField("max_spend").Invalid("Must not be negative")
+	}
+	if r.ConnectAt != "" {
+		if len(r.ConsumerID) == 0 {
+			errs.ForField("consumer_id").Required()
+		}
+		if len(r.ServiceType) == 0 {
+			errs.ForField("service_type").Required()
+		}
+	}
+	if errs.HasErrors() {
+		return errs
+	}
+	if err := r.ToRule(0).Validate(); err != nil {
+		errs.ForField("connect_at").Invalid(err.Error())
+	}
+	return errs
+}
+
+// ToRule converts request to connection schedule rule with the given ID.
+func (r ConnectionScheduleRequest) ToRule(id int) schedule.Rule {
+	return schedule.Rule{
+		ID:                       id,
+		ConsumerID:               r.ConsumerID,
+		ServiceType:              r.ServiceType,
+		CountryCode:              r.CountryCode,
+		PresetID:                 r.PresetID,
+		ConnectAt:                r.ConnectAt,
+		DisconnectAfter:          time.Duration(r.DisconnectAfterMinutes) * time.Minute,
+		MaxTraffic:               r.MaxTrafficBytes,
+		MaxSpend:                 r.MaxSpend,
+		ReconnectOnNetworkChange: r.ReconnectOnNetworkChange,
+	}
+}
+
+// ConnectionScheduleDTO represents connection schedule rule.
+// swagger:model ConnectionScheduleDTO
+type ConnectionScheduleDTO struct {
+	// example: 1
+	ID int `json:"id"`
+	ConnectionScheduleRequest
+}
+
+// NewConnectionScheduleDTO maps connection schedule rule to DTO.
+func NewConnectionScheduleDTO(rule schedule.Rule) ConnectionScheduleDTO {
+	return ConnectionScheduleDTO{
+		ID: rule.ID,
+		ConnectionScheduleRequest: ConnectionScheduleRequest{
+			ConsumerID:               rule.ConsumerID,
+			ServiceType:              rule.ServiceType,
+			CountryCode:              rule.CountryCode,
+			PresetID:                 rule.PresetID,
+			ConnectAt:                rule.ConnectAt,
+			DisconnectAfterMinutes:   int(rule.DisconnectAfter / time.Minute),
+			MaxTrafficBytes:          rule.MaxTraffic,
+			MaxSpend:                 rule.MaxSpend,
+			ReconnectOnNetworkChange: rule.ReconnectOnNetworkChange,
+		},
+	}
+}
+
+// ConnectionScheduleListResponse represents a list of connection schedule rules.
+// swagger:model ConnectionScheduleListResponse
+type ConnectionScheduleListResponse []ConnectionScheduleDTO
+
+// NewConnectionScheduleListResponse maps connection schedule rules to DTO.
+func NewConnectionScheduleListResponse(rules []schedule.Rule) ConnectionScheduleListResponse {
+	res := ConnectionScheduleListResponse{}
+	for _, rule := range rules {
+		res = append(res, NewConnectionScheduleDTO(rule))
+	}
+	return res
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/dns"
+)
+
+// DNSFilterOverridesDTO holds domains which are allowed or denied regardless of the DNS filter blocklists.
+// swagger:model DNSFilterOverridesDTO
+type DNSFilterOverridesDTO struct {
+	// domains which are never blocked, including their subdomains
+	// example: ["example.com"]
+	Allow []string `json:"allow"`
+
+	// domains which are always blocked, including their subdomains
+	// example: ["ads.example.org"]
+	Deny []string `json:"deny"`
+}
+
+// NewDNSFilterStatsDTO maps to API DNS filter stats.
+func NewDNSFilterStatsDTO(stats dns.FilterStats) DNSFilterStatsDTO {
+	dto := DNSFilterStatsDTO{
+		Lists:    make([]DNSFilterListDTO, len(stats.Lists)),
+		DenyHits: stats.DenyHits,
+	}
+	for i, list := range stats.Lists {
+		dto.Lists[i] = DNSFilterListDTO{
+			Source:  list.Source,
+			Domains: list.Domains,
+			Hits:    list.Hits,
+			Error:   list.Error,
+		}
+		if !list.UpdatedAt.IsZero() {
+			dto.Lists[i].UpdatedAt = list.UpdatedAt.Format(time.RFC3339)
+		}
+	}
+	return dto
+}
+
+// DNSFilterStatsDTO holds hit counters of the DNS filter.
+// swagger:model DNSFilterStatsDTO
+type DNSFilterStatsDTO struct {
+	Lists []DNSFilterListDTO `json:"lists"`
+
+	// queries blocked by the deny overrides
+	// example: 12
+	DenyHits uint64 `json:"deny_hits"`
+}
+
+// DNSFilterListDTO describes a single blocklist of the DNS filter.
+// swagger:model DNSFilterListDTO
+type DNSFilterListDTO struct {
+	// local file path or URL of the blocklist
+	// example: https://example.com/hosts.txt
+	Source string `json:"source"`
+
+	// count of blocked domains
+	// example: 84120
+	Domains int `json:"domains"`
+
+	// queries blocked by the list
+	// example: 340
+	Hits uint64 `json:"hits"`
+
+	// time of the last successful load, omitted if the list was never loaded
+	// example: 2021-07-20T10:00:00Z
+	UpdatedAt string `json:"updated_at,omitempty"`
+
+	// last loading error, previously loaded domains are still used
+	// example: unexpected response status: 404 Not Found
+	Error string `json:"error,omitempty"`
+}
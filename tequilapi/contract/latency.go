@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/latency"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// ProposalsProbeRequest request used to measure latency to the best proposals.
+// swagger:model ProposalsProbeRequestDTO
+type ProposalsProbeRequest struct {
+	// consumer identity used to sign echo requests
+	// required: true
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+	// service type of probed proposals
+	// required: false
+	// example: wireguard
+	ServiceType string `json:"service_type,omitempty"`
+	// provider country of probed proposals
+	// required: false
+	// example: DE
+	CountryCode string `json:"country_code,omitempty"`
+	// proposal filter preset to choose probed providers by
+	// required: false
+	// example: 1
+	PresetID int `json:"preset_id,omitempty"`
+	// count of the best quality proposals to probe
+	// required: false
+	// example: 10
+	Limit int `json:"limit,omitempty"`
+}
+
+// Validate validates fields in request.
+func (r ProposalsProbeRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if len(r.ConsumerID) == 0 {
+		errs.ForField("consumer_id").Required()
+	}
+	if r.Limit < 0 || r.Limit > latency.MaxCandidates {
+		errs.ForField("limit").Invalid(fmt.Sprintf("Must be between 0 and %d", latency.MaxCandidates))
+	}
+	return errs
+}
+
+// ProviderLatencyDTO represents round trip time to the provider measured from this node.
+// swagger:model ProviderLatencyDTO
+type ProviderLatencyDTO struct {
+	// example: 0x0000000000000000000000000000000000000002
+	ProviderID string `json:"provider_id"`
+	// round trip time in milliseconds
+	// example: 42.5
+	Latency float64 `json:"latency"`
+}
+
+// ProposalsProbeResponse represents latencies of providers which replied to the probe, fastest first.
+// swagger:model ProposalsProbeResponse
+type ProposalsProbeResponse []ProviderLatencyDTO
+
+// NewProposalsProbeResponse maps measured latencies to DTO.
+func NewProposalsProbeResponse(latencies map[string]time.Duration) ProposalsProbeResponse {
+	res := ProposalsProbeResponse{}
+	for providerID, rtt := range latencies {
+		res = append(res, ProviderLatencyDTO{
+			ProviderID: providerID,
+			Latency:    float64(rtt) / float64(time.Millisecond),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Latency < res[j].Latency
+	})
+	return res
+}
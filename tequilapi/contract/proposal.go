@@ -19,10 +19,17 @@ package contract
 
 import (
 	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
 )
 
 // AutoNATType passed as nat_compatibility parameter to proposal discovery
@@ -32,7 +39,7 @@ const AutoNATType = "auto"
 
 // NewProposalDTO maps to API service proposal.
 func NewProposalDTO(p proposal.PricedServiceProposal) ProposalDTO {
-	return ProposalDTO{
+	dto := ProposalDTO{
 		Format:         p.Format,
 		Compatibility:  p.Compatibility,
 		ProviderID:     p.ProviderID,
@@ -46,10 +53,27 @@ func NewProposalDTO(p proposal.PricedServiceProposal) ProposalDTO {
 		},
 		Price: Price{
 			Currency: money.CurrencyMyst.String(),
-			PerHour:  p.Price.PricePerHour.Uint64(),
-			PerGiB:   p.Price.PricePerGiB.Uint64(),
+			PerHour:  bigUint64(p.Price.PricePerHour),
+			PerGiB:   bigUint64(p.Price.PricePerGiB),
 		},
+		IPv6:         p.IPv6,
+		Capacity:     p.Capacity,
+		Reputation:   NewReputationDTO(p.Reputation),
+		LocalLatency: float64(p.LocalLatency) / float64(time.Millisecond),
+	}
+	if !p.CachedAt.IsZero() {
+		dto.Stale = true
+		dto.CacheAge = uint64(time.Since(p.CachedAt).Seconds())
+	}
+	return dto
+}
+
+// bigUint64 converts the price amount, proposals without a price are listed as free.
+func bigUint64(amount *big.Int) uint64 {
+	if amount == nil {
+		return 0
 	}
+	return amount.Uint64()
 }
 
 // NewServiceLocationsDTO maps to API service location.
@@ -102,6 +126,26 @@ type ProposalDTO struct {
 
 	// Quality of the service.
 	Quality Quality `json:"quality"`
+
+	// Service provides IPv6 egress.
+	IPv6 bool `json:"ipv6,omitempty"`
+
+	// Current utilization of the service, it is not advertised by older providers.
+	Capacity *market.Capacity `json:"capacity,omitempty"`
+
+	// Proposal is served from local cache as discovery is unreachable.
+	Stale bool `json:"stale,omitempty"`
+
+	// Seconds since stale proposal was seen by discovery.
+	// example: 3600
+	CacheAge uint64 `json:"cache_age,omitempty"`
+
+	// Personal reputation of the provider.
+	Reputation *ReputationDTO `json:"reputation,omitempty"`
+
+	// Round trip time to the provider in milliseconds measured from this node.
+	// example: 42.5
+	LocalLatency float64 `json:"local_latency,omitempty"`
 }
 
 // Price represents the service price.
@@ -122,19 +166,142 @@ type ListProposalFilterPresetsResponse struct {
 	Items []FilterPreset `json:"items"`
 }
 
-// FilterPreset is a pre-defined proposal filter.
+// FilterPreset is a pre-defined or user created proposal filter.
 // swagger:model FilterPreset
 type FilterPreset struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID int `json:"id"`
+	FilterPresetRequest
 }
 
 // NewFilterPreset maps to the FilterPreset.
 func NewFilterPreset(preset proposal.FilterPreset) FilterPreset {
 	return FilterPreset{
-		ID:   preset.ID,
-		Name: preset.Name,
+		ID: preset.ID,
+		FilterPresetRequest: FilterPresetRequest{
+			Name:             preset.Name,
+			IPType:           string(preset.IPType),
+			Country:          preset.Country,
+			PriceHourMax:     preset.PriceHourMax,
+			PriceGiBMax:      preset.PriceGiBMax,
+			QualityMin:       preset.QualityMin,
+			NATCompatibility: string(preset.NATCompatibility),
+			AllowedProviders: preset.AllowedProviders,
+			DeniedProviders:  preset.DeniedProviders,
+		},
+	}
+}
+
+// FilterPresetRequest request used to create or update user proposal filter preset.
+// swagger:model FilterPresetRequestDTO
+type FilterPresetRequest struct {
+	// required: true
+	// example: Cheap residential
+	Name string `json:"name"`
+	// provider IP type
+	// required: false
+	// example: residential
+	IPType string `json:"ip_type,omitempty"`
+	// two letter (ISO 3166-1 alpha-2) provider country code
+	// required: false
+	// example: DE
+	Country string `json:"country,omitempty"`
+	// maximum price per hour in wei
+	// required: false
+	// example: 100000000000000000
+	PriceHourMax *big.Int `json:"price_hour_max,omitempty"`
+	// maximum price per GiB in wei
+	// required: false
+	// example: 500000000000000000
+	PriceGiBMax *big.Int `json:"price_gib_max,omitempty"`
+	// minimum provider quality
+	// required: false
+	// example: 1.5
+	QualityMin float64 `json:"quality_min,omitempty"`
+	// pick providers compatible with NAT of the given type
+	// required: false
+	// example: prcone
+	NATCompatibility string `json:"nat_compatibility,omitempty"`
+	// select only the given providers
+	// required: false
+	AllowedProviders []string `json:"allowed_providers,omitempty"`
+	// never select the given providers
+	// required: false
+	DeniedProviders []string `json:"denied_providers,omitempty"`
+}
+
+var natCompatibilityTypes = []nat.NATType{
+	nat.NATTypeNone,
+	nat.NATTypeFullCone,
+	nat.NATTypeRestrictedCone,
+	nat.NATTypePortRestrictedCone,
+	nat.NATTypeSymmetric,
+}
+
+// Validate validates fields in request.
+func (r FilterPresetRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if strings.TrimSpace(r.Name) == "" {
+		errs.ForField("name").Required()
+	}
+	if r.Country != "" && len(r.Country) != 2 {
+		errs.ForField("country").Invalid("Country code must be in ISO 3166-1 alpha-2 format")
+	}
+	if r.PriceHourMax != nil && r.PriceHourMax.Sign() < 0 {
+		errs.ForField("price_hour_max").Invalid("Must not be negative")
+	}
+	if r.PriceGiBMax != nil && r.PriceGiBMax.Sign() < 0 {
+		errs.ForField("price_gib_max").Invalid("Must not be negative")
+	}
+	if r.QualityMin < 0 {
+		errs.ForField("quality_min").Invalid("Must not be negative")
+	}
+	if r.NATCompatibility != "" && !isNATCompatibilityType(nat.NATType(r.NATCompatibility)) {
+		errs.ForField("nat_compatibility").Invalid(fmt.Sprintf("Must be one of: %v", natCompatibilityTypes))
+	}
+	for field, providers := range map[string][]string{"allowed_providers": r.AllowedProviders, "denied_providers": r.DeniedProviders} {
+		for _, id := range providers {
+			if !common.IsHexAddress(id) {
+				errs.ForField(field).Invalid(fmt.Sprintf("Invalid provider identity: %s", id))
+			}
+		}
+	}
+	return errs
+}
+
+func isNATCompatibilityType(natType nat.NATType) bool {
+	for _, t := range natCompatibilityTypes {
+		if t == natType {
+			return true
+		}
+	}
+	return false
+}
+
+// ToFilterPreset converts request to user proposal filter preset with the given ID.
+func (r FilterPresetRequest) ToFilterPreset(id int) proposal.FilterPreset {
+	return proposal.FilterPreset{
+		ID:               id,
+		Name:             strings.TrimSpace(r.Name),
+		IPType:           proposal.IPType(r.IPType),
+		Country:          strings.ToUpper(r.Country),
+		PriceHourMax:     r.PriceHourMax,
+		PriceGiBMax:      r.PriceGiBMax,
+		QualityMin:       r.QualityMin,
+		NATCompatibility: nat.NATType(r.NATCompatibility),
+		AllowedProviders: toLower(r.AllowedProviders),
+		DeniedProviders:  toLower(r.DeniedProviders),
+	}
+}
+
+func toLower(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	res := make([]string, len(values))
+	for i, v := range values {
+		res[i] = strings.ToLower(v)
 	}
+	return res
 }
 
 // ServiceLocationDTO holds service location metadata.
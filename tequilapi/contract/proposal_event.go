@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/stream"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// ProposalEventDTO represents a change of the proposal matching the watched filter.
+// swagger:model ProposalEventDTO
+type ProposalEventDTO struct {
+	// one of "added", "updated" or "removed"
+	// example: added
+	Type     string      `json:"type"`
+	Proposal ProposalDTO `json:"proposal"`
+}
+
+// NewProposalEventDTO maps to API proposal event.
+func NewProposalEventDTO(e stream.Event) ProposalEventDTO {
+	return ProposalEventDTO{
+		Type:     string(e.Type),
+		Proposal: NewProposalDTO(e.Proposal),
+	}
+}
+
+// ProposalWebhookRequest request used to register a proposal webhook.
+// swagger:model ProposalWebhookRequestDTO
+type ProposalWebhookRequest struct {
+	// URL proposal events are posted to
+	// required: true
+	// example: https://example.com/proposals
+	URL string `json:"url"`
+	// URL encoded query parameters of GET /proposals to match proposals by
+	// required: false
+	// example: location_country=JP&ip_type=residential&query=price.gib%3C0.1
+	Filter string `json:"filter,omitempty"`
+}
+
+// Validate validates fields in request.
+func (r ProposalWebhookRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if len(r.URL) == 0 {
+		errs.ForField("url").Required()
+	} else if u, err := url.Parse(r.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.ForField("url").Invalid("Must be an absolute http or https URL")
+	}
+	if _, err := url.ParseQuery(r.Filter); err != nil {
+		errs.ForField("filter").Invalid(err.Error())
+	}
+	return errs
+}
+
+// ProposalWebhookDTO represents registered proposal webhook.
+// swagger:model ProposalWebhookDTO
+type ProposalWebhookDTO struct {
+	// example: 4c3b8a7e-4d34-4a52-a1c1-d1ba4e9c9a3e
+	ID string `json:"id"`
+	// example: https://example.com/proposals
+	URL string `json:"url"`
+	// example: location_country=JP&ip_type=residential
+	Filter string `json:"filter,omitempty"`
+	// example: 2021-07-01T12:00:00Z
+	CreatedAt string `json:"created_at"`
+}
+
+// NewProposalWebhookDTO maps to API proposal webhook.
+func NewProposalWebhookDTO(w stream.Webhook) ProposalWebhookDTO {
+	return ProposalWebhookDTO{
+		ID:        w.ID,
+		URL:       w.URL,
+		Filter:    w.Filter,
+		CreatedAt: w.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ProposalWebhookListResponse represents registered proposal webhooks.
+// swagger:model ProposalWebhookListResponse
+type ProposalWebhookListResponse []ProposalWebhookDTO
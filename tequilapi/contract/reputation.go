@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/reputation"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+)
+
+// ReputationDTO is the personal consumer view of the provider attached to proposals.
+// swagger:model ReputationDTO
+type ReputationDTO struct {
+	// score in range [0, 1] built from own sessions with the provider, providers without history score 0.5
+	// example: 0.72
+	Score float64 `json:"score"`
+	// provider is marked as favourite
+	Favourite bool `json:"favourite,omitempty"`
+	// provider is blocked, blocked providers are listed only when requested explicitly
+	Blocked bool `json:"blocked,omitempty"`
+}
+
+// NewReputationDTO maps proposal reputation to DTO, proposals listed without reputation get nil.
+func NewReputationDTO(r proposal.Reputation) *ReputationDTO {
+	if r == (proposal.Reputation{}) {
+		return nil
+	}
+	return &ReputationDTO{
+		Score:     r.Score,
+		Favourite: r.Favourite,
+		Blocked:   r.Blocked,
+	}
+}
+
+// ProviderReputationRequest request used to mark provider as favourite or blocked.
+// swagger:model ProviderReputationRequestDTO
+type ProviderReputationRequest struct {
+	// mark provider as favourite, omitted value keeps the current one
+	// required: false
+	// example: true
+	Favourite *bool `json:"favourite,omitempty"`
+	// block provider, omitted value keeps the current one
+	// required: false
+	// example: false
+	Blocked *bool `json:"blocked,omitempty"`
+}
+
+// ProviderReputationDTO represents the personal reputation of the provider.
+// swagger:model ProviderReputationDTO
+type ProviderReputationDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	ProviderID string `json:"provider_id"`
+	ReputationDTO
+	// example: 12
+	ConnectSuccesses int `json:"connect_successes"`
+	// example: 1
+	ConnectFailures int `json:"connect_failures"`
+	// moving average of the time to connect in milliseconds
+	// example: 3400
+	ConnectTimeMs int64 `json:"connect_time_ms,omitempty"`
+	// moving average of the session throughput in bytes per second
+	// example: 524288
+	Throughput uint64 `json:"throughput,omitempty"`
+	// count of sessions by the state which ended them
+	// example: {"NotConnected": 2, "Disconnecting": 8}
+	DisconnectReasons map[string]int `json:"disconnect_reasons,omitempty"`
+	// example: 2021-07-30T10:00:00Z
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// NewProviderReputationDTO maps provider reputation record to DTO.
+func NewProviderReputationDTO(r reputation.Record) ProviderReputationDTO {
+	dto := ProviderReputationDTO{
+		ProviderID: r.ProviderID,
+		ReputationDTO: ReputationDTO{
+			Score:     r.Score(),
+			Favourite: r.Favourite,
+			Blocked:   r.Blocked,
+		},
+		ConnectSuccesses:  r.ConnectSuccesses,
+		ConnectFailures:   r.ConnectFailures,
+		ConnectTimeMs:     r.ConnectTime.Milliseconds(),
+		Throughput:        uint64(r.Throughput),
+		DisconnectReasons: r.DisconnectReasons,
+	}
+	if !r.UpdatedAt.IsZero() {
+		dto.UpdatedAt = r.UpdatedAt.Format(time.RFC3339)
+	}
+	return dto
+}
+
+// ProviderReputationListResponse represents a list of provider reputations.
+// swagger:model ProviderReputationListResponse
+type ProviderReputationListResponse []ProviderReputationDTO
+
+// NewProviderReputationListResponse maps provider reputation records to DTO, best providers first.
+func NewProviderReputationListResponse(records []reputation.Record) ProviderReputationListResponse {
+	res := ProviderReputationListResponse{}
+	for _, r := range records {
+		res = append(res, NewProviderReputationDTO(r))
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Favourite != res[j].Favourite {
+			return res[i].Favourite
+		}
+		return res[i].Score > res[j].Score
+	})
+	return res
+}
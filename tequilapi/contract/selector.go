@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"math/big"
+
+	"github.com/mysteriumnetwork/node/core/discovery/selector"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// ProviderSelectorDTO describes how to pick the best provider automatically.
+// swagger:model ProviderSelectorDTO
+type ProviderSelectorDTO struct {
+	// relative importance of proposal properties, default weights are used when omitted or all zero
+	// required: false
+	Weights *SelectorWeightsDTO `json:"weights,omitempty"`
+	// providers charging more per GiB are skipped
+	// required: false
+	// example: 100000000000000000
+	MaxPricePerGiB *big.Int `json:"max_price_gib,omitempty"`
+	// providers charging more per hour are skipped
+	// required: false
+	// example: 50000000000000000
+	MaxPricePerHour *big.Int `json:"max_price_hour,omitempty"`
+}
+
+// SelectorWeightsDTO represents relative importance of proposal properties.
+// swagger:model SelectorWeightsDTO
+type SelectorWeightsDTO struct {
+	// example: 3
+	Quality float64 `json:"quality"`
+	// example: 2
+	Bandwidth float64 `json:"bandwidth"`
+	// example: 2
+	Latency float64 `json:"latency"`
+	// example: 2
+	PriceGiB float64 `json:"price_gib"`
+	// example: 1
+	PriceHour float64 `json:"price_hour"`
+}
+
+// ToSpec converts DTO to provider selector spec.
+func (dto ProviderSelectorDTO) ToSpec() selector.Spec {
+	spec := selector.Spec{
+		MaxPricePerGiB:  dto.MaxPricePerGiB,
+		MaxPricePerHour: dto.MaxPricePerHour,
+	}
+	if dto.Weights != nil {
+		spec.Weights = selector.Weights{
+			Quality:   dto.Weights.Quality,
+			Bandwidth: dto.Weights.Bandwidth,
+			Latency:   dto.Weights.Latency,
+			PriceGiB:  dto.Weights.PriceGiB,
+			PriceHour: dto.Weights.PriceHour,
+		}
+	}
+	return spec
+}
+
+// ProposalSelectRequest request used to preview the provider picked by the selector.
+// swagger:model ProposalSelectRequestDTO
+type ProposalSelectRequest struct {
+	// consumer identity whose balance is used to estimate entertainment at the selected price
+	// required: false
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id,omitempty"`
+	// required: false
+	// example: wireguard
+	ServiceType string `json:"service_type,omitempty"`
+	// required: false
+	// example: DE
+	CountryCode string `json:"country_code,omitempty"`
+	// required: false
+	// example: 1
+	PresetID int                 `json:"preset_id,omitempty"`
+	Selector ProviderSelectorDTO `json:"selector"`
+}
+
+// Validate validates fields in request.
+func (r ProposalSelectRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if err := r.Selector.ToSpec().Validate(); err != nil {
+		errs.ForField("selector").Invalid(err.Error())
+	}
+	return errs
+}
+
+// ProposalSelectResponse represents the provider picked by the selector.
+// swagger:model ProposalSelectResponse
+type ProposalSelectResponse struct {
+	Proposal ProposalDTO `json:"proposal"`
+	// selector score in range [0, 1]
+	// example: 0.82
+	Score float64 `json:"score"`
+	// how far the consumer balance goes at the selected provider price
+	Estimates *EntertainmentEstimateResponse `json:"estimates,omitempty"`
+}
@@ -17,6 +17,11 @@
 
 package contract
 
+import (
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
 // ServiceStartRequest request used to start a service.
 // swagger:model ServiceStartRequestDTO
 type ServiceStartRequest struct {
@@ -25,7 +30,7 @@ type ServiceStartRequest struct {
 	// example: 0x0000000000000000000000000000000000000002
 	ProviderID string `json:"provider_id"`
 
-	// service type. Possible values are "openvpn", "wireguard" and "noop"
+	// service type. Possible values are "openvpn", "wireguard", "noop" and "proxy"
 	// required: true
 	// example: openvpn
 	Type string `json:"type"`
@@ -34,6 +39,10 @@ type ServiceStartRequest struct {
 	// required: false
 	AccessPolicies ServiceAccessPolicies `json:"access_policies"`
 
+	// limits of the load accepted by the service, defaults are taken from the node configuration
+	// required: false
+	Capacity *ServiceCapacityDTO `json:"capacity,omitempty"`
+
 	// service options. Every service has a unique list of allowed options.
 	// required: false
 	// example: {"port": 1123, "protocol": "udp"}
@@ -46,6 +55,97 @@ type ServiceAccessPolicies struct {
 	IDs []string `json:"ids"`
 }
 
+// ServiceCapacityDTO represents the limits of the load accepted by the service, zero value disables the limit.
+// swagger:model ServiceCapacityDTO
+type ServiceCapacityDTO struct {
+	// max count of concurrent sessions served by the service
+	// example: 50
+	MaxSessions int `json:"max_sessions"`
+
+	// max count of concurrent sessions single consumer may have with the provider
+	// example: 2
+	MaxConsumerSessions int `json:"max_consumer_sessions"`
+
+	// share of CPU time in percent which has to stay idle
+	// example: 20
+	MinFreeCPU float64 `json:"min_free_cpu"`
+
+	// memory in MiB which has to stay available
+	// example: 256
+	MinFreeMemory uint64 `json:"min_free_memory"`
+
+	// uplink bandwidth of the provider in Mbit/s, it is required to keep bandwidth headroom
+	// example: 100
+	Bandwidth uint64 `json:"bandwidth"`
+
+	// uplink bandwidth in Mbit/s which has to stay unused
+	// example: 10
+	MinFreeBandwidth uint64 `json:"min_free_bandwidth"`
+}
+
+// NewServiceCapacityDTO maps service capacity to DTO.
+func NewServiceCapacityDTO(c service.Capacity) ServiceCapacityDTO {
+	return ServiceCapacityDTO{
+		MaxSessions:         c.MaxSessions,
+		MaxConsumerSessions: c.MaxConsumerSessions,
+		MinFreeCPU:          c.MinFreeCPU,
+		MinFreeMemory:       c.MinFreeMemory,
+		Bandwidth:           c.Bandwidth,
+		MinFreeBandwidth:    c.MinFreeBandwidth,
+	}
+}
+
+// ToCapacity maps DTO to service capacity.
+func (c ServiceCapacityDTO) ToCapacity() service.Capacity {
+	return service.Capacity{
+		MaxSessions:         c.MaxSessions,
+		MaxConsumerSessions: c.MaxConsumerSessions,
+		MinFreeCPU:          c.MinFreeCPU,
+		MinFreeMemory:       c.MinFreeMemory,
+		Bandwidth:           c.Bandwidth,
+		MinFreeBandwidth:    c.MinFreeBandwidth,
+	}
+}
+
+// Validate validates fields of the capacity.
+func (c ServiceCapacityDTO) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if c.MaxSessions < 0 {
+		errs.ForField("capacity.max_sessions").Invalid("Must not be negative")
+	}
+	if c.MaxConsumerSessions < 0 {
+		errs.ForField("capacity.max_consumer_sessions").Invalid("Must not be negative")
+	}
+	if c.MinFreeCPU < 0 || c.MinFreeCPU >= 100 {
+		errs.ForField("capacity.min_free_cpu").Invalid("Must be from 0 to 100")
+	}
+	if c.MinFreeBandwidth > 0 && c.Bandwidth == 0 {
+		errs.ForField("capacity.bandwidth").Invalid("Bandwidth is required to keep bandwidth headroom")
+	}
+	if c.Bandwidth > 0 && c.MinFreeBandwidth >= c.Bandwidth {
+		errs.ForField("capacity.min_free_bandwidth").Invalid("Must be less than bandwidth")
+	}
+	return errs
+}
+
+// ServiceDrainRequest request used to drain a service.
+// swagger:model ServiceDrainRequestDTO
+type ServiceDrainRequest struct {
+	// seconds to wait for sessions to end before they are closed, node default is used if not set
+	// required: false
+	// example: 600
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// Validate validates fields in request.
+func (r ServiceDrainRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if r.Timeout < 0 {
+		errs.ForField("timeout").Invalid("Must not be negative")
+	}
+	return errs
+}
+
 // ServiceListResponse represents a list of running services on the node.
 // swagger:model ServiceListResponse
 type ServiceListResponse []ServiceInfoDTO
@@ -60,7 +160,7 @@ type ServiceInfoDTO struct {
 	// example: 0x0000000000000000000000000000000000000002
 	ProviderID string `json:"provider_id"`
 
-	// service type. Possible values are "openvpn", "wireguard" and "noop"
+	// service type. Possible values are "openvpn", "wireguard", "noop" and "proxy"
 	// example: openvpn
 	Type string `json:"type"`
 
@@ -68,6 +168,9 @@ type ServiceInfoDTO struct {
 	// example: {"port": 1123, "protocol": "udp"}
 	Options interface{} `json:"options"`
 
+	// limits of the load accepted by the service
+	Capacity ServiceCapacityDTO `json:"capacity"`
+
 	// example: Running
 	Status string `json:"status"`
 
@@ -0,0 +1,143 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// ServiceSessionListResponse represents a list of live sessions of the service.
+// swagger:model ServiceSessionListResponse
+type ServiceSessionListResponse struct {
+	Sessions []ServiceSessionDTO `json:"sessions"`
+}
+
+// ServiceSessionDTO represents live session between service consumer and provider.
+// swagger:model ServiceSessionDTO
+type ServiceSessionDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	ID string `json:"id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// example: NL
+	ConsumerCountry string `json:"consumer_country"`
+
+	// addresses assigned to the consumer inside the tunnel, empty when the service doesn't report them
+	// example: ["10.182.0.2"]
+	TunnelIPs []string `json:"tunnel_ips"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	CreatedAt string `json:"created_at"`
+
+	// duration in seconds
+	// example: 120
+	Duration uint64 `json:"duration"`
+
+	// example: 1024
+	BytesReceived uint64 `json:"bytes_received"`
+
+	// example: 1024
+	BytesSent uint64 `json:"bytes_sent"`
+
+	// tokens earned so far
+	// example: 500000
+	Tokens *big.Int `json:"tokens"`
+}
+
+// NewServiceSessionDTO maps live session to DTO, traffic and earnings are filled in by the caller.
+func NewServiceSessionDTO(sess *service.Session) ServiceSessionDTO {
+	tunnelIPs := make([]string, 0)
+	for _, ip := range sess.TunnelIPs() {
+		tunnelIPs = append(tunnelIPs, ip.String())
+	}
+
+	return ServiceSessionDTO{
+		ID:              string(sess.ID),
+		ConsumerID:      sess.ConsumerID.Address,
+		ConsumerCountry: sess.ConsumerLocation.Country,
+		TunnelIPs:       tunnelIPs,
+		CreatedAt:       sess.CreatedAt.Format(time.RFC3339),
+		Duration:        uint64(time.Since(sess.CreatedAt).Seconds()),
+		Tokens:          new(big.Int),
+	}
+}
+
+// ConsumerBanRequest request used to ban consumer identity.
+// swagger:model ConsumerBanRequestDTO
+type ConsumerBanRequest struct {
+	// consumer identity to ban
+	// required: true
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+	// reason of the ban, for the provider's own records
+	// required: false
+	// example: abuse
+	Reason string `json:"reason,omitempty"`
+}
+
+// Validate validates fields in request.
+func (r ConsumerBanRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if r.ConsumerID == "" {
+		errs.ForField("consumer_id").Required()
+	}
+	return errs
+}
+
+// ConsumerBanListResponse represents the list of banned consumers.
+// swagger:model ConsumerBanListResponse
+type ConsumerBanListResponse struct {
+	Bans []ConsumerBanDTO `json:"bans"`
+}
+
+// NewConsumerBanListResponse maps ban list to DTO.
+func NewConsumerBanListResponse(bans []service.Ban) ConsumerBanListResponse {
+	res := ConsumerBanListResponse{Bans: make([]ConsumerBanDTO, 0, len(bans))}
+	for _, ban := range bans {
+		res.Bans = append(res.Bans, NewConsumerBanDTO(ban))
+	}
+	return res
+}
+
+// ConsumerBanDTO represents banned consumer.
+// swagger:model ConsumerBanDTO
+type ConsumerBanDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// example: abuse
+	Reason string `json:"reason,omitempty"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	CreatedAt string `json:"created_at"`
+}
+
+// NewConsumerBanDTO maps ban to DTO.
+func NewConsumerBanDTO(ban service.Ban) ConsumerBanDTO {
+	return ConsumerBanDTO{
+		ConsumerID: ban.ConsumerID,
+		Reason:     ban.Reason,
+		CreatedAt:  ban.CreatedAt.Format(time.RFC3339),
+	}
+}
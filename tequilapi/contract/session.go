@@ -258,6 +258,8 @@ func NewSessionDTO(se session.History) SessionDTO {
 		Tokens:          se.Tokens,
 		Status:          se.Status,
 		IPType:          se.IPType,
+		BandwidthUp:     se.BandwidthUp,
+		BandwidthDown:   se.BandwidthDown,
 	}
 }
 
@@ -309,4 +311,12 @@ type SessionDTO struct {
 
 	// example: residential
 	IPType string `json:"ip_type"`
+
+	// upload limit of provided session in Kbytes/s, omitted if unlimited
+	// example: 1024
+	BandwidthUp uint64 `json:"bandwidth_up,omitempty"`
+
+	// download limit of provided session in Kbytes/s, omitted if unlimited
+	// example: 1024
+	BandwidthDown uint64 `json:"bandwidth_down,omitempty"`
 }
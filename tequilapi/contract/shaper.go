@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// ShaperLimitsDTO holds bandwidth limits of a session in Kbytes per second, zero means unlimited.
+// swagger:model ShaperLimitsDTO
+type ShaperLimitsDTO struct {
+	// traffic sent by consumer
+	// example: 512
+	Up uint64 `json:"up"`
+
+	// traffic received by consumer
+	// example: 1024
+	Down uint64 `json:"down"`
+}
+
+// ShaperRulesDTO holds bandwidth limit rules of provided sessions.
+// swagger:model ShaperRulesDTO
+type ShaperRulesDTO struct {
+	// limits of every consumer, shaper flags are used if omitted
+	Default *ShaperLimitsDTO `json:"default,omitempty"`
+
+	// limits keyed by consumer identity, take precedence over policy limits
+	// example: {"0x0000000000000000000000000000000000000001": {"up": 0, "down": 2048}}
+	Identities map[string]ShaperLimitsDTO `json:"identities"`
+
+	// limits keyed by ID of access policy which allows the consumer
+	// example: {"mysterium": {"up": 512, "down": 1024}}
+	Policies map[string]ShaperLimitsDTO `json:"policies"`
+}
+
+// NewShaperRulesDTO maps to API shaper rules.
+func NewShaperRulesDTO(rules shaper.Rules) ShaperRulesDTO {
+	dto := ShaperRulesDTO{
+		Identities: make(map[string]ShaperLimitsDTO, len(rules.Identities)),
+		Policies:   make(map[string]ShaperLimitsDTO, len(rules.Policies)),
+	}
+	if rules.Default != nil {
+		dto.Default = &ShaperLimitsDTO{Up: rules.Default.Up, Down: rules.Default.Down}
+	}
+	for id, limits := range rules.Identities {
+		dto.Identities[id] = ShaperLimitsDTO{Up: limits.Up, Down: limits.Down}
+	}
+	for id, limits := range rules.Policies {
+		dto.Policies[id] = ShaperLimitsDTO{Up: limits.Up, Down: limits.Down}
+	}
+	return dto
+}
+
+// Validate validates fields in request.
+func (r ShaperRulesDTO) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if _, ok := r.Identities[""]; ok {
+		errs.ForField("identities").Invalid("Identity must not be empty")
+	}
+	if _, ok := r.Policies[""]; ok {
+		errs.ForField("policies").Invalid("Policy ID must not be empty")
+	}
+	return errs
+}
+
+// ToRules converts request to shaper rules.
+func (r ShaperRulesDTO) ToRules() shaper.Rules {
+	rules := shaper.Rules{
+		Identities: make(map[string]shaper.Limits, len(r.Identities)),
+		Policies:   make(map[string]shaper.Limits, len(r.Policies)),
+	}
+	if r.Default != nil {
+		rules.Default = &shaper.Limits{Up: r.Default.Up, Down: r.Default.Down}
+	}
+	for address, limits := range r.Identities {
+		rules.Identities[identity.FromAddress(address).Address] = shaper.Limits{Up: limits.Up, Down: limits.Down}
+	}
+	for id, limits := range r.Policies {
+		rules.Policies[id] = shaper.Limits{Up: limits.Up, Down: limits.Down}
+	}
+	return rules
+}
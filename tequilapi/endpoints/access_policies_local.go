@@ -0,0 +1,231 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type localPolicyStorage interface {
+	List() ([]market.AccessPolicyRuleSet, error)
+	Get(id string) (market.AccessPolicyRuleSet, error)
+	Save(rules market.AccessPolicyRuleSet) error
+	Delete(id string) error
+}
+
+type localAccessPoliciesEndpoint struct {
+	policies localPolicyStorage
+}
+
+// NewLocalAccessPoliciesEndpoint creates and returns endpoint managing access policies defined by the provider
+func NewLocalAccessPoliciesEndpoint(policies localPolicyStorage) *localAccessPoliciesEndpoint {
+	return &localAccessPoliciesEndpoint{policies: policies}
+}
+
+// List returns local access policies
+// swagger:operation GET /access-policies/local AccessPolicies localAccessPolicyList
+// ---
+// summary: Returns local access policies
+// description: Returns access policies defined by the provider, they are applied to every provided service
+// responses:
+//   200:
+//     description: List of local access policies
+//     schema:
+//       "$ref": "#/definitions/LocalAccessPolicyListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (lpe *localAccessPoliciesEndpoint) List(c *gin.Context) {
+	policies, err := lpe.policies.List()
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewLocalAccessPolicyListResponse(policies), c.Writer)
+}
+
+// Get returns local access policy
+// swagger:operation GET /access-policies/local/{id} AccessPolicies localAccessPolicyGet
+// ---
+// summary: Returns local access policy
+// description: Returns access policy defined by the provider
+// parameters:
+//   - name: id
+//     in: path
+//     description: Policy id
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Local access policy
+//     schema:
+//       "$ref": "#/definitions/LocalAccessPolicyDTO"
+//   404:
+//     description: Policy not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (lpe *localAccessPoliciesEndpoint) Get(c *gin.Context) {
+	rules, err := lpe.policies.Get(c.Param("id"))
+	if err == policy.ErrLocalPolicyNotFound {
+		utils.SendError(c.Writer, err, http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewLocalAccessPolicyDTO(rules), c.Writer)
+}
+
+// Save creates or replaces local access policy
+// swagger:operation PUT /access-policies/local/{id} AccessPolicies localAccessPolicySave
+// ---
+// summary: Creates or replaces local access policy
+// description: Stores access policy defined by the provider, running services apply it to new sessions
+// parameters:
+//   - name: id
+//     in: path
+//     description: Policy id
+//     type: string
+//     required: true
+//   - in: body
+//     name: body
+//     description: Access policy
+//     schema:
+//       $ref: "#/definitions/LocalAccessPolicyDTO"
+// responses:
+//   200:
+//     description: Policy stored
+//     schema:
+//       "$ref": "#/definitions/LocalAccessPolicyDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Policy is loaded from file and can not be changed
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (lpe *localAccessPoliciesEndpoint) Save(c *gin.Context) {
+	var req contract.LocalAccessPolicyDTO
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if req.ID == "" {
+		req.ID = c.Param("id")
+	}
+	errorMap := req.Validate()
+	if req.ID != c.Param("id") {
+		errorMap.ForField("id").Invalid("Policy id does not match the path")
+	}
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(c.Writer, errorMap)
+		return
+	}
+
+	rules := req.ToRuleSet()
+	err := lpe.policies.Save(rules)
+	if err == policy.ErrLocalPolicyReadOnly {
+		utils.SendError(c.Writer, err, http.StatusConflict)
+		return
+	} else if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewLocalAccessPolicyDTO(rules), c.Writer)
+}
+
+// Delete removes local access policy
+// swagger:operation DELETE /access-policies/local/{id} AccessPolicies localAccessPolicyDelete
+// ---
+// summary: Removes local access policy
+// description: Removes access policy defined by the provider, running services stop applying it to new sessions
+// parameters:
+//   - name: id
+//     in: path
+//     description: Policy id
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: Policy removed
+//   404:
+//     description: Policy not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Policy is loaded from file and can not be removed
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (lpe *localAccessPoliciesEndpoint) Delete(c *gin.Context) {
+	err := lpe.policies.Delete(c.Param("id"))
+	switch err {
+	case nil:
+		c.Writer.WriteHeader(http.StatusAccepted)
+	case policy.ErrLocalPolicyNotFound:
+		utils.SendError(c.Writer, err, http.StatusNotFound)
+	case policy.ErrLocalPolicyReadOnly:
+		utils.SendError(c.Writer, err, http.StatusConflict)
+	default:
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+	}
+}
+
+// AddRoutesForLocalAccessPolicies attaches endpoints managing access policies defined by the provider
+func AddRoutesForLocalAccessPolicies(policies localPolicyStorage) func(*gin.Engine) error {
+	lpe := NewLocalAccessPoliciesEndpoint(policies)
+	return func(e *gin.Engine) error {
+		g := e.Group("/access-policies/local")
+		{
+			g.GET("", lpe.List)
+			g.GET("/:id", lpe.Get)
+			g.PUT("/:id", lpe.Save)
+			g.DELETE("/:id", lpe.Delete)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type mockLocalPolicyStorage struct {
+	policies []market.AccessPolicyRuleSet
+	readOnly map[string]bool
+}
+
+func (m *mockLocalPolicyStorage) List() ([]market.AccessPolicyRuleSet, error) {
+	return m.policies, nil
+}
+
+func (m *mockLocalPolicyStorage) Get(id string) (market.AccessPolicyRuleSet, error) {
+	for _, rules := range m.policies {
+		if rules.ID == id {
+			return rules, nil
+		}
+	}
+	return market.AccessPolicyRuleSet{}, policy.ErrLocalPolicyNotFound
+}
+
+func (m *mockLocalPolicyStorage) Save(rules market.AccessPolicyRuleSet) error {
+	if m.readOnly[rules.ID] {
+		return policy.ErrLocalPolicyReadOnly
+	}
+	m.policies = append(m.policies, rules)
+	return nil
+}
+
+func (m *mockLocalPolicyStorage) Delete(id string) error {
+	if m.readOnly[id] {
+		return policy.ErrLocalPolicyReadOnly
+	}
+	for i, rules := range m.policies {
+		if rules.ID == id {
+			m.policies = append(m.policies[:i], m.policies[i+1:]...)
+			return nil
+		}
+	}
+	return policy.ErrLocalPolicyNotFound
+}
+
+func Test_LocalAccessPolicies_List(t *testing.T) {
+	// given
+	storage := &mockLocalPolicyStorage{
+		policies: []market.AccessPolicyRuleSet{{
+			ID:   "no-mail",
+			Deny: []market.AccessRule{{Type: market.AccessPolicyTypeDestinationPort, Value: "tcp/25"}},
+		}},
+	}
+	router := gin.Default()
+	err := AddRoutesForLocalAccessPolicies(storage)(router)
+	assert.NoError(t, err)
+
+	// when
+	req := httptest.NewRequest(http.MethodGet, "/access-policies/local", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	// then
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t,
+		`{
+			"entries": [
+				{
+					"id": "no-mail",
+					"allow": [],
+					"deny": [{"type": "destination_port", "value": "tcp/25"}]
+				}
+			]
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func Test_LocalAccessPolicies_Save(t *testing.T) {
+	// given
+	storage := &mockLocalPolicyStorage{readOnly: map[string]bool{"from-file": true}}
+	router := gin.Default()
+	err := AddRoutesForLocalAccessPolicies(storage)(router)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		body     string
+		wantCode int
+	}{
+		{
+			path:     "/access-policies/local/no-torrents",
+			body:     `{"deny": [{"type": "protocol", "value": "bittorrent"}]}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			path:     "/access-policies/local/no-torrents",
+			body:     `{"deny": [{"type": "protocol", "value": "gopher"}]}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			path:     "/access-policies/local/no-torrents",
+			body:     `{"id": "other", "deny": [{"type": "protocol", "value": "bittorrent"}]}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			path:     "/access-policies/local/from-file",
+			body:     `{"deny": [{"type": "protocol", "value": "smtp"}]}`,
+			wantCode: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		// when
+		req := httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		// then
+		assert.Equal(t, tt.wantCode, resp.Code, tt.body)
+	}
+	assert.Len(t, storage.policies, 1)
+	assert.Equal(t, "no-torrents", storage.policies[0].ID)
+}
+
+func Test_LocalAccessPolicies_Delete(t *testing.T) {
+	// given
+	storage := &mockLocalPolicyStorage{
+		policies: []market.AccessPolicyRuleSet{{ID: "no-mail"}},
+		readOnly: map[string]bool{"from-file": true},
+	}
+	router := gin.Default()
+	err := AddRoutesForLocalAccessPolicies(storage)(router)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		id       string
+		wantCode int
+	}{
+		{id: "no-mail", wantCode: http.StatusAccepted},
+		{id: "no-mail", wantCode: http.StatusNotFound},
+		{id: "from-file", wantCode: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		// when
+		req := httptest.NewRequest(http.MethodDelete, "/access-policies/local/"+tt.id, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		// then
+		assert.Equal(t, tt.wantCode, resp.Code, tt.id)
+	}
+}
@@ -63,10 +63,11 @@ type ConnectionEndpoint struct {
 	proposalRepository proposalRepository
 	identityRegistry   identityRegistry
 	addressProvider    addressProvider
+	prober             latencyProber
 }
 
 // NewConnectionEndpoint creates and returns connection endpoint
-func NewConnectionEndpoint(manager connection.Manager, stateProvider stateProvider, proposalRepository proposalRepository, identityRegistry identityRegistry, publisher eventbus.Publisher, addressProvider addressProvider) *ConnectionEndpoint {
+func NewConnectionEndpoint(manager connection.Manager, stateProvider stateProvider, proposalRepository proposalRepository, identityRegistry identityRegistry, publisher eventbus.Publisher, addressProvider addressProvider, prober latencyProber) *ConnectionEndpoint {
 	return &ConnectionEndpoint{
 		manager:            manager,
 		publisher:          publisher,
@@ -74,6 +75,7 @@ func NewConnectionEndpoint(manager connection.Manager, stateProvider stateProvid
 		proposalRepository: proposalRepository,
 		identityRegistry:   identityRegistry,
 		addressProvider:    addressProvider,
+		prober:             prober,
 	}
 }
 
@@ -105,7 +107,7 @@ func (ce *ConnectionEndpoint) Status(c *gin.Context) {
 // parameters:
 //   - in: body
 //     name: body
-//     description: Parameters in body (consumer_id, provider_id, service_type) required for creating new connection
+//     description: Parameters in body (consumer_id, provider_id, service_type) required for creating new connection, instead of provider_id filter.selector can pick the best provider within price caps
 //     schema:
 //       $ref: "#/definitions/ConnectionCreateRequestDTO"
 // responses:
@@ -193,9 +195,14 @@ func (ce *ConnectionEndpoint) Create(c *gin.Context) {
 		cr.Filter.Providers = append(cr.Filter.Providers, cr.ProviderID)
 	}
 
-	proposalLookup := filteredProposals(cr.ServiceType, cr.Filter, ce.proposalRepository)
+	proposalLookup := ce.filteredProposals(consumerID, cr.ServiceType, cr.Filter)
 
-	err = ce.manager.Connect(consumerID, common.HexToAddress(cr.HermesID), proposalLookup, getConnectOptions(cr))
+	connectOptions := getConnectOptions(cr)
+	for _, hop := range cr.Hops {
+		connectOptions.Hops = append(connectOptions.Hops, ce.filteredProposals(consumerID, cr.ServiceType, hop))
+	}
+
+	err = ce.manager.Connect(consumerID, common.HexToAddress(cr.HermesID), proposalLookup, connectOptions)
 	if err != nil {
 		switch err {
 		case connection.ErrAlreadyExists:
@@ -270,11 +277,47 @@ func (ce *ConnectionEndpoint) GetStatistics(c *gin.Context) {
 	utils.WriteAsJSON(response, c.Writer)
 }
 
+// Diagnose runs active probes over the current connection
+// swagger:operation GET /connection/diagnostics Connection connectionDiagnostics
+// ---
+// summary: Runs connection diagnostics
+// description: Measures latency, jitter and packet loss to the provider, DNS resolution time, tunnel handshake age and throughput of the current connection. Intermediate results are streamed as "connection-diagnostics" events over SSE.
+// responses:
+//   200:
+//     description: Connection diagnostics
+//     schema:
+//       "$ref": "#/definitions/ConnectionDiagnosticsDTO"
+//   409:
+//     description: Conflict. No connection exists
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (ce *ConnectionEndpoint) Diagnose(c *gin.Context) {
+	resp := c.Writer
+
+	diagnostics, err := ce.manager.Diagnose(c.Request.Context())
+	if err != nil {
+		switch err {
+		case connection.ErrNoConnection:
+			utils.SendError(resp, err, http.StatusConflict)
+		default:
+			utils.SendError(resp, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewConnectionDiagnosticsDTO(diagnostics), resp)
+}
+
 type proposalRepository interface {
 	Proposal(id market.ProposalID) (*proposal.PricedServiceProposal, error)
 	Proposals(filter *proposal.Filter) ([]proposal.PricedServiceProposal, error)
 	Countries(filter *proposal.Filter) (map[string]int, error)
 	EnrichProposalWithPrice(in market.ServiceProposal) (proposal.PricedServiceProposal, error)
+	ProposalPrice(in market.ServiceProposal) (market.Price, error)
 }
 
 // AddRoutesForConnection adds connections routes to given router
@@ -285,8 +328,9 @@ func AddRoutesForConnection(
 	identityRegistry identityRegistry,
 	publisher eventbus.Publisher,
 	addressProvider addressProvider,
+	prober latencyProber,
 ) func(*gin.Engine) error {
-	connectionEndpoint := NewConnectionEndpoint(manager, stateProvider, proposalRepository, identityRegistry, publisher, addressProvider)
+	connectionEndpoint := NewConnectionEndpoint(manager, stateProvider, proposalRepository, identityRegistry, publisher, addressProvider, prober)
 	return func(e *gin.Engine) error {
 		connGroup := e.Group("")
 		{
@@ -294,6 +338,7 @@ func AddRoutesForConnection(
 			connGroup.PUT("/connection", connectionEndpoint.Create)
 			connGroup.DELETE("/connection", connectionEndpoint.Kill)
 			connGroup.GET("/connection/statistics", connectionEndpoint.GetStatistics)
+			connGroup.GET("/connection/diagnostics", connectionEndpoint.Diagnose)
 		}
 		return nil
 	}
@@ -323,23 +368,29 @@ func getConnectOptions(cr *contract.ConnectionCreateRequest) connection.ConnectP
 	return connection.ConnectParams{
 		DisableKillSwitch: cr.ConnectOptions.DisableKillSwitch,
 		DNS:               dns,
+		Failover:          connection.FailoverPolicy{MaxFailures: cr.ConnectOptions.FailoverMaxFailures},
+		SplitTunnel:       cr.ConnectOptions.SplitTunnel.ToSplitTunnel(),
+		SpendingLimits:    cr.ConnectOptions.SpendingLimits.ToSpendingLimits(),
 	}
 }
 
-func filteredProposals(serviceType string, filter contract.ConnectionCreateFilter, repo proposalRepository) connection.ProposalLookup {
+func (ce *ConnectionEndpoint) filteredProposals(consumerID identity.Identity, serviceType string, filter contract.ConnectionCreateFilter) connection.ProposalLookup {
 	f := &proposal.Filter{
 		ServiceType:             serviceType,
 		LocationCountry:         filter.CountryCode,
 		ProviderIDs:             filter.Providers,
 		IPType:                  filter.IPType,
 		IncludeMonitoringFailed: filter.IncludeMonitoringFailed,
+		IPv6:                    filter.IPv6,
+		PresetID:                filter.PresetID,
 		AccessPolicy:            "all",
+		ExcludeFull:             true,
 	}
 
 	usedProposals := make(map[string]time.Time)
 
 	return func() (*proposal.PricedServiceProposal, error) {
-		proposals, err := repo.Proposals(f)
+		proposals, err := ce.proposalRepository.Proposals(f)
 		if err != nil {
 			return nil, err
 		}
@@ -349,6 +400,14 @@ func filteredProposals(serviceType string, filter contract.ConnectionCreateFilte
 			return nil, fmt.Errorf("failed to sort proposals: %w", err)
 		}
 
+		if filter.Selector != nil {
+			proposals = rankedProposals(filter.Selector.ToSpec(), proposals)
+		}
+
+		if filter.FastestOf > 0 && ce.prober != nil {
+			proposals = fastestFirst(proposals, filter.FastestOf, consumerID, ce.prober)
+		}
+
 		for _, p := range proposals { // Trying to find providers that we didn't try to connect during 5 minutes.
 			if t, ok := usedProposals[p.ProviderID]; !ok || time.Since(t) > 5*time.Minute {
 				usedProposals[p.ProviderID] = time.Now()
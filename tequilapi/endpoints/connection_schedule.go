@@ -0,0 +1,286 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/connection/schedule"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type connectionScheduleStorage interface {
+	List() ([]schedule.Rule, error)
+	Get(id int) (schedule.Rule, error)
+	Save(rule *schedule.Rule) error
+	Delete(id int) error
+}
+
+type connectionScheduleEndpoint struct {
+	storage connectionScheduleStorage
+}
+
+// NewConnectionScheduleEndpoint creates and returns connection schedule endpoint
+func NewConnectionScheduleEndpoint(storage connectionScheduleStorage) *connectionScheduleEndpoint {
+	return &connectionScheduleEndpoint{
+		storage: storage,
+	}
+}
+
+// List returns connection schedule rules
+// swagger:operation GET /connection/schedules Connection connectionScheduleList
+// ---
+// summary: Returns connection schedule rules
+// description: Returns rules which connect at the given time and disconnect after limits are exceeded
+// responses:
+//   200:
+//     description: List of connection schedule rules
+//     schema:
+//       "$ref": "#/definitions/ConnectionScheduleListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (cse *connectionScheduleEndpoint) List(c *gin.Context) {
+	rules, err := cse.storage.List()
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewConnectionScheduleListResponse(rules), c.Writer)
+}
+
+// Get returns connection schedule rule
+// swagger:operation GET /connection/schedules/{id} Connection connectionScheduleGet
+// ---
+// summary: Returns connection schedule rule
+// description: Returns connection schedule rule by the requested id
+// parameters:
+//   - name: id
+//     in: path
+//     description: Rule id
+//     type: integer
+//     required: true
+// responses:
+//   200:
+//     description: Connection schedule rule
+//     schema:
+//       "$ref": "#/definitions/ConnectionScheduleDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Rule not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (cse *connectionScheduleEndpoint) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	rule, err := cse.storage.Get(id)
+	if err == schedule.ErrRuleNotFound {
+		utils.SendError(c.Writer, err, http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewConnectionScheduleDTO(rule), c.Writer)
+}
+
+// Create creates connection schedule rule
+// swagger:operation POST /connection/schedules Connection connectionScheduleCreate
+// ---
+// summary: Creates connection schedule rule
+// description: Creates rule which connects at the given time or disconnects after limits are exceeded
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/ConnectionScheduleRequestDTO"
+// responses:
+//   201:
+//     description: Connection schedule rule created
+//     schema:
+//       "$ref": "#/definitions/ConnectionScheduleDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (cse *connectionScheduleEndpoint) Create(c *gin.Context) {
+	cse.save(c, 0, http.StatusCreated)
+}
+
+// Update updates connection schedule rule
+// swagger:operation PUT /connection/schedules/{id} Connection connectionScheduleUpdate
+// ---
+// summary: Updates connection schedule rule
+// description: Replaces connection schedule rule by the requested id
+// parameters:
+//   - name: id
+//     in: path
+//     description: Rule id
+//     type: integer
+//     required: true
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/ConnectionScheduleRequestDTO"
+// responses:
+//   200:
+//     description: Connection schedule rule updated
+//     schema:
+//       "$ref": "#/definitions/ConnectionScheduleDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Rule not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (cse *connectionScheduleEndpoint) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := cse.storage.Get(id); err == schedule.ErrRuleNotFound {
+		utils.SendError(c.Writer, err, http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	cse.save(c, id, http.StatusOK)
+}
+
+func (cse *connectionScheduleEndpoint) save(c *gin.Context, id int, status int) {
+	var req contract.ConnectionScheduleRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(c.Writer, errorMap)
+		return
+	}
+
+	rule := req.ToRule(id)
+	if err := cse.storage.Save(&rule); err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewConnectionScheduleDTO(rule), c.Writer, status)
+}
+
+// Delete removes connection schedule rule
+// swagger:operation DELETE /connection/schedules/{id} Connection connectionScheduleDelete
+// ---
+// summary: Removes connection schedule rule
+// description: Removes connection schedule rule by the requested id
+// parameters:
+//   - name: id
+//     in: path
+//     description: Rule id
+//     type: integer
+//     required: true
+// responses:
+//   202:
+//     description: Connection schedule rule removed
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Rule not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (cse *connectionScheduleEndpoint) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	err = cse.storage.Delete(id)
+	if err == schedule.ErrRuleNotFound {
+		utils.SendError(c.Writer, err, http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Writer.WriteHeader(http.StatusAccepted)
+}
+
+// AddRoutesForConnectionSchedule adds connection schedule routes to given router
+func AddRoutesForConnectionSchedule(storage connectionScheduleStorage) func(*gin.Engine) error {
+	cse := NewConnectionScheduleEndpoint(storage)
+	return func(e *gin.Engine) error {
+		g := e.Group("/connection/schedules")
+		{
+			g.GET("", cse.List)
+			g.POST("", cse.Create)
+			g.GET("/:id", cse.Get)
+			g.PUT("/:id", cse.Update)
+			g.DELETE("/:id", cse.Delete)
+		}
+		return nil
+	}
+}
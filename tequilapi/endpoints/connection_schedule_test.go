@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection/schedule"
+)
+
+type mockConnectionScheduleStorage struct {
+	rules map[int]schedule.Rule
+}
+
+func (m *mockConnectionScheduleStorage) List() ([]schedule.Rule, error) {
+	var rules []schedule.Rule
+	for _, r := range m.rules {
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func (m *mockConnectionScheduleStorage) Get(id int) (schedule.Rule, error) {
+	r, ok := m.rules[id]
+	if !ok {
+		return r, schedule.ErrRuleNotFound
+	}
+	return r, nil
+}
+
+func (m *mockConnectionScheduleStorage) Save(rule *schedule.Rule) error {
+	if rule.ID == 0 {
+		rule.ID = len(m.rules) + 1
+	}
+	m.rules[rule.ID] = *rule
+	return nil
+}
+
+func (m *mockConnectionScheduleStorage) Delete(id int) error {
+	if _, ok := m.rules[id]; !ok {
+		return schedule.ErrRuleNotFound
+	}
+	delete(m.rules, id)
+	return nil
+}
+
+func Test_ConnectionSchedule(t *testing.T) {
+	storage := &mockConnectionScheduleStorage{rules: map[int]schedule.Rule{}}
+	g := gin.Default()
+	err := AddRoutesForConnectionSchedule(storage)(g)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedJSON   string
+	}{
+		{
+			http.MethodPost,
+			"/connection/schedules",
+			`{"connect_at": "08:30", "country_code": "DE", "disconnect_after_minutes": 60}`,
+			http.StatusUnprocessableEntity,
+			`{
+				"message": "validation_error",
+				"errors": {
+					"consumer_id": [ {"code": "required", "message": "Field is required"} ],
+					"service_type": [ {"code": "required", "message": "Field is required"} ]
+				}
+			}`,
+		},
+		{
+			http.MethodPost,
+			"/connection/schedules",
+			`{"consumer_id": "0x1", "service_type": "wireguard", "connect_at": "08:30", "country_code": "DE", "disconnect_after_minutes": 60}`,
+			http.StatusCreated,
+			`{
+				"id": 1,
+				"consumer_id": "0x1",
+				"service_type": "wireguard",
+				"country_code": "DE",
+				"connect_at": "08:30",
+				"disconnect_after_minutes": 60,
+				"reconnect_on_network_change": false
+			}`,
+		},
+		{
+			http.MethodPut,
+			"/connection/schedules/1",
+			`{"max_spend": 1000000000000000000, "reconnect_on_network_change": true}`,
+			http.StatusOK,
+			`{
+				"id": 1,
+				"max_spend": 1000000000000000000,
+				"reconnect_on_network_change": true
+			}`,
+		},
+		{
+			http.MethodGet,
+			"/connection/schedules",
+			"",
+			http.StatusOK,
+			`[{
+				"id": 1,
+				"max_spend": 1000000000000000000,
+				"reconnect_on_network_change": true
+			}]`,
+		},
+		{
+			http.MethodDelete,
+			"/connection/schedules/1",
+			"",
+			http.StatusAccepted,
+			"",
+		},
+		{
+			http.MethodGet,
+			"/connection/schedules/1",
+			"",
+			http.StatusNotFound,
+			`{"message": "schedule rule not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		g.ServeHTTP(resp, req)
+
+		assert.Equal(t, test.expectedStatus, resp.Code, test.method+" "+test.path)
+		if test.expectedJSON != "" {
+			assert.JSONEq(t, test.expectedJSON, resp.Body.String(), test.method+" "+test.path)
+		}
+	}
+}
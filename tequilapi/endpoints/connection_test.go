@@ -48,6 +48,8 @@ type mockConnectionManager struct {
 	onDisconnectReturn   error
 	onCheckChannelReturn error
 	onStatusReturn       connectionstate.Status
+	onDiagnoseReturn     connectionstate.Diagnostics
+	onDiagnoseErr        error
 	disconnectCount      int
 	requestedConsumerID  identity.Identity
 	requestedProvider    identity.Identity
@@ -85,6 +87,10 @@ func (cm *mockConnectionManager) Reconnect() {
 	return
 }
 
+func (cm *mockConnectionManager) Diagnose(context.Context) (connectionstate.Diagnostics, error) {
+	return cm.onDiagnoseReturn, cm.onDiagnoseErr
+}
+
 func mockRepositoryWithProposal(providerID, serviceType string) *mockProposalRepository {
 	sampleProposal := proposal.PricedServiceProposal{
 		ServiceProposal: market.ServiceProposal{
@@ -110,7 +116,7 @@ func TestAddRoutesForConnectionAddsRoutes(t *testing.T) {
 	fakeState.stateToReturn.Connection.Statistics = connectionstate.Statistics{BytesSent: 1, BytesReceived: 2}
 
 	mockedProposalProvider := mockRepositoryWithProposal("node1", "noop")
-	err := AddRoutesForConnection(fakeManager, fakeState, mockedProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(fakeManager, fakeState, mockedProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(router)
 	assert.NoError(t, err)
 
 	tests := []struct {
@@ -171,7 +177,7 @@ func TestStateIsReturnedFromStore(t *testing.T) {
 	}
 
 	router := gin.Default()
-	err := AddRoutesForConnection(manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(router)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodGet, "/connection", nil)
@@ -194,7 +200,7 @@ func TestPutReturns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
 	router := gin.Default()
-	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(router)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodPut, "/connection", strings.NewReader("a"))
@@ -215,7 +221,7 @@ func TestPutReturns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
 	router := gin.Default()
-	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(router)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodPut, "/connection", strings.NewReader("{}"))
@@ -257,7 +263,7 @@ func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := gin.Default()
-	err := AddRoutesForConnection(&fakeManager, fakeState, proposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, fakeState, proposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -297,7 +303,7 @@ func TestPutUnregisteredIdentityReturnsError(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := gin.Default()
-	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -329,7 +335,7 @@ func TestPutFailedRegistrationCheckReturnsError(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := gin.Default()
-	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -359,7 +365,7 @@ func TestPutWithServiceTypeOverridesDefault(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := gin.Default()
-	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -379,7 +385,7 @@ func TestDeleteCallsDisconnect(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := gin.Default()
-	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -411,7 +417,7 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 			}`))
 
 	g := gin.Default()
-	err := AddRoutesForConnection(&manager, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -430,6 +436,109 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 	)
 }
 
+func TestDiagnoseEndpointReturnsDiagnostics(t *testing.T) {
+	manager := mockConnectionManager{
+		onDiagnoseReturn: connectionstate.Diagnostics{
+			SessionID:                      "session-1",
+			Completed:                      true,
+			PingsSent:                      10,
+			PingsLost:                      1,
+			Latency:                        45500 * time.Microsecond,
+			Jitter:                         3 * time.Millisecond,
+			DNSOption:                      "auto",
+			DNSServers:                     []string{"10.182.0.1"},
+			DNSResolveTime:                 25 * time.Millisecond,
+			HandshakeAge:                   42 * time.Second,
+			ObservedBytesSentPerSecond:     128,
+			ObservedBytesReceivedPerSecond: 256,
+		},
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/connection/diagnostics", nil)
+
+	g := gin.Default()
+	err := AddRoutesForConnection(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"session_id": "session-1",
+			"completed": true,
+			"pings_sent": 10,
+			"pings_lost": 1,
+			"packet_loss": 0.1,
+			"latency": 45.5,
+			"jitter": 3,
+			"dns_option": "auto",
+			"dns_servers": ["10.182.0.1"],
+			"dns_resolve_time": 25,
+			"handshake_age": 42,
+			"observed_traffic_rate_sent": 1024,
+			"observed_traffic_rate_received": 2048
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestDiagnoseEndpointReturnsConflictWhenNotConnected(t *testing.T) {
+	manager := mockConnectionManager{onDiagnoseErr: connection.ErrNoConnection}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/connection/diagnostics", nil)
+
+	g := gin.Default()
+	err := AddRoutesForConnection(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestGetStatisticsEndpointReturnsSpendingBudget(t *testing.T) {
+	fakeState := &mockStateProvider{}
+	fakeState.stateToReturn.Connection.Session = connectionstate.Status{
+		SpendingLimits: connectionstate.SpendingLimits{MaxSessionSpend: big.NewInt(15000), MaxTraffic: 10},
+	}
+	fakeState.stateToReturn.Connection.Statistics = connectionstate.Statistics{BytesSent: 1, BytesReceived: 2}
+	fakeState.stateToReturn.Connection.Invoice = crypto.Invoice{AgreementTotal: big.NewInt(10001)}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/connection/statistics", nil)
+
+	g := gin.Default()
+	err := AddRoutesForConnection(&mockConnectionManager{}, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.JSONEq(
+		t,
+		`{
+			"bytes_sent": 1,
+			"bytes_received": 2,
+			"throughput_sent": 0,
+			"throughput_received": 0,
+			"duration": 0,
+			"tokens_spent": 10001,
+			"spending_limits": {
+				"max_session_spend": 15000,
+				"max_traffic_bytes": 10
+			},
+			"spending_budget": {
+				"session": 4999,
+				"traffic_bytes": 7
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
 func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	manager := mockConnectionManager{}
 	manager.onConnectReturn = connection.ErrAlreadyExists
@@ -448,7 +557,7 @@ func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := gin.Default()
-	err := AddRoutesForConnection(&manager, nil, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, nil, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -467,7 +576,7 @@ func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	manager := mockConnectionManager{}
 	manager.onDisconnectReturn = connection.ErrNoConnection
 
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)
 
 	req := httptest.NewRequest(
 		http.MethodDelete,
@@ -505,7 +614,7 @@ func TestConnectReturnsConnectCancelledStatusWhenErrConnectionCancelledIsEncount
 	resp := httptest.NewRecorder()
 
 	g := gin.Default()
-	err := AddRoutesForConnection(&manager, nil, mockProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, nil, mockProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -536,7 +645,7 @@ func TestConnectReturnsErrorIfNoProposals(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := gin.Default()
-	err := AddRoutesForConnection(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
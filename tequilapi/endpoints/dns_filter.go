@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/dns"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type dnsFilter interface {
+	Overrides() (allow, deny []string)
+	SetOverrides(allow, deny []string) error
+	Stats() dns.FilterStats
+}
+
+type dnsFilterConfig interface {
+	SetUser(key string, value interface{})
+	SaveUserConfig() error
+}
+
+type dnsFilterEndpoint struct {
+	filter dnsFilter
+	config dnsFilterConfig
+}
+
+// NewDNSFilterEndpoint creates and returns DNS filter endpoint
+func NewDNSFilterEndpoint(filter dnsFilter, config dnsFilterConfig) *dnsFilterEndpoint {
+	return &dnsFilterEndpoint{
+		filter: filter,
+		config: config,
+	}
+}
+
+// Overrides returns DNS filter overrides
+// swagger:operation GET /dns/filter/overrides DNS dnsFilterOverrides
+// ---
+// summary: Returns DNS filter overrides
+// description: Returns domains which are allowed or denied by the filtered DNS option regardless of the blocklists
+// responses:
+//   200:
+//     description: DNS filter overrides
+//     schema:
+//       "$ref": "#/definitions/DNSFilterOverridesDTO"
+func (dfe *dnsFilterEndpoint) Overrides(c *gin.Context) {
+	allow, deny := dfe.filter.Overrides()
+	utils.WriteAsJSON(contract.DNSFilterOverridesDTO{Allow: allow, Deny: deny}, c.Writer)
+}
+
+// SetOverrides replaces DNS filter overrides
+// swagger:operation PUT /dns/filter/overrides DNS dnsFilterSetOverrides
+// ---
+// summary: Replaces DNS filter overrides
+// description: Replaces domains which are allowed or denied by the filtered DNS option regardless of the blocklists, allowed domains take precedence
+// parameters:
+//   - in: body
+//     name: body
+//     description: Allowed and denied domains
+//     schema:
+//       $ref: "#/definitions/DNSFilterOverridesDTO"
+// responses:
+//   200:
+//     description: DNS filter overrides
+//     schema:
+//       "$ref": "#/definitions/DNSFilterOverridesDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (dfe *dnsFilterEndpoint) SetOverrides(c *gin.Context) {
+	var req contract.DNSFilterOverridesDTO
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := dfe.filter.SetOverrides(req.Allow, req.Deny); err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	allow, deny := dfe.filter.Overrides()
+	dfe.config.SetUser(config.FlagDNSFilterAllow.Name, allow)
+	dfe.config.SetUser(config.FlagDNSFilterDeny.Name, deny)
+	if err := dfe.config.SaveUserConfig(); err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.DNSFilterOverridesDTO{Allow: allow, Deny: deny}, c.Writer)
+}
+
+// Stats returns DNS filter hit counters
+// swagger:operation GET /dns/filter/stats DNS dnsFilterStats
+// ---
+// summary: Returns DNS filter hit counters
+// description: Returns blocklists of the filtered DNS option with their loading state and count of blocked queries
+// responses:
+//   200:
+//     description: DNS filter hit counters
+//     schema:
+//       "$ref": "#/definitions/DNSFilterStatsDTO"
+func (dfe *dnsFilterEndpoint) Stats(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewDNSFilterStatsDTO(dfe.filter.Stats()), c.Writer)
+}
+
+// AddRoutesForDNSFilter adds DNS filter routes to given router
+func AddRoutesForDNSFilter(filter dnsFilter) func(*gin.Engine) error {
+	dfe := NewDNSFilterEndpoint(filter, config.Current)
+	return func(e *gin.Engine) error {
+		g := e.Group("/dns/filter")
+		{
+			g.GET("/overrides", dfe.Overrides)
+			g.PUT("/overrides", dfe.SetOverrides)
+			g.GET("/stats", dfe.Stats)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/dns"
+)
+
+type mockDNSFilterConfig struct {
+	values map[string]interface{}
+	saved  bool
+}
+
+func (m *mockDNSFilterConfig) SetUser(key string, value interface{}) {
+	m.values[key] = value
+}
+
+func (m *mockDNSFilterConfig) SaveUserConfig() error {
+	m.saved = true
+	return nil
+}
+
+func Test_DNSFilter(t *testing.T) {
+	cfg := &mockDNSFilterConfig{values: map[string]interface{}{}}
+	filter := dns.NewFilter(nil, time.Hour)
+	dfe := NewDNSFilterEndpoint(filter, cfg)
+
+	g := gin.Default()
+	g.GET("/dns/filter/overrides", dfe.Overrides)
+	g.PUT("/dns/filter/overrides", dfe.SetOverrides)
+	g.GET("/dns/filter/stats", dfe.Stats)
+
+	tests := []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedJSON   string
+	}{
+		{
+			http.MethodPut,
+			"/dns/filter/overrides",
+			`{"allow": ["*.example.com"]}`,
+			http.StatusBadRequest,
+			`{"message": "invalid domain: *.example.com"}`,
+		},
+		{
+			http.MethodPut,
+			"/dns/filter/overrides",
+			`{"allow": ["Example.com."], "deny": ["ads.example.org"]}`,
+			http.StatusOK,
+			`{"allow": ["example.com"], "deny": ["ads.example.org"]}`,
+		},
+		{
+			http.MethodGet,
+			"/dns/filter/overrides",
+			"",
+			http.StatusOK,
+			`{"allow": ["example.com"], "deny": ["ads.example.org"]}`,
+		},
+	}
+
+	for _, test := range tests {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		g.ServeHTTP(resp, req)
+
+		assert.Equal(t, test.expectedStatus, resp.Code, test.method+" "+test.path)
+		assert.JSONEq(t, test.expectedJSON, resp.Body.String(), test.method+" "+test.path)
+	}
+
+	assert.True(t, cfg.saved)
+	assert.Equal(t, []string{"example.com"}, cfg.values[config.FlagDNSFilterAllow.Name])
+	assert.Equal(t, []string{"ads.example.org"}, cfg.values[config.FlagDNSFilterDeny.Name])
+
+	assert.True(t, filter.Blocked("cdn.ads.example.org."))
+
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/dns/filter/stats", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"lists": [], "deny_hits": 1}`, resp.Body.String())
+}
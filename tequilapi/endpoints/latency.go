@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/query"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type latencyProber interface {
+	Probe(consumerID identity.Identity, proposals []proposal.PricedServiceProposal, limit int) map[string]time.Duration
+	Latency(providerID string) (time.Duration, bool)
+}
+
+type latencyEndpoint struct {
+	proposalRepository proposalRepository
+	prober             latencyProber
+}
+
+// NewLatencyEndpoint creates and returns proposals latency probe endpoint
+func NewLatencyEndpoint(proposalRepository proposalRepository, prober latencyProber) *latencyEndpoint {
+	return &latencyEndpoint{
+		proposalRepository: proposalRepository,
+		prober:             prober,
+	}
+}
+
+// Probe measures latency to the best proposals
+// swagger:operation POST /proposals/probe Proposal probeProposals
+// ---
+// summary: Measures latency to the best proposals
+// description: Concurrently sends signed echo requests to providers of the best quality proposals over their p2p contacts.
+//   Measured round trip times are cached and used by "local_latency" proposal sorting and filtering.
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/ProposalsProbeRequestDTO"
+// responses:
+//   200:
+//     description: Latencies of providers which replied, fastest first
+//     schema:
+//       "$ref": "#/definitions/ProposalsProbeResponse"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (le *latencyEndpoint) Probe(c *gin.Context) {
+	var req contract.ProposalsProbeRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(c.Writer, errorMap)
+		return
+	}
+
+	proposals, err := le.proposalRepository.Proposals(&proposal.Filter{
+		PresetID:           req.PresetID,
+		ServiceType:        req.ServiceType,
+		LocationCountry:    req.CountryCode,
+		ExcludeUnsupported: true,
+	})
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	proposals = query.Sort(proposals, []query.SortKey{{Field: "quality", Descending: true}})
+	latencies := le.prober.Probe(identity.FromAddress(req.ConsumerID), proposals, req.Limit)
+
+	utils.WriteAsJSON(contract.NewProposalsProbeResponse(latencies), c.Writer)
+}
+
+// fastestFirst probes latency of the top n proposals and moves them to the front ordered by it.
+func fastestFirst(proposals []proposal.PricedServiceProposal, n int, consumerID identity.Identity, prober latencyProber) []proposal.PricedServiceProposal {
+	if n > len(proposals) {
+		n = len(proposals)
+	}
+	prober.Probe(consumerID, proposals[:n], n)
+
+	top := make([]proposal.PricedServiceProposal, n)
+	copy(top, proposals[:n])
+	for i := range top {
+		top[i].LocalLatency, _ = prober.Latency(top[i].ProviderID)
+	}
+
+	return append(proposal.SortByLocalLatency(top), proposals[n:]...)
+}
+
+// AddRoutesForLatency adds proposals latency probe routes to given router
+func AddRoutesForLatency(proposalRepository proposalRepository, prober latencyProber) func(*gin.Engine) error {
+	le := NewLatencyEndpoint(proposalRepository, prober)
+	return func(e *gin.Engine) error {
+		e.POST("/proposals/probe", le.Probe)
+		return nil
+	}
+}
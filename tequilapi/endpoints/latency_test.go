@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type mockLatencyProber struct {
+	latencies   map[string]time.Duration
+	probedLimit int
+	probed      []string
+}
+
+func (m *mockLatencyProber) Probe(_ identity.Identity, proposals []proposal.PricedServiceProposal, limit int) map[string]time.Duration {
+	m.probedLimit = limit
+	res := make(map[string]time.Duration)
+	for _, p := range proposals {
+		m.probed = append(m.probed, p.ProviderID)
+		if rtt, ok := m.latencies[p.ProviderID]; ok {
+			res[p.ProviderID] = rtt
+		}
+	}
+	return res
+}
+
+func (m *mockLatencyProber) Latency(providerID string) (time.Duration, bool) {
+	rtt, ok := m.latencies[providerID]
+	return rtt, ok
+}
+
+func latencyTestProposal(providerID string) proposal.PricedServiceProposal {
+	return proposal.PricedServiceProposal{ServiceProposal: market.ServiceProposal{ProviderID: providerID, ServiceType: "wireguard"}}
+}
+
+func Test_ProbeProposals(t *testing.T) {
+	repo := &mockProposalRepository{proposals: []proposal.PricedServiceProposal{
+		latencyTestProposal("0x1"),
+		latencyTestProposal("0x2"),
+	}}
+	prober := &mockLatencyProber{latencies: map[string]time.Duration{
+		"0x1": 80 * time.Millisecond,
+		"0x2": 20 * time.Millisecond,
+	}}
+	g := gin.Default()
+	err := AddRoutesForLatency(repo, prober)(g)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/proposals/probe", strings.NewReader(`{"consumer_id": "0x3", "service_type": "wireguard", "limit": 5}`))
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `[{"provider_id": "0x2", "latency": 20}, {"provider_id": "0x1", "latency": 80}]`, resp.Body.String())
+	assert.Equal(t, 5, prober.probedLimit)
+	assert.Equal(t, "wireguard", repo.recordedFilter.ServiceType)
+	assert.True(t, repo.recordedFilter.ExcludeUnsupported)
+
+	req = httptest.NewRequest(http.MethodPost, "/proposals/probe", strings.NewReader(`{"limit": 100}`))
+	resp = httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func Test_FastestFirst(t *testing.T) {
+	proposals := []proposal.PricedServiceProposal{
+		latencyTestProposal("0x1"),
+		latencyTestProposal("0x2"),
+		latencyTestProposal("0x3"),
+		latencyTestProposal("0x4"),
+	}
+	prober := &mockLatencyProber{latencies: map[string]time.Duration{
+		"0x1": 80 * time.Millisecond,
+		"0x3": 20 * time.Millisecond,
+		"0x4": 10 * time.Millisecond,
+	}}
+
+	res := fastestFirst(proposals, 3, identity.FromAddress("0x5"), prober)
+
+	var order []string
+	for _, p := range res {
+		order = append(order, p.ProviderID)
+	}
+	assert.Equal(t, []string{"0x3", "0x1", "0x2", "0x4"}, order)
+	assert.Equal(t, []string{"0x1", "0x2", "0x3"}, prober.probed)
+	assert.Equal(t, 20*time.Millisecond, res[0].LocalLatency)
+}
@@ -0,0 +1,358 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/query"
+	"github.com/mysteriumnetwork/node/core/discovery/stream"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// proposalEventsKeepAlive is the interval of comments sent to keep idle event stream open.
+const proposalEventsKeepAlive = 30 * time.Second
+
+type proposalStream interface {
+	Watch(filter *proposal.Filter) (<-chan stream.Event, func())
+}
+
+type proposalWebhookStorage interface {
+	List() ([]stream.Webhook, error)
+	Add(url, filter string) (stream.Webhook, error)
+	Delete(id string) error
+}
+
+type webhookClient interface {
+	DoRequest(req *http.Request) error
+}
+
+type proposalEventsEndpoint struct {
+	pricer    query.Pricer
+	natProber natProber
+	stream    proposalStream
+	webhooks  proposalWebhookStorage
+	client    webhookClient
+
+	mu         sync.Mutex
+	deliveries map[string]func()
+}
+
+// NewProposalEventsEndpoint creates and returns proposal change stream endpoint
+func NewProposalEventsEndpoint(pricer query.Pricer, natProber natProber, changes proposalStream, webhooks proposalWebhookStorage, client webhookClient) *proposalEventsEndpoint {
+	return &proposalEventsEndpoint{
+		pricer:     pricer,
+		natProber:  natProber,
+		stream:     changes,
+		webhooks:   webhooks,
+		client:     client,
+		deliveries: make(map[string]func()),
+	}
+}
+
+// swagger:operation GET /proposals/events Proposal proposalEvents
+// ---
+// summary: Streams proposal changes
+// description: Server sent events stream of added, updated and removed proposals matching the filter.
+//   Accepts the same filter parameters as GET /proposals except sort, NAT compatibility is not checked.
+//   Every event data is a ProposalEventDTO.
+// responses:
+//   200:
+//     description: Stream of proposal events
+//     schema:
+//       "$ref": "#/definitions/ProposalEventDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func (pee *proposalEventsEndpoint) Events(c *gin.Context) {
+	req := c.Request
+	resp := c.Writer
+
+	filter, _, errs := proposalFilter(req.Context(), req.URL.Query(), pee.pricer, pee.natProber)
+	if errs.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errs)
+		return
+	}
+
+	resp.Header().Set("Content-Type", "text/event-stream")
+	resp.Header().Set("Cache-Control", "no-cache,no-transform")
+	resp.Header().Set("Connection", "keep-alive")
+	resp.WriteHeader(http.StatusOK)
+	resp.Flush()
+
+	events, stop := pee.stream.Watch(filter)
+	defer stop()
+
+	keepAlive := time.NewTicker(proposalEventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(resp, ":\n\n"); err != nil {
+				return
+			}
+			resp.Flush()
+		case e, open := <-events:
+			if !open {
+				return
+			}
+
+			msg, err := json.Marshal(contract.NewProposalEventDTO(e))
+			if err != nil {
+				log.Error().Err(err).Msg("Could not marshal proposal event")
+				continue
+			}
+			if _, err := fmt.Fprintf(resp, "data: %s\n\n", msg); err != nil {
+				return
+			}
+			resp.Flush()
+		}
+	}
+}
+
+// swagger:operation GET /proposals/webhooks Proposal listProposalWebhooks
+// ---
+// summary: Returns proposal webhooks
+// description: Returns registered URLs proposal events are posted to
+// responses:
+//   200:
+//     description: List of proposal webhooks
+//     schema:
+//       "$ref": "#/definitions/ProposalWebhookListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pee *proposalEventsEndpoint) Webhooks(c *gin.Context) {
+	webhooks, err := pee.webhooks.List()
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	res := contract.ProposalWebhookListResponse{}
+	for _, w := range webhooks {
+		res = append(res, contract.NewProposalWebhookDTO(w))
+	}
+	utils.WriteAsJSON(res, c.Writer)
+}
+
+// swagger:operation POST /proposals/webhooks Proposal createProposalWebhook
+// ---
+// summary: Registers proposal webhook
+// description: Every added, updated and removed proposal matching the filter is posted to the URL as ProposalEventDTO.
+//   Filter accepts the same parameters as GET /proposals except sort, NAT compatibility is not checked.
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/ProposalWebhookRequestDTO"
+// responses:
+//   200:
+//     description: Registered proposal webhook
+//     schema:
+//       "$ref": "#/definitions/ProposalWebhookDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pee *proposalEventsEndpoint) CreateWebhook(c *gin.Context) {
+	var req contract.ProposalWebhookRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(c.Writer, errorMap)
+		return
+	}
+
+	filter, errs := pee.webhookFilter(req.Filter)
+	if errs.HasErrors() {
+		utils.SendValidationErrorMessage(c.Writer, errs)
+		return
+	}
+
+	webhook, err := pee.webhooks.Add(req.URL, req.Filter)
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+	pee.startDelivery(webhook, filter)
+
+	utils.WriteAsJSON(contract.NewProposalWebhookDTO(webhook), c.Writer)
+}
+
+// swagger:operation DELETE /proposals/webhooks/{id} Proposal deleteProposalWebhook
+// ---
+// summary: Removes proposal webhook
+// parameters:
+//   - in: path
+//     name: id
+//     description: webhook id
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: Webhook removed
+//   404:
+//     description: Webhook not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pee *proposalEventsEndpoint) DeleteWebhook(c *gin.Context) {
+	id := c.Param("id")
+	if err := pee.webhooks.Delete(id); err != nil {
+		if errors.Is(err, stream.ErrWebhookNotFound) {
+			utils.SendError(c.Writer, err, http.StatusNotFound)
+			return
+		}
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+	pee.stopDelivery(id)
+
+	c.Writer.WriteHeader(http.StatusAccepted)
+}
+
+func (pee *proposalEventsEndpoint) webhookFilter(query string) (*proposal.Filter, *validation.FieldErrorMap) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		errs := validation.NewErrorMap()
+		errs.ForField("filter").Invalid(err.Error())
+		return nil, errs
+	}
+
+	filter, _, errs := proposalFilter(context.Background(), values, pee.pricer, pee.natProber)
+	return filter, errs
+}
+
+// restoreDeliveries starts delivery of the webhooks registered before.
+func (pee *proposalEventsEndpoint) restoreDeliveries() error {
+	webhooks, err := pee.webhooks.List()
+	if err != nil {
+		return err
+	}
+
+	for _, w := range webhooks {
+		filter, errs := pee.webhookFilter(w.Filter)
+		if errs.HasErrors() {
+			log.Warn().Msgf("Skipping proposal webhook %s with invalid filter %q", w.ID, w.Filter)
+			continue
+		}
+		pee.startDelivery(w, filter)
+	}
+	return nil
+}
+
+func (pee *proposalEventsEndpoint) startDelivery(w stream.Webhook, filter *proposal.Filter) {
+	events, stop := pee.stream.Watch(filter)
+
+	pee.mu.Lock()
+	pee.deliveries[w.ID] = stop
+	pee.mu.Unlock()
+
+	go func() {
+		for e := range events {
+			if err := pee.deliver(w.URL, contract.NewProposalEventDTO(e)); err != nil {
+				log.Warn().Err(err).Msgf("Failed to deliver proposal event to webhook %s", w.ID)
+			}
+		}
+	}()
+}
+
+func (pee *proposalEventsEndpoint) stopDelivery(id string) {
+	pee.mu.Lock()
+	stop, ok := pee.deliveries[id]
+	delete(pee.deliveries, id)
+	pee.mu.Unlock()
+
+	if ok {
+		stop()
+	}
+}
+
+func (pee *proposalEventsEndpoint) deliver(webhookURL string, event contract.ProposalEventDTO) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return pee.client.DoRequest(req)
+}
+
+// AddRoutesForProposalEvents attaches proposal change stream and webhook endpoints to router
+func AddRoutesForProposalEvents(
+	pricer query.Pricer,
+	natProber natProber,
+	changes proposalStream,
+	webhooks proposalWebhookStorage,
+	client webhookClient,
+) func(*gin.Engine) error {
+	pee := NewProposalEventsEndpoint(pricer, natProber, changes, webhooks, client)
+	return func(e *gin.Engine) error {
+		if err := pee.restoreDeliveries(); err != nil {
+			return err
+		}
+
+		proposalGroup := e.Group("/proposals")
+		{
+			proposalGroup.GET("/events", pee.Events)
+			proposalGroup.GET("/webhooks", pee.Webhooks)
+			proposalGroup.POST("/webhooks", pee.CreateWebhook)
+			proposalGroup.DELETE("/webhooks/:id", pee.DeleteWebhook)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/stream"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockProposalStream struct {
+	mu      sync.Mutex
+	filters []*proposal.Filter
+	events  chan stream.Event
+}
+
+func (m *mockProposalStream) Watch(filter *proposal.Filter) (<-chan stream.Event, func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.filters = append(m.filters, filter)
+	return m.events, func() {}
+}
+
+type mockWebhookStorage struct {
+	webhooks []stream.Webhook
+}
+
+func (m *mockWebhookStorage) List() ([]stream.Webhook, error) {
+	return m.webhooks, nil
+}
+
+func (m *mockWebhookStorage) Add(url, filter string) (stream.Webhook, error) {
+	w := stream.Webhook{ID: "1", URL: url, Filter: filter}
+	m.webhooks = append(m.webhooks, w)
+	return w, nil
+}
+
+func (m *mockWebhookStorage) Delete(id string) error {
+	for i, w := range m.webhooks {
+		if w.ID == id {
+			m.webhooks = append(m.webhooks[:i], m.webhooks[i+1:]...)
+			return nil
+		}
+	}
+	return stream.ErrWebhookNotFound
+}
+
+type mockWebhookClient struct {
+	requests chan *http.Request
+}
+
+func (m *mockWebhookClient) DoRequest(req *http.Request) error {
+	m.requests <- req
+	return nil
+}
+
+func Test_ProposalEvents_Stream(t *testing.T) {
+	changes := &mockProposalStream{events: make(chan stream.Event, 1)}
+	g := gin.Default()
+	err := AddRoutesForProposalEvents(nil, mockedNATProber, changes, &mockWebhookStorage{}, &mockWebhookClient{})(g)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(g)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/proposals/events?location_country=JP&ip_type=residential", nil)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	changes.events <- stream.Event{
+		Type:     stream.EventAdded,
+		Proposal: proposal.PricedServiceProposal{ServiceProposal: market.ServiceProposal{ProviderID: "0x1"}},
+	}
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	assert.NoError(t, err)
+
+	var event contract.ProposalEventDTO
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+	assert.Equal(t, "added", event.Type)
+	assert.Equal(t, "0x1", event.Proposal.ProviderID)
+
+	changes.mu.Lock()
+	defer changes.mu.Unlock()
+	assert.Equal(t, "JP", changes.filters[0].LocationCountry)
+	assert.Equal(t, "residential", changes.filters[0].IPType)
+}
+
+func Test_ProposalEvents_Webhooks(t *testing.T) {
+	changes := &mockProposalStream{events: make(chan stream.Event, 1)}
+	webhooks := &mockWebhookStorage{}
+	client := &mockWebhookClient{requests: make(chan *http.Request, 1)}
+	g := gin.Default()
+	err := AddRoutesForProposalEvents(nil, mockedNATProber, changes, webhooks, client)(g)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/proposals/webhooks", strings.NewReader(`{"url": "ftp://example.com"}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/proposals/webhooks", strings.NewReader(`{"url": "https://example.com/hook", "filter": "query=country%3D"}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/proposals/webhooks", strings.NewReader(`{"url": "https://example.com/hook", "filter": "location_country=JP"}`)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, webhooks.webhooks, 1)
+	assert.Equal(t, "JP", changes.filters[0].LocationCountry)
+
+	changes.events <- stream.Event{
+		Type:     stream.EventRemoved,
+		Proposal: proposal.PricedServiceProposal{ServiceProposal: market.ServiceProposal{ProviderID: "0x1"}},
+	}
+	select {
+	case req := <-client.requests:
+		assert.Equal(t, "https://example.com/hook", req.URL.String())
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.JSONEq(t, `"removed"`, string(mustJSONField(t, body, "type")))
+	case <-time.After(time.Second):
+		t.Fatal("webhook was not called")
+	}
+
+	resp = httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/proposals/webhooks/1", nil))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	resp = httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/proposals/webhooks/1", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func mustJSONField(t *testing.T, body []byte, field string) json.RawMessage {
+	var fields map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(body, &fields))
+	return fields[field]
+}
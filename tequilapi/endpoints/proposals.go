@@ -18,19 +18,27 @@
 package endpoints
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/query"
+	"github.com/mysteriumnetwork/node/core/discovery/reducer"
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
 )
 
 // QualityFinder allows to fetch proposal quality data
@@ -42,16 +50,22 @@ type priceAPI interface {
 	GetCurrentPrice(nodeType string, country string) (market.Price, error)
 }
 
+type filterPresetStorage interface {
+	proposal.FilterPresetRepository
+	Save(preset *proposal.FilterPreset) error
+	Delete(id int) error
+}
+
 type proposalsEndpoint struct {
 	proposalRepository proposalRepository
 	pricer             priceAPI
 	locationResolver   location.Resolver
-	filterPresets      proposal.FilterPresetRepository
+	filterPresets      filterPresetStorage
 	natProber          natProber
 }
 
 // NewProposalsEndpoint creates and returns proposal creation endpoint
-func NewProposalsEndpoint(proposalRepository proposalRepository, pricer priceAPI, locationResolver location.Resolver, filterPresetRepository proposal.FilterPresetRepository, natProber natProber) *proposalsEndpoint {
+func NewProposalsEndpoint(proposalRepository proposalRepository, pricer priceAPI, locationResolver location.Resolver, filterPresetRepository filterPresetStorage, natProber natProber) *proposalsEndpoint {
 	return &proposalsEndpoint{
 		proposalRepository: proposalRepository,
 		pricer:             pricer,
@@ -64,7 +78,7 @@ func NewProposalsEndpoint(proposalRepository proposalRepository, pricer priceAPI
 // swagger:operation GET /proposals Proposal listProposals
 // ---
 // summary: Returns proposals
-// description: Returns list of proposals filtered by provider id
+// description: Returns list of proposals filtered by provider id. Last seen proposals are returned from local cache and marked stale when discovery is unreachable.
 // parameters:
 //   - in: query
 //     name: provider_id
@@ -72,7 +86,7 @@ func NewProposalsEndpoint(proposalRepository proposalRepository, pricer priceAPI
 //     type: string
 //   - in: query
 //     name: service_type
-//     description: the service type of the proposal. Possible values are "openvpn", "wireguard" and "noop"
+//     description: the service type of the proposal. Possible values are "openvpn", "wireguard", "noop" and "proxy"
 //     type: string
 //   - in: query
 //     name: access_policy
@@ -106,11 +120,31 @@ func NewProposalsEndpoint(proposalRepository proposalRepository, pricer priceAPI
 //     name: nat_compatibility
 //     description: Pick nodes compatible with NAT of specified type. Specify "auto" to probe NAT.
 //     type: string
+//   - in: query
+//     name: ipv6
+//     description: If true, only proposals providing IPv6 egress are returned.
+//     type: boolean
+//   - in: query
+//     name: query
+//     description: Filter expression, e.g. "country in (DE,NL) and quality >= 2 and price.gib < 0.1 and not provider in (0x1)". Fields are provider, service_type, country, city, continent, ip_type, isp, asn, compatibility, quality, latency, bandwidth, ipv6, price.hour and price.gib (in MYST).
+//     type: string
+//   - in: query
+//     name: sort
+//     description: Comma separated list of query fields to sort proposals by, prefix field with "-" for descending order, e.g. "-quality,price.gib". Besides query fields proposals can be sorted by personal provider reputation "score" and "favourite" and by "local_latency" measured with POST /proposals/probe.
+//     type: string
+//   - in: query
+//     name: local_latency_max
+//     description: Maximum round trip time in milliseconds measured from this node with POST /proposals/probe, unmeasured providers are excluded.
+//     type: integer
 // responses:
 //   200:
 //     description: List of proposals
 //     schema:
 //       "$ref": "#/definitions/ListProposalsResponse"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
@@ -119,48 +153,22 @@ func (pe *proposalsEndpoint) List(c *gin.Context) {
 	req := c.Request
 	resp := c.Writer
 
-	presetID, _ := strconv.Atoi(req.URL.Query().Get("preset_id"))
-	compatibilityMin, _ := strconv.Atoi(req.URL.Query().Get("compatibility_min"))
-	compatibilityMax, _ := strconv.Atoi(req.URL.Query().Get("compatibility_max"))
-	qualityMin := func() float32 {
-		f, err := strconv.ParseFloat(req.URL.Query().Get("quality_min"), 32)
-		if err != nil {
-			return 0
-		}
-		return float32(f)
-	}()
-
-	natCompatibility := nat.NATType(req.URL.Query().Get("nat_compatibility"))
-	if natCompatibility == contract.AutoNATType {
-		natType, err := pe.natProber.Probe(req.Context())
-		if err != nil {
-			natCompatibility = ""
-		} else {
-			natCompatibility = natType
-		}
+	filter, sortKeys, errs := proposalFilter(req.Context(), req.URL.Query(), pe.proposalRepository, pe.natProber)
+	if errs.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errs)
+		return
 	}
 
-	includeMonitoringFailed, _ := strconv.ParseBool(req.URL.Query().Get("include_monitoring_failed"))
-	proposals, err := pe.proposalRepository.Proposals(&proposal.Filter{
-		PresetID:                presetID,
-		ProviderID:              req.URL.Query().Get("provider_id"),
-		ServiceType:             req.URL.Query().Get("service_type"),
-		AccessPolicy:            req.URL.Query().Get("access_policy"),
-		AccessPolicySource:      req.URL.Query().Get("access_policy_source"),
-		LocationCountry:         req.URL.Query().Get("location_country"),
-		IPType:                  req.URL.Query().Get("ip_type"),
-		NATCompatibility:        natCompatibility,
-		CompatibilityMin:        compatibilityMin,
-		CompatibilityMax:        compatibilityMax,
-		QualityMin:              qualityMin,
-		ExcludeUnsupported:      true,
-		IncludeMonitoringFailed: includeMonitoringFailed,
-	})
+	proposals, err := pe.proposalRepository.Proposals(filter)
 	if err != nil {
 		utils.SendError(resp, err, http.StatusInternalServerError)
 		return
 	}
 
+	if len(sortKeys) > 0 {
+		proposals = query.Sort(proposals, sortKeys)
+	}
+
 	proposalsRes := contract.ListProposalsResponse{Proposals: []contract.ProposalDTO{}}
 	for _, p := range proposals {
 		proposalsRes.Proposals = append(proposalsRes.Proposals, contract.NewProposalDTO(p))
@@ -180,7 +188,7 @@ func (pe *proposalsEndpoint) List(c *gin.Context) {
 //     type: string
 //   - in: query
 //     name: service_type
-//     description: the service type of the proposal. Possible values are "openvpn", "wireguard" and "noop"
+//     description: the service type of the proposal. Possible values are "openvpn", "wireguard", "noop" and "proxy"
 //     type: string
 //   - in: query
 //     name: access_policy
@@ -214,11 +222,23 @@ func (pe *proposalsEndpoint) List(c *gin.Context) {
 //     name: nat_compatibility
 //     description: Pick nodes compatible with NAT of specified type. Specify "auto" to probe NAT.
 //     type: string
+//   - in: query
+//     name: ipv6
+//     description: If true, only proposals providing IPv6 egress are returned.
+//     type: boolean
+//   - in: query
+//     name: query
+//     description: Filter expression, e.g. "country in (DE,NL) and quality >= 2 and price.gib < 0.1 and not provider in (0x1)". Fields are provider, service_type, country, city, continent, ip_type, isp, asn, compatibility, quality, latency, bandwidth, ipv6, price.hour and price.gib (in MYST).
+//     type: string
 // responses:
 //   200:
 //     description: List of countries
 //     schema:
 //       "$ref": "#/definitions/ListProposalsCountiesResponse"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
@@ -227,20 +247,57 @@ func (pe *proposalsEndpoint) Countries(c *gin.Context) {
 	req := c.Request
 	resp := c.Writer
 
-	presetID, _ := strconv.Atoi(req.URL.Query().Get("preset_id"))
-	compatibilityMin, _ := strconv.Atoi(req.URL.Query().Get("compatibility_min"))
-	compatibilityMax, _ := strconv.Atoi(req.URL.Query().Get("compatibility_max"))
+	filter, _, errs := proposalFilter(req.Context(), req.URL.Query(), pe.proposalRepository, pe.natProber)
+	if errs.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errs)
+		return
+	}
+
+	countries, err := pe.proposalRepository.Countries(filter)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(countries, resp)
+}
+
+// proposalFilter builds proposal filter and sort keys from the proposal listing query parameters.
+func proposalFilter(ctx context.Context, values url.Values, pricer query.Pricer, natProber natProber) (*proposal.Filter, []query.SortKey, *validation.FieldErrorMap) {
+	errs := validation.NewErrorMap()
+
+	var condition reducer.AndCondition
+	if expression := values.Get("query"); expression != "" {
+		match, err := query.Parse(expression, pricer)
+		if err != nil {
+			errs.ForField("query").Invalid(err.Error())
+		}
+		condition = match
+	}
+
+	sortKeys, err := query.ParseSort(values.Get("sort"))
+	if err != nil {
+		errs.ForField("sort").Invalid(err.Error())
+	}
+
+	if errs.HasErrors() {
+		return nil, nil, errs
+	}
+
+	presetID, _ := strconv.Atoi(values.Get("preset_id"))
+	compatibilityMin, _ := strconv.Atoi(values.Get("compatibility_min"))
+	compatibilityMax, _ := strconv.Atoi(values.Get("compatibility_max"))
 	qualityMin := func() float32 {
-		f, err := strconv.ParseFloat(req.URL.Query().Get("quality_min"), 32)
+		f, err := strconv.ParseFloat(values.Get("quality_min"), 32)
 		if err != nil {
 			return 0
 		}
 		return float32(f)
 	}()
 
-	natCompatibility := nat.NATType(req.URL.Query().Get("nat_compatibility"))
+	natCompatibility := nat.NATType(values.Get("nat_compatibility"))
 	if natCompatibility == contract.AutoNATType {
-		natType, err := pe.natProber.Probe(req.Context())
+		natType, err := natProber.Probe(ctx)
 		if err != nil {
 			natCompatibility = ""
 		} else {
@@ -248,28 +305,28 @@ func (pe *proposalsEndpoint) Countries(c *gin.Context) {
 		}
 	}
 
-	includeMonitoringFailed, _ := strconv.ParseBool(req.URL.Query().Get("include_monitoring_failed"))
-	countries, err := pe.proposalRepository.Countries(&proposal.Filter{
+	includeMonitoringFailed, _ := strconv.ParseBool(values.Get("include_monitoring_failed"))
+	ipv6, _ := strconv.ParseBool(values.Get("ipv6"))
+	localLatencyMax, _ := strconv.Atoi(values.Get("local_latency_max"))
+
+	return &proposal.Filter{
 		PresetID:                presetID,
-		ProviderID:              req.URL.Query().Get("provider_id"),
-		ServiceType:             req.URL.Query().Get("service_type"),
-		AccessPolicy:            req.URL.Query().Get("access_policy"),
-		AccessPolicySource:      req.URL.Query().Get("access_policy_source"),
-		LocationCountry:         req.URL.Query().Get("location_country"),
-		IPType:                  req.URL.Query().Get("ip_type"),
+		ProviderID:              values.Get("provider_id"),
+		ServiceType:             values.Get("service_type"),
+		AccessPolicy:            values.Get("access_policy"),
+		AccessPolicySource:      values.Get("access_policy_source"),
+		LocationCountry:         values.Get("location_country"),
+		IPType:                  values.Get("ip_type"),
 		NATCompatibility:        natCompatibility,
 		CompatibilityMin:        compatibilityMin,
 		CompatibilityMax:        compatibilityMax,
 		QualityMin:              qualityMin,
 		ExcludeUnsupported:      true,
 		IncludeMonitoringFailed: includeMonitoringFailed,
-	})
-	if err != nil {
-		utils.SendError(resp, err, http.StatusInternalServerError)
-		return
-	}
-
-	utils.WriteAsJSON(countries, resp)
+		IPv6:                    ipv6,
+		Query:                   condition,
+		LocalLatencyMax:         time.Duration(localLatencyMax) * time.Millisecond,
+	}, sortKeys, errs
 }
 
 // swagger:operation GET /prices/current
@@ -309,7 +366,7 @@ func (pe *proposalsEndpoint) CurrentPrice(c *gin.Context) {
 // swagger:operation GET /proposals/filter-presets Proposal proposalFilterPresets
 // ---
 // summary: Returns proposal filter presets
-// description: Returns proposal filter presets
+// description: Returns system and user created proposal filter presets
 // responses:
 //   200:
 //     description: List of proposal filter presets
@@ -334,12 +391,218 @@ func (pe *proposalsEndpoint) FilterPresets(c *gin.Context) {
 	utils.WriteAsJSON(presetsRes, resp)
 }
 
+// swagger:operation GET /proposals/filter-presets/{id} Proposal proposalFilterPreset
+// ---
+// summary: Returns proposal filter preset
+// description: Returns proposal filter preset by the requested id
+// parameters:
+//   - name: id
+//     in: path
+//     description: Preset id
+//     type: integer
+//     required: true
+// responses:
+//   200:
+//     description: Proposal filter preset
+//     schema:
+//       "$ref": "#/definitions/FilterPreset"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Preset not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pe *proposalsEndpoint) FilterPreset(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	preset, err := pe.filterPresets.Get(id)
+	if errors.Is(err, proposal.ErrFilterPresetNotFound) {
+		utils.SendError(c.Writer, err, http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewFilterPreset(*preset), c.Writer)
+}
+
+// swagger:operation POST /proposals/filter-presets Proposal proposalFilterPresetCreate
+// ---
+// summary: Creates proposal filter preset
+// description: Creates user proposal filter preset
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/FilterPresetRequestDTO"
+// responses:
+//   201:
+//     description: Proposal filter preset created
+//     schema:
+//       "$ref": "#/definitions/FilterPreset"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pe *proposalsEndpoint) CreateFilterPreset(c *gin.Context) {
+	pe.saveFilterPreset(c, 0, http.StatusCreated)
+}
+
+// swagger:operation PUT /proposals/filter-presets/{id} Proposal proposalFilterPresetUpdate
+// ---
+// summary: Updates proposal filter preset
+// description: Replaces user proposal filter preset by the requested id, system presets can't be updated
+// parameters:
+//   - name: id
+//     in: path
+//     description: Preset id
+//     type: integer
+//     required: true
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/FilterPresetRequestDTO"
+// responses:
+//   200:
+//     description: Proposal filter preset updated
+//     schema:
+//       "$ref": "#/definitions/FilterPreset"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Preset not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pe *proposalsEndpoint) UpdateFilterPreset(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := pe.filterPresets.Get(id); errors.Is(err, proposal.ErrFilterPresetNotFound) {
+		utils.SendError(c.Writer, err, http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+	if proposal.IsSystemFilterPreset(id) {
+		utils.SendError(c.Writer, errors.New("system presets can't be updated"), http.StatusBadRequest)
+		return
+	}
+
+	pe.saveFilterPreset(c, id, http.StatusOK)
+}
+
+func (pe *proposalsEndpoint) saveFilterPreset(c *gin.Context, id int, status int) {
+	var req contract.FilterPresetRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(c.Writer, errorMap)
+		return
+	}
+
+	preset := req.ToFilterPreset(id)
+	if err := pe.filterPresets.Save(&preset); err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewFilterPreset(preset), c.Writer, status)
+}
+
+// swagger:operation DELETE /proposals/filter-presets/{id} Proposal proposalFilterPresetDelete
+// ---
+// summary: Removes proposal filter preset
+// description: Removes user proposal filter preset by the requested id, system presets can't be removed
+// parameters:
+//   - name: id
+//     in: path
+//     description: Preset id
+//     type: integer
+//     required: true
+// responses:
+//   202:
+//     description: Proposal filter preset removed
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Preset not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pe *proposalsEndpoint) DeleteFilterPreset(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := pe.filterPresets.Get(id); errors.Is(err, proposal.ErrFilterPresetNotFound) {
+		utils.SendError(c.Writer, err, http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+	if proposal.IsSystemFilterPreset(id) {
+		utils.SendError(c.Writer, errors.New("system presets can't be removed"), http.StatusBadRequest)
+		return
+	}
+
+	if err := pe.filterPresets.Delete(id); err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Writer.WriteHeader(http.StatusAccepted)
+}
+
 // AddRoutesForProposals attaches proposals endpoints to router
 func AddRoutesForProposals(
 	proposalRepository proposalRepository,
 	pricer priceAPI,
 	locationResolver location.Resolver,
-	filterPresetRepository proposal.FilterPresetRepository,
+	filterPresetRepository filterPresetStorage,
 	natProber natProber,
 ) func(*gin.Engine) error {
 	pe := NewProposalsEndpoint(proposalRepository, pricer, locationResolver, filterPresetRepository, natProber)
@@ -348,6 +611,10 @@ func AddRoutesForProposals(
 		{
 			proposalGroup.GET("", pe.List)
 			proposalGroup.GET("/filter-presets", pe.FilterPresets)
+			proposalGroup.POST("/filter-presets", pe.CreateFilterPreset)
+			proposalGroup.GET("/filter-presets/:id", pe.FilterPreset)
+			proposalGroup.PUT("/filter-presets/:id", pe.UpdateFilterPreset)
+			proposalGroup.DELETE("/filter-presets/:id", pe.DeleteFilterPreset)
 			proposalGroup.GET("/countries", pe.Countries)
 		}
 
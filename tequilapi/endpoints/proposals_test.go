@@ -18,12 +18,13 @@
 package endpoints
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -34,6 +35,7 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 )
 
 var TestLocation = market.Location{ASN: 123, Country: "Lithuania", City: "Vilnius"}
@@ -281,6 +283,165 @@ func TestProposalsEndpointFilterByPresetID(t *testing.T) {
 	)
 }
 
+func TestProposalsEndpointListByQuery(t *testing.T) {
+	repository := &mockProposalRepository{
+		proposals: serviceProposals,
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/proposals", nil)
+	assert.Nil(t, err)
+
+	query := req.URL.Query()
+	query.Set("query", "provider in (other_provider) and quality >= 2")
+	query.Set("sort", "-provider")
+	req.URL.RawQuery = query.Encode()
+
+	resp := httptest.NewRecorder()
+	endpoint := NewProposalsEndpoint(repository, nil, nil, &mockFilterPresetRepository{}, mockedNATProber)
+	g := gin.Default()
+	g.GET("/proposals", endpoint.List)
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotNil(t, repository.recordedFilter.Query)
+	assert.False(t, repository.recordedFilter.Query(serviceProposals[0].ServiceProposal))
+	assert.True(t, repository.recordedFilter.Query(serviceProposals[1].ServiceProposal))
+
+	parsedResponse := &contract.ListProposalsResponse{}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), parsedResponse))
+	assert.Len(t, parsedResponse.Proposals, 2)
+	assert.Equal(t, "other_provider", parsedResponse.Proposals[0].ProviderID)
+	assert.Equal(t, "0xProviderId", parsedResponse.Proposals[1].ProviderID)
+}
+
+func TestProposalsEndpointListsProposalWithoutPrice(t *testing.T) {
+	repository := &mockProposalRepository{
+		proposals: []proposal.PricedServiceProposal{
+			{ServiceProposal: market.ServiceProposal{ProviderID: "0x1", ServiceType: "wireguard"}},
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/proposals", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	endpoint := NewProposalsEndpoint(repository, nil, nil, &mockFilterPresetRepository{}, mockedNATProber)
+	g := gin.Default()
+	g.GET("/proposals", endpoint.List)
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	parsedResponse := &contract.ListProposalsResponse{}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), parsedResponse))
+	assert.Len(t, parsedResponse.Proposals, 1)
+	assert.Equal(t, uint64(0), parsedResponse.Proposals[0].Price.PerHour)
+	assert.Equal(t, uint64(0), parsedResponse.Proposals[0].Price.PerGiB)
+}
+
+func TestProposalsEndpointListRejectsInvalidQuery(t *testing.T) {
+	repository := &mockProposalRepository{
+		proposals: serviceProposals,
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/proposals", nil)
+	assert.Nil(t, err)
+
+	query := req.URL.Query()
+	query.Set("query", "country = DE and quality >= high")
+	query.Set("sort", "speed")
+	req.URL.RawQuery = query.Encode()
+
+	resp := httptest.NewRecorder()
+	endpoint := NewProposalsEndpoint(repository, nil, nil, &mockFilterPresetRepository{}, mockedNATProber)
+	g := gin.Default()
+	g.GET("/proposals", endpoint.List)
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"query": [{"code": "invalid", "message": "expected number at position 29 near \"high\""}],
+				"sort": [{"code": "invalid", "message": "unknown sort field, expected one of: asn, bandwidth, city, compatibility, continent, country, favourite, ip_type, ipv6, isp, latency, load, local_latency, price.gib, price.hour, provider, quality, score, service_type, sessions at position 1 near \"speed\""}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+	assert.Nil(t, repository.recordedFilter)
+}
+
+func TestProposalsEndpointFilterPresetsCRUD(t *testing.T) {
+	presetRepository := &mockFilterPresetRepository{
+		presets: proposal.FilterPresets{Entries: []proposal.FilterPreset{
+			{ID: 1, Name: "Media Streaming", IPType: proposal.Residential},
+		}},
+	}
+	g := gin.Default()
+	err := AddRoutesForProposals(&mockProposalRepository{}, nil, nil, presetRepository, mockedNATProber)(g)
+	assert.NoError(t, err)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		g.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := serve(http.MethodPost, "/proposals/filter-presets", `{
+		"name": "Cheap DE",
+		"country": "de",
+		"price_gib_max": 100000000000000000,
+		"quality_min": 1.5,
+		"nat_compatibility": "prcone",
+		"denied_providers": ["0x0000000000000000000000000000000000000001"]
+	}`)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.JSONEq(t, `{
+		"id": 101,
+		"name": "Cheap DE",
+		"country": "DE",
+		"price_gib_max": 100000000000000000,
+		"quality_min": 1.5,
+		"nat_compatibility": "prcone",
+		"denied_providers": ["0x0000000000000000000000000000000000000001"]
+	}`, resp.Body.String())
+
+	resp = serve(http.MethodPut, "/proposals/filter-presets/101", `{"name": "Cheap NL", "country": "NL"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"id": 101, "name": "Cheap NL", "country": "NL"}`, resp.Body.String())
+
+	resp = serve(http.MethodGet, "/proposals/filter-presets/101", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"id": 101, "name": "Cheap NL", "country": "NL"}`, resp.Body.String())
+
+	resp = serve(http.MethodPut, "/proposals/filter-presets/1", `{"name": "Media"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = serve(http.MethodPost, "/proposals/filter-presets", `{"country": "DEU", "nat_compatibility": "auto", "allowed_providers": ["boom"]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "validation_error",
+		"errors": {
+			"name": [{"code": "required", "message": "Field is required"}],
+			"country": [{"code": "invalid", "message": "Country code must be in ISO 3166-1 alpha-2 format"}],
+			"nat_compatibility": [{"code": "invalid", "message": "Must be one of: [none fullcone rcone prcone symmetric]"}],
+			"allowed_providers": [{"code": "invalid", "message": "Invalid provider identity: boom"}]
+		}
+	}`, resp.Body.String())
+
+	resp = serve(http.MethodDelete, "/proposals/filter-presets/101", "")
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	resp = serve(http.MethodDelete, "/proposals/filter-presets/101", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = serve(http.MethodDelete, "/proposals/filter-presets/1", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
 type mockProposalRepository struct {
 	proposals      []proposal.PricedServiceProposal
 	recordedFilter *proposal.Filter
@@ -311,6 +472,10 @@ func (m *mockProposalRepository) EnrichProposalWithPrice(in market.ServicePropos
 	}, nil
 }
 
+func (m *mockProposalRepository) ProposalPrice(_ market.ServiceProposal) (market.Price, error) {
+	return m.priceToAdd, nil
+}
+
 type mockFilterPresetRepository struct {
 	presets proposal.FilterPresets
 }
@@ -325,7 +490,31 @@ func (m *mockFilterPresetRepository) Get(id int) (*proposal.FilterPreset, error)
 			return &p, nil
 		}
 	}
-	return nil, errors.New("preset not found")
+	return nil, fmt.Errorf("%w: %d", proposal.ErrFilterPresetNotFound, id)
+}
+
+func (m *mockFilterPresetRepository) Save(preset *proposal.FilterPreset) error {
+	if preset.ID == 0 {
+		preset.ID = 100 + len(m.presets.Entries)
+		m.presets.Entries = append(m.presets.Entries, *preset)
+		return nil
+	}
+	for i, p := range m.presets.Entries {
+		if p.ID == preset.ID {
+			m.presets.Entries[i] = *preset
+		}
+	}
+	return nil
+}
+
+func (m *mockFilterPresetRepository) Delete(id int) error {
+	for i, p := range m.presets.Entries {
+		if p.ID == id {
+			m.presets.Entries = append(m.presets.Entries[:i], m.presets.Entries[i+1:]...)
+			return nil
+		}
+	}
+	return proposal.ErrFilterPresetNotFound
 }
 
 func setPricingBounds(v url.Values) {
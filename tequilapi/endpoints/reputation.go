@@ -0,0 +1,196 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/consumer/reputation"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+type providerReputationStorage interface {
+	List() ([]reputation.Record, error)
+	Get(providerID string) (reputation.Record, error)
+	SetFavourite(providerID string, favourite bool) (reputation.Record, error)
+	SetBlocked(providerID string, blocked bool) (reputation.Record, error)
+}
+
+type providerReputationEndpoint struct {
+	storage providerReputationStorage
+}
+
+// NewProviderReputationEndpoint creates and returns provider reputation endpoint
+func NewProviderReputationEndpoint(storage providerReputationStorage) *providerReputationEndpoint {
+	return &providerReputationEndpoint{
+		storage: storage,
+	}
+}
+
+// List returns personal reputation of providers
+// swagger:operation GET /providers Provider providerReputationList
+// ---
+// summary: Returns personal reputation of providers
+// description: Returns providers consumer has connected to or marked as favourite or blocked, favourites and best scored first
+// responses:
+//   200:
+//     description: List of provider reputations
+//     schema:
+//       "$ref": "#/definitions/ProviderReputationListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pre *providerReputationEndpoint) List(c *gin.Context) {
+	records, err := pre.storage.List()
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderReputationListResponse(records), c.Writer)
+}
+
+// Get returns personal reputation of the provider
+// swagger:operation GET /providers/{id} Provider providerReputationGet
+// ---
+// summary: Returns personal reputation of the provider
+// description: Returns personal reputation of the provider, providers without history get the default score
+// parameters:
+//   - name: id
+//     in: path
+//     description: Provider identity
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Provider reputation
+//     schema:
+//       "$ref": "#/definitions/ProviderReputationDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pre *providerReputationEndpoint) Get(c *gin.Context) {
+	providerID, ok := providerIDParam(c)
+	if !ok {
+		return
+	}
+
+	record, err := pre.storage.Get(providerID)
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderReputationDTO(record), c.Writer)
+}
+
+// Update marks provider as favourite or blocked
+// swagger:operation PUT /providers/{id} Provider providerReputationUpdate
+// ---
+// summary: Marks provider as favourite or blocked
+// description: Adds or removes the provider to favourites or blocklist, blocked providers are excluded from proposals
+// parameters:
+//   - name: id
+//     in: path
+//     description: Provider identity
+//     type: string
+//     required: true
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/ProviderReputationRequestDTO"
+// responses:
+//   200:
+//     description: Provider reputation updated
+//     schema:
+//       "$ref": "#/definitions/ProviderReputationDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pre *providerReputationEndpoint) Update(c *gin.Context) {
+	providerID, ok := providerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req contract.ProviderReputationRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	record, err := pre.storage.Get(providerID)
+	if req.Favourite != nil && err == nil {
+		record, err = pre.storage.SetFavourite(providerID, *req.Favourite)
+	}
+	if req.Blocked != nil && err == nil {
+		record, err = pre.storage.SetBlocked(providerID, *req.Blocked)
+	}
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderReputationDTO(record), c.Writer)
+}
+
+func providerIDParam(c *gin.Context) (string, bool) {
+	providerID := c.Param("id")
+	if !common.IsHexAddress(providerID) {
+		errs := validation.NewErrorMap()
+		errs.ForField("id").Invalid("Must be a provider identity")
+		utils.SendValidationErrorMessage(c.Writer, errs)
+		return "", false
+	}
+	return providerID, true
+}
+
+// AddRoutesForProviderReputation adds provider reputation routes to given router
+func AddRoutesForProviderReputation(storage providerReputationStorage) func(*gin.Engine) error {
+	pre := NewProviderReputationEndpoint(storage)
+	return func(e *gin.Engine) error {
+		g := e.Group("/providers")
+		{
+			g.GET("", pre.List)
+			g.GET("/:id", pre.Get)
+			g.PUT("/:id", pre.Update)
+		}
+		return nil
+	}
+}
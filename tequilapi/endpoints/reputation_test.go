@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/consumer/reputation"
+)
+
+type mockReputationBackend struct {
+	records []reputation.Record
+}
+
+func (m *mockReputationBackend) Store(_ string, data interface{}) error {
+	record := *data.(*reputation.Record)
+	for i := range m.records {
+		if m.records[i].ProviderID == record.ProviderID {
+			m.records[i] = record
+			return nil
+		}
+	}
+	m.records = append(m.records, record)
+	return nil
+}
+
+func (m *mockReputationBackend) GetAllFrom(_ string, data interface{}) error {
+	*data.(*[]reputation.Record) = append([]reputation.Record(nil), m.records...)
+	return nil
+}
+
+func Test_ProviderReputation(t *testing.T) {
+	storage := reputation.NewStorage(&mockReputationBackend{})
+	g := gin.Default()
+	err := AddRoutesForProviderReputation(storage)(g)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedJSON   string
+	}{
+		{
+			http.MethodGet,
+			"/providers",
+			"",
+			http.StatusOK,
+			`[]`,
+		},
+		{
+			http.MethodGet,
+			"/providers/0x0000000000000000000000000000000000000001",
+			"",
+			http.StatusOK,
+			`{
+				"provider_id": "0x0000000000000000000000000000000000000001",
+				"score": 0.5,
+				"connect_successes": 0,
+				"connect_failures": 0
+			}`,
+		},
+		{
+			http.MethodPut,
+			"/providers/not-an-identity",
+			`{"blocked": true}`,
+			http.StatusUnprocessableEntity,
+			`{
+				"message": "validation_error",
+				"errors": {
+					"id": [ {"code": "invalid", "message": "Must be a provider identity"} ]
+				}
+			}`,
+		},
+		{
+			http.MethodPut,
+			"/providers/0x0000000000000000000000000000000000000001",
+			`{"favourite": true}`,
+			http.StatusOK,
+			"",
+		},
+		{
+			http.MethodPut,
+			"/providers/0x0000000000000000000000000000000000000002",
+			`{"blocked": true}`,
+			http.StatusOK,
+			"",
+		},
+		{
+			http.MethodPut,
+			"/providers/0x0000000000000000000000000000000000000002",
+			`{"favourite": false}`,
+			http.StatusOK,
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		g.ServeHTTP(resp, req)
+
+		assert.Equal(t, test.expectedStatus, resp.Code, test.method+" "+test.path)
+		if test.expectedJSON != "" {
+			assert.JSONEq(t, test.expectedJSON, resp.Body.String(), test.method+" "+test.path)
+		}
+	}
+
+	records, err := storage.List()
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.True(t, storage.Reputation("0x0000000000000000000000000000000000000001").Favourite)
+	assert.True(t, storage.Reputation("0x0000000000000000000000000000000000000002").Blocked)
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/consumer/entertainment"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/selector"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type selectorEndpoint struct {
+	proposalRepository proposalRepository
+	balanceProvider    balanceProvider
+}
+
+// NewSelectorEndpoint creates and returns provider selector endpoint
+func NewSelectorEndpoint(proposalRepository proposalRepository, balanceProvider balanceProvider) *selectorEndpoint {
+	return &selectorEndpoint{
+		proposalRepository: proposalRepository,
+		balanceProvider:    balanceProvider,
+	}
+}
+
+// Select picks the best provider within the price caps
+// swagger:operation POST /proposals/select Proposal selectProposal
+// ---
+// summary: Picks the best provider within the price caps
+// description: Scores proposals by weighted quality, bandwidth, latency and price, the same way PUT /connection does when filter selector is given.
+//   When consumer_id is given, estimates how far its balance goes at the selected price.
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/ProposalSelectRequestDTO"
+// responses:
+//   200:
+//     description: Selected proposal
+//     schema:
+//       "$ref": "#/definitions/ProposalSelectResponse"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: No providers within the price caps
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (se *selectorEndpoint) Select(c *gin.Context) {
+	var req contract.ProposalSelectRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(c.Writer, errorMap)
+		return
+	}
+
+	proposals, err := se.proposalRepository.Proposals(&proposal.Filter{
+		PresetID:           req.PresetID,
+		ServiceType:        req.ServiceType,
+		LocationCountry:    req.CountryCode,
+		ExcludeUnsupported: true,
+	})
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	best, err := req.Selector.ToSpec().Select(proposals)
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusNotFound)
+		return
+	}
+
+	res := contract.ProposalSelectResponse{
+		Proposal: contract.NewProposalDTO(best.Proposal),
+		Score:    best.Score,
+	}
+	if req.ConsumerID != "" {
+		balance := se.balanceProvider.GetBalance(config.GetInt64(config.FlagChainID), identity.FromAddress(req.ConsumerID))
+		estimates := entertainment.NewPriceEstimator(best.Proposal.Price.PricePerGiB, best.Proposal.Price.PricePerHour).
+			EstimatedEntertainment(entertainment.WeiToMyst(balance))
+		res.Estimates = &contract.EntertainmentEstimateResponse{
+			VideoMinutes:    estimates.VideoMinutes,
+			MusicMinutes:    estimates.MusicMinutes,
+			BrowsingMinutes: estimates.BrowsingMinutes,
+			TrafficMB:       estimates.TrafficMB,
+			PriceGiB:        estimates.PricePerGiB,
+			PriceMin:        estimates.PricePerMin,
+		}
+	}
+
+	utils.WriteAsJSON(res, c.Writer)
+}
+
+// rankedProposals orders proposals by selector score dropping the ones above the price caps.
+func rankedProposals(spec selector.Spec, proposals []proposal.PricedServiceProposal) []proposal.PricedServiceProposal {
+	candidates := spec.Rank(proposals)
+	ranked := make([]proposal.PricedServiceProposal, len(candidates))
+	for i, c := range candidates {
+		ranked[i] = c.Proposal
+	}
+	return ranked
+}
+
+// AddRoutesForSelector adds provider selector routes to given router
+func AddRoutesForSelector(proposalRepository proposalRepository, balanceProvider balanceProvider) func(*gin.Engine) error {
+	se := NewSelectorEndpoint(proposalRepository, balanceProvider)
+	return func(e *gin.Engine) error {
+		e.POST("/proposals/select", se.Select)
+		return nil
+	}
+}
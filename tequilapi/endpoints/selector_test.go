@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+func selectorTestProposal(providerID string, quality float64, priceGiB int64) proposal.PricedServiceProposal {
+	return proposal.PricedServiceProposal{
+		ServiceProposal: market.ServiceProposal{ProviderID: providerID, Quality: market.Quality{Quality: quality}},
+		Price:           market.Price{PricePerGiB: big.NewInt(priceGiB), PricePerHour: big.NewInt(0)},
+	}
+}
+
+func Test_SelectProposal(t *testing.T) {
+	repo := &mockProposalRepository{proposals: []proposal.PricedServiceProposal{
+		selectorTestProposal("0x1", 3, 500_000_000_000_000_000),
+		selectorTestProposal("0x2", 2, 100_000_000_000_000_000),
+		selectorTestProposal("0x3", 1, 50_000_000_000_000_000),
+	}}
+	balance := &mockBalanceProvider{balance: big.NewInt(1_000_000_000_000_000_000)}
+	g := gin.Default()
+	err := AddRoutesForSelector(repo, balance)(g)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		body             string
+		expectedStatus   int
+		expectedProvider string
+	}{
+		{
+			name:             "best quality without caps",
+			body:             `{"selector": {"weights": {"quality": 1}}}`,
+			expectedStatus:   http.StatusOK,
+			expectedProvider: "0x1",
+		},
+		{
+			name:             "best quality within price cap",
+			body:             `{"consumer_id": "0x4", "selector": {"weights": {"quality": 1}, "max_price_gib": 100000000000000000}}`,
+			expectedStatus:   http.StatusOK,
+			expectedProvider: "0x2",
+		},
+		{
+			name:           "nothing within price cap",
+			body:           `{"selector": {"max_price_gib": 1}}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "negative weights",
+			body:           `{"selector": {"weights": {"price_gib": -1}}}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			g.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/proposals/select", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var res contract.ProposalSelectResponse
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+			assert.Equal(t, tt.expectedProvider, res.Proposal.ProviderID)
+			if res.Estimates != nil {
+				// 1 MYST at 0.1 MYST/GiB
+				assert.Equal(t, uint64(10737), res.Estimates.TrafficMB)
+			}
+		})
+	}
+}
@@ -19,10 +19,14 @@ package endpoints
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/services"
@@ -169,6 +173,7 @@ func (se *ServiceEndpoint) ServiceStart(c *gin.Context) {
 		identity.FromAddress(sr.ProviderID),
 		sr.Type,
 		sr.AccessPolicies.IDs,
+		sr.Capacity.ToCapacity(),
 		sr.Options,
 	)
 	if err == service.ErrorLocation {
@@ -227,6 +232,73 @@ func (se *ServiceEndpoint) ServiceStop(c *gin.Context) {
 	resp.WriteHeader(http.StatusAccepted)
 }
 
+// ServiceDrain drains service on the node.
+// swagger:operation POST /services/:id/drain Service serviceDrain
+// ---
+// summary: Drains service
+// description: Unregisters service proposal and refuses new sessions, service is stopped once existing sessions end or the timeout expires
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/ServiceDrainRequestDTO"
+// responses:
+//   202:
+//     description: Service drain initiated
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: No service exists
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Service is not running or already draining
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (se *ServiceEndpoint) ServiceDrain(c *gin.Context) {
+	resp := c.Writer
+
+	id := service.ID(c.Param("id"))
+
+	var req contract.ServiceDrainRequest
+	// Request body is optional, node default timeout is used without it.
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	timeout := config.GetDuration(config.FlagServiceDrainTimeout)
+	if req.Timeout > 0 {
+		timeout = time.Duration(req.Timeout) * time.Second
+	}
+
+	err := se.serviceManager.Drain(id, timeout)
+	switch {
+	case errors.Is(err, service.ErrNoSuchInstance):
+		utils.SendErrorMessage(resp, "Service not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrAlreadyDraining), errors.Is(err, service.ErrNotRunning):
+		utils.SendError(resp, err, http.StatusConflict)
+	case err != nil:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	default:
+		resp.WriteHeader(http.StatusAccepted)
+	}
+}
+
 func (se *ServiceEndpoint) isAlreadyRunning(sr contract.ServiceStartRequest) bool {
 	for _, instance := range se.serviceManager.List() {
 		if instance.ProviderID.Address == sr.ProviderID && instance.Type == sr.Type {
@@ -251,6 +323,7 @@ func AddRoutesForService(
 			g.POST("", serviceEndpoint.ServiceStart)
 			g.GET("/:id", serviceEndpoint.ServiceGet)
 			g.DELETE("/:id", serviceEndpoint.ServiceStop)
+			g.POST("/:id/drain", serviceEndpoint.ServiceDrain)
 		}
 		return nil
 	}
@@ -262,6 +335,7 @@ func (se *ServiceEndpoint) toServiceRequest(req *http.Request) (contract.Service
 		Type           string                          `json:"type"`
 		Options        *json.RawMessage                `json:"options"`
 		AccessPolicies *contract.ServiceAccessPolicies `json:"access_policies"`
+		Capacity       *contract.ServiceCapacityDTO    `json:"capacity"`
 	}
 	decoder := json.NewDecoder(req.Body)
 	decoder.DisallowUnknownFields()
@@ -281,6 +355,12 @@ func (se *ServiceEndpoint) toServiceRequest(req *http.Request) (contract.Service
 	if jsonData.AccessPolicies != nil {
 		sr.AccessPolicies = *jsonData.AccessPolicies
 	}
+	if jsonData.Capacity != nil {
+		sr.Capacity = jsonData.Capacity
+	} else {
+		capacity := contract.NewServiceCapacityDTO(serviceOpts.Capacity)
+		sr.Capacity = &capacity
+	}
 	return sr, nil
 }
 
@@ -312,7 +392,7 @@ func (se *ServiceEndpoint) toServiceOptions(serviceType string, value *json.RawM
 }
 
 func (se *ServiceEndpoint) toServiceInfoResponse(id service.ID, instance *service.Instance) (contract.ServiceInfoDTO, error) {
-	priced, err := se.proposalRepository.EnrichProposalWithPrice(instance.Proposal)
+	priced, err := se.proposalRepository.EnrichProposalWithPrice(instance.CopyProposal())
 	if err != nil {
 		return contract.ServiceInfoDTO{}, err
 	}
@@ -322,6 +402,7 @@ func (se *ServiceEndpoint) toServiceInfoResponse(id service.ID, instance *servic
 		ProviderID: instance.ProviderID.Address,
 		Type:       instance.Type,
 		Options:    instance.Options,
+		Capacity:   contract.NewServiceCapacityDTO(instance.Capacity()),
 		Status:     string(instance.State()),
 		Proposal:   contract.NewProposalDTO(priced),
 	}, nil
@@ -353,13 +434,17 @@ func validateServiceRequest(sr contract.ServiceStartRequest) *validation.FieldEr
 	if sr.Options == serviceOptionsInvalid {
 		errors.ForField("options").AddError("invalid", "Invalid options")
 	}
+	if sr.Capacity != nil {
+		errors.Set(sr.Capacity.Validate())
+	}
 	return errors
 }
 
 // ServiceManager represents service manager that is used for services management.
 type ServiceManager interface {
-	Start(providerID identity.Identity, serviceType string, policies []string, options service.Options) (service.ID, error)
+	Start(providerID identity.Identity, serviceType string, policies []string, capacity service.Capacity, options service.Options) (service.ID, error)
 	Stop(id service.ID) error
+	Drain(id service.ID, timeout time.Duration) error
 	Service(id service.ID) *service.Instance
 	Kill() error
 	List() map[service.ID]*service.Instance
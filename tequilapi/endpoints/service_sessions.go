@@ -0,0 +1,288 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type serviceSessionPool interface {
+	GetAll() []*service.Session
+	Find(id session.ID) (*service.Session, bool)
+}
+
+type consumerBanList interface {
+	List() ([]service.Ban, error)
+	Ban(consumerID identity.Identity, reason string) (service.Ban, error)
+	Unban(consumerID identity.Identity) error
+}
+
+type serviceSessionsEndpoint struct {
+	serviceManager ServiceManager
+	sessions       serviceSessionPool
+	stateProvider  stateProvider
+	banList        consumerBanList
+}
+
+// NewServiceSessionsEndpoint creates and returns endpoint managing live sessions of the provided services
+func NewServiceSessionsEndpoint(
+	serviceManager ServiceManager,
+	sessions serviceSessionPool,
+	stateProvider stateProvider,
+	banList consumerBanList,
+) *serviceSessionsEndpoint {
+	return &serviceSessionsEndpoint{
+		serviceManager: serviceManager,
+		sessions:       sessions,
+		stateProvider:  stateProvider,
+		banList:        banList,
+	}
+}
+
+// List returns live sessions of the service
+// swagger:operation GET /services/{id}/sessions Service serviceSessionList
+// ---
+// summary: Returns live sessions of the service
+// description: Returns sessions currently served with the consumer, traffic and earnings so far
+// parameters:
+//   - name: id
+//     in: path
+//     description: Service id
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: List of live sessions
+//     schema:
+//       "$ref": "#/definitions/ServiceSessionListResponse"
+//   404:
+//     description: Service not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (sse *serviceSessionsEndpoint) List(c *gin.Context) {
+	id := c.Param("id")
+	if sse.serviceManager.Service(service.ID(id)) == nil {
+		utils.SendErrorMessage(c.Writer, "Service not found", http.StatusNotFound)
+		return
+	}
+
+	stats := make(map[session.ID]contract.SessionDTO)
+	for _, sess := range sse.stateProvider.GetState().Sessions {
+		stats[sess.SessionID] = contract.NewSessionDTO(sess)
+	}
+
+	res := contract.ServiceSessionListResponse{Sessions: make([]contract.ServiceSessionDTO, 0)}
+	for _, sess := range sse.sessions.GetAll() {
+		if sess.ServiceID != id {
+			continue
+		}
+
+		dto := contract.NewServiceSessionDTO(sess)
+		if stat, ok := stats[sess.ID]; ok {
+			dto.BytesReceived = stat.BytesReceived
+			dto.BytesSent = stat.BytesSent
+			if stat.Tokens != nil {
+				dto.Tokens = stat.Tokens
+			}
+		}
+		res.Sessions = append(res.Sessions, dto)
+	}
+
+	utils.WriteAsJSON(res, c.Writer)
+}
+
+// Terminate ends live session of the service
+// swagger:operation DELETE /services/{id}/sessions/{session_id} Service serviceSessionTerminate
+// ---
+// summary: Terminates live session
+// description: Closes the session with the consumer, the consumer is free to connect again unless banned
+// parameters:
+//   - name: id
+//     in: path
+//     description: Service id
+//     type: string
+//     required: true
+//   - name: session_id
+//     in: path
+//     description: Session id
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: Session terminated
+//   404:
+//     description: Session not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (sse *serviceSessionsEndpoint) Terminate(c *gin.Context) {
+	sess, ok := sse.sessions.Find(session.ID(c.Param("session_id")))
+	if !ok || sess.ServiceID != c.Param("id") {
+		utils.SendErrorMessage(c.Writer, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	sess.Close()
+	c.Writer.WriteHeader(http.StatusAccepted)
+}
+
+// Bans returns banned consumers
+// swagger:operation GET /consumer-bans Service consumerBanList
+// ---
+// summary: Returns banned consumers
+// description: Returns consumer identities which are not allowed to start sessions with the provider
+// responses:
+//   200:
+//     description: List of banned consumers
+//     schema:
+//       "$ref": "#/definitions/ConsumerBanListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (sse *serviceSessionsEndpoint) Bans(c *gin.Context) {
+	bans, err := sse.banList.List()
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewConsumerBanListResponse(bans), c.Writer)
+}
+
+// Ban bans consumer
+// swagger:operation POST /consumer-bans Service consumerBan
+// ---
+// summary: Bans consumer
+// description: Bans consumer identity and terminates its live sessions
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/ConsumerBanRequestDTO"
+// responses:
+//   201:
+//     description: Consumer banned
+//     schema:
+//       "$ref": "#/definitions/ConsumerBanDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (sse *serviceSessionsEndpoint) Ban(c *gin.Context) {
+	var req contract.ConsumerBanRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(c.Writer, errorMap)
+		return
+	}
+
+	consumerID := identity.FromAddress(req.ConsumerID)
+	ban, err := sse.banList.Ban(consumerID, req.Reason)
+	if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	for _, sess := range sse.sessions.GetAll() {
+		if sess.ConsumerID == consumerID {
+			sess.Close()
+		}
+	}
+
+	utils.WriteAsJSON(contract.NewConsumerBanDTO(ban), c.Writer, http.StatusCreated)
+}
+
+// Unban removes consumer from the ban list
+// swagger:operation DELETE /consumer-bans/{id} Service consumerUnban
+// ---
+// summary: Unbans consumer
+// description: Allows consumer identity to start sessions with the provider again
+// parameters:
+//   - name: id
+//     in: path
+//     description: Consumer identity
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: Consumer unbanned
+//   404:
+//     description: Consumer is not banned
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (sse *serviceSessionsEndpoint) Unban(c *gin.Context) {
+	err := sse.banList.Unban(identity.FromAddress(c.Param("id")))
+	if err == service.ErrBanNotFound {
+		utils.SendError(c.Writer, err, http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Writer.WriteHeader(http.StatusAccepted)
+}
+
+// AddRoutesForServiceSessions adds routes managing live sessions of the provided services and banned consumers
+func AddRoutesForServiceSessions(
+	serviceManager ServiceManager,
+	sessions serviceSessionPool,
+	stateProvider stateProvider,
+	banList consumerBanList,
+) func(*gin.Engine) error {
+	sse := NewServiceSessionsEndpoint(serviceManager, sessions, stateProvider, banList)
+	return func(e *gin.Engine) error {
+		g := e.Group("/services/:id/sessions")
+		{
+			g.GET("", sse.List)
+			g.DELETE("/:session_id", sse.Terminate)
+		}
+		b := e.Group("/consumer-bans")
+		{
+			b.GET("", sse.Bans)
+			b.POST("", sse.Ban)
+			b.DELETE("/:id", sse.Unban)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/service"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+type mockServiceSessionPool struct {
+	sessions []*service.Session
+}
+
+func (m *mockServiceSessionPool) GetAll() []*service.Session {
+	return m.sessions
+}
+
+func (m *mockServiceSessionPool) Find(id session.ID) (*service.Session, bool) {
+	for _, sess := range m.sessions {
+		if sess.ID == id {
+			return sess, true
+		}
+	}
+	return nil, false
+}
+
+type mockConsumerBanList struct {
+	bans map[string]service.Ban
+}
+
+func (m *mockConsumerBanList) List() ([]service.Ban, error) {
+	var bans []service.Ban
+	for _, ban := range m.bans {
+		bans = append(bans, ban)
+	}
+	return bans, nil
+}
+
+func (m *mockConsumerBanList) Ban(consumerID identity.Identity, reason string) (service.Ban, error) {
+	ban := service.Ban{ConsumerID: consumerID.Address, Reason: reason}
+	m.bans[consumerID.Address] = ban
+	return ban, nil
+}
+
+func (m *mockConsumerBanList) Unban(consumerID identity.Identity) error {
+	if _, ok := m.bans[consumerID.Address]; !ok {
+		return service.ErrBanNotFound
+	}
+	delete(m.bans, consumerID.Address)
+	return nil
+}
+
+func newLiveSession(t *testing.T, serviceID, consumerID string) *service.Session {
+	sess, err := service.NewSession(mockServiceRunning, &pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID,
+			Location: &pb.LocationInfo{Country: "LT"},
+		},
+	}, nil)
+	assert.NoError(t, err)
+	sess.ServiceID = serviceID
+	return sess
+}
+
+func Test_ServiceSessions(t *testing.T) {
+	served := newLiveSession(t, string(mockServiceID), "0x000000000000000000000000000000000000000a")
+	other := newLiveSession(t, "other-service", "0x000000000000000000000000000000000000000b")
+	pool := &mockServiceSessionPool{sessions: []*service.Session{served, other}}
+	state := &mockStateProvider{stateToReturn: stateEvent.State{
+		Sessions: []consumer_session.History{
+			{SessionID: served.ID, DataSent: 10, DataReceived: 20, Tokens: big.NewInt(300)},
+		},
+	}}
+	banList := &mockConsumerBanList{bans: map[string]service.Ban{}}
+
+	g := gin.Default()
+	err := AddRoutesForServiceSessions(&mockServiceManager{}, pool, state, banList)(g)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/services/"+string(mockServiceID)+"/sessions", nil)
+	g.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"id":"`+string(served.ID)+`"`)
+	assert.Contains(t, resp.Body.String(), `"consumer_country":"LT"`)
+	assert.Contains(t, resp.Body.String(), `"bytes_received":20,"bytes_sent":10,"tokens":300`)
+	assert.NotContains(t, resp.Body.String(), string(other.ID))
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/services/unknown/sessions", nil)
+	g.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodDelete, "/services/"+string(mockServiceID)+"/sessions/"+string(other.ID), nil)
+	g.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodDelete, "/services/"+string(mockServiceID)+"/sessions/"+string(served.ID), nil)
+	g.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	<-served.Done()
+}
+
+func Test_ConsumerBans(t *testing.T) {
+	live := newLiveSession(t, string(mockServiceID), "0x000000000000000000000000000000000000000a")
+	pool := &mockServiceSessionPool{sessions: []*service.Session{live}}
+	banList := &mockConsumerBanList{bans: map[string]service.Ban{}}
+
+	g := gin.Default()
+	err := AddRoutesForServiceSessions(&mockServiceManager{}, pool, &mockStateProvider{}, banList)(g)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/consumer-bans", strings.NewReader(`{"reason": "abuse"}`))
+	g.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/consumer-bans", strings.NewReader(`{"consumer_id": "0x000000000000000000000000000000000000000A", "reason": "abuse"}`))
+	g.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"consumer_id":"0x000000000000000000000000000000000000000a"`)
+	<-live.Done()
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/consumer-bans", nil)
+	g.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"reason":"abuse"`)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodDelete, "/consumer-bans/0x000000000000000000000000000000000000000a", nil)
+	g.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodDelete, "/consumer-bans/0x000000000000000000000000000000000000000a", nil)
+	g.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -26,6 +26,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -83,15 +84,25 @@ type fancyServiceOptions struct {
 	Foo string `json:"foo"`
 }
 
-type mockServiceManager struct{}
+type mockServiceManager struct {
+	drainTimeout time.Duration
+	drainErr     error
+}
 
-func (sm *mockServiceManager) Start(_ identity.Identity, serviceType string, _ []string, _ service.Options) (service.ID, error) {
+func (sm *mockServiceManager) Start(_ identity.Identity, serviceType string, _ []string, _ service.Capacity, _ service.Options) (service.ID, error) {
 	if serviceType == serviceTypeWithAccessPolicy {
 		return mockAccessPolicyServiceID, nil
 	}
 	return mockServiceID, nil
 }
 func (sm *mockServiceManager) Stop(id service.ID) error { return nil }
+func (sm *mockServiceManager) Drain(id service.ID, timeout time.Duration) error {
+	if sm.Service(id) == nil {
+		return service.ErrNoSuchInstance
+	}
+	sm.drainTimeout = timeout
+	return sm.drainErr
+}
 func (sm *mockServiceManager) Service(id service.ID) *service.Instance {
 	if id == "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
 		return mockServiceRunning
@@ -168,6 +179,7 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 						"per_hour": 1.0
 					}
 				},
+				"capacity": {"max_sessions":0, "max_consumer_sessions":0, "min_free_cpu":0, "min_free_memory":0, "bandwidth":0, "min_free_bandwidth":0},
 				"connection_statistics": {"attempted":0, "successful":0}
 			}]`,
 		},
@@ -203,6 +215,7 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 						"per_hour": 1.0
 					}
 				},
+				"capacity": {"max_sessions":0, "max_consumer_sessions":0, "min_free_cpu":0, "min_free_memory":0, "bandwidth":0, "min_free_bandwidth":0},
 				"connection_statistics": {"attempted":0, "successful":0}
 			}`,
 		},
@@ -238,6 +251,7 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 						"per_hour": 1.0
 					}
 				},
+				"capacity": {"max_sessions":0, "max_consumer_sessions":0, "min_free_cpu":0, "min_free_memory":0, "bandwidth":0, "min_free_bandwidth":0},
 				"connection_statistics": {"attempted":0, "successful":0}
 			}`,
 		},
@@ -249,6 +263,14 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 			http.MethodDelete, "/services/00000000-9dad-11d1-80b4-00c04fd43000", "",
 			http.StatusNotFound, `{"message":"Service not found"}`,
 		},
+		{
+			http.MethodPost, "/services/6ba7b810-9dad-11d1-80b4-00c04fd430c8/drain", "",
+			http.StatusAccepted, "",
+		},
+		{
+			http.MethodPost, "/services/00000000-9dad-11d1-80b4-00c04fd43000/drain", "",
+			http.StatusNotFound, `{"message":"Service not found"}`,
+		},
 	}
 
 	for _, test := range tests {
@@ -447,6 +469,7 @@ func Test_ServiceGetReturnsServiceInfo(t *testing.T) {
 					"per_hour": 1.0
 				}
 			},
+			"capacity": {"max_sessions":0, "max_consumer_sessions":0, "min_free_cpu":0, "min_free_memory":0, "bandwidth":0, "min_free_bandwidth":0},
 			"connection_statistics": {"attempted":0, "successful":0}
 		}`,
 		resp.Body.String(),
@@ -568,6 +591,7 @@ func Test_ServiceStart_WithAccessPolicy(t *testing.T) {
 					}
 				]
 			},
+			"capacity": {"max_sessions":0, "max_consumer_sessions":0, "min_free_cpu":0, "min_free_memory":0, "bandwidth":0, "min_free_bandwidth":0},
 			"connection_statistics": {"attempted":0, "successful":0}
 		}`,
 		resp.Body.String(),
@@ -601,3 +625,44 @@ func Test_ServiceStart_ReturnsBadRequest_WithUnknownParams(t *testing.T) {
 		resp.Body.String(),
 	)
 }
+
+func Test_ServiceDrain(t *testing.T) {
+	for name, tc := range map[string]struct {
+		body            string
+		drainErr        error
+		expectedStatus  int
+		expectedTimeout time.Duration
+	}{
+		"drains with requested timeout": {
+			body:            `{"timeout": 30}`,
+			expectedStatus:  http.StatusAccepted,
+			expectedTimeout: 30 * time.Second,
+		},
+		"rejects negative timeout": {
+			body:           `{"timeout": -1}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		"rejects invalid body": {
+			body:           `{"timeout":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"rejects service already draining": {
+			drainErr:       service.ErrAlreadyDraining,
+			expectedStatus: http.StatusConflict,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			manager := &mockServiceManager{drainErr: tc.drainErr}
+			g := gin.Default()
+			err := AddRoutesForService(manager, fakeOptionsParser, &mockProposalRepository{})(g)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/services/6ba7b810-9dad-11d1-80b4-00c04fd430c8/drain", strings.NewReader(tc.body))
+			resp := httptest.NewRecorder()
+			g.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+			assert.Equal(t, tc.expectedTimeout, manager.drainTimeout)
+		})
+	}
+}
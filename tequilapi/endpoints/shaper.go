@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type shaperLimiter interface {
+	Rules() shaper.Rules
+	SetRules(rules shaper.Rules) error
+}
+
+type shaperEndpoint struct {
+	limiter shaperLimiter
+}
+
+// NewShaperEndpoint creates and returns traffic shaper endpoint
+func NewShaperEndpoint(limiter shaperLimiter) *shaperEndpoint {
+	return &shaperEndpoint{
+		limiter: limiter,
+	}
+}
+
+// Rules returns bandwidth limit rules
+// swagger:operation GET /shaper/rules Shaper shaperRules
+// ---
+// summary: Returns bandwidth limit rules
+// description: Returns bandwidth limits applied to provided sessions by default, by consumer identity and by access policy
+// responses:
+//   200:
+//     description: Bandwidth limit rules
+//     schema:
+//       "$ref": "#/definitions/ShaperRulesDTO"
+func (se *shaperEndpoint) Rules(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewShaperRulesDTO(se.limiter.Rules()), c.Writer)
+}
+
+// SetRules replaces bandwidth limit rules
+// swagger:operation PUT /shaper/rules Shaper shaperSetRules
+// ---
+// summary: Replaces bandwidth limit rules
+// description: Replaces bandwidth limit rules and applies them to active sessions
+// parameters:
+//   - in: body
+//     name: body
+//     description: Bandwidth limit rules
+//     schema:
+//       $ref: "#/definitions/ShaperRulesDTO"
+// responses:
+//   200:
+//     description: Bandwidth limit rules
+//     schema:
+//       "$ref": "#/definitions/ShaperRulesDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (se *shaperEndpoint) SetRules(c *gin.Context) {
+	var req contract.ShaperRulesDTO
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		utils.SendError(c.Writer, err, http.StatusBadRequest)
+		return
+	}
+
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(c.Writer, errorMap)
+		return
+	}
+
+	if err := se.limiter.SetRules(req.ToRules()); err != nil {
+		utils.SendError(c.Writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewShaperRulesDTO(se.limiter.Rules()), c.Writer)
+}
+
+// AddRoutesForShaper adds traffic shaper routes to given router
+func AddRoutesForShaper(limiter shaperLimiter) func(*gin.Engine) error {
+	se := NewShaperEndpoint(limiter)
+	return func(e *gin.Engine) error {
+		g := e.Group("/shaper")
+		{
+			g.GET("/rules", se.Rules)
+			g.PUT("/rules", se.SetRules)
+		}
+		return nil
+	}
+}
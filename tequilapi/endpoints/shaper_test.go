@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/shaper"
+)
+
+type mockShaperLimiter struct {
+	rules shaper.Rules
+}
+
+func (m *mockShaperLimiter) Rules() shaper.Rules {
+	return m.rules
+}
+
+func (m *mockShaperLimiter) SetRules(rules shaper.Rules) error {
+	m.rules = rules
+	return nil
+}
+
+func Test_Shaper(t *testing.T) {
+	limiter := &mockShaperLimiter{}
+	se := NewShaperEndpoint(limiter)
+
+	g := gin.Default()
+	g.GET("/shaper/rules", se.Rules)
+	g.PUT("/shaper/rules", se.SetRules)
+
+	tests := []struct {
+		method         string
+		body           string
+		expectedStatus int
+		expectedJSON   string
+	}{
+		{
+			http.MethodGet,
+			"",
+			http.StatusOK,
+			`{"identities": {}, "policies": {}}`,
+		},
+		{
+			http.MethodPut,
+			`{"policies": {"": {"up": 1, "down": 1}}}`,
+			http.StatusUnprocessableEntity,
+			`{
+				"message": "validation_error",
+				"errors": {
+					"policies": [ {"code": "invalid", "message": "Policy ID must not be empty"} ]
+				}
+			}`,
+		},
+		{
+			http.MethodPut,
+			`{"default": {"up": 256, "down": 512}, "identities": {"0xAB": {"up": 0, "down": 2048}}, "policies": {"1": {"up": 512, "down": 1024}}}`,
+			http.StatusOK,
+			`{
+				"default": {"up": 256, "down": 512},
+				"identities": {"0xab": {"up": 0, "down": 2048}},
+				"policies": {"1": {"up": 512, "down": 1024}}
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(test.method, "/shaper/rules", strings.NewReader(test.body))
+		g.ServeHTTP(resp, req)
+
+		assert.Equal(t, test.expectedStatus, resp.Code, test.method)
+		assert.JSONEq(t, test.expectedJSON, resp.Body.String(), test.method)
+	}
+
+	assert.Equal(t, &shaper.Limits{Up: 256, Down: 512}, limiter.rules.Default)
+	assert.Equal(t, map[string]shaper.Limits{"0xab": {Down: 2048}}, limiter.rules.Identities)
+}
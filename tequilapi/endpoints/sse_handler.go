@@ -29,6 +29,7 @@ import (
 	"github.com/mysteriumnetwork/node/session/pingpong"
 
 	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	nodeEvent "github.com/mysteriumnetwork/node/core/node/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -53,6 +54,8 @@ const (
 	ServiceStatusEvent EventType = "service-status"
 	// StateChangeEvent represents the state change
 	StateChangeEvent EventType = "state-change"
+	// ConnectionDiagnosticsEvent represents the connection diagnostics progress
+	ConnectionDiagnosticsEvent EventType = "connection-diagnostics"
 )
 
 // Handler represents an sse handler
@@ -89,6 +92,10 @@ func (h *Handler) Subscribe(bus eventbus.Subscriber) error {
 		return err
 	}
 	err = bus.Subscribe(stateEvent.AppTopicState, h.ConsumeStateEvent)
+	if err != nil {
+		return err
+	}
+	err = bus.Subscribe(connectionstate.AppTopicConnectionDiagnostics, h.ConsumeDiagnosticsEvent)
 	return err
 }
 
@@ -288,3 +295,11 @@ func (h *Handler) ConsumeStateEvent(event stateEvent.State) {
 		Payload: mapState(event),
 	})
 }
+
+// ConsumeDiagnosticsEvent consumes the connection diagnostics progress event
+func (h *Handler) ConsumeDiagnosticsEvent(event connectionstate.AppEventConnectionDiagnostics) {
+	h.send(Event{
+		Type:    ConnectionDiagnosticsEvent,
+		Payload: contract.NewConnectionDiagnosticsDTO(event.Diagnostics),
+	})
+}
@@ -238,6 +238,37 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
 }`
 	assert.JSONEq(t, expectJSON, msgJSON)
 
+	h.ConsumeDiagnosticsEvent(connectionstate.AppEventConnectionDiagnostics{
+		Diagnostics: connectionstate.Diagnostics{
+			SessionID: "session-1",
+			PingsSent: 2,
+			PingsLost: 1,
+			Latency:   20 * time.Millisecond,
+		},
+	})
+
+	msg = <-results
+	assert.Regexp(t, "^data:\\s?{.*}$", msg)
+	msgJSON = strings.TrimPrefix(msg, "data: ")
+	expectJSON = `
+{
+  "payload": {
+    "session_id": "session-1",
+    "completed": false,
+    "pings_sent": 2,
+    "pings_lost": 1,
+    "packet_loss": 0.5,
+    "latency": 20,
+    "jitter": 0,
+    "dns_option": "",
+    "dns_resolve_time": 0,
+    "observed_traffic_rate_sent": 0,
+    "observed_traffic_rate_received": 0
+  },
+  "type": "connection-diagnostics"
+}`
+	assert.JSONEq(t, expectJSON, msgJSON)
+
 	cancel()
 	listener.Close()
 
@@ -18,6 +18,7 @@
 package netutil
 
 import (
+	"fmt"
 	"net"
 	"strings"
 
@@ -33,11 +34,107 @@ func AddDefaultRoute(iface string) error {
 	return addDefaultRoute(iface)
 }
 
+// maxNestingLevel bounds the count of nested tunnels, every level doubles the count of default routes.
+const maxNestingLevel = 6
+
+// AddNestedDefaultRoute adds default VPN tunnel route which takes precedence
+// over the default routes of already established outer tunnels, level is the count of them.
+// Traffic to the tunnel peer endpoint keeps going through the outer tunnel.
+func AddNestedDefaultRoute(iface string, level int, endpoint net.IP, outerIface string) error {
+	if level < 1 {
+		level = 1
+	}
+	if level > maxNestingLevel {
+		return fmt.Errorf("tunnel nesting level %d exceeds the limit of %d", level, maxNestingLevel)
+	}
+
+	if err := addHostRoute(endpoint, outerIface); err != nil {
+		return err
+	}
+
+	if err := addNestedDefaultRoute(iface, nestedSubnets(level)); err != nil {
+		if err := deleteHostRoute(endpoint, outerIface); err != nil {
+			log.Warn().Err(err).Msgf("Failed to delete route to %s", endpoint)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNestedDefaultRoute deletes the route to the tunnel peer endpoint added by AddNestedDefaultRoute,
+// default routes of the tunnel are deleted together with its interface.
+func DeleteNestedDefaultRoute(endpoint net.IP, outerIface string) error {
+	return deleteHostRoute(endpoint, outerIface)
+}
+
+// nestedSubnets splits IPv4 and IPv6 address space into subnets with a prefix longer than
+// the prefix of default routes of every outer tunnel, so the longest prefix match picks the nested one.
+func nestedSubnets(level int) []net.IPNet {
+	prefix := level + 1
+	count := 1 << prefix
+
+	subnets := make([]net.IPNet, 0, 2*count)
+	for i := 0; i < count; i++ {
+		ip := make(net.IP, net.IPv4len)
+		ip[0] = byte(i << (8 - prefix))
+		subnets = append(subnets, net.IPNet{IP: ip, Mask: net.CIDRMask(prefix, 8*net.IPv4len)})
+	}
+	for i := 0; i < count; i++ {
+		ip := make(net.IP, net.IPv6len)
+		ip[0] = byte(i << (8 - prefix))
+		subnets = append(subnets, net.IPNet{IP: ip, Mask: net.CIDRMask(prefix, 8*net.IPv6len)})
+	}
+
+	return subnets
+}
+
+// AddRoutes routes traffic of the given subnets through the VPN tunnel, existing routes of the subnets are replaced.
+func AddRoutes(iface string, subnets []string) error {
+	for _, subnet := range subnets {
+		_, ipNet, err := net.ParseCIDR(subnet)
+		if err != nil {
+			return fmt.Errorf("could not parse subnet %q: %w", subnet, err)
+		}
+
+		if err := addRoute(iface, *ipNet); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // AssignIP assigns subnet to given interface.
 func AssignIP(iface string, subnet net.IPNet) error {
 	return assignIP(iface, subnet)
 }
 
+// AssignIPv6 assigns IPv6 subnet to given interface.
+func AssignIPv6(iface string, subnet net.IPNet) error {
+	return assignIPv6(iface, subnet)
+}
+
+// HasIPv6Egress checks if the host has a global IPv6 address to reach the Internet with.
+func HasIPv6Egress() bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to list interface addresses")
+		return false
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() != nil {
+			continue
+		}
+		if ipNet.IP.IsGlobalUnicast() && !ipNet.IP.IsPrivate() {
+			return true
+		}
+	}
+	return false
+}
+
 func defaultLogNetworkStats() {
 	if log.Logger.GetLevel() != zerolog.TraceLevel {
 		return
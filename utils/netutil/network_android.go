@@ -32,6 +32,10 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return nil
 }
 
+func assignIPv6(iface string, subnet net.IPNet) error {
+	return nil
+}
+
 func excludeRoute(ip, gw net.IP) error {
 	return nil
 }
@@ -44,6 +48,22 @@ func addDefaultRoute(iface string) error {
 	return nil
 }
 
+func addNestedDefaultRoute(iface string, subnets []net.IPNet) error {
+	return nil
+}
+
+func addRoute(iface string, subnet net.IPNet) error {
+	return nil
+}
+
+func addHostRoute(ip net.IP, iface string) error {
+	return nil
+}
+
+func deleteHostRoute(ip net.IP, iface string) error {
+	return nil
+}
+
 func logNetworkStats() {
 }
 
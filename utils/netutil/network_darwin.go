@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"strconv"
 
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
 )
@@ -37,6 +38,11 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return nil
 }
 
+func assignIPv6(iface string, subnet net.IPNet) error {
+	ones, _ := subnet.Mask.Size()
+	return cmdutil.SudoExec("ifconfig", iface, "inet6", subnet.IP.String(), "prefixlen", strconv.Itoa(ones), "alias")
+}
+
 func excludeRoute(ip, gw net.IP) error {
 	return cmdutil.SudoExec("route", "add", "-host", ip.String(), gw.String())
 }
@@ -65,6 +71,42 @@ func addDefaultRoute(iface string) error {
 	return nil
 }
 
+func addNestedDefaultRoute(iface string, subnets []net.IPNet) error {
+	for _, subnet := range subnets {
+		args := []string{"route", "add", "-net", subnet.String(), "-interface", iface}
+		if subnet.IP.To4() == nil {
+			args = []string{"route", "add", "-inet6", subnet.String(), fmt.Sprintf("100::1%%%s", iface)}
+		}
+
+		if err := cmdutil.SudoExec(args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addRoute(iface string, subnet net.IPNet) error {
+	args := []string{"-net", subnet.String(), "-interface", iface}
+	if subnet.IP.To4() == nil {
+		args = []string{"-inet6", subnet.String(), fmt.Sprintf("100::1%%%s", iface)}
+	}
+
+	if err := cmdutil.SudoExec(append([]string{"route", "add"}, args...)...); err != nil {
+		return cmdutil.SudoExec(append([]string{"route", "change"}, args...)...)
+	}
+
+	return nil
+}
+
+func addHostRoute(ip net.IP, iface string) error {
+	return cmdutil.SudoExec("route", "add", "-host", ip.String(), "-interface", iface)
+}
+
+func deleteHostRoute(ip net.IP, iface string) error {
+	return cmdutil.SudoExec("route", "delete", "-host", ip.String(), "-interface", iface)
+}
+
 func peerIP(subnet net.IPNet) net.IP {
 	lastOctetID := len(subnet.IP) - 1
 	if subnet.IP[lastOctetID] == byte(1) {
@@ -36,6 +36,10 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return cmdutil.SudoExec("ip", "link", "set", "dev", iface, "up")
 }
 
+func assignIPv6(iface string, subnet net.IPNet) error {
+	return cmdutil.SudoExec("ip", "-6", "address", "replace", "dev", iface, subnet.String())
+}
+
 func excludeRoute(ip, gw net.IP) error {
 	return cmdutil.SudoExec("ip", "route", "add", ip.String(), "via", gw.String())
 }
@@ -66,6 +70,35 @@ func addDefaultRoute(iface string) error {
 	return nil
 }
 
+func addNestedDefaultRoute(iface string, subnets []net.IPNet) error {
+	ipv6 := ipv6Enabled()
+	for _, subnet := range subnets {
+		if subnet.IP.To4() != nil {
+			if err := cmdutil.SudoExec("ip", "route", "add", subnet.String(), "dev", iface); err != nil {
+				return err
+			}
+		} else if ipv6 {
+			if err := cmdutil.SudoExec("ip", "-6", "route", "add", subnet.String(), "dev", iface); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func addRoute(iface string, subnet net.IPNet) error {
+	return cmdutil.SudoExec("ip", "route", "replace", subnet.String(), "dev", iface)
+}
+
+func addHostRoute(ip net.IP, iface string) error {
+	return cmdutil.SudoExec("ip", "route", "add", ip.String(), "dev", iface)
+}
+
+func deleteHostRoute(ip net.IP, iface string) error {
+	return cmdutil.SudoExec("ip", "route", "delete", ip.String(), "dev", iface)
+}
+
 func logNetworkStats() {
 	for _, args := range [][]string{{"iptables", "-L", "-n"}, {"iptables", "-L", "-n", "-t", "nat"}, {"ip", "route", "list"}, {"ip", "address", "list"}} {
 		out, err := exec.Command("sudo", args...).CombinedOutput()
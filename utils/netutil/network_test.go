@@ -0,0 +1,43 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNestedSubnets(t *testing.T) {
+	var level1, level2 []string
+	for _, subnet := range nestedSubnets(1) {
+		level1 = append(level1, subnet.String())
+	}
+	for _, subnet := range nestedSubnets(2) {
+		level2 = append(level2, subnet.String())
+	}
+
+	assert.Equal(t, []string{
+		"0.0.0.0/2", "64.0.0.0/2", "128.0.0.0/2", "192.0.0.0/2",
+		"::/2", "4000::/2", "8000::/2", "c000::/2",
+	}, level1)
+	assert.Equal(t, []string{
+		"0.0.0.0/3", "32.0.0.0/3", "64.0.0.0/3", "96.0.0.0/3", "128.0.0.0/3", "160.0.0.0/3", "192.0.0.0/3", "224.0.0.0/3",
+		"::/3", "2000::/3", "4000::/3", "6000::/3", "8000::/3", "a000::/3", "c000::/3", "e000::/3",
+	}, level2)
+}
@@ -34,6 +34,11 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return errors.Wrap(err, string(out))
 }
 
+func assignIPv6(iface string, subnet net.IPNet) error {
+	out, err := exec.Command("powershell", "-Command", "netsh interface ipv6 add address interface=\""+iface+"\" address="+subnet.String()).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
 func excludeRoute(ip, gw net.IP) error {
 	out, err := exec.Command("powershell", "-Command", "route add "+ip.String()+"/32 "+gw.String()).CombinedOutput()
 	return errors.Wrap(err, string(out))
@@ -73,6 +78,58 @@ func addDefaultRoute(name string) error {
 	return nil
 }
 
+func addNestedDefaultRoute(name string, subnets []net.IPNet) error {
+	id, gw, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	for _, subnet := range subnets {
+		subnetGW := gw
+		if subnet.IP.To4() == nil {
+			subnetGW = "100::1"
+		}
+
+		if out, err := exec.Command("powershell", "-Command", "route add "+subnet.String()+" "+subnetGW+" if "+id).CombinedOutput(); err != nil {
+			return errors.Wrap(err, string(out))
+		}
+	}
+
+	return nil
+}
+
+func addRoute(name string, subnet net.IPNet) error {
+	id, gw, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	if subnet.IP.To4() == nil {
+		gw = "100::1"
+	}
+
+	if _, err := exec.Command("powershell", "-Command", "route add "+subnet.String()+" "+gw+" if "+id).CombinedOutput(); err != nil {
+		out, err := exec.Command("powershell", "-Command", "route change "+subnet.String()+" "+gw+" if "+id).CombinedOutput()
+		return errors.Wrap(err, string(out))
+	}
+
+	return nil
+}
+
+func addHostRoute(ip net.IP, name string) error {
+	id, gw, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	out, err := exec.Command("powershell", "-Command", "route add "+ip.String()+"/32 "+gw+" if "+id).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
+func deleteHostRoute(ip net.IP, name string) error {
+	return deleteRoute(ip.String(), "")
+}
+
 func interfaceInfo(name string) (id, gw string, err error) {
 	iface, err := net.InterfaceByName(name)
 	if err != nil {